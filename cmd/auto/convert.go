@@ -0,0 +1,430 @@
+// Package auto handles conversion of statements whose format is detected from
+// their content.
+package auto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"fjacquet/camt-csv/cmd/common"
+	"fjacquet/camt-csv/cmd/root"
+	"fjacquet/camt-csv/internal/batch"
+	"fjacquet/camt-csv/internal/container"
+	"fjacquet/camt-csv/internal/detect"
+	"fjacquet/camt-csv/internal/formatter"
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parser"
+	"fjacquet/camt-csv/internal/progress"
+
+	"github.com/spf13/cobra"
+)
+
+// Cmd represents the auto command
+var Cmd = &cobra.Command{
+	Use:   "auto",
+	Short: "Detect the statement format and convert to CSV",
+	Long: `Convert bank statements without naming their format: each input file is
+recognized from its content (CAMT namespace, MT940 tags, PDF, or the header
+of Revolut, Selma, Wise and debit CSV exports) and converted with the
+matching parser. Files no parser recognizes are skipped with a warning.
+
+Examples:
+  # One CSV per input file, written to out/
+  camt-csv auto -i downloads/ -o out/
+
+  # All statements in a single CSV, sorted chronologically
+  camt-csv auto -i "downloads/*" -o all.csv --consolidate
+
+  # BCV statements, read and cleaned with the settings of the bcv preset
+  camt-csv auto -i bcv/ -o out/ --preset bcv`,
+	Run: autoFunc,
+}
+
+func init() {
+	common.RegisterFormatFlags(Cmd)
+	common.RegisterLimitFlags(Cmd)
+	common.RegisterInputEncodingFlag(Cmd)
+	common.RegisterFilterFlags(Cmd)
+	common.RegisterUncategorizedFlag(Cmd)
+	common.RegisterNoClobberFlag(Cmd)
+	common.RegisterCategorizeFlag(Cmd)
+	common.RegisterPresetFlag(Cmd)
+	Cmd.Flags().Bool("consolidate", false,
+		"Write the transactions of all input files to the single CSV file given with --output instead of one CSV per input")
+}
+
+// parserLookup returns the parser registered under name.
+type parserLookup func(name string) (parser.FullParser, error)
+
+// Summary counts the outcome of an auto conversion.
+type Summary struct {
+	Converted int
+	Skipped   int
+	Failed    int
+}
+
+func autoFunc(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	logger := root.GetLogrusAdapter()
+	root.Log.Info("Auto convert command called")
+
+	inputPath := root.SharedFlags.Input
+	outputPath := root.SharedFlags.Output
+	if inputPath == "" || outputPath == "" {
+		logger.Fatal("--input and --output are required")
+	}
+
+	format, _ := cmd.Flags().GetString("format")
+	consolidate, _ := cmd.Flags().GetBool("consolidate")
+	opts, err := common.ParseOutputOptions(cmd, logger)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+	ctx, err = common.WithTransactionLimit(ctx, cmd)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+	ctx, err = common.WithInputEncoding(ctx, cmd)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+	ctx, err = common.WithTransactionFilter(ctx, cmd)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+	ctx, checkUncategorized, err := common.WithUncategorizedCheck(ctx, cmd, logger)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+
+	appContainer := root.GetContainer()
+	if appContainer == nil {
+		logger.Fatal("Container not initialized")
+	}
+	ctx, presetParser, err := common.WithPreset(ctx, cmd, appContainer, &opts.Options)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+	if presetParser != "" {
+		ctx = WithParser(ctx, presetParser)
+	}
+	if format == "" {
+		format = appContainer.GetConfig().Output.Format
+	}
+	outFormatter, err := appContainer.GetFormatterRegistry().Get(format)
+	if err != nil {
+		logger.Fatalf("Invalid output format '%s': valid formats are standard, icompta, jumpsoft", format)
+	}
+	outFormatter = formatter.ApplyOptions(outFormatter, opts.Options)
+
+	lookup := func(name string) (parser.FullParser, error) {
+		p, err := appContainer.GetParser(container.ParserType(name))
+		if err != nil {
+			return nil, err
+		}
+		common.ApplyCategorizeFlag(cmd, p, logger)
+		return p, nil
+	}
+
+	files, err := InputFiles(inputPath)
+	if err != nil {
+		logger.Fatalf("Error reading input: %v", err)
+	}
+
+	ctx = progress.WithReporter(ctx, common.NewProgress("Converting"))
+	var summary Summary
+	if consolidate {
+		summary, err = ConvertConsolidated(ctx, files, lookup, outputPath, logger, outFormatter, opts)
+	} else {
+		summary, err = ConvertEach(ctx, files, lookup, outputPath, logger, outFormatter, opts)
+	}
+	if err != nil {
+		logger.Fatalf("Auto conversion failed: %v", err)
+	}
+
+	logger.Info(fmt.Sprintf("Auto conversion complete: %d converted, %d skipped, %d failed",
+		summary.Converted, summary.Skipped, summary.Failed))
+	if summary.Failed > 0 {
+		logger.Fatalf("%d of %d files failed to convert", summary.Failed, len(files))
+	}
+	checkUncategorized()
+}
+
+// InputFiles returns the files selected by inputPath: the files of a
+// directory, the matches of a glob pattern, or a single file. Hidden files
+// and subdirectories are left out; the result is sorted.
+func InputFiles(inputPath string) ([]string, error) {
+	var candidates []string
+	if batch.IsGlobPattern(inputPath) {
+		matches, err := filepath.Glob(inputPath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input pattern %q: %w", inputPath, err)
+		}
+		candidates = matches
+	} else {
+		info, err := os.Stat(inputPath)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			return []string{inputPath}, nil
+		}
+		entries, err := os.ReadDir(inputPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input directory: %w", err)
+		}
+		for _, entry := range entries {
+			candidates = append(candidates, filepath.Join(inputPath, entry.Name()))
+		}
+	}
+
+	var files []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(filepath.Base(candidate), ".") {
+			continue
+		}
+		if info, err := os.Stat(candidate); err != nil || info.IsDir() {
+			continue
+		}
+		files = append(files, candidate)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files found in %s", inputPath)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// ConvertEach converts each file with its detected parser to a CSV of the
+// same base name in outputDir. Files sharing a base name, such as stmt.xml and
+// stmt.pdf, keep their extension in the output name (stmt.xml.csv and
+// stmt.pdf.csv); files that would still be written to the same CSV, such as
+// two stmt.xml of different directories, are rejected before any conversion.
+func ConvertEach(ctx context.Context, files []string, lookup parserLookup, outputDir string,
+	logger logging.Logger, outFormatter formatter.OutputFormatter, opts common.OutputOptions) (Summary, error) {
+
+	outputNames, err := outputFileNames(files)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	if err := os.MkdirAll(outputDir, models.PermissionDirectory); err != nil {
+		return Summary{}, fmt.Errorf("error creating output directory: %w", err)
+	}
+
+	var summary Summary
+	err = eachStatement(ctx, files, lookup, logger, &summary, func(file string, transactions []models.Transaction) error {
+		base := filepath.Base(file)
+		outputFile := filepath.Join(outputDir, outputNames[file])
+		if err := common.WriteTransactions(transactions, outputFile, logger, outFormatter, opts); err != nil {
+			return fmt.Errorf("error writing CSV: %w", err)
+		}
+		parser.RecordCategorization(ctx, transactions)
+		logger.Info("Converted file",
+			logging.Field{Key: "file", Value: base},
+			logging.Field{Key: "output", Value: outputFile},
+			logging.Field{Key: "count", Value: len(transactions)})
+		return nil
+	})
+	return summary, err
+}
+
+// outputFileNames returns the name of the CSV that ConvertEach writes for each
+// file: its base name with a .csv extension, or with .csv appended when
+// another file has the same base name without extension. Names are compared
+// case-insensitively; an error is returned when two files still get the same
+// name.
+func outputFileNames(files []string) (map[string]string, error) {
+	stems := make(map[string]int, len(files))
+	for _, file := range files {
+		base := filepath.Base(file)
+		stems[strings.ToLower(strings.TrimSuffix(base, filepath.Ext(base)))]++
+	}
+
+	names := make(map[string]string, len(files))
+	owners := make(map[string]string, len(files))
+	for _, file := range files {
+		base := filepath.Base(file)
+		stem := strings.TrimSuffix(base, filepath.Ext(base))
+		name := stem + ".csv"
+		if stems[strings.ToLower(stem)] > 1 {
+			name = base + ".csv"
+		}
+		if other, ok := owners[strings.ToLower(name)]; ok {
+			return nil, fmt.Errorf("%s and %s would both be converted to %s", other, file, name)
+		}
+		owners[strings.ToLower(name)] = file
+		names[file] = name
+	}
+	return names, nil
+}
+
+// ConvertConsolidated converts all files with their detected parsers and
+// writes their transactions, in chronological order, to outputFile.
+func ConvertConsolidated(ctx context.Context, files []string, lookup parserLookup, outputFile string,
+	logger logging.Logger, outFormatter formatter.OutputFormatter, opts common.OutputOptions) (Summary, error) {
+
+	allTransactions, summary, err := ParseAll(ctx, files, lookup, logger)
+	if err != nil {
+		return summary, err
+	}
+	allTransactions = parser.LimitTransactions(ctx, allTransactions)
+
+	logger.Info("Writing consolidated transactions",
+		logging.Field{Key: "total_transactions", Value: len(allTransactions)},
+		logging.Field{Key: "output", Value: outputFile})
+	if err := common.WriteTransactions(allTransactions, outputFile, logger, outFormatter, opts); err != nil {
+		return summary, fmt.Errorf("failed to write CSV: %w", err)
+	}
+	parser.RecordCategorization(ctx, allTransactions)
+	return summary, nil
+}
+
+// ParseAll parses all files with their detected parsers and returns their
+// transactions in chronological order (see models.SortChronologically). It
+// fails when no file could be parsed.
+func ParseAll(ctx context.Context, files []string, lookup parserLookup,
+	logger logging.Logger) ([]models.Transaction, Summary, error) {
+
+	var summary Summary
+	var allTransactions []models.Transaction
+	err := eachStatement(ctx, files, lookup, logger, &summary, func(_ string, transactions []models.Transaction) error {
+		allTransactions = append(allTransactions, transactions...)
+		return nil
+	})
+	if err != nil {
+		return nil, summary, err
+	}
+	if summary.Converted == 0 {
+		return nil, summary, fmt.Errorf("no transactions extracted from %d files", len(files))
+	}
+
+	models.SortChronologically(allTransactions)
+	return allTransactions, summary, nil
+}
+
+// parserKey is the context key of the parser forced by WithParser.
+type parserKey struct{}
+
+// WithParser returns ctx making the auto conversion parse every file with the
+// parser registered under name instead of detecting its format, as --preset
+// does.
+func WithParser(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, parserKey{}, name)
+}
+
+// detectParser returns the parser forced by WithParser, or the one detected
+// from the content of file.
+func detectParser(ctx context.Context, file string) (string, error) {
+	if name, _ := ctx.Value(parserKey{}).(string); name != "" {
+		return name, nil
+	}
+	return detect.File(file)
+}
+
+// eachStatement detects and parses each file and passes its transactions to
+// handle. Unrecognized files, and files whose output exists under
+// --no-clobber, are skipped with a warning; files that fail to parse or to be
+// handled are counted as failed. Neither stops the loop.
+func eachStatement(ctx context.Context, files []string, lookup parserLookup, logger logging.Logger,
+	summary *Summary, handle func(file string, transactions []models.Transaction) error) error {
+
+	bar := progress.FromContext(ctx)
+	bar.Start(len(files), "files")
+	defer bar.Finish()
+	// The bar counts files; parsers must not restart it for their statements
+	ctx = progress.WithReporter(ctx, progress.Nop())
+
+	for _, file := range files {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		convertStatement(ctx, file, lookup, logger, summary, handle)
+		bar.Increment()
+	}
+	return nil
+}
+
+// convertStatement detects, parses and handles one file of eachStatement,
+// counting the outcome in summary.
+func convertStatement(ctx context.Context, file string, lookup parserLookup, logger logging.Logger,
+	summary *Summary, handle func(file string, transactions []models.Transaction) error) {
+
+	base := filepath.Base(file)
+	name, err := detectParser(ctx, file)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to read file", logging.Field{Key: "file", Value: base})
+		summary.Failed++
+		return
+	}
+	if name == "" {
+		logger.Warn("Skipping file: format not recognized", logging.Field{Key: "file", Value: base})
+		summary.Skipped++
+		return
+	}
+	logger.Debug("Detected statement format",
+		logging.Field{Key: "file", Value: base},
+		logging.Field{Key: "parser", Value: name})
+
+	p, err := lookup(name)
+	if err != nil {
+		logger.WithError(err).Warn("Skipping file: no parser available",
+			logging.Field{Key: "file", Value: base},
+			logging.Field{Key: "parser", Value: name})
+		summary.Skipped++
+		return
+	}
+
+	transactions, err := parseFile(ctx, p, file, logger)
+	if err == nil {
+		err = handle(file, transactions)
+	}
+	if errors.Is(err, common.ErrOutputExists) {
+		logger.Warn("Skipping file: output already exists",
+			logging.Field{Key: "file", Value: base})
+		summary.Skipped++
+		return
+	}
+	if err != nil {
+		logger.WithError(err).Warn("Failed to convert file",
+			logging.Field{Key: "file", Value: base},
+			logging.Field{Key: "parser", Value: name})
+		summary.Failed++
+		return
+	}
+	summary.Converted++
+}
+
+// parseFile parses file with p and applies the transaction limit and filter.
+func parseFile(ctx context.Context, p parser.FullParser, file string, logger logging.Logger) ([]models.Transaction, error) {
+	p.SetLogger(logger)
+
+	f, err := os.Open(file) // #nosec G304 -- CLI tool requires user-provided file paths
+	if err != nil {
+		return nil, fmt.Errorf("error opening input file: %w", err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			logger.WithError(err).Warn("Failed to close file")
+		}
+	}()
+
+	transactions, err := p.Parse(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing file: %w", err)
+	}
+	if err := parser.CheckTransactionLimit(ctx, len(transactions)); err != nil {
+		return nil, fmt.Errorf("error parsing file: %w", err)
+	}
+	parser.WarnEmptyTransactions(ctx, transactions, filepath.Base(file), logger)
+	return parser.FilterTransactions(ctx, transactions), nil
+}
@@ -0,0 +1,261 @@
+package auto_test
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fjacquet/camt-csv/cmd/auto"
+	"fjacquet/camt-csv/cmd/common"
+	"fjacquet/camt-csv/internal/formatter"
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/parser"
+	"fjacquet/camt-csv/internal/progress"
+
+	// Registers the built-in parsers
+	_ "fjacquet/camt-csv/internal/container"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registryLookup builds parsers from the parser registry, without categorizer.
+func registryLookup(logger logging.Logger) func(name string) (parser.FullParser, error) {
+	return func(name string) (parser.FullParser, error) {
+		factory, ok := parser.LookupParser(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown parser type: %s", name)
+		}
+		return factory(logger), nil
+	}
+}
+
+// copyFixture copies a parser test fixture into dir and returns its new path.
+func copyFixture(t *testing.T, fixture, dir string) string {
+	t.Helper()
+	data, err := os.ReadFile(fixture) // #nosec G304 -- test fixture path
+	require.NoError(t, err)
+	path := filepath.Join(dir, filepath.Base(fixture))
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+func readRows(t *testing.T, path string) [][]string {
+	t.Helper()
+	file, err := os.Open(path) // #nosec G304 -- test output path
+	require.NoError(t, err)
+	defer func() { _ = file.Close() }()
+	rows, err := csv.NewReader(file).ReadAll()
+	require.NoError(t, err)
+	return rows
+}
+
+func inputDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	copyFixture(t, "../../internal/camtparser/testdata/camt053_v08.xml", dir)
+	copyFixture(t, "../../internal/wiseparser/testdata/wise_statement.csv", dir)
+	copyFixture(t, "../../internal/mt940parser/testdata/postbank.sta", dir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("shopping list\n"), 0600))
+	return dir
+}
+
+func TestInputFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.csv", "a.xml", ".hidden.csv"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0600))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0750))
+
+	files, err := auto.InputFiles(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a.xml"), filepath.Join(dir, "b.csv")}, files)
+
+	files, err = auto.InputFiles(filepath.Join(dir, "*.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "b.csv")}, files)
+
+	files, err = auto.InputFiles(filepath.Join(dir, "a.xml"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a.xml")}, files)
+
+	_, err = auto.InputFiles(filepath.Join(dir, "sub"))
+	assert.Error(t, err)
+}
+
+func TestConvertEach(t *testing.T) {
+	logger := logging.NewMockLogger()
+	files, err := auto.InputFiles(inputDir(t))
+	require.NoError(t, err)
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	summary, err := auto.ConvertEach(context.Background(), files, registryLookup(logger), outputDir,
+		logger, formatter.NewStandardFormatter(), common.OutputOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, auto.Summary{Converted: 3, Skipped: 1}, summary)
+	for _, name := range []string{"camt053_v08.csv", "wise_statement.csv", "postbank.csv"} {
+		rows := readRows(t, filepath.Join(outputDir, name))
+		assert.Greater(t, len(rows), 1, name)
+	}
+	assert.NoFileExists(t, filepath.Join(outputDir, "notes.csv"))
+	assert.True(t, logger.HasEntry("WARN", "Skipping file: format not recognized"))
+}
+
+func TestConvertEach_SameBaseName(t *testing.T) {
+	logger := logging.NewMockLogger()
+	dir := t.TempDir()
+	for fixture, name := range map[string]string{
+		"../../internal/camtparser/testdata/camt053_v08.xml":    "statement.xml",
+		"../../internal/wiseparser/testdata/wise_statement.csv": "statement.csv",
+		"../../internal/mt940parser/testdata/postbank.sta":      "postbank.sta",
+	} {
+		data, err := os.ReadFile(fixture) // #nosec G304 -- test fixture path
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0600))
+	}
+	files, err := auto.InputFiles(dir)
+	require.NoError(t, err)
+	outputDir := t.TempDir()
+
+	// Files sharing a base name keep their extension instead of overwriting
+	// each other's output
+	summary, err := auto.ConvertEach(context.Background(), files, registryLookup(logger), outputDir,
+		logger, formatter.NewStandardFormatter(), common.OutputOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, auto.Summary{Converted: 3}, summary)
+	for _, name := range []string{"statement.xml.csv", "statement.csv.csv", "postbank.csv"} {
+		assert.Greater(t, len(readRows(t, filepath.Join(outputDir, name))), 1, name)
+	}
+	assert.NoFileExists(t, filepath.Join(outputDir, "statement.csv"))
+
+	// The same file name in two directories cannot be told apart
+	other := copyFixture(t, "../../internal/camtparser/testdata/camt053_v08.xml", t.TempDir())
+	renamed := filepath.Join(filepath.Dir(other), "statement.xml")
+	require.NoError(t, os.Rename(other, renamed))
+	outputDir = filepath.Join(t.TempDir(), "out")
+	_, err = auto.ConvertEach(context.Background(), append(files, renamed), registryLookup(logger), outputDir,
+		logger, formatter.NewStandardFormatter(), common.OutputOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "statement.xml.csv")
+	assert.NoDirExists(t, outputDir)
+}
+
+func TestConvertConsolidated(t *testing.T) {
+	logger := logging.NewMockLogger()
+	files, err := auto.InputFiles(inputDir(t))
+	require.NoError(t, err)
+	outputFile := filepath.Join(t.TempDir(), "all.csv")
+
+	summary, err := auto.ConvertConsolidated(context.Background(), files, registryLookup(logger), outputFile,
+		logger, formatter.NewStandardFormatter(), common.OutputOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, auto.Summary{Converted: 3, Skipped: 1}, summary)
+
+	// Same transactions as the per-file outputs, under a single header
+	outputDir := t.TempDir()
+	_, err = auto.ConvertEach(context.Background(), files, registryLookup(logger), outputDir,
+		logger, formatter.NewStandardFormatter(), common.OutputOptions{})
+	require.NoError(t, err)
+	total := 0
+	for _, name := range []string{"camt053_v08.csv", "wise_statement.csv", "postbank.csv"} {
+		total += len(readRows(t, filepath.Join(outputDir, name))) - 1
+	}
+	assert.Equal(t, total, len(readRows(t, outputFile))-1)
+}
+
+func TestConvertConsolidated_NothingRecognized(t *testing.T) {
+	logger := logging.NewMockLogger()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(path, []byte("shopping list\n"), 0600))
+
+	summary, err := auto.ConvertConsolidated(context.Background(), []string{path}, registryLookup(logger),
+		filepath.Join(dir, "all.csv"), logger, formatter.NewStandardFormatter(), common.OutputOptions{})
+	assert.Error(t, err)
+	assert.Equal(t, auto.Summary{Skipped: 1}, summary)
+}
+
+// outputCounter records, at each progress increment, how many CSV files
+// have been written to dir.
+type outputCounter struct {
+	dir     string
+	written []int
+}
+
+func (r *outputCounter) Start(int, string) {}
+func (r *outputCounter) Finish()           {}
+func (r *outputCounter) Increment() {
+	files, _ := filepath.Glob(filepath.Join(r.dir, "*.csv"))
+	r.written = append(r.written, len(files))
+}
+
+func TestConvertEach_ProgressAfterEachFile(t *testing.T) {
+	logger := logging.NewMockLogger()
+	files, err := auto.InputFiles(inputDir(t))
+	require.NoError(t, err)
+	outputDir := t.TempDir()
+
+	reporter := &outputCounter{dir: outputDir}
+	ctx := progress.WithReporter(context.Background(), reporter)
+	_, err = auto.ConvertEach(ctx, files, registryLookup(logger), outputDir,
+		logger, formatter.NewStandardFormatter(), common.OutputOptions{})
+	require.NoError(t, err)
+
+	// camt053_v08.xml, notes.txt (skipped), postbank.sta, wise_statement.csv
+	assert.Equal(t, []int{1, 1, 2, 3}, reporter.written, "a file counts once its output is written")
+}
+
+func TestConvertEach_ParseFailure(t *testing.T) {
+	logger := logging.NewMockLogger()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "broken.xml")
+	require.NoError(t, os.WriteFile(path,
+		[]byte(`<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08"><BkToCstmrStmt>`), 0600))
+
+	summary, err := auto.ConvertEach(context.Background(), []string{path}, registryLookup(logger),
+		filepath.Join(dir, "out"), logger, formatter.NewStandardFormatter(), common.OutputOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, auto.Summary{Failed: 1}, summary)
+}
+
+func TestConvertEach_NoClobber(t *testing.T) {
+	logger := logging.NewMockLogger()
+	files, err := auto.InputFiles(inputDir(t))
+	require.NoError(t, err)
+	outputDir := t.TempDir()
+	existing := filepath.Join(outputDir, "wise_statement.csv")
+	require.NoError(t, os.WriteFile(existing, []byte("keep me\n"), 0600))
+
+	summary, err := auto.ConvertEach(context.Background(), files, registryLookup(logger), outputDir,
+		logger, formatter.NewStandardFormatter(), common.OutputOptions{NoClobber: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, auto.Summary{Converted: 2, Skipped: 2}, summary)
+	assert.True(t, logger.HasEntry("WARN", "Skipping file: output already exists"))
+	data, err := os.ReadFile(existing) // #nosec G304 -- test output path
+	require.NoError(t, err)
+	assert.Equal(t, "keep me\n", string(data))
+}
+
+func TestConvertEach_WithParser(t *testing.T) {
+	logger := logging.NewMockLogger()
+	dir := t.TempDir()
+	files := []string{
+		copyFixture(t, "../../internal/camtparser/testdata/camt053_v08.xml", dir),
+		copyFixture(t, "../../internal/wiseparser/testdata/wise_statement.csv", dir),
+	}
+	outputDir := filepath.Join(dir, "out")
+
+	// Every file is parsed with the forced parser, without detection
+	ctx := auto.WithParser(context.Background(), "camt")
+	summary, err := auto.ConvertEach(ctx, files, registryLookup(logger), outputDir,
+		logger, formatter.NewStandardFormatter(), common.OutputOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, auto.Summary{Converted: 1, Failed: 1}, summary)
+	assert.FileExists(t, filepath.Join(outputDir, "camt053_v08.csv"))
+	assert.NoFileExists(t, filepath.Join(outputDir, "wise_statement.csv"))
+}
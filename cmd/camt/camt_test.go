@@ -35,6 +35,11 @@ func TestCamtCommand_HelpText(t *testing.T) {
 	assert.Contains(t, camt.Cmd.Short, "CAMT.053")
 }
 
+func TestCamtCommand_RejectsInputEncoding(t *testing.T) {
+	assert.Nil(t, camt.Cmd.Flags().Lookup("input-encoding"),
+		"CAMT XML is decoded according to its own declaration")
+}
+
 func TestCamtCommand_Run_EmptyInput(t *testing.T) {
 	// Save original values
 	originalInput := root.SharedFlags.Input
@@ -18,4 +18,18 @@ var Cmd = &cobra.Command{
 	},
 }
 
-func init() { common.RegisterFormatFlags(Cmd) }
+func init() {
+	common.RegisterFormatFlags(Cmd)
+	common.RegisterLimitFlags(Cmd)
+	// No --input-encoding: the XML decoder takes the encoding from the
+	// <?xml encoding=...?> declaration
+	common.RegisterFilterFlags(Cmd)
+	common.RegisterAccountFlag(Cmd)
+	common.RegisterOutputDirFlag(Cmd)
+	common.RegisterUncategorizedFlag(Cmd)
+	common.RegisterNoClobberFlag(Cmd)
+	common.RegisterRecursiveFlag(Cmd)
+	common.RegisterWorkbookFlag(Cmd)
+	common.RegisterAppendFlags(Cmd)
+	common.RegisterCategorizeFlag(Cmd)
+}
@@ -0,0 +1,99 @@
+// Package categories handles the commands that inspect the category configuration
+package categories
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"fjacquet/camt-csv/cmd/root"
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Cmd represents the categories command
+var Cmd = &cobra.Command{
+	Use:   "categories",
+	Short: "Inspect the configured categories",
+	Long:  `Inspect the categories loaded from the categories YAML file.`,
+	// Nothing is categorized, so there are no mappings to save
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {},
+}
+
+// listCmd represents the categories list command
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the configured categories",
+	Long: `List the categories of the categories YAML file, in file order, which is
+also the order in which their keywords are matched.
+
+The table format prints the names, and the keywords with --keywords; the json
+and yaml formats print the names with their keywords.`,
+	Args: cobra.NoArgs,
+	Run:  listFunc,
+}
+
+func init() {
+	listCmd.Flags().String("format", "table", "Output format: table, json or yaml")
+	listCmd.Flags().Bool("keywords", false, "Table format: add a column with each category's keywords")
+	Cmd.AddCommand(listCmd)
+}
+
+func listFunc(cmd *cobra.Command, _ []string) {
+	logger := root.GetLogrusAdapter()
+	format, _ := cmd.Flags().GetString("format")
+	keywords, _ := cmd.Flags().GetBool("keywords")
+
+	appContainer := root.GetContainer()
+	if appContainer == nil {
+		logger.Fatal("Container not initialized")
+	}
+	categories, err := appContainer.GetCategories()
+	if err != nil {
+		logger.Fatalf("Error loading categories: %v", err)
+	}
+	if err := printCategories(cmd.OutOrStdout(), categories, format, keywords); err != nil {
+		logger.Fatalf("Error listing categories: %v", err)
+	}
+}
+
+// printCategories writes categories to w in the given format.
+func printCategories(w io.Writer, categories []models.CategoryConfig, format string, keywords bool) error {
+	switch format {
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		if keywords {
+			_, _ = fmt.Fprintln(tw, "NAME\tKEYWORDS")
+		} else {
+			_, _ = fmt.Fprintln(tw, "NAME")
+		}
+		for _, category := range categories {
+			if keywords {
+				_, _ = fmt.Fprintf(tw, "%s\t%s\n", category.Name, strings.Join(category.Keywords, ", "))
+			} else {
+				_, _ = fmt.Fprintln(tw, category.Name)
+			}
+		}
+		return tw.Flush()
+	case "json":
+		if categories == nil {
+			categories = []models.CategoryConfig{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(categories)
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(map[string][]models.CategoryConfig{"categories": categories}); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("invalid --format %q: valid formats are table, json, yaml", format)
+	}
+}
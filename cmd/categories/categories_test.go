@@ -0,0 +1,69 @@
+package categories
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+var testCategories = []models.CategoryConfig{
+	{Name: "Courses", Keywords: []string{"migros", "coop"}},
+	{Name: "Transports", Keywords: []string{"sbb"}},
+}
+
+func TestCategoriesCommand_Metadata(t *testing.T) {
+	assert.Equal(t, "categories", Cmd.Use)
+	list, _, err := Cmd.Find([]string{"list"})
+	require.NoError(t, err)
+	assert.Equal(t, "list", list.Use)
+	assert.Equal(t, "table", list.Flags().Lookup("format").DefValue)
+	assert.NotNil(t, list.Flags().Lookup("keywords"))
+}
+
+func TestPrintCategories(t *testing.T) {
+	t.Run("table", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, printCategories(&out, testCategories, "table", false))
+		assert.Equal(t, "NAME\nCourses\nTransports\n", out.String())
+	})
+
+	t.Run("table with keywords", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, printCategories(&out, testCategories, "table", true))
+		assert.Contains(t, out.String(), "NAME        KEYWORDS\n")
+		assert.Contains(t, out.String(), "Courses     migros, coop\n")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, printCategories(&out, testCategories, "json", false))
+		var decoded []models.CategoryConfig
+		require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+		assert.Equal(t, testCategories, decoded)
+
+		out.Reset()
+		require.NoError(t, printCategories(&out, nil, "json", false))
+		assert.Equal(t, "[]\n", out.String())
+	})
+
+	t.Run("yaml reads back as a categories file", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, printCategories(&out, testCategories, "yaml", false))
+		var decoded struct {
+			Categories []models.CategoryConfig `yaml:"categories"`
+		}
+		require.NoError(t, yaml.Unmarshal(out.Bytes(), &decoded))
+		assert.Equal(t, testCategories, decoded.Categories)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		err := printCategories(&bytes.Buffer{}, testCategories, "xml", false)
+		assert.ErrorContains(t, err, `invalid --format "xml"`)
+	})
+}
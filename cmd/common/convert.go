@@ -3,8 +3,10 @@ package common
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"fjacquet/camt-csv/cmd/root"
 	"fjacquet/camt-csv/internal/batch"
@@ -12,18 +14,21 @@ import (
 	"fjacquet/camt-csv/internal/formatter"
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/parser"
+	"fjacquet/camt-csv/internal/progress"
 
 	"github.com/spf13/cobra"
 )
 
-// osExitFn is the function used to exit the process. Replaced in tests to avoid os.Exit.
-var osExitFn = os.Exit
+// osExitFn is the function used to exit the process, saving the learned
+// category mappings first. Replaced in tests to avoid os.Exit.
+var osExitFn = root.Exit
 
 // RunConvert is the shared handler for all convert commands.
 // It handles: get logger, get container, get parser, stat input, branch to batch or single-file.
-// When input is a directory:
+// When input is a directory or a ZIP archive:
 //   - If --output is not set, it logs a fatal error and exits.
 //   - If --output is set, it delegates to FolderConvert (modern BatchProcessor path).
+//     --output-dir may be used instead of --output.
 func RunConvert(cmd *cobra.Command, _ []string, parserType container.ParserType, name string) {
 	ctx := cmd.Context()
 	logger := root.GetLogrusAdapter()
@@ -37,6 +42,34 @@ func RunConvert(cmd *cobra.Command, _ []string, parserType container.ParserType,
 
 	format, _ := cmd.Flags().GetString("format")
 	dateFormat, _ := cmd.Flags().GetString("date-format")
+	opts, err := ParseOutputOptions(cmd, logger)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+	ctx, err = WithTransactionLimit(ctx, cmd)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+	ctx, err = WithInputEncoding(ctx, cmd)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+	ctx, err = WithNumberFormat(ctx, cmd)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+	ctx, err = WithAccount(ctx, cmd)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+	ctx, err = WithTransactionFilter(ctx, cmd)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+	ctx, checkUncategorized, err := WithUncategorizedCheck(ctx, cmd, logger)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
 
 	appContainer := root.GetContainer()
 	if appContainer == nil {
@@ -46,26 +79,80 @@ func RunConvert(cmd *cobra.Command, _ []string, parserType container.ParserType,
 	if format == "" {
 		format = appContainer.GetConfig().Output.Format
 	}
+	if err := ValidateWorkbookFormat(format, opts.SingleWorkbook); err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
 
 	p, err := appContainer.GetParser(parserType)
 	if err != nil {
 		logger.Fatalf("Error getting %s parser: %v", name, err)
 	}
+	ApplyCategorizeFlag(cmd, p, logger)
 
+	batchInput := IsBatchInput(inputPath, logger)
+	if err := ValidateLedgerFormat(format, batchInput, opts); err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+	if batchInput {
+		// Batch output is already a directory of files named from their inputs
+		if opts.OutputDir != "" {
+			outputPath = opts.OutputDir
+		}
+		if outputPath == "" {
+			logger.Fatal("--output flag is required when processing a folder or zip archive. Use -o or --output to specify the output directory.")
+		}
+		if opts.SingleWorkbook {
+			WorkbookConvert(ctx, p, inputPath, outputPath, logger, opts)
+		} else {
+			FolderConvert(ctx, p, inputPath, outputPath, logger, format, dateFormat, opts)
+		}
+	} else {
+		if opts.SingleWorkbook {
+			logger.Fatal("--single-workbook requires a folder, ZIP archive or glob pattern as input")
+		}
+		ctx = progress.WithReporter(ctx, NewProgress("Parsing"))
+		ProcessFile(ctx, p, inputPath, outputPath, root.SharedFlags.Validate, root.Log, appContainer, format, dateFormat, opts)
+		root.Log.Info(name + " to CSV conversion completed successfully!")
+	}
+	checkUncategorized()
+}
+
+// IsBatchInput reports whether inputPath selects batch mode: a directory, a
+// ZIP archive or a glob pattern. It exits when the path cannot be accessed.
+func IsBatchInput(inputPath string, logger logging.Logger) bool {
+	if batch.IsGlobPattern(inputPath) {
+		return true
+	}
 	fileInfo, err := os.Stat(inputPath)
 	if err != nil {
 		logger.Fatalf("Error accessing input path: %v", err)
+		return false // unreachable in production (logger.Fatal exits), but enables testing with mock logger
 	}
+	return fileInfo.IsDir() || batch.IsZipArchive(inputPath)
+}
 
-	if fileInfo.IsDir() {
-		if outputPath == "" {
-			logger.Fatal("--output flag is required when processing a folder. Use -o or --output to specify the output directory.")
+// NewProgress returns a terminal progress bar labelled label, or a no-op
+// reporter when output is not a terminal, --quiet is set, or logs are JSON.
+// While the bar is shown, log lines are printed above it instead of being
+// written into it.
+func NewProgress(label string) progress.Reporter {
+	logFormat := ""
+	if root.AppConfig != nil {
+		logFormat = root.AppConfig.Log.Format
+	}
+	reporter := progress.New(label, root.SharedFlags.Quiet, logFormat)
+	if bar, ok := reporter.(*progress.Bar); ok {
+		if logger, ok := root.Log.(logOutputSetter); ok {
+			logger.SetOutput(bar.Writer(os.Stderr))
 		}
-		FolderConvert(ctx, p, inputPath, outputPath, logger, format, dateFormat)
-	} else {
-		ProcessFile(ctx, p, inputPath, outputPath, root.SharedFlags.Validate, root.Log, appContainer, format, dateFormat)
-		root.Log.Info(name + " to CSV conversion completed successfully!")
 	}
+	return reporter
+}
+
+// logOutputSetter is implemented by loggers whose output can be redirected,
+// such as logging.LogrusAdapter.
+type logOutputSetter interface {
+	SetOutput(w io.Writer)
 }
 
 // FolderConvert processes all files in a directory using the modern BatchProcessor with formatter support.
@@ -75,12 +162,13 @@ func RunConvert(cmd *cobra.Command, _ []string, parserType container.ParserType,
 // Parameters:
 //   - ctx: context for cancellation
 //   - p: parser (must implement parser.FullParser)
-//   - inputDir: path to directory containing input files
+//   - inputDir: path to directory containing input files, or to a ZIP archive
 //   - outputDir: path to output directory (will be created if absent)
 //   - logger: structured logger
 //   - format: output format name ("standard" or "icompta")
 //   - dateFormat: date format string (reserved for future use)
-func FolderConvert(ctx context.Context, p any, inputDir, outputDir string, logger logging.Logger, format string, _ string) {
+//   - opts: output options (e.g. include time of day, no-clobber)
+func FolderConvert(ctx context.Context, p any, inputDir, outputDir string, logger logging.Logger, format string, _ string, opts OutputOptions) {
 	// Resolve formatter
 	formatterReg := formatter.NewFormatterRegistry()
 	outFormatter, err := formatterReg.Get(format)
@@ -88,6 +176,7 @@ func FolderConvert(ctx context.Context, p any, inputDir, outputDir string, logge
 		logger.Fatalf("Invalid output format '%s': valid formats are standard, icompta, jumpsoft", format)
 		return // unreachable in production (logger.Fatal exits), but enables testing with mock logger
 	}
+	outFormatter = formatter.ApplyOptions(outFormatter, opts.Options)
 
 	// Assert parser to FullParser
 	fullParser, ok := p.(parser.FullParser)
@@ -98,6 +187,10 @@ func FolderConvert(ctx context.Context, p any, inputDir, outputDir string, logge
 
 	// Create and run the batch processor
 	processor := batch.NewBatchProcessor(fullParser, logger, outFormatter)
+	processor.SetNoClobber(opts.NoClobber)
+	processor.SetBOM(opts.BOM)
+	processor.SetRecursive(opts.Recursive)
+	processor.SetProgress(NewProgress("Converting"))
 
 	manifest, err := processor.ProcessDirectory(ctx, inputDir, outputDir)
 	if err != nil {
@@ -111,9 +204,21 @@ func FolderConvert(ctx context.Context, p any, inputDir, outputDir string, logge
 		logger.WithError(err).Warn("Failed to write manifest")
 	}
 
+	for _, result := range manifest.Results {
+		for _, note := range result.StatementNotes {
+			logger.Info("Statement note",
+				logging.Field{Key: "file", Value: result.FileName},
+				logging.Field{Key: "note", Value: note})
+		}
+	}
+
 	logger.Info(fmt.Sprintf("Batch complete: %d/%d files succeeded",
 		manifest.SuccessCount, manifest.TotalFiles))
 
+	if manifest.SkippedCount > 0 {
+		logger.Warn(fmt.Sprintf("%d files skipped because their output already exists",
+			manifest.SkippedCount))
+	}
 	if manifest.FailureCount > 0 {
 		logger.Warn(fmt.Sprintf("%d files failed (see %s for details)",
 			manifest.FailureCount, manifestPath))
@@ -123,3 +228,113 @@ func FolderConvert(ctx context.Context, p any, inputDir, outputDir string, logge
 		osExitFn(manifest.ExitCode())
 	}
 }
+
+// ValidateWorkbookFormat checks that the xlsx format and --single-workbook
+// are used together: XLSX output is only written as a single batch workbook.
+func ValidateWorkbookFormat(format string, singleWorkbook bool) error {
+	switch {
+	case format == FormatXLSX && !singleWorkbook:
+		return fmt.Errorf("--format %s requires --single-workbook", FormatXLSX)
+	case singleWorkbook && format != FormatXLSX:
+		return fmt.Errorf("--single-workbook requires --format %s", FormatXLSX)
+	}
+	return nil
+}
+
+// ValidateLedgerFormat checks that the ledger format is used for a single
+// journal file: it cannot be written per batch file, appended to, split or
+// chunked, and a journal has no byte order mark.
+func ValidateLedgerFormat(format string, batchInput bool, opts OutputOptions) error {
+	if format != FormatLedger {
+		return nil
+	}
+	switch {
+	case batchInput:
+		return fmt.Errorf("--format %s requires a single input file", FormatLedger)
+	case opts.Append:
+		return fmt.Errorf("--format %s cannot be combined with --append", FormatLedger)
+	case opts.Split != "":
+		return fmt.Errorf("--format %s cannot be combined with --split", FormatLedger)
+	case opts.ChunkSize > 0:
+		return fmt.Errorf("--format %s cannot be combined with --chunk-size", FormatLedger)
+	case opts.BOM:
+		return fmt.Errorf("--format %s cannot be combined with --bom", FormatLedger)
+	}
+	return nil
+}
+
+// WorkbookOutputPath returns the workbook written for --single-workbook:
+// output itself when it ends in .xlsx, otherwise a file in the output
+// directory named after the input directory.
+func WorkbookOutputPath(output, input string) string {
+	if strings.EqualFold(filepath.Ext(output), ".xlsx") {
+		return output
+	}
+	name := "statements"
+	if !batch.IsGlobPattern(input) {
+		base := filepath.Base(filepath.Clean(input))
+		name = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	return filepath.Join(output, name+".xlsx")
+}
+
+// WorkbookConvert converts the files of a directory into one XLSX workbook
+// with a sheet per account, in the standard columns configured by opts.
+// It exits with the manifest's exit code when files failed.
+func WorkbookConvert(ctx context.Context, p any, inputDir, output string, logger logging.Logger, opts OutputOptions) {
+	fullParser, ok := p.(parser.FullParser)
+	if !ok {
+		logger.Fatal("Parser does not support batch conversion")
+		return // unreachable in production, but enables testing with mock logger
+	}
+	outFormatter := formatter.ApplyOptions(formatter.NewStandardFormatter(), opts.Options)
+
+	processor := batch.NewBatchProcessor(fullParser, logger, outFormatter)
+	processor.SetNoClobber(opts.NoClobber)
+	processor.SetBOM(opts.BOM)
+	processor.SetRecursive(opts.Recursive)
+	processor.SetStrict(opts.Strict)
+	processor.SetProgress(NewProgress("Converting"))
+
+	outputFile := WorkbookOutputPath(output, inputDir)
+	manifest, err := processor.ProcessWorkbook(ctx, inputDir, outputFile)
+	if err != nil {
+		logger.WithError(err).Fatal("Workbook conversion failed")
+		return
+	}
+
+	logger.Info(fmt.Sprintf("Workbook complete: %d/%d files succeeded, written to %s",
+		manifest.SuccessCount, manifest.TotalFiles, outputFile))
+	if manifest.FailureCount > 0 {
+		logger.Warn(fmt.Sprintf("%d files failed (see %s for details)",
+			manifest.FailureCount, filepath.Join(filepath.Dir(outputFile), ".manifest.json")))
+	}
+
+	if manifest.ExitCode() != 0 {
+		osExitFn(manifest.ExitCode())
+	}
+}
+
+// NewConvertCommand builds a generic convert command for a parser registered
+// with parser.RegisterParser. It is used for parsers that have no dedicated
+// command package, such as custom parsers registered from a wrapper main.
+func NewConvertCommand(name string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   name,
+		Short: fmt.Sprintf("Convert %s statements to CSV", name),
+		Long:  fmt.Sprintf("Convert %s statements to CSV format using the registered %q parser.", name, name),
+		Run: func(cmd *cobra.Command, args []string) {
+			RunConvert(cmd, args, container.ParserType(name), name)
+		},
+	}
+	RegisterFormatFlags(cmd)
+	RegisterLimitFlags(cmd)
+	RegisterInputEncodingFlag(cmd)
+	RegisterFilterFlags(cmd)
+	RegisterOutputDirFlag(cmd)
+	RegisterUncategorizedFlag(cmd)
+	RegisterNoClobberFlag(cmd)
+	RegisterRecursiveFlag(cmd)
+	RegisterWorkbookFlag(cmd)
+	return cmd
+}
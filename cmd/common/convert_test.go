@@ -2,7 +2,9 @@ package common_test
 
 import (
 	"context"
+	"errors"
 	"io"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -62,7 +64,7 @@ func TestRunConvert_FolderWithoutOutput(t *testing.T) {
 	// Passing a non-FullParser (plain struct) triggers the guard in FolderConvert
 	// ("Parser does not support batch conversion")
 	type notAParser struct{}
-	common.FolderConvert(context.Background(), notAParser{}, inputDir, outputDir, mockLogger, "standard", "")
+	common.FolderConvert(context.Background(), notAParser{}, inputDir, outputDir, mockLogger, "standard", "", common.OutputOptions{})
 
 	fatalEntries := mockLogger.GetEntriesByLevel("FATAL")
 	require.NotEmpty(t, fatalEntries, "expected at least one FATAL log entry")
@@ -94,7 +96,7 @@ func TestFolderConvert_EmptyDirectory(t *testing.T) {
 	restore := common.SetOsExitFn(func(code int) { capturedExitCode = code })
 	defer restore()
 
-	common.FolderConvert(context.Background(), mockParser, inputDir, outputDir, mockLogger, "standard", "")
+	common.FolderConvert(context.Background(), mockParser, inputDir, outputDir, mockLogger, "standard", "", common.OutputOptions{})
 
 	// No FATAL entries — the exit is via osExitFn, not logger.Fatal
 	fatalEntries := mockLogger.GetEntriesByLevel("FATAL")
@@ -121,7 +123,7 @@ func TestFolderConvert_InvalidFormat(t *testing.T) {
 	restore := common.SetOsExitFn(func(_ int) {})
 	defer restore()
 
-	common.FolderConvert(context.Background(), mockParser, inputDir, outputDir, mockLogger, "invalid", "")
+	common.FolderConvert(context.Background(), mockParser, inputDir, outputDir, mockLogger, "invalid", "", common.OutputOptions{})
 
 	fatalEntries := mockLogger.GetEntriesByLevel("FATAL")
 	require.NotEmpty(t, fatalEntries, "expected a FATAL log entry for invalid format")
@@ -130,3 +132,93 @@ func TestFolderConvert_InvalidFormat(t *testing.T) {
 	found := mockLogger.VerifyFatalLog("invalid") || mockLogger.VerifyFatalLog("format")
 	assert.True(t, found, "expected FATAL message mentioning 'invalid' or 'format', got: %v", fatalEntries)
 }
+
+func TestNewConvertCommand(t *testing.T) {
+	cmd := common.NewConvertCommand("mybank")
+
+	assert.Equal(t, "mybank", cmd.Use)
+	assert.Contains(t, cmd.Short, "mybank")
+	assert.NotNil(t, cmd.Run)
+	assert.NotNil(t, cmd.Flags().Lookup("format"))
+	assert.NotNil(t, cmd.Flags().Lookup("date-format"))
+}
+
+// failingContentParser accepts every file and fails to parse files whose content is "bad".
+type failingContentParser struct {
+	convertMockParser
+}
+
+func (m *failingContentParser) ValidateFormat(_ string) (bool, error) {
+	return true, nil
+}
+
+func (m *failingContentParser) Parse(_ context.Context, r io.Reader) ([]models.Transaction, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if string(data) == "bad" {
+		return nil, errors.New("corrupt statement")
+	}
+	return []models.Transaction{{Description: string(data), Currency: "CHF"}}, nil
+}
+
+// TestFolderConvert_PartialFailure verifies that one failing file does not stop the
+// batch: the good files are still written and the process exits with code 1.
+func TestFolderConvert_PartialFailure(t *testing.T) {
+	mockLogger := logging.NewMockLogger()
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(inputDir, "good.csv"), []byte("good"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(inputDir, "bad.csv"), []byte("bad"), 0600))
+
+	var capturedExitCode int
+	restore := common.SetOsExitFn(func(code int) { capturedExitCode = code })
+	defer restore()
+
+	common.FolderConvert(context.Background(), &failingContentParser{}, inputDir, outputDir, mockLogger, "standard", "", common.OutputOptions{})
+
+	assert.Equal(t, 1, capturedExitCode, "expected exit code 1 for partial failure")
+	assert.FileExists(t, filepath.Join(outputDir, "good.csv"))
+	assert.NoFileExists(t, filepath.Join(outputDir, "bad.csv"))
+}
+
+func TestValidateWorkbookFormat(t *testing.T) {
+	assert.NoError(t, common.ValidateWorkbookFormat("xlsx", true))
+	assert.NoError(t, common.ValidateWorkbookFormat("standard", false))
+	assert.ErrorContains(t, common.ValidateWorkbookFormat("xlsx", false), "--single-workbook")
+	assert.ErrorContains(t, common.ValidateWorkbookFormat("standard", true), "--format xlsx")
+}
+
+func TestValidateLedgerFormat(t *testing.T) {
+	assert.NoError(t, common.ValidateLedgerFormat("ledger", false, common.OutputOptions{}))
+	assert.NoError(t, common.ValidateLedgerFormat("standard", true, common.OutputOptions{Append: true}))
+	assert.ErrorContains(t, common.ValidateLedgerFormat("ledger", true, common.OutputOptions{}), "single input file")
+	assert.ErrorContains(t, common.ValidateLedgerFormat("ledger", false, common.OutputOptions{Append: true}), "--append")
+	assert.ErrorContains(t, common.ValidateLedgerFormat("ledger", false, common.OutputOptions{Split: "by-party-iban"}), "--split")
+	assert.ErrorContains(t, common.ValidateLedgerFormat("ledger", false, common.OutputOptions{ChunkSize: 10}), "--chunk-size")
+	assert.ErrorContains(t, common.ValidateLedgerFormat("ledger", false, common.OutputOptions{BOM: true}), "--bom")
+}
+
+func TestWorkbookOutputPath(t *testing.T) {
+	assert.Equal(t, filepath.Join("out", "all.xlsx"), common.WorkbookOutputPath(filepath.Join("out", "all.xlsx"), "statements"))
+	assert.Equal(t, filepath.Join("out", "statements.xlsx"), common.WorkbookOutputPath("out", filepath.Join("in", "statements")+"/"))
+	assert.Equal(t, filepath.Join("out", "export.xlsx"), common.WorkbookOutputPath("out", "export.zip"))
+	assert.Equal(t, filepath.Join("out", "statements.xlsx"), common.WorkbookOutputPath("out", "in/*/2025-*.xml"))
+}
+
+func TestWorkbookConvert_EmptyDirectory(t *testing.T) {
+	mockLogger := logging.NewMockLogger()
+	outputDir := t.TempDir()
+
+	var capturedExitCode int
+	restore := common.SetOsExitFn(func(code int) { capturedExitCode = code })
+	defer restore()
+
+	common.WorkbookConvert(context.Background(), &convertMockParser{}, t.TempDir(), outputDir, mockLogger, common.OutputOptions{SingleWorkbook: true})
+
+	assert.Empty(t, mockLogger.GetEntriesByLevel("FATAL"))
+	assert.Equal(t, 2, capturedExitCode, "no file converted")
+	assert.FileExists(t, filepath.Join(outputDir, ".manifest.json"))
+}
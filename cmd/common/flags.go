@@ -1,12 +1,515 @@
 // Package common contains shared functionality for command handlers
 package common
 
-import "github.com/spf13/cobra"
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
 
-// RegisterFormatFlags adds --format and --date-format flags to a command.
+	"fjacquet/camt-csv/cmd/root"
+	internalcommon "fjacquet/camt-csv/internal/common"
+	"fjacquet/camt-csv/internal/container"
+	"fjacquet/camt-csv/internal/currency"
+	"fjacquet/camt-csv/internal/formatter"
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parser"
+
+	"github.com/spf13/cobra"
+)
+
+// RegisterFormatFlags adds the output format flags (--format, --profile, --columns, --date-format, --locale,
+// --with-time, --signed-amount, --category-source, --tags, --sequence, --party-bic, --creditor-reference,
+// --reference-type, --card, --mcc, --base-currency, --rates, --description-template, --sort and --bom) to a command.
 func RegisterFormatFlags(cmd *cobra.Command) {
 	cmd.Flags().StringP("format", "f", "",
-		"Output format: icompta (iCompta-compatible), standard (29-column comma-delimited CSV), or jumpsoft (7-column Jumpsoft Money CSV). Default: icompta (overridable via CAMT_OUTPUT_FORMAT env var)")
+		"Output format: icompta (iCompta-compatible), standard (29-column comma-delimited CSV), jumpsoft (7-column Jumpsoft Money CSV), ledger (hledger journal), or xlsx (batch workbook, with --single-workbook). Default: icompta (overridable via CAMT_OUTPUT_FORMAT env var)")
+	cmd.Flags().String("profile", "",
+		"Export profile from the profiles file (e.g. default, erp); selects the columns and sign convention and overrides --format")
+	cmd.Flags().String("columns", "",
+		"Comma-separated standard column names to write, in order (e.g. Date,Amount,Currency,Name); overrides --format and the columns of --profile")
+	cmd.Flags().String("description-template", "",
+		"Build each description from transaction fields, e.g. \"{PartyName} - {RemittanceInfo} ({Reference})\"; empty fields are left out with their separators. Default: output.description_template, else each parser's description")
+	cmd.Flags().String("sort", "",
+		"Order rows by comma-separated keys, each prefixed with - for descending: date, value-date, amount, currency, category, party, description (e.g. category,-amount). Default: input order, chronological for consolidated output")
 	cmd.Flags().String("date-format", "DD.MM.YYYY",
 		"Date format in output: DD.MM.YYYY, YYYY-MM-DD, MM/DD/YYYY, etc. (Go layout: 02.01.2006, 2006-01-02, 01/02/2006)")
+	cmd.Flags().String("locale", "",
+		"Number and date formatting of the standard format and profiles, e.g. de-DE (comma decimal), en-US (MM/DD/YYYY); default: dot decimal and DD.MM.YYYY")
+	cmd.Flags().Bool("with-time", false,
+		"Include the time of day in date columns (DD.MM.YYYY HH:MM) when the source provides it")
+	cmd.Flags().Bool("signed-amount", false,
+		"Standard format only: write one signed Amount column (negative for debits) instead of Amount plus CreditDebit")
+	cmd.Flags().Bool("category-source", false,
+		"Append a CategorySource column showing how each category was found: mapping, iban, mcc, keyword, ai, internal, override or fallback")
+	cmd.Flags().Bool("tags", false,
+		"Append a Tags column with the semicolon-separated tags matched from the tag rules file")
+	cmd.Flags().Bool("sequence", false,
+		"Append a SequenceNumber column with each entry's position in its source statement (CAMT only)")
+	cmd.Flags().Bool("party-bic", false,
+		"Append a PartyBIC column with the BIC of the counterparty's bank (CAMT only)")
+	cmd.Flags().Bool("creditor-reference", false,
+		"Append a CreditorReference column with the ISO 11649 (RF) creditor reference of each payment (CAMT only)")
+	cmd.Flags().Bool("reference-type", false,
+		"Append StructuredReference and ReferenceType columns with the structured payment reference and its type: QRR, SCOR or NON (CAMT only)")
+	cmd.Flags().Bool("card", false,
+		"Append a CardLast4 column with the last four digits of the masked card number (Viseca PDF and debit only)")
+	cmd.Flags().Bool("mcc", false,
+		"Append an MCC column with the merchant category code of card transactions, when the statement prints one (Viseca PDF and CAMT)")
+	cmd.Flags().Bool("bank-tx-code-description", false,
+		"Append a BankTxCodeDescription column with the meaning of the ISO bank transaction code, e.g. SEPA Credit Transfer for PMNT/RCDT/ESCT (CAMT only)")
+	cmd.Flags().Bool("fx-difference", false,
+		"Append an FXDifference column with the booked amount minus the original amount converted at the exchange rate")
+	cmd.Flags().Bool("anonymize", false,
+		"Replace party names, IBANs, descriptions and references with pseudonyms (e.g. Party-1a2b3c4d) so the CSV can be shared; amounts, dates and categories are kept. Set CAMT_ANONYMIZE_KEY for pseudonyms that match across runs")
+	cmd.Flags().String("base-currency", "",
+		"Append BaseAmount and BaseCurrency columns with amounts converted to this currency (e.g. CHF)")
+	cmd.Flags().String("rates", "",
+		"YAML file of exchange rates to the base currency by date, used when the statement has no exchange information")
+	cmd.Flags().Bool("bom", false,
+		"Start the CSV with a UTF-8 byte order mark so that Excel on Windows displays accented characters correctly")
+}
+
+// RegisterAppendFlags adds the --append, --dedupe and --split flags to a command.
+func RegisterAppendFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("append", false,
+		"Append to the output file if it exists (header must match the selected format) instead of overwriting it")
+	cmd.Flags().Bool("dedupe", false,
+		"With --append, skip transactions already present in the output file (compared on bank fields, not on category or tags)")
+	cmd.Flags().String("split", "",
+		"Write one CSV per group next to the output file instead of a single file: by-party-iban (transactions without a counterparty IBAN go to an 'unknown' file)")
+}
+
+// RegisterOutputDirFlag adds the --output-dir flag to a command.
+func RegisterOutputDirFlag(cmd *cobra.Command) {
+	cmd.Flags().String("output-dir", "",
+		"Write the CSV in this directory, named from the statement's account and date range (e.g. CH93..._2025-05-01_2025-05-31.csv), instead of --output")
+}
+
+// RegisterNoClobberFlag adds the --no-clobber flag to a command.
+func RegisterNoClobberFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("no-clobber", false,
+		"Refuse to overwrite an existing output file; in batch mode, inputs whose CSV already exists are skipped with a warning")
+}
+
+// RegisterRecursiveFlag adds the --recursive flag to a command.
+func RegisterRecursiveFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("recursive", false,
+		"In batch mode, also convert the files in subdirectories of the input directory; output is still written flat, so file names must be unique")
+}
+
+// FormatXLSX is the --format value that writes an XLSX workbook. It is only
+// valid with --single-workbook.
+const FormatXLSX = "xlsx"
+
+// FormatLedger is the --format value that writes an hledger journal instead
+// of a CSV. It is only valid for single-file conversions.
+const FormatLedger = "ledger"
+
+// RegisterWorkbookFlag adds the --single-workbook and --strict flags to a command.
+func RegisterWorkbookFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("single-workbook", false,
+		"With --format xlsx in batch mode, write one workbook with a sheet per account in the standard columns; -o is the .xlsx file or its directory")
+	cmd.Flags().Bool("strict", false,
+		"With --single-workbook, fail the files of an account whose statements mix currencies instead of warning")
+}
+
+// RegisterLimitFlags adds the --max-transactions, --limit and --chunk-size flags to a command.
+func RegisterLimitFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("max-transactions", 0,
+		"Stop with an error when an input file holds more than this many transactions (0 = unlimited)")
+	cmd.Flags().Int("limit", 0,
+		"Write only the first N transactions, counted after filtering, for quick previews (0 = all)")
+	cmd.Flags().Int("chunk-size", 0,
+		"Write at most this many transactions per file, in numbered files next to the output file (e.g. out_001.csv); 0 writes a single file")
+}
+
+// WithTransactionLimit returns ctx carrying the --max-transactions limit, for
+// the parsers to enforce, and the --limit preview size.
+func WithTransactionLimit(ctx context.Context, cmd *cobra.Command) (context.Context, error) {
+	maxTransactions, _ := cmd.Flags().GetInt("max-transactions")
+	if maxTransactions < 0 {
+		return ctx, fmt.Errorf("--max-transactions must not be negative")
+	}
+	limit, _ := cmd.Flags().GetInt("limit")
+	if limit < 0 {
+		return ctx, fmt.Errorf("--limit must not be negative")
+	}
+	return parser.WithLimit(parser.WithMaxTransactions(ctx, maxTransactions), limit), nil
+}
+
+// RegisterInputEncodingFlag adds the --input-encoding flag to a command.
+func RegisterInputEncodingFlag(cmd *cobra.Command) {
+	cmd.Flags().String("input-encoding", internalcommon.EncodingAuto,
+		"Encoding of text input (CSV, MT940, PDF text): auto (UTF-8, else Windows-1252), utf-8, windows-1252 or iso-8859-1; CAMT XML uses its own declaration")
+}
+
+// WithInputEncoding returns ctx carrying the --input-encoding value, for the
+// parsers to decode their input with.
+func WithInputEncoding(ctx context.Context, cmd *cobra.Command) (context.Context, error) {
+	name, _ := cmd.Flags().GetString("input-encoding")
+	encoding, err := internalcommon.NormalizeEncoding(name)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid --input-encoding: %w", err)
+	}
+	return parser.WithInputEncoding(ctx, encoding), nil
+}
+
+// RegisterNumberFormatFlag adds the --number-format flag to a command whose
+// parser reads amounts written with locale-specific separators.
+func RegisterNumberFormatFlag(cmd *cobra.Command) {
+	cmd.Flags().String("number-format", string(models.NumberFormatAuto),
+		"Separators of input amounts: auto (the last '.' or ',' is the decimal separator), dot (1,234.56) or comma (1.234,56)")
+}
+
+// WithNumberFormat returns ctx carrying the --number-format value, for the
+// parsers to read amounts with. Commands without the flag read amounts as auto.
+func WithNumberFormat(ctx context.Context, cmd *cobra.Command) (context.Context, error) {
+	if cmd.Flags().Lookup("number-format") == nil {
+		return ctx, nil
+	}
+	name, _ := cmd.Flags().GetString("number-format")
+	format, err := models.ParseNumberFormat(name)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid --number-format: %w", err)
+	}
+	return parser.WithNumberFormat(ctx, format), nil
+}
+
+// RegisterPresetFlag adds the --preset flag to a command.
+func RegisterPresetFlag(cmd *cobra.Command) {
+	cmd.Flags().String("preset", "",
+		"Bank preset from the presets file (e.g. bcv, viseca): selects the parser, input number format and encoding, output --locale and party-name cleanup rules in one flag; flags given explicitly take precedence")
+}
+
+// WithPreset applies the --preset bank preset. Its input encoding and number
+// format are carried by the returned context and its locale is set on opts,
+// except where the matching flag is given explicitly; its cleanup rules run
+// after those of the cleanup file. It returns the preset's parser name, or "" when
+// --preset is not set.
+func WithPreset(ctx context.Context, cmd *cobra.Command, appContainer *container.Container, opts *formatter.Options) (context.Context, string, error) {
+	name, _ := cmd.Flags().GetString("preset")
+	if name == "" {
+		return ctx, "", nil
+	}
+	preset, err := appContainer.GetBankPreset(name)
+	if err != nil {
+		return ctx, "", fmt.Errorf("invalid --preset: %w", err)
+	}
+
+	if preset.InputEncoding != "" && !cmd.Flags().Changed("input-encoding") {
+		encoding, err := internalcommon.NormalizeEncoding(preset.InputEncoding)
+		if err != nil {
+			return ctx, "", fmt.Errorf("invalid --preset: bank preset %q: %w", preset.Name, err)
+		}
+		ctx = parser.WithInputEncoding(ctx, encoding)
+	}
+	if preset.NumberFormat != "" && !cmd.Flags().Changed("number-format") {
+		format, _ := models.ParseNumberFormat(preset.NumberFormat)
+		ctx = parser.WithNumberFormat(ctx, format)
+	}
+	if preset.Locale != "" && !cmd.Flags().Changed("locale") {
+		opts.Locale, _ = models.LookupLocale(preset.Locale)
+	}
+	if len(preset.Cleanup) > 0 {
+		if cat := appContainer.GetCategorizer(); cat != nil {
+			if err := cat.AddNameCleanupRules(preset.Cleanup); err != nil {
+				return ctx, "", fmt.Errorf("invalid --preset: bank preset %q: %w", preset.Name, err)
+			}
+		}
+	}
+	return ctx, preset.Parser, nil
+}
+
+// RegisterAccountFlag adds the --account flag to a command whose input may
+// hold the statements of several accounts.
+func RegisterAccountFlag(cmd *cobra.Command) {
+	cmd.Flags().String("account", "",
+		"Only write the statements of this account, given as an IBAN or other account id; fails if no statement matches")
+}
+
+// WithAccount returns ctx carrying the --account value. Commands without the
+// flag write the statements of every account.
+func WithAccount(ctx context.Context, cmd *cobra.Command) (context.Context, error) {
+	if cmd.Flags().Lookup("account") == nil {
+		return ctx, nil
+	}
+	account, _ := cmd.Flags().GetString("account")
+	if account == "" {
+		return ctx, nil
+	}
+	if strings.TrimSpace(account) == "" {
+		return ctx, fmt.Errorf("invalid --account: must not be blank")
+	}
+	return parser.WithAccount(ctx, account), nil
+}
+
+// RegisterFilterFlags adds the --filter-description, --skip-zero, --status and
+// --drop-empty flags to a command.
+func RegisterFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().String("filter-description", "",
+		"Only write transactions whose description matches this regular expression (case-insensitive unless it starts with (?-i))")
+	cmd.Flags().Bool("skip-zero", false,
+		"Drop transactions with a zero amount, such as informational entries; amounts that fail to parse are logged as warnings")
+	cmd.Flags().String("status", statusAll,
+		"Entry statuses to write: all, or booked to drop pending (PDNG), information (INFO) and future (FUTR) entries")
+	cmd.Flags().Bool("drop-empty", false,
+		"Drop transactions with neither a party name nor a description; they are always counted in a warning")
+}
+
+// Values of the --status flag.
+const (
+	statusAll    = "all"
+	statusBooked = "booked"
+)
+
+// WithTransactionFilter returns ctx carrying the filter built from
+// --filter-description, --skip-zero, --status and --drop-empty. It returns an error if the
+// expression does not compile or the status is unknown.
+func WithTransactionFilter(ctx context.Context, cmd *cobra.Command) (context.Context, error) {
+	var filter parser.TransactionFilter
+	if pattern, _ := cmd.Flags().GetString("filter-description"); pattern != "" {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return ctx, fmt.Errorf("invalid --filter-description: %w", err)
+		}
+		filter.Description = re
+	}
+	filter.SkipZero, _ = cmd.Flags().GetBool("skip-zero")
+	filter.DropEmpty, _ = cmd.Flags().GetBool("drop-empty")
+	switch status, _ := cmd.Flags().GetString("status"); strings.ToLower(status) {
+	case "", statusAll:
+	case statusBooked:
+		filter.BookedOnly = true
+	default:
+		return ctx, fmt.Errorf("invalid --status %q: use %s or %s", status, statusAll, statusBooked)
+	}
+	if filter == (parser.TransactionFilter{}) {
+		return ctx, nil
+	}
+	return parser.WithTransactionFilter(ctx, filter), nil
+}
+
+// RegisterUncategorizedFlag adds the --fail-on-uncategorized flag to a command.
+func RegisterUncategorizedFlag(cmd *cobra.Command) {
+	cmd.Flags().String("fail-on-uncategorized", "",
+		"Exit with status 3 after writing the CSV when more than N transactions (or N% with a % suffix) were left uncategorized; without a value, when any was")
+	cmd.Flags().Lookup("fail-on-uncategorized").NoOptDefVal = "0"
+}
+
+// WithUncategorizedCheck reads --fail-on-uncategorized. When it is set, it
+// returns ctx carrying fresh categorization stats and a check to run once the
+// output is written, which exits with ExitCodeUncategorized when the threshold
+// is exceeded. Otherwise the check does nothing.
+func WithUncategorizedCheck(ctx context.Context, cmd *cobra.Command, logger logging.Logger) (context.Context, func(), error) {
+	flag := cmd.Flags().Lookup("fail-on-uncategorized")
+	if flag == nil || !flag.Changed {
+		return ctx, func() {}, nil
+	}
+	threshold, err := ParseUncategorizedThreshold(flag.Value.String())
+	if err != nil {
+		return ctx, nil, fmt.Errorf("--fail-on-uncategorized: %w", err)
+	}
+	if noCategorize, _ := cmd.Flags().GetBool("no-categorize"); noCategorize {
+		return ctx, nil, fmt.Errorf("--fail-on-uncategorized cannot be combined with --no-categorize")
+	}
+
+	stats := models.NewCategorizationStats()
+	check := func() {
+		if !threshold.Exceeded(*stats) {
+			return
+		}
+		logger.Error("Too many uncategorized transactions",
+			logging.Field{Key: "uncategorized", Value: stats.Uncategorized},
+			logging.Field{Key: "total", Value: stats.Total},
+			logging.Field{Key: "threshold", Value: threshold.String()})
+		osExitFn(ExitCodeUncategorized)
+	}
+	return parser.WithCategorizationStats(ctx, stats), check, nil
+}
+
+// RegisterCategorizeFlag adds the --no-categorize flag to a command.
+func RegisterCategorizeFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("no-categorize", false,
+		"Skip categorization: no mapping lookups, AI calls or mapping file writes; categories are left as Uncategorized")
+}
+
+// categorizerGetter is implemented by parsers built on parser.BaseParser.
+type categorizerGetter interface {
+	GetCategorizer() models.TransactionCategorizer
+}
+
+// ApplyCategorizeFlag removes the categorizer from p when --no-categorize is
+// set. The name cleanup rules still apply: a categorizer that cleans party
+// names is replaced with a models.CleanupOnly one.
+func ApplyCategorizeFlag(cmd *cobra.Command, p parser.FullParser, logger logging.Logger) {
+	if noCategorize, _ := cmd.Flags().GetBool("no-categorize"); noCategorize {
+		var cleanup models.TransactionCategorizer
+		if getter, ok := p.(categorizerGetter); ok {
+			if cleaner, ok := getter.GetCategorizer().(models.PartyNameCleaner); ok {
+				cleanup = models.CleanupOnly{Cleaner: cleaner}
+			}
+		}
+		p.SetCategorizer(cleanup)
+		logger.Info("Categorization disabled (--no-categorize)")
+	}
+}
+
+// ParseOutputOptions reads the options registered by RegisterFormatFlags, RegisterAppendFlags,
+// RegisterLimitFlags, RegisterNoClobberFlag, RegisterRecursiveFlag and RegisterWorkbookFlag.
+// It returns an error if --rates is given without --base-currency or the rates
+// file cannot be loaded.
+func ParseOutputOptions(cmd *cobra.Command, logger logging.Logger) (OutputOptions, error) {
+	withTime, _ := cmd.Flags().GetBool("with-time")
+	signedAmount, _ := cmd.Flags().GetBool("signed-amount")
+	categorySource, _ := cmd.Flags().GetBool("category-source")
+	tags, _ := cmd.Flags().GetBool("tags")
+	sequence, _ := cmd.Flags().GetBool("sequence")
+	partyBIC, _ := cmd.Flags().GetBool("party-bic")
+	creditorReference, _ := cmd.Flags().GetBool("creditor-reference")
+	referenceType, _ := cmd.Flags().GetBool("reference-type")
+	cardLast4, _ := cmd.Flags().GetBool("card")
+	mcc, _ := cmd.Flags().GetBool("mcc")
+	bankTxCodeDescription, _ := cmd.Flags().GetBool("bank-tx-code-description")
+	fxDifference, _ := cmd.Flags().GetBool("fx-difference")
+	anonymize, _ := cmd.Flags().GetBool("anonymize")
+	bom, _ := cmd.Flags().GetBool("bom")
+	appendMode, _ := cmd.Flags().GetBool("append")
+	noClobber, _ := cmd.Flags().GetBool("no-clobber")
+	recursive, _ := cmd.Flags().GetBool("recursive")
+	singleWorkbook, _ := cmd.Flags().GetBool("single-workbook")
+	strict, _ := cmd.Flags().GetBool("strict")
+	dedupe, _ := cmd.Flags().GetBool("dedupe")
+	split, _ := cmd.Flags().GetString("split")
+	chunkSize, _ := cmd.Flags().GetInt("chunk-size")
+	outputDir, _ := cmd.Flags().GetString("output-dir")
+	opts := OutputOptions{
+		Options: formatter.Options{
+			IncludeTime:           withTime,
+			SignedAmount:          signedAmount,
+			CategorySource:        categorySource,
+			Tags:                  tags,
+			SequenceNumber:        sequence,
+			PartyBIC:              partyBIC,
+			CreditorReference:     creditorReference,
+			ReferenceType:         referenceType,
+			CardLast4:             cardLast4,
+			MCC:                   mcc,
+			BankTxCodeDescription: bankTxCodeDescription,
+			FXDifference:          fxDifference,
+			Anonymize:             anonymize,
+		},
+		BOM:            bom,
+		Append:         appendMode,
+		Dedupe:         dedupe,
+		NoClobber:      noClobber,
+		Recursive:      recursive,
+		SingleWorkbook: singleWorkbook,
+		Strict:         strict,
+		Split:          split,
+		ChunkSize:      chunkSize,
+		OutputDir:      outputDir,
+	}
+	if dedupe && !appendMode {
+		return opts, fmt.Errorf("--dedupe requires --append")
+	}
+	if output, _ := cmd.Flags().GetString("output"); outputDir != "" && output != "" {
+		return opts, fmt.Errorf("--output-dir cannot be combined with --output")
+	}
+	localeName, _ := cmd.Flags().GetString("locale")
+	locale, err := models.LookupLocale(localeName)
+	if err != nil {
+		return opts, fmt.Errorf("invalid --locale: %w", err)
+	}
+	opts.Locale = locale
+	if err := internalcommon.ValidateSplitMode(split); err != nil {
+		return opts, err
+	}
+	switch {
+	case chunkSize < 0:
+		return opts, fmt.Errorf("--chunk-size must not be negative")
+	case chunkSize > 0 && split != "":
+		return opts, fmt.Errorf("--chunk-size cannot be combined with --split")
+	case chunkSize > 0 && appendMode:
+		return opts, fmt.Errorf("--chunk-size cannot be combined with --append")
+	}
+	// Split and derived file names are built from IBANs, which would leak
+	switch {
+	case anonymize && split != "":
+		return opts, fmt.Errorf("--anonymize cannot be combined with --split")
+	case anonymize && outputDir != "":
+		return opts, fmt.Errorf("--anonymize cannot be combined with --output-dir")
+	}
+
+	if anonymize {
+		if appContainer := root.GetContainer(); appContainer != nil && appContainer.GetConfig() != nil {
+			opts.AnonymizeKey = []byte(appContainer.GetConfig().Output.AnonymizeKey)
+		}
+	}
+
+	template, _ := cmd.Flags().GetString("description-template")
+	if template == "" {
+		if appContainer := root.GetContainer(); appContainer != nil && appContainer.GetConfig() != nil {
+			template = appContainer.GetConfig().Output.DescriptionTemplate
+		}
+	}
+	if template != "" {
+		descriptionTemplate, err := models.ParseDescriptionTemplate(template)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --description-template: %w", err)
+		}
+		opts.DescriptionTemplate = descriptionTemplate
+	}
+
+	if spec, _ := cmd.Flags().GetString("sort"); spec != "" {
+		order, err := models.ParseSortOrder(spec)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --sort: %w", err)
+		}
+		opts.Sort = order
+	}
+
+	if profileName, _ := cmd.Flags().GetString("profile"); profileName != "" {
+		appContainer := root.GetContainer()
+		if appContainer == nil {
+			return opts, fmt.Errorf("container not initialized")
+		}
+		profile, err := appContainer.GetExportProfile(profileName)
+		if err != nil {
+			return opts, err
+		}
+		opts.Profile = &profile
+	}
+
+	if list, _ := cmd.Flags().GetString("columns"); list != "" {
+		columns, err := formatter.ParseColumns(list)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --columns: %w", err)
+		}
+		profile, err := formatter.ColumnsProfile(columns, opts.Profile)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --columns: %w", err)
+		}
+		opts.Profile = &profile
+	}
+
+	baseCurrency, _ := cmd.Flags().GetString("base-currency")
+	ratesFile, _ := cmd.Flags().GetString("rates")
+	if baseCurrency == "" {
+		if ratesFile != "" {
+			return opts, fmt.Errorf("--rates requires --base-currency")
+		}
+		return opts, nil
+	}
+
+	var rates *currency.RateTable
+	if ratesFile != "" {
+		var err error
+		if rates, err = currency.LoadRates(ratesFile); err != nil {
+			return opts, err
+		}
+	}
+	opts.BaseCurrency = currency.NewConverter(baseCurrency, rates, logger)
+	return opts, nil
 }
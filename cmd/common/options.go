@@ -0,0 +1,49 @@
+package common
+
+import "fjacquet/camt-csv/internal/formatter"
+
+// OutputOptions holds the output settings of a conversion command: the
+// formatter options, which shape the rows, and the settings of the writers
+// and the batch processor, which decide what files are read and written.
+type OutputOptions struct {
+	formatter.Options
+
+	// BOM starts each CSV output with a UTF-8 byte order mark, which Excel on
+	// Windows needs to display accented characters. Appended rows never get one.
+	BOM bool
+
+	// Append adds rows to an existing output file instead of overwriting it.
+	Append bool
+
+	// Dedupe skips appended rows that are already present in the output file.
+	Dedupe bool
+
+	// NoClobber makes writing fail instead of overwriting an existing output
+	// file. Appending is not affected.
+	NoClobber bool
+
+	// Split writes one output file per group instead of a single file
+	// (e.g. "by-party-iban").
+	Split string
+
+	// ChunkSize, when positive, writes at most ChunkSize transactions per
+	// output file, in numbered files next to the output file.
+	ChunkSize int
+
+	// OutputDir, when set, replaces the output file of a single-file
+	// conversion: the file is written in OutputDir and named from the
+	// statement's account and date range, like batch consolidation does.
+	OutputDir string
+
+	// Recursive makes batch conversion also convert the files in the input
+	// directory's subdirectories.
+	Recursive bool
+
+	// SingleWorkbook makes batch conversion write one XLSX workbook with a
+	// sheet per account instead of one CSV per input file.
+	SingleWorkbook bool
+
+	// Strict makes single-workbook consolidation fail the files of an account
+	// whose statements mix currencies.
+	Strict bool
+}
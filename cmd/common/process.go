@@ -5,16 +5,28 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"slices"
 
+	"fjacquet/camt-csv/internal/batch"
 	internalcommon "fjacquet/camt-csv/internal/common"
 	"fjacquet/camt-csv/internal/container"
+	"fjacquet/camt-csv/internal/formatter"
 	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
 	"fjacquet/camt-csv/internal/parser"
+	"fjacquet/camt-csv/internal/parsererror"
 )
 
-// ErrInvalidFormat is returned when a file fails format validation.
-var ErrInvalidFormat = errors.New("file is not in a valid format")
+// ErrInvalidFormat is returned when a file fails format validation. It is the
+// same sentinel the parsers wrap, so errors.Is matches either source.
+var ErrInvalidFormat = parsererror.ErrInvalidFormat
+
+// ErrOutputExists is returned when --no-clobber is set and the output file
+// exists.
+var ErrOutputExists = internalcommon.ErrOutputExists
 
 // ProcessFileWithError processes a single file using the given parser and returns an error on failure.
 // This is the preferred function for testable code.
@@ -43,27 +55,35 @@ func ProcessFileWithError(ctx context.Context, p parser.FullParser, inputFile, o
 
 // ProcessFile processes a single file using the given parser with formatter support.
 // Calls ProcessFileWithErrorFormatted and calls log.Fatalf on error.
-func ProcessFile(ctx context.Context, p parser.FullParser, inputFile, outputFile string, validate bool, log logging.Logger, c *container.Container, format string, dateFormat string) {
-	if err := ProcessFileWithErrorFormatted(ctx, p, inputFile, outputFile, validate, log, c, format, dateFormat); err != nil {
+func ProcessFile(ctx context.Context, p parser.FullParser, inputFile, outputFile string, validate bool, log logging.Logger, c *container.Container, format string, dateFormat string, opts OutputOptions) {
+	if err := ProcessFileWithErrorFormatted(ctx, p, inputFile, outputFile, validate, log, c, format, dateFormat, opts); err != nil {
 		log.Fatalf("%v", err)
 	}
 }
 
 // ProcessFileWithErrorFormatted processes a single file using the given parser with formatter support and returns an error on failure.
-func ProcessFileWithErrorFormatted(ctx context.Context, p parser.FullParser, inputFile, outputFile string, validate bool, log logging.Logger, c *container.Container, format string, dateFormat string) error {
+func ProcessFileWithErrorFormatted(ctx context.Context, p parser.FullParser, inputFile, outputFile string, validate bool, log logging.Logger, c *container.Container, format string, dateFormat string, opts OutputOptions) error {
 	// Set the logger on the parser using the new interface
 	p.SetLogger(log)
 
-	// Get formatter registry from container
-	registry := c.GetFormatterRegistry()
-	formatter, err := registry.Get(format)
-	if err != nil {
-		return fmt.Errorf("invalid format '%s': %w. Valid formats: standard, icompta, jumpsoft", format, err)
-	}
+	// A journal is not a CSV, so the ledger format has no formatter
+	var outFormatter formatter.OutputFormatter
+	if format == FormatLedger {
+		log.WithField("format", format).Info("Using output format")
+	} else {
+		// Get formatter registry from container
+		registry := c.GetFormatterRegistry()
+		var err error
+		outFormatter, err = registry.Get(format)
+		if err != nil {
+			return fmt.Errorf("invalid format '%s': %w. Valid formats: standard, icompta, jumpsoft, ledger", format, err)
+		}
+		outFormatter = formatter.ApplyOptions(outFormatter, opts.Options)
 
-	// Get delimiter from formatter
-	delimiter := formatter.Delimiter()
-	log.WithField("format", format).WithField("delimiter", string(delimiter)).Info("Using output format")
+		// Get delimiter from formatter
+		delimiter := outFormatter.Delimiter()
+		log.WithField("format", format).WithField("delimiter", string(delimiter)).Info("Using output format")
+	}
 
 	if validate {
 		log.Info("Validating format...")
@@ -93,12 +113,309 @@ func ProcessFileWithErrorFormatted(ctx context.Context, p parser.FullParser, inp
 	if err != nil {
 		return fmt.Errorf("error parsing file: %w", err)
 	}
+	// The built-in parsers stop at the limit themselves; parsers registered
+	// from another module may not, and --timeout may expire while parsing
+	if err := parser.CheckTransactionLimit(ctx, len(transactions)); err != nil {
+		return fmt.Errorf("error parsing file: %w", err)
+	}
+	parser.WarnEmptyTransactions(ctx, transactions, filepath.Base(inputFile), log)
+	transactions = parser.FilterTransactions(ctx, transactions)
+
+	if opts.OutputDir != "" {
+		if err := os.MkdirAll(opts.OutputDir, models.PermissionDirectory); err != nil {
+			return fmt.Errorf("error creating output directory: %w", err)
+		}
+		var statements []models.StatementInfo
+		if infoReader, ok := p.(parser.StatementInfoReader); ok {
+			statements = readStatementInfo(infoReader, inputFile, log)
+		}
+		outputFile = AutoOutputPath(opts.OutputDir, inputFile, transactions, statements, log)
+		log.Info("Derived output file from the statement",
+			logging.Field{Key: "file", Value: outputFile})
+	}
 
-	// Write transactions using the selected formatter
-	if err := internalcommon.WriteTransactionsToCSVWithFormatter(transactions, outputFile, log, formatter, delimiter); err != nil {
+	if format == FormatLedger {
+		// A journal has no columns; the options that rewrite transactions,
+		// anonymization included, still apply
+		journal := formatter.ApplyTransactionOptions(transactions, opts.Options)
+		if err := internalcommon.WriteTransactionsToLedger(journal, outputFile, log, c.GetConfig().Output.LedgerAccount, opts.NoClobber); err != nil {
+			return fmt.Errorf("error writing journal: %w", err)
+		}
+	} else if err := WriteTransactions(transactions, outputFile, log, outFormatter, opts); err != nil {
+		// Write transactions using the selected formatter
 		return fmt.Errorf("error writing CSV: %w", err)
 	}
+	parser.RecordCategorization(ctx, transactions)
 
 	log.Info("Conversion completed successfully!")
 	return nil
 }
+
+// AutoOutputPath returns the output file for --output-dir: a file in dir named
+// by the batch aggregator from the statement account and date range. The date
+// range is the reporting period of statements when they declare one, and the
+// date range of transactions otherwise. Without an account IBAN in the
+// statement, the account is taken from the input file name as in batch mode.
+func AutoOutputPath(dir, inputFile string, transactions []models.Transaction, statements []models.StatementInfo, log logging.Logger) string {
+	accountID := ""
+	for _, tx := range transactions {
+		if tx.IBAN != "" {
+			accountID = tx.IBAN
+			break
+		}
+	}
+	if accountID == "" {
+		accountID = internalcommon.ExtractAccountFromCAMTFilename(inputFile).ID
+	}
+
+	aggregator := batch.NewBatchAggregator(log)
+	dateRange := aggregator.ResolveDateRange(statements, transactions)
+	return filepath.Join(dir, aggregator.GenerateOutputFilename(accountID, dateRange))
+}
+
+// readStatementInfo returns the statement metadata of inputFile. Failures
+// are only logged, since the file has already been parsed.
+func readStatementInfo(infoReader parser.StatementInfoReader, inputFile string, log logging.Logger) []models.StatementInfo {
+	file, err := os.Open(inputFile) // #nosec G304 -- CLI tool requires user-provided file paths
+	if err != nil {
+		log.WithError(err).Debug("Could not read statement metadata",
+			logging.Field{Key: "file", Value: inputFile})
+		return nil
+	}
+	defer func() { _ = file.Close() }()
+
+	infos, err := infoReader.ReadStatementInfo(file)
+	if err != nil {
+		log.WithError(err).Debug("Could not read statement metadata",
+			logging.Field{Key: "file", Value: inputFile})
+		return nil
+	}
+	return infos
+}
+
+// WriteTransactions writes transactions to outputFile with the given formatter,
+// appending to an existing file when opts.Append is set. With opts.Split or
+// opts.ChunkSize, it writes several files next to outputFile instead. With
+// opts.NoClobber, it fails with ErrOutputExists before overwriting a file.
+// A failure leaves no partially written file behind (see writeOutputFiles).
+func WriteTransactions(transactions []models.Transaction, outputFile string, log logging.Logger, outFormatter formatter.OutputFormatter, opts OutputOptions) error {
+	if opts.ChunkSize > 0 {
+		return writeChunkedTransactions(transactions, outputFile, log, outFormatter, opts)
+	}
+	if opts.Split != "" {
+		return writeSplitTransactions(transactions, outputFile, log, outFormatter, opts)
+	}
+	if opts.Append {
+		// Appending only adds rows, so the file is written in place
+		return writeTransactionsFile(transactions, outputFile, log, outFormatter, opts)
+	}
+	return writeOutputFiles([]outputPart{{path: outputFile, transactions: transactions}}, log, outFormatter, opts)
+}
+
+// writeSplitTransactions partitions transactions by opts.Split and writes each
+// group to its own file derived from outputFile.
+func writeSplitTransactions(transactions []models.Transaction, outputFile string, log logging.Logger, outFormatter formatter.OutputFormatter, opts OutputOptions) error {
+	if outputFile == "" {
+		return fmt.Errorf("--split requires an output file")
+	}
+
+	groups, err := internalcommon.SplitTransactions(transactions, opts.Split)
+	if err != nil {
+		return err
+	}
+
+	files := make([]outputPart, len(groups))
+	for i, group := range groups {
+		files[i] = outputPart{path: internalcommon.SplitOutputPath(outputFile, group.Key), transactions: group.Transactions}
+	}
+	if err := writeOutputFiles(files, log, outFormatter, opts); err != nil {
+		return err
+	}
+	for i, group := range groups {
+		log.Info("Wrote split output",
+			logging.Field{Key: "group", Value: group.Key},
+			logging.Field{Key: "file", Value: files[i].path},
+			logging.Field{Key: "count", Value: len(group.Transactions)})
+	}
+	return nil
+}
+
+// writeChunkedTransactions writes transactions in files of at most
+// opts.ChunkSize rows, numbered from 001 in input order.
+func writeChunkedTransactions(transactions []models.Transaction, outputFile string, log logging.Logger, outFormatter formatter.OutputFormatter, opts OutputOptions) error {
+	if outputFile == "" {
+		return fmt.Errorf("--chunk-size requires an output file")
+	}
+
+	// The formatter sorts each chunk; sort first so that chunks follow each other
+	if len(opts.Sort) > 0 {
+		transactions = slices.Clone(transactions)
+		opts.Sort.Sort(transactions)
+	}
+	chunks := internalcommon.ChunkTransactions(transactions, opts.ChunkSize)
+	files := make([]outputPart, len(chunks))
+	for i, chunk := range chunks {
+		files[i] = outputPart{path: internalcommon.SplitOutputPath(outputFile, fmt.Sprintf("%03d", i+1)), transactions: chunk}
+	}
+	if err := writeOutputFiles(files, log, outFormatter, opts); err != nil {
+		return err
+	}
+	for i, chunk := range chunks {
+		log.Info("Wrote output chunk",
+			logging.Field{Key: "chunk", Value: i + 1},
+			logging.Field{Key: "file", Value: files[i].path},
+			logging.Field{Key: "count", Value: len(chunk)})
+	}
+	return nil
+}
+
+// outputPart is one of the files written by writeOutputFiles.
+type outputPart struct {
+	path         string
+	transactions []models.Transaction
+}
+
+// writeOutputFiles writes each of files so that a failure leaves none of them
+// partially written. Each file is written to a temporary file next to it, and
+// the temporary files replace the outputs once all of them are complete. With
+// opts.Append, a temporary file starts as a copy of the output it replaces.
+//
+// With opts.NoClobber, existing outputs are reported before anything is
+// written, and the temporary files are hard-linked into place, which fails
+// instead of overwriting an output created in the meantime. The outputs of
+// the run that were already in place are then removed again.
+func writeOutputFiles(files []outputPart, log logging.Logger, outFormatter formatter.OutputFormatter, opts OutputOptions) error {
+	noClobber := opts.NoClobber && !opts.Append
+	if noClobber {
+		for _, f := range files {
+			if err := internalcommon.CheckNoClobber(f.path); err != nil {
+				return err
+			}
+		}
+	}
+
+	type stagedFile struct{ tmp, path string }
+	var staged []stagedFile
+	defer func() {
+		// Temporary files that were put in place are already gone
+		for _, f := range staged {
+			_ = os.Remove(f.tmp)
+		}
+	}()
+
+	stageOpts := opts
+	stageOpts.NoClobber = false
+	for _, f := range files {
+		if len(f.transactions) == 0 {
+			// No file is written for no transactions
+			if err := writeTransactionsFile(f.transactions, f.path, log, outFormatter, opts); err != nil {
+				return fmt.Errorf("error writing %s: %w", f.path, err)
+			}
+			continue
+		}
+		tmp, err := stageOutputFile(f.path, opts.Append)
+		if err != nil {
+			return fmt.Errorf("error writing %s: %w", f.path, err)
+		}
+		staged = append(staged, stagedFile{tmp: tmp, path: f.path})
+		if err := writeTransactionsFile(f.transactions, tmp, log, outFormatter, stageOpts); err != nil {
+			return fmt.Errorf("error writing %s: %w", f.path, err)
+		}
+	}
+
+	for i, f := range staged {
+		if err := publishOutputFile(f.tmp, f.path, noClobber); err != nil {
+			if noClobber {
+				for _, written := range staged[:i] {
+					_ = os.Remove(written.path)
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// stageOutputFile creates the temporary file that output is written to,
+// with the permissions of the file it replaces. With appendMode, it starts as
+// a copy of output.
+func stageOutputFile(output string, appendMode bool) (string, error) {
+	dir := filepath.Dir(output)
+	if err := os.MkdirAll(dir, models.PermissionDirectory); err != nil {
+		return "", fmt.Errorf("error creating directory: %w", err)
+	}
+
+	perm := os.FileMode(models.PermissionNonSecretFile)
+	var existing []byte
+	if info, err := os.Stat(output); err == nil {
+		perm = info.Mode().Perm()
+		if appendMode {
+			if existing, err = os.ReadFile(output); err != nil { // #nosec G304 -- CLI tool requires user-provided output paths
+				return "", err
+			}
+		}
+	}
+
+	tmpFile, err := os.CreateTemp(dir, "."+filepath.Base(output)+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("error creating temporary file: %w", err)
+	}
+	tmp := tmpFile.Name()
+	if len(existing) > 0 {
+		_, err = tmpFile.Write(existing)
+	}
+	if err == nil {
+		err = tmpFile.Chmod(perm)
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	return tmp, nil
+}
+
+// publishOutputFile puts the temporary file tmp in place of output. With
+// noClobber, it fails with ErrOutputExists rather than replace an existing
+// output.
+func publishOutputFile(tmp, output string, noClobber bool) error {
+	if !noClobber {
+		if err := os.Rename(tmp, output); err != nil {
+			return fmt.Errorf("error replacing %s: %w", output, err)
+		}
+		return nil
+	}
+	if err := os.Link(tmp, output); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("%w: %s (remove it or drop --no-clobber to overwrite it)", ErrOutputExists, output)
+		}
+		return fmt.Errorf("error writing %s: %w", output, err)
+	}
+	return os.Remove(tmp)
+}
+
+// writeTransactionsFile writes transactions to a single file, starting it
+// with a byte order mark when opts.BOM is set and the file is created.
+func writeTransactionsFile(transactions []models.Transaction, outputFile string, log logging.Logger, outFormatter formatter.OutputFormatter, opts OutputOptions) error {
+	if opts.Append && (!opts.BOM || hasContent(outputFile)) {
+		return internalcommon.AppendTransactionsToCSVWithFormatter(transactions, outputFile, log, outFormatter, outFormatter.Delimiter(), opts.Dedupe)
+	}
+	if opts.NoClobber && !opts.Append {
+		if err := internalcommon.CheckNoClobber(outputFile); err != nil {
+			return err
+		}
+	}
+	if opts.BOM {
+		return internalcommon.WriteTransactionsToCSVWithBOM(transactions, outputFile, log, outFormatter, outFormatter.Delimiter())
+	}
+	return internalcommon.WriteTransactionsToCSVWithFormatter(transactions, outputFile, log, outFormatter, outFormatter.Delimiter())
+}
+
+// hasContent reports whether path is a non-empty file, which appending adds
+// rows to rather than creating it.
+func hasContent(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Size() > 0
+}
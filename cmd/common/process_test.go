@@ -4,15 +4,27 @@ import (
 	"context"
 	"errors"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"fjacquet/camt-csv/cmd/common"
+	"fjacquet/camt-csv/internal/config"
+	"fjacquet/camt-csv/internal/container"
+	"fjacquet/camt-csv/internal/csvparser"
+	"fjacquet/camt-csv/internal/debitparser"
+	"fjacquet/camt-csv/internal/formatter"
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parser"
 
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockFullParser implements parser.FullParser for testing
@@ -281,3 +293,733 @@ func TestProcessFileWithError_ContextTimeout(t *testing.T) {
 	assert.ErrorIs(t, err, context.DeadlineExceeded)
 	mockParser.AssertExpectations(t)
 }
+
+func TestWriteTransactions_SplitByPartyIBAN(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "statement.csv")
+	date := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	transactions := []models.Transaction{
+		{Date: date, Amount: decimal.NewFromInt(1500), Currency: "CHF", PartyName: "Landlord", PartyIBAN: "CH9300762011623852957"},
+		{Date: date, Amount: decimal.NewFromInt(20), Currency: "CHF", PartyName: "Migros"},
+	}
+
+	opts := common.OutputOptions{Split: "by-party-iban"}
+	err := common.WriteTransactions(transactions, output, logging.NewMockLogger(), formatter.NewStandardFormatter(), opts)
+	require.NoError(t, err)
+
+	assert.NoFileExists(t, output)
+	landlord, err := os.ReadFile(filepath.Join(dir, "statement_CH9300762011623852957.csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(landlord), "Landlord")
+	assert.NotContains(t, string(landlord), "Migros")
+
+	unknown, err := os.ReadFile(filepath.Join(dir, "statement_unknown.csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(unknown), "Migros")
+}
+
+func TestWriteTransactions_NoClobber(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "statement.csv")
+	transactions := []models.Transaction{
+		{Date: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), Amount: decimal.NewFromInt(10), Currency: "CHF", PartyName: "Coop"},
+	}
+	opts := common.OutputOptions{NoClobber: true}
+
+	require.NoError(t, common.WriteTransactions(transactions, output, logging.NewMockLogger(), formatter.NewStandardFormatter(), opts))
+	assert.FileExists(t, output)
+
+	require.NoError(t, os.WriteFile(output, []byte("keep me\n"), 0600))
+	err := common.WriteTransactions(transactions, output, logging.NewMockLogger(), formatter.NewStandardFormatter(), opts)
+	require.ErrorIs(t, err, common.ErrOutputExists)
+	data, err := os.ReadFile(output) // #nosec G304 -- test output path
+	require.NoError(t, err)
+	assert.Equal(t, "keep me\n", string(data))
+
+	// Appending does not overwrite, so it is allowed
+	opts.Append = true
+	err = common.WriteTransactions(transactions, output, logging.NewMockLogger(), formatter.NewStandardFormatter(), opts)
+	assert.NotErrorIs(t, err, common.ErrOutputExists)
+}
+
+func TestProcessFileWithErrorFormatted_Ledger(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "statement.xml")
+	require.NoError(t, os.WriteFile(input, []byte("<Document/>"), 0600))
+	output := filepath.Join(dir, "statement.journal")
+
+	cfg := &config.Config{}
+	cfg.Log.Level = "info"
+	cfg.Log.Format = "text"
+	appContainer, err := container.NewContainer(cfg)
+	require.NoError(t, err)
+
+	p := &MockFullParser{}
+	p.On("SetLogger", mock.Anything).Return()
+	p.On("Parse", mock.Anything, mock.Anything).Return([]models.Transaction{
+		{Date: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), Amount: decimal.NewFromInt(10), Currency: "CHF",
+			PartyName: "Jean Dupont", Description: "Loyer Jean Dupont", IBAN: "CH9300762011623852957", CreditDebit: models.TransactionTypeDebit},
+	}, nil)
+	opts := common.OutputOptions{Options: formatter.Options{Anonymize: true}, NoClobber: true}
+
+	err = common.ProcessFileWithErrorFormatted(context.Background(), p, input, output, false, logging.NewMockLogger(), appContainer, common.FormatLedger, "", opts)
+	require.NoError(t, err)
+	data, err := os.ReadFile(output) // #nosec G304 -- test output path
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Dupont", "the journal is anonymized")
+	assert.NotContains(t, string(data), "CH9300762011623852957")
+
+	// An existing journal is not overwritten
+	err = common.ProcessFileWithErrorFormatted(context.Background(), p, input, output, false, logging.NewMockLogger(), appContainer, common.FormatLedger, "", opts)
+	require.ErrorIs(t, err, common.ErrOutputExists)
+}
+
+func TestWriteTransactions_BOM(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "statement.csv")
+	transactions := []models.Transaction{
+		{Date: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), Amount: decimal.NewFromInt(10), Currency: "CHF", PartyName: "Café du Commerce"},
+	}
+	opts := common.OutputOptions{BOM: true, Append: true}
+
+	// Appending to a missing file creates it with the mark, later appends add rows only
+	require.NoError(t, common.WriteTransactions(transactions, output, logging.NewMockLogger(), formatter.NewStandardFormatter(), opts))
+	require.NoError(t, common.WriteTransactions(transactions, output, logging.NewMockLogger(), formatter.NewStandardFormatter(), opts))
+	data, err := os.ReadFile(output) // #nosec G304 -- test output path
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), models.UTF8BOM+"Status,Date,"), "file must start with the byte order mark")
+	assert.Equal(t, 1, strings.Count(string(data), models.UTF8BOM))
+	assert.Equal(t, 2, strings.Count(string(data), "Café du Commerce"))
+
+	// The export reads back with the mark stripped
+	parsed, err := csvparser.ParseWithCategorizer(context.Background(), strings.NewReader(string(data)), logging.NewMockLogger(), nil)
+	require.NoError(t, err)
+	require.Len(t, parsed, 2)
+	assert.Equal(t, "Café du Commerce", parsed[0].PartyName)
+}
+
+func TestWriteTransactions_ChunkSize(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "statement.csv")
+	date := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	transactions := []models.Transaction{
+		{Date: date, Amount: decimal.NewFromInt(10), Currency: "CHF", PartyName: "Coop"},
+		{Date: date, Amount: decimal.NewFromInt(20), Currency: "CHF", PartyName: "Migros"},
+		{Date: date, Amount: decimal.NewFromInt(30), Currency: "CHF", PartyName: "Denner"},
+	}
+
+	opts := common.OutputOptions{ChunkSize: 2}
+	err := common.WriteTransactions(transactions, output, logging.NewMockLogger(), formatter.NewStandardFormatter(), opts)
+	require.NoError(t, err)
+
+	assert.NoFileExists(t, output)
+	first, err := os.ReadFile(filepath.Join(dir, "statement_001.csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(first), "Coop")
+	assert.Contains(t, string(first), "Migros")
+	assert.NotContains(t, string(first), "Denner")
+
+	second, err := os.ReadFile(filepath.Join(dir, "statement_002.csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(second), "Denner")
+	assert.NoFileExists(t, filepath.Join(dir, "statement_003.csv"))
+
+	err = common.WriteTransactions(transactions, "", logging.NewMockLogger(), formatter.NewStandardFormatter(), opts)
+	assert.ErrorContains(t, err, "--chunk-size requires an output file")
+}
+
+// failingFormatter fails to format once it has formatted ok batches.
+type failingFormatter struct {
+	formatter.OutputFormatter
+	ok int
+}
+
+func (f *failingFormatter) Format(transactions []models.Transaction) ([][]string, error) {
+	if f.ok == 0 {
+		return nil, errors.New("format failed")
+	}
+	f.ok--
+	return f.OutputFormatter.Format(transactions)
+}
+
+func TestWriteTransactions_ChunksAllOrNothing(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "statement.csv")
+	date := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	transactions := []models.Transaction{
+		{Date: date, Amount: decimal.NewFromInt(10), Currency: "CHF", PartyName: "Coop"},
+		{Date: date, Amount: decimal.NewFromInt(20), Currency: "CHF", PartyName: "Migros"},
+		{Date: date, Amount: decimal.NewFromInt(30), Currency: "CHF", PartyName: "Denner"},
+	}
+	opts := common.OutputOptions{ChunkSize: 1}
+
+	// A chunk that fails to be written leaves none of the others behind
+	err := common.WriteTransactions(transactions, output, logging.NewMockLogger(), &failingFormatter{OutputFormatter: formatter.NewStandardFormatter(), ok: 2}, opts)
+	require.ErrorContains(t, err, "format failed")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "no chunk and no temporary file is left")
+
+	// With --no-clobber, an existing chunk stops the conversion before any file is written
+	opts.NoClobber = true
+	existing := filepath.Join(dir, "statement_003.csv")
+	require.NoError(t, os.WriteFile(existing, []byte("keep me\n"), 0600))
+	err = common.WriteTransactions(transactions, output, logging.NewMockLogger(), formatter.NewStandardFormatter(), opts)
+	require.ErrorIs(t, err, common.ErrOutputExists)
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	data, err := os.ReadFile(existing) // #nosec G304 -- test output path
+	require.NoError(t, err)
+	assert.Equal(t, "keep me\n", string(data))
+}
+
+func TestAutoOutputPath(t *testing.T) {
+	dir := t.TempDir()
+	transactions := []models.Transaction{
+		{Date: time.Date(2025, 5, 14, 0, 0, 0, 0, time.UTC), IBAN: "CH93 0076 2011 6238 5295 7"},
+		{Date: time.Date(2025, 5, 2, 0, 0, 0, 0, time.UTC), IBAN: "CH93 0076 2011 6238 5295 7"},
+		{Date: time.Date(2025, 5, 30, 0, 0, 0, 0, time.UTC)},
+	}
+
+	path := common.AutoOutputPath(dir, "statement.xml", transactions, nil, logging.NewMockLogger())
+	assert.Equal(t, filepath.Join(dir, "CH93_0076_2011_6238_5295_7_2025-05-02_2025-05-30.csv"), path)
+
+	// Without an account IBAN the account comes from the input file name
+	transactions[0].IBAN, transactions[1].IBAN = "", ""
+	path = common.AutoOutputPath(dir, "in/CAMT.053_54293249_2025-05-01_2025-05-31_1.xml", transactions, nil, logging.NewMockLogger())
+	assert.Equal(t, filepath.Join(dir, "54293249_2025-05-02_2025-05-30.csv"), path)
+
+	path = common.AutoOutputPath(dir, "in/export.xml", nil, nil, logging.NewMockLogger())
+	assert.Equal(t, filepath.Join(dir, "export.csv"), path)
+
+	// The statement's reporting period is preferred over the transaction dates
+	statements := []models.StatementInfo{{
+		PeriodStart: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC),
+	}}
+	path = common.AutoOutputPath(dir, "in/export.xml", transactions, statements, logging.NewMockLogger())
+	assert.Equal(t, filepath.Join(dir, "export_2025-05-01_2025-05-31.csv"), path)
+}
+
+func TestParseOutputOptions_OutputDir(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	common.RegisterFormatFlags(cmd)
+	common.RegisterOutputDirFlag(cmd)
+	cmd.Flags().StringP("output", "o", "", "")
+	require.NoError(t, cmd.Flags().Set("output-dir", "out"))
+
+	opts, err := common.ParseOutputOptions(cmd, logging.NewMockLogger())
+	require.NoError(t, err)
+	assert.Equal(t, "out", opts.OutputDir)
+
+	require.NoError(t, cmd.Flags().Set("output", "out.csv"))
+	_, err = common.ParseOutputOptions(cmd, logging.NewMockLogger())
+	assert.ErrorContains(t, err, "--output-dir cannot be combined with --output")
+}
+
+func TestParseOutputOptions_DescriptionTemplate(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	common.RegisterFormatFlags(cmd)
+	opts, err := common.ParseOutputOptions(cmd, logging.NewMockLogger())
+	require.NoError(t, err)
+	assert.True(t, opts.DescriptionTemplate.IsZero())
+
+	require.NoError(t, cmd.Flags().Set("description-template", "{PartyName} ({Reference})"))
+	opts, err = common.ParseOutputOptions(cmd, logging.NewMockLogger())
+	require.NoError(t, err)
+	assert.Equal(t, "Coop (R1)", opts.DescriptionTemplate.Render(models.Transaction{PartyName: "Coop", Reference: "R1"}))
+
+	require.NoError(t, cmd.Flags().Set("description-template", "{Payee}"))
+	_, err = common.ParseOutputOptions(cmd, logging.NewMockLogger())
+	assert.ErrorContains(t, err, "invalid --description-template")
+}
+
+func TestWriteTransactions_SortedChunks(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "statement.csv")
+	date := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	transactions := []models.Transaction{
+		{Date: date, Amount: decimal.NewFromInt(10), Currency: "CHF", PartyName: "Coop"},
+		{Date: date, Amount: decimal.NewFromInt(30), Currency: "CHF", PartyName: "Denner"},
+		{Date: date, Amount: decimal.NewFromInt(20), Currency: "CHF", PartyName: "Migros"},
+	}
+	order, err := models.ParseSortOrder("-amount")
+	require.NoError(t, err)
+
+	opts := common.OutputOptions{Options: formatter.Options{Sort: order}, ChunkSize: 2}
+	require.NoError(t, common.WriteTransactions(transactions, output, logging.NewMockLogger(), formatter.NewStandardFormatter(), opts))
+
+	first, err := os.ReadFile(filepath.Join(dir, "statement_001.csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(first), "Denner")
+	assert.Contains(t, string(first), "Migros")
+	second, err := os.ReadFile(filepath.Join(dir, "statement_002.csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(second), "Coop")
+	assert.Equal(t, "Coop", transactions[0].PartyName, "input order is kept")
+}
+
+func TestParseOutputOptions_Sort(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	common.RegisterFormatFlags(cmd)
+	require.NoError(t, cmd.Flags().Set("sort", "category,-amount"))
+	opts, err := common.ParseOutputOptions(cmd, logging.NewMockLogger())
+	require.NoError(t, err)
+	assert.Equal(t, models.SortOrder{{Field: "category"}, {Field: "amount", Descending: true}}, opts.Sort)
+
+	require.NoError(t, cmd.Flags().Set("sort", "size"))
+	_, err = common.ParseOutputOptions(cmd, logging.NewMockLogger())
+	assert.ErrorContains(t, err, "invalid --sort")
+}
+
+func TestParseOutputOptions_ChunkSize(t *testing.T) {
+	newCmd := func(flags map[string]string) *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		common.RegisterFormatFlags(cmd)
+		common.RegisterAppendFlags(cmd)
+		common.RegisterLimitFlags(cmd)
+		for name, value := range flags {
+			require.NoError(t, cmd.Flags().Set(name, value))
+		}
+		return cmd
+	}
+
+	opts, err := common.ParseOutputOptions(newCmd(map[string]string{"chunk-size": "500"}), logging.NewMockLogger())
+	require.NoError(t, err)
+	assert.Equal(t, 500, opts.ChunkSize)
+
+	_, err = common.ParseOutputOptions(newCmd(map[string]string{"chunk-size": "-1"}), logging.NewMockLogger())
+	assert.ErrorContains(t, err, "--chunk-size must not be negative")
+
+	_, err = common.ParseOutputOptions(newCmd(map[string]string{"chunk-size": "10", "split": "by-party-iban"}), logging.NewMockLogger())
+	assert.ErrorContains(t, err, "cannot be combined with --split")
+
+	_, err = common.ParseOutputOptions(newCmd(map[string]string{"chunk-size": "10", "append": "true"}), logging.NewMockLogger())
+	assert.ErrorContains(t, err, "cannot be combined with --append")
+}
+
+func TestWithTransactionLimit(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	common.RegisterLimitFlags(cmd)
+
+	ctx, err := common.WithTransactionLimit(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Equal(t, 0, parser.MaxTransactions(ctx))
+
+	require.NoError(t, cmd.Flags().Set("max-transactions", "100"))
+	ctx, err = common.WithTransactionLimit(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Equal(t, 100, parser.MaxTransactions(ctx))
+
+	require.NoError(t, cmd.Flags().Set("max-transactions", "-5"))
+	_, err = common.WithTransactionLimit(context.Background(), cmd)
+	assert.Error(t, err)
+
+	require.NoError(t, cmd.Flags().Set("max-transactions", "0"))
+	require.NoError(t, cmd.Flags().Set("limit", "20"))
+	ctx, err = common.WithTransactionLimit(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Equal(t, 20, parser.Limit(ctx))
+
+	require.NoError(t, cmd.Flags().Set("limit", "-1"))
+	_, err = common.WithTransactionLimit(context.Background(), cmd)
+	assert.ErrorContains(t, err, "--limit")
+}
+
+func TestWithInputEncoding(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	common.RegisterInputEncodingFlag(cmd)
+
+	ctx, err := common.WithInputEncoding(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Equal(t, "auto", parser.InputEncoding(ctx))
+
+	require.NoError(t, cmd.Flags().Set("input-encoding", "Latin-1"))
+	ctx, err = common.WithInputEncoding(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Equal(t, "iso-8859-1", parser.InputEncoding(ctx))
+
+	require.NoError(t, cmd.Flags().Set("input-encoding", "utf-16"))
+	_, err = common.WithInputEncoding(context.Background(), cmd)
+	assert.ErrorContains(t, err, "invalid --input-encoding")
+}
+
+func TestWithNumberFormat(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+
+	// Commands without the flag read amounts as auto
+	ctx, err := common.WithNumberFormat(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Equal(t, models.NumberFormatAuto, parser.NumberFormat(ctx))
+
+	common.RegisterNumberFormatFlag(cmd)
+	require.NoError(t, cmd.Flags().Set("number-format", "comma"))
+	ctx, err = common.WithNumberFormat(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Equal(t, models.NumberFormatComma, parser.NumberFormat(ctx))
+
+	require.NoError(t, cmd.Flags().Set("number-format", "swiss"))
+	_, err = common.WithNumberFormat(context.Background(), cmd)
+	assert.ErrorContains(t, err, "invalid --number-format")
+}
+
+func TestWithPreset(t *testing.T) {
+	presetsFile := filepath.Join(t.TempDir(), "presets.yaml")
+	require.NoError(t, os.WriteFile(presetsFile, []byte(`presets:
+  - name: bank
+    parser: debit
+    number_format: comma
+    input_encoding: windows-1252
+    locale: de-DE
+    cleanup:
+      - action: strip_prefix
+        values: ["ACHAT "]
+`), 0600))
+	cfg := &config.Config{}
+	cfg.Log.Level = "info"
+	cfg.Log.Format = "text"
+	cfg.Parsers.PresetsFile = presetsFile
+	appContainer, err := container.NewContainer(cfg)
+	require.NoError(t, err)
+
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		common.RegisterFormatFlags(cmd)
+		common.RegisterInputEncodingFlag(cmd)
+		common.RegisterPresetFlag(cmd)
+		return cmd
+	}
+
+	// Without --preset nothing changes
+	var opts formatter.Options
+	ctx, parserName, err := common.WithPreset(context.Background(), newCmd(), appContainer, &opts)
+	require.NoError(t, err)
+	assert.Empty(t, parserName)
+	assert.Equal(t, models.NumberFormatAuto, parser.NumberFormat(ctx))
+
+	cmd := newCmd()
+	require.NoError(t, cmd.Flags().Set("preset", "bank"))
+	ctx, parserName, err = common.WithPreset(context.Background(), cmd, appContainer, &opts)
+	require.NoError(t, err)
+	assert.Equal(t, "debit", parserName)
+	assert.Equal(t, models.NumberFormatComma, parser.NumberFormat(ctx))
+	assert.Equal(t, "windows-1252", parser.InputEncoding(ctx))
+	assert.Equal(t, "de-DE", opts.Locale.Name)
+	assert.Equal(t, "Migros", appContainer.GetCategorizer().CleanPartyName("ACHAT Migros"))
+
+	// Flags given explicitly take precedence
+	cmd = newCmd()
+	require.NoError(t, cmd.Flags().Set("preset", "bank"))
+	require.NoError(t, cmd.Flags().Set("input-encoding", "utf-8"))
+	require.NoError(t, cmd.Flags().Set("locale", "en-US"))
+	opts = formatter.Options{}
+	ctx, _, err = common.WithPreset(parser.WithInputEncoding(context.Background(), "utf-8"), cmd, appContainer, &opts)
+	require.NoError(t, err)
+	assert.Equal(t, "utf-8", parser.InputEncoding(ctx))
+	assert.Empty(t, opts.Locale.Name)
+
+	cmd = newCmd()
+	require.NoError(t, cmd.Flags().Set("preset", "ubs"))
+	_, _, err = common.WithPreset(context.Background(), cmd, appContainer, &opts)
+	assert.ErrorContains(t, err, "invalid --preset: bank preset not found: ubs")
+}
+
+func TestWithAccount(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+
+	// Commands without the flag write every account
+	ctx, err := common.WithAccount(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Empty(t, parser.Account(ctx))
+
+	common.RegisterAccountFlag(cmd)
+	require.NoError(t, cmd.Flags().Set("account", "CH9300762011623852957"))
+	ctx, err = common.WithAccount(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Equal(t, "CH9300762011623852957", parser.Account(ctx))
+
+	require.NoError(t, cmd.Flags().Set("account", "  "))
+	_, err = common.WithAccount(context.Background(), cmd)
+	assert.ErrorContains(t, err, "invalid --account")
+}
+
+func TestWithTransactionFilter(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	common.RegisterFilterFlags(cmd)
+	transactions := []models.Transaction{
+		{Description: "SBB Ticket Lausanne"},
+		{Description: "Coop"},
+	}
+
+	ctx, err := common.WithTransactionFilter(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Len(t, parser.FilterTransactions(ctx, transactions), 2)
+
+	// Case-insensitive by default
+	require.NoError(t, cmd.Flags().Set("filter-description", "sbb"))
+	ctx, err = common.WithTransactionFilter(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Equal(t, transactions[:1], parser.FilterTransactions(ctx, transactions))
+
+	// (?-i) makes the match case-sensitive
+	require.NoError(t, cmd.Flags().Set("filter-description", "(?-i)sbb"))
+	ctx, err = common.WithTransactionFilter(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Empty(t, parser.FilterTransactions(ctx, transactions))
+
+	require.NoError(t, cmd.Flags().Set("filter-description", "sbb("))
+	_, err = common.WithTransactionFilter(context.Background(), cmd)
+	assert.ErrorContains(t, err, "invalid --filter-description")
+}
+
+func TestWithTransactionFilter_SkipZero(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	common.RegisterFilterFlags(cmd)
+	transactions := []models.Transaction{
+		{Description: "Notice", Amount: decimal.Zero},
+		{Description: "Coop", Amount: decimal.RequireFromString("-8.40")},
+	}
+
+	// Off by default
+	ctx, err := common.WithTransactionFilter(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Len(t, parser.FilterTransactions(ctx, transactions), 2)
+
+	require.NoError(t, cmd.Flags().Set("skip-zero", "true"))
+	ctx, err = common.WithTransactionFilter(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Equal(t, transactions[1:], parser.FilterTransactions(ctx, transactions))
+}
+
+func TestWithTransactionFilter_DropEmpty(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	common.RegisterFilterFlags(cmd)
+	transactions := []models.Transaction{
+		{Reference: "REF-1"},
+		{PartyName: "Coop"},
+	}
+
+	// Kept by default
+	ctx, err := common.WithTransactionFilter(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Len(t, parser.FilterTransactions(ctx, transactions), 2)
+
+	require.NoError(t, cmd.Flags().Set("drop-empty", "true"))
+	ctx, err = common.WithTransactionFilter(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Equal(t, transactions[1:], parser.FilterTransactions(ctx, transactions))
+}
+
+func TestWithTransactionFilter_Status(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	common.RegisterFilterFlags(cmd)
+	transactions := []models.Transaction{
+		{Description: "Coop", Status: models.EntryStatusBooked},
+		{Description: "Migros", Status: models.EntryStatusPending},
+	}
+
+	// All statuses by default
+	ctx, err := common.WithTransactionFilter(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Len(t, parser.FilterTransactions(ctx, transactions), 2)
+
+	require.NoError(t, cmd.Flags().Set("status", "booked"))
+	ctx, err = common.WithTransactionFilter(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.Equal(t, transactions[:1], parser.FilterTransactions(ctx, transactions))
+
+	require.NoError(t, cmd.Flags().Set("status", "settled"))
+	_, err = common.WithTransactionFilter(context.Background(), cmd)
+	assert.ErrorContains(t, err, "invalid --status")
+}
+
+func TestApplyCategorizeFlag(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	common.RegisterCategorizeFlag(cmd)
+
+	// Without the flag the parser keeps its categorizer
+	p := &MockFullParser{}
+	common.ApplyCategorizeFlag(cmd, p, logging.NewMockLogger())
+	p.AssertNotCalled(t, "SetCategorizer", mock.Anything)
+
+	require.NoError(t, cmd.Flags().Set("no-categorize", "true"))
+	p.On("SetCategorizer", nil).Return()
+	common.ApplyCategorizeFlag(cmd, p, logging.NewMockLogger())
+	p.AssertCalled(t, "SetCategorizer", nil)
+}
+
+// upperCleaner is a categorizer that cleans party names by uppercasing them
+// and must never be asked to categorize.
+type upperCleaner struct{ t *testing.T }
+
+func (c upperCleaner) Categorize(context.Context, string, bool, string, string, string) (models.Category, error) {
+	c.t.Error("Categorize called with --no-categorize")
+	return models.Category{}, nil
+}
+
+func (c upperCleaner) CleanPartyName(name string) string {
+	return strings.ToUpper(name)
+}
+
+func TestApplyCategorizeFlag_KeepsNameCleanup(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	common.RegisterCategorizeFlag(cmd)
+	require.NoError(t, cmd.Flags().Set("no-categorize", "true"))
+
+	p := debitparser.NewAdapter(logging.NewMockLogger())
+	p.SetCategorizer(upperCleaner{t})
+	common.ApplyCategorizeFlag(cmd, p, logging.NewMockLogger())
+
+	transactions, err := p.Parse(context.Background(), strings.NewReader("Bénéficiaire;Date;Montant;Monnaie\nPMT CARTE Ratp;15.04.2025;-4,21;CHF\n"))
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, "RATP", transactions[0].PartyName)
+	assert.Equal(t, models.CategoryUncategorized, transactions[0].Category)
+	assert.Equal(t, models.CategorySourceFallback, transactions[0].CategorySource)
+}
+
+func TestParseOutputOptions_Anonymize(t *testing.T) {
+	newCmd := func(flags map[string]string) *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		common.RegisterFormatFlags(cmd)
+		common.RegisterAppendFlags(cmd)
+		common.RegisterOutputDirFlag(cmd)
+		for name, value := range flags {
+			require.NoError(t, cmd.Flags().Set(name, value))
+		}
+		return cmd
+	}
+
+	opts, err := common.ParseOutputOptions(newCmd(map[string]string{"anonymize": "true"}), logging.NewMockLogger())
+	require.NoError(t, err)
+	assert.True(t, opts.Anonymize)
+
+	// File names built from IBANs would expose them
+	_, err = common.ParseOutputOptions(newCmd(map[string]string{"anonymize": "true", "split": "by-party-iban"}), logging.NewMockLogger())
+	assert.ErrorContains(t, err, "cannot be combined with --split")
+
+	_, err = common.ParseOutputOptions(newCmd(map[string]string{"anonymize": "true", "output-dir": "out"}), logging.NewMockLogger())
+	assert.ErrorContains(t, err, "cannot be combined with --output-dir")
+}
+
+func TestParseOutputOptions_Columns(t *testing.T) {
+	newCmd := func(columns string) *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		common.RegisterFormatFlags(cmd)
+		common.RegisterAppendFlags(cmd)
+		require.NoError(t, cmd.Flags().Set("columns", columns))
+		return cmd
+	}
+
+	opts, err := common.ParseOutputOptions(newCmd("Amount, Date,Name"), logging.NewMockLogger())
+	require.NoError(t, err)
+	require.NotNil(t, opts.Profile)
+	assert.Equal(t, []string{"Amount", "Date", "Name"}, opts.Profile.Columns)
+
+	_, err = common.ParseOutputOptions(newCmd("Date,Bogus"), logging.NewMockLogger())
+	assert.ErrorContains(t, err, `unknown column "Bogus"`)
+
+	_, err = common.ParseOutputOptions(newCmd("Date,Date"), logging.NewMockLogger())
+	assert.ErrorContains(t, err, `duplicate column "Date"`)
+}
+
+func TestParseOutputOptions_Locale(t *testing.T) {
+	newCmd := func(locale string) *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		common.RegisterFormatFlags(cmd)
+		common.RegisterAppendFlags(cmd)
+		require.NoError(t, cmd.Flags().Set("locale", locale))
+		return cmd
+	}
+
+	opts, err := common.ParseOutputOptions(newCmd("de-DE"), logging.NewMockLogger())
+	require.NoError(t, err)
+	assert.Equal(t, "de-DE", opts.Locale.Name)
+
+	_, err = common.ParseOutputOptions(newCmd("klingon"), logging.NewMockLogger())
+	assert.ErrorContains(t, err, "invalid --locale")
+}
+
+func TestParseUncategorizedThreshold(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    common.UncategorizedThreshold
+		wantErr bool
+	}{
+		{"0", common.UncategorizedThreshold{Limit: 0}, false},
+		{"12", common.UncategorizedThreshold{Limit: 12}, false},
+		{"5%", common.UncategorizedThreshold{Limit: 5, Percent: true}, false},
+		{" 2.5% ", common.UncategorizedThreshold{Limit: 2.5, Percent: true}, false},
+		{"2.5", common.UncategorizedThreshold{}, true},
+		{"-1", common.UncategorizedThreshold{}, true},
+		{"101%", common.UncategorizedThreshold{}, true},
+		{"many", common.UncategorizedThreshold{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := common.ParseUncategorizedThreshold(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, strings.TrimSpace(tt.input), got.String())
+		})
+	}
+}
+
+func TestUncategorizedThreshold_Exceeded(t *testing.T) {
+	stats := models.CategorizationStats{Total: 40, Uncategorized: 2}
+
+	assert.True(t, common.UncategorizedThreshold{Limit: 0}.Exceeded(stats))
+	assert.False(t, common.UncategorizedThreshold{Limit: 2}.Exceeded(stats))
+	assert.False(t, common.UncategorizedThreshold{Limit: 5, Percent: true}.Exceeded(stats))
+	assert.True(t, common.UncategorizedThreshold{Limit: 4.9, Percent: true}.Exceeded(stats))
+	assert.False(t, common.UncategorizedThreshold{Limit: 0, Percent: true}.Exceeded(models.CategorizationStats{}))
+}
+
+func TestWithUncategorizedCheck(t *testing.T) {
+	var exitCode int
+	restore := common.SetOsExitFn(func(code int) { exitCode = code })
+	defer restore()
+
+	newCmd := func(args ...string) *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		common.RegisterUncategorizedFlag(cmd)
+		common.RegisterCategorizeFlag(cmd)
+		require.NoError(t, cmd.ParseFlags(args))
+		return cmd
+	}
+	uncategorized := []models.Transaction{{Category: models.CategoryUncategorized}}
+
+	// Not set: no stats are collected and the check never exits
+	ctx, check, err := common.WithUncategorizedCheck(context.Background(), newCmd(), logging.NewMockLogger())
+	require.NoError(t, err)
+	parser.RecordCategorization(ctx, uncategorized)
+	check()
+	assert.Equal(t, 0, exitCode)
+
+	// Without a value any uncategorized transaction fails the run
+	logger := logging.NewMockLogger()
+	ctx, check, err = common.WithUncategorizedCheck(context.Background(), newCmd("--fail-on-uncategorized"), logger)
+	require.NoError(t, err)
+	parser.RecordCategorization(ctx, uncategorized)
+	check()
+	assert.Equal(t, common.ExitCodeUncategorized, exitCode)
+	assert.True(t, logger.HasEntry("ERROR", "Too many uncategorized transactions"))
+
+	exitCode = 0
+	ctx, check, err = common.WithUncategorizedCheck(context.Background(), newCmd("--fail-on-uncategorized=1"), logging.NewMockLogger())
+	require.NoError(t, err)
+	parser.RecordCategorization(ctx, uncategorized)
+	check()
+	assert.Equal(t, 0, exitCode)
+
+	_, _, err = common.WithUncategorizedCheck(context.Background(), newCmd("--fail-on-uncategorized=x"), logging.NewMockLogger())
+	assert.Error(t, err)
+
+	_, _, err = common.WithUncategorizedCheck(context.Background(), newCmd("--fail-on-uncategorized", "--no-categorize"), logging.NewMockLogger())
+	assert.ErrorContains(t, err, "--no-categorize")
+}
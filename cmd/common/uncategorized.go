@@ -0,0 +1,61 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fjacquet/camt-csv/internal/models"
+)
+
+// ExitCodeUncategorized is the exit status when more transactions than allowed
+// by --fail-on-uncategorized were left uncategorized. It differs from the batch
+// exit statuses (1 partial failure, 2 total failure) so CI jobs can tell them apart.
+const ExitCodeUncategorized = 3
+
+// UncategorizedThreshold is the limit set with --fail-on-uncategorized: a number
+// of uncategorized transactions, or a percentage of all transactions.
+type UncategorizedThreshold struct {
+	Limit   float64
+	Percent bool
+}
+
+// ParseUncategorizedThreshold parses a threshold such as "0", "5" or "2.5%".
+func ParseUncategorizedThreshold(s string) (UncategorizedThreshold, error) {
+	s = strings.TrimSpace(s)
+	number, percent := strings.CutSuffix(s, "%")
+
+	if percent {
+		limit, err := strconv.ParseFloat(number, 64)
+		if err != nil || limit < 0 || limit > 100 {
+			return UncategorizedThreshold{}, fmt.Errorf("invalid threshold %q: want a percentage between 0%% and 100%%", s)
+		}
+		return UncategorizedThreshold{Limit: limit, Percent: true}, nil
+	}
+
+	limit, err := strconv.Atoi(number)
+	if err != nil || limit < 0 {
+		return UncategorizedThreshold{}, fmt.Errorf("invalid threshold %q: want a number of transactions or a percentage such as 5%%", s)
+	}
+	return UncategorizedThreshold{Limit: float64(limit)}, nil
+}
+
+// Exceeded reports whether the uncategorized transactions in stats are above the threshold.
+func (t UncategorizedThreshold) Exceeded(stats models.CategorizationStats) bool {
+	if !t.Percent {
+		return float64(stats.Uncategorized) > t.Limit
+	}
+	if stats.Total == 0 {
+		return false
+	}
+	return float64(stats.Uncategorized)*100/float64(stats.Total) > t.Limit
+}
+
+// String returns the threshold as given on the command line.
+func (t UncategorizedThreshold) String() string {
+	limit := strconv.FormatFloat(t.Limit, 'f', -1, 64)
+	if t.Percent {
+		return limit + "%"
+	}
+	return limit
+}
@@ -18,4 +18,17 @@ var Cmd = &cobra.Command{
 	},
 }
 
-func init() { common.RegisterFormatFlags(Cmd) }
+func init() {
+	common.RegisterFormatFlags(Cmd)
+	common.RegisterLimitFlags(Cmd)
+	common.RegisterInputEncodingFlag(Cmd)
+	common.RegisterNumberFormatFlag(Cmd)
+	common.RegisterFilterFlags(Cmd)
+	common.RegisterOutputDirFlag(Cmd)
+	common.RegisterUncategorizedFlag(Cmd)
+	common.RegisterNoClobberFlag(Cmd)
+	common.RegisterRecursiveFlag(Cmd)
+	common.RegisterWorkbookFlag(Cmd)
+	common.RegisterAppendFlags(Cmd)
+	common.RegisterCategorizeFlag(Cmd)
+}
@@ -0,0 +1,171 @@
+// Package diff handles the command comparing two CSV exports
+package diff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"fjacquet/camt-csv/cmd/root"
+	"fjacquet/camt-csv/internal/csvparser"
+	"fjacquet/camt-csv/internal/dateutils"
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+// Cmd represents the diff command
+var Cmd = &cobra.Command{
+	Use:   "diff OLD.csv NEW.csv",
+	Short: "Compare two CSV exports",
+	Long: `Compare two CSV files written by camt-csv, for example the same month
+converted before and after a change to the mapping files, and list the
+transactions that were added, removed or given another category.
+
+Transactions are matched by a hash of their dates, party, description, amount,
+direction and references, so a change of category is not seen as a different
+transaction.
+
+Examples:
+  camt-csv diff before/2025-03.csv after/2025-03.csv
+  camt-csv diff before.csv after.csv --format json`,
+	Args: cobra.ExactArgs(2),
+	Run:  diffFunc,
+}
+
+func init() {
+	Cmd.Flags().String("format", "table", "Output format: table or json")
+}
+
+func diffFunc(cmd *cobra.Command, args []string) {
+	logger := root.GetLogrusAdapter()
+	format, _ := cmd.Flags().GetString("format")
+	if format != "table" && format != "json" {
+		logger.Fatalf("Invalid --format %q: valid formats are table, json", format)
+	}
+
+	old, err := readExport(cmd.Context(), args[0], logger)
+	if err != nil {
+		logger.Fatalf("Error reading %s: %v", args[0], err)
+	}
+	updated, err := readExport(cmd.Context(), args[1], logger)
+	if err != nil {
+		logger.Fatalf("Error reading %s: %v", args[1], err)
+	}
+
+	diff := models.DiffTransactions(old, updated)
+	if err := printDiff(cmd.OutOrStdout(), diff, format); err != nil {
+		logger.Fatalf("Error printing diff: %v", err)
+	}
+}
+
+// readExport reads the transactions of a CSV written by camt-csv, keeping
+// the categories recorded in it.
+func readExport(ctx context.Context, path string, logger logging.Logger) ([]models.Transaction, error) {
+	file, err := os.Open(path) // #nosec G304 -- CLI tool requires user-provided file paths
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			logger.WithError(err).Warn("Failed to close input file",
+				logging.Field{Key: "file", Value: path})
+		}
+	}()
+	return csvparser.ParseWithCategorizer(ctx, file, logger, nil)
+}
+
+// jsonTransaction is the JSON form of a transaction in the diff.
+type jsonTransaction struct {
+	Date        string `json:"date"`
+	Party       string `json:"party"`
+	Description string `json:"description"`
+	Amount      string `json:"amount"`
+	Currency    string `json:"currency"`
+	Category    string `json:"category,omitempty"`
+	OldCategory string `json:"old_category,omitempty"`
+	NewCategory string `json:"new_category,omitempty"`
+}
+
+// newJSONTransaction returns the JSON form of tx.
+func newJSONTransaction(tx models.Transaction) jsonTransaction {
+	return jsonTransaction{
+		Date:        tx.Date.Format(dateutils.DateLayoutISO),
+		Party:       tx.PartyName,
+		Description: tx.Description,
+		Amount:      signedAmount(tx),
+		Currency:    tx.Currency,
+		Category:    tx.Category,
+	}
+}
+
+// signedAmount returns the amount of tx, negative for debits.
+func signedAmount(tx models.Transaction) string {
+	amount := tx.Amount.Abs()
+	if tx.IsDebit() {
+		amount = amount.Neg()
+	}
+	return amount.StringFixed(2)
+}
+
+// printDiff writes diff to w in the given format.
+func printDiff(w io.Writer, diff models.TransactionDiff, format string) error {
+	switch format {
+	case "table":
+		_, _ = fmt.Fprintf(w, "%d added, %d removed, %d recategorized\n",
+			len(diff.Added), len(diff.Removed), len(diff.Recategorized))
+		if diff.Empty() {
+			return nil
+		}
+		_, _ = fmt.Fprintln(w)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "CHANGE\tDATE\tPARTY\tAMOUNT\tCATEGORY\t")
+		for _, tx := range diff.Added {
+			printRow(tw, "+", tx, tx.Category)
+		}
+		for _, tx := range diff.Removed {
+			printRow(tw, "-", tx, tx.Category)
+		}
+		for _, change := range diff.Recategorized {
+			printRow(tw, "~", change.Transaction, change.OldCategory+" -> "+change.NewCategory)
+		}
+		return tw.Flush()
+	case "json":
+		out := map[string][]jsonTransaction{
+			"added":         make([]jsonTransaction, 0, len(diff.Added)),
+			"removed":       make([]jsonTransaction, 0, len(diff.Removed)),
+			"recategorized": make([]jsonTransaction, 0, len(diff.Recategorized)),
+		}
+		for _, tx := range diff.Added {
+			out["added"] = append(out["added"], newJSONTransaction(tx))
+		}
+		for _, tx := range diff.Removed {
+			out["removed"] = append(out["removed"], newJSONTransaction(tx))
+		}
+		for _, change := range diff.Recategorized {
+			line := newJSONTransaction(change.Transaction)
+			line.Category = ""
+			line.OldCategory, line.NewCategory = change.OldCategory, change.NewCategory
+			out["recategorized"] = append(out["recategorized"], line)
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(out)
+	default:
+		return fmt.Errorf("invalid --format %q: valid formats are table, json", format)
+	}
+}
+
+// printRow writes one transaction of the table.
+func printRow(w io.Writer, change string, tx models.Transaction, category string) {
+	party := tx.PartyName
+	if party == "" {
+		party = tx.Description
+	}
+	_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s %s\t%s\t\n", change,
+		tx.Date.Format(dateutils.DateLayoutEuropean), party, signedAmount(tx), tx.Currency, category)
+}
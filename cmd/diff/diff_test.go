@@ -0,0 +1,88 @@
+package diff
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	oldExport = `Date,PartyName,Description,Amount,CreditDebit,Currency,Category
+04.03.2025,Migros,Migros Lausanne,42.10,DBIT,CHF,Uncategorized
+05.03.2025,SBB,SBB Ticket,18.00,DBIT,CHF,Transport
+`
+	newExport = `# Statement note: March
+Date,PartyName,Description,Amount,CreditDebit,Currency,Category
+04.03.2025,Migros,Migros Lausanne,42.10,DBIT,CHF,Courses
+06.03.2025,Employer SA,Salary,4200.00,CRDT,CHF,Salaire
+`
+)
+
+func readDiff(t *testing.T) models.TransactionDiff {
+	t.Helper()
+	dir := t.TempDir()
+	oldFile, newFile := filepath.Join(dir, "old.csv"), filepath.Join(dir, "new.csv")
+	require.NoError(t, os.WriteFile(oldFile, []byte(oldExport), 0600))
+	require.NoError(t, os.WriteFile(newFile, []byte(newExport), 0600))
+
+	old, err := readExport(context.Background(), oldFile, logging.NewMockLogger())
+	require.NoError(t, err)
+	updated, err := readExport(context.Background(), newFile, logging.NewMockLogger())
+	require.NoError(t, err)
+	return models.DiffTransactions(old, updated)
+}
+
+func TestDiffCommand_Metadata(t *testing.T) {
+	assert.Equal(t, "diff OLD.csv NEW.csv", Cmd.Use)
+	assert.Equal(t, "table", Cmd.Flags().Lookup("format").DefValue)
+	assert.Error(t, Cmd.Args(Cmd, []string{"old.csv"}))
+}
+
+func TestPrintDiff(t *testing.T) {
+	diff := readDiff(t)
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, printDiff(&buf, diff, "table"))
+		out := buf.String()
+		assert.Contains(t, out, "1 added, 1 removed, 1 recategorized")
+		assert.Regexp(t, `\+\s+06\.03\.2025\s+Employer SA\s+4200\.00 CHF\s+Salaire`, out)
+		assert.Regexp(t, `-\s+05\.03\.2025\s+SBB\s+-18\.00 CHF\s+Transport`, out)
+		assert.Regexp(t, `~\s+04\.03\.2025\s+Migros\s+-42\.10 CHF\s+Uncategorized -> Courses`, out)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, printDiff(&buf, diff, "json"))
+		var got map[string][]jsonTransaction
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+		require.Len(t, got["added"], 1)
+		assert.Equal(t, "2025-03-06", got["added"][0].Date)
+		assert.Equal(t, "Salaire", got["added"][0].Category)
+		require.Len(t, got["removed"], 1)
+		assert.Equal(t, "-18.00", got["removed"][0].Amount)
+		require.Len(t, got["recategorized"], 1)
+		assert.Equal(t, "Uncategorized", got["recategorized"][0].OldCategory)
+		assert.Equal(t, "Courses", got["recategorized"][0].NewCategory)
+		assert.Empty(t, got["recategorized"][0].Category)
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, printDiff(&buf, models.TransactionDiff{}, "table"))
+		assert.Equal(t, "0 added, 0 removed, 0 recategorized\n", buf.String())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		assert.Error(t, printDiff(&bytes.Buffer{}, diff, "xml"))
+	})
+}
@@ -0,0 +1,55 @@
+// Package doctor handles the environment check command
+package doctor
+
+import (
+	"os"
+
+	"fjacquet/camt-csv/internal/config"
+	"fjacquet/camt-csv/internal/doctor"
+
+	"github.com/spf13/cobra"
+)
+
+// osExitFn is the function used to exit the process. Replaced in tests to avoid os.Exit.
+var osExitFn = os.Exit
+
+// Cmd represents the doctor command
+var Cmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that the environment is set up",
+	Long: `Check the environment camt-csv runs in and print a checklist:
+
+  - the pdftotext binary, needed by the pdf command
+  - the configuration and the directories it is read from
+  - the AI API key
+  - the categories, creditors, debtors and tags YAML files
+  - write access for saving learned mappings
+
+Each problem comes with a hint on how to fix it. The command exits with
+status 1 when a critical check fails; warnings do not change the status.`,
+	// The configuration is loaded by the checks themselves, so that a broken
+	// one is reported instead of aborting, and no mappings are saved
+	PersistentPreRun:  func(cmd *cobra.Command, args []string) {},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {},
+	Run:               doctorFunc,
+}
+
+func doctorFunc(cmd *cobra.Command, _ []string) {
+	cfg, err := config.InitializeConfig()
+
+	dirs := make([]string, 0, len(config.ConfigSearchPaths))
+	for _, dir := range config.ConfigSearchPaths {
+		dirs = append(dirs, os.ExpandEnv(dir))
+	}
+
+	results := doctor.Run(doctor.Environment{
+		Config:     cfg,
+		ConfigErr:  err,
+		ConfigDirs: dirs,
+	})
+	doctor.Print(cmd.OutOrStdout(), results)
+
+	if doctor.HasFailures(results) {
+		osExitFn(1)
+	}
+}
@@ -0,0 +1,33 @@
+// Package mt940 handles MT940 statement conversion commands.
+package mt940
+
+import (
+	"fjacquet/camt-csv/cmd/common"
+	"fjacquet/camt-csv/internal/container"
+
+	"github.com/spf13/cobra"
+)
+
+// Cmd represents the mt940 command.
+var Cmd = &cobra.Command{
+	Use:   "mt940",
+	Short: "Convert MT940 statements to CSV",
+	Long:  `Convert SWIFT MT940 account statements (.sta files, e.g. from Postbank or Deutsche Bank) to CSV format.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		common.RunConvert(cmd, args, container.MT940, "MT940")
+	},
+}
+
+func init() {
+	common.RegisterFormatFlags(Cmd)
+	common.RegisterLimitFlags(Cmd)
+	common.RegisterInputEncodingFlag(Cmd)
+	common.RegisterFilterFlags(Cmd)
+	common.RegisterOutputDirFlag(Cmd)
+	common.RegisterUncategorizedFlag(Cmd)
+	common.RegisterNoClobberFlag(Cmd)
+	common.RegisterRecursiveFlag(Cmd)
+	common.RegisterWorkbookFlag(Cmd)
+	common.RegisterAppendFlags(Cmd)
+	common.RegisterCategorizeFlag(Cmd)
+}
@@ -6,17 +6,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 
 	"fjacquet/camt-csv/cmd/common"
 	"fjacquet/camt-csv/cmd/root"
-	internalcommon "fjacquet/camt-csv/internal/common"
 	"fjacquet/camt-csv/internal/container"
 	"fjacquet/camt-csv/internal/formatter"
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
 	"fjacquet/camt-csv/internal/parser"
+	"fjacquet/camt-csv/internal/progress"
 
 	"github.com/spf13/cobra"
 )
@@ -45,6 +44,34 @@ transactions into a single CSV file, sorted chronologically by date.`,
 
 func init() {
 	common.RegisterFormatFlags(Cmd)
+	common.RegisterLimitFlags(Cmd)
+	common.RegisterInputEncodingFlag(Cmd)
+	common.RegisterFilterFlags(Cmd)
+	common.RegisterUncategorizedFlag(Cmd)
+	common.RegisterNoClobberFlag(Cmd)
+	common.RegisterAppendFlags(Cmd)
+	common.RegisterCategorizeFlag(Cmd)
+	Cmd.Flags().Bool("strict", false,
+		"Fail instead of warning when a Viseca statement total does not match the parsed transactions")
+	Cmd.Flags().Bool("ocr", false,
+		"Read PDFs without extractable text, such as scanned statements, with tesseract OCR")
+	Cmd.Flags().String("refund-category", "",
+		"Category given to Viseca card refunds instead of the merchant's (default from parsers.pdf.refund_category)")
+}
+
+// strictSetter is implemented by parsers that can turn consistency warnings into errors.
+type strictSetter interface {
+	SetStrict(strict bool)
+}
+
+// refundCategorySetter is implemented by parsers that recognize card refunds.
+type refundCategorySetter interface {
+	SetRefundCategory(category string)
+}
+
+// ocrEnabler is implemented by parsers that can fall back to OCR for scanned documents.
+type ocrEnabler interface {
+	EnableOCR() error
 }
 
 func pdfFunc(cmd *cobra.Command, _ []string) {
@@ -59,6 +86,26 @@ func pdfFunc(cmd *cobra.Command, _ []string) {
 	// Get format flags
 	format, _ := cmd.Flags().GetString("format")
 	dateFormat, _ := cmd.Flags().GetString("date-format")
+	opts, err := common.ParseOutputOptions(cmd, logger)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+	ctx, err = common.WithTransactionLimit(ctx, cmd)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+	ctx, err = common.WithInputEncoding(ctx, cmd)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+	ctx, err = common.WithTransactionFilter(ctx, cmd)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+	ctx, checkUncategorized, err := common.WithUncategorizedCheck(ctx, cmd, logger)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
 
 	// Get container from root command context
 	appContainer := root.GetContainer()
@@ -75,6 +122,26 @@ func pdfFunc(cmd *cobra.Command, _ []string) {
 	if err != nil {
 		logger.Fatalf("Error getting PDF parser: %v", err)
 	}
+	common.ApplyCategorizeFlag(cmd, p, logger)
+	if strict, _ := cmd.Flags().GetBool("strict"); strict {
+		if s, ok := p.(strictSetter); ok {
+			s.SetStrict(true)
+		}
+	}
+	refundCategory := appContainer.GetConfig().Parsers.PDF.RefundCategory
+	if cmd.Flags().Changed("refund-category") {
+		refundCategory, _ = cmd.Flags().GetString("refund-category")
+	}
+	if r, ok := p.(refundCategorySetter); ok {
+		r.SetRefundCategory(refundCategory)
+	}
+	if ocr, _ := cmd.Flags().GetBool("ocr"); ocr {
+		if o, ok := p.(ocrEnabler); ok {
+			if err := o.EnableOCR(); err != nil {
+				logger.Fatalf("Cannot enable OCR: %v", err)
+			}
+		}
+	}
 
 	// Check if input is directory or file
 	fileInfo, err := os.Stat(inputPath)
@@ -93,24 +160,26 @@ func pdfFunc(cmd *cobra.Command, _ []string) {
 			outputPath = filepath.Join(outputPath, filepath.Base(inputPath)+".csv")
 			logger.Infof("Output is a directory, writing to: %s", outputPath)
 		}
+		ctx = progress.WithReporter(ctx, common.NewProgress("Consolidating"))
 		count, err := consolidatePDFDirectory(ctx, p, inputPath,
 			outputPath, root.SharedFlags.Validate, logger,
-			format, dateFormat)
+			format, dateFormat, opts)
 		if err != nil {
 			logger.Fatalf("Error consolidating PDFs: %v", err)
 		}
 		logger.Infof("Consolidated %d PDF files successfully!", count)
 	} else {
 		common.ProcessFile(ctx, p, inputPath, root.SharedFlags.Output,
-			root.SharedFlags.Validate, root.Log, appContainer, format, dateFormat)
+			root.SharedFlags.Validate, root.Log, appContainer, format, dateFormat, opts)
 		root.Log.Info("PDF to CSV conversion completed successfully!")
 	}
+	checkUncategorized()
 }
 
 // consolidatePDFDirectory consolidates all PDF files in a directory into a single CSV
 func consolidatePDFDirectory(ctx context.Context, p parser.FullParser,
 	inputDir, outputFile string, validate bool, logger logging.Logger,
-	format string, _ string) (int, error) {
+	format string, _ string, opts common.OutputOptions) (int, error) {
 
 	logger.Info("Consolidating PDF files from directory",
 		logging.Field{Key: "inputDir", Value: inputDir},
@@ -144,10 +213,14 @@ func consolidatePDFDirectory(ctx context.Context, p parser.FullParser,
 	var allTransactions []models.Transaction
 	processedCount := 0
 
+	bar := progress.FromContext(ctx)
+	bar.Start(len(pdfFiles), "files")
+
 	for _, pdfFile := range pdfFiles {
 		// Check for cancellation
 		select {
 		case <-ctx.Done():
+			bar.Finish()
 			logger.Warn("Consolidation cancelled",
 				logging.Field{Key: "processed", Value: processedCount},
 				logging.Field{Key: "total", Value: len(pdfFiles)})
@@ -155,51 +228,17 @@ func consolidatePDFDirectory(ctx context.Context, p parser.FullParser,
 		default:
 		}
 
-		logger.Debug("Processing PDF", logging.Field{Key: "file", Value: filepath.Base(pdfFile)})
-
-		// Validate if requested
-		if validate {
-			isValid, err := p.ValidateFormat(pdfFile)
-			if err != nil {
-				logger.WithError(err).Warn("Error validating PDF",
-					logging.Field{Key: "file", Value: filepath.Base(pdfFile)})
-				continue // Skip this file
-			}
-			if !isValid {
-				logger.Warn("Skipping invalid PDF",
-					logging.Field{Key: "file", Value: filepath.Base(pdfFile)})
-				continue
-			}
-		}
-
-		// Open and parse the file
-		file, err := os.Open(pdfFile) // #nosec G304 -- CLI tool requires user-provided paths
-		if err != nil {
-			logger.WithError(err).Warn("Failed to open PDF",
-				logging.Field{Key: "file", Value: filepath.Base(pdfFile)})
-			continue
-		}
-
-		transactions, err := p.Parse(ctx, file)
-		if closeErr := file.Close(); closeErr != nil {
-			logger.WithError(closeErr).Warn("Failed to close PDF file",
-				logging.Field{Key: "file", Value: filepath.Base(pdfFile)})
-		}
-
-		if err != nil {
-			logger.WithError(err).Warn("Failed to parse PDF",
-				logging.Field{Key: "file", Value: filepath.Base(pdfFile)})
+		transactions, ok := parseConsolidatedPDF(ctx, p, pdfFile, validate, logger)
+		bar.Increment()
+		if !ok {
 			continue
 		}
-
-		logger.Debug("Parsed transactions",
-			logging.Field{Key: "file", Value: filepath.Base(pdfFile)},
-			logging.Field{Key: "count", Value: len(transactions)})
-
 		allTransactions = append(allTransactions, transactions...)
 		processedCount++
 	}
 
+	bar.Finish()
+
 	if len(allTransactions) == 0 {
 		logger.Warn("No transactions found in any PDF files")
 		return processedCount, fmt.Errorf("no transactions extracted from PDF files")
@@ -207,6 +246,7 @@ func consolidatePDFDirectory(ctx context.Context, p parser.FullParser,
 
 	// Sort transactions chronologically
 	sortTransactionsChronologically(allTransactions)
+	allTransactions = parser.LimitTransactions(ctx, allTransactions)
 
 	// Resolve formatter from registry
 	formatterReg := formatter.NewFormatterRegistry()
@@ -216,17 +256,17 @@ func consolidatePDFDirectory(ctx context.Context, p parser.FullParser,
 			logging.Field{Key: "format", Value: format})
 		return processedCount, err
 	}
+	outputFormatter = formatter.ApplyOptions(outputFormatter, opts.Options)
 
 	logger.Info("Writing consolidated transactions",
 		logging.Field{Key: "total_transactions", Value: len(allTransactions)},
 		logging.Field{Key: "output", Value: outputFile})
 
 	// Write consolidated CSV with formatter
-	delimiter := outputFormatter.Delimiter()
-	if err := internalcommon.WriteTransactionsToCSVWithFormatter(
-		allTransactions, outputFile, logger, outputFormatter, delimiter); err != nil {
+	if err := common.WriteTransactions(allTransactions, outputFile, logger, outputFormatter, opts); err != nil {
 		return processedCount, fmt.Errorf("failed to write CSV: %w", err)
 	}
+	parser.RecordCategorization(ctx, allTransactions)
 
 	logger.Info("Successfully wrote consolidated CSV",
 		logging.Field{Key: "files_processed", Value: processedCount},
@@ -236,20 +276,60 @@ func consolidatePDFDirectory(ctx context.Context, p parser.FullParser,
 	return processedCount, nil
 }
 
-// sortTransactionsChronologically sorts transactions by date, then value date, then amount
+// sortTransactionsChronologically sorts transactions by date, then value date, then amount,
+// then source sequence number (see models.SortChronologically).
 func sortTransactionsChronologically(transactions []models.Transaction) {
-	sort.Slice(transactions, func(i, j int) bool {
-		// Primary sort: by transaction date
-		if !transactions[i].Date.Equal(transactions[j].Date) {
-			return transactions[i].Date.Before(transactions[j].Date)
-		}
+	models.SortChronologically(transactions)
+}
+
+// parseConsolidatedPDF validates (when requested) and parses one PDF of a
+// consolidated directory. Files that cannot be read or parsed are logged and
+// reported as not ok.
+func parseConsolidatedPDF(ctx context.Context, p parser.FullParser, pdfFile string, validate bool, logger logging.Logger) ([]models.Transaction, bool) {
+	logger.Debug("Processing PDF", logging.Field{Key: "file", Value: filepath.Base(pdfFile)})
 
-		// Secondary sort: by value date
-		if !transactions[i].ValueDate.Equal(transactions[j].ValueDate) {
-			return transactions[i].ValueDate.Before(transactions[j].ValueDate)
+	// Validate if requested
+	if validate {
+		isValid, err := p.ValidateFormat(pdfFile)
+		if err != nil {
+			logger.WithError(err).Warn("Error validating PDF",
+				logging.Field{Key: "file", Value: filepath.Base(pdfFile)})
+			return nil, false
+		}
+		if !isValid {
+			logger.Warn("Skipping invalid PDF",
+				logging.Field{Key: "file", Value: filepath.Base(pdfFile)})
+			return nil, false
 		}
+	}
+
+	// Open and parse the file
+	file, err := os.Open(pdfFile) // #nosec G304 -- CLI tool requires user-provided paths
+	if err != nil {
+		logger.WithError(err).Warn("Failed to open PDF",
+			logging.Field{Key: "file", Value: filepath.Base(pdfFile)})
+		return nil, false
+	}
+
+	transactions, err := p.Parse(ctx, file)
+	if closeErr := file.Close(); closeErr != nil {
+		logger.WithError(closeErr).Warn("Failed to close PDF file",
+			logging.Field{Key: "file", Value: filepath.Base(pdfFile)})
+	}
+
+	if err == nil {
+		err = parser.CheckTransactionLimit(ctx, len(transactions))
+	}
+	if err != nil {
+		logger.WithError(err).Warn("Failed to parse PDF",
+			logging.Field{Key: "file", Value: filepath.Base(pdfFile)})
+		return nil, false
+	}
+
+	logger.Debug("Parsed transactions",
+		logging.Field{Key: "file", Value: filepath.Base(pdfFile)},
+		logging.Field{Key: "count", Value: len(transactions)})
 
-		// Tertiary sort: by amount (for consistency)
-		return transactions[i].Amount.LessThan(transactions[j].Amount)
-	})
+	parser.WarnEmptyTransactions(ctx, transactions, filepath.Base(pdfFile), logger)
+	return parser.FilterTransactions(ctx, transactions), true
 }
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"fjacquet/camt-csv/cmd/common"
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
 	"fjacquet/camt-csv/internal/parser"
@@ -110,6 +111,19 @@ func TestSortTransactionsChronologically(t *testing.T) {
 				{Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), ValueDate: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Amount: decimal.NewFromInt(300)},
 			},
 		},
+		{
+			name: "sort by sequence number - same date, value date and amount",
+			input: []models.Transaction{
+				{Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Amount: decimal.NewFromInt(100), SequenceNumber: 3},
+				{Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Amount: decimal.NewFromInt(100), SequenceNumber: 1},
+				{Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Amount: decimal.NewFromInt(100), SequenceNumber: 2},
+			},
+			expected: []models.Transaction{
+				{Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Amount: decimal.NewFromInt(100), SequenceNumber: 1},
+				{Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Amount: decimal.NewFromInt(100), SequenceNumber: 2},
+				{Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Amount: decimal.NewFromInt(100), SequenceNumber: 3},
+			},
+		},
 		{
 			name:     "empty slice",
 			input:    []models.Transaction{},
@@ -166,7 +180,7 @@ func TestConsolidatePDFDirectory_Success(t *testing.T) {
 	logger := logging.NewLogrusAdapter("info", "text")
 
 	// Execute
-	count, err := consolidatePDFDirectory(context.Background(), mockParser, tempDir, outputFile, false, logger, "standard", "")
+	count, err := consolidatePDFDirectory(context.Background(), mockParser, tempDir, outputFile, false, logger, "standard", "", common.OutputOptions{})
 
 	// Assert
 	require.NoError(t, err)
@@ -188,7 +202,7 @@ func TestConsolidatePDFDirectory_EmptyDirectory(t *testing.T) {
 
 	logger := logging.NewLogrusAdapter("info", "text")
 
-	count, err := consolidatePDFDirectory(context.Background(), mockParser, tempDir, outputFile, false, logger, "standard", "")
+	count, err := consolidatePDFDirectory(context.Background(), mockParser, tempDir, outputFile, false, logger, "standard", "", common.OutputOptions{})
 
 	assert.NoError(t, err)
 	assert.Equal(t, 0, count)
@@ -226,7 +240,7 @@ func TestConsolidatePDFDirectory_MixedValidInvalid(t *testing.T) {
 
 	logger := logging.NewLogrusAdapter("info", "text")
 
-	count, err := consolidatePDFDirectory(context.Background(), mockParser, tempDir, outputFile, false, logger, "standard", "")
+	count, err := consolidatePDFDirectory(context.Background(), mockParser, tempDir, outputFile, false, logger, "standard", "", common.OutputOptions{})
 
 	require.NoError(t, err)
 	assert.Equal(t, 2, count, "Should only process 2 valid PDF files")
@@ -266,7 +280,7 @@ func TestConsolidatePDFDirectory_ValidationEnabled(t *testing.T) {
 	logger := logging.NewLogrusAdapter("info", "text")
 
 	// Execute with validation enabled
-	count, err := consolidatePDFDirectory(context.Background(), mockParser, tempDir, outputFile, true, logger, "standard", "")
+	count, err := consolidatePDFDirectory(context.Background(), mockParser, tempDir, outputFile, true, logger, "standard", "", common.OutputOptions{})
 
 	require.NoError(t, err)
 	assert.Equal(t, 1, count, "Should only process valid PDF")
@@ -311,7 +325,7 @@ func TestConsolidatePDFDirectory_ContextCancellation(t *testing.T) {
 
 	logger := logging.NewLogrusAdapter("info", "text")
 
-	count, err := consolidatePDFDirectory(ctx, mockParser, tempDir, outputFile, false, logger, "standard", "")
+	count, err := consolidatePDFDirectory(ctx, mockParser, tempDir, outputFile, false, logger, "standard", "", common.OutputOptions{})
 
 	assert.Error(t, err)
 	assert.ErrorIs(t, err, context.Canceled)
@@ -357,7 +371,7 @@ func TestConsolidatePDFDirectory_ParseErrors(t *testing.T) {
 
 	logger := logging.NewLogrusAdapter("info", "text")
 
-	count, err := consolidatePDFDirectory(context.Background(), mockParser, tempDir, outputFile, false, logger, "standard", "")
+	count, err := consolidatePDFDirectory(context.Background(), mockParser, tempDir, outputFile, false, logger, "standard", "", common.OutputOptions{})
 
 	// Should succeed but skip the bad file
 	require.NoError(t, err)
@@ -380,7 +394,7 @@ func TestConsolidatePDFDirectory_NoTransactions(t *testing.T) {
 
 	logger := logging.NewLogrusAdapter("info", "text")
 
-	count, err := consolidatePDFDirectory(context.Background(), mockParser, tempDir, outputFile, false, logger, "standard", "")
+	count, err := consolidatePDFDirectory(context.Background(), mockParser, tempDir, outputFile, false, logger, "standard", "", common.OutputOptions{})
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no transactions extracted")
@@ -414,7 +428,7 @@ func TestConsolidatePDFDirectory_CaseInsensitivePDFExtension(t *testing.T) {
 
 	logger := logging.NewLogrusAdapter("info", "text")
 
-	count, err := consolidatePDFDirectory(context.Background(), mockParser, tempDir, outputFile, false, logger, "standard", "")
+	count, err := consolidatePDFDirectory(context.Background(), mockParser, tempDir, outputFile, false, logger, "standard", "", common.OutputOptions{})
 
 	require.NoError(t, err)
 	assert.Equal(t, 3, count, "Should process all PDF files regardless of case")
@@ -463,7 +477,7 @@ func TestConsolidatePDFDirectory_TransactionsSorted(t *testing.T) {
 
 	logger := logging.NewLogrusAdapter("info", "text")
 
-	count, err := consolidatePDFDirectory(context.Background(), mockParser, tempDir, outputFile, false, logger, "standard", "")
+	count, err := consolidatePDFDirectory(context.Background(), mockParser, tempDir, outputFile, false, logger, "standard", "", common.OutputOptions{})
 
 	require.NoError(t, err)
 	assert.Equal(t, 2, count)
@@ -0,0 +1,35 @@
+// Package reprocess handles the command that runs camt-csv exports through the
+// conversion pipeline again.
+package reprocess
+
+import (
+	"fjacquet/camt-csv/cmd/common"
+	"fjacquet/camt-csv/internal/container"
+
+	"github.com/spf13/cobra"
+)
+
+// Cmd represents the reprocess command.
+var Cmd = &cobra.Command{
+	Use:   "reprocess",
+	Short: "Re-process a CSV written by camt-csv",
+	Long: `Read a CSV previously written by camt-csv and write it again, so that
+transactions can be re-categorized, filtered, split or sorted without the
+original statement. Both the standard and the signed-amount layouts are read;
+columns outside the standard layout are ignored.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		common.RunConvert(cmd, args, container.Reprocess, "Reprocess")
+	},
+}
+
+func init() {
+	common.RegisterFormatFlags(Cmd)
+	common.RegisterLimitFlags(Cmd)
+	common.RegisterInputEncodingFlag(Cmd)
+	common.RegisterFilterFlags(Cmd)
+	common.RegisterOutputDirFlag(Cmd)
+	common.RegisterUncategorizedFlag(Cmd)
+	common.RegisterNoClobberFlag(Cmd)
+	common.RegisterAppendFlags(Cmd)
+	common.RegisterCategorizeFlag(Cmd)
+}
@@ -0,0 +1,54 @@
+package reprocess_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fjacquet/camt-csv/cmd/reprocess"
+	"fjacquet/camt-csv/cmd/root"
+	"fjacquet/camt-csv/internal/config"
+	"fjacquet/camt-csv/internal/container"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReprocessCommand_Split(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "export.csv")
+	require.NoError(t, os.WriteFile(input, []byte(`Date,PartyName,PartyIBAN,Amount,Currency,CreditDebit,Category
+05.01.2025,Cafe Central,,4.50,CHF,DBIT,Food
+25.01.2025,ACME SA,CH9300762011623852957,2500.00,CHF,CRDT,Salary
+`), 0600))
+	output := filepath.Join(dir, "out.csv")
+
+	cfg := &config.Config{}
+	cfg.Log.Level = "info"
+	cfg.Log.Format = "text"
+	cfg.Output.Format = "standard"
+	appContainer, err := container.NewContainer(cfg)
+	require.NoError(t, err)
+
+	originalInput := root.SharedFlags.Input
+	originalOutput := root.SharedFlags.Output
+	originalContainer := root.AppContainer
+	defer func() {
+		root.SharedFlags.Input = originalInput
+		root.SharedFlags.Output = originalOutput
+		root.AppContainer = originalContainer
+	}()
+	root.SharedFlags.Input = input
+	root.SharedFlags.Output = output
+	root.AppContainer = appContainer
+
+	require.NoError(t, reprocess.Cmd.ParseFlags([]string{"--split", "by-party-iban", "--no-categorize"}))
+	reprocess.Cmd.SetContext(context.Background())
+	reprocess.Cmd.Run(reprocess.Cmd, nil)
+
+	files, err := filepath.Glob(filepath.Join(dir, "out_*.csv"))
+	require.NoError(t, err)
+	assert.Len(t, files, 2, "one file per counterparty IBAN, plus one for transactions without")
+	assert.NoFileExists(t, output)
+}
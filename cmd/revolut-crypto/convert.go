@@ -18,4 +18,14 @@ var Cmd = &cobra.Command{
 	},
 }
 
-func init() { common.RegisterFormatFlags(Cmd) }
+func init() {
+	common.RegisterFormatFlags(Cmd)
+	common.RegisterLimitFlags(Cmd)
+	common.RegisterInputEncodingFlag(Cmd)
+	common.RegisterFilterFlags(Cmd)
+	common.RegisterOutputDirFlag(Cmd)
+	common.RegisterUncategorizedFlag(Cmd)
+	common.RegisterNoClobberFlag(Cmd)
+	common.RegisterRecursiveFlag(Cmd)
+	common.RegisterWorkbookFlag(Cmd)
+}
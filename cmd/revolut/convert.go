@@ -26,7 +26,15 @@ var Cmd = &cobra.Command{
 	Run:   revolutFunc,
 }
 
-func init() { common.RegisterFormatFlags(Cmd) }
+func init() {
+	common.RegisterFormatFlags(Cmd)
+	common.RegisterLimitFlags(Cmd)
+	common.RegisterInputEncodingFlag(Cmd)
+	common.RegisterFilterFlags(Cmd)
+	common.RegisterUncategorizedFlag(Cmd)
+	common.RegisterNoClobberFlag(Cmd)
+	common.RegisterRecursiveFlag(Cmd)
+}
 
 func revolutFunc(cmd *cobra.Command, _ []string) {
 	ctx := cmd.Context()
@@ -41,6 +49,26 @@ func revolutFunc(cmd *cobra.Command, _ []string) {
 
 	format, _ := cmd.Flags().GetString("format")
 	dateFormat, _ := cmd.Flags().GetString("date-format")
+	opts, err := common.ParseOutputOptions(cmd, logger)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+	ctx, err = common.WithTransactionLimit(ctx, cmd)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+	ctx, err = common.WithInputEncoding(ctx, cmd)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+	ctx, err = common.WithTransactionFilter(ctx, cmd)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+	ctx, checkUncategorized, err := common.WithUncategorizedCheck(ctx, cmd, logger)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
 
 	appContainer := root.GetContainer()
 	if appContainer == nil {
@@ -56,26 +84,23 @@ func revolutFunc(cmd *cobra.Command, _ []string) {
 		logger.Fatalf("Error getting Revolut parser: %v", err)
 	}
 
-	fileInfo, err := os.Stat(inputPath)
-	if err != nil {
-		logger.Fatalf("Error accessing input path: %v", err)
+	isBatch := common.IsBatchInput(inputPath, logger)
+	if isBatch && outputPath == "" {
+		logger.Fatalf("--output flag is required when processing a folder or zip archive. Use -o or --output to specify the output directory.")
 	}
 
-	if fileInfo.IsDir() && outputPath == "" {
-		logger.Fatalf("--output flag is required when processing a folder. Use -o or --output to specify the output directory.")
-	}
-
-	if fileInfo.IsDir() {
-		batchConvert(ctx, p, inputPath, outputPath, logger, format, dateFormat)
+	if isBatch {
+		batchConvert(ctx, p, inputPath, outputPath, logger, format, dateFormat, opts)
 	} else {
-		common.ProcessFile(ctx, p, inputPath, outputPath, root.SharedFlags.Validate, root.Log, appContainer, format, dateFormat)
+		common.ProcessFile(ctx, p, inputPath, outputPath, root.SharedFlags.Validate, root.Log, appContainer, format, dateFormat, opts)
 		root.Log.Info("Revolut to CSV conversion completed successfully!")
 	}
+	checkUncategorized()
 }
 
 // batchConvert processes all files in a directory using BatchProcessor with formatter
 func batchConvert(ctx context.Context, p any, inputDir, outputDir string,
-	logger logging.Logger, format string, _ string) {
+	logger logging.Logger, format string, _ string, opts common.OutputOptions) {
 
 	fullParser, ok := p.(parser.FullParser)
 	if !ok {
@@ -90,8 +115,13 @@ func batchConvert(ctx context.Context, p any, inputDir, outputDir string,
 			logging.Field{Key: "format", Value: format})
 		os.Exit(1)
 	}
+	outFormatter = formatter.ApplyOptions(outFormatter, opts.Options)
 
 	processor := batch.NewBatchProcessor(fullParser, logger, outFormatter)
+	processor.SetNoClobber(opts.NoClobber)
+	processor.SetBOM(opts.BOM)
+	processor.SetRecursive(opts.Recursive)
+	processor.SetProgress(common.NewProgress("Converting"))
 
 	manifest, err := processor.ProcessDirectory(ctx, inputDir, outputDir)
 	if err != nil {
@@ -107,12 +137,16 @@ func batchConvert(ctx context.Context, p any, inputDir, outputDir string,
 	logger.Info(fmt.Sprintf("Batch complete: %d/%d files succeeded",
 		manifest.SuccessCount, manifest.TotalFiles))
 
+	if manifest.SkippedCount > 0 {
+		logger.Warn(fmt.Sprintf("%d files skipped because their output already exists",
+			manifest.SkippedCount))
+	}
 	if manifest.FailureCount > 0 {
 		logger.Warn(fmt.Sprintf("%d files failed (see %s for details)",
 			manifest.FailureCount, manifestPath))
 	}
 
 	if manifest.ExitCode() != 0 {
-		os.Exit(manifest.ExitCode())
+		root.Exit(manifest.ExitCode())
 	}
 }
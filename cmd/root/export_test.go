@@ -0,0 +1,18 @@
+// Package root exports internal symbols for testing.
+// This file is only compiled during tests.
+package root
+
+// ApplyFlagOverrides exposes applyFlagOverrides to tests.
+var ApplyFlagOverrides = applyFlagOverrides
+
+// ApplyTimeout exposes applyTimeout to tests.
+var ApplyTimeout = applyTimeout
+
+// ApplyCategoryOverrides exposes applyCategoryOverrides to tests.
+var ApplyCategoryOverrides = applyCategoryOverrides
+
+// ParseCategoryOverrides exposes parseCategoryOverrides to tests.
+var ParseCategoryOverrides = parseCategoryOverrides
+
+// FlushOnExit exposes flushOnExit to tests.
+var FlushOnExit = flushOnExit
@@ -2,10 +2,17 @@
 package root
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
 	"fjacquet/camt-csv/internal/common"
 	"fjacquet/camt-csv/internal/config"
 	"fjacquet/camt-csv/internal/container"
 	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
 	"log"
 
 	"github.com/sirupsen/logrus"
@@ -18,8 +25,13 @@ type CommonFlags struct {
 	Input    string
 	Output   string
 	Validate bool
+	Quiet    bool
 }
 
+// AnnotationNoTimeout marks a command that runs until it is stopped, such as
+// a server, so --timeout is rejected instead of shutting it down.
+const AnnotationNoTimeout = "camt-csv/no-timeout"
+
 var (
 	// Log is the shared logger instance for commands - will be updated with config
 	Log = logging.NewLogrusAdapter("info", "text")
@@ -30,6 +42,12 @@ var (
 	// Global container instance for dependency injection
 	AppContainer *container.Container
 
+	// cancelTimeout releases the --timeout deadline once the command is done
+	cancelTimeout context.CancelFunc
+
+	// closeAuditLog closes the --audit-log file once the command is done
+	closeAuditLog io.Closer
+
 	// Cmd is the root command
 	Cmd = &cobra.Command{
 		Use:   "camt-csv",
@@ -44,10 +62,21 @@ It also provides transaction categorization based on the party's name.`,
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
 			// Initialize configuration first
 			initializeConfiguration()
+			applyFlagOverrides(cmd)
 
 			// Initialize container with dependency injection
 			initializeContainer()
 
+			if err := applyTimeout(cmd); err != nil {
+				Log.Fatalf("Invalid --timeout: %v", err)
+			}
+			if err := applyAuditLog(cmd); err != nil {
+				Log.Fatalf("Invalid --audit-log: %v", err)
+			}
+			if err := applyCategoryOverrides(cmd); err != nil {
+				Log.Fatalf("Invalid --map: %v", err)
+			}
+
 			// Note: Logger is now injected through dependency injection container
 			// Individual parsers receive loggers through their constructors
 
@@ -57,22 +86,20 @@ It also provides transaction categorization based on the party's name.`,
 		},
 		// Add a PersistentPostRun hook to save party mappings when ANY command finishes
 		PersistentPostRun: func(cmd *cobra.Command, args []string) {
-			// Save the creditor and debitor mappings back to disk after any command runs
-			if AppContainer == nil {
-				Log.Warn("Container not initialized, skipping category mapping save")
-				return
+			if cancelTimeout != nil {
+				defer cancelTimeout()
 			}
-
-			categorizerInstance := AppContainer.GetCategorizer()
-			err := categorizerInstance.SaveCreditorsToYAML()
-			if err != nil {
-				Log.WithError(err).Warn("Failed to save creditor mappings")
+			if closeAuditLog != nil {
+				defer func() { _ = closeAuditLog.Close() }()
 			}
 
-			err = categorizerInstance.SaveDebitorsToYAML()
-			if err != nil {
-				Log.WithError(err).Warn("Failed to save debitor mappings")
+			// Nothing was categorized, so there are no mappings to save
+			if noCategorize, _ := cmd.Flags().GetBool("no-categorize"); noCategorize {
+				return
 			}
+
+			// Save the creditor and debitor mappings back to disk after any command runs
+			saveCategoryMappings()
 		},
 	}
 
@@ -98,12 +125,146 @@ func initializeConfiguration() {
 	if err != nil {
 		log.Fatalf("Failed to initialize configuration: %v", err)
 	}
+	if err := models.SetCurrencyPrecision(AppConfig.CSV.CurrencyPrecision); err != nil {
+		log.Fatalf("Failed to initialize configuration: %v", err)
+	}
 
 	// Configure logging based on the loaded configuration
 	logrusLogger := config.ConfigureLoggingFromConfig(AppConfig)
 	Log = logging.NewLogrusAdapterFromLogger(logrusLogger)
 }
 
+// applyFlagOverrides applies CLI flags that override the loaded configuration.
+// --no-auto-learn wins over --auto-learn and the config file; --offline
+// disables AI categorization whatever --ai-enabled and the config file say.
+func applyFlagOverrides(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	if flags.Changed("auto-learn") {
+		AppConfig.Categorization.AutoLearn, _ = flags.GetBool("auto-learn")
+	}
+	if noAutoLearn, _ := flags.GetBool("no-auto-learn"); noAutoLearn {
+		AppConfig.Categorization.AutoLearn = false
+	}
+	if offline, _ := flags.GetBool("offline"); offline {
+		AppConfig.AI.Enabled = false
+	}
+}
+
+// applyTimeout gives the command's context the --timeout deadline, so that
+// parsing and AI categorization stop with a timeout error once it has passed.
+func applyTimeout(cmd *cobra.Command) error {
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	if timeout < 0 {
+		return fmt.Errorf("must not be negative")
+	}
+	if timeout == 0 {
+		return nil
+	}
+	if _, ok := cmd.Annotations[AnnotationNoTimeout]; ok {
+		return fmt.Errorf("%s runs until it is stopped and does not take a timeout", cmd.Name())
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancelTimeout = context.WithTimeoutCause(ctx, timeout,
+		fmt.Errorf("%w: run exceeded --timeout %s", context.DeadlineExceeded, timeout))
+	cmd.SetContext(ctx)
+	return nil
+}
+
+// saveCategoryMappings writes the mappings auto-learned since the last save.
+// Auto-learning only saves in batches during a run, so this final save is
+// what persists the rest.
+func saveCategoryMappings() {
+	if AppContainer == nil {
+		Log.Warn("Container not initialized, skipping category mapping save")
+		return
+	}
+	if err := AppContainer.GetCategorizer().SaveMappings(); err != nil {
+		Log.WithError(err).Warn("Failed to save category mappings")
+	}
+}
+
+// Exit saves the auto-learned category mappings and exits with code. Commands
+// use it instead of os.Exit, which would skip PersistentPostRun and lose them.
+func Exit(code int) {
+	flushOnExit()
+	os.Exit(code)
+}
+
+// flushOnExit saves the auto-learned category mappings and closes the
+// --audit-log file. Init registers it as a logrus exit handler, so that a run
+// ending with logger.Fatal keeps what it learned too.
+func flushOnExit() {
+	// A run that fails before the container exists has learned nothing
+	if AppContainer != nil {
+		saveCategoryMappings()
+	}
+	if closeAuditLog != nil {
+		_ = closeAuditLog.Close()
+		closeAuditLog = nil
+	}
+}
+
+// applyAuditLog makes the categorizer append each categorization decision
+// to the --audit-log file as a JSON line, independently of the CSV output.
+func applyAuditLog(cmd *cobra.Command) error {
+	path, _ := cmd.Flags().GetString("audit-log")
+	if path == "" || AppContainer == nil {
+		return nil
+	}
+
+	auditLogger, closer, err := logging.NewAuditLogger(path)
+	if err != nil {
+		return err
+	}
+	closeAuditLog = closer
+	AppContainer.GetCategorizer().SetAuditLogger(auditLogger)
+	return nil
+}
+
+// applyCategoryOverrides forces the parties of the --map flags into their
+// category for this run. They are written to the creditor and debitor
+// mapping files only with --persist.
+func applyCategoryOverrides(cmd *cobra.Command) error {
+	values, _ := cmd.Flags().GetStringArray("map")
+	persist, _ := cmd.Flags().GetBool("persist")
+	if len(values) == 0 {
+		if persist {
+			return fmt.Errorf("--persist requires --map")
+		}
+		return nil
+	}
+
+	overrides, err := parseCategoryOverrides(values)
+	if err != nil {
+		return err
+	}
+	if AppContainer == nil {
+		return nil
+	}
+	AppContainer.GetCategorizer().SetCategoryOverrides(overrides, persist)
+	return nil
+}
+
+// parseCategoryOverrides parses "Party=Category" values, split at the first
+// "=", into a map of party names to categories. A party given twice gets the
+// last category.
+func parseCategoryOverrides(values []string) (map[string]string, error) {
+	overrides := make(map[string]string, len(values))
+	for _, value := range values {
+		party, category, ok := strings.Cut(value, "=")
+		party, category = strings.TrimSpace(party), strings.TrimSpace(category)
+		if !ok || party == "" || category == "" {
+			return nil, fmt.Errorf("%q is not of the form Party=Category", value)
+		}
+		overrides[party] = category
+	}
+	return overrides, nil
+}
+
 // initializeContainer creates the dependency injection container
 func initializeContainer() {
 	var err error
@@ -131,18 +292,28 @@ func GetContainer() *container.Container {
 
 // Init initializes the root command and all flags
 func Init() {
+	logrus.RegisterExitHandler(flushOnExit)
+
 	// Add persistent flags to root command for common options
 	Cmd.PersistentFlags().StringVarP(&SharedFlags.Input, "input", "i", "", "Input file")
 	Cmd.PersistentFlags().StringVarP(&SharedFlags.Output, "output", "o", "", "Output file")
 	Cmd.PersistentFlags().BoolVarP(&SharedFlags.Validate, "validate", "v", false, "Validate file format before conversion")
+	Cmd.PersistentFlags().BoolVar(&SharedFlags.Quiet, "quiet", false, "Hide progress output")
 
 	// Add configuration-related flags
 	Cmd.PersistentFlags().String("config", "", "Config file (default is $HOME/.camt-csv/config.yaml)")
+	Cmd.PersistentFlags().String("env-file", "", "Environment file to load instead of .env; its .local sibling (e.g. .env.local) overrides it")
 	Cmd.PersistentFlags().String("log-level", "", "Log level (debug, info, warn, error)")
 	Cmd.PersistentFlags().String("log-format", "", "Log format (text, json)")
 	Cmd.PersistentFlags().String("csv-delimiter", "", "CSV delimiter character")
 	Cmd.PersistentFlags().Bool("ai-enabled", false, "Enable AI categorization")
 	Cmd.PersistentFlags().Bool("auto-learn", false, "Enable AI auto-learning of categorizations (default: false)")
+	Cmd.PersistentFlags().Bool("no-auto-learn", false, "Never save categorizations to the mapping files, overriding config")
+	Cmd.PersistentFlags().Bool("offline", false, "Disable AI categorization: categorize with mappings and keywords only, for reproducible output")
+	Cmd.PersistentFlags().String("audit-log", "", "Append one JSON line per categorization decision (party, category, method, AI confidence) to this file")
+	Cmd.PersistentFlags().StringArray("map", nil, "Force a party into a category for this run, as \"Party=Category\" (repeatable); beats every mapping and rule")
+	Cmd.PersistentFlags().Bool("persist", false, "Also save the --map overrides to the creditor and debitor mapping files")
+	Cmd.PersistentFlags().Duration("timeout", 0, "Abort the run with an error once it has taken longer than this, e.g. 5m (0 = no timeout)")
 
 	// Bind flags to viper
 	if err := viper.BindPFlag("log.level", Cmd.PersistentFlags().Lookup("log-level")); err != nil {
@@ -1,12 +1,19 @@
 package root_test
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"fjacquet/camt-csv/cmd/root"
+	"fjacquet/camt-csv/internal/config"
+	"fjacquet/camt-csv/internal/container"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRootCommand_Metadata(t *testing.T) {
@@ -311,3 +318,125 @@ func TestRootCommand_ConfigurationIntegration(t *testing.T) {
 		root.GetLogrusAdapter()
 	})
 }
+
+func TestApplyFlagOverrides_Offline(t *testing.T) {
+	prev := root.AppConfig
+	defer func() { root.AppConfig = prev }()
+
+	newCmd := func(flags map[string]string) *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		cmd.Flags().Bool("auto-learn", false, "")
+		cmd.Flags().Bool("no-auto-learn", false, "")
+		cmd.Flags().Bool("offline", false, "")
+		for name, value := range flags {
+			require.NoError(t, cmd.Flags().Set(name, value))
+		}
+		return cmd
+	}
+
+	root.AppConfig = &config.Config{}
+	root.AppConfig.AI.Enabled = true
+	root.ApplyFlagOverrides(newCmd(nil))
+	assert.True(t, root.AppConfig.AI.Enabled)
+
+	root.ApplyFlagOverrides(newCmd(map[string]string{"offline": "true"}))
+	assert.False(t, root.AppConfig.AI.Enabled)
+}
+
+func TestApplyTimeout(t *testing.T) {
+	newCmd := func(timeout string) *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		cmd.Flags().Duration("timeout", 0, "")
+		require.NoError(t, cmd.Flags().Set("timeout", timeout))
+		cmd.SetContext(context.Background())
+		return cmd
+	}
+
+	// No deadline by default
+	cmd := newCmd("0")
+	require.NoError(t, root.ApplyTimeout(cmd))
+	_, ok := cmd.Context().Deadline()
+	assert.False(t, ok)
+
+	cmd = newCmd("1ms")
+	require.NoError(t, root.ApplyTimeout(cmd))
+	_, ok = cmd.Context().Deadline()
+	assert.True(t, ok)
+	<-cmd.Context().Done()
+	cause := context.Cause(cmd.Context())
+	assert.True(t, errors.Is(cause, context.DeadlineExceeded))
+	assert.ErrorContains(t, cause, "--timeout 1ms")
+
+	assert.Error(t, root.ApplyTimeout(newCmd("-1s")))
+
+	// A server runs until it is stopped
+	cmd = newCmd("5m")
+	cmd.Annotations = map[string]string{root.AnnotationNoTimeout: ""}
+	assert.ErrorContains(t, root.ApplyTimeout(cmd), "does not take a timeout")
+	_, ok = cmd.Context().Deadline()
+	assert.False(t, ok)
+}
+
+func TestParseCategoryOverrides(t *testing.T) {
+	overrides, err := root.ParseCategoryOverrides([]string{
+		"Migros=Loisirs",
+		" Jean Dupont = Cadeaux ",
+		"Coop, Lausanne=Alimentation",
+		"Migros=Sorties",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"Migros":         "Sorties",
+		"Jean Dupont":    "Cadeaux",
+		"Coop, Lausanne": "Alimentation",
+	}, overrides)
+
+	for _, value := range []string{"Migros", "=Loisirs", "Migros=", " = "} {
+		_, err := root.ParseCategoryOverrides([]string{value})
+		assert.Error(t, err, value)
+	}
+}
+
+func TestApplyCategoryOverrides(t *testing.T) {
+	newCmd := func(maps []string, persist bool) *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		cmd.Flags().StringArray("map", nil, "")
+		cmd.Flags().Bool("persist", false, "")
+		for _, value := range maps {
+			require.NoError(t, cmd.Flags().Set("map", value))
+		}
+		if persist {
+			require.NoError(t, cmd.Flags().Set("persist", "true"))
+		}
+		return cmd
+	}
+
+	assert.NoError(t, root.ApplyCategoryOverrides(newCmd(nil, false)))
+	assert.ErrorContains(t, root.ApplyCategoryOverrides(newCmd(nil, true)), "--persist requires --map")
+	assert.ErrorContains(t, root.ApplyCategoryOverrides(newCmd([]string{"Migros"}, false)), "Party=Category")
+}
+
+func TestFlushOnExit(t *testing.T) {
+	dir := t.TempDir()
+	creditorsFile := filepath.Join(dir, "creditors.yaml")
+	cfg := &config.Config{}
+	cfg.Log.Level = "info"
+	cfg.Log.Format = "text"
+	cfg.Categories.File = filepath.Join(dir, "categories.yaml")
+	cfg.Categories.CreditorsFile = creditorsFile
+	cfg.Categories.DebtorsFile = filepath.Join(dir, "debtors.yaml")
+	appContainer, err := container.NewContainer(cfg)
+	require.NoError(t, err)
+
+	originalContainer := root.AppContainer
+	defer func() { root.AppContainer = originalContainer }()
+	root.AppContainer = appContainer
+
+	// A mapping learned before a logger.Fatal exit is saved by the exit handler
+	appContainer.GetCategorizer().UpdateCreditorCategory("ACME SA", "Salary")
+	root.FlushOnExit()
+
+	data, err := os.ReadFile(creditorsFile) // #nosec G304 -- test file
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Salary")
+}
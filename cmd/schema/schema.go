@@ -0,0 +1,89 @@
+// Package schema handles the command describing the CSV output columns
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"fjacquet/camt-csv/cmd/common"
+	"fjacquet/camt-csv/cmd/root"
+	"fjacquet/camt-csv/internal/formatter"
+
+	"github.com/spf13/cobra"
+)
+
+// Cmd represents the schema command
+var Cmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the CSV columns written with the given output flags",
+	Long: `Print, as JSON, the delimiter and the ordered columns of the CSV that the
+convert commands write with the same output flags, each with the type of its
+values: string, date, datetime, decimal or integer.
+
+The columns are read from the formatter that writes the CSV, so the schema
+always matches the output, including the columns selected by --profile or
+--columns and those appended by flags such as --category-source or --mcc.
+
+Examples:
+  camt-csv schema
+  camt-csv schema --format standard --signed-amount
+  camt-csv schema --profile erp --category-source --tags`,
+	Args: cobra.NoArgs,
+	// Nothing is categorized, so there are no mappings to save
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {},
+	Run:               schemaFunc,
+}
+
+func init() {
+	common.RegisterFormatFlags(Cmd)
+}
+
+// schemaOutput is the JSON document printed by the schema command. An export
+// profile takes the place of the format, so only one of the two is set.
+type schemaOutput struct {
+	Format  string `json:"format,omitempty"`
+	Profile string `json:"profile,omitempty"`
+	formatter.Schema
+}
+
+func schemaFunc(cmd *cobra.Command, _ []string) {
+	logger := root.GetLogrusAdapter()
+	opts, err := common.ParseOutputOptions(cmd, logger)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+
+	appContainer := root.GetContainer()
+	if appContainer == nil {
+		logger.Fatal("Container not initialized")
+	}
+	format, _ := cmd.Flags().GetString("format")
+	if format == "" {
+		format = appContainer.GetConfig().Output.Format
+	}
+
+	if err := printSchema(cmd.OutOrStdout(), appContainer.GetFormatterRegistry(), format, opts.Options); err != nil {
+		logger.Fatalf("Error describing the output: %v", err)
+	}
+}
+
+// printSchema writes the schema of the CSV written in format with opts to w.
+func printSchema(w io.Writer, registry *formatter.FormatterRegistry, format string, opts formatter.Options) error {
+	if format == common.FormatLedger || format == common.FormatXLSX {
+		return fmt.Errorf("format %s is not a CSV and has no columns", format)
+	}
+	outFormatter, err := registry.Get(format)
+	if err != nil {
+		return fmt.Errorf("invalid format '%s': %w. Valid formats: standard, icompta, jumpsoft", format, err)
+	}
+	outFormatter = formatter.ApplyOptions(outFormatter, opts)
+
+	out := schemaOutput{Format: format, Schema: formatter.DescribeSchema(outFormatter, opts)}
+	if opts.Profile != nil {
+		out.Format, out.Profile = "", opts.Profile.Name
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
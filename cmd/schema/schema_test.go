@@ -0,0 +1,59 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"fjacquet/camt-csv/internal/formatter"
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaCommand_Metadata(t *testing.T) {
+	assert.Equal(t, "schema", Cmd.Use)
+	assert.NotNil(t, Cmd.Flags().Lookup("profile"))
+	assert.NotNil(t, Cmd.Flags().Lookup("category-source"))
+	assert.Error(t, Cmd.Args(Cmd, []string{"file.csv"}))
+}
+
+func TestPrintSchema(t *testing.T) {
+	registry := formatter.NewFormatterRegistry()
+
+	t.Run("format", func(t *testing.T) {
+		var buf bytes.Buffer
+		opts := formatter.Options{CategorySource: true}
+		require.NoError(t, printSchema(&buf, registry, "jumpsoft", opts))
+
+		var out schemaOutput
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+		assert.Equal(t, "jumpsoft", out.Format)
+		assert.Empty(t, out.Profile)
+		assert.Equal(t, ",", out.Delimiter)
+		require.Len(t, out.Columns, 8)
+		assert.Equal(t, formatter.Column{Name: "Date", Type: models.ColumnTypeDate}, out.Columns[0])
+		assert.Equal(t, formatter.Column{Name: "CategorySource", Type: models.ColumnTypeString}, out.Columns[7])
+	})
+
+	t.Run("profile", func(t *testing.T) {
+		var buf bytes.Buffer
+		profile := models.ExportProfile{Name: "erp", Columns: []string{"Date", "Amount"}}
+		require.NoError(t, printSchema(&buf, registry, "icompta", formatter.Options{Profile: &profile}))
+		assert.JSONEq(t, `{
+			"profile": "erp",
+			"delimiter": ",",
+			"columns": [
+				{"name": "Date", "type": "date"},
+				{"name": "Amount", "type": "decimal"}
+			]
+		}`, buf.String())
+	})
+
+	t.Run("not a CSV", func(t *testing.T) {
+		assert.ErrorContains(t, printSchema(&bytes.Buffer{}, registry, "ledger", formatter.Options{}), "not a CSV")
+		assert.ErrorContains(t, printSchema(&bytes.Buffer{}, registry, "xlsx", formatter.Options{}), "not a CSV")
+		assert.ErrorContains(t, printSchema(&bytes.Buffer{}, registry, "qif", formatter.Options{}), "invalid format")
+	})
+}
@@ -0,0 +1,95 @@
+// Package serve handles the HTTP server command
+package serve
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"fjacquet/camt-csv/cmd/common"
+	"fjacquet/camt-csv/cmd/root"
+	"fjacquet/camt-csv/internal/container"
+	"fjacquet/camt-csv/internal/server"
+
+	"github.com/spf13/cobra"
+)
+
+// Cmd represents the serve command
+var Cmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve conversions over HTTP",
+	Long: `Start an HTTP server that converts uploaded statements to CSV.
+
+Endpoints:
+  POST /convert/camt  request body is a CAMT.053 XML document
+  POST /convert/pdf   multipart form upload, one or more "file" parts
+
+Both return CSV. The "format" query parameter overrides --format per request.
+Changes to categories.yaml, creditors.yaml, debtors.yaml and the IBAN, MCC,
+tag and cleanup rule files are picked up without a restart; a file that fails to load leaves the previous one in use.
+The server shuts down gracefully on SIGINT or SIGTERM.
+
+Examples:
+  camt-csv serve --addr 127.0.0.1:8080
+  curl --data-binary @statement.xml http://127.0.0.1:8080/convert/camt
+  curl -F file=@statement.pdf http://127.0.0.1:8080/convert/pdf?format=icompta`,
+	Run:         serveFunc,
+	Annotations: map[string]string{root.AnnotationNoTimeout: ""},
+}
+
+func init() {
+	Cmd.Flags().String("addr", "127.0.0.1:8080", "Address to listen on")
+	common.RegisterFormatFlags(Cmd)
+}
+
+func serveFunc(cmd *cobra.Command, _ []string) {
+	logger := root.GetLogrusAdapter()
+	root.Log.Info("Serve command called")
+
+	addr, _ := cmd.Flags().GetString("addr")
+	format, _ := cmd.Flags().GetString("format")
+	opts, err := common.ParseOutputOptions(cmd, logger)
+	if err != nil {
+		logger.Fatalf("Invalid output options: %v", err)
+	}
+
+	// Get container from root command context
+	appContainer := root.GetContainer()
+	if appContainer == nil {
+		logger.Fatal("Container not initialized")
+	}
+
+	if format == "" {
+		format = appContainer.GetConfig().Output.Format
+	}
+
+	camtParser, err := appContainer.GetParser(container.CAMT)
+	if err != nil {
+		logger.Fatalf("Error getting CAMT parser: %v", err)
+	}
+	pdfParser, err := appContainer.GetParser(container.PDF)
+	if err != nil {
+		logger.Fatalf("Error getting PDF parser: %v", err)
+	}
+
+	srv := server.New(camtParser, pdfParser, appContainer.GetFormatterRegistry(), format, opts.Options, logger)
+	srv.SetBOM(opts.BOM)
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Edits to the category files take effect without a restart
+	if err := appContainer.WatchCategories(ctx); err != nil {
+		logger.WithError(err).Error("Cannot watch the categories files, hot reload disabled")
+	}
+
+	if err := server.ListenAndServe(ctx, addr, srv.Handler(), logger); err != nil {
+		logger.Fatalf("HTTP server error: %v", err)
+	}
+	root.Log.Info("HTTP server stopped")
+}
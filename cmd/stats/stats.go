@@ -0,0 +1,171 @@
+// Package stats handles the spending statistics command
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"fjacquet/camt-csv/cmd/auto"
+	"fjacquet/camt-csv/cmd/common"
+	"fjacquet/camt-csv/cmd/root"
+	"fjacquet/camt-csv/internal/container"
+	"fjacquet/camt-csv/internal/dateutils"
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parser"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+// Cmd represents the stats command
+var Cmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Compare spending per category with the budgets",
+	Long: `Parse and categorize statements, detecting their format as the auto command
+does, and print the net spending of each category over the period they cover.
+
+Categories with a monthly budget in the budgets file (budgets.yaml) show the
+budget for the months covered, the delta, and OVER when more was spent.
+
+Examples:
+  camt-csv stats -i statements/2025-Q1/
+  camt-csv stats -i "downloads/*.xml" --format json`,
+	Args: cobra.NoArgs,
+	Run:  statsFunc,
+}
+
+func init() {
+	Cmd.Flags().String("format", "table", "Output format: table or json")
+	common.RegisterInputEncodingFlag(Cmd)
+	common.RegisterFilterFlags(Cmd)
+}
+
+func statsFunc(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+	logger := root.GetLogrusAdapter()
+	format, _ := cmd.Flags().GetString("format")
+	if format != "table" && format != "json" {
+		logger.Fatalf("Invalid --format %q: valid formats are table, json", format)
+	}
+	if root.SharedFlags.Input == "" {
+		logger.Fatal("--input is required")
+	}
+
+	ctx, err := common.WithInputEncoding(ctx, cmd)
+	if err != nil {
+		logger.Fatalf("Invalid options: %v", err)
+	}
+	ctx, err = common.WithTransactionFilter(ctx, cmd)
+	if err != nil {
+		logger.Fatalf("Invalid options: %v", err)
+	}
+
+	appContainer := root.GetContainer()
+	if appContainer == nil {
+		logger.Fatal("Container not initialized")
+	}
+	budgets, err := appContainer.GetBudgets()
+	if err != nil {
+		logger.Fatalf("Error loading budgets: %v", err)
+	}
+	if categories, err := appContainer.GetCategories(); err == nil {
+		warnUnknownCategories(budgets, categories, logger)
+	}
+
+	files, err := auto.InputFiles(root.SharedFlags.Input)
+	if err != nil {
+		logger.Fatalf("Error reading input: %v", err)
+	}
+	lookup := func(name string) (parser.FullParser, error) {
+		return appContainer.GetParser(container.ParserType(name))
+	}
+	transactions, _, err := auto.ParseAll(ctx, files, lookup, logger)
+	if err != nil {
+		logger.Fatalf("Error parsing statements: %v", err)
+	}
+
+	report := models.CompareBudgets(transactions, budgets)
+	if err := printReport(cmd.OutOrStdout(), report, format); err != nil {
+		logger.Fatalf("Error printing statistics: %v", err)
+	}
+}
+
+// warnUnknownCategories warns about budgets for categories that are not in
+// the categories file, which are usually typos. Without a categories file
+// there is nothing to check against.
+func warnUnknownCategories(budgets map[string]decimal.Decimal, categories []models.CategoryConfig, logger logging.Logger) {
+	if len(categories) == 0 {
+		return
+	}
+	known := map[string]bool{models.CategoryUncategorized: true}
+	for _, category := range categories {
+		known[category.Name] = true
+	}
+	for name := range budgets {
+		if !known[name] {
+			logger.Warn("Budget for a category missing from the categories file",
+				logging.Field{Key: "category", Value: name})
+		}
+	}
+}
+
+// jsonLine is the JSON form of a models.BudgetLine.
+type jsonLine struct {
+	Category   string  `json:"category"`
+	Budget     *string `json:"budget"`
+	Actual     string  `json:"actual"`
+	Delta      *string `json:"delta"`
+	OverBudget bool    `json:"over_budget"`
+}
+
+// printReport writes report to w in the given format.
+func printReport(w io.Writer, report models.BudgetReport, format string) error {
+	switch format {
+	case "table":
+		if report.Months > 0 {
+			months := "months"
+			if report.Months == 1 {
+				months = "month"
+			}
+			_, _ = fmt.Fprintf(w, "Period: %s - %s (%d %s)\n\n",
+				report.From.Format(dateutils.DateLayoutEuropean), report.To.Format(dateutils.DateLayoutEuropean), report.Months, months)
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "CATEGORY\tBUDGET\tACTUAL\tDELTA\t\t")
+		for _, line := range report.Lines {
+			budget, delta, flag := "-", "-", ""
+			if line.HasBudget {
+				budget = line.Budget.StringFixed(2)
+				delta = line.Delta.StringFixed(2)
+			}
+			if line.OverBudget() {
+				flag = "OVER"
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t\n", line.Category, budget, line.Actual.StringFixed(2), delta, flag)
+		}
+		return tw.Flush()
+	case "json":
+		lines := make([]jsonLine, 0, len(report.Lines))
+		for _, line := range report.Lines {
+			out := jsonLine{Category: line.Category, Actual: line.Actual.StringFixed(2), OverBudget: line.OverBudget()}
+			if line.HasBudget {
+				budget, delta := line.Budget.StringFixed(2), line.Delta.StringFixed(2)
+				out.Budget, out.Delta = &budget, &delta
+			}
+			lines = append(lines, out)
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]any{
+			"from":       report.From.Format(dateutils.DateLayoutISO),
+			"to":         report.To.Format(dateutils.DateLayoutISO),
+			"months":     report.Months,
+			"categories": lines,
+		})
+	default:
+		return fmt.Errorf("invalid --format %q: valid formats are table, json", format)
+	}
+}
@@ -0,0 +1,91 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReport() models.BudgetReport {
+	return models.BudgetReport{
+		From:   time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+		Months: 3,
+		Lines: []models.BudgetLine{
+			{Category: "Courses", HasBudget: true, Budget: decimal.NewFromInt(900),
+				Actual: decimal.NewFromInt(1100), Delta: decimal.NewFromInt(-200)},
+			{Category: "Salaire", Actual: decimal.NewFromInt(-4200)},
+		},
+	}
+}
+
+func TestStatsCommand_Metadata(t *testing.T) {
+	assert.Equal(t, "stats", Cmd.Use)
+	assert.Equal(t, "table", Cmd.Flags().Lookup("format").DefValue)
+	assert.NotNil(t, Cmd.Flags().Lookup("filter-description"))
+}
+
+func TestPrintReport(t *testing.T) {
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, printReport(&buf, testReport(), "table"))
+		out := buf.String()
+		assert.Contains(t, out, "Period: 05.01.2025 - 31.03.2025 (3 months)")
+		assert.Regexp(t, `Courses\s+900\.00\s+1100\.00\s+-200\.00\s+OVER`, out)
+		assert.Regexp(t, `Salaire\s+-\s+-4200\.00\s+-`, out)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, printReport(&buf, testReport(), "json"))
+		var got struct {
+			From       string `json:"from"`
+			Months     int    `json:"months"`
+			Categories []struct {
+				Category   string  `json:"category"`
+				Budget     *string `json:"budget"`
+				Actual     string  `json:"actual"`
+				Delta      *string `json:"delta"`
+				OverBudget bool    `json:"over_budget"`
+			} `json:"categories"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+		assert.Equal(t, "2025-01-05", got.From)
+		assert.Equal(t, 3, got.Months)
+		require.Len(t, got.Categories, 2)
+		assert.Equal(t, "-200.00", *got.Categories[0].Delta)
+		assert.True(t, got.Categories[0].OverBudget)
+		assert.Nil(t, got.Categories[1].Budget)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		assert.Error(t, printReport(&bytes.Buffer{}, testReport(), "xml"))
+	})
+}
+
+func TestWarnUnknownCategories(t *testing.T) {
+	logger := logging.NewMockLogger()
+	budgets := map[string]decimal.Decimal{
+		"Courses":                    decimal.NewFromInt(600),
+		"Coursse":                    decimal.NewFromInt(10),
+		models.CategoryUncategorized: decimal.NewFromInt(50),
+	}
+	warnUnknownCategories(budgets, []models.CategoryConfig{{Name: "Courses"}}, logger)
+
+	warnings := logger.GetEntriesByLevel("WARN")
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "Budget for a category missing from the categories file", warnings[0].Message)
+
+	// Without a categories file nothing is checked
+	logger = logging.NewMockLogger()
+	warnUnknownCategories(budgets, nil, logger)
+	assert.Empty(t, logger.GetEntriesByLevel("WARN"))
+}
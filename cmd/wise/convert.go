@@ -0,0 +1,31 @@
+// Package wise handles Wise statement conversion commands.
+package wise
+
+import (
+	"fjacquet/camt-csv/cmd/common"
+	"fjacquet/camt-csv/internal/container"
+
+	"github.com/spf13/cobra"
+)
+
+// Cmd represents the wise command.
+var Cmd = &cobra.Command{
+	Use:   "wise",
+	Short: "Convert Wise CSV to CSV",
+	Long:  `Convert Wise (TransferWise) statement CSV exports to CSV format.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		common.RunConvert(cmd, args, container.Wise, "Wise")
+	},
+}
+
+func init() {
+	common.RegisterFormatFlags(Cmd)
+	common.RegisterLimitFlags(Cmd)
+	common.RegisterInputEncodingFlag(Cmd)
+	common.RegisterFilterFlags(Cmd)
+	common.RegisterOutputDirFlag(Cmd)
+	common.RegisterUncategorizedFlag(Cmd)
+	common.RegisterNoClobberFlag(Cmd)
+	common.RegisterRecursiveFlag(Cmd)
+	common.RegisterWorkbookFlag(Cmd)
+}
@@ -57,9 +57,67 @@ type FileGroup struct {
 	DateRange DateRange // Overall date range for all files
 }
 
+// FileError records a file that could not be parsed during aggregation.
+type FileError struct {
+	File string
+	Err  error
+}
+
+// Error returns the file name followed by the parse error.
+func (e FileError) Error() string {
+	return fmt.Sprintf("%s: %v", filepath.Base(e.File), e.Err)
+}
+
+// Unwrap returns the underlying parse error.
+func (e FileError) Unwrap() error {
+	return e.Err
+}
+
+// AggregationError summarizes the files of a group that failed to parse.
+// The transactions of the remaining files are still returned alongside it.
+type AggregationError struct {
+	AccountID  string
+	TotalFiles int
+	Failed     []FileError
+}
+
+// Error returns a one-line summary listing every failed file.
+func (e *AggregationError) Error() string {
+	details := make([]string, len(e.Failed))
+	for i, f := range e.Failed {
+		details[i] = f.Error()
+	}
+	return fmt.Sprintf("%d of %d files failed for account %s: %s",
+		len(e.Failed), e.TotalFiles, e.AccountID, strings.Join(details, "; "))
+}
+
+// Unwrap exposes the per-file errors to errors.Is and errors.As.
+func (e *AggregationError) Unwrap() []error {
+	errs := make([]error, len(e.Failed))
+	for i, f := range e.Failed {
+		errs[i] = f
+	}
+	return errs
+}
+
+// MixedCurrencyError reports an account group whose transactions are in
+// several currencies, such as an EUR statement among CHF ones, so that its
+// amounts cannot be summed.
+type MixedCurrencyError struct {
+	AccountID  string
+	Currencies []string // sorted
+}
+
+// Error names the account and its currencies.
+func (e *MixedCurrencyError) Error() string {
+	return fmt.Sprintf("account %s mixes currencies %s: its amounts cannot be summed",
+		e.AccountID, strings.Join(e.Currencies, ", "))
+}
+
 // BatchAggregator handles the aggregation of multiple files by account
 type BatchAggregator struct {
 	logger logging.Logger
+	strict bool
 }
 
 // NewBatchAggregator creates a new BatchAggregator instance
@@ -69,14 +127,21 @@ func NewBatchAggregator(logger logging.Logger) *BatchAggregator {
 	}
 }
 
+// SetStrict makes AggregateTransactions fail with a *MixedCurrencyError for
+// an account whose transactions mix currencies, instead of only warning.
+func (ba *BatchAggregator) SetStrict(strict bool) {
+	ba.strict = strict
+}
+
 // GroupFilesByAccount groups files by their account identifier
-// It analyzes filenames to extract account information and groups files accordingly
+// It analyzes filenames to extract account information and groups files accordingly;
+// files whose name encodes no account are grouped by the account read from their content
 func (ba *BatchAggregator) GroupFilesByAccount(files []string) ([]FileGroup, error) {
 	accountGroups := make(map[string]*FileGroup)
 
 	for _, file := range files {
-		// Extract account identifier from filename
-		accountID := common.ExtractAccountFromFilename(file)
+		// Extract account identifier from filename, or from the content
+		accountID := common.ExtractAccountFromFile(file)
 
 		ba.logger.Debug("File mapped to account",
 			logging.Field{Key: "file", Value: filepath.Base(file)},
@@ -146,10 +211,15 @@ func (ba *BatchAggregator) extractDateRangeFromFilename(filename string) DateRan
 }
 
 // AggregateTransactions aggregates transactions from multiple files in a file group
-// It sorts transactions chronologically and handles potential duplicates
+// It sorts transactions chronologically and handles potential duplicates.
+// A file that fails to parse does not abort the group: the transactions of the
+// other files are still returned, together with an *AggregationError listing
+// every failed file. Transactions in several currencies are logged as a
+// warning, or rejected with a *MixedCurrencyError in strict mode.
 func (ba *BatchAggregator) AggregateTransactions(group FileGroup, parseFunc func(string) ([]models.Transaction, error)) ([]models.Transaction, error) {
 	var allTransactions []models.Transaction
 	var sourceFiles []string
+	var failed []FileError
 
 	ba.logger.Info("Aggregating transactions for account",
 		logging.Field{Key: "account", Value: group.AccountID},
@@ -163,6 +233,7 @@ func (ba *BatchAggregator) AggregateTransactions(group FileGroup, parseFunc func
 			ba.logger.Error("Failed to parse file",
 				logging.Field{Key: "file", Value: file},
 				logging.Field{Key: "error", Value: err})
+			failed = append(failed, FileError{File: file, Err: err})
 			continue // Skip this file but continue with others
 		}
 
@@ -185,25 +256,53 @@ func (ba *BatchAggregator) AggregateTransactions(group FileGroup, parseFunc func
 		logging.Field{Key: "account", Value: group.AccountID},
 		logging.Field{Key: "source_files", Value: strings.Join(sourceFiles, ", ")})
 
-	return allTransactions, nil
-}
+	if currencies := transactionCurrencies(allTransactions); len(currencies) > 1 {
+		mixed := &MixedCurrencyError{AccountID: group.AccountID, Currencies: currencies}
+		if ba.strict {
+			ba.logger.Error("Account statements mix currencies",
+				logging.Field{Key: "account", Value: group.AccountID},
+				logging.Field{Key: "currencies", Value: strings.Join(currencies, ", ")})
+			return nil, mixed
+		}
+		ba.logger.Warn("Account statements mix currencies; amounts of the consolidated output cannot be summed (use --strict to fail)",
+			logging.Field{Key: "account", Value: group.AccountID},
+			logging.Field{Key: "currencies", Value: strings.Join(currencies, ", ")})
+	}
 
-// sortTransactionsChronologically sorts transactions by date, then by value date as secondary sort
-func (ba *BatchAggregator) sortTransactionsChronologically(transactions []models.Transaction) {
-	sort.Slice(transactions, func(i, j int) bool {
-		// Primary sort: by transaction date
-		if !transactions[i].Date.Equal(transactions[j].Date) {
-			return transactions[i].Date.Before(transactions[j].Date)
+	if len(failed) > 0 {
+		ba.logger.Warn("Some files could not be aggregated",
+			logging.Field{Key: "account", Value: group.AccountID},
+			logging.Field{Key: "failed_files", Value: len(failed)},
+			logging.Field{Key: "total_files", Value: len(group.Files)})
+		return allTransactions, &AggregationError{
+			AccountID:  group.AccountID,
+			TotalFiles: len(group.Files),
+			Failed:     failed,
 		}
+	}
 
-		// Secondary sort: by value date
-		if !transactions[i].ValueDate.Equal(transactions[j].ValueDate) {
-			return transactions[i].ValueDate.Before(transactions[j].ValueDate)
+	return allTransactions, nil
+}
+
+// transactionCurrencies returns the sorted currencies of transactions,
+// ignoring transactions without one.
+func transactionCurrencies(transactions []models.Transaction) []string {
+	seen := make(map[string]bool)
+	var currencies []string
+	for _, tx := range transactions {
+		if tx.Currency != "" && !seen[tx.Currency] {
+			seen[tx.Currency] = true
+			currencies = append(currencies, tx.Currency)
 		}
+	}
+	sort.Strings(currencies)
+	return currencies
+}
 
-		// Tertiary sort: by amount (for consistency)
-		return transactions[i].Amount.LessThan(transactions[j].Amount)
-	})
+// sortTransactionsChronologically sorts transactions by date, then value date, then amount,
+// then source sequence number (see models.SortChronologically).
+func (ba *BatchAggregator) sortTransactionsChronologically(transactions []models.Transaction) {
+	models.SortChronologically(transactions)
 }
 
 // detectAndLogDuplicates identifies potential duplicate transactions and logs warnings
@@ -312,3 +411,27 @@ func (ba *BatchAggregator) CalculateDateRangeFromTransactions(transactions []mod
 
 	return DateRange{Start: start, End: end}
 }
+
+// StatementPeriod returns the reporting period covered by statements, merged
+// across them. It is zero when there are no statements or any of them has no
+// period, since the merged period would then not cover all transactions.
+func StatementPeriod(statements []models.StatementInfo) DateRange {
+	var period DateRange
+	for _, s := range statements {
+		if s.PeriodStart.IsZero() || s.PeriodEnd.IsZero() {
+			return DateRange{}
+		}
+		period = period.Merge(DateRange{Start: s.PeriodStart, End: s.PeriodEnd})
+	}
+	return period
+}
+
+// ResolveDateRange returns the reporting period of statements when they all
+// declare one, as it is more authoritative than the dates of the transactions
+// they hold, and the date range of transactions otherwise.
+func (ba *BatchAggregator) ResolveDateRange(statements []models.StatementInfo, transactions []models.Transaction) DateRange {
+	if period := StatementPeriod(statements); !period.Start.IsZero() {
+		return period
+	}
+	return ba.CalculateDateRangeFromTransactions(transactions)
+}
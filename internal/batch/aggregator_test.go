@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"fmt"
 	"math/big"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -374,6 +375,29 @@ func TestBatchAggregator_GroupFilesByAccount(t *testing.T) {
 	assert.Equal(t, "54293249", group249.AccountID)
 }
 
+func TestBatchAggregator_GroupFilesByAccount_ReadsAccountFromContent(t *testing.T) {
+	dir := t.TempDir()
+	statement := func(iban string) []byte {
+		return []byte(`<Document><BkToCstmrStmt><Stmt><Acct><Id><IBAN>` + iban + `</IBAN></Id></Acct></Stmt></BkToCstmrStmt></Document>`)
+	}
+	files := []string{
+		filepath.Join(dir, "ubs-april.xml"),
+		filepath.Join(dir, "ubs-may.xml"),
+		filepath.Join(dir, "statement.xml"),
+	}
+	require.NoError(t, os.WriteFile(files[0], statement("CH9300762011623852957"), 0600))
+	require.NoError(t, os.WriteFile(files[1], statement("CH9300762011623852957"), 0600))
+	require.NoError(t, os.WriteFile(files[2], statement("CH5604835012345678009"), 0600))
+
+	groups, err := NewBatchAggregator(logging.NewMockLogger()).GroupFilesByAccount(files)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	assert.Equal(t, "CH5604835012345678009", groups[0].AccountID)
+	assert.Equal(t, []string{files[2]}, groups[0].Files)
+	assert.Equal(t, "CH9300762011623852957", groups[1].AccountID)
+	assert.Equal(t, files[:2], groups[1].Files)
+}
+
 // **Feature: parser-enhancements, Property 4: Duplicate transaction preservation**
 // **Validates: Requirements 1.4**
 func TestProperty_DuplicateTransactionPreservation(t *testing.T) {
@@ -962,3 +986,103 @@ func generateRandomOutputDirectory() string {
 
 	return baseDir
 }
+
+func TestBatchAggregator_AggregateTransactions_PartialFailure(t *testing.T) {
+	aggregator := NewBatchAggregator(logging.NewMockLogger())
+	group := FileGroup{
+		AccountID: "CH9300762011623852957",
+		Files:     []string{"/in/jan.xml", "/in/feb.xml", "/in/mar.xml"},
+	}
+	parseErr := fmt.Errorf("unexpected EOF")
+
+	parseFunc := func(file string) ([]models.Transaction, error) {
+		if filepath.Base(file) == "feb.xml" {
+			return nil, parseErr
+		}
+		month := time.January
+		if filepath.Base(file) == "mar.xml" {
+			month = time.March
+		}
+		return []models.Transaction{{
+			Date:   time.Date(2025, month, 10, 0, 0, 0, 0, time.UTC),
+			Amount: decimal.NewFromInt(int64(month)),
+		}}, nil
+	}
+
+	transactions, err := aggregator.AggregateTransactions(group, parseFunc)
+
+	require.Error(t, err)
+	assert.Len(t, transactions, 2, "transactions from the good files are kept")
+	assert.Equal(t, time.January, transactions[0].Date.Month())
+	assert.Equal(t, time.March, transactions[1].Date.Month())
+
+	var aggErr *AggregationError
+	require.ErrorAs(t, err, &aggErr)
+	assert.Equal(t, 3, aggErr.TotalFiles)
+	require.Len(t, aggErr.Failed, 1)
+	assert.Equal(t, "/in/feb.xml", aggErr.Failed[0].File)
+	assert.ErrorIs(t, err, parseErr)
+	assert.Equal(t, "1 of 3 files failed for account CH9300762011623852957: feb.xml: unexpected EOF", err.Error())
+}
+
+func TestBatchAggregator_AggregateTransactions_AllSucceed(t *testing.T) {
+	aggregator := NewBatchAggregator(logging.NewMockLogger())
+	group := FileGroup{AccountID: "ACC", Files: []string{"a.xml", "b.xml"}}
+
+	transactions, err := aggregator.AggregateTransactions(group, func(string) ([]models.Transaction, error) {
+		return []models.Transaction{{Amount: decimal.NewFromInt(1)}}, nil
+	})
+
+	require.NoError(t, err)
+	assert.Len(t, transactions, 2)
+}
+
+func TestBatchAggregator_AggregateTransactions_MixedCurrencies(t *testing.T) {
+	group := FileGroup{AccountID: "CH9300762011623852957", Files: []string{"jan.xml", "feb.xml", "mar.xml"}}
+	parseFunc := func(file string) ([]models.Transaction, error) {
+		currency := "CHF"
+		if file == "feb.xml" {
+			currency = "EUR"
+		}
+		return []models.Transaction{{Amount: decimal.NewFromInt(10), Currency: currency}}, nil
+	}
+
+	logger := logging.NewMockLogger()
+	transactions, err := NewBatchAggregator(logger).AggregateTransactions(group, parseFunc)
+	require.NoError(t, err)
+	assert.Len(t, transactions, 3, "mixed currencies only warn by default")
+	assert.True(t, logger.HasEntry("WARN", "Account statements mix currencies; amounts of the consolidated output cannot be summed (use --strict to fail)"))
+
+	aggregator := NewBatchAggregator(logging.NewMockLogger())
+	aggregator.SetStrict(true)
+	transactions, err = aggregator.AggregateTransactions(group, parseFunc)
+	assert.Empty(t, transactions)
+	var mixedErr *MixedCurrencyError
+	require.ErrorAs(t, err, &mixedErr)
+	assert.Equal(t, []string{"CHF", "EUR"}, mixedErr.Currencies)
+	assert.Equal(t, "account CH9300762011623852957 mixes currencies CHF, EUR: its amounts cannot be summed", err.Error())
+
+	// A single currency passes in strict mode
+	transactions, err = aggregator.AggregateTransactions(FileGroup{AccountID: "ACC", Files: []string{"jan.xml"}}, parseFunc)
+	require.NoError(t, err)
+	assert.Len(t, transactions, 1)
+}
+
+func TestBatchAggregator_ResolveDateRange(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 6, d, 0, 0, 0, 0, time.UTC) }
+	transactions := []models.Transaction{{Date: day(3)}, {Date: day(25)}}
+	aggregator := NewBatchAggregator(logging.NewMockLogger())
+
+	// The reporting periods of all statements are merged
+	statements := []models.StatementInfo{
+		{PeriodStart: day(16), PeriodEnd: day(30)},
+		{PeriodStart: day(1), PeriodEnd: day(15)},
+	}
+	assert.Equal(t, DateRange{Start: day(1), End: day(30)}, aggregator.ResolveDateRange(statements, transactions))
+
+	// A statement without a period falls back to the transaction dates
+	statements = append(statements, models.StatementInfo{})
+	assert.True(t, StatementPeriod(statements).Start.IsZero())
+	assert.Equal(t, DateRange{Start: day(3), End: day(25)}, aggregator.ResolveDateRange(statements, transactions))
+	assert.Equal(t, DateRange{Start: day(3), End: day(25)}, aggregator.ResolveDateRange(nil, transactions))
+}
@@ -0,0 +1,169 @@
+package batch
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"fjacquet/camt-csv/internal/logging"
+)
+
+// maxArchiveEntrySize caps the decompressed size of a single archive entry
+// to protect against decompression bombs.
+const maxArchiveEntrySize = 100 << 20 // 100 MiB
+
+// archiveEntry is a file extracted in memory from a ZIP archive.
+type archiveEntry struct {
+	Name string // Base name of the entry, or its flattened path when the base name is not unique; used for the output file name
+	Path string // Path of the entry inside the archive
+	Data []byte
+}
+
+// IsZipArchive reports whether path names a ZIP archive, based on its extension.
+func IsZipArchive(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".zip")
+}
+
+// readZipEntries extracts the regular files of a ZIP archive into memory.
+// Directories, hidden files and macOS resource forks are skipped. Entries whose
+// names are absolute or escape the archive root (zip-slip) are rejected.
+// Entries sharing a base name in different folders are named after their
+// path, with "/" replaced by "_", so that their output files do not collide.
+func readZipEntries(archivePath string) ([]archiveEntry, error) {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip archive: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	var entries []archiveEntry
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		name := f.Name
+		if !filepath.IsLocal(filepath.FromSlash(name)) || strings.Contains(name, `\`) {
+			return nil, fmt.Errorf("unsafe path in zip archive: %q", name)
+		}
+
+		base := path.Base(name)
+		if strings.HasPrefix(base, ".") || strings.HasPrefix(name, "__MACOSX/") {
+			continue
+		}
+
+		data, err := readZipFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from zip archive: %w", name, err)
+		}
+		entries = append(entries, archiveEntry{Name: base, Path: name, Data: data})
+	}
+
+	bases := make(map[string]int, len(entries))
+	for _, entry := range entries {
+		bases[entry.Name]++
+	}
+	for i, entry := range entries {
+		if bases[entry.Name] > 1 {
+			entries[i].Name = strings.ReplaceAll(entry.Path, "/", "_")
+		}
+	}
+
+	// Sort entries for consistent ordering, like loose files
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+
+	return entries, nil
+}
+
+// readZipFile reads a single archive entry, enforcing maxArchiveEntrySize.
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxArchiveEntrySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxArchiveEntrySize {
+		return nil, fmt.Errorf("entry exceeds %d bytes", maxArchiveEntrySize)
+	}
+	return data, nil
+}
+
+// processArchive expands a ZIP archive in memory and converts each entry,
+// returning one BatchResult per entry. The output file of an entry is named
+// after the entry itself, so account detection from file names works as for
+// loose files. Each entry is validated from a temporary copy, then parsed from
+// memory; failures are recorded in their results. If the archive cannot be
+// read, a single failed result is returned.
+func (bp *BatchProcessor) processArchive(ctx context.Context, archivePath, outputDir string) []BatchResult {
+	archiveName := filepath.Base(archivePath)
+
+	bp.logger.Info("Expanding zip archive",
+		logging.Field{Key: "file", Value: archiveName})
+
+	entries, err := readZipEntries(archivePath)
+	if err != nil {
+		bp.logger.WithError(err).Warn("Failed to read zip archive",
+			logging.Field{Key: "file", Value: archiveName})
+		return []BatchResult{{
+			FilePath: archivePath,
+			FileName: archiveName,
+			Error:    fmt.Sprintf("archive_error: %v", err),
+		}}
+	}
+
+	// ValidateFormat reads a path, so the entries are validated from a copy
+	tempDir, err := os.MkdirTemp("", "camt-csv-zip-*")
+	if err != nil {
+		return []BatchResult{{
+			FilePath: archivePath,
+			FileName: archiveName,
+			Error:    fmt.Sprintf("archive_error: %v", err),
+		}}
+	}
+	defer func() {
+		if err := os.RemoveAll(tempDir); err != nil {
+			bp.logger.WithError(err).Warn("Failed to remove temporary directory",
+				logging.Field{Key: "dir", Value: tempDir})
+		}
+	}()
+
+	results := make([]BatchResult, 0, len(entries))
+	for _, entry := range entries {
+		bp.logger.Info("Processing archive entry",
+			logging.Field{Key: "archive", Value: archiveName},
+			logging.Field{Key: "file", Value: entry.Name})
+
+		result := BatchResult{
+			FilePath: archivePath,
+			FileName: entry.Name,
+			Archive:  archiveName,
+		}
+		entryPath := filepath.Join(tempDir, entry.Name)
+		if err := os.WriteFile(entryPath, entry.Data, 0600); err != nil {
+			result.Error = fmt.Sprintf("archive_error: %v", err)
+			results = append(results, result)
+			continue
+		}
+		if !bp.validate(entryPath, entry.Name, &result) {
+			results = append(results, result)
+			continue
+		}
+		results = append(results, bp.convert(ctx, bytes.NewReader(entry.Data), entry.Name, outputDir, result))
+	}
+
+	return results
+}
@@ -0,0 +1,193 @@
+package batch
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeZip creates a ZIP archive at path containing the given name -> content entries.
+func writeZip(t *testing.T, path string, entries map[string]string) {
+	t.Helper()
+	f, err := os.Create(path) // #nosec G304 -- test file
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	w := zip.NewWriter(f)
+	for name, content := range entries {
+		fw, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = fw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+}
+
+func TestIsZipArchive(t *testing.T) {
+	assert.True(t, IsZipArchive("statements.zip"))
+	assert.True(t, IsZipArchive("/tmp/STATEMENTS.ZIP"))
+	assert.False(t, IsZipArchive("statement.xml"))
+	assert.False(t, IsZipArchive("zip"))
+}
+
+func TestReadZipEntries(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "monthly.zip")
+	writeZip(t, archive, map[string]string{
+		"b/CAMT.053_CH11_2025-01-01_2025-01-31_1.xml": "b",
+		"CAMT.053_CH22_2025-01-01_2025-01-31_1.xml":   "a",
+		"__MACOSX/._CAMT.053_CH22.xml":                "ignored",
+		".hidden.xml":                                 "ignored",
+		"docs/":                                       "",
+	})
+
+	entries, err := readZipEntries(archive)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "CAMT.053_CH11_2025-01-01_2025-01-31_1.xml", entries[0].Name)
+	assert.Equal(t, []byte("b"), entries[0].Data)
+	assert.Equal(t, "CAMT.053_CH22_2025-01-01_2025-01-31_1.xml", entries[1].Name)
+}
+
+func TestReadZipEntries_SameBaseName(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "yearly.zip")
+	writeZip(t, archive, map[string]string{
+		"2025-01/statement.xml": "january",
+		"2025-02/statement.xml": "february",
+		"2025-02/other.xml":     "other",
+	})
+
+	entries, err := readZipEntries(archive)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "2025-01_statement.xml", entries[0].Name)
+	assert.Equal(t, []byte("january"), entries[0].Data)
+	assert.Equal(t, "2025-02_statement.xml", entries[1].Name)
+	assert.Equal(t, "other.xml", entries[2].Name, "unique base names are kept")
+}
+
+func TestReadZipEntries_RejectsZipSlip(t *testing.T) {
+	for _, name := range []string{"../evil.xml", "/etc/evil.xml", `..\evil.xml`} {
+		t.Run(name, func(t *testing.T) {
+			archive := filepath.Join(t.TempDir(), "evil.zip")
+			writeZip(t, archive, map[string]string{name: "x"})
+
+			_, err := readZipEntries(archive)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "unsafe path")
+		})
+	}
+}
+
+func TestProcessDirectory_ZipArchive(t *testing.T) {
+	tempDir := t.TempDir()
+	inputDir := filepath.Join(tempDir, "input")
+	outputDir := filepath.Join(tempDir, "output")
+	require.NoError(t, os.MkdirAll(inputDir, 0750))
+
+	writeZip(t, filepath.Join(inputDir, "january.zip"), map[string]string{
+		"CAMT.053_CH11_2025-01-01_2025-01-31_1.xml": "good",
+		"CAMT.053_CH22_2025-01-01_2025-01-31_1.xml": "bad",
+	})
+	require.NoError(t, os.WriteFile(filepath.Join(inputDir, "loose.xml"), []byte("good"), 0600))
+
+	mockParser := newMockParser()
+	mockParser.validateFunc = func(filePath string) (bool, error) {
+		return true, nil
+	}
+	mockParser.parseFunc = func(ctx context.Context, r io.Reader) ([]models.Transaction, error) {
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		if string(data) == "bad" {
+			return nil, errors.New("not a CAMT document")
+		}
+		return createTestTransactions(2), nil
+	}
+
+	processor := NewBatchProcessor(mockParser, logging.NewLogrusAdapter("error", "text"), nil)
+	manifest, err := processor.ProcessDirectory(context.Background(), inputDir, outputDir)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, manifest.TotalFiles)
+	assert.Equal(t, 2, manifest.SuccessCount)
+	assert.Equal(t, 1, manifest.FailureCount)
+	require.Len(t, manifest.Results, 3)
+	assert.Equal(t, "january.zip", manifest.Results[0].Archive)
+	assert.Empty(t, manifest.Results[2].Archive)
+
+	assert.FileExists(t, filepath.Join(outputDir, "CAMT.053_CH11_2025-01-01_2025-01-31_1.csv"))
+	assert.NoFileExists(t, filepath.Join(outputDir, "CAMT.053_CH22_2025-01-01_2025-01-31_1.csv"))
+	assert.FileExists(t, filepath.Join(outputDir, "loose.csv"))
+}
+
+func TestProcessDirectory_ZipAsInput(t *testing.T) {
+	tempDir := t.TempDir()
+	archive := filepath.Join(tempDir, "statements.zip")
+	outputDir := filepath.Join(tempDir, "output")
+	writeZip(t, archive, map[string]string{"statement.xml": "good"})
+
+	mockParser := newMockParser()
+	mockParser.parseFunc = func(ctx context.Context, r io.Reader) ([]models.Transaction, error) {
+		return createTestTransactions(1), nil
+	}
+
+	processor := NewBatchProcessor(mockParser, logging.NewLogrusAdapter("error", "text"), nil)
+	manifest, err := processor.ProcessDirectory(context.Background(), archive, outputDir)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, manifest.TotalFiles)
+	assert.Equal(t, 0, manifest.ExitCode())
+	assert.FileExists(t, filepath.Join(outputDir, "statement.csv"))
+}
+
+func TestProcessDirectory_ZipValidatesEntries(t *testing.T) {
+	tempDir := t.TempDir()
+	archive := filepath.Join(tempDir, "statements.zip")
+	outputDir := filepath.Join(tempDir, "output")
+	writeZip(t, archive, map[string]string{"statement.xml": "good", "notes.txt": "not a statement"})
+
+	mockParser := newMockParser()
+	mockParser.validateFunc = func(filePath string) (bool, error) {
+		data, err := os.ReadFile(filePath) // #nosec G304 -- test file
+		require.NoError(t, err)
+		return string(data) == "good", nil
+	}
+	mockParser.parseFunc = func(ctx context.Context, r io.Reader) ([]models.Transaction, error) {
+		return createTestTransactions(1), nil
+	}
+
+	processor := NewBatchProcessor(mockParser, logging.NewLogrusAdapter("error", "text"), nil)
+	manifest, err := processor.ProcessDirectory(context.Background(), archive, outputDir)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, manifest.SuccessCount)
+	require.Len(t, manifest.Results, 2)
+	assert.Equal(t, "notes.txt", manifest.Results[0].FileName)
+	assert.Equal(t, "validation_failed", manifest.Results[0].Error)
+	assert.FileExists(t, filepath.Join(outputDir, "statement.csv"))
+	assert.NoFileExists(t, filepath.Join(outputDir, "notes.csv"))
+}
+
+func TestProcessDirectory_CorruptZip(t *testing.T) {
+	tempDir := t.TempDir()
+	inputDir := filepath.Join(tempDir, "input")
+	require.NoError(t, os.MkdirAll(inputDir, 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(inputDir, "broken.zip"), []byte("not a zip"), 0600))
+
+	processor := NewBatchProcessor(newMockParser(), logging.NewLogrusAdapter("error", "text"), nil)
+	manifest, err := processor.ProcessDirectory(context.Background(), inputDir, filepath.Join(tempDir, "output"))
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, manifest.TotalFiles)
+	assert.Equal(t, 1, manifest.FailureCount)
+	assert.Contains(t, manifest.Results[0].Error, "archive_error")
+}
@@ -0,0 +1,62 @@
+package batch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IsGlobPattern reports whether path is a glob pattern such as
+// "statements/2025-*.xml" rather than the path of a file or directory.
+func IsGlobPattern(path string) bool {
+	return strings.ContainsAny(path, "*?")
+}
+
+// globFiles returns the sorted files matching pattern, skipping directories
+// and hidden files like discoverFiles does. It returns an error when the
+// pattern is malformed, matches no file, or matches two files that would be
+// converted to the same output name.
+func globFiles(pattern string) ([]string, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input pattern %q: %w", pattern, err)
+	}
+
+	var files []string
+	for _, match := range matches {
+		if strings.HasPrefix(filepath.Base(match), ".") {
+			continue
+		}
+		if info, err := os.Stat(match); err != nil || info.IsDir() {
+			continue
+		}
+		files = append(files, match)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files match %s", pattern)
+	}
+	sort.Strings(files)
+
+	// Matches from different directories are written side by side
+	if err := checkOutputNames(files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// checkOutputNames returns an error when two of files, possibly from
+// different directories, would be converted to the same output name.
+func checkOutputNames(files []string) error {
+	outputs := make(map[string]string, len(files))
+	for _, file := range files {
+		base := filepath.Base(file)
+		output := strings.TrimSuffix(base, filepath.Ext(base)) + ".csv"
+		if other, ok := outputs[output]; ok {
+			return fmt.Errorf("%s and %s would both be written to %s", other, file, output)
+		}
+		outputs[output] = file
+	}
+	return nil
+}
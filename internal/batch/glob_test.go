@@ -0,0 +1,75 @@
+package batch
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsGlobPattern(t *testing.T) {
+	assert.True(t, IsGlobPattern("statements/2025-*.xml"))
+	assert.True(t, IsGlobPattern("statements/2025-0?.xml"))
+	assert.False(t, IsGlobPattern("statements"))
+	assert.False(t, IsGlobPattern("statements/archive.zip"))
+}
+
+func TestProcessDirectory_GlobPattern(t *testing.T) {
+	tempDir := t.TempDir()
+	outputDir := filepath.Join(tempDir, "output")
+	files := map[string]string{
+		"ubs/2025-01.xml":   "match",
+		"bcv/2025-02.xml":   "match",
+		"bcv/2024-12.xml":   "older",
+		"bcv/.2025-03.xml":  "hidden",
+		"bcv/2025-notes":    "other extension",
+		"bcv/2025-dir.xml/": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if content == "" {
+			require.NoError(t, os.MkdirAll(path, 0750))
+			continue
+		}
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+
+	mockParser := newMockParser()
+	mockParser.parseFunc = func(ctx context.Context, r io.Reader) ([]models.Transaction, error) {
+		return createTestTransactions(2), nil
+	}
+	processor := NewBatchProcessor(mockParser, logging.NewMockLogger(), nil)
+
+	manifest, err := processor.ProcessDirectory(context.Background(), filepath.Join(tempDir, "*", "2025-*.xml"), outputDir)
+	require.NoError(t, err)
+	assert.Equal(t, 2, manifest.TotalFiles)
+	assert.Equal(t, 2, manifest.SuccessCount)
+	assert.Equal(t, []string{"2025-02.xml", "2025-01.xml"}, []string{manifest.Results[0].FileName, manifest.Results[1].FileName})
+	assert.FileExists(t, filepath.Join(outputDir, "2025-01.csv"))
+	assert.FileExists(t, filepath.Join(outputDir, "2025-02.csv"))
+}
+
+func TestProcessDirectory_GlobErrors(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, dir := range []string{"a", "b"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(tempDir, dir), 0750))
+		require.NoError(t, os.WriteFile(filepath.Join(tempDir, dir, "statement.xml"), []byte("x"), 0600))
+	}
+	processor := NewBatchProcessor(newMockParser(), logging.NewMockLogger(), nil)
+	outputDir := filepath.Join(tempDir, "output")
+
+	_, err := processor.ProcessDirectory(context.Background(), filepath.Join(tempDir, "*", "statement.xml"), outputDir)
+	assert.ErrorContains(t, err, "would both be written to statement.csv")
+
+	_, err = processor.ProcessDirectory(context.Background(), filepath.Join(tempDir, "*.csv"), outputDir)
+	assert.ErrorContains(t, err, "no files match")
+	assert.NoDirExists(t, outputDir)
+}
@@ -13,8 +13,12 @@ type BatchResult struct {
 	FilePath    string `json:"file_path"`
 	FileName    string `json:"file_name"`
 	Success     bool   `json:"success"`
-	Error       string `json:"error"`        // Only populated if Success=false
-	RecordCount int    `json:"record_count"` // Number of transactions extracted
+	Skipped     bool   `json:"skipped,omitempty"` // Output already existed and overwriting was disabled
+	Error       string `json:"error"`             // Only populated if Success=false
+	RecordCount int    `json:"record_count"`      // Number of transactions extracted
+	Archive     string `json:"archive,omitempty"` // ZIP archive the file was extracted from, if any
+	// Statement-level notes (CAMT AddtlStmtInf) found in the file, informational only
+	StatementNotes []string `json:"statement_notes,omitempty"`
 }
 
 // BatchManifest aggregates results from a batch operation
@@ -22,6 +26,7 @@ type BatchManifest struct {
 	TotalFiles   int           `json:"total_files"`
 	SuccessCount int           `json:"success_count"`
 	FailureCount int           `json:"failure_count"`
+	SkippedCount int           `json:"skipped_count"`
 	Results      []BatchResult `json:"results"`
 	Duration     time.Duration `json:"duration"`
 	ProcessedAt  time.Time     `json:"processed_at"`
@@ -1,8 +1,11 @@
 package batch
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
@@ -12,7 +15,10 @@ import (
 	"fjacquet/camt-csv/internal/common"
 	"fjacquet/camt-csv/internal/formatter"
 	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
 	"fjacquet/camt-csv/internal/parser"
+	"fjacquet/camt-csv/internal/parsererror"
+	"fjacquet/camt-csv/internal/progress"
 )
 
 // BatchProcessor handles standardized batch processing for any parser
@@ -20,6 +26,15 @@ type BatchProcessor struct {
 	parser    parser.FullParser
 	logger    logging.Logger
 	formatter formatter.OutputFormatter
+	progress  progress.Reporter
+	noClobber bool
+	recursive bool
+	strict    bool
+	bom       bool
+
+	// Statement metadata collected during ProcessDirectory when the parser
+	// implements parser.StatementInfoReader
+	statements []models.StatementInfo
 }
 
 // NewBatchProcessor creates a new BatchProcessor instance that wraps the provided parser.
@@ -35,19 +50,57 @@ func NewBatchProcessor(p parser.FullParser, logger logging.Logger, fmt formatter
 		parser:    p,
 		logger:    logger,
 		formatter: fmt,
+		progress:  progress.Nop(),
 	}
 }
 
+// SetProgress configures the reporter that is advanced once per input file.
+// Pass nil to disable progress reporting.
+func (bp *BatchProcessor) SetProgress(r progress.Reporter) {
+	if r == nil {
+		r = progress.Nop()
+	}
+	bp.progress = r
+}
+
+// SetNoClobber makes the processor skip, with a warning, input files whose
+// output CSV already exists instead of overwriting it.
+func (bp *BatchProcessor) SetNoClobber(noClobber bool) {
+	bp.noClobber = noClobber
+}
+
+// SetRecursive makes the processor also convert the files of inputDir's
+// subdirectories. Output files are still written flat in outputDir.
+func (bp *BatchProcessor) SetRecursive(recursive bool) {
+	bp.recursive = recursive
+}
+
+// SetBOM makes the processor start each output CSV with a UTF-8 byte order
+// mark, for Excel on Windows.
+func (bp *BatchProcessor) SetBOM(bom bool) {
+	bp.bom = bom
+}
+
+// SetStrict makes ProcessWorkbook fail the files of an account whose
+// statements mix currencies instead of only warning (see MixedCurrencyError).
+func (bp *BatchProcessor) SetStrict(strict bool) {
+	bp.strict = strict
+}
+
 // ProcessDirectory processes all files in inputDir and writes converted files to outputDir.
+// ZIP archives found in inputDir are expanded in memory and each entry is processed
+// like a loose file; inputDir may also point directly at a single ZIP archive, or be
+// a glob pattern (see IsGlobPattern) whose matching files are processed.
+// With SetRecursive, the files of subdirectories are processed as well.
 // Returns a manifest (never nil) containing results for each file processed.
 // Individual file failures are captured in the manifest, not returned as errors.
 // An error is returned only for configuration or permission issues with the directories.
 func (bp *BatchProcessor) ProcessDirectory(ctx context.Context, inputDir, outputDir string) (*BatchManifest, error) {
 	startTime := time.Now()
 
-	// Validate input directory exists
-	if _, err := os.Stat(inputDir); os.IsNotExist(err) {
-		return nil, fmt.Errorf("input directory does not exist: %s", inputDir)
+	files, err := bp.inputFiles(inputDir, outputDir)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create output directory if it doesn't exist
@@ -55,9 +108,6 @@ func (bp *BatchProcessor) ProcessDirectory(ctx context.Context, inputDir, output
 		return nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// Discover files to process
-	files := bp.discoverFiles(inputDir)
-
 	bp.logger.Info("Starting batch processing",
 		logging.Field{Key: "input_dir", Value: inputDir},
 		logging.Field{Key: "output_dir", Value: outputDir},
@@ -72,11 +122,15 @@ func (bp *BatchProcessor) ProcessDirectory(ctx context.Context, inputDir, output
 		ProcessedAt:  time.Now(),
 	}
 
+	bp.statements = nil
+	bp.progress.Start(len(files), "files")
+
 	// Process each file sequentially
 	for _, filePath := range files {
 		// Check for cancellation
 		select {
 		case <-ctx.Done():
+			bp.progress.Finish()
 			bp.logger.Warn("Batch processing cancelled",
 				logging.Field{Key: "processed", Value: len(manifest.Results)},
 				logging.Field{Key: "total", Value: manifest.TotalFiles})
@@ -85,15 +139,31 @@ func (bp *BatchProcessor) ProcessDirectory(ctx context.Context, inputDir, output
 		default:
 		}
 
-		result := bp.processFile(ctx, filePath, outputDir)
-		manifest.Results = append(manifest.Results, result)
-
-		if result.Success {
-			manifest.SuccessCount++
+		var results []BatchResult
+		if IsZipArchive(filePath) {
+			results = bp.processArchive(ctx, filePath, outputDir)
+			// The archive counts as its entries rather than as one file
+			manifest.TotalFiles += len(results) - 1
 		} else {
-			manifest.FailureCount++
+			results = []BatchResult{bp.processFile(ctx, filePath, outputDir)}
 		}
+
+		for _, result := range results {
+			manifest.Results = append(manifest.Results, result)
+			switch {
+			case result.Skipped:
+				manifest.SkippedCount++
+			case result.Success:
+				manifest.SuccessCount++
+			default:
+				manifest.FailureCount++
+			}
+		}
+		bp.progress.Increment()
 	}
+	bp.progress.Finish()
+
+	bp.warnSequenceGaps()
 
 	// Calculate duration
 	manifest.Duration = time.Since(startTime)
@@ -117,8 +187,29 @@ func (bp *BatchProcessor) ProcessDirectory(ctx context.Context, inputDir, output
 	return manifest, nil
 }
 
+// inputFiles returns the files selected by inputDir: the files matching a
+// glob pattern, a single ZIP archive, or the files of a directory and, with
+// SetRecursive, of its subdirectories.
+func (bp *BatchProcessor) inputFiles(inputDir, outputDir string) ([]string, error) {
+	switch {
+	case IsGlobPattern(inputDir):
+		return globFiles(inputDir)
+	case IsZipArchive(inputDir):
+		return []string{inputDir}, nil
+	}
+
+	// Validate input directory exists
+	if _, err := os.Stat(inputDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("input directory does not exist: %s", inputDir)
+	}
+	if bp.recursive {
+		return walkFiles(inputDir, outputDir)
+	}
+	return bp.discoverFiles(inputDir), nil
+}
+
 // discoverFiles returns a sorted list of processable files in the given directory.
-// Only returns files in the top-level directory (not recursive).
+// Only returns files in the top-level directory (see walkFiles for recursion).
 // Skips hidden files (starting with '.') and directories.
 func (bp *BatchProcessor) discoverFiles(inputDir string) []string {
 	var files []string
@@ -170,18 +261,7 @@ func (bp *BatchProcessor) processFile(ctx context.Context, filePath, outputDir s
 	}
 
 	// Step 1: Validate format
-	isValid, err := bp.parser.ValidateFormat(filePath)
-	if err != nil {
-		result.Error = fmt.Sprintf("validation_error: %v", err)
-		bp.logger.WithError(err).Warn("Validation error",
-			logging.Field{Key: "file", Value: fileName})
-		return result
-	}
-
-	if !isValid {
-		result.Error = "validation_failed"
-		bp.logger.Warn("Invalid format",
-			logging.Field{Key: "file", Value: fileName})
+	if !bp.validate(filePath, fileName, &result) {
 		return result
 	}
 
@@ -200,23 +280,86 @@ func (bp *BatchProcessor) processFile(ctx context.Context, filePath, outputDir s
 		}
 	}()
 
-	transactions, err := bp.parser.Parse(ctx, file)
+	return bp.convert(ctx, file, fileName, outputDir, result)
+}
+
+// validate checks the format of the file at filePath, recording the reason in
+// result when it cannot be converted.
+func (bp *BatchProcessor) validate(filePath, fileName string, result *BatchResult) bool {
+	isValid, err := bp.parser.ValidateFormat(filePath)
 	if err != nil {
-		result.Error = err.Error()
-		bp.logger.WithError(err).Warn("Parse error",
+		result.Error = fmt.Sprintf("validation_error: %v", err)
+		bp.logger.WithError(err).Warn("Validation error",
 			logging.Field{Key: "file", Value: fileName})
-		return result
+		return false
+	}
+
+	if !isValid {
+		result.Error = "validation_failed"
+		bp.logger.Warn("Invalid format",
+			logging.Field{Key: "file", Value: fileName})
+		return false
 	}
+	return true
+}
 
-	// Step 3: Generate output filename (preserve basename, change extension to .csv)
+// convert parses transactions from r and writes them to outputDir as
+// <name without extension>.csv, recording the outcome in result.
+func (bp *BatchProcessor) convert(ctx context.Context, r io.Reader, fileName, outputDir string, result BatchResult) BatchResult {
+	// Generate output filename (preserve basename, change extension to .csv)
 	baseName := strings.TrimSuffix(fileName, filepath.Ext(fileName))
 	outputFileName := baseName + ".csv"
 	outputPath := filepath.Join(outputDir, outputFileName)
 
-	// Step 4: Write CSV using formatter
+	if bp.noClobber {
+		if err := common.CheckNoClobber(outputPath); err != nil {
+			result.Error = err.Error()
+			if !errors.Is(err, common.ErrOutputExists) {
+				bp.logger.WithError(err).Warn("Failed to check output file",
+					logging.Field{Key: "file", Value: fileName})
+				return result
+			}
+			result.Skipped = true
+			bp.logger.Warn("Skipping file: output already exists",
+				logging.Field{Key: "file", Value: fileName},
+				logging.Field{Key: "output", Value: outputFileName})
+			return result
+		}
+	}
+
+	transactions, notes, err := bp.parse(ctx, r, fileName)
+	if !formatter.Anonymizes(bp.formatter) {
+		// Notes are free text, kept out of the manifest of anonymized output
+		result.StatementNotes = notes
+	}
+	if err != nil {
+		result.Error = err.Error()
+		bp.logger.WithError(err).Warn("Parse error",
+			logging.Field{Key: "file", Value: fileName})
+		return result
+	}
+	parser.WarnEmptyTransactions(ctx, transactions, fileName, bp.logger)
+	transactions = parser.FilterTransactions(ctx, transactions)
+
+	// Write CSV using formatter
 	delimiter := bp.formatter.Delimiter()
-	if err := common.WriteTransactionsToCSVWithFormatter(
-		transactions, outputPath, bp.logger, bp.formatter, delimiter); err != nil {
+	switch {
+	case bp.noClobber:
+		// The output may have been created while the file was parsed
+		err = common.WriteTransactionsToNewCSV(transactions, outputPath, bp.logger, bp.formatter, delimiter, bp.bom)
+	case bp.bom:
+		err = common.WriteTransactionsToCSVWithBOM(transactions, outputPath, bp.logger, bp.formatter, delimiter)
+	default:
+		err = common.WriteTransactionsToCSVWithFormatter(transactions, outputPath, bp.logger, bp.formatter, delimiter)
+	}
+	if errors.Is(err, common.ErrOutputExists) {
+		result.Error = err.Error()
+		result.Skipped = true
+		bp.logger.Warn("Skipping file: output already exists",
+			logging.Field{Key: "file", Value: fileName},
+			logging.Field{Key: "output", Value: outputFileName})
+		return result
+	} else if err != nil {
 		result.Error = fmt.Sprintf("write_error: %v", err)
 		bp.logger.WithError(err).Warn("Failed to write CSV",
 			logging.Field{Key: "file", Value: fileName},
@@ -227,6 +370,7 @@ func (bp *BatchProcessor) processFile(ctx context.Context, filePath, outputDir s
 	// Success!
 	result.Success = true
 	result.RecordCount = len(transactions)
+	parser.RecordCategorization(ctx, transactions)
 
 	bp.logger.Info("Successfully processed file",
 		logging.Field{Key: "file", Value: fileName},
@@ -235,3 +379,64 @@ func (bp *BatchProcessor) processFile(ctx context.Context, filePath, outputDir s
 
 	return result
 }
+
+// parse parses the transactions of r, applying the transaction limit of ctx,
+// and records the statement metadata of the file, returning its statement
+// notes. The metadata is read after parsing, from the bytes the parser read
+// followed by the rest of r, so that a file stopped by --max-transactions is
+// not read in full.
+func (bp *BatchProcessor) parse(ctx context.Context, r io.Reader, fileName string) ([]models.Transaction, []string, error) {
+	infoReader, readsInfo := bp.parser.(parser.StatementInfoReader)
+	var consumed bytes.Buffer
+	input := r
+	if readsInfo {
+		input = io.TeeReader(r, &consumed)
+	}
+
+	transactions, err := bp.parser.Parse(ctx, input)
+	if err == nil {
+		// Also covers registered parsers that do not count their rows, and
+		// an expired --timeout
+		err = parser.CheckTransactionLimit(ctx, len(transactions))
+	}
+	var notes []string
+	if readsInfo && !errors.Is(err, parsererror.ErrTooManyTransactions) && ctx.Err() == nil {
+		notes = bp.recordStatements(infoReader, io.MultiReader(&consumed, r), fileName)
+	}
+	return transactions, notes, err
+}
+
+// recordStatements collects the statement metadata of one input file and
+// returns the statement notes it carries. Failures are only logged: the
+// file's parse error is reported on its own.
+func (bp *BatchProcessor) recordStatements(infoReader parser.StatementInfoReader, r io.Reader, fileName string) []string {
+	infos, err := infoReader.ReadStatementInfo(r)
+	if err != nil {
+		bp.logger.WithError(err).Debug("Could not read statement metadata",
+			logging.Field{Key: "file", Value: fileName})
+		return nil
+	}
+	var notes []string
+	for _, info := range infos {
+		info.Source = fileName
+		bp.statements = append(bp.statements, info)
+		if info.AdditionalInfo != "" {
+			notes = append(notes, info.AdditionalInfo)
+		}
+	}
+	return notes
+}
+
+// warnSequenceGaps logs a warning for each gap in the electronic sequence
+// numbers of the statements collected for one account.
+func (bp *BatchProcessor) warnSequenceGaps() {
+	for _, gap := range FindSequenceGaps(bp.statements) {
+		bp.logger.Warn("Statement sequence gap, statements may be missing",
+			logging.Field{Key: "account", Value: gap.AccountID},
+			logging.Field{Key: "previous_sequence", Value: gap.Previous.ElectronicSequenceNumber},
+			logging.Field{Key: "previous_file", Value: gap.Previous.Source},
+			logging.Field{Key: "next_sequence", Value: gap.Next.ElectronicSequenceNumber},
+			logging.Field{Key: "next_file", Value: gap.Next.Source},
+			logging.Field{Key: "missing", Value: gap.Missing()})
+	}
+}
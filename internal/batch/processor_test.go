@@ -139,6 +139,44 @@ func TestProcessDirectory_AllSuccess(t *testing.T) {
 	}
 }
 
+func TestProcessDirectory_NoClobber(t *testing.T) {
+	tempDir := t.TempDir()
+	inputDir := filepath.Join(tempDir, "input")
+	outputDir := filepath.Join(tempDir, "output")
+	require.NoError(t, os.MkdirAll(inputDir, 0750))
+	require.NoError(t, os.MkdirAll(outputDir, 0750))
+	for _, name := range []string{"file1.xml", "file2.xml"} {
+		require.NoError(t, os.WriteFile(filepath.Join(inputDir, name), []byte("test data"), 0600))
+	}
+	existing := filepath.Join(outputDir, "file1.csv")
+	require.NoError(t, os.WriteFile(existing, []byte("keep me\n"), 0600))
+
+	mockParser := newMockParser()
+	parsed := 0
+	mockParser.parseFunc = func(ctx context.Context, r io.Reader) ([]models.Transaction, error) {
+		parsed++
+		return createTestTransactions(2), nil
+	}
+
+	logger := logging.NewMockLogger()
+	processor := NewBatchProcessor(mockParser, logger, nil)
+	processor.SetNoClobber(true)
+	manifest, err := processor.ProcessDirectory(context.Background(), inputDir, outputDir)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, manifest.SuccessCount)
+	assert.Equal(t, 1, manifest.SkippedCount)
+	assert.Equal(t, 0, manifest.FailureCount)
+	assert.Equal(t, 0, manifest.ExitCode())
+	assert.Equal(t, 1, parsed, "skipped files are not parsed")
+	assert.True(t, logger.HasEntry("WARN", "Skipping file: output already exists"))
+
+	data, err := os.ReadFile(existing) // #nosec G304 -- test output path
+	require.NoError(t, err)
+	assert.Equal(t, "keep me\n", string(data))
+	assert.FileExists(t, filepath.Join(outputDir, "file2.csv"))
+}
+
 func TestProcessDirectory_PartialSuccess(t *testing.T) {
 	// Setup
 	tempDir := t.TempDir()
@@ -545,3 +583,41 @@ func (f *testIComptaFormatter) Format(transactions []models.Transaction) ([][]st
 func (f *testIComptaFormatter) Delimiter() rune {
 	return ';'
 }
+
+// recordingReporter counts progress calls.
+type recordingReporter struct {
+	total, increments, finishes int
+	unit                        string
+}
+
+func (r *recordingReporter) Start(total int, unit string) { r.total, r.unit = total, unit }
+func (r *recordingReporter) Increment()                   { r.increments++ }
+func (r *recordingReporter) Finish()                      { r.finishes++ }
+
+func TestProcessDirectory_ReportsProgress(t *testing.T) {
+	tempDir := t.TempDir()
+	inputDir := filepath.Join(tempDir, "input")
+	outputDir := filepath.Join(tempDir, "output")
+	require.NoError(t, os.MkdirAll(inputDir, 0750))
+	for _, name := range []string{"a.xml", "b.xml", "c.xml"} {
+		require.NoError(t, os.WriteFile(filepath.Join(inputDir, name), []byte("test data"), 0600))
+	}
+
+	mockParser := newMockParser()
+	mockParser.validateFunc = func(string) (bool, error) { return true, nil }
+	mockParser.parseFunc = func(context.Context, io.Reader) ([]models.Transaction, error) {
+		return createTestTransactions(1), nil
+	}
+
+	reporter := &recordingReporter{}
+	processor := NewBatchProcessor(mockParser, logging.NewLogrusAdapter("error", "text"), nil)
+	processor.SetProgress(reporter)
+
+	_, err := processor.ProcessDirectory(context.Background(), inputDir, outputDir)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, reporter.total)
+	assert.Equal(t, "files", reporter.unit)
+	assert.Equal(t, 3, reporter.increments)
+	assert.Equal(t, 1, reporter.finishes)
+}
@@ -0,0 +1,53 @@
+package batch
+
+import (
+	"sort"
+
+	"fjacquet/camt-csv/internal/models"
+)
+
+// SequenceGap describes statements missing between two consecutive
+// statements of one account.
+type SequenceGap struct {
+	AccountID string
+	Previous  models.StatementInfo
+	Next      models.StatementInfo
+}
+
+// Missing returns the number of statements missing between Previous and Next.
+func (g SequenceGap) Missing() int64 {
+	return g.Next.ElectronicSequenceNumber - g.Previous.ElectronicSequenceNumber - 1
+}
+
+// FindSequenceGaps orders the statements of each account by electronic
+// sequence number and reports every jump of more than one. Statements without
+// a sequence number are ignored. Gaps are sorted by account, then sequence.
+func FindSequenceGaps(statements []models.StatementInfo) []SequenceGap {
+	byAccount := make(map[string][]models.StatementInfo)
+	for _, s := range statements {
+		if s.ElectronicSequenceNumber == 0 {
+			continue
+		}
+		byAccount[s.AccountID] = append(byAccount[s.AccountID], s)
+	}
+
+	accounts := make([]string, 0, len(byAccount))
+	for account := range byAccount {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+
+	var gaps []SequenceGap
+	for _, account := range accounts {
+		list := byAccount[account]
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].ElectronicSequenceNumber < list[j].ElectronicSequenceNumber
+		})
+		for i := 1; i < len(list); i++ {
+			if list[i].ElectronicSequenceNumber > list[i-1].ElectronicSequenceNumber+1 {
+				gaps = append(gaps, SequenceGap{AccountID: account, Previous: list[i-1], Next: list[i]})
+			}
+		}
+	}
+	return gaps
+}
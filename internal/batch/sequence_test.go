@@ -0,0 +1,163 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parsererror"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindSequenceGaps(t *testing.T) {
+	statements := []models.StatementInfo{
+		{AccountID: "CH01", ElectronicSequenceNumber: 1},
+		{AccountID: "CH01", ElectronicSequenceNumber: 4},
+		{AccountID: "CH01", ElectronicSequenceNumber: 2},
+		{AccountID: "CH02", ElectronicSequenceNumber: 7},
+		{AccountID: "CH02", ElectronicSequenceNumber: 8},
+		{AccountID: "CH02"}, // no sequence number
+	}
+
+	gaps := FindSequenceGaps(statements)
+
+	require.Len(t, gaps, 1)
+	assert.Equal(t, "CH01", gaps[0].AccountID)
+	assert.Equal(t, int64(2), gaps[0].Previous.ElectronicSequenceNumber)
+	assert.Equal(t, int64(4), gaps[0].Next.ElectronicSequenceNumber)
+	assert.Equal(t, int64(1), gaps[0].Missing())
+
+	assert.Empty(t, FindSequenceGaps(nil))
+}
+
+// sequenceParser is a mock parser whose files hold "<account>:<sequence>".
+type sequenceParser struct {
+	*mockFullParser
+}
+
+func (p *sequenceParser) ReadStatementInfo(r io.Reader) ([]models.StatementInfo, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var info models.StatementInfo
+	if _, err := fmt.Sscanf(strings.Replace(string(data), ":", " ", 1), "%s %d", &info.AccountID, &info.ElectronicSequenceNumber); err != nil {
+		return nil, err
+	}
+	return []models.StatementInfo{info}, nil
+}
+
+func TestProcessDirectory_WarnsOnSequenceGap(t *testing.T) {
+	tempDir := t.TempDir()
+	inputDir := filepath.Join(tempDir, "input")
+	require.NoError(t, os.MkdirAll(inputDir, 0750))
+	files := map[string]string{"jan.xml": "CH01:1", "feb.xml": "CH01:2", "apr.xml": "CH01:4"}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(inputDir, name), []byte(content), 0600))
+	}
+
+	var parsed []string
+	p := &sequenceParser{mockFullParser: newMockParser()}
+	p.parseFunc = func(ctx context.Context, r io.Reader) ([]models.Transaction, error) {
+		// The parser still sees the whole input after the metadata read
+		data, err := io.ReadAll(r)
+		parsed = append(parsed, string(data))
+		return createTestTransactions(1), err
+	}
+
+	logger := logging.NewMockLogger()
+	manifest, err := NewBatchProcessor(p, logger, nil).ProcessDirectory(context.Background(), inputDir, filepath.Join(tempDir, "output"))
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, manifest.SuccessCount)
+	assert.ElementsMatch(t, []string{"CH01:1", "CH01:2", "CH01:4"}, parsed)
+
+	warnings := logger.GetEntriesByLevel("WARN")
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "Statement sequence gap, statements may be missing", warnings[0].Message)
+}
+
+func TestProcessDirectory_NoWarningWithoutGap(t *testing.T) {
+	tempDir := t.TempDir()
+	inputDir := filepath.Join(tempDir, "input")
+	require.NoError(t, os.MkdirAll(inputDir, 0750))
+	for name, content := range map[string]string{"a.xml": "CH01:1", "b.xml": "CH01:2", "c.xml": "CH02:9"} {
+		require.NoError(t, os.WriteFile(filepath.Join(inputDir, name), []byte(content), 0600))
+	}
+
+	logger := logging.NewMockLogger()
+	_, err := NewBatchProcessor(&sequenceParser{mockFullParser: newMockParser()}, logger, nil).
+		ProcessDirectory(context.Background(), inputDir, filepath.Join(tempDir, "output"))
+
+	require.NoError(t, err)
+	assert.False(t, logger.HasEntry("WARN", "Statement sequence gap, statements may be missing"))
+}
+
+// noteParser is a mock parser whose files hold their statement note.
+type noteParser struct {
+	*mockFullParser
+}
+
+func (p *noteParser) ReadStatementInfo(r io.Reader) ([]models.StatementInfo, error) {
+	data, err := io.ReadAll(r)
+	return []models.StatementInfo{{AccountID: "CH01", AdditionalInfo: string(data)}}, err
+}
+
+func TestProcessDirectory_RecordsStatementNotes(t *testing.T) {
+	tempDir := t.TempDir()
+	inputDir := filepath.Join(tempDir, "input")
+	require.NoError(t, os.MkdirAll(inputDir, 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(inputDir, "corrected.xml"), []byte("Replaces statement 7"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(inputDir, "plain.xml"), nil, 0600))
+
+	manifest, err := NewBatchProcessor(&noteParser{mockFullParser: newMockParser()}, logging.NewMockLogger(), nil).
+		ProcessDirectory(context.Background(), inputDir, filepath.Join(tempDir, "output"))
+	require.NoError(t, err)
+
+	notes := make(map[string][]string)
+	for _, result := range manifest.Results {
+		notes[result.FileName] = result.StatementNotes
+	}
+	assert.Equal(t, []string{"Replaces statement 7"}, notes["corrected.xml"])
+	assert.Empty(t, notes["plain.xml"])
+}
+
+// limitedParser is a noteParser whose Parse reads one byte and stops at the
+// transaction limit.
+type limitedParser struct {
+	noteParser
+	infoRead bool
+}
+
+func (p *limitedParser) Parse(ctx context.Context, r io.Reader) ([]models.Transaction, error) {
+	_, err := r.Read(make([]byte, 1))
+	if err != nil {
+		return nil, err
+	}
+	return nil, parsererror.ErrTooManyTransactions
+}
+
+func (p *limitedParser) ReadStatementInfo(r io.Reader) ([]models.StatementInfo, error) {
+	p.infoRead = true
+	return p.noteParser.ReadStatementInfo(r)
+}
+
+func TestParse_StopsReadingAtTransactionLimit(t *testing.T) {
+	p := &limitedParser{noteParser: noteParser{mockFullParser: newMockParser()}}
+	input := strings.NewReader(strings.Repeat("x", 1<<20))
+
+	_, notes, err := NewBatchProcessor(p, logging.NewMockLogger(), nil).parse(context.Background(), input, "huge.xml")
+
+	require.ErrorIs(t, err, parsererror.ErrTooManyTransactions)
+	assert.Empty(t, notes)
+	assert.False(t, p.infoRead, "statement metadata is not read past the limit")
+	assert.Equal(t, 1<<20-1, input.Len(), "only the bytes the parser needed are read")
+}
@@ -0,0 +1,71 @@
+package batch
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// walkFiles returns the sorted files of inputDir and all its subdirectories,
+// skipping hidden files and directories like discoverFiles does. Symbolic
+// links to directories are not followed, and symbolic links to files are only
+// kept when they resolve inside inputDir. It returns an error when two files
+// would be converted to the same output name, since output is written flat.
+// outputDir is skipped when it lies within inputDir.
+func walkFiles(inputDir, outputDir string) ([]string, error) {
+	root, err := filepath.EvalSymlinks(inputDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve input directory: %w", err)
+	}
+
+	var files []string
+	err = filepath.WalkDir(inputDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != inputDir && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			if path != inputDir && filepath.Clean(path) == filepath.Clean(outputDir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.Type()&fs.ModeSymlink != 0 && !linksToFileInside(path, root) {
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk input directory: %w", err)
+	}
+	sort.Strings(files)
+
+	if err := checkOutputNames(files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// linksToFileInside reports whether the symbolic link at path resolves to a
+// regular file within root, which must itself be resolved.
+func linksToFileInside(path, root string) bool {
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(root, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	info, err := os.Stat(target)
+	return err == nil && info.Mode().IsRegular()
+}
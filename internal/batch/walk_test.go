@@ -0,0 +1,100 @@
+package batch
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+}
+
+func TestProcessDirectory_Recursive(t *testing.T) {
+	tempDir := t.TempDir()
+	inputDir := filepath.Join(tempDir, "input")
+	outputDir := filepath.Join(inputDir, "output")
+	writeTree(t, inputDir, map[string]string{
+		"2024/12/statement-2024-12.xml": "match",
+		"2025/01/statement-2025-01.xml": "match",
+		"2025/statement-2025.xml":       "match",
+		"top.xml":                       "match",
+		"2025/.hidden.xml":              "hidden",
+		".archive/old.xml":              "hidden directory",
+		"output/previous.xml":           "output directory",
+	})
+
+	mockParser := newMockParser()
+	mockParser.parseFunc = func(ctx context.Context, r io.Reader) ([]models.Transaction, error) {
+		return createTestTransactions(1), nil
+	}
+	processor := NewBatchProcessor(mockParser, logging.NewMockLogger(), nil)
+	processor.SetRecursive(true)
+
+	manifest, err := processor.ProcessDirectory(context.Background(), inputDir, outputDir)
+	require.NoError(t, err)
+	assert.Equal(t, 4, manifest.TotalFiles)
+	assert.Equal(t, 4, manifest.SuccessCount)
+	for _, name := range []string{"statement-2024-12.csv", "statement-2025-01.csv", "statement-2025.csv", "top.csv"} {
+		assert.FileExists(t, filepath.Join(outputDir, name))
+	}
+}
+
+func TestProcessDirectory_NotRecursiveByDefault(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTree(t, tempDir, map[string]string{
+		"top.xml":     "match",
+		"2025/01.xml": "nested",
+	})
+
+	processor := NewBatchProcessor(newMockParser(), logging.NewMockLogger(), nil)
+	manifest, err := processor.ProcessDirectory(context.Background(), tempDir, filepath.Join(t.TempDir(), "out"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, manifest.TotalFiles)
+}
+
+func TestWalkFiles_DuplicateOutputNames(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTree(t, tempDir, map[string]string{
+		"2025/01/statement.xml": "january",
+		"2025/02/statement.xml": "february",
+	})
+
+	_, err := walkFiles(tempDir, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "statement.csv")
+}
+
+func TestWalkFiles_Symlinks(t *testing.T) {
+	tempDir := t.TempDir()
+	inputDir := filepath.Join(tempDir, "input")
+	outsideDir := filepath.Join(tempDir, "outside")
+	writeTree(t, tempDir, map[string]string{
+		"input/2025/real.xml":     "inside",
+		"outside/secret.xml":      "outside",
+		"outside/nested/more.xml": "outside",
+	})
+	require.NoError(t, os.Symlink(filepath.Join(inputDir, "2025", "real.xml"), filepath.Join(inputDir, "alias.xml")))
+	require.NoError(t, os.Symlink(filepath.Join(outsideDir, "secret.xml"), filepath.Join(inputDir, "secret.xml")))
+	require.NoError(t, os.Symlink(filepath.Join(outsideDir, "nested"), filepath.Join(inputDir, "nested")))
+
+	files, err := walkFiles(inputDir, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(inputDir, "2025", "real.xml"),
+		filepath.Join(inputDir, "alias.xml"),
+	}, files)
+}
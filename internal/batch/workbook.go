@@ -0,0 +1,160 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"fjacquet/camt-csv/internal/common"
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parser"
+)
+
+// ProcessWorkbook converts the files selected by inputDir, like
+// ProcessDirectory does, into a single XLSX workbook at outputFile with one
+// sheet per account. Files are grouped with the BatchAggregator, and each
+// sheet holds the chronologically sorted transactions of one account, named
+// after it. ZIP archives are not expanded in this mode and are reported as
+// failures. The manifest is written next to outputFile.
+func (bp *BatchProcessor) ProcessWorkbook(ctx context.Context, inputDir, outputFile string) (*BatchManifest, error) {
+	startTime := time.Now()
+	outputDir := filepath.Dir(outputFile)
+
+	files, err := bp.inputFiles(inputDir, outputDir)
+	if err != nil {
+		return nil, err
+	}
+	if bp.noClobber {
+		if err := common.CheckNoClobber(outputFile); err != nil {
+			return nil, err
+		}
+	}
+
+	bp.logger.Info("Starting workbook batch processing",
+		logging.Field{Key: "input_dir", Value: inputDir},
+		logging.Field{Key: "output_file", Value: outputFile},
+		logging.Field{Key: "files_found", Value: len(files)})
+
+	manifest := &BatchManifest{
+		TotalFiles:  len(files),
+		Results:     make([]BatchResult, 0, len(files)),
+		ProcessedAt: time.Now(),
+	}
+
+	aggregator := NewBatchAggregator(bp.logger)
+	aggregator.SetStrict(bp.strict)
+	groups, err := aggregator.GroupFilesByAccount(files)
+	if err != nil {
+		return nil, err
+	}
+
+	bp.statements = nil
+	bp.progress.Start(len(files), "files")
+
+	var sheets []common.WorkbookSheet
+	for _, group := range groups {
+		if err := ctx.Err(); err != nil {
+			bp.progress.Finish()
+			manifest.Duration = time.Since(startTime)
+			return manifest, err
+		}
+
+		transactions, err := aggregator.AggregateTransactions(group, func(file string) ([]models.Transaction, error) {
+			defer bp.progress.Increment()
+			return bp.parseFile(ctx, file)
+		})
+		failed := make(map[string]error)
+		var aggErr *AggregationError
+		if errors.As(err, &aggErr) {
+			for _, f := range aggErr.Failed {
+				failed[f.File] = f.Err
+			}
+		}
+		// In strict mode an account mixing currencies fails as a whole
+		var mixedErr *MixedCurrencyError
+		if errors.As(err, &mixedErr) {
+			for _, file := range group.Files {
+				failed[file] = mixedErr
+			}
+		}
+
+		for _, file := range group.Files {
+			result := BatchResult{FilePath: file, FileName: filepath.Base(file)}
+			if fileErr, ok := failed[file]; ok {
+				result.Error = fileErr.Error()
+				manifest.FailureCount++
+			} else {
+				result.Success = true
+				manifest.SuccessCount++
+			}
+			manifest.Results = append(manifest.Results, result)
+		}
+
+		if len(transactions) == 0 {
+			continue
+		}
+		parser.RecordCategorization(ctx, transactions)
+		sheets = append(sheets, common.WorkbookSheet{Name: group.AccountID, Transactions: transactions})
+	}
+	bp.progress.Finish()
+
+	bp.warnSequenceGaps()
+
+	if len(sheets) > 0 {
+		if err := common.WriteTransactionsToXLSX(sheets, outputFile, bp.logger, bp.formatter); err != nil {
+			return nil, err
+		}
+	} else {
+		bp.logger.Warn("No transactions found, skipping workbook",
+			logging.Field{Key: "file", Value: outputFile})
+	}
+
+	manifest.Duration = time.Since(startTime)
+	bp.logger.Info("Workbook batch processing completed",
+		logging.Field{Key: "total_files", Value: manifest.TotalFiles},
+		logging.Field{Key: "success", Value: manifest.SuccessCount},
+		logging.Field{Key: "failed", Value: manifest.FailureCount},
+		logging.Field{Key: "sheets", Value: len(sheets)},
+		logging.Field{Key: "duration", Value: manifest.Duration.String()})
+
+	manifestPath := filepath.Join(outputDir, ".manifest.json")
+	if err := manifest.WriteManifest(manifestPath); err != nil {
+		bp.logger.WithError(err).Warn("Failed to write manifest file",
+			logging.Field{Key: "path", Value: manifestPath})
+	}
+
+	return manifest, nil
+}
+
+// parseFile validates and parses one input file, applying the transaction
+// limit and filters of ctx, and records its statement metadata.
+func (bp *BatchProcessor) parseFile(ctx context.Context, filePath string) ([]models.Transaction, error) {
+	if IsZipArchive(filePath) {
+		return nil, errors.New("zip archives are not supported in a single workbook")
+	}
+
+	isValid, err := bp.parser.ValidateFormat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("validation_error: %w", err)
+	}
+	if !isValid {
+		return nil, errors.New("validation_failed")
+	}
+
+	file, err := os.Open(filePath) // #nosec G304 -- CLI tool requires user-provided file paths
+	if err != nil {
+		return nil, fmt.Errorf("read_error: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	transactions, _, err := bp.parse(ctx, file, filepath.Base(filePath))
+	if err != nil {
+		return nil, err
+	}
+	parser.WarnEmptyTransactions(ctx, transactions, filepath.Base(filePath), bp.logger)
+	return parser.FilterTransactions(ctx, transactions), nil
+}
@@ -0,0 +1,96 @@
+package batch
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessWorkbook_SheetPerAccount(t *testing.T) {
+	inputDir := t.TempDir()
+	statement := func(iban string) string {
+		return `<Document><BkToCstmrStmt><Stmt><Acct><Id><IBAN>` + iban + `</IBAN></Id></Acct></Stmt></BkToCstmrStmt></Document>`
+	}
+	writeTree(t, inputDir, map[string]string{
+		"CAMT.053_54293249_2025-04-01_2025-04-30_1.xml": "april",
+		"CAMT.053_54293249_2025-05-01_2025-05-31_1.xml": "may",
+		"savings.xml": statement("CH5604835012345678009"),
+		"broken.xml":  "broken",
+	})
+
+	mockParser := newMockParser()
+	mockParser.parseFunc = func(ctx context.Context, r io.Reader) ([]models.Transaction, error) {
+		data, _ := io.ReadAll(r)
+		if string(data) == "broken" {
+			return nil, assert.AnError
+		}
+		return createTestTransactions(2), nil
+	}
+	processor := NewBatchProcessor(mockParser, logging.NewMockLogger(), nil)
+
+	outputFile := filepath.Join(t.TempDir(), "out", "statements.xlsx")
+	require.NoError(t, os.MkdirAll(filepath.Dir(outputFile), 0750))
+	manifest, err := processor.ProcessWorkbook(context.Background(), inputDir, outputFile)
+	require.NoError(t, err)
+	assert.Equal(t, 4, manifest.TotalFiles)
+	assert.Equal(t, 3, manifest.SuccessCount)
+	assert.Equal(t, 1, manifest.FailureCount)
+	assert.Equal(t, 1, manifest.ExitCode())
+
+	archive, err := zip.OpenReader(outputFile)
+	require.NoError(t, err)
+	defer func() { _ = archive.Close() }()
+	var workbook string
+	var sheetRows []int
+	for _, f := range archive.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		_ = r.Close()
+		switch {
+		case f.Name == "xl/workbook.xml":
+			workbook = string(data)
+		case strings.HasPrefix(f.Name, "xl/worksheets/"):
+			sheetRows = append(sheetRows, strings.Count(string(data), "<row "))
+		}
+	}
+	// The broken file has its own pseudo-account and no transactions
+	assert.Contains(t, workbook, `<sheet name="54293249" sheetId="1" r:id="rId1"/>`)
+	assert.Contains(t, workbook, `<sheet name="CH5604835012345678009" sheetId="2" r:id="rId2"/>`)
+	assert.NotContains(t, workbook, "broken")
+	assert.Equal(t, []int{5, 3}, sheetRows)
+
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(outputFile), ".manifest.json"))
+	require.NoError(t, err)
+	var written BatchManifest
+	require.NoError(t, json.Unmarshal(data, &written))
+	assert.Len(t, written.Results, 4)
+}
+
+func TestProcessWorkbook_NoClobber(t *testing.T) {
+	inputDir := t.TempDir()
+	writeTree(t, inputDir, map[string]string{"statement.xml": "content"})
+	outputFile := filepath.Join(t.TempDir(), "statements.xlsx")
+	require.NoError(t, os.WriteFile(outputFile, []byte("previous"), 0600))
+
+	processor := NewBatchProcessor(newMockParser(), logging.NewMockLogger(), nil)
+	processor.SetNoClobber(true)
+	_, err := processor.ProcessWorkbook(context.Background(), inputDir, outputFile)
+	require.Error(t, err)
+
+	data, err := os.ReadFile(outputFile)
+	require.NoError(t, err)
+	assert.Equal(t, "previous", string(data))
+}
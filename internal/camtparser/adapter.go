@@ -1,22 +1,24 @@
 package camtparser
 
 import (
-	"bytes"
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"fjacquet/camt-csv/internal/common"
 	"fjacquet/camt-csv/internal/dateutils"
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
 	"fjacquet/camt-csv/internal/parser"
+	"fjacquet/camt-csv/internal/parsererror"
+	"fjacquet/camt-csv/internal/progress"
 
+	"github.com/shopspring/decimal"
 	"golang.org/x/net/html/charset"
 )
 
@@ -25,6 +27,12 @@ type Adapter struct {
 	parser.BaseParser
 }
 
+func init() {
+	parser.RegisterParser("camt", func(logger logging.Logger) parser.FullParser {
+		return NewAdapter(logger)
+	})
+}
+
 // NewAdapter creates a new adapter for the camtparser.
 func NewAdapter(logger logging.Logger) *Adapter {
 	return &Adapter{
@@ -40,19 +48,12 @@ func NewAdapter(logger logging.Logger) *Adapter {
 
 func (a *Adapter) Parse(ctx context.Context, r io.Reader) ([]models.Transaction, error) {
 
-	// Read the XML content
-
-	xmlData, err := io.ReadAll(r)
-
-	if err != nil {
-
-		return nil, fmt.Errorf("error reading from reader: %w", err)
-
-	}
+	// The XML is streamed rather than read whole, so that a file over
+	// --max-transactions is rejected without being loaded
 
-	// Create decoder with proper namespace handling
+	input := &readErrorRecorder{r: r}
 
-	decoder := xml.NewDecoder(bytes.NewReader(xmlData))
+	decoder := xml.NewDecoder(input)
 
 	decoder.CharsetReader = charset.NewReaderLabel
 
@@ -64,10 +65,6 @@ func (a *Adapter) Parse(ctx context.Context, r io.Reader) ([]models.Transaction,
 		Currency string `xml:"Ccy,attr"`
 	}
 
-	type Date struct {
-		Date string `xml:"Dt"`
-	}
-
 	type AdditionalInfo struct {
 		Info string `xml:",chardata"`
 	}
@@ -76,10 +73,6 @@ func (a *Adapter) Parse(ctx context.Context, r io.Reader) ([]models.Transaction,
 		Indicator string `xml:",chardata"`
 	}
 
-	type Status struct {
-		Status string `xml:",chardata"`
-	}
-
 	type AccountServicerRef struct {
 		Ref string `xml:",chardata"`
 	}
@@ -97,7 +90,8 @@ func (a *Adapter) Parse(ctx context.Context, r io.Reader) ([]models.Transaction,
 	}
 
 	type RemittanceInfo struct {
-		Ustrd string `xml:"Ustrd"`
+		Ustrd        []string                `xml:"Ustrd"`
+		CreditorRefs []creditorReferenceInfo `xml:"Strd>CdtrRefInf"`
 	}
 
 	type Account struct {
@@ -110,18 +104,45 @@ func (a *Adapter) Parse(ctx context.Context, r io.Reader) ([]models.Transaction,
 		Debtor struct {
 			Name string `xml:"Nm"`
 
+			Party models.PartyName `xml:"Pty"`
+
 			Account Account `xml:"Acct,omitempty"`
 		} `xml:"Dbtr"`
 
 		Creditor struct {
 			Name string `xml:"Nm"`
 
+			Party models.PartyName `xml:"Pty"`
+
 			Account Account `xml:"Acct,omitempty"`
 		} `xml:"Cdtr"`
 
 		DebtorAccount Account `xml:"DbtrAcct,omitempty"`
 
 		CreditorAccount Account `xml:"CdtrAcct,omitempty"`
+
+		UltimateDebtor struct {
+			Name string `xml:"Nm"`
+		} `xml:"UltmtDbtr"`
+
+		UltimateCreditor struct {
+			Name string `xml:"Nm"`
+		} `xml:"UltmtCdtr"`
+	}
+
+	type Agent struct {
+		Name string `xml:"FinInstnId>Nm"`
+
+		// BIC up to camt.053.001.04, BICFI from 001.08
+		BIC string `xml:"FinInstnId>BIC"`
+
+		BICFI string `xml:"FinInstnId>BICFI"`
+	}
+
+	type RelatedAgents struct {
+		DebtorAgent Agent `xml:"DbtrAgt"`
+
+		CreditorAgent Agent `xml:"CdtrAgt"`
 	}
 
 	type RelatedAccounts struct {
@@ -142,6 +163,16 @@ func (a *Adapter) Parse(ctx context.Context, r io.Reader) ([]models.Transaction,
 		RelatedParties RelatedParties `xml:"RltdPties"`
 
 		RelatedAccounts RelatedAccounts `xml:"RltdAccts,omitempty"`
+
+		RelatedAgents RelatedAgents `xml:"RltdAgts"`
+
+		AmountDetails amountDetails `xml:"AmtDtls"`
+
+		Charges chargesInfo `xml:"Chrgs"`
+
+		AdditionalTxInfo string `xml:"AddtlTxInf"`
+
+		BankTxCode models.BankTxCode `xml:"BkTxCd"`
 	}
 
 	type EntryDetails struct {
@@ -153,79 +184,200 @@ func (a *Adapter) Parse(ctx context.Context, r io.Reader) ([]models.Transaction,
 
 		CreditDebit CreditDebitIndicator `xml:"CdtDbtInd"`
 
-		Status Status `xml:"Sts"`
+		Reversal bool `xml:"RvslInd"`
 
-		BookingDate Date `xml:"BookgDt"`
+		Status models.EntryStatus `xml:"Sts"`
 
-		ValueDate Date `xml:"ValDt"`
+		BookingDate models.EntryDate `xml:"BookgDt"`
+
+		ValueDate models.EntryDate `xml:"ValDt"`
 
 		AccountServicer AccountServicerRef `xml:"AcctSvcrRef"`
 
+		BankTxCode models.BankTxCode `xml:"BkTxCd"`
+
 		EntryDetails EntryDetails `xml:"NtryDtls"`
 
+		Charges chargesInfo `xml:"Chrgs"`
+
 		AdditionalInfo AdditionalInfo `xml:"AddtlNtryInf"`
 	}
 
 	type Statement struct {
-		Entries []Entry `xml:"Ntry"`
-	}
+		Account Account `xml:"Acct"`
 
-	type Document struct {
-		XMLName xml.Name `xml:"Document"`
+		Entries []Entry `xml:"Ntry"`
 
-		BkToCstmrStmt struct {
-			Stmt []Statement `xml:"Stmt"`
-		} `xml:"BkToCstmrStmt"`
+		AdditionalInfo string `xml:"AddtlStmtInf"`
 	}
 
-	// Unmarshal the XML
-
-	var doc Document
+	// decodeStatement decodes the children of a Stmt element one at a time.
+	// Entries are counted as they are read: decoding stops with an error
+	// once --max-transactions is exceeded, and entries past --limit are
+	// skipped. Entries of statements excluded by --account are skipped
+	// without being decoded or counted.
+	entryCount := 0
+	decodeStatement := func() (Statement, error) {
+		var stmt Statement
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				return stmt, err
+			}
+			switch t := token.(type) {
+			case xml.EndElement:
+				return stmt, nil
+			case xml.StartElement:
+				switch t.Name.Local {
+				case "Acct":
+					err = decoder.DecodeElement(&stmt.Account, &t)
+				case "AddtlStmtInf":
+					err = decoder.DecodeElement(&stmt.AdditionalInfo, &t)
+				case "Ntry":
+					if !parser.AccountSelected(ctx, stmt.Account.IBAN, stmt.Account.ID) || parser.LimitReached(ctx, entryCount) {
+						err = decoder.Skip()
+						break
+					}
+					entryCount++
+					if err := parser.CheckTransactionLimit(ctx, entryCount); err != nil {
+						return stmt, err
+					}
+					var entry Entry
+					if err = decoder.DecodeElement(&entry, &t); err == nil {
+						stmt.Entries = append(stmt.Entries, entry)
+					}
+				default:
+					err = decoder.Skip()
+				}
+				if err != nil {
+					return stmt, err
+				}
+			}
+		}
+	}
 
-	err = decoder.Decode(&doc)
+	// Walk the document down to its BkToCstmrStmt>Stmt elements
 
-	if err != nil {
+	var statements []Statement
 
-		return nil, fmt.Errorf("error decoding XML: %w", err)
+	var path []string
 
+	for done := false; !done; {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, decodeError(input, err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if len(path) == 0 && t.Name.Local != "Document" {
+				return nil, fmt.Errorf("%w: error decoding XML: expected element type <Document> but have <%s>",
+					parsererror.ErrInvalidFormat, t.Name.Local)
+			}
+			if len(path) == 2 && path[1] == "BkToCstmrStmt" && t.Name.Local == "Stmt" {
+				stmt, err := decodeStatement()
+				if err != nil {
+					if errors.Is(err, parsererror.ErrTooManyTransactions) || ctx.Err() != nil {
+						return nil, err
+					}
+					return nil, decodeError(input, err)
+				}
+				statements = append(statements, stmt)
+				continue
+			}
+			path = append(path, t.Name.Local)
+		case xml.EndElement:
+			path = path[:len(path)-1]
+			done = len(path) == 0
+		}
 	}
 
 	var transactions []models.Transaction
 
 	// Process all statements and entries
 
-	for _, stmt := range doc.BkToCstmrStmt.Stmt {
-
-		for _, entry := range stmt.Entries {
+	bar := progress.FromContext(ctx)
+	bar.Start(len(statements), "statements")
 
-			// Convert dates to standard format
+	// With --account, statements of the file's other accounts are skipped
+	accountMatched := false
+	var accounts []string
 
-			bookingDate := entry.BookingDate.Date
+statements:
+	for _, stmt := range statements {
+		// The account holder's IBAN lives at statement level and applies to every entry
+		accountIBAN := firstNonEmpty(stmt.Account.IBAN, ibanFromID(stmt.Account.ID))
 
-			valueDate := entry.ValueDate.Date
+		if !parser.AccountSelected(ctx, stmt.Account.IBAN, stmt.Account.ID) {
+			account := firstNonEmpty(stmt.Account.IBAN, stmt.Account.ID)
+			accounts = append(accounts, account)
+			a.GetLogger().Debug("Skipping statement of another account",
+				logging.Field{Key: "account", Value: account})
+			bar.Increment()
+			continue
+		}
+		accountMatched = true
+
+		// Statement notes are informational: written above the CSV header, not in the rows
+		statementNote := strings.Join(strings.Fields(stmt.AdditionalInfo), " ")
+		if statementNote != "" {
+			a.GetLogger().Info("Statement carries additional information",
+				logging.Field{Key: "account", Value: accountIBAN},
+				logging.Field{Key: "note", Value: statementNote})
+		}
 
-			// Format the dates as DD.MM.YYYY
+		for _, entry := range stmt.Entries {
+			// With --limit the remaining entries would not be written, so
+			// they are neither parsed nor categorized
+			if parser.LimitReached(ctx, len(transactions)) {
+				break statements
+			}
+			if err := parser.CheckTransactionLimit(ctx, len(transactions)+1); err != nil {
+				bar.Finish()
+				return nil, err
+			}
 
-			var parsedBookingDate, parsedValueDate time.Time
+			// Dates come as Dt, or as DtTm in camt.053.001.08 and later;
+			// a missing booking or value date is taken from the other one
+			parsedBookingDate, parsedValueDate, fallback := models.EntryDates(entry.BookingDate, entry.ValueDate)
+			if fallback != "" {
+				a.GetLogger().Debug("Entry date missing, using the other date",
+					logging.Field{Key: "missing", Value: fallback + "_date"},
+					logging.Field{Key: "account_servicer_ref", Value: entry.AccountServicer.Ref})
+			}
 
-			if bookingDateParsed, err := time.Parse(dateutils.DateLayoutISO, bookingDate); err == nil {
-				parsedBookingDate = bookingDateParsed
+			// An unparsable amount is reported here rather than passed on as a
+			// zero that --skip-zero would silently drop
+			amount, err := models.ParseAmountChecked(entry.Amount.Value)
+			if err != nil {
+				a.GetLogger().WithError(err).Warn("Invalid entry amount, using zero",
+					logging.Field{Key: "account_servicer_ref", Value: entry.AccountServicer.Ref})
 			}
 
-			if valueDateParsed, err := time.Parse(dateutils.DateLayoutISO, valueDate); err == nil {
-				parsedValueDate = valueDateParsed
+			// Some banks leave out CdtDbtInd and give debits a negative
+			// amount; the direction is then taken from the sign
+			if entry.CreditDebit.Indicator == "" {
+				entry.CreditDebit.Indicator = models.TransactionTypeCredit
+				if amount.IsNegative() {
+					entry.CreditDebit.Indicator = models.TransactionTypeDebit
+				}
+				a.GetLogger().Debug("Entry has no CdtDbtInd, direction taken from the amount sign",
+					logging.Field{Key: "account_servicer_ref", Value: entry.AccountServicer.Ref},
+					logging.Field{Key: "credit_debit", Value: entry.CreditDebit.Indicator})
 			}
+			amount = amount.Abs()
 
 			// Create transaction using TransactionBuilder
 			builder := models.NewTransactionBuilder().
 				WithID(""). // Don't generate UUID, keep empty like original
 				WithDatetime(parsedBookingDate).
 				WithValueDatetime(parsedValueDate).
-				WithAmount(models.ParseAmount(entry.Amount.Value), entry.Amount.Currency).
+				WithAmount(amount, entry.Amount.Currency).
 				WithAccountServicer(entry.AccountServicer.Ref).
-				WithStatus(entry.Status.Status)
+				WithStatus(entry.Status.String()).
+				WithIBAN(accountIBAN)
 
-			// Set transaction direction
+			// Set transaction direction; a reversal is booked in the direction
+			// that undoes the entry it reverses, so CdtDbtInd is kept as is
 			if entry.CreditDebit.Indicator == models.TransactionTypeDebit {
 				builder = builder.AsDebit()
 			} else {
@@ -235,14 +387,22 @@ func (a *Adapter) Parse(ctx context.Context, r io.Reader) ([]models.Transaction,
 			// Add details from transaction details if available
 			txDetails := entry.EntryDetails.TransactionDetails
 
+			// camt.053.001.08 nests party names in Pty; fold them into Name
+			parties := &txDetails.RelatedParties
+			parties.Debtor.Name = firstNonEmpty(parties.Debtor.Name, parties.Debtor.Party.Nm)
+			parties.Creditor.Name = firstNonEmpty(parties.Creditor.Name, parties.Creditor.Party.Nm)
+
+			// Ustrd may repeat; long references are split across several lines
+			remittanceInfo := joinRemittanceLines(txDetails.RemittanceInfo.Ustrd)
+
 			// Set description from AddtlNtryInf or RemittanceInfo
 			description := ""
 
 			if entry.AdditionalInfo.Info != "" {
 				description = entry.AdditionalInfo.Info
-			} else if txDetails.RemittanceInfo.Ustrd != "" {
+			} else if remittanceInfo != "" {
 				// Use RemittanceInfo as Description if there's no AddtlNtryInf
-				description = txDetails.RemittanceInfo.Ustrd
+				description = remittanceInfo
 			}
 
 			if description != "" {
@@ -250,8 +410,8 @@ func (a *Adapter) Parse(ctx context.Context, r io.Reader) ([]models.Transaction,
 			}
 
 			// Always set RemittanceInfo from the XML field if present
-			if txDetails.RemittanceInfo.Ustrd != "" {
-				builder = builder.WithRemittanceInfo(txDetails.RemittanceInfo.Ustrd)
+			if remittanceInfo != "" {
+				builder = builder.WithRemittanceInfo(remittanceInfo)
 			}
 
 			// Handle party name extraction and special cases
@@ -274,9 +434,21 @@ func (a *Adapter) Parse(ctx context.Context, r io.Reader) ([]models.Transaction,
 					}
 				}
 
-				// If PartyName is still empty, try to get debtor name from related parties if available
-				if partyName == "" && len(txDetails.RelatedParties.Debtor.Name) > 0 {
-					partyName = txDetails.RelatedParties.Debtor.Name
+				// If PartyName is still empty, take the counterparty from related parties
+				// with the same precedence as models.Entry.GetPayee/GetPayer: a debit's
+				// counterparty is the creditor (payee), a credit's is the debtor (payer)
+				if partyName == "" {
+					if entry.CreditDebit.Indicator == models.TransactionTypeDebit {
+						partyName = firstNonEmpty(
+							txDetails.RelatedParties.UltimateCreditor.Name,
+							txDetails.RelatedParties.Creditor.Name,
+							txDetails.RelatedAgents.CreditorAgent.Name)
+					} else {
+						partyName = firstNonEmpty(
+							txDetails.RelatedParties.UltimateDebtor.Name,
+							txDetails.RelatedParties.Debtor.Name,
+							txDetails.RelatedAgents.DebtorAgent.Name)
+					}
 				}
 			}
 
@@ -288,34 +460,47 @@ func (a *Adapter) Parse(ctx context.Context, r io.Reader) ([]models.Transaction,
 				builder = builder.WithType(transactionType)
 			}
 
-			// Check for IBAN in related parties and accounts
-			// Try multiple possible paths for finding the IBAN
-			var partyIBAN string
-			if txDetails.RelatedParties.Debtor.Account.IBAN != "" {
-				partyIBAN = txDetails.RelatedParties.Debtor.Account.IBAN
-			} else if txDetails.RelatedParties.Creditor.Account.IBAN != "" {
-				partyIBAN = txDetails.RelatedParties.Creditor.Account.IBAN
-			} else if txDetails.RelatedParties.DebtorAccount.IBAN != "" {
-				partyIBAN = txDetails.RelatedParties.DebtorAccount.IBAN
-			} else if txDetails.RelatedParties.CreditorAccount.IBAN != "" {
-				partyIBAN = txDetails.RelatedParties.CreditorAccount.IBAN
-			} else if txDetails.RelatedAccounts.DebtorAccount.IBAN != "" {
-				partyIBAN = txDetails.RelatedAccounts.DebtorAccount.IBAN
-			} else if txDetails.RelatedAccounts.CreditorAccount.IBAN != "" {
-				partyIBAN = txDetails.RelatedAccounts.CreditorAccount.IBAN
-			} else if txDetails.RelatedParties.Debtor.Account.ID != "" && isIBANFormat(txDetails.RelatedParties.Debtor.Account.ID) {
+			// Look for the counterparty IBAN in related parties and accounts: a debit's
+			// counterparty is the creditor, a credit's the debtor. The other side is
+			// usually the account holder, so the statement IBAN is never taken.
+			debtorIBANs := []string{
+				txDetails.RelatedParties.Debtor.Account.IBAN,
+				txDetails.RelatedParties.DebtorAccount.IBAN,
+				txDetails.RelatedAccounts.DebtorAccount.IBAN,
 				// Some CAMT files store IBAN in the ID field
-				partyIBAN = txDetails.RelatedParties.Debtor.Account.ID
-			} else if txDetails.RelatedParties.Creditor.Account.ID != "" && isIBANFormat(txDetails.RelatedParties.Creditor.Account.ID) {
-				partyIBAN = txDetails.RelatedParties.Creditor.Account.ID
+				ibanFromID(txDetails.RelatedParties.Debtor.Account.ID),
+			}
+			creditorIBANs := []string{
+				txDetails.RelatedParties.Creditor.Account.IBAN,
+				txDetails.RelatedParties.CreditorAccount.IBAN,
+				txDetails.RelatedAccounts.CreditorAccount.IBAN,
+				ibanFromID(txDetails.RelatedParties.Creditor.Account.ID),
+			}
+			var partyIBAN string
+			if entry.CreditDebit.Indicator == models.TransactionTypeDebit {
+				partyIBAN = firstIBANExcept(accountIBAN, append(creditorIBANs, debtorIBANs...)...)
+			} else {
+				partyIBAN = firstIBANExcept(accountIBAN, append(debtorIBANs, creditorIBANs...)...)
 			}
 
 			if partyIBAN != "" {
 				builder = builder.WithPartyIBAN(partyIBAN)
 			}
 
+			// The counterparty's bank: the creditor agent of a debit, the
+			// debtor agent of a credit
+			var partyBIC string
+			if entry.CreditDebit.Indicator == models.TransactionTypeDebit {
+				partyBIC = firstNonEmpty(txDetails.RelatedAgents.CreditorAgent.BICFI, txDetails.RelatedAgents.CreditorAgent.BIC)
+			} else {
+				partyBIC = firstNonEmpty(txDetails.RelatedAgents.DebtorAgent.BICFI, txDetails.RelatedAgents.DebtorAgent.BIC)
+			}
+
 			// Set transaction Type based on description prefix if not already set
-			if transactionType == "" {
+			if entry.Reversal {
+				transactionType = models.TypeReversal
+				builder = builder.WithType(transactionType)
+			} else if transactionType == "" {
 				transactionType = setTransactionTypeFromDescription(description)
 				if transactionType != "" {
 					builder = builder.WithType(transactionType)
@@ -347,18 +532,44 @@ func (a *Adapter) Parse(ctx context.Context, r io.Reader) ([]models.Transaction,
 
 				fallback, _ := models.NewTransactionBuilder().
 					WithDatetime(parsedBookingDate).
-					WithAmount(models.ParseAmount(entry.Amount.Value), entry.Amount.Currency).
+					WithAmount(amount, entry.Amount.Currency).
 					WithDescription("Failed to parse transaction").
 					Build()
 				transaction = fallback
 			}
 
+			transaction.Reversal = entry.Reversal
+			// ISO 20022 has no element for the merchant category code; banks
+			// that report it print it in the additional information
+			transaction.MCC, _ = models.ExtractMCC(txDetails.AdditionalTxInfo + " " + entry.AdditionalInfo.Info)
+			// The entry's code applies to all its transactions; a batch
+			// booking may also give each transaction its own
+			transaction.BankTxCode = firstNonEmpty(txDetails.BankTxCode.Code(), entry.BankTxCode.Code())
+			transaction.BankTxCodeDescription = models.BankTxCodeDescription(transaction.BankTxCode)
+			transaction.PartyBIC = strings.TrimSpace(partyBIC)
+			transaction.CreditorReference = a.creditorReference(txDetails.RemittanceInfo.CreditorRefs)
+			transaction.StructuredReference, transaction.ReferenceType =
+				structuredReference(txDetails.RemittanceInfo.CreditorRefs)
+
+			// Charges are usually reported on the transaction details, some
+			// banks only put them on the entry
+			charges := txDetails.Charges
+			if charges.empty() {
+				charges = entry.Charges
+			}
+			transaction.Fees = a.charges(charges, entry.Amount.Currency)
+
+			// FX payments carry the instructed amount and the exchange rate
+			transaction.OriginalAmount, transaction.OriginalCurrency, transaction.ExchangeRate =
+				a.foreignExchange(txDetails.AmountDetails, entry.Amount.Currency)
+
 			// Set Name from PartyName and also update Payee/Payer fields to ensure
 			// that UpdateNameFromParties won't override our Name during export
 			if transaction.Name == "" {
 				transaction.Name = transaction.PartyName
 			}
 
+			// The counterparty of a debit is the payee, that of a credit the payer
 			if transaction.IsDebit() {
 				transaction.Payee = transaction.PartyName
 			} else {
@@ -368,6 +579,9 @@ func (a *Adapter) Parse(ctx context.Context, r io.Reader) ([]models.Transaction,
 			// Update derived fields
 			transaction.UpdateDebitCreditAmounts()
 
+			// Transfers with the user's own accounts are recognized by IBAN
+			internalTransfer := models.ApplyOwnAccounts(&transaction, a.GetCategorizer())
+
 			// Prepare categorization parameters
 			catPartyName := transaction.PartyName
 			isDebtor := transaction.CreditDebit == models.TransactionTypeDebit
@@ -375,8 +589,15 @@ func (a *Adapter) Parse(ctx context.Context, r io.Reader) ([]models.Transaction,
 			catDate := transaction.Date.Format(dateutils.DateLayoutEuropean)
 			catInfo := transaction.RemittanceInfo
 
-			// If PartyName is empty, use Description or RemittanceInfo to help with categorization
-			if catPartyName == "" {
+			// If PartyName is empty, categorize by the counterparty's BIC so that
+			// mappings can be keyed on it, keeping the description as context;
+			// without a BIC, use Description or RemittanceInfo
+			if catPartyName == "" && transaction.PartyBIC != "" {
+				catPartyName = transaction.PartyBIC
+				if catInfo == "" {
+					catInfo = transaction.Description
+				}
+			} else if catPartyName == "" {
 				// Try to use Description as PartyName if available
 				if transaction.Description != "" {
 					catPartyName = transaction.Description
@@ -386,19 +607,52 @@ func (a *Adapter) Parse(ctx context.Context, r io.Reader) ([]models.Transaction,
 				}
 			}
 
-			// Clean PartyName by removing payment method prefixes before categorization
-			catPartyName = cleanPaymentMethodPrefixes(catPartyName)
+			// The additional transaction info often names the merchant, so keyword
+			// rules get to match it too
+			if additionalInfo := strings.TrimSpace(txDetails.AdditionalTxInfo); additionalInfo != "" {
+				catInfo = strings.TrimSpace(catInfo + " " + additionalInfo)
+			}
+
+			// Clean the party names with the cleanup rules before categorization and output
+			cat := a.GetCategorizer()
+			models.CleanPartyName(&transaction, cat)
+			catPartyName = models.CleanName(catPartyName, cat)
 
 			// Categorize the transaction using the injected categorizer (includes auto-learning)
-			if cat := a.GetCategorizer(); cat != nil {
-				category, err := cat.Categorize(context.Background(), catPartyName, isDebtor, catAmount, catDate, catInfo)
+			if cat != nil {
+				models.ApplyTags(&transaction, cat)
+			}
+			if models.ApplyCategoryOverride(&transaction, cat) {
+				a.GetLogger().WithFields(
+					logging.Field{Key: "party", Value: transaction.PartyName},
+					logging.Field{Key: "category", Value: transaction.Category},
+				).Debug("Transaction categorized by category override")
+			} else if internalTransfer {
+				a.GetLogger().WithFields(
+					logging.Field{Key: "party_iban", Value: transaction.PartyIBAN},
+					logging.Field{Key: "category", Value: transaction.Category},
+				).Debug("Transfer between own accounts")
+			} else if models.ApplyIBANMapping(&transaction, cat) {
+				a.GetLogger().WithFields(
+					logging.Field{Key: "party_iban", Value: transaction.PartyIBAN},
+					logging.Field{Key: "category", Value: transaction.Category},
+				).Debug("Transaction categorized by counterparty IBAN")
+			} else if models.ApplyMCC(&transaction, cat) {
+				a.GetLogger().WithFields(
+					logging.Field{Key: "mcc", Value: transaction.MCC},
+					logging.Field{Key: "category", Value: transaction.Category},
+				).Debug("Transaction categorized by merchant category code")
+			} else if cat != nil {
+				category, err := cat.Categorize(ctx, catPartyName, isDebtor, catAmount, catDate, catInfo)
 				if err != nil {
 					a.GetLogger().WithError(err).WithFields(
 						logging.Field{Key: "party", Value: catPartyName},
 					).Warn("Failed to categorize transaction")
 					transaction.Category = models.CategoryUncategorized
+					transaction.CategorySource = models.CategorySourceFallback
 				} else {
 					transaction.Category = category.Name
+					transaction.CategorySource = category.Source
 					a.GetLogger().WithFields(
 						logging.Field{Key: "party", Value: catPartyName},
 						logging.Field{Key: "category", Value: category.Name},
@@ -406,12 +660,25 @@ func (a *Adapter) Parse(ctx context.Context, r io.Reader) ([]models.Transaction,
 				}
 			} else {
 				transaction.Category = models.CategoryUncategorized
+				transaction.CategorySource = models.CategorySourceFallback
 			}
 
+			// Position of the entry in the file, so sorting can keep the bank's order
+			transaction.SequenceNumber = len(transactions) + 1
+			transaction.StatementNote = statementNote
+
 			transactions = append(transactions, transaction)
 
 		}
 
+		bar.Increment()
+	}
+
+	bar.Finish()
+
+	if account := parser.Account(ctx); account != "" && !accountMatched {
+		return nil, fmt.Errorf("%w: %q (statements in the file are for %s)",
+			parsererror.ErrAccountNotFound, account, strings.Join(accounts, ", "))
 	}
 
 	return transactions, nil
@@ -428,7 +695,7 @@ func (a *Adapter) ConvertToCSV(ctx context.Context, xmlFile, csvFile string) err
 
 	if err != nil {
 
-		return fmt.Errorf("error opening XML file: %w", err)
+		return fmt.Errorf("%w: error opening XML file: %w", parsererror.ErrReadFailed, err)
 
 	}
 
@@ -491,6 +758,11 @@ func (a *Adapter) ValidateFormat(xmlFile string) (bool, error) {
 	return parser.ValidateFormat(xmlFile)
 }
 
+// ReadStatementInfo implements parser.StatementInfoReader.
+func (a *Adapter) ReadStatementInfo(r io.Reader) ([]models.StatementInfo, error) {
+	return NewISO20022Parser(a.GetLogger()).ReadStatementInfo(r)
+}
+
 // BatchConvert converts all XML files in a directory to CSV files.
 
 func (a *Adapter) BatchConvert(ctx context.Context, inputDir, outputDir string) (int, error) {
@@ -632,52 +904,193 @@ func isIBANFormat(s string) bool {
 
 }
 
-// Helper function to clean payment method prefixes from party names
-
-func cleanPaymentMethodPrefixes(partyName string) string {
-
-	// If the party name consists only of one of these terms, leave it as is
-
-	// This ensures "PMT CARTE", "PMT TWINT", and "BCV-NET" are still categorized correctly
-
-	if partyName == "PMT CARTE" || partyName == "PMT TWINT" || partyName == "BCV-NET" || partyName == "VIRT BANC" {
-
-		return partyName
+// firstNonEmpty returns the first non-empty value, or "" if all are empty
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
 
+// ibanFromID returns id if it looks like an IBAN, for files that store the
+// IBAN in Id>Othr>Id instead of Id>IBAN.
+func ibanFromID(id string) string {
+	if id != "" && isIBANFormat(id) {
+		return id
 	}
+	return ""
+}
 
-	// Remove these prefixes if they're part of a longer string
+// firstIBANExcept returns the first non-empty candidate that is not exclude,
+// ignoring spaces and case when comparing.
+func firstIBANExcept(exclude string, candidates ...string) string {
+	normalize := func(iban string) string {
+		return strings.ToUpper(strings.ReplaceAll(iban, " ", ""))
+	}
+	for _, c := range candidates {
+		if c != "" && (exclude == "" || normalize(c) != normalize(exclude)) {
+			return c
+		}
+	}
+	return ""
+}
 
-	prefixes := []string{"PMT CARTE", "PMT TWINT", "BCV-NET", "VIRT BANC"}
+// creditorReferenceInfo is a structured creditor reference (RmtInf/Strd/CdtrRefInf).
+type creditorReferenceInfo struct {
+	Code        string `xml:"Tp>CdOrPrtry>Cd"`
+	Proprietary string `xml:"Tp>CdOrPrtry>Prtry"`
+	Ref         string `xml:"Ref"`
+}
 
-	cleanedName := partyName
+// creditorReference returns the first ISO 11649 (RF) creditor reference among
+// infos: one typed SCOR, or an untyped one starting with "RF". References with
+// wrong check digits are logged and skipped.
+func (a *Adapter) creditorReference(infos []creditorReferenceInfo) string {
+	for _, info := range infos {
+		ref := models.NormalizeCreditorReference(info.Ref)
+		untyped := info.Code == "" && info.Proprietary == ""
+		if !strings.HasPrefix(ref, "RF") || !(strings.EqualFold(info.Code, "SCOR") || untyped) {
+			continue
+		}
+		if !models.IsValidCreditorReference(ref) {
+			a.GetLogger().Warn("Ignoring creditor reference with invalid check digits",
+				logging.Field{Key: "reference", Value: info.Ref})
+			continue
+		}
+		return ref
+	}
+	return ""
+}
 
-	for _, prefix := range prefixes {
+// structuredReference returns the first structured creditor reference among
+// infos, without spaces, and its type: the code or proprietary type (QRR,
+// SCOR, NON) upper-cased, or SCOR for an untyped valid RF reference. Unlike
+// creditorReference, QR references and unchecked references are kept.
+func structuredReference(infos []creditorReferenceInfo) (string, string) {
+	for _, info := range infos {
+		ref := models.NormalizeCreditorReference(info.Ref)
+		refType := strings.ToUpper(strings.TrimSpace(info.Code))
+		if refType == "" {
+			refType = strings.ToUpper(strings.TrimSpace(info.Proprietary))
+		}
+		if ref == "" && refType == "" {
+			continue
+		}
+		if refType == "" && models.IsValidCreditorReference(ref) {
+			refType = "SCOR"
+		}
+		return ref, refType
+	}
+	return "", ""
+}
 
-		// Check if the party name starts with the prefix
+// chargeAmount is an amount with its currency attribute.
+type chargeAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
 
-		if strings.HasPrefix(cleanedName, prefix) {
+// chargeRecord is one charge (Chrgs/Rcrd). A CRDT record refunds a charge.
+type chargeRecord struct {
+	Amount      chargeAmount `xml:"Amt"`
+	CreditDebit string       `xml:"CdtDbtInd"`
+}
 
-			// Extract the remaining part after the prefix and trim spaces
+// chargesInfo is a Chrgs block: a list of records from camt.053.001.04, a
+// repeated Chrgs/Amt in camt.053.001.02.
+type chargesInfo struct {
+	Amounts []chargeAmount `xml:"Amt"`
+	Records []chargeRecord `xml:"Rcrd"`
+}
 
-			cleanedName = strings.TrimSpace(cleanedName[len(prefix):])
+// empty reports whether the block lists no charge.
+func (c chargesInfo) empty() bool {
+	return len(c.Amounts) == 0 && len(c.Records) == 0
+}
 
-			break // Only need to remove one prefix
+// charges returns the total of the charges in info. Charges in another
+// currency than the entry's cannot be added to its fees and are logged and
+// skipped.
+func (a *Adapter) charges(info chargesInfo, currency string) decimal.Decimal {
+	records := info.Records
+	for _, amount := range info.Amounts {
+		records = append(records, chargeRecord{Amount: amount})
+	}
 
+	total := decimal.Zero
+	for _, record := range records {
+		if record.Amount.Currency != "" && currency != "" && record.Amount.Currency != currency {
+			a.GetLogger().Warn("Ignoring charge in a different currency than the entry",
+				logging.Field{Key: "charge_currency", Value: record.Amount.Currency},
+				logging.Field{Key: "entry_currency", Value: currency})
+			continue
 		}
-
+		amount := models.ParseAmount(record.Amount.Value).Abs()
+		if record.CreditDebit == models.TransactionTypeCredit {
+			amount = amount.Neg()
+		}
+		total = total.Add(amount)
 	}
+	return total
+}
 
-	// If we removed everything, return the original name
+// amountAndExchange is an amount of AmtDtls with its optional currency
+// exchange (CcyXchg).
+type amountAndExchange struct {
+	Amount   chargeAmount `xml:"Amt"`
+	Exchange struct {
+		Rate string `xml:"XchgRate"`
+	} `xml:"CcyXchg"`
+}
 
-	if cleanedName == "" {
+// amountDetails is an AmtDtls block: the amount instructed by the ordering
+// party, the amount transferred and its counter value, each possibly with the
+// exchange rate applied.
+type amountDetails struct {
+	Instructed   amountAndExchange `xml:"InstdAmt"`
+	Transaction  amountAndExchange `xml:"TxAmt"`
+	CounterValue amountAndExchange `xml:"CntrValAmt"`
+}
 
-		return partyName
+// foreignExchange returns the instructed amount and currency of an FX
+// payment, along with the exchange rate as the bank reports it, or zeros when
+// the payment was instructed in the entry's currency. The rate is taken from
+// the first of InstdAmt, TxAmt and CntrValAmt that carries one.
+func (a *Adapter) foreignExchange(details amountDetails, currency string) (decimal.Decimal, string, decimal.Decimal) {
+	instructed := details.Instructed.Amount
+	if instructed.Value == "" || instructed.Currency == "" || strings.EqualFold(instructed.Currency, currency) {
+		return decimal.Zero, "", decimal.Zero
+	}
 
+	amount, err := models.ParseAmountChecked(instructed.Value)
+	if err != nil {
+		a.GetLogger().WithError(err).Warn("Invalid instructed amount, ignoring it",
+			logging.Field{Key: "currency", Value: instructed.Currency})
+		return decimal.Zero, "", decimal.Zero
 	}
 
-	return cleanedName
+	rate := decimal.Zero
+	for _, exchange := range []amountAndExchange{details.Instructed, details.Transaction, details.CounterValue} {
+		if exchange.Exchange.Rate != "" {
+			rate = models.ParseAmount(exchange.Exchange.Rate)
+			break
+		}
+	}
+	return amount.Abs(), strings.ToUpper(instructed.Currency), rate
+}
 
+// joinRemittanceLines joins repeated Ustrd lines with the same separator as
+// models.Entry.GetRemittanceInfo, skipping blank lines
+func joinRemittanceLines(lines []string) string {
+	parts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			parts = append(parts, line)
+		}
+	}
+	return strings.Join(parts, ", ")
 }
 
 // extractPartyNameFromDescription extracts party name from description based on prefixes
@@ -737,3 +1150,28 @@ func setTransactionTypeFromDescription(description string) string {
 	return ""
 
 }
+
+// readErrorRecorder remembers the error of the underlying reader, so that a
+// failed read is reported as such rather than as malformed XML.
+type readErrorRecorder struct {
+	r   io.Reader
+	err error
+}
+
+func (r *readErrorRecorder) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
+
+// decodeError wraps an error of the streaming XML decoder in
+// parsererror.ErrReadFailed when reading the input failed, and in
+// parsererror.ErrInvalidFormat otherwise.
+func decodeError(input *readErrorRecorder, err error) error {
+	if input.err != nil {
+		return fmt.Errorf("%w: error reading from reader: %w", parsererror.ErrReadFailed, input.err)
+	}
+	return fmt.Errorf("%w: error decoding XML: %w", parsererror.ErrInvalidFormat, err)
+}
@@ -4,11 +4,15 @@ package camtparser
 import (
 	"encoding/xml"
 	"fmt"
+	"io"
 	"os"
 
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
 	"fjacquet/camt-csv/internal/parser"
+	"fjacquet/camt-csv/internal/parsererror"
+
+	"golang.org/x/net/html/charset"
 )
 
 // ISO20022Parser is a parser implementation for CAMT.053 files using ISO20022 standard definitions
@@ -31,7 +35,7 @@ func (p *ISO20022Parser) ValidateFormat(filePath string) (bool, error) {
 	// Try to open and read the file
 	xmlFile, err := os.Open(filePath) // #nosec G304 -- CLI tool requires user-provided file paths
 	if err != nil {
-		return false, fmt.Errorf("error opening file: %w", err)
+		return false, fmt.Errorf("%w: error opening file: %w", parsererror.ErrReadFailed, err)
 	}
 	defer func() {
 		if err := xmlFile.Close(); err != nil {
@@ -43,14 +47,14 @@ func (p *ISO20022Parser) ValidateFormat(filePath string) (bool, error) {
 	// Read the file content
 	xmlBytes, err := os.ReadFile(filePath) // #nosec G304 -- CLI tool requires user-provided file paths
 	if err != nil {
-		return false, fmt.Errorf("error reading file: %w", err)
+		return false, fmt.Errorf("%w: error reading file: %w", parsererror.ErrReadFailed, err)
 	}
 
 	// Check if file is empty
 	if len(xmlBytes) == 0 {
 		p.GetLogger().Info("File is empty",
 			logging.Field{Key: "file", Value: filePath})
-		return false, fmt.Errorf("file is empty")
+		return false, fmt.Errorf("%w: file is empty", parsererror.ErrNoTransactions)
 	}
 
 	// Try to unmarshal the XML data into our ISO20022 document structure
@@ -58,17 +62,41 @@ func (p *ISO20022Parser) ValidateFormat(filePath string) (bool, error) {
 	if err := xml.Unmarshal(xmlBytes, &document); err != nil {
 		p.GetLogger().Info("File is not a valid CAMT.053 XML",
 			logging.Field{Key: "file", Value: filePath})
-		return false, fmt.Errorf("invalid XML format: %w", err)
+		return false, fmt.Errorf("%w: invalid XML format: %w", parsererror.ErrInvalidFormat, err)
 	}
 
 	// Check if we have at least one statement
 	if len(document.BkToCstmrStmt.Stmt) == 0 {
 		p.GetLogger().Info("File is not a valid CAMT.053 XML (no statements)",
 			logging.Field{Key: "file", Value: filePath})
-		return false, fmt.Errorf("no statements found in CAMT.053 file")
+		return false, fmt.Errorf("%w: no statements found in CAMT.053 file", parsererror.ErrNoTransactions)
 	}
 
 	p.GetLogger().Info("File is a valid CAMT.053 XML",
 		logging.Field{Key: "file", Value: filePath})
 	return true, nil
 }
+
+// ReadStatementInfo decodes a CAMT.053 document from r and returns the
+// metadata of each statement, including its electronic and legal sequence
+// numbers.
+func (p *ISO20022Parser) ReadStatementInfo(r io.Reader) ([]models.StatementInfo, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	var document models.ISO20022Document
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("%w: error decoding XML: %w", parsererror.ErrInvalidFormat, err)
+	}
+
+	infos := make([]models.StatementInfo, 0, len(document.BkToCstmrStmt.Stmt))
+	for i := range document.BkToCstmrStmt.Stmt {
+		info := document.BkToCstmrStmt.Stmt[i].Info()
+		p.GetLogger().Debug("Read statement sequence numbers",
+			logging.Field{Key: "statement", Value: info.ID},
+			logging.Field{Key: "electronic_sequence", Value: info.ElectronicSequenceNumber},
+			logging.Field{Key: "legal_sequence", Value: info.LegalSequenceNumber})
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
@@ -167,32 +167,6 @@ func TestIsIBANFormat(t *testing.T) {
 	}
 }
 
-func TestCleanPaymentMethodPrefixes(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		expected string
-	}{
-		{"PMT CARTE only", "PMT CARTE", "PMT CARTE"},
-		{"PMT CARTE with merchant", "PMT CARTE Starbucks", "Starbucks"},
-		{"PMT TWINT only", "PMT TWINT", "PMT TWINT"},
-		{"PMT TWINT with merchant", "PMT TWINT Coffee Shop", "Coffee Shop"},
-		{"BCV-NET only", "BCV-NET", "BCV-NET"},
-		{"BCV-NET with description", "BCV-NET Transfer", "Transfer"},
-		{"VIRT BANC only", "VIRT BANC", "VIRT BANC"},
-		{"VIRT BANC with description", "VIRT BANC Payment", "Payment"},
-		{"no prefix", "Regular Payment", "Regular Payment"},
-		{"empty", "", ""},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := cleanPaymentMethodPrefixes(tt.input)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
 func TestExtractPartyNameFromDescription(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -1,17 +1,26 @@
 package camtparser
 
 import (
+	"bytes"
 	"context"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parser"
+	"fjacquet/camt-csv/internal/parsererror"
+	"fjacquet/camt-csv/internal/progress"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -69,9 +78,9 @@ const testXMLContent = `<?xml version="1.0" encoding="UTF-8"?>
 							<EndToEndId>BK123</EndToEndId>
 						</Refs>
 						<RltdPties>
-							<Dbtr>
+							<Cdtr>
 								<Nm>Test Payee</Nm>
-							</Dbtr>
+							</Cdtr>
 						</RltdPties>
 						<RmtInf>
 							<Ustrd>Test Transaction</Ustrd>
@@ -182,8 +191,9 @@ func TestConvertToCSV(t *testing.T) {
 	csvContent, err := os.ReadFile(csvFile)
 	assert.NoError(t, err)
 
-	// Expected CSV content (comma-separated) - updated to 29-column format per Phase 10
-	expectedCSV := "Status,Date,ValueDate,Name,PartyName,PartyIBAN,Description,RemittanceInfo,Amount,CreditDebit,Currency,Product,AmountExclTax,TaxRate,InvestmentType,Number,Category,Type,Fund,NumberOfShares,Fees,IBAN,EntryReference,Reference,AccountServicer,BankTxCode,OriginalCurrency,OriginalAmount,ExchangeRate\n,01.01.2023,02.01.2023,Test Payee,Test Payee,,Test Transaction,Test Transaction,-100.00,DBIT,EUR,,0.00,0.00,,,Uncategorized,,,0,0.00,,,BK123,,,,0.00,0.00\n"
+	// Expected CSV content (comma-separated) - updated to 29-column format per Phase 10.
+	// BankTxCode holds the entry's BkTxCd (see CHANGELOG: it used to be empty)
+	expectedCSV := "Status,Date,ValueDate,Name,PartyName,PartyIBAN,Description,RemittanceInfo,Amount,CreditDebit,Currency,Product,AmountExclTax,TaxRate,InvestmentType,Number,Category,Type,Fund,NumberOfShares,Fees,IBAN,EntryReference,Reference,AccountServicer,BankTxCode,OriginalCurrency,OriginalAmount,ExchangeRate\n,01.01.2023,02.01.2023,Test Payee,Test Payee,,Test Transaction,Test Transaction,-100.00,DBIT,EUR,,0.00,0.00,,,Uncategorized,,,0,0.00,CH9300762011623852957,,BK123,,PMNT,,0.00,0.00\n"
 
 	assert.Equal(t, expectedCSV, string(csvContent))
 }
@@ -251,6 +261,7 @@ func TestCAMTParser_ErrorScenarios(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, transactions)
 		assert.Contains(t, err.Error(), "error decoding XML")
+		assert.ErrorIs(t, err, parsererror.ErrInvalidFormat)
 	})
 
 	t.Run("empty XML document", func(t *testing.T) {
@@ -607,3 +618,785 @@ func TestCAMTParser_ErrorMessagesIncludeFilePath(t *testing.T) {
 		}
 	})
 }
+
+// partyCrossCheckXML has entries without AddtlNtryInf, so the counterparty comes
+// from related parties/agents only.
+const partyCrossCheckXML = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.04">
+  <BkToCstmrStmt><Stmt>
+    <Ntry>
+      <Amt Ccy="CHF">50.00</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts>BOOK</Sts>
+      <BookgDt><Dt>2025-01-10</Dt></BookgDt><ValDt><Dt>2025-01-10</Dt></ValDt>
+      <NtryDtls><TxDtls><RltdPties>
+        <Dbtr><Nm>Account Holder</Nm></Dbtr>
+        <Cdtr><Nm>Electricity Company</Nm></Cdtr>
+      </RltdPties></TxDtls></NtryDtls>
+    </Ntry>
+    <Ntry>
+      <Amt Ccy="CHF">3000.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Sts>BOOK</Sts>
+      <BookgDt><Dt>2025-01-25</Dt></BookgDt><ValDt><Dt>2025-01-25</Dt></ValDt>
+      <NtryDtls><TxDtls><RltdPties>
+        <Dbtr><Nm>Employer SA</Nm></Dbtr>
+        <Cdtr><Nm>Account Holder</Nm></Cdtr>
+      </RltdPties></TxDtls></NtryDtls>
+    </Ntry>
+    <Ntry>
+      <Amt Ccy="CHF">20.00</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts>BOOK</Sts>
+      <BookgDt><Dt>2025-01-12</Dt></BookgDt><ValDt><Dt>2025-01-12</Dt></ValDt>
+      <NtryDtls><TxDtls><RltdPties>
+        <Dbtr><Nm>Account Holder</Nm></Dbtr>
+        <Cdtr><Nm>Payment Provider</Nm></Cdtr>
+        <UltmtCdtr><Nm>Online Shop</Nm></UltmtCdtr>
+      </RltdPties></TxDtls></NtryDtls>
+    </Ntry>
+    <Ntry>
+      <Amt Ccy="CHF">5.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Sts>BOOK</Sts>
+      <BookgDt><Dt>2025-01-31</Dt></BookgDt><ValDt><Dt>2025-01-31</Dt></ValDt>
+      <NtryDtls><TxDtls><RltdAgts>
+        <DbtrAgt><FinInstnId><Nm>Other Bank</Nm></FinInstnId></DbtrAgt>
+      </RltdAgts></TxDtls></NtryDtls>
+    </Ntry>
+  </Stmt></BkToCstmrStmt>
+</Document>`
+
+// TestAdapter_PartyMatchesISO20022Entry cross-checks the adapter's counterparty
+// against models.Entry.GetPayee/GetPayer for the same input.
+func TestAdapter_PartyMatchesISO20022Entry(t *testing.T) {
+	adapter := NewAdapter(logging.NewLogrusAdapter("error", "text"))
+	transactions, err := adapter.Parse(context.Background(), strings.NewReader(partyCrossCheckXML))
+	require.NoError(t, err)
+
+	var doc models.ISO20022Document
+	require.NoError(t, xml.Unmarshal([]byte(partyCrossCheckXML), &doc))
+	entries := doc.BkToCstmrStmt.Stmt[0].Ntry
+	require.Len(t, transactions, len(entries))
+
+	expected := []string{"Electricity Company", "Employer SA", "Online Shop", "Other Bank"}
+	for i, entry := range entries {
+		counterparty := entry.GetPayer()
+		if entry.CdtDbtInd == models.TransactionTypeDebit {
+			counterparty = entry.GetPayee()
+		}
+
+		assert.Equal(t, expected[i], counterparty)
+		assert.Equal(t, counterparty, transactions[i].PartyName, "entry %d PartyName", i)
+		assert.Equal(t, counterparty, transactions[i].Name, "entry %d Name", i)
+		assert.Equal(t, entry.GetPayee(), transactions[i].Payee, "entry %d Payee", i)
+		assert.Equal(t, entry.GetPayer(), transactions[i].Payer, "entry %d Payer", i)
+	}
+}
+
+// multiLineRemittanceXML has a payment reference split across two Ustrd lines.
+const multiLineRemittanceXML = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.04">
+  <BkToCstmrStmt><Stmt>
+    <Ntry>
+      <Amt Ccy="CHF">1250.00</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts>BOOK</Sts>
+      <BookgDt><Dt>2025-02-03</Dt></BookgDt><ValDt><Dt>2025-02-03</Dt></ValDt>
+      <NtryDtls><TxDtls>
+        <RltdPties><Cdtr><Nm>Regie Immobiliere SA</Nm></Cdtr></RltdPties>
+        <RmtInf>
+          <Ustrd>Loyer fevrier 2025 appartement 3.5 pieces</Ustrd>
+          <Ustrd>Ref 2025-02-LOY-000417 Rue du Lac 12</Ustrd>
+        </RmtInf>
+      </TxDtls></NtryDtls>
+    </Ntry>
+  </Stmt></BkToCstmrStmt>
+</Document>`
+
+func TestAdapter_MultiLineRemittanceInfo(t *testing.T) {
+	adapter := NewAdapter(logging.NewLogrusAdapter("error", "text"))
+	transactions, err := adapter.Parse(context.Background(), strings.NewReader(multiLineRemittanceXML))
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+
+	var doc models.ISO20022Document
+	require.NoError(t, xml.Unmarshal([]byte(multiLineRemittanceXML), &doc))
+	expected := doc.BkToCstmrStmt.Stmt[0].Ntry[0].GetRemittanceInfo()
+
+	assert.Equal(t, "Loyer fevrier 2025 appartement 3.5 pieces, Ref 2025-02-LOY-000417 Rue du Lac 12", expected)
+	assert.Equal(t, expected, transactions[0].RemittanceInfo)
+	assert.Equal(t, expected, transactions[0].Description)
+}
+
+func TestJoinRemittanceLines(t *testing.T) {
+	assert.Equal(t, "", joinRemittanceLines(nil))
+	assert.Equal(t, "single", joinRemittanceLines([]string{"single"}))
+	assert.Equal(t, "a, b", joinRemittanceLines([]string{" a ", "", "b"}))
+}
+
+// accountIBANXML has the holder's IBAN at statement level, and counterparty IBANs
+// on both sides of each entry's related parties.
+const accountIBANXML = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.04">
+  <BkToCstmrStmt><Stmt>
+    <Acct><Id><IBAN>CH9300762011623852957</IBAN></Id></Acct>
+    <Ntry>
+      <Amt Ccy="CHF">80.00</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts>BOOK</Sts>
+      <BookgDt><Dt>2025-03-03</Dt></BookgDt><ValDt><Dt>2025-03-03</Dt></ValDt>
+      <NtryDtls><TxDtls><RltdPties>
+        <Dbtr><Nm>Account Holder</Nm></Dbtr>
+        <DbtrAcct><Id><IBAN>CH9300762011623852957</IBAN></Id></DbtrAcct>
+        <Cdtr><Nm>Telecom AG</Nm></Cdtr>
+        <CdtrAcct><Id><IBAN>CH5604835012345678009</IBAN></Id></CdtrAcct>
+      </RltdPties></TxDtls></NtryDtls>
+    </Ntry>
+    <Ntry>
+      <Amt Ccy="CHF">3000.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Sts>BOOK</Sts>
+      <BookgDt><Dt>2025-03-25</Dt></BookgDt><ValDt><Dt>2025-03-25</Dt></ValDt>
+      <NtryDtls><TxDtls><RltdPties>
+        <Dbtr><Nm>Employer SA</Nm></Dbtr>
+        <DbtrAcct><Id><IBAN>CH2100700110000387896</IBAN></Id></DbtrAcct>
+        <Cdtr><Nm>Account Holder</Nm></Cdtr>
+        <CdtrAcct><Id><IBAN>CH9300762011623852957</IBAN></Id></CdtrAcct>
+      </RltdPties></TxDtls></NtryDtls>
+    </Ntry>
+  </Stmt></BkToCstmrStmt>
+</Document>`
+
+func TestAdapter_AccountIBANDistinctFromPartyIBAN(t *testing.T) {
+	tempDir := t.TempDir()
+	xmlFile := filepath.Join(tempDir, "statement.xml")
+	csvFile := filepath.Join(tempDir, "statement.csv")
+	require.NoError(t, os.WriteFile(xmlFile, []byte(accountIBANXML), 0600))
+
+	adapter := NewAdapter(logging.NewLogrusAdapter("error", "text"))
+	transactions, err := adapter.Parse(context.Background(), strings.NewReader(accountIBANXML))
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+
+	const accountIBAN = "CH9300762011623852957"
+	expectedPartyIBANs := []string{"CH5604835012345678009", "CH2100700110000387896"}
+	for i, tx := range transactions {
+		assert.Equal(t, accountIBAN, tx.IBAN, "entry %d IBAN", i)
+		assert.Equal(t, expectedPartyIBANs[i], tx.PartyIBAN, "entry %d PartyIBAN", i)
+	}
+
+	require.NoError(t, adapter.ConvertToCSV(context.Background(), xmlFile, csvFile))
+	content, err := os.ReadFile(csvFile)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 3)
+
+	header := strings.Split(lines[0], ",")
+	col := make(map[string]int, len(header))
+	for i, h := range header {
+		col[h] = i
+	}
+	for i, line := range lines[1:] {
+		row := strings.Split(line, ",")
+		assert.Equal(t, accountIBAN, row[col["IBAN"]], "row %d IBAN", i)
+		assert.Equal(t, expectedPartyIBANs[i], row[col["PartyIBAN"]], "row %d PartyIBAN", i)
+	}
+}
+
+func TestFirstIBANExcept(t *testing.T) {
+	assert.Equal(t, "CH56", firstIBANExcept("", "", "CH56"))
+	assert.Equal(t, "CH21", firstIBANExcept("CH93 0076", "ch930076", "CH21"))
+	assert.Equal(t, "", firstIBANExcept("CH93", "CH93"))
+}
+
+// countingReporter counts progress increments.
+type countingReporter struct {
+	total, increments int
+}
+
+func (r *countingReporter) Start(total int, _ string) { r.total = total }
+func (r *countingReporter) Increment()                { r.increments++ }
+func (r *countingReporter) Finish()                   {}
+
+func TestAdapter_ReportsProgressPerStatement(t *testing.T) {
+	twoStatements := strings.Replace(multiLineRemittanceXML, "</Stmt></BkToCstmrStmt>",
+		"</Stmt><Stmt></Stmt></BkToCstmrStmt>", 1)
+
+	reporter := &countingReporter{}
+	ctx := progress.WithReporter(context.Background(), reporter)
+	_, err := NewAdapter(logging.NewLogrusAdapter("error", "text")).Parse(ctx, strings.NewReader(twoStatements))
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, reporter.total)
+	assert.Equal(t, 2, reporter.increments)
+}
+
+func TestAdapter_ParsesCamt053V08(t *testing.T) {
+	f, err := os.Open("testdata/camt053_v08.xml")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	txs, err := NewAdapter(logging.NewLogrusAdapter("error", "text")).Parse(context.Background(), f)
+	require.NoError(t, err)
+	require.Len(t, txs, 3)
+
+	// DtTm booking date keeps its time of day and offset
+	booked := txs[0]
+	assert.True(t, time.Date(2025, 1, 15, 10, 30, 0, 0, time.FixedZone("", 3600)).Equal(booked.Date), "got %v", booked.Date)
+	assert.Equal(t, "BOOK", booked.Status)
+	assert.Equal(t, "Migros Lausanne", booked.PartyName)
+	assert.Equal(t, models.TransactionTypeDebit, booked.CreditDebit)
+	assert.Equal(t, "PMNT/CCRD/POSD", booked.BankTxCode)
+	assert.Equal(t, "Point-of-Sale Payment - Debit Card", booked.BankTxCodeDescription)
+
+	assert.Equal(t, 2025, txs[1].Date.Year())
+	assert.Equal(t, time.January, txs[1].Date.Month())
+	assert.Equal(t, 25, txs[1].Date.Day())
+	assert.Equal(t, models.TransactionTypeCredit, txs[1].CreditDebit)
+
+	for i, tx := range txs {
+		assert.Equal(t, i+1, tx.SequenceNumber)
+	}
+
+	// Without a booking date the value date is used
+	pending := txs[2]
+	assert.Equal(t, "2025-01-30", pending.Date.Format("2006-01-02"))
+	assert.Equal(t, "PDNG", pending.Status)
+	assert.Equal(t, "EUR", pending.Currency)
+}
+
+func TestISO20022Parser_ValidatesCamt053V08(t *testing.T) {
+	valid, err := NewISO20022Parser(logging.NewLogrusAdapter("error", "text")).ValidateFormat("testdata/camt053_v08.xml")
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func TestAdapter_SingleDateEntries(t *testing.T) {
+	f, err := os.Open("testdata/camt053_single_date.xml")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	logger := logging.NewMockLogger()
+	txs, err := NewAdapter(logger).Parse(context.Background(), f)
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+
+	valueOnly := txs[0]
+	assert.False(t, valueOnly.Date.IsZero())
+	assert.True(t, valueOnly.Date.Equal(valueOnly.ValueDate))
+	assert.Equal(t, "2025-02-10", valueOnly.Date.Format("2006-01-02"))
+
+	bookingOnly := txs[1]
+	assert.True(t, bookingOnly.ValueDate.Equal(bookingOnly.Date))
+	assert.Equal(t, "2025-02-20", bookingOnly.ValueDate.Format("2006-01-02"))
+	assert.True(t, logger.HasEntry("DEBUG", "Entry date missing, using the other date"))
+
+	valid, err := NewISO20022Parser(logger).ValidateFormat("testdata/camt053_single_date.xml")
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func TestAdapter_ReversalEntries(t *testing.T) {
+	f, err := os.Open("testdata/camt053_reversal.xml")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	txs, err := NewAdapter(logging.NewMockLogger()).Parse(context.Background(), f)
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+
+	original := txs[0]
+	assert.True(t, original.IsDebit())
+	assert.False(t, original.Reversal)
+	assert.NotEqual(t, models.TypeReversal, original.Type)
+
+	// The reversal gives the money back: its CdtDbtInd is kept as booked
+	reversal := txs[1]
+	assert.True(t, reversal.IsCredit())
+	assert.Equal(t, models.TransactionTypeCredit, reversal.CreditDebit)
+	assert.True(t, reversal.Reversal)
+	assert.Equal(t, models.TypeReversal, reversal.Type)
+	assert.Equal(t, "Sunrise GmbH", reversal.PartyName)
+	assert.True(t, reversal.Amount.Equal(original.Amount.Neg()), "reversal %s, original %s", reversal.Amount, original.Amount)
+	assert.True(t, reversal.Credit.Equal(original.Debit))
+
+	// Together they cancel out
+	assert.True(t, original.Credit.Sub(original.Debit).Add(reversal.Credit.Sub(reversal.Debit)).IsZero())
+}
+
+func TestAdapter_SignEncodedDirection(t *testing.T) {
+	f, err := os.Open("testdata/camt053_signed_amount.xml")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	txs, err := NewAdapter(logging.NewMockLogger()).Parse(context.Background(), f)
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+
+	// Without CdtDbtInd, the negative amount makes the entry a debit
+	debit := txs[0]
+	assert.Equal(t, models.TransactionTypeDebit, debit.CreditDebit)
+	assert.True(t, debit.IsDebit())
+	assert.Equal(t, "Swisscom AG", debit.PartyName, "a debit's counterparty is the creditor")
+	assert.True(t, debit.Debit.Equal(decimal.RequireFromString("85.40")), "debit %s", debit.Debit)
+	assert.True(t, debit.Credit.IsZero())
+
+	credit := txs[1]
+	assert.Equal(t, models.TransactionTypeCredit, credit.CreditDebit)
+	assert.Equal(t, "Employer SA", credit.PartyName)
+	assert.True(t, credit.Amount.Equal(decimal.RequireFromString("1200")))
+	assert.True(t, credit.Credit.Equal(decimal.RequireFromString("1200")))
+	assert.True(t, credit.Debit.IsZero())
+}
+
+func TestAdapter_Account(t *testing.T) {
+	data, err := os.ReadFile("testdata/camt053_multi_account.xml")
+	require.NoError(t, err)
+	adapter := NewAdapter(logging.NewMockLogger())
+
+	txs, err := adapter.Parse(context.Background(), bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Len(t, txs, 3, "without --account every statement is exported")
+
+	txs, err = adapter.Parse(parser.WithAccount(context.Background(), "CH56 0483 5012 3456 7800 9"), bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	assert.Equal(t, "Monthly savings", txs[0].Description)
+	assert.Equal(t, "CH5604835012345678009", txs[0].IBAN)
+
+	// Accounts without an IBAN are selected by their other id
+	txs, err = adapter.Parse(parser.WithAccount(context.Background(), "0235-00123456.3"), bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	assert.Equal(t, "Custody fee", txs[0].Description)
+
+	_, err = adapter.Parse(parser.WithAccount(context.Background(), "DE89370400440532013000"), bytes.NewReader(data))
+	require.ErrorIs(t, err, parsererror.ErrAccountNotFound)
+	assert.Contains(t, err.Error(), "DE89370400440532013000")
+	assert.Contains(t, err.Error(), "CH9300762011623852957, CH5604835012345678009, 0235-00123456.3")
+}
+
+func TestAdapter_MaxTransactions(t *testing.T) {
+	data, err := os.ReadFile("testdata/camt053_reversal.xml")
+	require.NoError(t, err)
+	adapter := NewAdapter(logging.NewMockLogger())
+
+	ctx := parser.WithMaxTransactions(context.Background(), 1)
+	_, err = adapter.Parse(ctx, bytes.NewReader(data))
+	assert.ErrorIs(t, err, parsererror.ErrTooManyTransactions)
+
+	txs, err := adapter.Parse(parser.WithMaxTransactions(context.Background(), 2), bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Len(t, txs, 2)
+}
+
+func TestAdapter_MaxTransactionsStopsReading(t *testing.T) {
+	data, err := os.ReadFile("testdata/camt053_reversal.xml")
+	require.NoError(t, err)
+	adapter := NewAdapter(logging.NewMockLogger())
+
+	// The input fails to read past the second entry: the limit must be
+	// enforced before the parser gets there
+	second := bytes.Index(data, []byte("<Ntry>"))
+	second += bytes.Index(data[second+1:], []byte("<Ntry>")) + 1
+	input := io.MultiReader(bytes.NewReader(data[:second+len("<Ntry>")]), iotest.ErrReader(errors.New("read past the limit")))
+
+	_, err = adapter.Parse(parser.WithMaxTransactions(context.Background(), 1), input)
+	assert.ErrorIs(t, err, parsererror.ErrTooManyTransactions)
+}
+
+func TestAdapter_ParseReadError(t *testing.T) {
+	adapter := NewAdapter(logging.NewMockLogger())
+
+	input := io.MultiReader(strings.NewReader("<Document><BkToCstmrStmt>"), iotest.ErrReader(errors.New("disk failure")))
+	_, err := adapter.Parse(context.Background(), input)
+	assert.ErrorIs(t, err, parsererror.ErrReadFailed)
+	assert.Contains(t, err.Error(), "disk failure")
+}
+
+func TestAdapter_Limit(t *testing.T) {
+	data, err := os.ReadFile("testdata/camt053_reversal.xml")
+	require.NoError(t, err)
+	adapter := NewAdapter(logging.NewMockLogger())
+
+	// Parsing stops at the limit, before --max-transactions is exceeded
+	ctx := parser.WithLimit(parser.WithMaxTransactions(context.Background(), 1), 1)
+	txs, err := adapter.Parse(ctx, bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Len(t, txs, 1)
+}
+
+// partyRecorder is a categorizer that records the party names and additional
+// info it is asked about.
+type partyRecorder struct {
+	parties []string
+	infos   []string
+}
+
+func (r *partyRecorder) Categorize(_ context.Context, partyName string, _ bool, _, _, info string) (models.Category, error) {
+	r.parties = append(r.parties, partyName)
+	r.infos = append(r.infos, info)
+	return models.Category{Name: models.CategoryUncategorized}, nil
+}
+
+// ownAccountsRecorder is a partyRecorder that knows the user's own IBANs.
+type ownAccountsRecorder struct {
+	partyRecorder
+	own map[string]bool
+}
+
+func (r *ownAccountsRecorder) IsOwnAccount(iban string) bool {
+	return r.own[models.NormalizeIBAN(iban)]
+}
+
+func (r *ownAccountsRecorder) InternalTransferCategory() string {
+	return "Virements internes"
+}
+
+func TestAdapter_OwnAccountTransfer(t *testing.T) {
+	f, err := os.Open("testdata/camt053_own_transfer.xml")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	recorder := &ownAccountsRecorder{own: map[string]bool{
+		"CH9300762011623852957": true,
+		"CH5604835012345678009": true,
+	}}
+	adapter := NewAdapter(logging.NewMockLogger())
+	adapter.SetCategorizer(recorder)
+
+	txs, err := adapter.Parse(context.Background(), f)
+	require.NoError(t, err)
+	require.Len(t, txs, 3)
+
+	// Both sides of the transfer are internal and keep their account's direction
+	out, bill, in := txs[0], txs[1], txs[2]
+	assert.Equal(t, "Virements internes", out.Category)
+	assert.Equal(t, models.CategorySourceInternal, out.CategorySource)
+	assert.True(t, out.IsDebit())
+	assert.Equal(t, "Virements internes", in.Category)
+	assert.Equal(t, models.CategorySourceInternal, in.CategorySource)
+	assert.True(t, in.IsCredit())
+	assert.True(t, out.Amount.Neg().Equal(in.Amount))
+
+	// Only the payment to a third party reaches the categorizer
+	assert.Equal(t, []string{"Services Industriels"}, recorder.parties)
+	assert.Equal(t, models.CategoryUncategorized, bill.Category)
+}
+
+func TestAdapter_PartyBIC(t *testing.T) {
+	f, err := os.Open("testdata/camt053_bic.xml")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	recorder := &partyRecorder{}
+	adapter := NewAdapter(logging.NewMockLogger())
+	adapter.SetCategorizer(recorder)
+
+	txs, err := adapter.Parse(context.Background(), f)
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+
+	// Debit: the counterparty's bank is the creditor agent, not the debtor agent
+	assert.Equal(t, "BCGECHGGXXX", txs[0].PartyBIC)
+	assert.Empty(t, txs[0].PartyName)
+	// Credit: BICFI, as used from camt.053.001.08
+	assert.Equal(t, "UBSWCHZH80A", txs[1].PartyBIC)
+
+	// Without a party name the BIC is what gets categorized
+	assert.Equal(t, []string{"BCGECHGGXXX", "Jane Doe"}, recorder.parties)
+}
+
+// additionalTxInfoXML has a card payment whose merchant is only named in
+// the transaction's AddtlTxInf.
+const additionalTxInfoXML = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.04">
+  <BkToCstmrStmt><Stmt>
+    <Ntry>
+      <Amt Ccy="CHF">42.80</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts>BOOK</Sts>
+      <BookgDt><Dt>2025-03-07</Dt></BookgDt><ValDt><Dt>2025-03-07</Dt></ValDt>
+      <AddtlNtryInf>Achat carte de debit</AddtlNtryInf>
+      <NtryDtls><TxDtls>
+        <RltdPties><Cdtr><Nm>Worldline Schweiz AG</Nm></Cdtr></RltdPties>
+        <RmtInf><Ustrd>Carte 1234</Ustrd></RmtInf>
+        <AddtlTxInf>MIGROS LAUSANNE</AddtlTxInf>
+      </TxDtls></NtryDtls>
+    </Ntry>
+  </Stmt></BkToCstmrStmt>
+</Document>`
+
+func TestAdapter_AdditionalTxInfoCategorized(t *testing.T) {
+	recorder := &partyRecorder{}
+	adapter := NewAdapter(logging.NewMockLogger())
+	adapter.SetCategorizer(recorder)
+
+	txs, err := adapter.Parse(context.Background(), strings.NewReader(additionalTxInfoXML))
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+
+	assert.Equal(t, []string{"Worldline Schweiz AG"}, recorder.parties)
+	assert.Equal(t, []string{"Carte 1234 MIGROS LAUSANNE"}, recorder.infos)
+	// The output description is still the entry's additional info
+	assert.Equal(t, "Achat carte de debit", txs[0].Description)
+}
+
+// mccRecorder is a partyRecorder that maps merchant category codes.
+type mccRecorder struct {
+	partyRecorder
+	mcc map[string]string
+}
+
+func (r *mccRecorder) CategoryForMCC(mcc string) (string, bool) {
+	category, ok := r.mcc[mcc]
+	return category, ok
+}
+
+func TestAdapter_MCC(t *testing.T) {
+	xml := strings.Replace(additionalTxInfoXML, "<AddtlTxInf>MIGROS LAUSANNE</AddtlTxInf>",
+		"<AddtlTxInf>MIGROS LAUSANNE MCC 5411</AddtlTxInf>", 1)
+	recorder := &mccRecorder{mcc: map[string]string{"5411": "Alimentation", "5812": "Restaurants"}}
+	adapter := NewAdapter(logging.NewMockLogger())
+	adapter.SetCategorizer(recorder)
+
+	txs, err := adapter.Parse(context.Background(), strings.NewReader(xml))
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+
+	assert.Equal(t, "5411", txs[0].MCC)
+	assert.Equal(t, "Alimentation", txs[0].Category)
+	assert.Equal(t, models.CategorySourceMCC, txs[0].CategorySource)
+	assert.Empty(t, recorder.parties, "a mapped code is not categorized by name")
+
+	// Without a code the acquirer name is categorized as before
+	recorder = &mccRecorder{mcc: map[string]string{"5411": "Alimentation"}}
+	adapter.SetCategorizer(recorder)
+	txs, err = adapter.Parse(context.Background(), strings.NewReader(additionalTxInfoXML))
+	require.NoError(t, err)
+	assert.Empty(t, txs[0].MCC)
+	assert.Equal(t, []string{"Worldline Schweiz AG"}, recorder.parties)
+}
+
+// zeroAmountXML has an informational zero-amount entry and an entry whose
+// amount is not a number.
+const zeroAmountXML = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.04">
+  <BkToCstmrStmt><Stmt>
+    <Ntry>
+      <Amt Ccy="CHF">0.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Sts>BOOK</Sts>
+      <BookgDt><Dt>2025-03-31</Dt></BookgDt><ValDt><Dt>2025-03-31</Dt></ValDt>
+      <AcctSvcrRef>NOTICE-1</AcctSvcrRef>
+      <AddtlNtryInf>Interest rate change notice</AddtlNtryInf>
+    </Ntry>
+    <Ntry>
+      <Amt Ccy="CHF">12.x0</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts>BOOK</Sts>
+      <BookgDt><Dt>2025-03-31</Dt></BookgDt><ValDt><Dt>2025-03-31</Dt></ValDt>
+      <AcctSvcrRef>BROKEN-1</AcctSvcrRef>
+    </Ntry>
+  </Stmt></BkToCstmrStmt>
+</Document>`
+
+func TestAdapter_InvalidAmountIsReported(t *testing.T) {
+	logger := logging.NewMockLogger()
+	adapter := NewAdapter(logger)
+
+	txs, err := adapter.Parse(context.Background(), strings.NewReader(zeroAmountXML))
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+	assert.True(t, txs[0].Amount.IsZero())
+	assert.True(t, txs[1].Amount.IsZero())
+
+	// Only the unparsable amount is warned about, not the genuine zero
+	warnings := 0
+	for _, entry := range logger.GetEntriesByLevel("WARN") {
+		if entry.Message == "Invalid entry amount, using zero" {
+			warnings++
+		}
+	}
+	assert.Equal(t, 1, warnings)
+}
+
+func TestAdapter_CreditorReference(t *testing.T) {
+	f, err := os.Open("testdata/camt053_creditor_reference.xml")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	logger := logging.NewMockLogger()
+	txs, err := NewAdapter(logger).Parse(context.Background(), f)
+	require.NoError(t, err)
+	require.Len(t, txs, 3)
+
+	// Printed with spaces in the file, normalized in the transaction
+	assert.Equal(t, "RF18539007547034", txs[0].CreditorReference)
+	assert.Equal(t, "E2E-INV-1", txs[0].Reference, "the general reference is unchanged")
+	assert.Equal(t, "Invoice 2025-117", txs[0].RemittanceInfo)
+
+	// Wrong check digits
+	assert.Empty(t, txs[1].CreditorReference)
+	assert.True(t, logger.HasEntry("WARN", "Ignoring creditor reference with invalid check digits"))
+
+	// The QR reference is skipped, the untyped RF reference is taken
+	assert.Equal(t, "RF712348231", txs[2].CreditorReference)
+}
+
+func TestAdapter_ReferenceType(t *testing.T) {
+	f, err := os.Open("testdata/camt053_reference_type.xml")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	txs, err := NewAdapter(logging.NewMockLogger()).Parse(context.Background(), f)
+	require.NoError(t, err)
+	require.Len(t, txs, 4)
+
+	// QR reference: kept as structured reference, not a creditor reference
+	assert.Equal(t, "210000000003139471430009017", txs[0].StructuredReference)
+	assert.Equal(t, "QRR", txs[0].ReferenceType)
+	assert.Empty(t, txs[0].CreditorReference)
+
+	assert.Equal(t, "RF18539007547034", txs[1].StructuredReference)
+	assert.Equal(t, "SCOR", txs[1].ReferenceType)
+	assert.Equal(t, "RF18539007547034", txs[1].CreditorReference)
+
+	// An untyped valid RF reference is a SCOR reference
+	assert.Equal(t, "RF712348231", txs[2].StructuredReference)
+	assert.Equal(t, "SCOR", txs[2].ReferenceType)
+
+	// A payment without reference
+	assert.Empty(t, txs[3].StructuredReference)
+	assert.Equal(t, "NON", txs[3].ReferenceType)
+}
+
+func TestAdapter_Charges(t *testing.T) {
+	f, err := os.Open("testdata/camt053_charges.xml")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	logger := logging.NewMockLogger()
+	txs, err := NewAdapter(logger).Parse(context.Background(), f)
+	require.NoError(t, err)
+	require.Len(t, txs, 3)
+
+	// Charge records on the transaction details are added up; the amount
+	// stays the booked entry amount
+	assert.Equal(t, "12.5", txs[0].Fees.String())
+	assert.Equal(t, "-1012.5", txs[0].Amount.String())
+
+	// Entry-level charges; the one in EUR cannot be added to CHF fees
+	assert.Equal(t, "3", txs[1].Fees.String())
+	assert.True(t, logger.HasEntry("WARN", "Ignoring charge in a different currency than the entry"))
+
+	assert.True(t, txs[2].Fees.IsZero())
+
+	record, err := txs[0].MarshalCSV()
+	require.NoError(t, err)
+	assert.Equal(t, "12.50", record[20])
+}
+
+func TestAdapter_ErrorsMatchSentinels(t *testing.T) {
+	adapter := NewAdapter(logging.NewLogrusAdapter("info", "text"))
+
+	t.Run("missing file", func(t *testing.T) {
+		err := adapter.ConvertToCSV(context.Background(), filepath.Join(t.TempDir(), "missing.xml"), filepath.Join(t.TempDir(), "out.csv"))
+		assert.ErrorIs(t, err, parsererror.ErrReadFailed)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("no statements", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "empty.xml")
+		require.NoError(t, os.WriteFile(file, []byte(`<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"><BkToCstmrStmt></BkToCstmrStmt></Document>`), 0600))
+
+		_, err := adapter.ValidateFormat(file)
+		assert.ErrorIs(t, err, parsererror.ErrNoTransactions)
+	})
+}
+
+func TestISO20022Parser_ReadStatementInfo(t *testing.T) {
+	f, err := os.Open("testdata/camt053_v08.xml")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	infos, err := NewAdapter(logging.NewLogrusAdapter("info", "text")).ReadStatementInfo(f)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	assert.Equal(t, "STMT-2025-01", infos[0].ID)
+	assert.Equal(t, int64(1), infos[0].ElectronicSequenceNumber)
+	assert.NotEmpty(t, infos[0].AccountID)
+
+	_, err = NewISO20022Parser(logging.NewLogrusAdapter("info", "text")).ReadStatementInfo(strings.NewReader("not xml"))
+	assert.ErrorIs(t, err, parsererror.ErrInvalidFormat)
+}
+
+func TestISO20022Parser_ReadStatementInfo_Period(t *testing.T) {
+	f, err := os.Open("testdata/camt053_period.xml")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	infos, err := NewAdapter(logging.NewMockLogger()).ReadStatementInfo(f)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	assert.Equal(t, "2025-06-01", infos[0].PeriodStart.Format("2006-01-02"))
+	assert.Equal(t, "2025-06-30", infos[0].PeriodEnd.Format("2006-01-02"))
+}
+
+func TestAdapter_ForeignExchange(t *testing.T) {
+	f, err := os.Open("testdata/camt053_fx.xml")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	txs, err := NewAdapter(logging.NewMockLogger()).Parse(context.Background(), f)
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+
+	// The instructed EUR amount and the rate from TxAmt/CcyXchg
+	fx := txs[0]
+	assert.Equal(t, "-937.4", fx.Amount.String())
+	assert.Equal(t, "EUR", fx.OriginalCurrency)
+	assert.Equal(t, "1000", fx.OriginalAmount.String())
+	assert.Equal(t, "0.9374", fx.ExchangeRate.String())
+
+	difference, ok := fx.FXDifference()
+	require.True(t, ok)
+	assert.True(t, difference.IsZero(), "difference %s", difference)
+
+	// An amount instructed in the entry's currency is not a conversion
+	assert.Empty(t, txs[1].OriginalCurrency)
+	assert.True(t, txs[1].OriginalAmount.IsZero())
+	assert.True(t, txs[1].ExchangeRate.IsZero())
+}
+
+func TestParse_EntryStatus(t *testing.T) {
+	f, err := os.Open("testdata/camt053_pending.xml")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	txs, err := NewAdapter(logging.NewMockLogger()).Parse(context.Background(), f)
+	require.NoError(t, err)
+	require.Len(t, txs, 3)
+
+	// Both the plain and the camt.053.001.08 <Cd> layouts are read
+	assert.Equal(t, models.EntryStatusBooked, txs[0].Status)
+	assert.Equal(t, models.EntryStatusPending, txs[1].Status)
+	assert.Equal(t, models.EntryStatusInfo, txs[2].Status)
+
+	ctx := parser.WithTransactionFilter(context.Background(), parser.TransactionFilter{BookedOnly: true})
+	booked := parser.FilterTransactions(ctx, txs)
+	require.Len(t, booked, 1)
+	assert.Equal(t, "Coop Pronto", booked[0].PartyName)
+}
+
+func TestAdapter_StatementAdditionalInfo(t *testing.T) {
+	const note = "This statement replaces statement no. 7 issued on 31.07.2025 (correction of the closing balance)."
+	adapter := NewAdapter(logging.NewMockLogger())
+
+	data, err := os.ReadFile("testdata/camt053_statement_info.xml")
+	require.NoError(t, err)
+
+	infos, err := adapter.ReadStatementInfo(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	assert.Equal(t, note, infos[0].AdditionalInfo)
+
+	txs, err := adapter.Parse(context.Background(), bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+	for _, tx := range txs {
+		assert.Equal(t, note, tx.StatementNote)
+		assert.NotContains(t, tx.Description, "replaces")
+	}
+
+	// The note is written above the CSV header, the rows are unchanged
+	csvFile := filepath.Join(t.TempDir(), "statement.csv")
+	require.NoError(t, adapter.ConvertToCSV(context.Background(), "testdata/camt053_statement_info.xml", csvFile))
+	output, err := os.ReadFile(csvFile)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, "# Statement note: "+note, lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "Status,Date,"))
+}
@@ -18,3 +18,16 @@ type AIClient interface {
 	// GetEmbedding returns the vector embedding for the given text.
 	GetEmbedding(ctx context.Context, text string) ([]float32, error)
 }
+
+// PromptConfigurable is implemented by AI clients whose categorization prompt
+// can be replaced, e.g. to instruct the model in another language.
+type PromptConfigurable interface {
+	SetPromptBuilder(pb *PromptBuilder)
+}
+
+// ConfidenceCategorizer is implemented by AI clients that report how sure they
+// are of a category. The AI strategy then uses the reported confidence instead
+// of its own estimate; a confidence of 0 means none was reported.
+type ConfidenceCategorizer interface {
+	CategorizeWithConfidence(ctx context.Context, transaction models.Transaction) (models.Transaction, float64, error)
+}
@@ -56,8 +56,14 @@ func (s *AIStrategy) Categorize(ctx context.Context, tx Transaction) (models.Cat
 		return models.Category{}, false, nil
 	}
 
-	// Use the AI client to categorize
-	categorizedTransaction, err := s.aiClient.Categorize(ctx, modelTransaction)
+	// Use the AI client to categorize, with the confidence it reports if any
+	var categorizedTransaction models.Transaction
+	var reportedConfidence float64
+	if cc, ok := s.aiClient.(ConfidenceCategorizer); ok {
+		categorizedTransaction, reportedConfidence, err = cc.CategorizeWithConfidence(ctx, modelTransaction)
+	} else {
+		categorizedTransaction, err = s.aiClient.Categorize(ctx, modelTransaction)
+	}
 	if err != nil {
 		s.logger.WithError(err).WithFields(
 			logging.Field{Key: "strategy", Value: s.Name()},
@@ -76,7 +82,7 @@ func (s *AIStrategy) Categorize(ctx context.Context, tx Transaction) (models.Cat
 		).Debug("AI returned uncategorized result")
 		return models.Category{
 			Confidence: 0.0,
-			Source:     "ai",
+			Source:     models.CategorySourceAI,
 		}, false, nil
 	}
 
@@ -91,13 +97,16 @@ func (s *AIStrategy) Categorize(ctx context.Context, tx Transaction) (models.Cat
 	// - If category name matches known category list: Confidence: 0.9
 	// - Otherwise: Confidence: 0.8 (default AI estimate)
 	confidence := s.estimateConfidence(categorizedTransaction.Category)
+	if reportedConfidence > 0 {
+		confidence = reportedConfidence
+	}
 
 	// Return the category from the AI response
 	category := models.Category{
 		Name:        categorizedTransaction.Category,
 		Description: categoryDescriptionFromName(categorizedTransaction.Category),
 		Confidence:  confidence,
-		Source:      "ai",
+		Source:      models.CategorySourceAI,
 	}
 
 	return category, true, nil
@@ -158,6 +167,12 @@ func (s *AIStrategy) convertToModelTransaction(tx Transaction) (models.Transacti
 		Date:        parsedDate,
 		Category:    "", // Will be filled by AI
 	}
+	// A debtor party sent the money, so the transaction is a credit
+	if tx.IsDebtor {
+		modelTransaction.CreditDebit = models.TransactionTypeCredit
+	} else {
+		modelTransaction.CreditDebit = models.TransactionTypeDebit
+	}
 
 	// Add additional context from the Info field if available
 	if tx.Info != "" {
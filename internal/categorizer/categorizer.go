@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
@@ -18,6 +19,11 @@ import (
 // TYPE DEFINITIONS
 //------------------------------------------------------------------------------
 
+// AutoLearnSaveInterval is the number of auto-learned mappings after which the
+// mapping files are saved during a run. Whatever is left is saved by
+// SaveMappings when the command finishes.
+const AutoLearnSaveInterval = 50
+
 // Transaction represents a financial transaction to be categorized
 type Transaction struct {
 	PartyName   string // Name of the relevant party (creditor or debtor)
@@ -39,8 +45,8 @@ type Categorizer struct {
 	creditorMappings map[string]string // Maps creditor names to categories
 	debitorMappings  map[string]string // Maps debitor names to categories
 	configMutex      sync.RWMutex
-	isDirtyCreditors bool // Track if creditorMappings has been modified and needs to be saved
-	isDirtyDebitors  bool // Track if debitorMappings has been modified and needs to be saved
+	dirtyCreditors   map[string]bool // Keys of creditorMappings learned since the last save
+	dirtyDebitors    map[string]bool // Keys of debitorMappings learned since the last save
 	store            CategoryStoreInterface
 	logger           logging.Logger
 
@@ -48,11 +54,44 @@ type Categorizer struct {
 	aiClient AIClient
 
 	// Auto-learning control
-	isAutoLearnEnabled bool // Controls whether AI categorizations are saved to YAML
+	isAutoLearnEnabled bool         // Controls whether AI categorizations are saved to YAML
+	learnedSinceSave   atomic.Int32 // Mappings auto-learned since the last periodic save
 
 	// Staging store for AI suggestions when auto-learn is disabled (nil = no staging)
 	stagingStore StagingStoreInterface
 
+	// Tag rules applied independently of the category
+	tagRules []models.TagRule
+
+	// Categories of card merchant category codes, consulted before the party name
+	mccMappings map[string]string
+
+	// Categories of counterparty IBANs, consulted before any other mapping;
+	// keys are normalized with models.NormalizeIBAN and guarded by configMutex
+	ibanMappings map[string]string
+	dirtyIBANs   map[string]bool // Keys of ibanMappings learned since the last save
+
+	// Per-run categories of lowercased party names, consulted before any
+	// strategy; guarded by configMutex
+	overrides map[string]string
+
+	// Party-name cleanup applied before categorization and output, and the
+	// rules added to those of the cleanup file (bank presets); guarded by
+	// configMutex
+	nameCleaner       *models.NameCleaner
+	extraCleanupRules []models.NameCleanupRule
+
+	// Whether internal transfers are left out of categorization statistics
+	excludeInternalFromStats bool
+
+	// Normalized IBANs of the user's own accounts and the category of
+	// transfers between them
+	ownIBANs         map[string]bool
+	internalCategory string
+
+	// Audit log receiving one entry per categorization decision (nil = none)
+	auditLogger logging.Logger
+
 	// In-batch deduplication cache: avoids re-categorizing the same party name within a single run
 	batchCache   map[string]models.Category
 	batchCacheMu sync.RWMutex
@@ -71,8 +110,9 @@ func NewCategorizer(aiClient AIClient, store CategoryStoreInterface, logger logg
 		creditorMappings:   make(map[string]string, 100),         // Pre-allocate with size hint
 		debitorMappings:    make(map[string]string, 100),         // Pre-allocate with size hint
 		configMutex:        sync.RWMutex{},
-		isDirtyCreditors:   false,
-		isDirtyDebitors:    false,
+		dirtyCreditors:     make(map[string]bool),
+		dirtyDebitors:      make(map[string]bool),
+		dirtyIBANs:         make(map[string]bool),
 		store:              store,
 		logger:             logger,
 		aiClient:           aiClient,
@@ -130,6 +170,12 @@ func NewCategorizer(aiClient AIClient, store CategoryStoreInterface, logger logg
 		}
 	}
 
+	c.loadTagRules()
+	c.loadMCCMappings()
+	c.loadIBANMappings()
+	c.loadNameCleaner()
+	internalParties, _ := c.loadInternalParties()
+
 	// Initialize strategies in priority order
 	// Pass pre-loaded data to strategy constructors (pure, no I/O)
 	if semanticThreshold <= 0 {
@@ -142,8 +188,12 @@ func NewCategorizer(aiClient AIClient, store CategoryStoreInterface, logger logg
 		embCache = NewEmbeddingCache("", logger)
 	}
 
+	directMapping := NewDirectMappingStrategy(c.creditorMappings, c.debitorMappings, store, logger)
+	directMapping.SetDirectionalMappings(loadDirectionalMappings(store, logger))
+
 	c.strategies = []CategorizationStrategy{
-		NewDirectMappingStrategy(c.creditorMappings, c.debitorMappings, store, logger),
+		NewInternalPartyStrategy(internalParties, logger),
+		directMapping,
 		NewKeywordStrategy(c.categories, store, logger),
 		NewSemanticStrategyWithCache(aiClient, logger, c.categories, semanticThreshold, embCache),
 		NewAIStrategy(aiClient, logger),
@@ -171,7 +221,11 @@ func NewCategorizer(aiClient AIClient, store CategoryStoreInterface, logger logg
 //	}
 //	category, err := container.Categorizer.CategorizeTransaction(ctx, transaction)
 func (c *Categorizer) CategorizeTransaction(ctx context.Context, transaction Transaction) (models.Category, error) {
-	return c.categorizeTransaction(ctx, transaction)
+	category, err := c.categorizeTransaction(ctx, transaction)
+	if err == nil {
+		c.audit(transaction, category)
+	}
+	return category, err
 }
 
 // Categorize implements the models.TransactionCategorizer interface.
@@ -179,8 +233,10 @@ func (c *Categorizer) CategorizeTransaction(ctx context.Context, transaction Tra
 // requiring the caller to create a Transaction struct.
 //
 // This method includes auto-learning: when a category is successfully determined,
-// it automatically saves the mapping to the appropriate database (creditors or debtors)
-// for future use.
+// it records the mapping for the appropriate database (creditors or debtors)
+// for future use. The mappings are written in batches of AutoLearnSaveInterval
+// and by SaveMappings, never once per call, so concurrent callers do not
+// serialize on file writes.
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout control
@@ -203,10 +259,19 @@ func (c *Categorizer) Categorize(ctx context.Context, partyName string, isDebtor
 	}
 
 	category, err := c.categorizeTransaction(ctx, transaction)
+	if err == nil {
+		c.audit(transaction, category)
+	}
 
 	// Auto-learn: if we successfully found a category AND auto-learning is enabled,
 	// save it to the database so we don't need to recategorize similar transactions in the future
-	if err == nil && c.isAutoLearnEnabled && category.Name != "" && category.Name != models.CategoryUncategorized {
+	if err == nil && category.Source == models.CategorySourceInternal {
+		// Internal parties come from configuration, so there is nothing to learn
+		c.logger.WithField("party", partyName).Debug("Internal transfer, skipping auto-learn")
+	} else if err == nil && category.Source == models.CategorySourceOverride {
+		// Overrides are only saved when persisted on purpose
+		c.logger.WithField("party", partyName).Debug("Category override, skipping auto-learn")
+	} else if err == nil && c.isAutoLearnEnabled && category.Name != "" && category.Name != models.CategoryUncategorized {
 		if isDebtor {
 			c.logger.WithFields(
 				logging.Field{Key: "party", Value: partyName},
@@ -216,9 +281,7 @@ func (c *Categorizer) Categorize(ctx context.Context, partyName string, isDebtor
 				logging.Field{Key: "action", Value: "auto_learn_pending"},
 			).Info("Auto-learning debitor mapping")
 			c.updateDebitorCategory(partyName, category.Name)
-			if saveErr := c.SaveDebitorsToYAML(); saveErr != nil {
-				c.logger.WithError(saveErr).Warn("Failed to save debitor mapping")
-			}
+			c.mappingLearned()
 		} else {
 			c.logger.WithFields(
 				logging.Field{Key: "party", Value: partyName},
@@ -228,9 +291,7 @@ func (c *Categorizer) Categorize(ctx context.Context, partyName string, isDebtor
 				logging.Field{Key: "action", Value: "auto_learn_pending"},
 			).Info("Auto-learning creditor mapping")
 			c.updateCreditorCategory(partyName, category.Name)
-			if saveErr := c.SaveCreditorsToYAML(); saveErr != nil {
-				c.logger.WithError(saveErr).Warn("Failed to save creditor mapping")
-			}
+			c.mappingLearned()
 		}
 	} else if err == nil && !c.isAutoLearnEnabled && category.Name != "" && category.Name != models.CategoryUncategorized {
 		// Log that auto-learning is disabled but categorization succeeded
@@ -252,16 +313,27 @@ func (c *Categorizer) Categorize(ctx context.Context, partyName string, isDebtor
 	return category, err
 }
 
-// private method for the Categorizer struct
+// categorizeTransaction runs the strategies in priority order and returns the
+// category, its Source telling the method that produced it.
 func (c *Categorizer) categorizeTransaction(ctx context.Context, transaction Transaction) (models.Category, error) {
 	// If party name is empty, return uncategorized immediately
 	if strings.TrimSpace(transaction.PartyName) == "" {
 		return models.Category{
 			Name:        models.CategoryUncategorized,
 			Description: "No party name provided",
+			Source:      models.CategorySourceFallback,
 		}, nil
 	}
 
+	// Overrides given for this run beat every strategy
+	if category, ok := c.overrideCategory(transaction); ok {
+		c.logger.WithFields(
+			logging.Field{Key: "party", Value: transaction.PartyName},
+			logging.Field{Key: "category", Value: category.Name},
+		).Debug("Transaction categorized by category override")
+		return category, nil
+	}
+
 	// Check in-batch deduplication cache
 	cacheKey := fmt.Sprintf("%s|%v", strings.ToLower(strings.TrimSpace(transaction.PartyName)), transaction.IsDebtor)
 	c.batchCacheMu.RLock()
@@ -320,9 +392,49 @@ func (c *Categorizer) categorizeTransaction(ctx context.Context, transaction Tra
 	return models.Category{
 		Name:        models.CategoryUncategorized,
 		Description: "No categorization strategy succeeded",
+		Source:      models.CategorySourceFallback,
 	}, nil
 }
 
+// SetAuditLogger makes the categorizer write one entry per categorization
+// decision to logger: the party, the chosen category, the method and, for AI
+// categorizations, the confidence. Pass nil to stop auditing.
+func (c *Categorizer) SetAuditLogger(logger logging.Logger) {
+	c.auditLogger = logger
+}
+
+// AuditCategorization implements models.CategorizationAuditor, so that the
+// categories set by the own-account, IBAN, MCC and override shortcuts are
+// recorded in the audit log like those of Categorize.
+func (c *Categorizer) AuditCategorization(tx models.Transaction) {
+	c.audit(Transaction{
+		PartyName:   tx.PartyName,
+		IsDebtor:    tx.IsDebit(),
+		Amount:      tx.Amount.String(),
+		Date:        tx.Date.Format("2006-01-02"),
+		Description: tx.Description,
+	}, models.Category{Name: tx.Category, Source: tx.CategorySource})
+}
+
+// audit records the categorization of transaction in the audit log, if any.
+func (c *Categorizer) audit(transaction Transaction, category models.Category) {
+	if c.auditLogger == nil {
+		return
+	}
+	fields := []logging.Field{
+		{Key: "party", Value: transaction.PartyName},
+		{Key: "debtor", Value: transaction.IsDebtor},
+		{Key: "amount", Value: transaction.Amount},
+		{Key: "date", Value: transaction.Date},
+		{Key: logging.FieldCategory, Value: category.Name},
+		{Key: "method", Value: string(category.Source)},
+	}
+	if category.Source == models.CategorySourceAI {
+		fields = append(fields, logging.Field{Key: "confidence", Value: category.Confidence})
+	}
+	c.auditLogger.Info("Transaction categorized", fields...)
+}
+
 func categoryDescriptionFromName(name string) string {
 	// In a real-world scenario, you would look up the description from a database
 	return "Description for " + name
@@ -346,7 +458,7 @@ func (c *Categorizer) updateDebitorCategory(partyName, categoryName string) {
 	defer c.configMutex.Unlock()
 	// Performance optimization: Use helper function to minimize allocations during mapping updates
 	c.debitorMappings[strings.ToLower(partyName)] = categoryName
-	c.isDirtyDebitors = true
+	c.dirtyDebitors[strings.ToLower(partyName)] = true
 
 	// Update the DirectMappingStrategy as well
 	for _, strategy := range c.strategies {
@@ -363,17 +475,47 @@ func (c *Categorizer) updateDebitorCategory(partyName, categoryName string) {
 	c.batchCacheMu.Unlock()
 }
 
-// SaveDebitorsToYAML saves debitor mappings to YAML file if they have been modified.
+// mappingLearned counts an auto-learned mapping and saves the mapping files
+// once AutoLearnSaveInterval of them have accumulated.
+func (c *Categorizer) mappingLearned() {
+	if c.learnedSinceSave.Add(1)%AutoLearnSaveInterval != 0 {
+		return
+	}
+	if err := c.SaveMappings(); err != nil {
+		c.logger.WithError(err).Warn("Failed to save auto-learned mappings")
+		return
+	}
+	c.logger.Debug("Saved auto-learned mappings",
+		logging.Field{Key: "learned", Value: AutoLearnSaveInterval})
+}
+
+// SaveMappings saves the creditor, debitor and IBAN mappings learned since
+// they were last saved. Only those entries are written: the store merges them
+// into the files as they are on disk, so entries edited or removed there by
+// someone else since they were loaded stay that way. All files are attempted;
+// the first error is returned.
+func (c *Categorizer) SaveMappings() error {
+	errs := []error{c.SaveCreditorsToYAML(), c.SaveDebitorsToYAML(), c.SaveIBANMappingsToYAML()}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveDebitorsToYAML saves the debitor mappings learned since the last save
+// to the YAML file.
 func (c *Categorizer) SaveDebitorsToYAML() error {
 	c.configMutex.Lock()
 	defer c.configMutex.Unlock()
-	if !c.isDirtyDebitors {
+	if len(c.dirtyDebitors) == 0 {
 		return nil
 	}
-	if err := c.store.SaveDebtorMappings(c.debitorMappings); err != nil {
+	if err := c.store.SaveDebtorMappings(learnedEntries(c.debitorMappings, c.dirtyDebitors)); err != nil {
 		return err
 	}
-	c.isDirtyDebitors = false
+	clear(c.dirtyDebitors)
 	return nil
 }
 
@@ -387,7 +529,7 @@ func (c *Categorizer) updateCreditorCategory(partyName, categoryName string) {
 	defer c.configMutex.Unlock()
 	// Performance optimization: Use helper function to minimize allocations during mapping updates
 	c.creditorMappings[strings.ToLower(partyName)] = categoryName
-	c.isDirtyCreditors = true
+	c.dirtyCreditors[strings.ToLower(partyName)] = true
 
 	// Update the DirectMappingStrategy as well
 	for _, strategy := range c.strategies {
@@ -420,6 +562,16 @@ func (c *Categorizer) SetEmbeddingClient(client AIClient) {
 	}
 }
 
+// SetMatchMode sets how party names that match no creditor or debtor mapping
+// exactly are matched against the mapping keys. See MatchMode.
+func (c *Categorizer) SetMatchMode(mode MatchMode) {
+	for _, strategy := range c.strategies {
+		if directMapping, ok := strategy.(*DirectMappingStrategy); ok {
+			directMapping.SetMatchMode(mode)
+		}
+	}
+}
+
 // SetStagingStore configures the staging store for accumulating AI categorization
 // suggestions when auto-learn is disabled. Pass nil to disable staging.
 func (c *Categorizer) SetStagingStore(staging StagingStoreInterface) {
@@ -456,16 +608,28 @@ func (c *Categorizer) saveStagingSuggestion(partyName string, isDebtor bool, cat
 	).Debug("Saved suggestion to staging")
 }
 
-// SaveCreditorsToYAML saves creditor mappings to YAML file if they have been modified.
+// SaveCreditorsToYAML saves the creditor mappings learned since the last save
+// to the YAML file.
 func (c *Categorizer) SaveCreditorsToYAML() error {
 	c.configMutex.Lock()
 	defer c.configMutex.Unlock()
-	if !c.isDirtyCreditors {
+	if len(c.dirtyCreditors) == 0 {
 		return nil
 	}
-	if err := c.store.SaveCreditorMappings(c.creditorMappings); err != nil {
+	if err := c.store.SaveCreditorMappings(learnedEntries(c.creditorMappings, c.dirtyCreditors)); err != nil {
 		return err
 	}
-	c.isDirtyCreditors = false
+	clear(c.dirtyCreditors)
 	return nil
 }
+
+// learnedEntries returns the entries of mappings whose keys are in learned.
+func learnedEntries(mappings map[string]string, learned map[string]bool) map[string]string {
+	entries := make(map[string]string, len(learned))
+	for key := range learned {
+		if category, ok := mappings[key]; ok {
+			entries[key] = category
+		}
+	}
+	return entries
+}
@@ -98,3 +98,31 @@ func TestCategorizer_DependencyInjection(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "MockCategory", category2.Name)
 }
+
+func TestCategorizer_AutoLearnToggle(t *testing.T) {
+	tests := []struct {
+		name      string
+		autoLearn bool
+		wantSaved bool
+	}{
+		{"enabled saves AI result", true, true},
+		{"disabled keeps mapping files untouched", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := &store.MockCategoryStore{}
+			cat := NewCategorizer(&MockAIClient{}, mockStore, logging.NewLogrusAdapter("info", "text"), tt.autoLearn, 0.70)
+
+			for range 2 {
+				category, err := cat.Categorize(context.Background(), "Corner Bistro", false, "12.50", "05.01.2025", "")
+				require.NoError(t, err)
+				assert.Equal(t, "MockCategory", category.Name)
+			}
+			require.NoError(t, cat.SaveCreditorsToYAML())
+
+			_, saved := mockStore.CreditorMappings["corner bistro"]
+			assert.Equal(t, tt.wantSaved, saved)
+		})
+	}
+}
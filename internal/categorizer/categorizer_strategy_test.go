@@ -13,6 +13,13 @@ import (
 )
 
 func TestCategorizer_StrategyOrchestration(t *testing.T) {
+	expectedMethods := map[string]models.CategorySource{
+		"DirectMapping": models.CategorySourceMapping,
+		"Keyword":       models.CategorySourceKeyword,
+		"AI":            models.CategorySourceAI,
+		"":              models.CategorySourceFallback,
+	}
+
 	tests := []struct {
 		name             string
 		transaction      Transaction
@@ -172,6 +179,7 @@ func TestCategorizer_StrategyOrchestration(t *testing.T) {
 			// Assert
 			require.NoError(t, err)
 			assert.Equal(t, tt.expectedCategory, category.Name)
+			assert.Equal(t, expectedMethods[tt.expectedStrategy], category.Source)
 
 			// Verify logging based on expected strategy
 			if tt.expectedStrategy != "" {
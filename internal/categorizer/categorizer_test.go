@@ -14,6 +14,7 @@ import (
 	"fjacquet/camt-csv/internal/models"
 	"fjacquet/camt-csv/internal/store"
 
+	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -477,3 +478,166 @@ func TestCategorizer_ConcurrentCategorization(t *testing.T) {
 		assert.Equal(t, "Food", result) // Should all be categorized as Food by keyword strategy
 	}
 }
+
+func TestCategorizer_AuditLogger(t *testing.T) {
+	tempDir := t.TempDir()
+
+	categoriesFile := filepath.Join(tempDir, "categories.yaml")
+	require.NoError(t, os.WriteFile(categoriesFile, []byte(`categories:
+  - name: "Food"
+    keywords: ["grocery"]`), 0600))
+	creditorsFile := filepath.Join(tempDir, "creditors.yaml")
+	require.NoError(t, os.WriteFile(creditorsFile, []byte(`"MIGROS": "Groceries"`), 0600))
+	debtorsFile := filepath.Join(tempDir, "debtors.yaml")
+	require.NoError(t, os.WriteFile(debtorsFile, []byte("{}"), 0600))
+
+	categoryStore := &store.CategoryStore{
+		CategoriesFile: categoriesFile,
+		CreditorsFile:  creditorsFile,
+		DebtorsFile:    debtorsFile,
+	}
+	mockAIClient := &MockAIClient{
+		CategorizeFunc: func(ctx context.Context, transaction models.Transaction) (models.Transaction, error) {
+			transaction.Category = "Travel"
+			return transaction, nil
+		},
+	}
+
+	cat := categorizer.NewCategorizer(mockAIClient, categoryStore, logging.NewMockLogger(), false, 0.70)
+	audit := logging.NewMockLogger()
+	cat.SetAuditLogger(audit)
+
+	for _, party := range []string{"MIGROS", "Corner Grocery", "SBB"} {
+		_, err := cat.CategorizeTransaction(context.Background(), categorizer.Transaction{
+			PartyName: party,
+			Amount:    "10.00",
+			Date:      "01.02.2025",
+		})
+		require.NoError(t, err)
+	}
+
+	fieldsOf := func(entry logging.LogEntry) map[string]interface{} {
+		fields := make(map[string]interface{}, len(entry.Fields))
+		for _, f := range entry.Fields {
+			fields[f.Key] = f.Value
+		}
+		return fields
+	}
+
+	entries := audit.GetEntries()
+	require.Len(t, entries, 3)
+
+	mapping := fieldsOf(entries[0])
+	assert.Equal(t, "MIGROS", mapping["party"])
+	assert.Equal(t, "Groceries", mapping[logging.FieldCategory])
+	assert.Equal(t, "mapping", mapping["method"])
+	assert.NotContains(t, mapping, "confidence")
+
+	keyword := fieldsOf(entries[1])
+	assert.Equal(t, "Food", keyword[logging.FieldCategory])
+	assert.Equal(t, "keyword", keyword["method"])
+
+	ai := fieldsOf(entries[2])
+	assert.Equal(t, "SBB", ai["party"])
+	assert.Equal(t, "Travel", ai[logging.FieldCategory])
+	assert.Equal(t, "ai", ai["method"])
+	assert.Contains(t, ai, "confidence")
+	assert.Equal(t, "01.02.2025", ai["date"])
+}
+
+func TestCategorizer_AuditLogShortcuts(t *testing.T) {
+	categoryStore := &store.MockCategoryStore{
+		IBANMappings: map[string]string{"CH9300762011623852957": "Salary"},
+		MCCMappings:  map[string]string{"5411": "Groceries"},
+	}
+	cat := categorizer.NewCategorizer(nil, categoryStore, logging.NewMockLogger(), false, 0.70)
+	audit := logging.NewMockLogger()
+	cat.SetAuditLogger(audit)
+	cat.SetCategoryOverrides(map[string]string{"Cafe Central": "Leisure"}, false)
+
+	date := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	iban := models.Transaction{PartyName: "ACME SA", PartyIBAN: "CH9300762011623852957", Amount: decimal.NewFromInt(2500), CreditDebit: models.TransactionTypeCredit, Date: date}
+	require.True(t, models.ApplyIBANMapping(&iban, cat))
+	mcc := models.Transaction{PartyName: "Corner Grocery", MCC: "5411", Amount: decimal.NewFromInt(-12), CreditDebit: models.TransactionTypeDebit, DebitFlag: true, Date: date}
+	require.True(t, models.ApplyMCC(&mcc, cat))
+	override := models.Transaction{PartyName: "Cafe Central", Date: date}
+	require.True(t, models.ApplyCategoryOverride(&override, cat))
+
+	entries := audit.GetEntries()
+	require.Len(t, entries, 3, "one entry per shortcut categorization")
+	assert.Contains(t, entries[0].Fields, logging.Field{Key: "method", Value: "iban"})
+	assert.Contains(t, entries[0].Fields, logging.Field{Key: logging.FieldCategory, Value: "Salary"})
+	assert.Contains(t, entries[0].Fields, logging.Field{Key: "date", Value: "2025-02-01"})
+	assert.Contains(t, entries[1].Fields, logging.Field{Key: "method", Value: "mcc"})
+	assert.Contains(t, entries[1].Fields, logging.Field{Key: "debtor", Value: true})
+	assert.Contains(t, entries[2].Fields, logging.Field{Key: "method", Value: "override"})
+}
+
+// countingStore counts the creditor mapping saves of a MockCategoryStore.
+type countingStore struct {
+	*store.MockCategoryStore
+	creditorSaves int
+}
+
+func (s *countingStore) SaveCreditorMappings(mappings map[string]string) error {
+	s.creditorSaves++
+	return s.MockCategoryStore.SaveCreditorMappings(mappings)
+}
+
+func TestCategorizer_AutoLearnSavesInBatches(t *testing.T) {
+	categoryStore := &countingStore{MockCategoryStore: &store.MockCategoryStore{}}
+	mockAIClient := &MockAIClient{
+		CategorizeFunc: func(ctx context.Context, transaction models.Transaction) (models.Transaction, error) {
+			transaction.Category = "Travel"
+			return transaction, nil
+		},
+	}
+	cat := categorizer.NewCategorizer(mockAIClient, categoryStore, logging.NewMockLogger(), true, 0.70)
+
+	categorize := func(from, to int) {
+		for i := from; i < to; i++ {
+			_, err := cat.Categorize(context.Background(), fmt.Sprintf("Party %d", i), false, "10.00", "01.02.2025", "")
+			require.NoError(t, err)
+		}
+	}
+
+	// Learned mappings are not written on every call
+	categorize(0, 3)
+	assert.Equal(t, 0, categoryStore.creditorSaves)
+
+	// The final save persists every learned mapping
+	require.NoError(t, cat.SaveMappings())
+	assert.Equal(t, 1, categoryStore.creditorSaves)
+	assert.Len(t, categoryStore.CreditorMappings, 3)
+	require.NoError(t, cat.SaveMappings())
+	assert.Equal(t, 1, categoryStore.creditorSaves, "nothing left to save")
+
+	// A long run saves periodically
+	categorize(3, categorizer.AutoLearnSaveInterval)
+	assert.Equal(t, 2, categoryStore.creditorSaves)
+	assert.Len(t, categoryStore.CreditorMappings, categorizer.AutoLearnSaveInterval)
+}
+
+func TestCategorizer_SaveMappingsWritesOnlyLearnedEntries(t *testing.T) {
+	categoryStore := &store.MockCategoryStore{
+		CreditorMappings: map[string]string{"coop": "Courses", "migros": "Shopping"},
+	}
+	mockAIClient := &MockAIClient{
+		CategorizeFunc: func(ctx context.Context, transaction models.Transaction) (models.Transaction, error) {
+			transaction.Category = "Travel"
+			return transaction, nil
+		},
+	}
+	cat := categorizer.NewCategorizer(mockAIClient, categoryStore, logging.NewMockLogger(), true, 0.70)
+
+	// The file is edited by someone else after it was loaded
+	delete(categoryStore.CreditorMappings, "migros")
+	categoryStore.CreditorMappings["coop"] = "Groceries"
+
+	_, err := cat.Categorize(context.Background(), "SBB", false, "10.00", "01.02.2025", "")
+	require.NoError(t, err)
+	require.NoError(t, cat.SaveMappings())
+
+	// Only the learned entry is saved: the removal and the edit survive
+	assert.Equal(t, map[string]string{"coop": "Groceries", "sbb": "Travel"}, categoryStore.CreditorMappings)
+}
@@ -0,0 +1,57 @@
+package categorizer
+
+import (
+	"slices"
+
+	"fjacquet/camt-csv/internal/models"
+)
+
+// CleanupStoreInterface is implemented by stores that provide party-name
+// cleanup rules. It is optional: without it the default rules are used.
+type CleanupStoreInterface interface {
+	LoadNameCleanupRules() ([]models.NameCleanupRule, error)
+}
+
+// loadNameCleaner compiles the cleanup rules from the store, falling back to
+// models.DefaultNameCleanupRules when the store has no cleanup file or its
+// rules are invalid, followed by the rules added with AddNameCleanupRules.
+func (c *Categorizer) loadNameCleaner() {
+	rules := models.DefaultNameCleanupRules()
+	if cleanupStore, ok := c.store.(CleanupStoreInterface); ok {
+		loaded, err := cleanupStore.LoadNameCleanupRules()
+		if err != nil {
+			c.logger.WithError(err).Warn("Failed to load cleanup rules, using the defaults")
+		} else if loaded != nil {
+			rules = loaded
+		}
+	}
+
+	cleaner, err := models.NewNameCleaner(slices.Concat(rules, c.extraCleanupRules))
+	if err != nil {
+		c.logger.WithError(err).Warn("Invalid cleanup rules, using the defaults")
+		cleaner, _ = models.NewNameCleaner(slices.Concat(models.DefaultNameCleanupRules(), c.extraCleanupRules))
+	}
+	c.nameCleaner = cleaner
+}
+
+// CleanPartyName implements models.PartyNameCleaner.
+func (c *Categorizer) CleanPartyName(name string) string {
+	c.configMutex.RLock()
+	defer c.configMutex.RUnlock()
+	return c.nameCleaner.Clean(name)
+}
+
+// AddNameCleanupRules adds rules, such as a bank preset's, to run after
+// those of the cleanup file for the rest of the run; they are kept when the
+// cleanup file is reloaded. Invalid rules are an error and leave the current
+// ones.
+func (c *Categorizer) AddNameCleanupRules(rules []models.NameCleanupRule) error {
+	if _, err := models.NewNameCleaner(rules); err != nil {
+		return err
+	}
+	c.configMutex.Lock()
+	defer c.configMutex.Unlock()
+	c.extraCleanupRules = slices.Concat(c.extraCleanupRules, rules)
+	c.loadNameCleaner()
+	return nil
+}
@@ -0,0 +1,66 @@
+package categorizer
+
+import (
+	"testing"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/store"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategorizer_CleanPartyName(t *testing.T) {
+	// Without a cleanup file the payment method prefixes are stripped
+	cat := NewCategorizer(nil, &store.MockCategoryStore{}, logging.NewMockLogger(), false, 0.70)
+	assert.Equal(t, "Starbucks", cat.CleanPartyName("PMT CARTE Starbucks"))
+	assert.Equal(t, "PMT TWINT", cat.CleanPartyName("PMT TWINT"))
+
+	// Rules from the store replace the defaults
+	cat = NewCategorizer(nil, &store.MockCategoryStore{
+		CleanupRules: []models.NameCleanupRule{
+			{Action: models.CleanupReplace, Pattern: `\s*X{4,}\d*\s*`, Replacement: " "},
+			{Action: models.CleanupStripSuffix, Values: []string{" CH"}},
+		},
+	}, logging.NewMockLogger(), false, 0.70)
+	assert.Equal(t, "Migros Lausanne", cat.CleanPartyName("Migros XXXX1234 Lausanne CH"))
+	assert.Equal(t, "PMT CARTE Coop", cat.CleanPartyName("PMT CARTE Coop"))
+
+	// An empty rule list disables the cleanup
+	cat = NewCategorizer(nil, &store.MockCategoryStore{CleanupRules: []models.NameCleanupRule{}},
+		logging.NewMockLogger(), false, 0.70)
+	assert.Equal(t, "PMT CARTE Coop", cat.CleanPartyName("PMT CARTE Coop"))
+}
+
+func TestCategorizer_CleanupRulesInvalid(t *testing.T) {
+	logger := logging.NewMockLogger()
+	cat := NewCategorizer(nil, &store.MockCategoryStore{
+		CleanupRules: []models.NameCleanupRule{{Action: "shout"}},
+	}, logger, false, 0.70)
+
+	assert.Equal(t, "Starbucks", cat.CleanPartyName("PMT CARTE Starbucks"))
+	assert.True(t, logger.HasEntry("WARN", "Invalid cleanup rules, using the defaults"))
+
+	logger = logging.NewMockLogger()
+	cat = NewCategorizer(nil, &store.MockCategoryStore{LoadCleanupRulesError: assert.AnError}, logger, false, 0.70)
+	assert.Equal(t, "Starbucks", cat.CleanPartyName("PMT CARTE Starbucks"))
+	assert.True(t, logger.HasEntry("WARN", "Failed to load cleanup rules, using the defaults"))
+}
+
+func TestCategorizer_AddNameCleanupRules(t *testing.T) {
+	cat := NewCategorizer(nil, &store.MockCategoryStore{
+		CleanupRules: []models.NameCleanupRule{{Action: models.CleanupStripPrefix, Values: []string{"PMT CARTE "}}},
+	}, logging.NewMockLogger(), false, 0.70)
+
+	err := cat.AddNameCleanupRules([]models.NameCleanupRule{{Action: models.CleanupStripSuffix, Values: []string{" CH"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, "Coop", cat.CleanPartyName("PMT CARTE Coop CH"), "added to the cleanup file's rules")
+
+	// Invalid rules keep the current ones
+	assert.Error(t, cat.AddNameCleanupRules([]models.NameCleanupRule{{Action: "shout"}}))
+	assert.Equal(t, "Coop", cat.CleanPartyName("PMT CARTE Coop CH"))
+
+	// Reloading the cleanup file keeps the added rules
+	assert.NoError(t, cat.Reload())
+	assert.Equal(t, "Coop", cat.CleanPartyName("PMT CARTE Coop CH"))
+}
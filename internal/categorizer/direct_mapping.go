@@ -9,11 +9,20 @@ import (
 	"fjacquet/camt-csv/internal/models"
 )
 
-// DirectMappingStrategy implements categorization using exact name matches
-// from creditor and debtor mapping databases.
+// DirectionalMappingStoreInterface is implemented by stores whose mapping
+// files can give a party a different category for debits and credits.
+type DirectionalMappingStoreInterface interface {
+	LoadDirectionalMappings() (map[string]models.DirectionalCategory, error)
+}
+
+// DirectMappingStrategy implements categorization using name matches from
+// creditor and debtor mapping databases. Names are matched exactly first,
+// then, depending on the match mode, as whole words or substrings.
 type DirectMappingStrategy struct {
-	creditorMappings map[string]string // Maps creditor names to categories
-	debtorMappings   map[string]string // Maps debtor names to categories
+	creditorMappings map[string]string                     // Maps creditor names to categories
+	debtorMappings   map[string]string                     // Maps debtor names to categories
+	directional      map[string]models.DirectionalCategory // Maps party names to per-direction categories
+	matchMode        MatchMode                             // Fallback matching once the exact lookup failed
 	store            CategoryStoreInterface
 	logger           logging.Logger
 	mu               sync.RWMutex // Protects the mappings
@@ -26,11 +35,51 @@ func NewDirectMappingStrategy(creditorMappings, debtorMappings map[string]string
 		debtorMappings:   debtorMappings,
 		store:            store,
 		logger:           logger,
+		matchMode:        MatchModeExact,
 	}
 
 	return strategy
 }
 
+// SetDirectionalMappings sets the parties whose category depends on the
+// transaction direction. They take precedence over the creditor and debtor
+// mappings; a direction without a category falls back to them.
+func (s *DirectMappingStrategy) SetDirectionalMappings(mappings map[string]models.DirectionalCategory) {
+	normalized := make(map[string]models.DirectionalCategory, len(mappings))
+	for key, value := range mappings {
+		normalized[strings.ToLower(key)] = value
+	}
+
+	s.mu.Lock()
+	s.directional = normalized
+	s.mu.Unlock()
+}
+
+// SetMatchMode sets how party names that match no mapping key exactly are
+// matched against the creditor and debtor mappings. Directional mappings
+// always require an exact match.
+func (s *DirectMappingStrategy) SetMatchMode(mode MatchMode) {
+	s.mu.Lock()
+	s.matchMode = mode
+	s.mu.Unlock()
+}
+
+// loadDirectionalMappings loads the directional mappings from the store if it
+// supports them.
+func loadDirectionalMappings(store CategoryStoreInterface, logger logging.Logger) map[string]models.DirectionalCategory {
+	directionalStore, ok := store.(DirectionalMappingStoreInterface)
+	if !ok {
+		return nil
+	}
+
+	mappings, err := directionalStore.LoadDirectionalMappings()
+	if err != nil {
+		logger.WithError(err).Warn("Failed to load directional mappings")
+		return nil
+	}
+	return mappings
+}
+
 // Name returns the name of this strategy for logging and debugging.
 func (s *DirectMappingStrategy) Name() string {
 	return "DirectMapping"
@@ -53,7 +102,15 @@ func (s *DirectMappingStrategy) Categorize(ctx context.Context, tx Transaction)
 	var found bool
 
 	// Check appropriate mapping based on transaction direction
-	if tx.IsDebtor {
+	if categoryName = s.directional[partyNameLower].For(tx.IsDebtor); categoryName != "" {
+		found = true
+		s.logger.WithFields(
+			logging.Field{Key: "strategy", Value: s.Name()},
+			logging.Field{Key: "party", Value: tx.PartyName},
+			logging.Field{Key: "category", Value: categoryName},
+			logging.Field{Key: "mapping_type", Value: "directional"},
+		).Debug("Transaction categorized using directional mapping")
+	} else if tx.IsDebtor {
 		// For debtor transactions, check debtor mappings
 		categoryName, found = s.debtorMappings[partyNameLower]
 		if found {
@@ -77,6 +134,27 @@ func (s *DirectMappingStrategy) Categorize(ctx context.Context, tx Transaction)
 		}
 	}
 
+	// Fall back to matching the mapping keys inside the party name
+	confidence := 1.0 // Highest confidence for exact direct mappings
+	if !found && s.matchMode != MatchModeExact {
+		mappings, mappingType := s.creditorMappings, "creditor"
+		if tx.IsDebtor {
+			mappings, mappingType = s.debtorMappings, "debtor"
+		}
+		var key string
+		if key, categoryName, found = partialMatch(mappings, partyNameLower, s.matchMode); found {
+			confidence = 0.9
+			s.logger.WithFields(
+				logging.Field{Key: "strategy", Value: s.Name()},
+				logging.Field{Key: "party", Value: tx.PartyName},
+				logging.Field{Key: "category", Value: categoryName},
+				logging.Field{Key: "mapping_type", Value: mappingType},
+				logging.Field{Key: "match_mode", Value: string(s.matchMode)},
+				logging.Field{Key: "mapping_key", Value: key},
+			).Debug("Transaction categorized using partial name mapping")
+		}
+	}
+
 	// If not found or if the found category is a failed AI attempt, allow other strategies to try
 	if !found || categoryName == "Uncategorized (AI)" {
 		if found && categoryName == "Uncategorized (AI)" {
@@ -86,23 +164,35 @@ func (s *DirectMappingStrategy) Categorize(ctx context.Context, tx Transaction)
 				logging.Field{Key: "category", Value: categoryName},
 			).Debug("Found previous failed AI attempt, allowing retry with other strategies")
 		}
-		return models.Category{
-			Confidence: 0.0,
-			Source:     "none",
-		}, false, nil
+		return models.Category{}, false, nil
 	}
 
 	// Create category with name and description
 	category := models.Category{
 		Name:        categoryName,
 		Description: categoryDescriptionFromName(categoryName),
-		Confidence:  1.0, // Highest confidence for direct mappings
-		Source:      "direct_mapping",
+		Confidence:  confidence,
+		Source:      models.CategorySourceMapping,
 	}
 
 	return category, true, nil
 }
 
+// SetMappings replaces the creditor, debtor and directional mappings. The
+// keys of creditorMappings and debtorMappings must already be lowercase.
+func (s *DirectMappingStrategy) SetMappings(creditorMappings, debtorMappings map[string]string, directional map[string]models.DirectionalCategory) {
+	normalized := make(map[string]models.DirectionalCategory, len(directional))
+	for key, value := range directional {
+		normalized[strings.ToLower(key)] = value
+	}
+
+	s.mu.Lock()
+	s.creditorMappings = creditorMappings
+	s.debtorMappings = debtorMappings
+	s.directional = normalized
+	s.mu.Unlock()
+}
+
 // ReloadMappings reloads the mappings from the store.
 // This can be called when the underlying YAML files have been updated.
 func (s *DirectMappingStrategy) ReloadMappings() {
@@ -117,6 +207,8 @@ func (s *DirectMappingStrategy) ReloadMappings() {
 		s.logger.WithError(debtorErr).Warn("Failed to load debtor mappings during reload")
 	}
 
+	directional := loadDirectionalMappings(s.store, s.logger)
+
 	// Build new maps with normalized keys (outside lock)
 	newCreditorMappings := make(map[string]string, 100)
 	if creditorErr == nil {
@@ -132,8 +224,14 @@ func (s *DirectMappingStrategy) ReloadMappings() {
 		}
 	}
 
+	newDirectional := make(map[string]models.DirectionalCategory, len(directional))
+	for key, value := range directional {
+		newDirectional[strings.ToLower(key)] = value
+	}
+
 	// Atomic swap under lock (very brief critical section)
 	s.mu.Lock()
+	s.directional = newDirectional
 	s.creditorMappings = newCreditorMappings
 	s.debtorMappings = newDebtorMappings
 	s.mu.Unlock()
@@ -344,3 +344,115 @@ func TestDirectMappingStrategy_ReloadMappings_RaceCondition(t *testing.T) {
 		t.Errorf("Unexpected error during concurrent operations: %v", err)
 	}
 }
+
+func TestDirectMappingStrategy_DirectionalMappings(t *testing.T) {
+	mockStore := &store.MockCategoryStore{
+		CreditorMappings: map[string]string{"twint": models.CategoryTransfers},
+		DebtorMappings:   map[string]string{"galaxus": "Old Category"},
+	}
+	strategy := NewDirectMappingStrategy(mockStore.CreditorMappings, mockStore.DebtorMappings, mockStore, &logging.MockLogger{})
+	strategy.SetDirectionalMappings(map[string]models.DirectionalCategory{
+		"Galaxus": {Debit: models.CategoryShopping, Credit: "Remboursements"},
+		"twint":   {Debit: models.CategoryShopping},
+	})
+
+	tests := []struct {
+		name             string
+		transaction      Transaction
+		expectedCategory string
+	}{
+		{"debit uses debit category", Transaction{PartyName: "GALAXUS", IsDebtor: true}, models.CategoryShopping},
+		{"credit uses credit category", Transaction{PartyName: "Galaxus", IsDebtor: false}, "Remboursements"},
+		{"missing direction falls back to plain mapping", Transaction{PartyName: "Twint", IsDebtor: false}, models.CategoryTransfers},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			category, found, err := strategy.Categorize(context.Background(), tt.transaction)
+			require.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, tt.expectedCategory, category.Name)
+		})
+	}
+}
+
+func TestDirectMappingStrategy_ReloadDirectionalMappings(t *testing.T) {
+	mockStore := &store.MockCategoryStore{}
+	strategy := NewDirectMappingStrategy(map[string]string{}, map[string]string{}, mockStore, &logging.MockLogger{})
+
+	mockStore.DirectionalMappings = map[string]models.DirectionalCategory{
+		"Galaxus": {Debit: models.CategoryShopping, Credit: "Remboursements"},
+	}
+	strategy.ReloadMappings()
+
+	category, found, err := strategy.Categorize(context.Background(), Transaction{PartyName: "galaxus", IsDebtor: false})
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "Remboursements", category.Name)
+}
+
+func TestDirectMappingStrategy_MatchMode(t *testing.T) {
+	creditors := map[string]string{
+		"coop":         models.CategoryGroceries,
+		"coop pronto":  "Fuel",
+		"failed party": "Uncategorized (AI)",
+	}
+
+	tests := []struct {
+		name             string
+		mode             MatchMode
+		partyName        string
+		expectedCategory string
+		expectedFound    bool
+	}{
+		{"exact ignores partial names", MatchModeExact, "COOP ZURICH", "", false},
+		{"exact still matches whole name", MatchModeExact, "Coop", models.CategoryGroceries, true},
+		{"token matches whole word", MatchModeToken, "COOP CITY ZURICH", models.CategoryGroceries, true},
+		{"token matches word after punctuation", MatchModeToken, "MIGROS/COOP", models.CategoryGroceries, true},
+		{"token rejects word containing key", MatchModeToken, "SCOOPER", "", false},
+		{"token prefers longest key", MatchModeToken, "COOP PRONTO BERN", "Fuel", true},
+		{"token skips failed AI categorizations", MatchModeToken, "FAILED PARTY AG", "", false},
+		{"substring matches inside word", MatchModeSubstring, "SCOOPER", models.CategoryGroceries, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := NewDirectMappingStrategy(creditors, map[string]string{}, &store.MockCategoryStore{}, &logging.MockLogger{})
+			strategy.SetMatchMode(tt.mode)
+
+			category, found, err := strategy.Categorize(context.Background(), Transaction{PartyName: tt.partyName})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedFound, found)
+			assert.Equal(t, tt.expectedCategory, category.Name)
+		})
+	}
+}
+
+func TestDirectMappingStrategy_MatchModeUsesDirection(t *testing.T) {
+	strategy := NewDirectMappingStrategy(
+		map[string]string{"coop": models.CategoryGroceries},
+		map[string]string{"acme": models.CategorySalary},
+		&store.MockCategoryStore{}, &logging.MockLogger{})
+	strategy.SetMatchMode(MatchModeToken)
+
+	_, found, err := strategy.Categorize(context.Background(), Transaction{PartyName: "COOP BERN", IsDebtor: true})
+	require.NoError(t, err)
+	assert.False(t, found, "debtor transactions only match debtor mappings")
+
+	category, found, err := strategy.Categorize(context.Background(), Transaction{PartyName: "ACME SA Lausanne", IsDebtor: true})
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, models.CategorySalary, category.Name)
+	assert.Less(t, category.Confidence, 1.0)
+}
+
+func TestParseMatchMode(t *testing.T) {
+	for input, expected := range map[string]MatchMode{"": MatchModeExact, "exact": MatchModeExact, "Token": MatchModeToken, " substring ": MatchModeSubstring} {
+		mode, err := ParseMatchMode(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, mode)
+	}
+
+	_, err := ParseMatchMode("fuzzy")
+	assert.Error(t, err)
+}
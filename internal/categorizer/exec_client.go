@@ -0,0 +1,150 @@
+package categorizer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ExecClient implements AIClient by running an external command for each
+// transaction, so that an existing categorizer can be plugged in without code
+// changes. The command receives the transaction as a JSON object on stdin and
+// writes {"category": "...", "confidence": 0.9} to stdout.
+//
+// A command that fails, exits with a non-zero status or runs past the timeout
+// leaves the transaction uncategorized.
+type ExecClient struct {
+	command string
+	timeout time.Duration
+	log     logging.Logger
+}
+
+// execWaitDelay is how long a timed-out command's output is still waited for.
+const execWaitDelay = time.Second
+
+// ExecRequest is the JSON object an ExecClient writes to the command's stdin.
+// Amount is signed: negative for debits.
+type ExecRequest struct {
+	Date        string `json:"date,omitempty"`
+	PartyName   string `json:"party_name"`
+	Description string `json:"description,omitempty"`
+	Amount      string `json:"amount"`
+	Currency    string `json:"currency,omitempty"`
+	CreditDebit string `json:"credit_debit,omitempty"`
+}
+
+// ExecResponse is the JSON object an ExecClient reads from the command's stdout.
+type ExecResponse struct {
+	Category   string  `json:"category"`
+	Confidence float64 `json:"confidence"`
+}
+
+// NewExecClient creates an ExecClient running command, the path of an
+// executable, with a timeout of timeoutSeconds per transaction (30 when not
+// positive).
+func NewExecClient(logger logging.Logger, command string, timeoutSeconds int) *ExecClient {
+	if logger == nil {
+		logger = logging.NewLogrusAdapterFromLogger(logrus.New())
+	}
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = 30
+	}
+	return &ExecClient{
+		command: command,
+		timeout: time.Duration(timeoutSeconds) * time.Second,
+		log:     logger,
+	}
+}
+
+// Categorize runs the command for transaction and returns it with the
+// category the command chose.
+func (c *ExecClient) Categorize(ctx context.Context, transaction models.Transaction) (models.Transaction, error) {
+	categorized, _, err := c.CategorizeWithConfidence(ctx, transaction)
+	return categorized, err
+}
+
+// CategorizeWithConfidence runs the command for transaction and returns it
+// with the category the command chose, and the confidence it reported.
+func (c *ExecClient) CategorizeWithConfidence(ctx context.Context, transaction models.Transaction) (models.Transaction, float64, error) {
+	transaction.Category = models.CategoryUncategorized
+
+	input, err := json.Marshal(newExecRequest(transaction))
+	if err != nil {
+		return transaction, 0, fmt.Errorf("error encoding transaction: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	// #nosec G204 -- the command is the categorizer configured by the user
+	cmd := exec.CommandContext(ctx, c.command)
+	cmd.Stdin = bytes.NewReader(input)
+	// Children of a killed script may keep its output open; stop waiting for them
+	cmd.WaitDelay = execWaitDelay
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("categorizer command timed out after %s", c.timeout)
+		}
+		c.log.WithError(err).WithFields(
+			logging.Field{Key: "party_name", Value: transaction.PartyName},
+			logging.Field{Key: "stderr", Value: strings.TrimSpace(stderr.String())},
+		).Warn("Categorizer command failed")
+		return transaction, 0, fmt.Errorf("categorizer command failed: %w", err)
+	}
+
+	var response ExecResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return transaction, 0, fmt.Errorf("invalid categorizer output: %w", err)
+	}
+	if response.Confidence < 0 || response.Confidence > 1 {
+		return transaction, 0, fmt.Errorf("invalid categorizer output: confidence %v is not between 0 and 1", response.Confidence)
+	}
+
+	if category := strings.TrimSpace(response.Category); category != "" {
+		transaction.Category = category
+	}
+	c.log.WithFields(
+		logging.Field{Key: "party_name", Value: transaction.PartyName},
+		logging.Field{Key: "category", Value: transaction.Category},
+		logging.Field{Key: "confidence", Value: response.Confidence},
+	).Debug("Transaction categorized by external command")
+	return transaction, response.Confidence, nil
+}
+
+// GetEmbedding returns an error since an external categorizer has no embeddings.
+func (c *ExecClient) GetEmbedding(_ context.Context, _ string) ([]float32, error) {
+	return nil, fmt.Errorf("external categorizer commands do not support embeddings")
+}
+
+// newExecRequest returns the JSON request of tx.
+func newExecRequest(tx models.Transaction) ExecRequest {
+	amount := tx.Amount.Abs()
+	if tx.CreditDebit == models.TransactionTypeDebit {
+		amount = amount.Neg()
+	}
+	request := ExecRequest{
+		PartyName:   tx.PartyName,
+		Description: tx.Description,
+		Amount:      models.FormatAmount(amount, tx.Currency),
+		Currency:    tx.Currency,
+		CreditDebit: tx.CreditDebit,
+	}
+	if !tx.Date.IsZero() {
+		request.Date = tx.Date.Format(time.DateOnly)
+	}
+	return request
+}
@@ -0,0 +1,98 @@
+package categorizer
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeScript writes an executable shell script with body and returns its path.
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("categorizer scripts are shell scripts")
+	}
+	path := filepath.Join(t.TempDir(), "categorize.sh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o700)) // #nosec G306 -- test script must be executable
+	return path
+}
+
+func TestExecClient_Categorize(t *testing.T) {
+	stdin := filepath.Join(t.TempDir(), "stdin.json")
+	script := writeScript(t, `cat > "`+stdin+`"
+echo '{"category": "Groceries", "confidence": 0.93}'`)
+	client := NewExecClient(logging.NewMockLogger(), script, 5)
+
+	tx := models.Transaction{
+		PartyName:   "Migros",
+		Description: "Groceries week 19",
+		Amount:      decimal.RequireFromString("64.2"),
+		Currency:    "CHF",
+		CreditDebit: models.TransactionTypeDebit,
+	}
+	categorized, confidence, err := client.CategorizeWithConfidence(context.Background(), tx)
+	require.NoError(t, err)
+	assert.Equal(t, "Groceries", categorized.Category)
+	assert.InDelta(t, 0.93, confidence, 1e-9)
+
+	data, err := os.ReadFile(stdin)
+	require.NoError(t, err)
+	var request ExecRequest
+	require.NoError(t, json.Unmarshal(data, &request))
+	assert.Equal(t, ExecRequest{
+		PartyName:   "Migros",
+		Description: "Groceries week 19",
+		Amount:      "-64.20",
+		Currency:    "CHF",
+		CreditDebit: models.TransactionTypeDebit,
+	}, request)
+}
+
+func TestExecClient_FallsBackToUncategorized(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		timeout int
+		wantErr string
+	}{
+		{"non-zero exit", `echo "model not loaded" >&2; exit 3`, 5, "categorizer command failed"},
+		{"timeout", `sleep 5`, 1, "timed out"},
+		{"invalid output", `echo 'Groceries'`, 5, "invalid categorizer output"},
+		{"confidence out of range", `echo '{"category": "Groceries", "confidence": 7}'`, 5, "not between 0 and 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewExecClient(logging.NewMockLogger(), writeScript(t, tt.script), tt.timeout)
+			categorized, err := client.Categorize(context.Background(), models.Transaction{PartyName: "Migros"})
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.Equal(t, models.CategoryUncategorized, categorized.Category)
+		})
+	}
+}
+
+func TestAIStrategy_UsesReportedConfidence(t *testing.T) {
+	client := NewExecClient(logging.NewMockLogger(), writeScript(t, `echo '{"category": "Leisure", "confidence": 0.42}'`), 5)
+	strategy := NewAIStrategy(client, logging.NewMockLogger())
+
+	category, found, err := strategy.Categorize(context.Background(), Transaction{PartyName: "Cinema Pathé", Amount: "25.00"})
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, "Leisure", category.Name)
+	assert.InDelta(t, 0.42, category.Confidence, 1e-9)
+
+	// Commands that fail leave the transaction to the next tier
+	strategy = NewAIStrategy(NewExecClient(logging.NewMockLogger(), writeScript(t, `exit 1`), 5), logging.NewMockLogger())
+	_, found, err = strategy.Categorize(context.Background(), Transaction{PartyName: "Cinema Pathé"})
+	require.NoError(t, err)
+	assert.False(t, found)
+}
@@ -32,6 +32,7 @@ type GeminiClient struct {
 	httpClient *http.Client
 	log        logging.Logger
 	limiter    *rate.Limiter
+	prompts    *PromptBuilder
 }
 
 // GeminiRequest represents the request structure for Gemini API
@@ -109,6 +110,7 @@ func NewGeminiClient(logger logging.Logger, requestsPerMinute int, model string,
 		},
 		log:     logger,
 		limiter: limiter,
+		prompts: defaultPromptBuilder(),
 	}
 }
 
@@ -122,7 +124,11 @@ func (c *GeminiClient) Categorize(ctx context.Context, transaction models.Transa
 	}
 
 	// Build the prompt for categorization
-	prompt := c.buildCategorizationPrompt(transaction)
+	prompt, err := c.buildCategorizationPrompt(transaction)
+	if err != nil {
+		transaction.Category = models.CategoryUncategorized
+		return transaction, err
+	}
 
 	c.log.WithFields(
 		logging.Field{Key: "operation", Value: "gemini_categorization"},
@@ -147,7 +153,7 @@ func (c *GeminiClient) Categorize(ctx context.Context, transaction models.Transa
 	}
 
 	// Clean and validate the category
-	category = c.cleanCategory(category)
+	category = extractCategoryFromResponse(category, c.prompts.Categories(), c.cleanCategory)
 	if category == "" || category == models.CategoryUncategorized {
 		c.log.WithFields(
 			logging.Field{Key: "party_name", Value: transaction.PartyName},
@@ -253,176 +259,17 @@ func (c *GeminiClient) callGeminiAPIWithRetry(ctx context.Context, prompt string
 	return "", lastErr
 }
 
-// buildCategorizationPrompt creates a prompt for the Gemini API to categorize the transaction
-
-func (c *GeminiClient) buildCategorizationPrompt(transaction models.Transaction) string {
-
-	prompt := fmt.Sprintf(`You are a financial transaction categorizer for a personal finance application.
-
-Your goal is to categorize the given transaction into ONE of the specific categories listed below.
-
-
-
-CATEGORIES (Strictly limit your answer to this list):
-
-- Abonnements
-
-- Activités
-
-- Alimentation (boucherie, boulangerie, traiteur - NOT supermarkets)
-
-- Allocations
-
-- Animaux
-
-- Assurance Maladie
-
-- Assurances
-
-- Autre
-
-- Bien-être (spa, massage)
-
-- Cadeaux
-
-- Courses (supermarkets like Migros, Coop, Aldi, Lidl)
-
-- Divers (cash withdrawals, pocket money)
-
-- Divertissement (movies, games)
-
-- Dons
-
-- Éducation
-
-- Enfants
-
-- Épargne
-
-- Équipement Maison (appliances, electronics for home)
-
-- Famille
-
-- Formation
-
-- Frais Bancaires
-
-- Hypothèques
-
-- Impôts
-
-- Investissements
-
-- Logement (rent, charges)
-
-- Loisirs (parks, museums, concerts)
-
-- Mobilier (furniture, decoration, IKEA)
-
-- Non Classé
-
-- Pension (retirement, AVS/AI)
-
-- Prêts
-
-- Restaurants (dining out, fast food, cafes)
-
-- Revenus Financiers
-
-- Revenus Locatifs
-
-- Revenus Professionnels
-
-- Salaire
-
-- Santé (doctors, pharmacy)
-
-- Séjours (short stays, weekends)
-
-- Services
-
-- Shopping (clothes, electronics, online)
-
-- Soins Personnels (hairdresser, cosmetics)
-
-- Sport
-
-- Taxes
-
-- Transferts
-
-- Transport Privé
-
-- Transports Publics
-
-- Utilités (electricity, phone, internet)
-
-- Vacances (travel, flights, hotels)
-
-- Virements
-
-- Voiture (fuel, parking, repairs)
-
-- Voyages (travel agency, cruises)
-
-
-
-TRICKY CASES / RULES:
-
-1. **Supermarkets**: "Migros", "Coop", "Denner", "Aldi" are **Courses**. They are NOT "Alimentation" (reserved for specialized food shops) or "Restaurants".
-
-2. **Restaurants**: "McDonalds", "Starbucks", "Restaurant X" are **Restaurants**.
-
-3. **AI & Tech**: "Claude.ai", "OpenAI", "ChatGPT", "Google One" are **Abonnements**.
-
-4. **Transport**: "SNCF", "CFF", "SBB" are **Transports Publics**. "Shell", "BP", "Parking" are **Voiture**.
-
-5. **Vacation**: "EasyJet", "Airbnb", "Booking.com" are **Vacances**.
-
-6. **Furniture vs Appliances**: "IKEA", "Conforama" are **Mobilier**. "Dyson", "Fust" are **Équipement Maison**.
-
-7. **Retirement**: "Pension" is ONLY for retirement funds.
-
-
-
-FEW-SHOT EXAMPLES:
-
-- Transaction: "OpenAI *ChatGPT", Amount: 20.00 -> Category: Abonnements
-
-- Transaction: "Coop Pronto", Amount: 15.50 -> Category: Courses
-
-- Transaction: "McDonalds", Amount: 24.90 -> Category: Restaurants
-
-- Transaction: "SBB CFF FFS Mobile Ticket", Amount: 5.60 -> Category: Transports Publics
-
-- Transaction: "Parking de la Gare", Amount: 3.00 -> Category: Voiture
-
-- Transaction: "IKEA AG", Amount: 150.00 -> Category: Mobilier
-
-- Transaction: "Zalando", Amount: 89.90 -> Category: Shopping
-
-- Transaction: "Retrait Bancomat", Amount: 100.00 -> Category: Divers
-
-- Transaction: "La Vaudoise Assurances", Amount: 450.00 -> Category: Assurances
-
-- Transaction: "EasyJet", Amount: 120.00 -> Category: Vacances
-
-
-
-TRANSACTION TO CATEGORIZE:
-
-Party: %s
-
-Description: %s
-
-Amount: %s CHF
-
-
-
-Category:`, transaction.PartyName, transaction.Description, transaction.Amount.String())
-
-	return prompt
+// buildCategorizationPrompt renders the configured prompt template for the transaction.
+func (c *GeminiClient) buildCategorizationPrompt(transaction models.Transaction) (string, error) {
+	return c.prompts.Build(transaction)
+}
 
+// SetPromptBuilder replaces the prompt template and the list of known categories
+// used to interpret responses.
+func (c *GeminiClient) SetPromptBuilder(pb *PromptBuilder) {
+	if pb != nil {
+		c.prompts = pb
+	}
 }
 
 // callGeminiAPI makes the actual API call to Gemini
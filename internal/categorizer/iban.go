@@ -0,0 +1,77 @@
+package categorizer
+
+import (
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+)
+
+// IBANStoreInterface is implemented by stores that provide counterparty IBAN
+// mappings. It is optional: a store without it simply maps no IBANs.
+type IBANStoreInterface interface {
+	LoadIBANMappings() (map[string]string, error)
+	SaveIBANMappings(mappings map[string]string) error
+}
+
+// loadIBANMappings loads the counterparty IBAN mappings from the store if it
+// supports them.
+func (c *Categorizer) loadIBANMappings() {
+	if c.ibanMappings == nil {
+		c.ibanMappings = make(map[string]string)
+	}
+	ibanStore, ok := c.store.(IBANStoreInterface)
+	if !ok {
+		return
+	}
+
+	mappings, err := ibanStore.LoadIBANMappings()
+	if err != nil {
+		c.logger.WithError(err).Warn("Failed to load IBAN mappings")
+		return
+	}
+	ibanMappings := make(map[string]string, len(mappings))
+	for iban, category := range mappings {
+		ibanMappings[models.NormalizeIBAN(iban)] = category
+	}
+	c.ibanMappings = ibanMappings
+	if len(mappings) > 0 {
+		c.logger.Debug("Loaded IBAN mappings",
+			logging.Field{Key: "count", Value: len(mappings)})
+	}
+}
+
+// CategoryForIBAN implements models.IBANCategorizer with the mappings of the
+// IBAN mappings file.
+func (c *Categorizer) CategoryForIBAN(iban string) (string, bool) {
+	c.configMutex.RLock()
+	defer c.configMutex.RUnlock()
+	category := c.ibanMappings[models.NormalizeIBAN(iban)]
+	return category, category != ""
+}
+
+// UpdateIBANCategory maps the counterparty IBAN iban to categoryName for this
+// categorizer instance. The mapping is written by SaveIBANMappingsToYAML.
+func (c *Categorizer) UpdateIBANCategory(iban, categoryName string) {
+	c.configMutex.Lock()
+	defer c.configMutex.Unlock()
+	c.ibanMappings[models.NormalizeIBAN(iban)] = categoryName
+	c.dirtyIBANs[models.NormalizeIBAN(iban)] = true
+}
+
+// SaveIBANMappingsToYAML saves the IBAN mappings set since the last save to
+// the YAML file.
+func (c *Categorizer) SaveIBANMappingsToYAML() error {
+	c.configMutex.Lock()
+	defer c.configMutex.Unlock()
+	if len(c.dirtyIBANs) == 0 {
+		return nil
+	}
+	ibanStore, ok := c.store.(IBANStoreInterface)
+	if !ok {
+		return nil
+	}
+	if err := ibanStore.SaveIBANMappings(learnedEntries(c.ibanMappings, c.dirtyIBANs)); err != nil {
+		return err
+	}
+	clear(c.dirtyIBANs)
+	return nil
+}
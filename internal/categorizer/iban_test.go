@@ -0,0 +1,66 @@
+package categorizer
+
+import (
+	"context"
+	"testing"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategorizer_CategoryForIBAN(t *testing.T) {
+	mockStore := &store.MockCategoryStore{
+		CreditorMappings: map[string]string{"regie du centre": "Services"},
+		IBANMappings:     map[string]string{"CH93 0076 2011 6238 5295 7": "Logement"},
+	}
+	cat := NewCategorizer(nil, mockStore, logging.NewMockLogger(), false, 0.70)
+
+	category, ok := cat.CategoryForIBAN("ch9300762011623852957")
+	assert.True(t, ok)
+	assert.Equal(t, "Logement", category)
+	_, ok = cat.CategoryForIBAN("CH5604835012345678009")
+	assert.False(t, ok)
+
+	// The IBAN wins over the party mapping, whatever the name says
+	tx := models.Transaction{PartyName: "REGIE DU CENTRE SA", PartyIBAN: "CH93 0076 2011 6238 5295 7"}
+	require.True(t, models.ApplyIBANMapping(&tx, cat))
+	assert.Equal(t, "Logement", tx.Category)
+	assert.Equal(t, models.CategorySourceIBAN, tx.CategorySource)
+
+	// Without a mapped IBAN, the party is categorized as before
+	result, err := cat.CategorizeTransaction(context.Background(), Transaction{PartyName: "Regie du Centre"})
+	require.NoError(t, err)
+	assert.Equal(t, "Services", result.Name)
+}
+
+func TestCategorizer_UpdateIBANCategory(t *testing.T) {
+	mockStore := &store.MockCategoryStore{}
+	cat := NewCategorizer(nil, mockStore, logging.NewMockLogger(), false, 0.70)
+
+	cat.UpdateIBANCategory("CH93 0076 2011 6238 5295 7", "Logement")
+	category, ok := cat.CategoryForIBAN("CH9300762011623852957")
+	assert.True(t, ok)
+	assert.Equal(t, "Logement", category)
+
+	require.NoError(t, cat.SaveMappings())
+	assert.Equal(t, map[string]string{"CH9300762011623852957": "Logement"}, mockStore.IBANMappings)
+
+	mockStore.SaveIBANMappingsError = assert.AnError
+	require.NoError(t, cat.SaveIBANMappingsToYAML(), "nothing changed since the last save")
+	cat.UpdateIBANCategory("CH5604835012345678009", "Épargne")
+	assert.ErrorIs(t, cat.SaveMappings(), assert.AnError)
+}
+
+func TestCategorizer_IBANLoadError(t *testing.T) {
+	mockStore := &store.MockCategoryStore{LoadIBANMappingsError: assert.AnError}
+	logger := logging.NewMockLogger()
+	cat := NewCategorizer(nil, mockStore, logger, false, 0.70)
+
+	_, ok := cat.CategoryForIBAN("CH9300762011623852957")
+	assert.False(t, ok)
+	assert.True(t, logger.HasEntry("WARN", "Failed to load IBAN mappings"))
+}
@@ -0,0 +1,160 @@
+package categorizer
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"unicode"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+)
+
+// InternalPartyStoreInterface is implemented by stores that provide the
+// user's internal party names. It is optional: a store without it simply
+// yields no internal parties.
+type InternalPartyStoreInterface interface {
+	LoadInternalParties() (models.InternalPartiesConfig, error)
+}
+
+// InternalPartyStrategy categorizes transactions with the user's own parties
+// (own accounts, household members) as internal transfers. It runs before all
+// other strategies so that such transfers never end up as income or spending.
+type InternalPartyStrategy struct {
+	names    []string // Normalized internal party names
+	category string
+	logger   logging.Logger
+	mu       sync.RWMutex // Protects names and category
+}
+
+// NewInternalPartyStrategy creates a new InternalPartyStrategy. Transactions
+// matching config.Names are assigned config.Category, or models.CategoryTransfers
+// when it is empty.
+func NewInternalPartyStrategy(config models.InternalPartiesConfig, logger logging.Logger) *InternalPartyStrategy {
+	s := &InternalPartyStrategy{logger: logger}
+	s.SetInternalParties(config)
+	return s
+}
+
+// SetInternalParties replaces the internal party names and category, as when
+// the categories file is reloaded.
+func (s *InternalPartyStrategy) SetInternalParties(config models.InternalPartiesConfig) {
+	names := make([]string, 0, len(config.Names))
+	for _, name := range config.Names {
+		if normalized := normalizePartyName(name); normalized != "" {
+			names = append(names, normalized)
+		}
+	}
+
+	s.mu.Lock()
+	s.names = names
+	s.category = internalTransferCategory(config)
+	s.mu.Unlock()
+}
+
+// internalTransferCategory returns the category configured for internal
+// transfers, or models.CategoryTransfers when there is none.
+func internalTransferCategory(config models.InternalPartiesConfig) string {
+	if category := strings.TrimSpace(config.Category); category != "" {
+		return category
+	}
+	return models.CategoryTransfers
+}
+
+// Name returns the name of this strategy for logging and debugging.
+func (s *InternalPartyStrategy) Name() string {
+	return "InternalParty"
+}
+
+// Categorize assigns the transfer category when the party name contains one of
+// the internal party names as whole words, ignoring case and punctuation.
+func (s *InternalPartyStrategy) Categorize(ctx context.Context, tx Transaction) (models.Category, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.names) == 0 {
+		return models.Category{}, false, nil
+	}
+
+	// Pad with spaces so that names only match on word boundaries
+	party := " " + normalizePartyName(tx.PartyName) + " "
+	for _, name := range s.names {
+		if strings.Contains(party, " "+name+" ") {
+			s.logger.WithFields(
+				logging.Field{Key: "strategy", Value: s.Name()},
+				logging.Field{Key: "party", Value: tx.PartyName},
+				logging.Field{Key: "category", Value: s.category},
+			).Debug("Transaction categorized as internal transfer")
+			return models.Category{
+				Name:        s.category,
+				Description: "Transfer between own accounts",
+				Confidence:  1.0,
+				Source:      models.CategorySourceInternal,
+			}, true, nil
+		}
+	}
+
+	return models.Category{}, false, nil
+}
+
+// normalizePartyName lowercases name, replaces punctuation with spaces and
+// collapses runs of whitespace.
+func normalizePartyName(name string) string {
+	mapped := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, name)
+	return strings.Join(strings.Fields(mapped), " ")
+}
+
+// loadInternalParties loads the internal party settings from the store if it
+// supports them. It reports false, leaving the current settings, when there
+// are none to load.
+func (c *Categorizer) loadInternalParties() (models.InternalPartiesConfig, bool) {
+	internalStore, ok := c.store.(InternalPartyStoreInterface)
+	if !ok {
+		return models.InternalPartiesConfig{}, false
+	}
+
+	config, err := internalStore.LoadInternalParties()
+	if err != nil {
+		c.logger.WithError(err).Warn("Failed to load internal parties")
+		return models.InternalPartiesConfig{}, false
+	}
+	c.excludeInternalFromStats = config.ExcludeFromStats
+	c.internalCategory = internalTransferCategory(config)
+	c.ownIBANs = make(map[string]bool, len(config.OwnIBANs))
+	for _, iban := range config.OwnIBANs {
+		if normalized := models.NormalizeIBAN(iban); normalized != "" {
+			c.ownIBANs[normalized] = true
+		}
+	}
+	return config, true
+}
+
+// IsOwnAccount implements models.OwnAccountMatcher with the own_ibans of the
+// categories file.
+func (c *Categorizer) IsOwnAccount(iban string) bool {
+	c.configMutex.RLock()
+	defer c.configMutex.RUnlock()
+	return c.ownIBANs[models.NormalizeIBAN(iban)]
+}
+
+// InternalTransferCategory implements models.OwnAccountMatcher.
+func (c *Categorizer) InternalTransferCategory() string {
+	c.configMutex.RLock()
+	defer c.configMutex.RUnlock()
+	if c.internalCategory == "" {
+		return models.CategoryTransfers
+	}
+	return c.internalCategory
+}
+
+// ExcludeFromStats implements models.StatsExcluder. Internal transfers are
+// excluded when the categories file sets exclude_internal_transfers_from_stats.
+func (c *Categorizer) ExcludeFromStats(category models.Category) bool {
+	c.configMutex.RLock()
+	defer c.configMutex.RUnlock()
+	return c.excludeInternalFromStats && category.Source == models.CategorySourceInternal
+}
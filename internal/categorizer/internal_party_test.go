@@ -0,0 +1,111 @@
+package categorizer
+
+import (
+	"context"
+	"testing"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePartyName(t *testing.T) {
+	assert.Equal(t, "jacquet florence c i", normalizePartyName("  JACQUET Florence C.I. "))
+	assert.Equal(t, "frédéric jacquet", normalizePartyName("Frédéric-Jacquet"))
+	assert.Equal(t, "", normalizePartyName(" ., "))
+}
+
+func TestInternalPartyStrategy_Categorize(t *testing.T) {
+	s := NewInternalPartyStrategy(models.InternalPartiesConfig{
+		Names:    []string{"Florence Jacquet", "JACQUET FREDERIC", " "},
+		Category: "Virements",
+	}, logging.NewMockLogger())
+
+	tests := []struct {
+		party string
+		found bool
+	}{
+		{"FLORENCE JACQUET", true},
+		{"Payment from Jacquet, Frederic", true},
+		{"florence  jacquet-pagan", true},
+		{"Florence Jacquetti", false},
+		{"Migros", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.party, func(t *testing.T) {
+			category, found, err := s.Categorize(context.Background(), Transaction{PartyName: tt.party})
+			require.NoError(t, err)
+			assert.Equal(t, tt.found, found)
+			if tt.found {
+				assert.Equal(t, "Virements", category.Name)
+			}
+		})
+	}
+
+	// Without a configured category, the generic transfers category is used
+	s = NewInternalPartyStrategy(models.InternalPartiesConfig{Names: []string{"Jane Doe"}}, logging.NewMockLogger())
+	category, found, err := s.Categorize(context.Background(), Transaction{PartyName: "JANE DOE"})
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, models.CategoryTransfers, category.Name)
+}
+
+func TestCategorizer_InternalParties(t *testing.T) {
+	mockStore := &store.MockCategoryStore{
+		// Internal parties take precedence over existing mappings
+		DebtorMappings: map[string]string{"jane doe": "Family"},
+		InternalParties: models.InternalPartiesConfig{
+			Names:            []string{"Jane Doe"},
+			Category:         "Virements",
+			ExcludeFromStats: true,
+		},
+	}
+	cat := NewCategorizer(nil, mockStore, logging.NewMockLogger(), true, 0.70)
+
+	category, err := cat.Categorize(context.Background(), "Jane DOE", true, "100.00", "2025-01-15", "")
+	require.NoError(t, err)
+	assert.Equal(t, "Virements", category.Name)
+	assert.Equal(t, models.CategorySourceInternal, category.Source)
+	assert.True(t, cat.ExcludeFromStats(category))
+
+	// Internal transfers are not auto-learned
+	assert.Equal(t, map[string]string{"jane doe": "Family"}, mockStore.DebtorMappings)
+
+	assert.False(t, cat.ExcludeFromStats(models.Category{Name: "Virements", Source: models.CategorySourceMapping}))
+}
+
+func TestCategorizer_InternalPartiesCountedInStatsByDefault(t *testing.T) {
+	mockStore := &store.MockCategoryStore{
+		InternalParties: models.InternalPartiesConfig{Names: []string{"Jane Doe"}},
+	}
+	cat := NewCategorizer(nil, mockStore, logging.NewMockLogger(), false, 0.70)
+
+	category, err := cat.Categorize(context.Background(), "Jane Doe", false, "100.00", "2025-01-15", "")
+	require.NoError(t, err)
+	assert.Equal(t, models.CategoryTransfers, category.Name)
+	assert.False(t, cat.ExcludeFromStats(category))
+}
+
+func TestCategorizer_OwnAccounts(t *testing.T) {
+	mockStore := &store.MockCategoryStore{
+		InternalParties: models.InternalPartiesConfig{
+			OwnIBANs: []string{"CH93 0076 2011 6238 5295 7", " "},
+			Category: "Virements",
+		},
+	}
+	cat := NewCategorizer(nil, mockStore, logging.NewMockLogger(), false, 0.70)
+
+	assert.True(t, cat.IsOwnAccount("ch9300762011623852957"))
+	assert.False(t, cat.IsOwnAccount("CH5604835012345678009"))
+	assert.False(t, cat.IsOwnAccount(""))
+	assert.Equal(t, "Virements", cat.InternalTransferCategory())
+
+	// Without a configured category, the generic transfers category is used
+	cat = NewCategorizer(nil, &store.MockCategoryStore{}, logging.NewMockLogger(), false, 0.70)
+	assert.Equal(t, models.CategoryTransfers, cat.InternalTransferCategory())
+}
@@ -3,6 +3,7 @@ package categorizer
 import (
 	"context"
 	"strings"
+	"sync"
 
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
@@ -14,6 +15,7 @@ type KeywordStrategy struct {
 	categories []models.CategoryConfig
 	store      CategoryStoreInterface
 	logger     logging.Logger
+	mu         sync.RWMutex // Protects categories
 }
 
 // NewKeywordStrategy creates a new KeywordStrategy instance.
@@ -44,8 +46,19 @@ func (s *KeywordStrategy) Categorize(ctx context.Context, tx Transaction) (model
 	partyName := strings.ToUpper(tx.PartyName)
 	description := strings.ToUpper(tx.Info)
 
-	// Try to match against category keywords
-	for _, categoryConfig := range s.categories {
+	s.mu.RLock()
+	categories := s.categories
+	s.mu.RUnlock()
+
+	amount := models.ParseAmount(tx.Amount)
+
+	// Categories and their keywords are tried in file order and the first match
+	// wins, so overlapping keywords always resolve to the same category
+	for _, categoryConfig := range categories {
+		// Categories with amount bounds only apply to amounts within them
+		if !categoryConfig.AmountInRange(amount) {
+			continue
+		}
 		for _, keyword := range categoryConfig.Keywords {
 			// Performance optimization: Use helper function to minimize allocations in keyword matching loop
 			keywordUpper := strings.ToUpper(keyword)
@@ -63,7 +76,7 @@ func (s *KeywordStrategy) Categorize(ctx context.Context, tx Transaction) (model
 					Name:        categoryConfig.Name,
 					Description: categoryDescriptionFromName(categoryConfig.Name),
 					Confidence:  0.95, // High confidence for keyword matches
-					Source:      "keyword",
+					Source:      models.CategorySourceKeyword,
 				}
 
 				return category, true, nil
@@ -74,13 +87,21 @@ func (s *KeywordStrategy) Categorize(ctx context.Context, tx Transaction) (model
 	return models.Category{}, false, nil
 }
 
+// SetCategories replaces the categories matched against, as when the
+// categories file is reloaded.
+func (s *KeywordStrategy) SetCategories(categories []models.CategoryConfig) {
+	s.mu.Lock()
+	s.categories = categories
+	s.mu.Unlock()
+}
+
 // loadCategories loads category configurations from the store.
 func (s *KeywordStrategy) loadCategories() {
 	categories, err := s.store.LoadCategories()
 	if err != nil {
 		s.logger.WithError(err).Warn("Failed to load categories for KeywordStrategy")
 	} else {
-		s.categories = categories
+		s.SetCategories(categories)
 		s.logger.WithField("count", len(categories)).Debug("Loaded categories for KeywordStrategy")
 	}
 }
@@ -8,6 +8,7 @@ import (
 	"fjacquet/camt-csv/internal/models"
 	"fjacquet/camt-csv/internal/store"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -228,6 +229,59 @@ func TestKeywordStrategy_Categorize(t *testing.T) {
 	}
 }
 
+func TestKeywordStrategy_FirstMatchInFileOrder(t *testing.T) {
+	categories := []models.CategoryConfig{
+		{Name: "Courses", Keywords: []string{"MIGROS"}},
+		{Name: "Restaurants", Keywords: []string{"MIGROS RESTAURANT"}},
+		{Name: "Loisirs", Keywords: []string{"RESTAURANT"}},
+	}
+	strategy := NewKeywordStrategy(categories, nil, &logging.MockLogger{})
+	transaction := Transaction{PartyName: "Migros Restaurant Lausanne"}
+
+	// Overlapping keywords always resolve to the first category listed
+	for i := 0; i < 20; i++ {
+		category, found, err := strategy.Categorize(context.Background(), transaction)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, "Courses", category.Name)
+	}
+}
+
+func TestKeywordStrategy_AmountBounds(t *testing.T) {
+	maxGroceries := decimal.NewFromInt(80)
+	minShopping := decimal.RequireFromString("80.01")
+	categories := []models.CategoryConfig{
+		{Name: "Courses", Keywords: []string{"COOP"}, MaxAmount: &maxGroceries},
+		{Name: "Shopping", Keywords: []string{"COOP"}, MinAmount: &minShopping},
+		{Name: "Transport", Keywords: []string{"SBB"}},
+	}
+	strategy := NewKeywordStrategy(categories, nil, &logging.MockLogger{})
+
+	tests := []struct {
+		party  string
+		amount string
+		want   string
+	}{
+		{"Coop Pronto", "12.50", "Courses"},
+		{"Coop Pronto", "80.00", "Courses"},
+		{"Coop City", "-80.01", "Shopping"},
+		{"Coop City", "249.90", "Shopping"},
+		{"SBB CFF FFS", "1200", "Transport"},
+	}
+	for _, tt := range tests {
+		category, found, err := strategy.Categorize(context.Background(), Transaction{PartyName: tt.party, Amount: tt.amount})
+		require.NoError(t, err)
+		require.True(t, found, "%s %s", tt.party, tt.amount)
+		assert.Equal(t, tt.want, category.Name, "%s %s", tt.party, tt.amount)
+	}
+
+	// Outside every bound the keyword does not match
+	strategy = NewKeywordStrategy(categories[:1], nil, &logging.MockLogger{})
+	_, found, err := strategy.Categorize(context.Background(), Transaction{PartyName: "Coop City", Amount: "150"})
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
 func TestKeywordStrategy_ReloadCategories(t *testing.T) {
 	// Create mock store with initial categories
 	mockStore := &store.MockCategoryStore{
@@ -0,0 +1,96 @@
+package categorizer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MatchMode selects how party names are matched against the creditor and
+// debtor mapping keys once an exact match has failed.
+type MatchMode string
+
+const (
+	// MatchModeExact only matches party names equal to a mapping key.
+	MatchModeExact MatchMode = "exact"
+	// MatchModeToken also matches a key appearing as whole words in the
+	// party name: "COOP" matches "COOP PRONTO ZURICH" but not "SCOOPER".
+	MatchModeToken MatchMode = "token"
+	// MatchModeSubstring also matches a key appearing anywhere in the party
+	// name, including inside a word: "COOP" matches "SCOOPER".
+	MatchModeSubstring MatchMode = "substring"
+)
+
+// ParseMatchMode returns the MatchMode named by s, case-insensitively. An
+// empty string selects MatchModeExact.
+func ParseMatchMode(s string) (MatchMode, error) {
+	switch mode := MatchMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case "":
+		return MatchModeExact, nil
+	case MatchModeExact, MatchModeToken, MatchModeSubstring:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown match mode %q (supported: exact, token, substring)", s)
+	}
+}
+
+// partialMatch returns the mapping key, and its category, that matches
+// partyName under mode. The keys and partyName must be lowercase. When several
+// keys match, the longest wins, then the first in alphabetical order, so that
+// the result does not depend on map iteration. Failed AI categorizations are
+// never matched.
+func partialMatch(mappings map[string]string, partyName string, mode MatchMode) (string, string, bool) {
+	if mode != MatchModeToken && mode != MatchModeSubstring {
+		return "", "", false
+	}
+
+	var candidates []string
+	for key, category := range mappings {
+		if key == "" || category == "Uncategorized (AI)" {
+			continue
+		}
+		if mode == MatchModeSubstring && strings.Contains(partyName, key) ||
+			mode == MatchModeToken && containsToken(partyName, key) {
+			candidates = append(candidates, key)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", "", false
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if len(candidates[i]) != len(candidates[j]) {
+			return len(candidates[i]) > len(candidates[j])
+		}
+		return candidates[i] < candidates[j]
+	})
+	return candidates[0], mappings[candidates[0]], true
+}
+
+// containsToken reports whether key appears in s delimited by word
+// boundaries: the start or end of s, or a character that is neither a letter
+// nor a digit.
+func containsToken(s, key string) bool {
+	for offset := 0; offset < len(s); {
+		i := strings.Index(s[offset:], key)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(key)
+		before, _ := utf8.DecodeLastRuneInString(s[:start])
+		after, _ := utf8.DecodeRuneInString(s[end:])
+		if (start == 0 || !isWordRune(before)) && (end == len(s) || !isWordRune(after)) {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(s[start:])
+		offset = start + size
+	}
+	return false
+}
+
+// isWordRune reports whether r is part of a word.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,42 @@
+package categorizer
+
+import (
+	"strings"
+
+	"fjacquet/camt-csv/internal/logging"
+)
+
+// MCCStoreInterface is implemented by stores that provide merchant category
+// code mappings. It is optional: a store without it simply maps no codes.
+type MCCStoreInterface interface {
+	LoadMCCMappings() (map[string]string, error)
+}
+
+// loadMCCMappings loads the merchant category code mappings from the store if
+// it supports them.
+func (c *Categorizer) loadMCCMappings() {
+	mccStore, ok := c.store.(MCCStoreInterface)
+	if !ok {
+		return
+	}
+
+	mappings, err := mccStore.LoadMCCMappings()
+	if err != nil {
+		c.logger.WithError(err).Warn("Failed to load MCC mappings")
+		return
+	}
+	c.mccMappings = mappings
+	if len(mappings) > 0 {
+		c.logger.Debug("Loaded MCC mappings",
+			logging.Field{Key: "count", Value: len(mappings)})
+	}
+}
+
+// CategoryForMCC implements models.MCCCategorizer with the mappings of the
+// MCC file.
+func (c *Categorizer) CategoryForMCC(mcc string) (string, bool) {
+	c.configMutex.RLock()
+	defer c.configMutex.RUnlock()
+	category := strings.TrimSpace(c.mccMappings[strings.TrimSpace(mcc)])
+	return category, category != ""
+}
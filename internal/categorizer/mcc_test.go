@@ -0,0 +1,50 @@
+package categorizer
+
+import (
+	"context"
+	"testing"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategorizer_CategoryForMCC(t *testing.T) {
+	mockStore := &store.MockCategoryStore{
+		CreditorMappings: map[string]string{"migros": "Shopping"},
+		MCCMappings:      map[string]string{"5411": "Alimentation", "5812": "Restaurants"},
+	}
+	cat := NewCategorizer(nil, mockStore, logging.NewMockLogger(), false, 0.70)
+
+	category, ok := cat.CategoryForMCC("5411")
+	assert.True(t, ok)
+	assert.Equal(t, "Alimentation", category)
+	category, ok = cat.CategoryForMCC(" 5812 ")
+	assert.True(t, ok)
+	assert.Equal(t, "Restaurants", category)
+	_, ok = cat.CategoryForMCC("4111")
+	assert.False(t, ok)
+
+	// The code wins over the party mapping
+	tx := models.Transaction{PartyName: "Migros", MCC: "5411"}
+	require.True(t, models.ApplyMCC(&tx, cat))
+	assert.Equal(t, "Alimentation", tx.Category)
+
+	// Without a code, the party is categorized as before
+	result, err := cat.CategorizeTransaction(context.Background(), Transaction{PartyName: "Migros"})
+	require.NoError(t, err)
+	assert.Equal(t, "Shopping", result.Name)
+}
+
+func TestCategorizer_MCCLoadError(t *testing.T) {
+	mockStore := &store.MockCategoryStore{LoadMCCMappingsError: assert.AnError}
+	logger := logging.NewMockLogger()
+	cat := NewCategorizer(nil, mockStore, logger, false, 0.70)
+
+	_, ok := cat.CategoryForMCC("5411")
+	assert.False(t, ok)
+	assert.True(t, logger.HasEntry("WARN", "Failed to load MCC mappings"))
+}
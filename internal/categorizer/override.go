@@ -0,0 +1,66 @@
+package categorizer
+
+import (
+	"strings"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+)
+
+// SetCategoryOverrides forces the parties of overrides, party name to
+// category, into their category for this run, whatever the mapping files,
+// rules or AI say. Party names are matched case-insensitively, as in the
+// mapping files. With persist, each override is also recorded as a creditor
+// and a debitor mapping, written by SaveMappings; otherwise nothing is saved.
+func (c *Categorizer) SetCategoryOverrides(overrides map[string]string, persist bool) {
+	normalized := make(map[string]string, len(overrides))
+	for party, category := range overrides {
+		normalized[strings.ToLower(party)] = category
+	}
+
+	c.configMutex.Lock()
+	c.overrides = normalized
+	c.configMutex.Unlock()
+
+	// Parties categorized before the overrides were set must not be served
+	// from the cache
+	c.batchCacheMu.Lock()
+	clear(c.batchCache)
+	c.batchCacheMu.Unlock()
+
+	if persist {
+		for party, category := range overrides {
+			c.updateCreditorCategory(party, category)
+			c.updateDebitorCategory(party, category)
+		}
+	}
+	if len(overrides) > 0 {
+		c.logger.Debug("Category overrides set",
+			logging.Field{Key: "count", Value: len(overrides)},
+			logging.Field{Key: "persist", Value: persist})
+	}
+}
+
+// CategoryOverride implements models.CategoryOverrider with the overrides
+// set by SetCategoryOverrides.
+func (c *Categorizer) CategoryOverride(party string) (string, bool) {
+	c.configMutex.RLock()
+	defer c.configMutex.RUnlock()
+	category, ok := c.overrides[strings.ToLower(party)]
+	return category, ok
+}
+
+// overrideCategory returns the category of transaction's party from the
+// per-run overrides, if it has one.
+func (c *Categorizer) overrideCategory(transaction Transaction) (models.Category, bool) {
+	name, ok := c.CategoryOverride(transaction.PartyName)
+	if !ok {
+		return models.Category{}, false
+	}
+	return models.Category{
+		Name:        name,
+		Description: "Category override for this run",
+		Confidence:  1.0,
+		Source:      models.CategorySourceOverride,
+	}, true
+}
@@ -0,0 +1,76 @@
+package categorizer
+
+import (
+	"context"
+	"testing"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategorizer_CategoryOverrides(t *testing.T) {
+	mockStore := &store.MockCategoryStore{
+		CreditorMappings: map[string]string{"migros": "Alimentation"},
+	}
+	cat := NewCategorizer(nil, mockStore, logging.NewMockLogger(), true, 0.70)
+
+	// Categorized before the override: must not be served from the batch cache
+	result, err := cat.Categorize(context.Background(), "Migros", false, "10.00", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "Alimentation", result.Name)
+	require.NoError(t, cat.SaveMappings())
+	mockStore.SaveCreditorMappingsError = assert.AnError
+	mockStore.SaveDebtorMappingsError = assert.AnError
+
+	cat.SetCategoryOverrides(map[string]string{"Migros": "Loisirs", "Jean Dupont": "Cadeaux"}, false)
+
+	// The override beats the creditor mapping, matched as the store matches names
+	result, err = cat.Categorize(context.Background(), "MIGROS", false, "10.00", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "Loisirs", result.Name)
+	assert.Equal(t, models.CategorySourceOverride, result.Source)
+
+	// Overrides apply to both directions
+	result, err = cat.Categorize(context.Background(), "jean dupont", true, "50.00", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "Cadeaux", result.Name)
+
+	// Nothing was learned, even with auto-learn enabled
+	require.NoError(t, cat.SaveMappings(), "no mapping file is written")
+
+	// Before any other categorization in the parsing pipelines
+	tx := models.Transaction{PartyName: "Migros", Category: "Alimentation", CategorySource: models.CategorySourceMCC}
+	require.True(t, models.ApplyCategoryOverride(&tx, cat))
+	assert.Equal(t, "Loisirs", tx.Category)
+	assert.Equal(t, models.CategorySourceOverride, tx.CategorySource)
+}
+
+func TestCategorizer_PersistedCategoryOverrides(t *testing.T) {
+	mockStore := &store.MockCategoryStore{
+		CreditorMappings: map[string]string{"migros": "Alimentation"},
+	}
+	cat := NewCategorizer(nil, mockStore, logging.NewMockLogger(), false, 0.70)
+
+	cat.SetCategoryOverrides(map[string]string{"Migros": "Loisirs"}, true)
+	require.NoError(t, cat.SaveMappings())
+	assert.Equal(t, "Loisirs", mockStore.CreditorMappings["migros"])
+	assert.Equal(t, "Loisirs", mockStore.DebtorMappings["migros"])
+}
+
+func TestCategorizer_CategoryOverrideAudit(t *testing.T) {
+	cat := NewCategorizer(nil, &store.MockCategoryStore{}, logging.NewMockLogger(), false, 0.70)
+	audit := logging.NewMockLogger()
+	cat.SetAuditLogger(audit)
+	cat.SetCategoryOverrides(map[string]string{"Migros": "Loisirs"}, false)
+
+	_, err := cat.Categorize(context.Background(), "Migros", false, "10.00", "", "")
+	require.NoError(t, err)
+
+	entries := audit.GetEntries()
+	require.Len(t, entries, 1)
+	assert.Contains(t, entries[0].Fields, logging.Field{Key: "method", Value: "override"})
+}
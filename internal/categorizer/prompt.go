@@ -0,0 +1,511 @@
+package categorizer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"fjacquet/camt-csv/internal/models"
+)
+
+// Supported built-in prompt languages.
+const (
+	PromptLanguageEnglish = "en"
+	PromptLanguageFrench  = "fr"
+)
+
+// defaultBaseCurrency is the currency assumed for transactions that carry
+// none, unless SetBaseCurrency configures another.
+const defaultBaseCurrency = "CHF"
+
+// builtinPrompts holds the categorization prompt templates shipped with the binary.
+// Category names stay in French in every language because they are the values
+// written to the output files.
+var builtinPrompts = map[string]string{
+	PromptLanguageEnglish: `You are a financial transaction categorizer for a personal finance application.
+
+Your goal is to categorize the given transaction into ONE of the specific categories listed below.
+
+
+
+CATEGORIES (Strictly limit your answer to this list):
+
+- Abonnements
+
+- Activités
+
+- Alimentation (boucherie, boulangerie, traiteur - NOT supermarkets)
+
+- Allocations
+
+- Animaux
+
+- Assurance Maladie
+
+- Assurances
+
+- Autre
+
+- Bien-être (spa, massage)
+
+- Cadeaux
+
+- Courses (supermarkets like Migros, Coop, Aldi, Lidl)
+
+- Divers (cash withdrawals, pocket money)
+
+- Divertissement (movies, games)
+
+- Dons
+
+- Éducation
+
+- Enfants
+
+- Épargne
+
+- Équipement Maison (appliances, electronics for home)
+
+- Famille
+
+- Formation
+
+- Frais Bancaires
+
+- Hypothèques
+
+- Impôts
+
+- Investissements
+
+- Logement (rent, charges)
+
+- Loisirs (parks, museums, concerts)
+
+- Mobilier (furniture, decoration, IKEA)
+
+- Non Classé
+
+- Pension (retirement, AVS/AI)
+
+- Prêts
+
+- Restaurants (dining out, fast food, cafes)
+
+- Revenus Financiers
+
+- Revenus Locatifs
+
+- Revenus Professionnels
+
+- Salaire
+
+- Santé (doctors, pharmacy)
+
+- Séjours (short stays, weekends)
+
+- Services
+
+- Shopping (clothes, electronics, online)
+
+- Soins Personnels (hairdresser, cosmetics)
+
+- Sport
+
+- Taxes
+
+- Transferts
+
+- Transport Privé
+
+- Transports Publics
+
+- Utilités (electricity, phone, internet)
+
+- Vacances (travel, flights, hotels)
+
+- Virements
+
+- Voiture (fuel, parking, repairs)
+
+- Voyages (travel agency, cruises)
+
+
+
+TRICKY CASES / RULES:
+
+1. **Supermarkets**: "Migros", "Coop", "Denner", "Aldi" are **Courses**. They are NOT "Alimentation" (reserved for specialized food shops) or "Restaurants".
+
+2. **Restaurants**: "McDonalds", "Starbucks", "Restaurant X" are **Restaurants**.
+
+3. **AI & Tech**: "Claude.ai", "OpenAI", "ChatGPT", "Google One" are **Abonnements**.
+
+4. **Transport**: "SNCF", "CFF", "SBB" are **Transports Publics**. "Shell", "BP", "Parking" are **Voiture**.
+
+5. **Vacation**: "EasyJet", "Airbnb", "Booking.com" are **Vacances**.
+
+6. **Furniture vs Appliances**: "IKEA", "Conforama" are **Mobilier**. "Dyson", "Fust" are **Équipement Maison**.
+
+7. **Retirement**: "Pension" is ONLY for retirement funds.
+
+
+
+FEW-SHOT EXAMPLES:
+
+- Transaction: "OpenAI *ChatGPT", Amount: 20.00 -> Category: Abonnements
+
+- Transaction: "Coop Pronto", Amount: 15.50 -> Category: Courses
+
+- Transaction: "McDonalds", Amount: 24.90 -> Category: Restaurants
+
+- Transaction: "SBB CFF FFS Mobile Ticket", Amount: 5.60 -> Category: Transports Publics
+
+- Transaction: "Parking de la Gare", Amount: 3.00 -> Category: Voiture
+
+- Transaction: "IKEA AG", Amount: 150.00 -> Category: Mobilier
+
+- Transaction: "Zalando", Amount: 89.90 -> Category: Shopping
+
+- Transaction: "Retrait Bancomat", Amount: 100.00 -> Category: Divers
+
+- Transaction: "La Vaudoise Assurances", Amount: 450.00 -> Category: Assurances
+
+- Transaction: "EasyJet", Amount: 120.00 -> Category: Vacances
+
+
+
+TRANSACTION TO CATEGORIZE:
+
+Party: {{.Party}}
+
+Description: {{.Description}}
+
+Amount: {{.Amount}} CHF
+
+
+
+Category:`,
+	PromptLanguageFrench: `Tu es un assistant de catégorisation de transactions financières pour une application de finances personnelles.
+
+Ton objectif est de classer la transaction ci-dessous dans UNE SEULE des catégories listées.
+
+
+
+CATÉGORIES (réponds strictement avec un nom de cette liste) :
+
+- Abonnements
+
+- Activités
+
+- Alimentation (boucherie, boulangerie, traiteur - PAS les supermarchés)
+
+- Allocations
+
+- Animaux
+
+- Assurance Maladie
+
+- Assurances
+
+- Autre
+
+- Bien-être (spa, massage)
+
+- Cadeaux
+
+- Courses (supermarchés comme Migros, Coop, Aldi, Lidl)
+
+- Divers (retraits d'espèces, argent de poche)
+
+- Divertissement (cinéma, jeux)
+
+- Dons
+
+- Éducation
+
+- Enfants
+
+- Épargne
+
+- Équipement Maison (électroménager, électronique pour la maison)
+
+- Famille
+
+- Formation
+
+- Frais Bancaires
+
+- Hypothèques
+
+- Impôts
+
+- Investissements
+
+- Logement (loyer, charges)
+
+- Loisirs (parcs, musées, concerts)
+
+- Mobilier (meubles, décoration, IKEA)
+
+- Non Classé
+
+- Pension (retraite, AVS/AI)
+
+- Prêts
+
+- Restaurants (repas à l'extérieur, fast-food, cafés)
+
+- Revenus Financiers
+
+- Revenus Locatifs
+
+- Revenus Professionnels
+
+- Salaire
+
+- Santé (médecins, pharmacie)
+
+- Séjours (courts séjours, week-ends)
+
+- Services
+
+- Shopping (vêtements, électronique, achats en ligne)
+
+- Soins Personnels (coiffeur, cosmétiques)
+
+- Sport
+
+- Taxes
+
+- Transferts
+
+- Transport Privé
+
+- Transports Publics
+
+- Utilités (électricité, téléphone, internet)
+
+- Vacances (voyages, vols, hôtels)
+
+- Virements
+
+- Voiture (carburant, parking, réparations)
+
+- Voyages (agence de voyage, croisières)
+
+
+
+CAS PARTICULIERS / RÈGLES :
+
+1. **Supermarchés** : "Migros", "Coop", "Denner", "Aldi" sont des **Courses**. Ce ne sont PAS de l'"Alimentation" (réservée aux commerces spécialisés) ni des "Restaurants".
+
+2. **Restaurants** : "McDonalds", "Starbucks", "Restaurant X" sont des **Restaurants**.
+
+3. **IA & Tech** : "Claude.ai", "OpenAI", "ChatGPT", "Google One" sont des **Abonnements**.
+
+4. **Transport** : "SNCF", "CFF", "SBB" sont des **Transports Publics**. "Shell", "BP", "Parking" relèvent de **Voiture**.
+
+5. **Vacances** : "EasyJet", "Airbnb", "Booking.com" relèvent de **Vacances**.
+
+6. **Meubles ou électroménager** : "IKEA", "Conforama" relèvent de **Mobilier**. "Dyson", "Fust" relèvent de **Équipement Maison**.
+
+7. **Retraite** : "Pension" est réservé UNIQUEMENT aux caisses de retraite.
+
+
+
+EXEMPLES :
+
+- Transaction : "OpenAI *ChatGPT", Montant : 20.00 -> Catégorie : Abonnements
+
+- Transaction : "Coop Pronto", Montant : 15.50 -> Catégorie : Courses
+
+- Transaction : "McDonalds", Montant : 24.90 -> Catégorie : Restaurants
+
+- Transaction : "SBB CFF FFS Mobile Ticket", Montant : 5.60 -> Catégorie : Transports Publics
+
+- Transaction : "Parking de la Gare", Montant : 3.00 -> Catégorie : Voiture
+
+- Transaction : "IKEA AG", Montant : 150.00 -> Catégorie : Mobilier
+
+- Transaction : "Zalando", Montant : 89.90 -> Catégorie : Shopping
+
+- Transaction : "Retrait Bancomat", Montant : 100.00 -> Catégorie : Divers
+
+- Transaction : "La Vaudoise Assurances", Montant : 450.00 -> Catégorie : Assurances
+
+- Transaction : "EasyJet", Montant : 120.00 -> Catégorie : Vacances
+
+
+
+Réponds uniquement avec le nom exact de la catégorie, sans explication.
+
+
+
+TRANSACTION À CATÉGORISER :
+
+Partie : {{.Party}}
+
+Description : {{.Description}}
+
+Montant : {{.Amount}} {{.Currency}}
+
+
+
+Catégorie :`,
+}
+
+// categoryLinePattern extracts category names from the "- Name (hint)" lines of
+// the built-in prompt.
+var categoryLinePattern = regexp.MustCompile(`(?m)^- ([^(\n"]+?)(?: \(.*\))?$`)
+
+// PromptData is the data passed to a categorization prompt template.
+// Custom templates may reference any of these fields, e.g. {{.Party}} or
+// {{range .Categories}}- {{.}}{{"\n"}}{{end}}.
+type PromptData struct {
+	Party       string
+	Description string
+	Amount      string
+	Currency    string
+	Categories  []string
+}
+
+// PromptBuilder renders categorization prompts and maps free-form model
+// answers back to known category names.
+type PromptBuilder struct {
+	tmpl         *template.Template
+	categories   []string
+	baseCurrency string
+}
+
+// NewPromptBuilder creates a PromptBuilder for the given language.
+// If templateFile is set, its content is used as the template instead of the
+// built-in one. categories is the list of valid category names; when empty,
+// the categories of the built-in prompt are used.
+func NewPromptBuilder(language, templateFile string, categories []string) (*PromptBuilder, error) {
+	var text string
+	if templateFile != "" {
+		content, err := os.ReadFile(templateFile) // #nosec G304 -- path comes from user configuration
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt template %s: %w", templateFile, err)
+		}
+		text = string(content)
+	} else {
+		if language == "" {
+			language = PromptLanguageEnglish
+		}
+		builtin, ok := builtinPrompts[strings.ToLower(language)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported prompt language %q (supported: %s)", language, strings.Join(SupportedPromptLanguages(), ", "))
+		}
+		text = builtin
+	}
+
+	tmpl, err := template.New("prompt").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template: %w", err)
+	}
+
+	if len(categories) == 0 {
+		categories = defaultCategories()
+	}
+
+	return &PromptBuilder{tmpl: tmpl, categories: categories, baseCurrency: defaultBaseCurrency}, nil
+}
+
+// SupportedPromptLanguages returns the built-in prompt languages in sorted order.
+func SupportedPromptLanguages() []string {
+	langs := make([]string, 0, len(builtinPrompts))
+	for lang := range builtinPrompts {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// defaultPromptBuilder returns the English built-in prompt builder.
+func defaultPromptBuilder() *PromptBuilder {
+	pb, err := NewPromptBuilder(PromptLanguageEnglish, "", nil)
+	if err != nil {
+		panic(fmt.Sprintf("built-in prompt template is invalid: %v", err))
+	}
+	return pb
+}
+
+// defaultCategories returns the category names listed in the built-in prompt.
+func defaultCategories() []string {
+	matches := categoryLinePattern.FindAllStringSubmatch(builtinPrompts[PromptLanguageEnglish], -1)
+	categories := make([]string, 0, len(matches))
+	for _, m := range matches {
+		categories = append(categories, strings.TrimSpace(m[1]))
+	}
+	return categories
+}
+
+// Categories returns the category names the builder matches answers against.
+func (pb *PromptBuilder) Categories() []string {
+	return pb.categories
+}
+
+// SetBaseCurrency sets the currency given in the prompt for transactions that
+// carry none. An empty code keeps the current one.
+func (pb *PromptBuilder) SetBaseCurrency(code string) {
+	if code = strings.ToUpper(strings.TrimSpace(code)); code != "" {
+		pb.baseCurrency = code
+	}
+}
+
+// Build renders the prompt for a transaction.
+func (pb *PromptBuilder) Build(transaction models.Transaction) (string, error) {
+	currency := transaction.Currency
+	if currency == "" {
+		currency = pb.baseCurrency
+	}
+
+	data := PromptData{
+		Party:       transaction.PartyName,
+		Description: transaction.Description,
+		Amount:      transaction.Amount.String(),
+		Currency:    currency,
+		Categories:  pb.categories,
+	}
+
+	var buf bytes.Buffer
+	if err := pb.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// extractCategoryFromResponse maps a raw model answer to a known category.
+// The answer is first cleaned with clean and compared case-insensitively to
+// the known categories; failing that, the raw answer is searched for a known
+// category name appearing as whole words (longest first), which makes the
+// result independent of the prompt language. If nothing matches, the cleaned
+// answer is returned.
+func extractCategoryFromResponse(raw string, known []string, clean func(string) string) string {
+	cleaned := clean(raw)
+	for _, name := range known {
+		if strings.EqualFold(cleaned, name) {
+			return name
+		}
+	}
+
+	byLength := make([]string, len(known))
+	copy(byLength, known)
+	sort.SliceStable(byLength, func(i, j int) bool {
+		return len(byLength[i]) > len(byLength[j])
+	})
+
+	lowerRaw := strings.ToLower(raw)
+	for _, name := range byLength {
+		if containsToken(lowerRaw, strings.ToLower(name)) {
+			return name
+		}
+	}
+
+	return cleaned
+}
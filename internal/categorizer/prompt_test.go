@@ -0,0 +1,163 @@
+package categorizer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPromptBuilder_BuiltinLanguages(t *testing.T) {
+	tx := models.Transaction{
+		PartyName:   "Coop Pronto",
+		Description: "Achat",
+		Amount:      decimal.RequireFromString("15.50"),
+		Currency:    "EUR",
+	}
+
+	en, err := NewPromptBuilder("en", "", nil)
+	require.NoError(t, err)
+	prompt, err := en.Build(tx)
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "You are a financial transaction categorizer")
+	assert.Contains(t, prompt, "Party: Coop Pronto")
+	assert.Contains(t, prompt, "Amount: 15.5 CHF")
+
+	fr, err := NewPromptBuilder("FR", "", nil)
+	require.NoError(t, err)
+	prompt, err = fr.Build(tx)
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "TRANSACTION À CATÉGORISER")
+	assert.Contains(t, prompt, "Partie : Coop Pronto")
+	assert.Contains(t, prompt, "Montant : 15.5 EUR")
+}
+
+func TestNewPromptBuilder_DefaultPrompt(t *testing.T) {
+	pb, err := NewPromptBuilder("", "", nil)
+	require.NoError(t, err)
+
+	prompt, err := pb.Build(models.Transaction{PartyName: "Migros", Description: "Achat", Amount: decimal.NewFromInt(10), Currency: "EUR"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(prompt, "You are a financial transaction categorizer for a personal finance application.\n\nYour goal is"))
+	assert.True(t, strings.HasSuffix(prompt, "TRANSACTION TO CATEGORIZE:\n\nParty: Migros\n\nDescription: Achat\n\nAmount: 10 CHF\n\n\n\nCategory:"))
+}
+
+func TestNewPromptBuilder_CurrencyDefaultsToBaseCurrency(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompt.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte("{{.Amount}} {{.Currency}}"), 0600))
+	pb, err := NewPromptBuilder("", path, nil)
+	require.NoError(t, err)
+
+	prompt, err := pb.Build(models.Transaction{PartyName: "Migros", Amount: decimal.NewFromInt(10)})
+	require.NoError(t, err)
+	assert.Equal(t, "10 CHF", prompt)
+
+	pb.SetBaseCurrency("eur")
+	prompt, err = pb.Build(models.Transaction{PartyName: "Migros", Amount: decimal.NewFromInt(10)})
+	require.NoError(t, err)
+	assert.Equal(t, "10 EUR", prompt)
+}
+
+func TestNewPromptBuilder_UnsupportedLanguage(t *testing.T) {
+	_, err := NewPromptBuilder("de", "", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported prompt language")
+}
+
+func TestNewPromptBuilder_TemplateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompt.tmpl")
+	content := `Catégories: {{range $i, $c := .Categories}}{{if $i}}, {{end}}{{$c}}{{end}}
+{{.Party}} | {{.Amount}} {{.Currency}}`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	pb, err := NewPromptBuilder("en", path, []string{"Courses", "Restaurants"})
+	require.NoError(t, err)
+
+	prompt, err := pb.Build(models.Transaction{PartyName: "Denner", Amount: decimal.NewFromInt(3), Currency: "CHF"})
+	require.NoError(t, err)
+	assert.Equal(t, "Catégories: Courses, Restaurants\nDenner | 3 CHF", prompt)
+}
+
+func TestNewPromptBuilder_TemplateFileErrors(t *testing.T) {
+	_, err := NewPromptBuilder("en", filepath.Join(t.TempDir(), "missing.tmpl"), nil)
+	require.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "broken.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte("{{.Party"), 0600))
+	_, err = NewPromptBuilder("en", path, nil)
+	require.Error(t, err)
+}
+
+func TestDefaultCategories(t *testing.T) {
+	categories := defaultCategories()
+	assert.Len(t, categories, 50)
+	assert.Contains(t, categories, "Courses")
+	assert.Contains(t, categories, "Équipement Maison")
+	assert.Contains(t, categories, "Non Classé")
+	for _, c := range categories {
+		assert.NotContains(t, c, "(")
+	}
+}
+
+func TestExtractCategoryFromResponse(t *testing.T) {
+	known := []string{"Courses", "Sport", "Transport Privé", "Restaurants"}
+	identity := strings.TrimSpace
+
+	tests := []struct {
+		name     string
+		response string
+		expected string
+	}{
+		{"exact match", "Courses", "Courses"},
+		{"case insensitive", "restaurants", "Restaurants"},
+		{"french sentence", "La catégorie est : Courses.", "Courses"},
+		{"longest name wins", "Catégorie : Transport Privé", "Transport Privé"},
+		{"whole words only", "Transports publics", "Transports publics"},
+		{"unknown kept", "Jardinage", "Jardinage"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, extractCategoryFromResponse(tt.response, known, identity))
+		})
+	}
+}
+
+func TestOpenRouterClient_FrenchPromptAndVerboseAnswer(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OpenRouterRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		received = req.Messages[0].Content
+
+		resp := OpenRouterResponse{
+			Choices: []OpenRouterChoice{
+				{Message: OpenRouterMessage{Role: "assistant", Content: "Je classerais cette transaction dans Courses."}},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	client := NewOpenRouterClient(logging.NewLogrusAdapter("debug", "text"), 60, "test-model", 30, "test-api-key", server.URL)
+	pb, err := NewPromptBuilder("fr", "", nil)
+	require.NoError(t, err)
+	client.SetPromptBuilder(pb)
+
+	result, err := client.Categorize(context.Background(), models.Transaction{PartyName: "Coop"})
+	require.NoError(t, err)
+	assert.Contains(t, received, "TRANSACTION À CATÉGORISER")
+	assert.Equal(t, "Courses", result.Category)
+}
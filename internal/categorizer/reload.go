@@ -0,0 +1,102 @@
+package categorizer
+
+import (
+	"fmt"
+	"strings"
+
+	"fjacquet/camt-csv/internal/logging"
+)
+
+// Reload saves the mappings learned so far, then reads the categories,
+// creditor, debtor, IBAN and MCC files, the tag and cleanup rules and the
+// internal parties again and replaces the configuration the strategies
+// categorize with. If the categories, creditor or debtor file fails to load,
+// the previous configuration is kept and the error is logged and returned;
+// the other rule sets keep their previous version when they fail to load, as
+// at startup. The semantic strategy keeps the category embeddings computed at
+// startup.
+func (c *Categorizer) Reload() error {
+	// The files are read again, so learned mappings must be on disk first
+	if err := c.SaveMappings(); err != nil {
+		return c.reloadFailed(fmt.Errorf("error saving learned mappings: %w", err))
+	}
+
+	categories, err := c.store.LoadCategories()
+	if err != nil {
+		return c.reloadFailed(fmt.Errorf("error loading categories: %w", err))
+	}
+	creditorMappings, err := c.store.LoadCreditorMappings()
+	if err != nil {
+		return c.reloadFailed(fmt.Errorf("error loading creditor mappings: %w", err))
+	}
+	debitorMappings, err := c.store.LoadDebtorMappings()
+	if err != nil {
+		return c.reloadFailed(fmt.Errorf("error loading debtor mappings: %w", err))
+	}
+	directional := loadDirectionalMappings(c.store, c.logger)
+
+	c.configMutex.Lock()
+	c.categories = categories
+	// Mappings learned since the save above are kept for the next one
+	c.creditorMappings = keepLearned(lowerKeys(creditorMappings), c.creditorMappings, c.dirtyCreditors)
+	c.debitorMappings = keepLearned(lowerKeys(debitorMappings), c.debitorMappings, c.dirtyDebitors)
+	ibanMappings, dirtyIBANs := c.ibanMappings, c.dirtyIBANs
+	c.loadIBANMappings()
+	c.ibanMappings = keepLearned(c.ibanMappings, ibanMappings, dirtyIBANs)
+	c.loadMCCMappings()
+	c.loadTagRules()
+	c.loadNameCleaner()
+	internalParties, internalLoaded := c.loadInternalParties()
+	for _, strategy := range c.strategies {
+		switch s := strategy.(type) {
+		case *InternalPartyStrategy:
+			if internalLoaded {
+				s.SetInternalParties(internalParties)
+			}
+		case *DirectMappingStrategy:
+			s.SetMappings(c.creditorMappings, c.debitorMappings, directional)
+		case *KeywordStrategy:
+			s.SetCategories(categories)
+		}
+	}
+	c.configMutex.Unlock()
+
+	// Cached results may come from the previous configuration
+	c.batchCacheMu.Lock()
+	clear(c.batchCache)
+	c.batchCacheMu.Unlock()
+
+	c.logger.WithFields(
+		logging.Field{Key: "categories", Value: len(categories)},
+		logging.Field{Key: "creditors", Value: len(creditorMappings)},
+		logging.Field{Key: "debtors", Value: len(debitorMappings)},
+	).Info("Reloaded categories")
+	return nil
+}
+
+// keepLearned copies the entries of previous named in dirty, which were
+// learned but not saved yet, into loaded and returns it.
+func keepLearned(loaded, previous map[string]string, dirty map[string]bool) map[string]string {
+	for key := range dirty {
+		if value, ok := previous[key]; ok {
+			loaded[key] = value
+		}
+	}
+	return loaded
+}
+
+// reloadFailed logs a failed reload and returns err.
+func (c *Categorizer) reloadFailed(err error) error {
+	c.logger.WithError(err).Error("Failed to reload categories, keeping the previous configuration")
+	return err
+}
+
+// lowerKeys returns a copy of mappings with lowercase keys, for
+// case-insensitive lookup.
+func lowerKeys(mappings map[string]string) map[string]string {
+	lowered := make(map[string]string, len(mappings))
+	for key, value := range mappings {
+		lowered[strings.ToLower(key)] = value
+	}
+	return lowered
+}
@@ -0,0 +1,94 @@
+package categorizer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategorizer_Reload(t *testing.T) {
+	dir := t.TempDir()
+	categoriesFile := filepath.Join(dir, "categories.yaml")
+	creditorsFile := filepath.Join(dir, "creditors.yaml")
+	require.NoError(t, os.WriteFile(categoriesFile, []byte("categories:\n  - name: Food\n    keywords: [MIGROS]\n"), 0600))
+	require.NoError(t, os.WriteFile(creditorsFile, []byte("ACME SA: Salary\n"), 0600))
+
+	testStore := &store.CategoryStore{
+		CategoriesFile: categoriesFile,
+		CreditorsFile:  creditorsFile,
+		DebtorsFile:    filepath.Join(dir, "debtors.yaml"),
+	}
+	logger := logging.NewMockLogger()
+	cat := NewCategorizer(nil, testStore, logger, false, 0.70)
+
+	categorize := func(party string, isDebtor bool) string {
+		category, err := cat.CategorizeTransaction(context.Background(), Transaction{PartyName: party, IsDebtor: isDebtor})
+		require.NoError(t, err)
+		return category.Name
+	}
+	assert.Equal(t, "Food", categorize("MIGROS LAUSANNE", true))
+	assert.Equal(t, "Salary", categorize("ACME SA", false))
+
+	require.NoError(t, os.WriteFile(categoriesFile, []byte("categories:\n  - name: Groceries\n    keywords: [MIGROS]\n"), 0600))
+	require.NoError(t, os.WriteFile(creditorsFile, []byte("ACME SA: Bonus\n"), 0600))
+	require.NoError(t, cat.Reload())
+
+	// Results cached before the reload are not reused
+	assert.Equal(t, "Groceries", categorize("MIGROS LAUSANNE", true))
+	assert.Equal(t, "Bonus", categorize("ACME SA", false))
+
+	// A broken file keeps the previous configuration
+	require.NoError(t, os.WriteFile(categoriesFile, []byte("categories: [unclosed\n"), 0600))
+	assert.Error(t, cat.Reload())
+	assert.True(t, logger.HasEntry("ERROR", "Failed to reload categories, keeping the previous configuration"))
+	assert.Equal(t, "Groceries", categorize("MIGROS ZURICH", true))
+	assert.Equal(t, "Bonus", categorize("ACME SA", false))
+}
+
+func TestCategorizer_ReloadRulesAndLearnedMappings(t *testing.T) {
+	dir := t.TempDir()
+	categoriesFile := filepath.Join(dir, "categories.yaml")
+	tagsFile := filepath.Join(dir, "tags.yaml")
+	cleanupFile := filepath.Join(dir, "cleanup.yaml")
+	creditorsFile := filepath.Join(dir, "creditors.yaml")
+	require.NoError(t, os.WriteFile(categoriesFile, []byte("categories:\n  - name: Food\n    keywords: [MIGROS]\n"), 0600))
+	require.NoError(t, os.WriteFile(tagsFile, []byte("tags:\n  - name: business\n    keywords: [AWS]\n"), 0600))
+	require.NoError(t, os.WriteFile(cleanupFile, []byte("rules:\n  - action: strip_prefix\n    values: [\"PMT CARTE \"]\n"), 0600))
+
+	testStore := store.NewCategoryStore(categoriesFile, creditorsFile, filepath.Join(dir, "debtors.yaml"))
+	testStore.TagsFile = tagsFile
+	testStore.CleanupFile = cleanupFile
+	testStore.SetBackupConfig(false, "", "")
+	cat := NewCategorizer(nil, testStore, logging.NewMockLogger(), true, 0.70)
+
+	cat.UpdateCreditorCategory("ACME SA", "Salary")
+
+	require.NoError(t, os.WriteFile(categoriesFile, []byte("categories:\n  - name: Food\n    keywords: [MIGROS]\ninternal_parties:\n  - Jane Doe\n"), 0600))
+	require.NoError(t, os.WriteFile(tagsFile, []byte("tags:\n  - name: travel\n    keywords: [HOTEL]\n"), 0600))
+	require.NoError(t, os.WriteFile(cleanupFile, []byte("rules:\n  - action: strip_prefix\n    values: [\"PMT TWINT \"]\n"), 0600))
+	require.NoError(t, cat.Reload())
+
+	// The mapping learned before the reload was saved, not dropped
+	data, err := os.ReadFile(creditorsFile) // #nosec G304 -- test file
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Salary")
+	category, err := cat.CategorizeTransaction(context.Background(), Transaction{PartyName: "ACME SA"})
+	require.NoError(t, err)
+	assert.Equal(t, "Salary", category.Name)
+
+	assert.Equal(t, []string{"travel"}, cat.Tags("HOTEL DU LAC", ""))
+	assert.Empty(t, cat.Tags("AWS", ""))
+	assert.Equal(t, "MIGROS", cat.CleanPartyName("PMT TWINT MIGROS"))
+
+	category, err = cat.CategorizeTransaction(context.Background(), Transaction{PartyName: "Jane Doe", IsDebtor: true})
+	require.NoError(t, err)
+	assert.Equal(t, models.CategorySourceInternal, category.Source)
+}
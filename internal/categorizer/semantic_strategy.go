@@ -100,10 +100,11 @@ func (s *SemanticStrategy) Categorize(ctx context.Context, tx Transaction) (mode
 	var bestCategory string
 	var maxScore float32 = -1.0
 
-	// Find best matching category
+	// Find best matching category; ties go to the first name in sort order so
+	// the result does not depend on map iteration order
 	for catName, catEmbedding := range s.categoryEmbeddings {
 		score := s.cosineSimilarity(txEmbedding, catEmbedding)
-		if score > maxScore {
+		if score > maxScore || (score == maxScore && catName < bestCategory) {
 			maxScore = score
 			bestCategory = catName
 		}
@@ -129,7 +130,7 @@ func (s *SemanticStrategy) Categorize(ctx context.Context, tx Transaction) (mode
 			Name:        bestCategory,
 			Description: "Semantic match",
 			Confidence:  0.90, // High confidence for semantic matches above threshold
-			Source:      "semantic",
+			Source:      models.CategorySourceAI,
 		}, true, nil
 	}
 
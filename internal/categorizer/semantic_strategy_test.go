@@ -9,6 +9,7 @@ import (
 	"fjacquet/camt-csv/internal/models"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSemanticStrategy_Name(t *testing.T) {
@@ -98,6 +99,28 @@ func TestSemanticStrategy_Categorize(t *testing.T) {
 	}
 }
 
+func TestSemanticStrategy_TieGoesToFirstName(t *testing.T) {
+	categories := []models.CategoryConfig{
+		{Name: "Shopping", Keywords: []string{"store"}},
+		{Name: "Groceries", Keywords: []string{"market"}},
+		{Name: "Household", Keywords: []string{"home"}},
+	}
+	mockClient := &TestMockAIClient{
+		GetEmbeddingFunc: func(ctx context.Context, text string) ([]float32, error) {
+			return []float32{1, 0, 0}, nil // every category scores the same
+		},
+	}
+	strategy := NewSemanticStrategy(mockClient, &logging.MockLogger{}, categories, 0.70)
+	time.Sleep(100 * time.Millisecond)
+
+	for i := 0; i < 20; i++ {
+		cat, found, err := strategy.Categorize(context.Background(), Transaction{PartyName: "Corner Shop"})
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, "Groceries", cat.Name)
+	}
+}
+
 func TestSemanticStrategy_CosineSimilarity(t *testing.T) {
 	strategy := &SemanticStrategy{}
 
@@ -0,0 +1,61 @@
+package categorizer
+
+import (
+	"slices"
+	"strings"
+
+	"fjacquet/camt-csv/internal/models"
+)
+
+// TagStoreInterface is implemented by stores that provide tag rules.
+// It is optional: a store without it simply yields no tags.
+type TagStoreInterface interface {
+	LoadTagRules() ([]models.TagRule, error)
+}
+
+// loadTagRules loads tag rules from the store if it supports them.
+func (c *Categorizer) loadTagRules() {
+	tagStore, ok := c.store.(TagStoreInterface)
+	if !ok {
+		return
+	}
+
+	rules, err := tagStore.LoadTagRules()
+	if err != nil {
+		c.logger.WithError(err).Warn("Failed to load tag rules")
+		return
+	}
+	c.tagRules = rules
+}
+
+// Tags implements models.TransactionTagger. It returns the names of all tag
+// rules with a keyword contained (case-insensitively) in the party name or
+// description, in rule order and without duplicates.
+func (c *Categorizer) Tags(partyName, description string) []string {
+	c.configMutex.RLock()
+	defer c.configMutex.RUnlock()
+	if len(c.tagRules) == 0 {
+		return nil
+	}
+
+	party := strings.ToUpper(partyName)
+	desc := strings.ToUpper(description)
+
+	var tags []string
+	for _, rule := range c.tagRules {
+		for _, keyword := range rule.Keywords {
+			keywordUpper := strings.ToUpper(strings.TrimSpace(keyword))
+			if keywordUpper == "" {
+				continue
+			}
+			if strings.Contains(party, keywordUpper) || strings.Contains(desc, keywordUpper) {
+				if !slices.Contains(tags, rule.Name) {
+					tags = append(tags, rule.Name)
+				}
+				break
+			}
+		}
+	}
+
+	return tags
+}
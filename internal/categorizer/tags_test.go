@@ -0,0 +1,70 @@
+package categorizer
+
+import (
+	"context"
+	"testing"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategorizer_Tags(t *testing.T) {
+	mockStore := &store.MockCategoryStore{
+		CreditorMappings: map[string]string{"hotel du lac": "Voyages"},
+		TagRules: []models.TagRule{
+			{Name: "business", Keywords: []string{"aws", "conference"}},
+			{Name: "reimbursable", Keywords: []string{"hotel", "conference"}},
+			{Name: "empty", Keywords: []string{" "}},
+		},
+	}
+	cat := NewCategorizer(nil, mockStore, logging.NewMockLogger(), false, 0.70)
+
+	tests := []struct {
+		name        string
+		party       string
+		description string
+		expected    []string
+	}{
+		{"party match", "AWS EMEA SARL", "", []string{"business"}},
+		{"description match", "Hotel du Lac", "Conference stay", []string{"business", "reimbursable"}},
+		{"no match", "Migros", "Groceries", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, cat.Tags(tt.party, tt.description))
+		})
+	}
+
+	// Tags do not change the category
+	category, err := cat.CategorizeTransaction(context.Background(), Transaction{PartyName: "Hotel du Lac"})
+	require.NoError(t, err)
+	assert.Equal(t, "Voyages", category.Name)
+}
+
+func TestCategorizer_TagsLoadError(t *testing.T) {
+	mockStore := &store.MockCategoryStore{LoadTagRulesError: assert.AnError}
+	logger := logging.NewMockLogger()
+	cat := NewCategorizer(nil, mockStore, logger, false, 0.70)
+
+	assert.Nil(t, cat.Tags("AWS", ""))
+	assert.NotEmpty(t, logger.GetEntriesByLevel("WARN"))
+}
+
+func TestApplyTags(t *testing.T) {
+	cat := NewCategorizer(nil, &store.MockCategoryStore{
+		TagRules: []models.TagRule{{Name: "business", Keywords: []string{"github"}}},
+	}, logging.NewMockLogger(), false, 0.70)
+
+	tx := models.Transaction{PartyName: "GitHub Inc", Description: "Team plan"}
+	models.ApplyTags(&tx, cat)
+	assert.Equal(t, []string{"business"}, tx.Tags)
+
+	untagged := models.Transaction{PartyName: "GitHub Inc"}
+	models.ApplyTags(&untagged, nil)
+	assert.Nil(t, untagged.Tags)
+}
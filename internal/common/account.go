@@ -2,6 +2,10 @@
 package common
 
 import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -108,3 +112,91 @@ func ExtractAccountFromFilename(filename string) AccountIdentifier {
 		Source: "default",
 	}
 }
+
+// ExtractAccountFromFile returns the account of a statement file. The
+// account encoded in a CAMT file name is used when there is one; otherwise
+// the account IBAN (or other account ID) is read from the XML content, so
+// that files are grouped by their real account whatever their name. Files
+// that are not XML statements fall back to the base file name.
+func ExtractAccountFromFile(filename string) AccountIdentifier {
+	fromName := ExtractAccountFromFilename(filename)
+	if fromName.Source == "filename" {
+		return fromName
+	}
+
+	file, err := os.Open(filename) // #nosec G304 -- CLI tool requires user-provided file paths
+	if err != nil {
+		return fromName
+	}
+	defer func() { _ = file.Close() }()
+
+	accountID, err := ExtractAccountFromXML(file)
+	if err != nil || accountID == "" {
+		return fromName
+	}
+	return AccountIdentifier{
+		ID:     SanitizeAccountID(accountID),
+		Source: "content",
+	}
+}
+
+// ExtractAccountFromXML returns the account of the first statement, report
+// or notification of a CAMT document: its IBAN, or its other ID when it has
+// no IBAN. It stops at the first entry, so that large files are not read
+// entirely, and returns "" when the document names no account.
+func ExtractAccountFromXML(r io.Reader) (string, error) {
+	decoder := xml.NewDecoder(r)
+	var path []string
+	otherID := ""
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return otherID, nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "Ntry" {
+				return otherID, nil
+			}
+			path = append(path, t.Name.Local)
+		case xml.EndElement:
+			if len(path) > 0 {
+				path = path[:len(path)-1]
+			}
+		case xml.CharData:
+			value := strings.TrimSpace(string(t))
+			if value == "" {
+				continue
+			}
+			switch {
+			case hasPathSuffix(path, "Acct", "Id", "IBAN"):
+				return value, nil
+			case otherID == "" && hasPathSuffix(path, "Acct", "Id", "Othr", "Id"):
+				otherID = value
+			}
+		}
+	}
+}
+
+// hasPathSuffix reports whether the element path ends with suffix, under a
+// statement (Stmt), report (Rpt) or notification (Ntfctn) element.
+func hasPathSuffix(path []string, suffix ...string) bool {
+	if len(path) < len(suffix)+1 {
+		return false
+	}
+	start := len(path) - len(suffix)
+	for i, name := range suffix {
+		if path[start+i] != name {
+			return false
+		}
+	}
+	switch path[start-1] {
+	case "Stmt", "Rpt", "Ntfctn":
+		return true
+	}
+	return false
+}
@@ -4,11 +4,14 @@ import (
 	"crypto/rand"
 	"fmt"
 	"math/big"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestExtractAccountFromCAMTFilename tests basic CAMT filename parsing
@@ -368,3 +371,42 @@ func TestExtractAccountFromFilename(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractAccountFromFile_ReadsContent(t *testing.T) {
+	dir := t.TempDir()
+	statement := `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.04">
+  <BkToCstmrStmt>
+    <GrpHdr><MsgId>MSG-1</MsgId></GrpHdr>
+    <Stmt>
+      <Id>STMT-1</Id>
+      <Acct><Id><IBAN>CH9300762011623852957</IBAN></Id><Ownr><Nm>Holder</Nm></Ownr></Acct>
+      <Ntry><NtryDtls><TxDtls><RltdPties><CdtrAcct><Id><IBAN>DE89370400440532013000</IBAN></Id></CdtrAcct></RltdPties></TxDtls></NtryDtls></Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>`
+	other := `<Document><BkToCstmrStmt><Stmt><Acct><Id><Othr><Id>54293249</Id></Othr></Id></Acct></Stmt></BkToCstmrStmt></Document>`
+
+	files := map[string]string{
+		"statement-may.xml": statement,
+		"export (2).xml":    other,
+		"notes.txt":         "not a statement",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+
+	result := ExtractAccountFromFile(filepath.Join(dir, "statement-may.xml"))
+	assert.Equal(t, AccountIdentifier{ID: "CH9300762011623852957", Source: "content"}, result)
+
+	result = ExtractAccountFromFile(filepath.Join(dir, "export (2).xml"))
+	assert.Equal(t, AccountIdentifier{ID: "54293249", Source: "content"}, result)
+
+	// Not XML: the file name is used
+	result = ExtractAccountFromFile(filepath.Join(dir, "notes.txt"))
+	assert.Equal(t, AccountIdentifier{ID: "notes", Source: "default"}, result)
+
+	// The file name wins without reading the file
+	result = ExtractAccountFromFile(filepath.Join(dir, "CAMT.053_11111111_2025-05-01_2025-05-31_1.xml"))
+	assert.Equal(t, AccountIdentifier{ID: "11111111", Source: "filename"}, result)
+}
@@ -9,8 +9,11 @@ import (
 )
 
 // ProcessTransactionsWithCategorizationStats processes transactions with categorization
-// and tracks statistics, providing fallback behavior for failed categorization
+// and tracks statistics, providing fallback behavior for failed categorization.
+// Parsers categorize through it, so that they all apply the own-account, override,
+// IBAN and MCC shortcuts before asking categorizer.
 func ProcessTransactionsWithCategorizationStats(
+	ctx context.Context,
 	transactions []models.Transaction,
 	logger logging.Logger,
 	categorizer models.TransactionCategorizer,
@@ -31,8 +34,9 @@ func ProcessTransactionsWithCategorizationStats(
 		if categorizer == nil {
 			logger.Debug("No categorizer provided, skipping categorization",
 				logging.Field{Key: "parser_type", Value: parserType})
-			if tx.Category == "" {
+			if tx.Category == "" || tx.Category == models.CategoryUncategorized {
 				processedTransactions[i].Category = models.CategoryUncategorized
+				processedTransactions[i].CategorySource = models.CategorySourceFallback
 				stats.IncrementUncategorized()
 			} else {
 				stats.IncrementSuccessful()
@@ -40,6 +44,43 @@ func ProcessTransactionsWithCategorizationStats(
 			continue
 		}
 
+		// Tags are independent of the category, so apply them even when it is already set
+		models.CleanPartyName(&processedTransactions[i], categorizer)
+		models.ApplyTags(&processedTransactions[i], categorizer)
+
+		// Transfers with the user's own accounts are recognized by IBAN,
+		// after their direction has been corrected, unless the run overrides
+		// the party's category
+		internalTransfer := models.ApplyOwnAccounts(&processedTransactions[i], categorizer)
+		if models.ApplyCategoryOverride(&processedTransactions[i], categorizer) {
+			logger.Debug("Transaction categorized by category override",
+				logging.Field{Key: "parser_type", Value: parserType},
+				logging.Field{Key: "party", Value: processedTransactions[i].PartyName},
+				logging.Field{Key: "category", Value: processedTransactions[i].Category})
+			stats.IncrementSuccessful()
+			continue
+		}
+		if internalTransfer {
+			internal := models.Category{Name: processedTransactions[i].Category, Source: models.CategorySourceInternal}
+			if models.ExcludedFromStats(categorizer, internal) {
+				stats.IncrementExcluded()
+			} else {
+				stats.IncrementSuccessful()
+			}
+			continue
+		}
+
+		// A mapped counterparty IBAN beats the parser's category and any guess from the name
+		if models.ApplyIBANMapping(&processedTransactions[i], categorizer) {
+			logger.Debug("Transaction categorized by counterparty IBAN",
+				logging.Field{Key: "parser_type", Value: parserType},
+				logging.Field{Key: "party_iban", Value: processedTransactions[i].PartyIBAN},
+				logging.Field{Key: "category", Value: processedTransactions[i].Category})
+			stats.IncrementSuccessful()
+			continue
+		}
+		tx = processedTransactions[i]
+
 		// Skip categorization if category already determined by parser-internal logic
 		if tx.Category != "" && tx.Category != models.CategoryUncategorized {
 			logger.Debug("Category already set, skipping external categorization",
@@ -49,6 +90,16 @@ func ProcessTransactionsWithCategorizationStats(
 			continue
 		}
 
+		// The merchant category code of card spend beats any guess from the name
+		if models.ApplyMCC(&processedTransactions[i], categorizer) {
+			logger.Debug("Transaction categorized by merchant category code",
+				logging.Field{Key: "parser_type", Value: parserType},
+				logging.Field{Key: "mcc", Value: tx.MCC},
+				logging.Field{Key: "category", Value: processedTransactions[i].Category})
+			stats.IncrementSuccessful()
+			continue
+		}
+
 		// Attempt categorization
 		partyName := tx.GetPartyName()
 		if partyName == "" {
@@ -59,8 +110,12 @@ func ProcessTransactionsWithCategorizationStats(
 				partyName = tx.Name
 			} else if tx.Recipient != "" {
 				partyName = tx.Recipient
+			} else if tx.PartyBIC != "" {
+				// Without a counterparty name, rules can match the counterparty's BIC
+				partyName = tx.PartyBIC
 			}
 		}
+		partyName = models.CleanName(partyName, categorizer)
 
 		if partyName == "" {
 			logger.Debug("No party name available for categorization",
@@ -68,11 +123,12 @@ func ProcessTransactionsWithCategorizationStats(
 				logging.Field{Key: "transaction_description", Value: tx.Description})
 			stats.IncrementUncategorized()
 			processedTransactions[i].Category = "Uncategorized"
+			processedTransactions[i].CategorySource = models.CategorySourceFallback
 			continue
 		}
 
 		category, err := categorizer.Categorize(
-			context.Background(),
+			ctx,
 			partyName,
 			tx.IsDebit(),
 			tx.Amount.String(),
@@ -87,12 +143,22 @@ func ProcessTransactionsWithCategorizationStats(
 				logging.Field{Key: "amount", Value: tx.Amount.String()})
 			stats.IncrementFailed()
 			processedTransactions[i].Category = "Uncategorized"
+			processedTransactions[i].CategorySource = models.CategorySourceFallback
 		} else if category.Name == "" || category.Name == "Uncategorized" {
 			logger.Debug("Transaction categorized as uncategorized",
 				logging.Field{Key: "parser_type", Value: parserType},
 				logging.Field{Key: "party_name", Value: partyName})
 			stats.IncrementUncategorized()
 			processedTransactions[i].Category = "Uncategorized"
+			processedTransactions[i].CategorySource = models.CategorySourceFallback
+		} else if models.ExcludedFromStats(categorizer, category) {
+			logger.Debug("Transaction excluded from categorization statistics",
+				logging.Field{Key: "parser_type", Value: parserType},
+				logging.Field{Key: "party_name", Value: partyName},
+				logging.Field{Key: "category", Value: category.Name})
+			stats.IncrementExcluded()
+			processedTransactions[i].Category = category.Name
+			processedTransactions[i].CategorySource = category.Source
 		} else {
 			logger.Debug("Transaction categorized successfully",
 				logging.Field{Key: "parser_type", Value: parserType},
@@ -100,6 +166,7 @@ func ProcessTransactionsWithCategorizationStats(
 				logging.Field{Key: "category", Value: category.Name})
 			stats.IncrementSuccessful()
 			processedTransactions[i].Category = category.Name
+			processedTransactions[i].CategorySource = category.Source
 		}
 	}
 
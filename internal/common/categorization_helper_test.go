@@ -92,7 +92,7 @@ func TestProperty9_CategorizationFallbackBehavior(t *testing.T) {
 			mockLogger.On("Warn", mock.AnythingOfType("string"), mock.Anything).Return()
 
 			// Test case 1: No categorizer provided
-			result1 := ProcessTransactionsWithCategorizationStats(transactions, mockLogger, nil, "TestParser")
+			result1 := ProcessTransactionsWithCategorizationStats(context.Background(), transactions, mockLogger, nil, "TestParser")
 
 			// All transactions should be uncategorized
 			for _, tx := range result1 {
@@ -104,7 +104,7 @@ func TestProperty9_CategorizationFallbackBehavior(t *testing.T) {
 			mockCategorizer.On("Categorize", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 				Return(models.Category{}, errors.New("categorization failed"))
 
-			result2 := ProcessTransactionsWithCategorizationStats(transactions, mockLogger, mockCategorizer, "TestParser")
+			result2 := ProcessTransactionsWithCategorizationStats(context.Background(), transactions, mockLogger, mockCategorizer, "TestParser")
 
 			// All transactions should be uncategorized due to errors
 			for _, tx := range result2 {
@@ -116,7 +116,7 @@ func TestProperty9_CategorizationFallbackBehavior(t *testing.T) {
 			mockCategorizer2.On("Categorize", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 				Return(models.Category{Name: ""}, nil)
 
-			result3 := ProcessTransactionsWithCategorizationStats(transactions, mockLogger, mockCategorizer2, "TestParser")
+			result3 := ProcessTransactionsWithCategorizationStats(context.Background(), transactions, mockLogger, mockCategorizer2, "TestParser")
 
 			// All transactions should be uncategorized due to empty category
 			for _, tx := range result3 {
@@ -197,7 +197,7 @@ func TestProperty10_CategorizationStatisticsLogging(t *testing.T) {
 			}
 
 			// Process transactions
-			result := ProcessTransactionsWithCategorizationStats(transactions, mockLogger, mockCategorizer, "TestParser")
+			result := ProcessTransactionsWithCategorizationStats(context.Background(), transactions, mockLogger, mockCategorizer, "TestParser")
 
 			// Verify that all transactions were processed
 			assert.Equal(t, len(transactions), len(result), "All transactions should be processed")
@@ -343,8 +343,58 @@ func TestProcessTransactionsWithCategorizationStats_NilLogger(t *testing.T) {
 	transactions := generateTestTransactions(1)
 
 	// Should not panic with nil logger
-	result := ProcessTransactionsWithCategorizationStats(transactions, nil, nil, "TestParser")
+	result := ProcessTransactionsWithCategorizationStats(context.Background(), transactions, nil, nil, "TestParser")
 
 	assert.Equal(t, len(transactions), len(result))
 	assert.Equal(t, "Uncategorized", result[0].Category)
 }
+
+func TestProcessTransactionsWithCategorizationStats_CategorySource(t *testing.T) {
+	transactions := []models.Transaction{
+		{PartyName: "Migros", CreditDebit: models.TransactionTypeDebit},
+		{PartyName: "Unknown Shop", CreditDebit: models.TransactionTypeDebit},
+		{PartyName: "Selma", Category: "Investissements"},
+	}
+
+	categorizer := &MockCategorizer{}
+	categorizer.On("Categorize", mock.Anything, "Migros", true, mock.Anything, mock.Anything, mock.Anything).
+		Return(models.Category{Name: models.CategoryGroceries, Source: models.CategorySourceKeyword}, nil)
+	categorizer.On("Categorize", mock.Anything, "Unknown Shop", true, mock.Anything, mock.Anything, mock.Anything).
+		Return(models.Category{Name: models.CategoryUncategorized, Source: models.CategorySourceFallback}, nil)
+
+	result := ProcessTransactionsWithCategorizationStats(context.Background(), transactions, logging.NewMockLogger(), categorizer, "TestParser")
+
+	assert.Equal(t, models.CategorySourceKeyword, result[0].CategorySource)
+	assert.Equal(t, models.CategorySourceFallback, result[1].CategorySource)
+	assert.Empty(t, result[2].CategorySource, "category set by the parser has no source")
+	categorizer.AssertExpectations(t)
+}
+
+// overridingCategorizer forces party names into categories for the run.
+type overridingCategorizer struct {
+	MockCategorizer
+	overrides map[string]string
+}
+
+func (c *overridingCategorizer) CategoryOverride(party string) (string, bool) {
+	category, ok := c.overrides[party]
+	return category, ok
+}
+
+func TestProcessTransactionsWithCategorizationStats_CategoryOverride(t *testing.T) {
+	transactions := []models.Transaction{
+		{PartyName: "Selma", Category: "Investissements"},
+		{PartyName: "Migros", CreditDebit: models.TransactionTypeDebit},
+	}
+
+	categorizer := &overridingCategorizer{overrides: map[string]string{"Selma": "Épargne"}}
+	categorizer.On("Categorize", mock.Anything, "Migros", true, mock.Anything, mock.Anything, mock.Anything).
+		Return(models.Category{Name: models.CategoryGroceries, Source: models.CategorySourceKeyword}, nil)
+
+	result := ProcessTransactionsWithCategorizationStats(context.Background(), transactions, logging.NewMockLogger(), categorizer, "TestParser")
+
+	assert.Equal(t, "Épargne", result[0].Category, "the override beats the parser's category")
+	assert.Equal(t, models.CategorySourceOverride, result[0].CategorySource)
+	assert.Equal(t, models.CategoryGroceries, result[1].Category)
+	categorizer.AssertExpectations(t)
+}
@@ -429,7 +429,7 @@ func TestProcessTransactionsWithCategorizationStats_EmptyPartyName(t *testing.T)
 	}
 
 	// Use nil categorizer — empty party name should result in Uncategorized
-	result := ProcessTransactionsWithCategorizationStats(txs, nil, nil, "test")
+	result := ProcessTransactionsWithCategorizationStats(context.Background(), txs, nil, nil, "test")
 	assert.Equal(t, "Uncategorized", result[0].Category)
 }
 
@@ -446,7 +446,7 @@ func TestProcessTransactionsWithCategorizationStats_FallbackToName(t *testing.T)
 	}
 
 	mockCat := &simpleCategorizer{category: "TestCat"}
-	result := ProcessTransactionsWithCategorizationStats(txs, nil, mockCat, "test")
+	result := ProcessTransactionsWithCategorizationStats(context.Background(), txs, nil, mockCat, "test")
 	assert.Equal(t, "TestCat", result[0].Category)
 }
 
@@ -463,7 +463,7 @@ func TestProcessTransactionsWithCategorizationStats_FallbackToRecipient(t *testi
 	}
 
 	mockCat := &simpleCategorizer{category: "RecipientCat"}
-	result := ProcessTransactionsWithCategorizationStats(txs, nil, mockCat, "test")
+	result := ProcessTransactionsWithCategorizationStats(context.Background(), txs, nil, mockCat, "test")
 	assert.Equal(t, "RecipientCat", result[0].Category)
 }
 
@@ -480,7 +480,7 @@ func TestProcessTransactionsWithCategorizationStats_FallbackToPartyNameField(t *
 	}
 
 	mockCat := &simpleCategorizer{category: "PartyCat"}
-	result := ProcessTransactionsWithCategorizationStats(txs, nil, mockCat, "test")
+	result := ProcessTransactionsWithCategorizationStats(context.Background(), txs, nil, mockCat, "test")
 	assert.Equal(t, "PartyCat", result[0].Category)
 }
 
@@ -512,7 +512,7 @@ func TestProcessTransactionsWithCategorizationStats_EmptyPartyNameWithCategorize
 	}
 
 	mockCat := &simpleCategorizer{category: "ShouldNotReach"}
-	result := ProcessTransactionsWithCategorizationStats(txs, nil, mockCat, "test")
+	result := ProcessTransactionsWithCategorizationStats(context.Background(), txs, nil, mockCat, "test")
 	assert.Equal(t, "Uncategorized", result[0].Category)
 }
 
@@ -2,10 +2,17 @@
 package common
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"encoding/csv"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"fjacquet/camt-csv/internal/formatter"
 	"fjacquet/camt-csv/internal/logging"
@@ -19,6 +26,36 @@ import (
 // Delimiter is the CSV delimiter used for output (immutable, use config for customization)
 const Delimiter = models.DefaultCSVDelimiter
 
+// ErrOutputExists is returned by CheckNoClobber when the output file exists.
+var ErrOutputExists = errors.New("output file already exists")
+
+// CheckNoClobber returns an error wrapping ErrOutputExists when path exists,
+// for conversions that must not overwrite earlier output.
+func CheckNoClobber(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%w: %s (remove it or drop --no-clobber to overwrite it)", ErrOutputExists, path)
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("error checking output file: %w", err)
+	}
+	return nil
+}
+
+// createOutputFile creates path for writing, truncating an existing file. With
+// noClobber, it fails with an error wrapping ErrOutputExists instead when path
+// exists; the check and the creation are a single step, so that no other
+// writer can create the file in between.
+func createOutputFile(path string, noClobber bool) (*os.File, error) {
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if noClobber {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	}
+	file, err := os.OpenFile(path, flag, models.PermissionNonSecretFile) // #nosec G304 -- CLI tool requires user-provided output paths
+	if noClobber && errors.Is(err, fs.ErrExist) {
+		return nil, fmt.Errorf("%w: %s (remove it or drop --no-clobber to overwrite it)", ErrOutputExists, path)
+	}
+	return file, err
+}
+
 func init() {
 	// Configure gocsv with the standard delimiter
 	gocsv.TagSeparator = string(Delimiter)
@@ -91,6 +128,48 @@ func WriteTransactionsToCSVWithFormatter(
 	logger logging.Logger,
 	formatter formatter.OutputFormatter,
 	delimiter rune,
+) error {
+	return writeTransactionsToCSV(transactions, csvFile, logger, formatter, delimiter, false, false)
+}
+
+// WriteTransactionsToCSVWithBOM is WriteTransactionsToCSVWithFormatter
+// starting the file with a UTF-8 byte order mark (models.UTF8BOM), so that
+// Excel on Windows displays accented characters correctly.
+func WriteTransactionsToCSVWithBOM(
+	transactions []models.Transaction,
+	csvFile string,
+	logger logging.Logger,
+	formatter formatter.OutputFormatter,
+	delimiter rune,
+) error {
+	return writeTransactionsToCSV(transactions, csvFile, logger, formatter, delimiter, true, false)
+}
+
+// WriteTransactionsToNewCSV is WriteTransactionsToCSVWithFormatter, or
+// WriteTransactionsToCSVWithBOM with bom, failing with an error wrapping
+// ErrOutputExists instead of overwriting an existing csvFile.
+func WriteTransactionsToNewCSV(
+	transactions []models.Transaction,
+	csvFile string,
+	logger logging.Logger,
+	formatter formatter.OutputFormatter,
+	delimiter rune,
+	bom bool,
+) error {
+	return writeTransactionsToCSV(transactions, csvFile, logger, formatter, delimiter, bom, true)
+}
+
+// writeTransactionsToCSV writes transactions to csvFile, preceded by a byte
+// order mark when bom is set. With noClobber, it does not overwrite an
+// existing csvFile (see createOutputFile).
+func writeTransactionsToCSV(
+	transactions []models.Transaction,
+	csvFile string,
+	logger logging.Logger,
+	outFormatter formatter.OutputFormatter,
+	delimiter rune,
+	bom bool,
+	noClobber bool,
 ) error {
 	if logger == nil {
 		logger = logging.NewLogrusAdapter("info", "text")
@@ -116,24 +195,20 @@ func WriteTransactionsToCSVWithFormatter(
 		return fmt.Errorf("error creating directory: %w", err)
 	}
 
-	// Copy transactions to avoid mutating the caller's slice
-	prepared := make([]models.Transaction, len(transactions))
-	copy(prepared, transactions)
-	for i := range prepared {
-		prepared[i].UpdateNameFromParties()
-		prepared[i].UpdateRecipientFromPayee()
-		prepared[i].UpdateDebitCreditAmounts()
-	}
+	prepared := prepareForOutput(transactions)
 
 	// Format transactions using the provided formatter
-	rows, err := formatter.Format(prepared)
+	rows, err := outFormatter.Format(prepared)
 	if err != nil {
 		logger.WithError(err).Error("Failed to format transactions")
 		return fmt.Errorf("error formatting transactions: %w", err)
 	}
 
 	// Create the file
-	file, err := os.Create(csvFile) // #nosec G304 -- CLI tool requires user-provided output paths
+	file, err := createOutputFile(csvFile, noClobber)
+	if errors.Is(err, ErrOutputExists) {
+		return err
+	}
 	if err != nil {
 		logger.WithError(err).Error("Failed to create CSV file")
 		return fmt.Errorf("error creating CSV file: %w", err)
@@ -144,12 +219,31 @@ func WriteTransactionsToCSVWithFormatter(
 		}
 	}()
 
+	// The byte order mark must come first, before any comment line
+	if bom {
+		if _, err := io.WriteString(file, models.UTF8BOM); err != nil {
+			logger.WithError(err).Error("Failed to write byte order mark")
+			return fmt.Errorf("error writing byte order mark: %w", err)
+		}
+	}
+
+	// Statement notes are informational and go above the header. They are
+	// free text, so anonymized output leaves them out
+	if !formatter.Anonymizes(outFormatter) {
+		for _, note := range models.StatementNotes(transactions) {
+			if _, err := fmt.Fprintln(file, models.StatementNoteComment(note)); err != nil {
+				logger.WithError(err).Error("Failed to write statement note")
+				return fmt.Errorf("error writing statement note: %w", err)
+			}
+		}
+	}
+
 	// Configure CSV writer with the specified delimiter
 	csvWriter := csv.NewWriter(file)
 	csvWriter.Comma = delimiter
 
 	// Write header from formatter
-	if err := csvWriter.Write(formatter.Header()); err != nil {
+	if err := csvWriter.Write(outFormatter.Header()); err != nil {
 		logger.WithError(err).Error("Failed to write CSV header")
 		return fmt.Errorf("error writing CSV header: %w", err)
 	}
@@ -177,6 +271,193 @@ func WriteTransactionsToCSVWithFormatter(
 	return nil
 }
 
+// WriteTransactionsWithFormatter writes the formatter's header followed by the
+// formatted transactions as CSV to w. It is the io.Writer counterpart of
+// WriteTransactionsToCSVWithFormatter for output that is not a file, such as
+// an HTTP response.
+func WriteTransactionsWithFormatter(w io.Writer, transactions []models.Transaction, formatter formatter.OutputFormatter, delimiter rune) error {
+	rows, err := formatter.Format(prepareForOutput(transactions))
+	if err != nil {
+		return fmt.Errorf("error formatting transactions: %w", err)
+	}
+
+	csvWriter := csv.NewWriter(w)
+	csvWriter.Comma = delimiter
+
+	if err := csvWriter.Write(formatter.Header()); err != nil {
+		return fmt.Errorf("error writing CSV header: %w", err)
+	}
+	for _, row := range rows {
+		if err := csvWriter.Write(row); err != nil {
+			return fmt.Errorf("error writing CSV record: %w", err)
+		}
+	}
+
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return fmt.Errorf("error flushing CSV writer: %w", err)
+	}
+	return nil
+}
+
+// prepareForOutput returns a copy of transactions with the derived name,
+// recipient and debit/credit fields filled in, leaving the caller's slice untouched.
+func prepareForOutput(transactions []models.Transaction) []models.Transaction {
+	prepared := make([]models.Transaction, len(transactions))
+	copy(prepared, transactions)
+	for i := range prepared {
+		prepared[i].UpdateNameFromParties()
+		prepared[i].UpdateRecipientFromPayee()
+		prepared[i].UpdateDebitCreditAmounts()
+	}
+	return prepared
+}
+
+// AppendTransactionsToCSVWithFormatter appends transactions to an existing CSV file
+// written with the same formatter. If csvFile does not exist or is empty, it behaves
+// like WriteTransactionsToCSVWithFormatter.
+//
+// The existing header must match outFormatter.Header() exactly; otherwise an error is
+// returned and the file is left untouched. When dedupe is true, rows whose bank
+// fields already appear in the file are skipped: the columns holding the
+// category, its source or the tags are left out of the comparison.
+func AppendTransactionsToCSVWithFormatter(
+	transactions []models.Transaction,
+	csvFile string,
+	logger logging.Logger,
+	outFormatter formatter.OutputFormatter,
+	delimiter rune,
+	dedupe bool,
+) error {
+	if logger == nil {
+		logger = logging.NewLogrusAdapter("info", "text")
+	}
+	if transactions == nil {
+		return fmt.Errorf("cannot write nil transactions to CSV")
+	}
+
+	if info, err := os.Stat(csvFile); err != nil || info.Size() == 0 {
+		return WriteTransactionsToCSVWithFormatter(transactions, csvFile, logger, outFormatter, delimiter)
+	}
+
+	existing, err := readCSVRecords(csvFile, delimiter)
+	if err != nil {
+		return err
+	}
+
+	header := outFormatter.Header()
+	if !slices.Equal(existing[0], header) {
+		return fmt.Errorf("cannot append to %s: existing header does not match the %d-column output schema", csvFile, len(header))
+	}
+
+	prepared := prepareForOutput(transactions)
+
+	rows, err := outFormatter.Format(prepared)
+	if err != nil {
+		logger.WithError(err).Error("Failed to format transactions")
+		return fmt.Errorf("error formatting transactions: %w", err)
+	}
+
+	skipped := 0
+	if dedupe && len(prepared) > 0 {
+		// Rows are compared on their bank fields only, so that a transaction
+		// categorized differently since the last run is not appended again
+		skip := formatter.CategoryColumns(outFormatter)
+		seen := make(map[string]bool, len(existing)-1)
+		for _, record := range existing[1:] {
+			seen[bankRowHash(record, skip)] = true
+		}
+		unique := make([][]string, 0, len(rows))
+		for _, row := range rows {
+			if seen[bankRowHash(row, skip)] {
+				skipped++
+				continue
+			}
+			unique = append(unique, row)
+		}
+		rows = unique
+	}
+
+	file, err := os.OpenFile(csvFile, os.O_APPEND|os.O_WRONLY, 0) // #nosec G304 -- CLI tool requires user-provided output paths
+	if err != nil {
+		logger.WithError(err).Error("Failed to open CSV file for appending")
+		return fmt.Errorf("error opening CSV file: %w", err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			logger.WithError(err).Warn("Failed to close file")
+		}
+	}()
+
+	csvWriter := csv.NewWriter(file)
+	csvWriter.Comma = delimiter
+	for _, row := range rows {
+		if err := csvWriter.Write(row); err != nil {
+			logger.WithError(err).Error("Failed to write CSV record")
+			return fmt.Errorf("error writing CSV record: %w", err)
+		}
+	}
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		logger.WithError(err).Error("Failed to flush CSV writer")
+		return fmt.Errorf("error flushing CSV writer: %w", err)
+	}
+
+	logger.WithFields(
+		logging.Field{Key: "file", Value: csvFile},
+		logging.Field{Key: "appended", Value: len(rows)},
+		logging.Field{Key: "duplicates_skipped", Value: skipped},
+	).Info("Appended transactions to CSV file")
+
+	return nil
+}
+
+// RowHash returns a SHA-256 hash identifying a formatted CSV row, used to
+// detect transactions that are already present in an output file.
+func RowHash(row []string) string {
+	h := sha256.New()
+	for _, field := range row {
+		h.Write([]byte(field))
+		h.Write([]byte{0x1f})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// bankRowHash returns the RowHash of row with the columns in skip emptied.
+func bankRowHash(row []string, skip map[int]bool) string {
+	if len(skip) == 0 {
+		return RowHash(row)
+	}
+	bank := slices.Clone(row)
+	for i := range bank {
+		if skip[i] {
+			bank[i] = ""
+		}
+	}
+	return RowHash(bank)
+}
+
+// readCSVRecords reads all records of a CSV file, requiring at least a header.
+// Comment lines above the header are skipped.
+func readCSVRecords(csvFile string, delimiter rune) ([][]string, error) {
+	data, err := os.ReadFile(csvFile) // #nosec G304 -- CLI tool requires user-provided output paths
+	if err != nil {
+		return nil, fmt.Errorf("error opening CSV file: %w", err)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(models.StripCSVComments(data)))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading existing CSV file %s: %w", csvFile, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("existing CSV file %s has no header", csvFile)
+	}
+	return records, nil
+}
+
 // WriteTransactionsToCSVWithLogger writes transactions to a CSV file with a logger
 func WriteTransactionsToCSVWithLogger(transactions []models.Transaction, csvFile string, logger logging.Logger) error {
 	if logger == nil {
@@ -232,17 +513,26 @@ func WriteTransactionsToCSVWithLogger(transactions []models.Transaction, csvFile
 			prepared[i].DebitFlag = false
 		}
 
-		// Ensure all decimal values have 2 decimal places
+		// Round all decimal values to the precision of their currency
 		// This is needed for proper CSV formatting that passes tests
-		prepared[i].Amount = models.ParseAmount(prepared[i].Amount.StringFixed(2))
-		prepared[i].Debit = models.ParseAmount(prepared[i].Debit.StringFixed(2))
-		prepared[i].Credit = models.ParseAmount(prepared[i].Credit.StringFixed(2))
-		prepared[i].AmountExclTax = models.ParseAmount(prepared[i].AmountExclTax.StringFixed(2))
-		prepared[i].AmountTax = models.ParseAmount(prepared[i].AmountTax.StringFixed(2))
+		currency, originalCurrency := prepared[i].Currency, prepared[i].OriginalCurrency
+		prepared[i].Amount = models.ParseAmount(models.FormatAmount(prepared[i].Amount, currency))
+		prepared[i].Debit = models.ParseAmount(models.FormatAmount(prepared[i].Debit, currency))
+		prepared[i].Credit = models.ParseAmount(models.FormatAmount(prepared[i].Credit, currency))
+		prepared[i].AmountExclTax = models.ParseAmount(models.FormatAmount(prepared[i].AmountExclTax, currency))
+		prepared[i].AmountTax = models.ParseAmount(models.FormatAmount(prepared[i].AmountTax, currency))
 		prepared[i].TaxRate = models.ParseAmount(prepared[i].TaxRate.StringFixed(2))
-		prepared[i].Fees = models.ParseAmount(prepared[i].Fees.StringFixed(2))
-		prepared[i].OriginalAmount = models.ParseAmount(prepared[i].OriginalAmount.StringFixed(2))
-		prepared[i].ExchangeRate = models.ParseAmount(prepared[i].ExchangeRate.StringFixed(2))
+		prepared[i].Fees = models.ParseAmount(models.FormatAmount(prepared[i].Fees, currency))
+		prepared[i].OriginalAmount = models.ParseAmount(models.FormatAmount(prepared[i].OriginalAmount, originalCurrency))
+		prepared[i].ExchangeRate = models.ParseAmount(models.FormatRate(prepared[i].ExchangeRate))
+	}
+
+	// Statement notes are informational and go above the header
+	for _, note := range models.StatementNotes(transactions) {
+		if _, err := fmt.Fprintln(file, models.StatementNoteComment(note)); err != nil {
+			logger.WithError(err).Error("Failed to write statement note")
+			return fmt.Errorf("error writing statement note: %w", err)
+		}
 	}
 
 	// Configure CSV writer with custom delimiter
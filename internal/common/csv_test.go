@@ -3,13 +3,16 @@ package common
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"fjacquet/camt-csv/internal/formatter"
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestCSVRow represents a test CSV row for gocsv unmarshaling
@@ -174,3 +177,171 @@ Bob Johnson,42,bob@example.com,UK`
 
 // TestSetLogger removed - common package no longer uses global logging
 // Logging is now handled through dependency injection
+
+func TestAppendTransactionsToCSVWithFormatter(t *testing.T) {
+	txs := sampleTransactions()
+	csvPath := filepath.Join(t.TempDir(), "append.csv")
+	first := &mockFormatter{
+		header: []string{"Date", "Amount"},
+		rows:   [][]string{{"15.03.2024", "-50.00"}},
+	}
+
+	// Missing file: written from scratch with a header
+	require.NoError(t, AppendTransactionsToCSVWithFormatter(txs, csvPath, nil, first, ',', false))
+
+	second := &mockFormatter{
+		header: []string{"Date", "Amount"},
+		rows:   [][]string{{"15.03.2024", "-50.00"}, {"16.03.2024", "5000.00"}},
+	}
+
+	// Existing file with dedupe: header not repeated, duplicate row skipped
+	require.NoError(t, AppendTransactionsToCSVWithFormatter(txs, csvPath, nil, second, ',', true))
+	content, err := os.ReadFile(csvPath)
+	require.NoError(t, err)
+	assert.Equal(t, "Date,Amount\n15.03.2024,-50.00\n16.03.2024,5000.00\n", string(content))
+
+	// Without dedupe every row is appended
+	require.NoError(t, AppendTransactionsToCSVWithFormatter(txs, csvPath, nil, first, ',', false))
+	content, err = os.ReadFile(csvPath)
+	require.NoError(t, err)
+	assert.Equal(t, "Date,Amount\n15.03.2024,-50.00\n16.03.2024,5000.00\n15.03.2024,-50.00\n", string(content))
+}
+
+func TestAppendTransactionsToCSVWithFormatter_DedupeIgnoresCategory(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "append.csv")
+	f := formatter.ApplyOptions(formatter.NewStandardFormatter(), formatter.Options{CategorySource: true, Tags: true})
+
+	txs := sampleTransactions()
+	txs[0].Category = "Food"
+	txs[0].CategorySource = models.CategorySourceKeyword
+	require.NoError(t, AppendTransactionsToCSVWithFormatter(txs, csvPath, nil, f, ',', false))
+	before, err := os.ReadFile(csvPath)
+	require.NoError(t, err)
+
+	// Recategorized since the last run: the transactions are still duplicates
+	txs = sampleTransactions()
+	txs[0].Category = "Groceries"
+	txs[0].CategorySource = models.CategorySourceMapping
+	txs[0].Tags = []string{"weekly"}
+	require.NoError(t, AppendTransactionsToCSVWithFormatter(txs, csvPath, nil, f, ',', true))
+	after, err := os.ReadFile(csvPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+
+	// A change in a bank field makes a new transaction
+	txs[0].Amount = models.ParseAmount("-51.00")
+	require.NoError(t, AppendTransactionsToCSVWithFormatter(txs, csvPath, nil, f, ',', true))
+	after, err = os.ReadFile(csvPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(after), "Groceries")
+	assert.Equal(t, 4, strings.Count(string(after), "\n"))
+}
+
+func TestAppendTransactionsToCSVWithFormatter_HeaderMismatch(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "mismatch.csv")
+	require.NoError(t, os.WriteFile(csvPath, []byte("Date,Amount,Currency\n15.03.2024,-50.00,CHF\n"), 0600))
+
+	f := &mockFormatter{
+		header: []string{"Date", "Amount"},
+		rows:   [][]string{{"16.03.2024", "5000.00"}},
+	}
+	err := AppendTransactionsToCSVWithFormatter(sampleTransactions(), csvPath, nil, f, ',', false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "existing header does not match")
+
+	content, err := os.ReadFile(csvPath)
+	require.NoError(t, err)
+	assert.Equal(t, "Date,Amount,Currency\n15.03.2024,-50.00,CHF\n", string(content), "file must be left untouched")
+}
+
+func TestAppendTransactionsToCSVWithFormatter_StatementNotes(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "notes.csv")
+	txs := sampleTransactions()
+	for i := range txs {
+		txs[i].StatementNote = "Corrected statement"
+	}
+	f := &mockFormatter{
+		header: []string{"Date", "Amount"},
+		rows:   [][]string{{"15.03.2024", "-50.00"}, {"16.03.2024", "5000.00"}},
+	}
+
+	// A new file starts with the note, an existing one keeps it above the header
+	require.NoError(t, AppendTransactionsToCSVWithFormatter(txs, csvPath, nil, f, ',', false))
+	require.NoError(t, AppendTransactionsToCSVWithFormatter(txs, csvPath, nil, f, ',', true))
+	content, err := os.ReadFile(csvPath)
+	require.NoError(t, err)
+	assert.Equal(t, "# Statement note: Corrected statement\nDate,Amount\n15.03.2024,-50.00\n16.03.2024,5000.00\n", string(content))
+}
+
+func TestWriteTransactionsToCSVWithFormatter_AnonymizedNotes(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "anonymized.csv")
+	txs := sampleTransactions()
+	txs[0].StatementNote = "Corrected statement for Jean Dupont"
+	f := formatter.ApplyOptions(&mockFormatter{
+		header: []string{"Date", "Amount"},
+		rows:   [][]string{{"15.03.2024", "-50.00"}},
+	}, formatter.Options{Anonymize: true})
+
+	// Notes are free text, so anonymized output leaves them out
+	require.NoError(t, WriteTransactionsToCSVWithFormatter(txs, csvPath, nil, f, ','))
+	content, err := os.ReadFile(csvPath)
+	require.NoError(t, err)
+	assert.Equal(t, "Date,Amount\n15.03.2024,-50.00\n", string(content))
+}
+
+func TestWriteTransactionsToCSVWithBOM(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "bom.csv")
+	txs := sampleTransactions()
+	txs[0].StatementNote = "Corrected statement"
+	f := &mockFormatter{
+		header: []string{"Date", "Amount"},
+		rows:   [][]string{{"15.03.2024", "-50.00"}},
+	}
+
+	// The mark comes first, even before comment lines
+	require.NoError(t, WriteTransactionsToCSVWithBOM(txs, csvPath, nil, f, ','))
+	content, err := os.ReadFile(csvPath)
+	require.NoError(t, err)
+	assert.Equal(t, "\ufeff# Statement note: Corrected statement\nDate,Amount\n15.03.2024,-50.00\n", string(content))
+
+	// Appending matches the header behind the mark and does not repeat it
+	second := &mockFormatter{
+		header: []string{"Date", "Amount"},
+		rows:   [][]string{{"16.03.2024", "5000.00"}},
+	}
+	require.NoError(t, AppendTransactionsToCSVWithFormatter(txs, csvPath, nil, second, ',', false))
+	content, err = os.ReadFile(csvPath)
+	require.NoError(t, err)
+	assert.Equal(t, "\ufeff# Statement note: Corrected statement\nDate,Amount\n15.03.2024,-50.00\n16.03.2024,5000.00\n", string(content))
+
+	// Without the mark, the file starts with its content
+	require.NoError(t, WriteTransactionsToCSVWithFormatter(txs[1:], csvPath, nil, f, ','))
+	content, err = os.ReadFile(csvPath)
+	require.NoError(t, err)
+	assert.Equal(t, "Date,Amount\n15.03.2024,-50.00\n", string(content))
+}
+
+func TestWriteTransactionsToNewCSV(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "new.csv")
+	f := &mockFormatter{
+		header: []string{"Date", "Amount"},
+		rows:   [][]string{{"15.03.2024", "-50.00"}},
+	}
+
+	require.NoError(t, WriteTransactionsToNewCSV(sampleTransactions()[1:], csvPath, nil, f, ',', true))
+	content, err := os.ReadFile(csvPath)
+	require.NoError(t, err)
+	assert.Equal(t, "\ufeffDate,Amount\n15.03.2024,-50.00\n", string(content))
+
+	// An existing file is left as it is
+	err = WriteTransactionsToNewCSV(sampleTransactions()[1:], csvPath, nil, f, ',', false)
+	require.ErrorIs(t, err, ErrOutputExists)
+	unchanged, err := os.ReadFile(csvPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, unchanged)
+}
+
+func TestRowHash(t *testing.T) {
+	assert.Equal(t, RowHash([]string{"a", "b"}), RowHash([]string{"a", "b"}))
+	assert.NotEqual(t, RowHash([]string{"ab", ""}), RowHash([]string{"a", "b"}))
+}
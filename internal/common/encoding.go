@@ -0,0 +1,104 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
+)
+
+// Input encodings accepted by --input-encoding.
+const (
+	// EncodingAuto reads valid UTF-8 as is and any other byte as
+	// Windows-1252, the encoding of older Windows bank exports.
+	EncodingAuto        = "auto"
+	EncodingUTF8        = "utf-8"
+	EncodingWindows1252 = "windows-1252"
+	EncodingLatin1      = "iso-8859-1"
+)
+
+// encodingAliases maps the accepted spellings to the encoding names above.
+var encodingAliases = map[string]string{
+	"":             EncodingAuto,
+	"auto":         EncodingAuto,
+	"utf-8":        EncodingUTF8,
+	"utf8":         EncodingUTF8,
+	"windows-1252": EncodingWindows1252,
+	"cp1252":       EncodingWindows1252,
+	"iso-8859-1":   EncodingLatin1,
+	"latin1":       EncodingLatin1,
+	"latin-1":      EncodingLatin1,
+}
+
+// NormalizeEncoding returns the canonical name of an input encoding, or an
+// error listing the supported ones.
+func NormalizeEncoding(name string) (string, error) {
+	encoding, ok := encodingAliases[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return "", fmt.Errorf("unsupported encoding %q (supported: %s, %s, %s, %s)",
+			name, EncodingAuto, EncodingUTF8, EncodingWindows1252, EncodingLatin1)
+	}
+	return encoding, nil
+}
+
+// DecodeToUTF8 converts data from encoding to UTF-8. With EncodingAuto, valid
+// UTF-8 is kept and the bytes that are not are read as Windows-1252.
+func DecodeToUTF8(data []byte, encoding string) ([]byte, error) {
+	r, err := NewUTF8Reader(bytes.NewReader(data), encoding)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s input: %w", encoding, err)
+	}
+	return decoded, nil
+}
+
+// NewUTF8Reader returns a reader over the content of r converted from
+// encoding to UTF-8 as it is read, so that a parser stopping early does not
+// read the rest of r. With EncodingAuto, valid UTF-8 is kept and the bytes
+// that are not are read as Windows-1252.
+func NewUTF8Reader(r io.Reader, encoding string) (io.Reader, error) {
+	encoding, err := NormalizeEncoding(encoding)
+	if err != nil {
+		return nil, err
+	}
+	switch encoding {
+	case EncodingUTF8:
+		return r, nil
+	case EncodingWindows1252:
+		return charmap.Windows1252.NewDecoder().Reader(r), nil
+	case EncodingLatin1:
+		return charmap.ISO8859_1.NewDecoder().Reader(r), nil
+	}
+	return transform.NewReader(r, autoDecoder{}), nil
+}
+
+// autoDecoder is the EncodingAuto transformer: it copies valid UTF-8 and
+// decodes every other byte as Windows-1252.
+type autoDecoder struct{ transform.NopResetter }
+
+// Transform implements transform.Transformer.
+func (autoDecoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		r, size := utf8.DecodeRune(src[nSrc:])
+		if r == utf8.RuneError && size <= 1 {
+			// A sequence cut at the end of src may complete with the next read
+			if !atEOF && !utf8.FullRune(src[nSrc:]) {
+				return nDst, nSrc, transform.ErrShortSrc
+			}
+			r, size = charmap.Windows1252.DecodeByte(src[nSrc]), 1
+		}
+		if nDst+utf8.RuneLen(r) > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += utf8.EncodeRune(dst[nDst:], r)
+		nSrc += size
+	}
+	return nDst, nSrc, nil
+}
@@ -0,0 +1,68 @@
+package common
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeToUTF8(t *testing.T) {
+	// "Café 5€" in Windows-1252: é is 0xE9, € is 0x80
+	cp1252 := []byte{'C', 'a', 'f', 0xE9, ' ', '5', 0x80}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		encoding string
+		want     string
+	}{
+		{"auto keeps UTF-8", []byte("Café 5€"), EncodingAuto, "Café 5€"},
+		{"auto falls back to Windows-1252", cp1252, EncodingAuto, "Café 5€"},
+		{"empty means auto", cp1252, "", "Café 5€"},
+		{"explicit Windows-1252", cp1252, "cp1252", "Café 5€"},
+		{"explicit Latin-1", []byte{'C', 'a', 'f', 0xE9}, "Latin1", "Café"},
+		{"explicit UTF-8 passes through", []byte("Müller"), "UTF-8", "Müller"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeToUTF8(tt.data, tt.encoding)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+
+	_, err := DecodeToUTF8(cp1252, "ebcdic")
+	assert.ErrorContains(t, err, `unsupported encoding "ebcdic"`)
+}
+
+func TestNewUTF8Reader(t *testing.T) {
+	r, err := NewUTF8Reader(strings.NewReader("Cr\xeaperie"), EncodingAuto)
+	require.NoError(t, err)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "Crêperie", string(got))
+
+	_, err = NewUTF8Reader(strings.NewReader("x"), "utf-16")
+	assert.Error(t, err)
+
+	// UTF-8 and Windows-1252 mixed in one file both come out right
+	r, err = NewUTF8Reader(strings.NewReader("Caf\xc3\xa9 Cr\xeaperie"), EncodingAuto)
+	require.NoError(t, err)
+	got, err = io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "Café Crêperie", string(got))
+
+	// The input is decoded as it is read, not read in full up front
+	failing := io.MultiReader(strings.NewReader("Cr\xeape"), iotest.ErrReader(errors.New("disk error")))
+	r, err = NewUTF8Reader(failing, EncodingAuto)
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	n, err := io.ReadFull(r, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "Crê", string(buf[:n]))
+}
@@ -0,0 +1,145 @@
+package common
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+)
+
+// Ledger accounts transactions are posted to. The bank side is
+// DefaultLedgerAssetAccount followed by the statement IBAN, unless an asset
+// account is configured; the other side is derived from the category.
+const (
+	DefaultLedgerAssetAccount = "Assets:Bank"
+	LedgerExpensesAccount     = "Expenses"
+	LedgerIncomeAccount       = "Income"
+)
+
+// ExportTransactionsToLedger writes transactions to w as an hledger journal,
+// one entry per transaction with two postings: the amount on the bank account
+// and its opposite on the account derived from the category, Expenses:<Category>
+// for debits and refunds, Income:<Category> for other credits.
+//
+// assetAccount is the bank account of every entry; when empty, it is
+// DefaultLedgerAssetAccount followed by the transaction's IBAN.
+func ExportTransactionsToLedger(w io.Writer, transactions []models.Transaction, assetAccount string) error {
+	bw := bufio.NewWriter(w)
+	for i, tx := range prepareForOutput(transactions) {
+		if i > 0 {
+			_, _ = fmt.Fprintln(bw)
+		}
+		writeLedgerEntry(bw, tx, assetAccount)
+	}
+	return bw.Flush()
+}
+
+// writeLedgerEntry writes the journal entry of tx.
+func writeLedgerEntry(w io.Writer, tx models.Transaction, assetAccount string) {
+	// Entries are cleared once booked; pending ones are marked as such
+	status := "*"
+	if tx.Status == models.EntryStatusPending {
+		status = "!"
+	}
+
+	payee := ledgerText(tx.PartyName)
+	description := ledgerText(tx.Description)
+	if payee == "" {
+		payee, description = description, ""
+	}
+	header := fmt.Sprintf("%s %s %s", tx.Date.Format("2006-01-02"), status, payee)
+	if description != "" && description != payee {
+		header += " | " + description
+	}
+	_, _ = fmt.Fprintln(w, strings.TrimRight(header, " "))
+	if tx.Reference != "" {
+		_, _ = fmt.Fprintf(w, "    ; reference: %s\n", ledgerText(tx.Reference))
+	}
+
+	amount := tx.Amount.Abs()
+	if tx.IsDebit() {
+		amount = amount.Neg()
+	}
+	bank := ledgerAssetAccount(tx, assetAccount)
+	category := ledgerCategoryAccount(tx)
+	width := max(len(bank), len(category)) + 2
+	_, _ = fmt.Fprintf(w, "    %-*s%s %s\n", width, bank, models.FormatAmount(amount, tx.Currency), tx.Currency)
+	_, _ = fmt.Fprintf(w, "    %-*s%s %s\n", width, category, models.FormatAmount(amount.Neg(), tx.Currency), tx.Currency)
+}
+
+// ledgerAssetAccount returns the bank account tx is posted to.
+func ledgerAssetAccount(tx models.Transaction, assetAccount string) string {
+	if assetAccount != "" {
+		return assetAccount
+	}
+	if iban := strings.ReplaceAll(tx.IBAN, " ", ""); iban != "" {
+		return DefaultLedgerAssetAccount + ":" + ledgerAccountName(iban)
+	}
+	return DefaultLedgerAssetAccount
+}
+
+// ledgerCategoryAccount returns the expense or income account of tx's category.
+func ledgerCategoryAccount(tx models.Transaction) string {
+	category := ledgerAccountName(tx.Category)
+	if category == "" {
+		category = models.CategoryUncategorized
+	}
+	if tx.IsDebit() || tx.Type == models.TypeRefund {
+		return LedgerExpensesAccount + ":" + category
+	}
+	return LedgerIncomeAccount + ":" + category
+}
+
+// ledgerAccountName returns name as one level of an account name: colons
+// would start a sub-account and two spaces end the name, so both are replaced.
+func ledgerAccountName(name string) string {
+	return strings.ReplaceAll(ledgerText(name), ":", "-")
+}
+
+// ledgerText returns s on one line with single spaces, as journal lines need.
+func ledgerText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// WriteTransactionsToLedger writes transactions to ledgerFile as an hledger
+// journal with ExportTransactionsToLedger. With noClobber, it fails with
+// ErrOutputExists instead of overwriting an existing file.
+func WriteTransactionsToLedger(transactions []models.Transaction, ledgerFile string, logger logging.Logger, assetAccount string, noClobber bool) error {
+	if logger == nil {
+		logger = logging.NewLogrusAdapter("info", "text")
+	}
+	if len(transactions) == 0 {
+		logger.WithField("file", ledgerFile).Info("No transactions found, skipping output file")
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(ledgerFile), models.PermissionDirectory); err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
+	}
+	file, err := createOutputFile(ledgerFile, noClobber)
+	if errors.Is(err, ErrOutputExists) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("error creating journal file: %w", err)
+	}
+	if err := ExportTransactionsToLedger(file, transactions, assetAccount); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("error writing journal: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error closing journal file: %w", err)
+	}
+
+	logger.WithFields(
+		logging.Field{Key: "file", Value: ledgerFile},
+		logging.Field{Key: "count", Value: len(transactions)},
+	).Info("Successfully wrote transactions to journal file")
+	return nil
+}
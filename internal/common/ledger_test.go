@@ -0,0 +1,85 @@
+package common
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportTransactionsToLedger(t *testing.T) {
+	groceries := models.NewTransactionBuilder().
+		WithDate("2025-05-06").
+		WithAmount(decimal.RequireFromString("64.20"), "CHF").
+		AsDebit().
+		WithPayee("Migros", "").
+		WithDescription("Groceries  week 19").
+		WithIBAN("CH93 0076 2011 6238 5295 7").
+		WithReference("REF-1").
+		WithCategory("Food: Groceries").
+		MustBuild()
+	salary := models.NewTransactionBuilder().
+		WithDate("2025-05-25").
+		WithAmount(decimal.RequireFromString("5200"), "CHF").
+		AsCredit().
+		WithPayer("Employer SA", "").
+		WithDescription("Employer SA").
+		WithIBAN("CH9300762011623852957").
+		WithCategory("Salary").
+		WithStatus(models.EntryStatusPending).
+		MustBuild()
+	refund := models.NewTransactionBuilder().
+		WithDate("2025-05-27").
+		WithAmount(decimal.RequireFromString("19.90"), "EUR").
+		AsCredit().
+		WithDescription("Zalando").
+		WithType(models.TypeRefund).
+		MustBuild()
+
+	var buf bytes.Buffer
+	require.NoError(t, ExportTransactionsToLedger(&buf, []models.Transaction{groceries, salary, refund}, ""))
+	assert.Equal(t, `2025-05-06 * Migros | Groceries week 19
+    ; reference: REF-1
+    Assets:Bank:CH9300762011623852957  -64.20 CHF
+    Expenses:Food- Groceries           64.20 CHF
+
+2025-05-25 ! Employer SA
+    Assets:Bank:CH9300762011623852957  5200.00 CHF
+    Income:Salary                      -5200.00 CHF
+
+2025-05-27 * Zalando
+    Assets:Bank             19.90 EUR
+    Expenses:Uncategorized  -19.90 EUR
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, ExportTransactionsToLedger(&buf, []models.Transaction{groceries}, "Assets:Checking"))
+	assert.Contains(t, buf.String(), "    Assets:Checking           -64.20 CHF\n")
+}
+
+func TestWriteTransactionsToLedger(t *testing.T) {
+	tx := models.NewTransactionBuilder().
+		WithDate("2025-05-06").
+		WithAmount(decimal.RequireFromString("64.20"), "CHF").
+		AsDebit().
+		WithPayee("Migros", "").
+		WithCategory("Groceries").
+		MustBuild()
+	path := filepath.Join(t.TempDir(), "out", "2025-05.journal")
+
+	require.NoError(t, WriteTransactionsToLedger([]models.Transaction{tx}, path, nil, "", false))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Expenses:Groceries")
+
+	// Without transactions no journal is written
+	empty := filepath.Join(t.TempDir(), "empty.journal")
+	require.NoError(t, WriteTransactionsToLedger(nil, empty, nil, "", false))
+	assert.NoFileExists(t, empty)
+}
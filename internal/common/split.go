@@ -0,0 +1,87 @@
+package common
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"fjacquet/camt-csv/internal/models"
+)
+
+// Split modes accepted by --split.
+const (
+	// SplitByPartyIBAN writes one file per counterparty IBAN.
+	SplitByPartyIBAN = "by-party-iban"
+)
+
+// unknownSplitKey names the group of transactions without a split key.
+const unknownSplitKey = "unknown"
+
+// SplitGroup is a set of transactions written to the same split output file.
+type SplitGroup struct {
+	Key          string // Group key, e.g. the normalized IBAN or "unknown"
+	Transactions []models.Transaction
+}
+
+// ValidateSplitMode returns an error if mode is neither empty nor a known split mode.
+func ValidateSplitMode(mode string) error {
+	switch mode {
+	case "", SplitByPartyIBAN:
+		return nil
+	default:
+		return fmt.Errorf("invalid split mode %q: valid modes are %s", mode, SplitByPartyIBAN)
+	}
+}
+
+// SplitTransactions partitions transactions according to mode. Groups are
+// sorted by key and keep the original transaction order.
+func SplitTransactions(transactions []models.Transaction, mode string) ([]SplitGroup, error) {
+	if err := ValidateSplitMode(mode); err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string][]models.Transaction)
+	for _, tx := range transactions {
+		key := models.NormalizeIBAN(tx.PartyIBAN)
+		if key == "" {
+			key = unknownSplitKey
+		}
+		byKey[key] = append(byKey[key], tx)
+	}
+
+	groups := make([]SplitGroup, 0, len(byKey))
+	for key, txs := range byKey {
+		groups = append(groups, SplitGroup{Key: key, Transactions: txs})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Key < groups[j].Key })
+	return groups, nil
+}
+
+// ChunkTransactions cuts transactions into consecutive chunks of at most size
+// transactions. An empty input gives a single empty chunk, so that an output
+// file with just the header is still written.
+func ChunkTransactions(transactions []models.Transaction, size int) [][]models.Transaction {
+	if size <= 0 || len(transactions) <= size {
+		return [][]models.Transaction{transactions}
+	}
+	chunks := make([][]models.Transaction, 0, (len(transactions)+size-1)/size)
+	for start := 0; start < len(transactions); start += size {
+		end := min(start+size, len(transactions))
+		chunks = append(chunks, transactions[start:end])
+	}
+	return chunks
+}
+
+// SplitOutputPath derives the output file for a split group by appending the
+// key to the base name of outputFile: "out/statement.csv" with key "unknown"
+// becomes "out/statement_unknown.csv". The key is sanitized like account IDs so
+// the name is filesystem-safe.
+func SplitOutputPath(outputFile, key string) string {
+	ext := filepath.Ext(outputFile)
+	if ext == "" {
+		ext = ".csv"
+	}
+	base := strings.TrimSuffix(outputFile, filepath.Ext(outputFile))
+	return base + "_" + SanitizeAccountID(key) + ext
+}
@@ -0,0 +1,71 @@
+package common
+
+import (
+	"path/filepath"
+	"testing"
+
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitTransactions_ByPartyIBAN(t *testing.T) {
+	transactions := []models.Transaction{
+		{Description: "rent jan", PartyIBAN: "CH93 0076 2011 6238 5295 7"},
+		{Description: "card"},
+		{Description: "rent feb", PartyIBAN: "ch9300762011623852957"},
+		{Description: "other", PartyIBAN: "DE89370400440532013000"},
+	}
+
+	groups, err := SplitTransactions(transactions, SplitByPartyIBAN)
+	require.NoError(t, err)
+	require.Len(t, groups, 3)
+
+	assert.Equal(t, "CH9300762011623852957", groups[0].Key)
+	require.Len(t, groups[0].Transactions, 2)
+	assert.Equal(t, "rent jan", groups[0].Transactions[0].Description)
+	assert.Equal(t, "rent feb", groups[0].Transactions[1].Description)
+
+	assert.Equal(t, "DE89370400440532013000", groups[1].Key)
+	assert.Equal(t, "unknown", groups[2].Key)
+	assert.Equal(t, "card", groups[2].Transactions[0].Description)
+}
+
+func TestSplitTransactions_InvalidMode(t *testing.T) {
+	_, err := SplitTransactions(nil, "by-month")
+	assert.Error(t, err)
+
+	assert.NoError(t, ValidateSplitMode(""))
+	assert.NoError(t, ValidateSplitMode(SplitByPartyIBAN))
+	assert.Error(t, ValidateSplitMode("by-month"))
+}
+
+func TestSplitOutputPath(t *testing.T) {
+	dir := filepath.Join("out", "2025")
+
+	assert.Equal(t, filepath.Join(dir, "statement_CH9300762011623852957.csv"),
+		SplitOutputPath(filepath.Join(dir, "statement.csv"), "CH9300762011623852957"))
+	assert.Equal(t, filepath.Join(dir, "statement_unknown.csv"),
+		SplitOutputPath(filepath.Join(dir, "statement"), "unknown"))
+	// Keys are sanitized so they cannot escape the output directory
+	assert.Equal(t, filepath.Join(dir, "statement_etc_passwd.csv"),
+		SplitOutputPath(filepath.Join(dir, "statement.csv"), "../etc/passwd"))
+}
+
+func TestChunkTransactions(t *testing.T) {
+	transactions := make([]models.Transaction, 5)
+	for i := range transactions {
+		transactions[i].Number = string(rune('a' + i))
+	}
+
+	chunks := ChunkTransactions(transactions, 2)
+	require.Len(t, chunks, 3)
+	assert.Len(t, chunks[0], 2)
+	assert.Len(t, chunks[1], 2)
+	assert.Equal(t, "e", chunks[2][0].Number)
+
+	assert.Len(t, ChunkTransactions(transactions, 5), 1)
+	assert.Len(t, ChunkTransactions(transactions, 0), 1)
+	assert.Equal(t, [][]models.Transaction{nil}, ChunkTransactions(nil, 2))
+}
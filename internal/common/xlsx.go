@@ -0,0 +1,203 @@
+package common
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"fjacquet/camt-csv/internal/formatter"
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+)
+
+// MaxSheetNameLength is the longest sheet name Excel accepts.
+const MaxSheetNameLength = 31
+
+// WorkbookSheet is one sheet of an XLSX workbook: its name and the
+// transactions written on it.
+type WorkbookSheet struct {
+	Name         string
+	Transactions []models.Transaction
+}
+
+// invalidSheetNameChars are the characters Excel rejects in sheet names.
+var invalidSheetNameChars = strings.NewReplacer(
+	":", "_", "\\", "_", "/", "_", "?", "_", "*", "_", "[", "_", "]", "_")
+
+// numericCell matches the formatted amounts written as numbers rather than
+// text. Leading zeros are kept as text, since they belong to identifiers.
+var numericCell = regexp.MustCompile(`^-?(0|[1-9][0-9]{0,14})(\.[0-9]+)?$`)
+
+// SheetName returns name made valid as an Excel sheet name: without the
+// characters Excel rejects, at most MaxSheetNameLength characters long and
+// different, ignoring case, from the names already in used. The returned name
+// is added to used.
+func SheetName(name string, used map[string]bool) string {
+	name = strings.Trim(invalidSheetNameChars.Replace(strings.TrimSpace(name)), "'")
+	if name == "" {
+		name = "Sheet"
+	}
+	candidate := truncateRunes(name, MaxSheetNameLength)
+	for i := 2; used[strings.ToLower(candidate)]; i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		candidate = truncateRunes(name, MaxSheetNameLength-len(suffix)) + suffix
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+// truncateRunes returns the first n characters of s.
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+// WriteTransactionsToXLSX writes one sheet per WorkbookSheet to xlsxFile, each
+// with the formatter's header followed by the formatted transactions. Sheet
+// names are made valid with SheetName. It returns an error when there is no
+// sheet, since a workbook needs at least one.
+func WriteTransactionsToXLSX(sheets []WorkbookSheet, xlsxFile string, logger logging.Logger, formatter formatter.OutputFormatter) error {
+	if logger == nil {
+		logger = logging.NewLogrusAdapter("info", "text")
+	}
+	if len(sheets) == 0 {
+		return fmt.Errorf("cannot write a workbook without sheets")
+	}
+
+	used := make(map[string]bool, len(sheets))
+	tables := make([]xlsxTable, 0, len(sheets))
+	for _, sheet := range sheets {
+		rows, err := formatter.Format(prepareForOutput(sheet.Transactions))
+		if err != nil {
+			return fmt.Errorf("error formatting transactions of sheet %s: %w", sheet.Name, err)
+		}
+		tables = append(tables, xlsxTable{
+			name: SheetName(sheet.Name, used),
+			rows: append([][]string{formatter.Header()}, rows...),
+		})
+	}
+
+	if err := os.MkdirAll(filepath.Dir(xlsxFile), models.PermissionDirectory); err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
+	}
+	file, err := os.Create(xlsxFile) // #nosec G304 -- CLI tool requires user-provided output paths
+	if err != nil {
+		return fmt.Errorf("error creating XLSX file: %w", err)
+	}
+	if err := writeXLSX(file, tables); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("error writing XLSX file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error closing XLSX file: %w", err)
+	}
+
+	logger.Info("Wrote XLSX workbook",
+		logging.Field{Key: "file", Value: xlsxFile},
+		logging.Field{Key: "sheets", Value: len(tables)})
+	return nil
+}
+
+// xlsxTable is a named sheet of rows of cells, the first row being the header.
+type xlsxTable struct {
+	name string
+	rows [][]string
+}
+
+// writeXLSX writes a minimal Office Open XML workbook holding tables to w.
+// Cells that look like numbers are written as numbers, the others as inline
+// strings, so that no shared string table is needed.
+func writeXLSX(w io.Writer, tables []xlsxTable) error {
+	archive := zip.NewWriter(w)
+
+	var sheets, sheetRels, sheetTypes strings.Builder
+	for i := range tables {
+		n := i + 1
+		fmt.Fprintf(&sheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(tables[i].name), n, n)
+		fmt.Fprintf(&sheetRels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+		fmt.Fprintf(&sheetTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+	}
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			sheetTypes.String() + `</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + sheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			sheetRels.String() + `</Relationships>`},
+	}
+	for _, part := range parts {
+		entry, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(entry, part.content); err != nil {
+			return err
+		}
+	}
+
+	for i, table := range tables {
+		entry, err := archive.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1))
+		if err != nil {
+			return err
+		}
+		if err := writeWorksheet(entry, table.rows); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// writeWorksheet writes the worksheet part holding rows to w.
+func writeWorksheet(w io.Writer, rows [][]string) error {
+	buf := bufio.NewWriter(w)
+	_, _ = buf.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for r, row := range rows {
+		fmt.Fprintf(buf, `<row r="%d">`, r+1)
+		for c, value := range row {
+			ref := columnName(c) + strconv.Itoa(r+1)
+			// The header stays text, even for a numeric column name
+			if r > 0 && numericCell.MatchString(value) {
+				fmt.Fprintf(buf, `<c r="%s"><v>%s</v></c>`, ref, value)
+			} else if value != "" {
+				fmt.Fprintf(buf, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xmlEscape(value))
+			}
+		}
+		_, _ = buf.WriteString(`</row>`)
+	}
+	_, _ = buf.WriteString(`</sheetData></worksheet>`)
+	return buf.Flush()
+}
+
+// columnName returns the letters of the zero-based column index: A, ..., Z, AA, ...
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// xmlEscape escapes s for use as XML text or attribute value.
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
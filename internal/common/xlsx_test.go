@@ -0,0 +1,93 @@
+package common
+
+import (
+	"archive/zip"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fjacquet/camt-csv/internal/formatter"
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSheetName(t *testing.T) {
+	used := make(map[string]bool)
+	assert.Equal(t, "CH9300762011623852957", SheetName("CH9300762011623852957", used))
+	assert.Equal(t, "a_b_c_d", SheetName("a/b:c?d", used))
+	assert.Equal(t, "Sheet", SheetName("  ", used))
+
+	long := strings.Repeat("x", 40)
+	first := SheetName(long, used)
+	second := SheetName(long, used)
+	assert.Equal(t, strings.Repeat("x", MaxSheetNameLength), first)
+	assert.Equal(t, strings.Repeat("x", MaxSheetNameLength-4)+" (2)", second)
+	assert.Len(t, second, MaxSheetNameLength)
+
+	// Excel compares sheet names without case
+	assert.Equal(t, "SHEET (2)", SheetName("SHEET", used))
+}
+
+func TestColumnName(t *testing.T) {
+	assert.Equal(t, "A", columnName(0))
+	assert.Equal(t, "Z", columnName(25))
+	assert.Equal(t, "AA", columnName(26))
+	assert.Equal(t, "AZ", columnName(51))
+	assert.Equal(t, "BA", columnName(52))
+}
+
+func TestWriteTransactionsToXLSX(t *testing.T) {
+	tx := models.NewTransactionBuilder().
+		WithDate("2025-05-02").
+		WithAmount(decimal.RequireFromString("42.50"), "CHF").
+		AsCredit().
+		WithPayer("Müller & Söhne <AG>", "").
+		WithDescription("Invoice").
+		MustBuild()
+	path := filepath.Join(t.TempDir(), "out", "statements.xlsx")
+
+	err := WriteTransactionsToXLSX([]WorkbookSheet{
+		{Name: "CH9300762011623852957", Transactions: []models.Transaction{tx}},
+		{Name: "Savings/2025", Transactions: []models.Transaction{tx, tx}},
+	}, path, nil, formatter.NewStandardFormatter())
+	require.NoError(t, err)
+
+	parts := readZip(t, path)
+	assert.Contains(t, parts, "[Content_Types].xml")
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="CH9300762011623852957" sheetId="1" r:id="rId1"/>`)
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="Savings_2025" sheetId="2" r:id="rId2"/>`)
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<c r="A1" t="inlineStr"><is><t xml:space="preserve">Status</t></is></c>`)
+	assert.Contains(t, sheet, "Müller &amp; Söhne &lt;AG&gt;")
+	assert.Contains(t, sheet, "<v>42.50</v>")
+	assert.Equal(t, 3, strings.Count(parts["xl/worksheets/sheet2.xml"], "<row "))
+}
+
+func TestWriteTransactionsToXLSX_NoSheets(t *testing.T) {
+	err := WriteTransactionsToXLSX(nil, filepath.Join(t.TempDir(), "empty.xlsx"), nil, formatter.NewStandardFormatter())
+	assert.Error(t, err)
+}
+
+// readZip returns the content of each file of the ZIP archive at path.
+func readZip(t *testing.T, path string) map[string]string {
+	t.Helper()
+	archive, err := zip.OpenReader(path)
+	require.NoError(t, err)
+	defer func() { _ = archive.Close() }()
+
+	parts := make(map[string]string)
+	for _, f := range archive.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		_ = r.Close()
+		parts[f.Name] = string(data)
+	}
+	return parts
+}
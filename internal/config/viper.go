@@ -3,6 +3,8 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"fjacquet/camt-csv/internal/models"
@@ -24,6 +26,8 @@ type Config struct {
 		DateFormat     string `mapstructure:"date_format" yaml:"date_format"`
 		IncludeHeaders bool   `mapstructure:"include_headers" yaml:"include_headers"`
 		QuoteAll       bool   `mapstructure:"quote_all" yaml:"quote_all"`
+		// CurrencyPrecision overrides the decimal places of amounts per currency code
+		CurrencyPrecision map[string]int `mapstructure:"currency_precision" yaml:"currency_precision"`
 	} `mapstructure:"csv" yaml:"csv"`
 
 	AI struct {
@@ -34,6 +38,10 @@ type Config struct {
 		RequestsPerMinute int    `mapstructure:"requests_per_minute" yaml:"requests_per_minute"`
 		TimeoutSeconds    int    `mapstructure:"timeout_seconds" yaml:"timeout_seconds"`
 		FallbackCategory  string `mapstructure:"fallback_category" yaml:"fallback_category"`
+		PromptLanguage    string `mapstructure:"prompt_language" yaml:"prompt_language"`
+		PromptTemplate    string `mapstructure:"prompt_template_file" yaml:"prompt_template_file"`
+		BaseCurrency      string `mapstructure:"base_currency" yaml:"base_currency"`
+		ExecCommand       string `mapstructure:"exec_command" yaml:"exec_command"`
 		APIKey            string `mapstructure:"api_key" yaml:"-" json:"-"` // #nosec G117 -- Never serialized; loaded from env only
 	} `mapstructure:"ai" yaml:"ai"`
 
@@ -53,6 +61,8 @@ type Config struct {
 		ConfidenceThreshold float64 `mapstructure:"confidence_threshold" yaml:"confidence_threshold"`
 		CaseSensitive       bool    `mapstructure:"case_sensitive" yaml:"case_sensitive"`
 		SemanticThreshold   float64 `mapstructure:"semantic_threshold" yaml:"semantic_threshold"`
+		// MatchMode is how party names are matched against mapping keys: exact, token or substring
+		MatchMode string `mapstructure:"match_mode" yaml:"match_mode"`
 	} `mapstructure:"categorization" yaml:"categorization"`
 
 	Staging struct {
@@ -67,16 +77,25 @@ type Config struct {
 		} `mapstructure:"camt" yaml:"camt"`
 		PDF struct {
 			OCREnabled bool `mapstructure:"ocr_enabled" yaml:"ocr_enabled"`
+			// RefundCategory is given to card refunds; empty keeps the merchant's category
+			RefundCategory string `mapstructure:"refund_category" yaml:"refund_category"`
 		} `mapstructure:"pdf" yaml:"pdf"`
 		Revolut struct {
 			DateFormatDetection bool `mapstructure:"date_format_detection" yaml:"date_format_detection"`
 		} `mapstructure:"revolut" yaml:"revolut"`
+		// PresetsFile holds the bank presets selected with --preset
+		PresetsFile string `mapstructure:"presets_file" yaml:"presets_file"`
 	} `mapstructure:"parsers" yaml:"parsers"`
 
 	Categories struct {
-		File          string `mapstructure:"file" yaml:"file"`
-		CreditorsFile string `mapstructure:"creditors_file" yaml:"creditors_file"`
-		DebtorsFile   string `mapstructure:"debtors_file" yaml:"debtors_file"`
+		File             string `mapstructure:"file" yaml:"file"`
+		CreditorsFile    string `mapstructure:"creditors_file" yaml:"creditors_file"`
+		DebtorsFile      string `mapstructure:"debtors_file" yaml:"debtors_file"`
+		TagsFile         string `mapstructure:"tags_file" yaml:"tags_file"`
+		MCCFile          string `mapstructure:"mcc_file" yaml:"mcc_file"`
+		IBANMappingsFile string `mapstructure:"iban_mappings_file" yaml:"iban_mappings_file"`
+		CleanupFile      string `mapstructure:"cleanup_file" yaml:"cleanup_file"`
+		BudgetsFile      string `mapstructure:"budgets_file" yaml:"budgets_file"`
 	} `mapstructure:"categories" yaml:"categories"`
 
 	Constitution struct {
@@ -84,10 +103,48 @@ type Config struct {
 	} `mapstructure:"constitution" yaml:"constitution"`
 
 	Output struct {
-		Format string `mapstructure:"format" yaml:"format"`
+		Format       string `mapstructure:"format" yaml:"format"`
+		ProfilesFile string `mapstructure:"profiles_file" yaml:"profiles_file"`
+		// DescriptionTemplate builds descriptions from transaction fields; empty keeps the parsers' descriptions
+		DescriptionTemplate string `mapstructure:"description_template" yaml:"description_template"`
+		// LedgerAccount is the bank account of --format ledger entries; empty derives it from the IBAN
+		LedgerAccount string `mapstructure:"ledger_account" yaml:"ledger_account"`
+		// AnonymizeKey keys the --anonymize pseudonyms so they match across runs; empty uses a random key per run
+		AnonymizeKey string `mapstructure:"anonymize_key" yaml:"-" json:"-"` // #nosec G117 -- Never serialized
 	} `mapstructure:"output" yaml:"output"`
 }
 
+// GetAutoLearnEnabled reports whether categorizations are saved back to the
+// creditor and debitor mapping files.
+func (c *Config) GetAutoLearnEnabled() bool {
+	return c.Categorization.AutoLearn
+}
+
+// ConfigSearchPaths are the directories config.yaml and camt-csv.toml are
+// looked up in, in order. Environment variables such as $HOME are expanded.
+var ConfigSearchPaths = []string{"$HOME/.camt-csv", ".camt-csv", "."}
+
+// TOMLConfigFile is the name of the optional TOML config file. Its values use
+// the same keys as config.yaml and take precedence over it.
+const TOMLConfigFile = "camt-csv.toml"
+
+// APIKeyEnvVars are the environment variables the AI API key is read from,
+// in order of precedence: the unified key, then OPENROUTER_API_KEY for
+// OpenRouter users and GEMINI_API_KEY for backward compatibility.
+var APIKeyEnvVars = []string{"CAMT_AI_API_KEY", "OPENROUTER_API_KEY", models.EnvGeminiAPIKey}
+
+// findConfigFile returns the path of name in the first of ConfigSearchPaths
+// holding it, or "" when none does.
+func findConfigFile(name string) string {
+	for _, dir := range ConfigSearchPaths {
+		path := filepath.Join(os.ExpandEnv(dir), name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
 // InitializeConfig initializes Viper configuration with hierarchical loading
 func InitializeConfig() (*Config, error) {
 	// 0. Load .env file if it exists (before Viper so env vars are available)
@@ -102,9 +159,9 @@ func InitializeConfig() (*Config, error) {
 	// 2. Config file locations
 	v.SetConfigName("config")
 	v.SetConfigType("yaml")
-	v.AddConfigPath("$HOME/.camt-csv")
-	v.AddConfigPath(".camt-csv")
-	v.AddConfigPath(".")
+	for _, path := range ConfigSearchPaths {
+		v.AddConfigPath(path)
+	}
 
 	// 3. Environment variables
 	v.SetEnvPrefix("CAMT")
@@ -120,22 +177,36 @@ func InitializeConfig() (*Config, error) {
 		// Config file not found or invalid is OK, we'll use defaults and env vars
 	}
 
-	// 5. Handle special case for API key (always from env, not prefixed)
-	// Bind CAMT_AI_API_KEY first (new unified key)
-	if err := v.BindEnv("ai.api_key", "CAMT_AI_API_KEY"); err != nil {
-		fmt.Printf("Warning: failed to bind CAMT_AI_API_KEY environment variable: %v\n", err)
-	}
-	// Fallback: if CAMT_AI_API_KEY is not set, try OPENROUTER_API_KEY (for OpenRouter users)
-	if v.GetString("ai.api_key") == "" {
-		if err := v.BindEnv("ai.api_key", "OPENROUTER_API_KEY"); err != nil {
-			fmt.Printf("Warning: failed to bind OPENROUTER_API_KEY environment variable: %v\n", err)
+	// 4b. Merge camt-csv.toml over config.yaml (optional). Environment
+	// variables, including the API keys below, still take precedence.
+	if tomlFile := findConfigFile(TOMLConfigFile); tomlFile != "" {
+		v.SetConfigFile(tomlFile)
+		v.SetConfigType("toml")
+		if err := v.MergeInConfig(); err != nil {
+			fmt.Printf("Warning: error reading config file %s: %v\n", tomlFile, err)
 		}
 	}
-	// Fallback: if still not set, try GEMINI_API_KEY for backward compatibility
-	if v.GetString("ai.api_key") == "" {
-		if err := v.BindEnv("ai.api_key", "GEMINI_API_KEY"); err != nil {
-			fmt.Printf("Warning: failed to bind GEMINI_API_KEY environment variable: %v\n", err)
+
+	// 5. Handle special case for API key (always from env, not prefixed):
+	// CAMT_AI_API_KEY is always bound, the others only as fallbacks while
+	// no key is set
+	for i, name := range APIKeyEnvVars {
+		if i > 0 && v.GetString("ai.api_key") != "" {
+			break
 		}
+		if err := v.BindEnv("ai.api_key", name); err != nil {
+			fmt.Printf("Warning: failed to bind %s environment variable: %v\n", name, err)
+		}
+	}
+
+	// The exec provider's command may also come from EXEC_CATEGORIZER
+	if err := v.BindEnv("ai.exec_command", "CAMT_AI_EXEC_COMMAND", "EXEC_CATEGORIZER"); err != nil {
+		fmt.Printf("Warning: failed to bind EXEC_CATEGORIZER environment variable: %v\n", err)
+	}
+
+	// The --anonymize key is a secret, also taken from CAMT_ANONYMIZE_KEY
+	if err := v.BindEnv("output.anonymize_key", "CAMT_ANONYMIZE_KEY"); err != nil {
+		fmt.Printf("Warning: failed to bind CAMT_ANONYMIZE_KEY environment variable: %v\n", err)
 	}
 
 	// Bind constitution file paths from environment variable
@@ -176,6 +247,10 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("ai.requests_per_minute", 10)
 	v.SetDefault("ai.timeout_seconds", 30)
 	v.SetDefault("ai.fallback_category", models.CategoryUncategorized)
+	v.SetDefault("ai.prompt_language", "en")
+	v.SetDefault("ai.prompt_template_file", "")
+	v.SetDefault("ai.base_currency", "CHF")
+	v.SetDefault("ai.exec_command", "")
 
 	// Data defaults
 	v.SetDefault("data.directory", "")
@@ -191,6 +266,7 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("categorization.confidence_threshold", 0.8)
 	v.SetDefault("categorization.case_sensitive", false)
 	v.SetDefault("categorization.semantic_threshold", 0.70)
+	v.SetDefault("categorization.match_mode", "exact")
 
 	// Staging defaults — saves AI suggestions when auto-learn is off
 	v.SetDefault("staging.enabled", true)
@@ -200,18 +276,29 @@ func setDefaults(v *viper.Viper) {
 	// Parser defaults
 	v.SetDefault("parsers.camt.strict_validation", true)
 	v.SetDefault("parsers.pdf.ocr_enabled", false)
+	v.SetDefault("parsers.pdf.refund_category", "")
 	v.SetDefault("parsers.revolut.date_format_detection", true)
+	v.SetDefault("parsers.presets_file", "presets.yaml")
 
 	// Categories defaults
 	v.SetDefault("categories.file", "categories.yaml")
 	v.SetDefault("categories.creditors_file", "creditors.yaml")
 	v.SetDefault("categories.debtors_file", "debtors.yaml")
+	v.SetDefault("categories.tags_file", "tags.yaml")
+	v.SetDefault("categories.mcc_file", "mcc.yaml")
+	v.SetDefault("categories.iban_mappings_file", "iban_mappings.yaml")
+	v.SetDefault("categories.cleanup_file", "cleanup.yaml")
+	v.SetDefault("categories.budgets_file", "budgets.yaml")
 
 	// Constitution defaults
 	v.SetDefault("constitution.file_paths", []string{})
 
 	// Output defaults
 	v.SetDefault("output.format", "icompta")
+	v.SetDefault("output.profiles_file", "profiles.yaml")
+	v.SetDefault("output.description_template", "")
+	v.SetDefault("output.ledger_account", "")
+	v.SetDefault("output.anonymize_key", "")
 }
 
 // validateConfig validates the configuration values
@@ -231,19 +318,30 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("CSV delimiter must be a single character, got: %s", config.CSV.Delimiter)
 	}
 
+	// Validate currency precision
+	for code, places := range config.CSV.CurrencyPrecision {
+		if places < 0 || places > 8 {
+			return fmt.Errorf("csv.currency_precision.%s must be between 0 and 8, got: %d", code, places)
+		}
+	}
+
 	// Validate AI configuration
 	if config.AI.Enabled {
-		validProviders := map[string]bool{"gemini": true, "openrouter": true}
+		validProviders := map[string]bool{"gemini": true, "openrouter": true, "exec": true}
 		if !validProviders[config.AI.Provider] {
-			return fmt.Errorf("ai.provider must be 'gemini' or 'openrouter', got: %s", config.AI.Provider)
+			return fmt.Errorf("ai.provider must be 'gemini', 'openrouter' or 'exec', got: %s", config.AI.Provider)
 		}
 
 		if config.AI.Model == "" {
 			return fmt.Errorf("ai.model must not be empty when AI is enabled")
 		}
 
-		if config.AI.APIKey == "" {
-			return fmt.Errorf("AI is enabled but no API key found. Set one of: CAMT_AI_API_KEY, OPENROUTER_API_KEY, or GEMINI_API_KEY")
+		if config.AI.Provider == "exec" {
+			if config.AI.ExecCommand == "" {
+				return fmt.Errorf("ai.provider is 'exec' but no command is set. Set ai.exec_command or EXEC_CATEGORIZER")
+			}
+		} else if config.AI.APIKey == "" {
+			return fmt.Errorf("AI is enabled but no API key found. Set one of: %s", strings.Join(APIKeyEnvVars, ", "))
 		}
 
 		if config.AI.RequestsPerMinute < 1 || config.AI.RequestsPerMinute > 1000 {
@@ -253,6 +351,11 @@ func validateConfig(config *Config) error {
 		if config.AI.TimeoutSeconds < 1 || config.AI.TimeoutSeconds > 300 {
 			return fmt.Errorf("ai.timeout_seconds must be between 1 and 300, got: %d", config.AI.TimeoutSeconds)
 		}
+
+		validLanguages := map[string]bool{"": true, "en": true, "fr": true}
+		if config.AI.PromptTemplate == "" && !validLanguages[config.AI.PromptLanguage] {
+			return fmt.Errorf("ai.prompt_language must be 'en' or 'fr', got: %s", config.AI.PromptLanguage)
+		}
 	}
 
 	// Validate confidence threshold
@@ -265,6 +368,12 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("categorization.semantic_threshold must be between 0.0 and 1.0, got: %f", config.Categorization.SemanticThreshold)
 	}
 
+	// Validate match mode
+	validMatchModes := map[string]bool{"": true, "exact": true, "token": true, "substring": true}
+	if !validMatchModes[strings.ToLower(config.Categorization.MatchMode)] {
+		return fmt.Errorf("categorization.match_mode must be 'exact', 'token' or 'substring', got: %s", config.Categorization.MatchMode)
+	}
+
 	return nil
 }
 
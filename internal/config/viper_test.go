@@ -38,6 +38,7 @@ func TestInitializeConfig_Defaults(t *testing.T) {
 	assert.False(t, config.Categorization.CaseSensitive)
 	assert.True(t, config.Parsers.CAMT.StrictValidation)
 	assert.False(t, config.Parsers.PDF.OCREnabled)
+	assert.Empty(t, config.Parsers.PDF.RefundCategory)
 	assert.True(t, config.Parsers.Revolut.DateFormatDetection)
 }
 
@@ -176,6 +177,76 @@ ai:
 	assert.Equal(t, "env-api-key", config.AI.APIKey) // env var (API key)
 }
 
+func TestInitializeConfig_TOMLFile(t *testing.T) {
+	clearTestEnvVars(t)
+
+	tempDir := t.TempDir()
+	yamlContent := `
+csv:
+  delimiter: "|"
+ai:
+  model: "yaml-model"
+  requests_per_minute: 20
+`
+	tomlContent := `
+[csv]
+delimiter = ";"
+
+[ai]
+provider = "openrouter"
+model = "toml-model"
+timeout_seconds = 45
+fallback_category = "Divers"
+api_key = "file-api-key"
+
+[categorization]
+confidence_threshold = 0.75
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "config.yaml"), []byte(yamlContent), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, TOMLConfigFile), []byte(tomlContent), 0600))
+
+	t.Setenv("CAMT_AI_TIMEOUT_SECONDS", "60")
+	t.Setenv("CAMT_AI_API_KEY", "env-api-key")
+
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.Chdir(originalDir))
+	}()
+	require.NoError(t, os.Chdir(tempDir))
+
+	config, err := InitializeConfig()
+	require.NoError(t, err)
+
+	assert.Equal(t, ";", config.CSV.Delimiter)                       // TOML wins over YAML
+	assert.Equal(t, "toml-model", config.AI.Model)                   // TOML wins over YAML
+	assert.Equal(t, 20, config.AI.RequestsPerMinute)                 // YAML value kept
+	assert.Equal(t, "openrouter", config.AI.Provider)                // TOML only
+	assert.Equal(t, "Divers", config.AI.FallbackCategory)            // TOML only
+	assert.Equal(t, 0.75, config.Categorization.ConfidenceThreshold) // TOML only
+	assert.Equal(t, 60, config.AI.TimeoutSeconds)                    // env var wins
+	assert.Equal(t, "env-api-key", config.AI.APIKey)                 // env var wins for secrets
+}
+
+func TestFindConfigFile(t *testing.T) {
+	tempDir := t.TempDir()
+	original := ConfigSearchPaths
+	ConfigSearchPaths = []string{filepath.Join(tempDir, "first"), filepath.Join(tempDir, "second")}
+	defer func() { ConfigSearchPaths = original }()
+
+	assert.Empty(t, findConfigFile(TOMLConfigFile))
+
+	second := filepath.Join(tempDir, "second", TOMLConfigFile)
+	require.NoError(t, os.MkdirAll(filepath.Dir(second), 0750))
+	require.NoError(t, os.WriteFile(second, []byte(""), 0600))
+	assert.Equal(t, second, findConfigFile(TOMLConfigFile))
+
+	first := filepath.Join(tempDir, "first", TOMLConfigFile)
+	require.NoError(t, os.MkdirAll(filepath.Dir(first), 0750))
+	require.NoError(t, os.WriteFile(first, []byte(""), 0600))
+	assert.Equal(t, first, findConfigFile(TOMLConfigFile))
+}
+
 func TestValidateConfig_InvalidValues(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -238,6 +309,20 @@ func TestValidateConfig_InvalidValues(t *testing.T) {
 			},
 			expectError: "categorization.confidence_threshold must be between 0.0 and 1.0",
 		},
+		{
+			name: "invalid match mode",
+			modifyConfig: func(c *Config) {
+				c.Categorization.MatchMode = "fuzzy"
+			},
+			expectError: "categorization.match_mode must be 'exact', 'token' or 'substring'",
+		},
+		{
+			name: "negative currency precision",
+			modifyConfig: func(c *Config) {
+				c.CSV.CurrencyPrecision = map[string]int{"jpy": -1}
+			},
+			expectError: "csv.currency_precision.jpy must be between 0 and 8",
+		},
 	}
 
 	for _, tt := range tests {
@@ -251,10 +336,11 @@ func TestValidateConfig_InvalidValues(t *testing.T) {
 					Format: "text",
 				},
 				CSV: struct {
-					Delimiter      string `mapstructure:"delimiter" yaml:"delimiter"`
-					DateFormat     string `mapstructure:"date_format" yaml:"date_format"`
-					IncludeHeaders bool   `mapstructure:"include_headers" yaml:"include_headers"`
-					QuoteAll       bool   `mapstructure:"quote_all" yaml:"quote_all"`
+					Delimiter         string         `mapstructure:"delimiter" yaml:"delimiter"`
+					DateFormat        string         `mapstructure:"date_format" yaml:"date_format"`
+					IncludeHeaders    bool           `mapstructure:"include_headers" yaml:"include_headers"`
+					QuoteAll          bool           `mapstructure:"quote_all" yaml:"quote_all"`
+					CurrencyPrecision map[string]int `mapstructure:"currency_precision" yaml:"currency_precision"`
 				}{
 					Delimiter: ",",
 				},
@@ -266,6 +352,10 @@ func TestValidateConfig_InvalidValues(t *testing.T) {
 					RequestsPerMinute int    `mapstructure:"requests_per_minute" yaml:"requests_per_minute"`
 					TimeoutSeconds    int    `mapstructure:"timeout_seconds" yaml:"timeout_seconds"`
 					FallbackCategory  string `mapstructure:"fallback_category" yaml:"fallback_category"`
+					PromptLanguage    string `mapstructure:"prompt_language" yaml:"prompt_language"`
+					PromptTemplate    string `mapstructure:"prompt_template_file" yaml:"prompt_template_file"`
+					BaseCurrency      string `mapstructure:"base_currency" yaml:"base_currency"`
+					ExecCommand       string `mapstructure:"exec_command" yaml:"exec_command"`
 					APIKey            string `mapstructure:"api_key" yaml:"-" json:"-"`
 				}{
 					Provider:          "gemini",
@@ -278,6 +368,7 @@ func TestValidateConfig_InvalidValues(t *testing.T) {
 					ConfidenceThreshold float64 `mapstructure:"confidence_threshold" yaml:"confidence_threshold"`
 					CaseSensitive       bool    `mapstructure:"case_sensitive" yaml:"case_sensitive"`
 					SemanticThreshold   float64 `mapstructure:"semantic_threshold" yaml:"semantic_threshold"`
+					MatchMode           string  `mapstructure:"match_mode" yaml:"match_mode"`
 				}{
 					ConfidenceThreshold: 0.8,
 					SemanticThreshold:   0.70,
@@ -404,7 +495,27 @@ func TestValidateConfig_NewProviderAndModelChecks(t *testing.T) {
 				c.AI.Model = "some-model"
 				c.AI.APIKey = "some-key"
 			},
-			expectError: "ai.provider must be 'gemini' or 'openrouter', got: badprovider",
+			expectError: "ai.provider must be 'gemini', 'openrouter' or 'exec', got: badprovider",
+		},
+		{
+			name: "AI enabled with exec provider needs no API key",
+			modifyConfig: func(c *Config) {
+				c.AI.Enabled = true
+				c.AI.Provider = "exec"
+				c.AI.Model = "some-model"
+				c.AI.ExecCommand = "/usr/local/bin/categorize"
+			},
+			expectError: "",
+		},
+		{
+			name: "AI enabled with exec provider without command",
+			modifyConfig: func(c *Config) {
+				c.AI.Enabled = true
+				c.AI.Provider = "exec"
+				c.AI.Model = "some-model"
+				c.AI.APIKey = "some-key"
+			},
+			expectError: "Set ai.exec_command or EXEC_CATEGORIZER",
 		},
 		{
 			name: "AI enabled with openrouter provider is valid",
@@ -431,10 +542,11 @@ func TestValidateConfig_NewProviderAndModelChecks(t *testing.T) {
 					Format: "text",
 				},
 				CSV: struct {
-					Delimiter      string `mapstructure:"delimiter" yaml:"delimiter"`
-					DateFormat     string `mapstructure:"date_format" yaml:"date_format"`
-					IncludeHeaders bool   `mapstructure:"include_headers" yaml:"include_headers"`
-					QuoteAll       bool   `mapstructure:"quote_all" yaml:"quote_all"`
+					Delimiter         string         `mapstructure:"delimiter" yaml:"delimiter"`
+					DateFormat        string         `mapstructure:"date_format" yaml:"date_format"`
+					IncludeHeaders    bool           `mapstructure:"include_headers" yaml:"include_headers"`
+					QuoteAll          bool           `mapstructure:"quote_all" yaml:"quote_all"`
+					CurrencyPrecision map[string]int `mapstructure:"currency_precision" yaml:"currency_precision"`
 				}{
 					Delimiter: ",",
 				},
@@ -446,6 +558,10 @@ func TestValidateConfig_NewProviderAndModelChecks(t *testing.T) {
 					RequestsPerMinute int    `mapstructure:"requests_per_minute" yaml:"requests_per_minute"`
 					TimeoutSeconds    int    `mapstructure:"timeout_seconds" yaml:"timeout_seconds"`
 					FallbackCategory  string `mapstructure:"fallback_category" yaml:"fallback_category"`
+					PromptLanguage    string `mapstructure:"prompt_language" yaml:"prompt_language"`
+					PromptTemplate    string `mapstructure:"prompt_template_file" yaml:"prompt_template_file"`
+					BaseCurrency      string `mapstructure:"base_currency" yaml:"base_currency"`
+					ExecCommand       string `mapstructure:"exec_command" yaml:"exec_command"`
 					APIKey            string `mapstructure:"api_key" yaml:"-" json:"-"`
 				}{
 					RequestsPerMinute: 10,
@@ -456,6 +572,7 @@ func TestValidateConfig_NewProviderAndModelChecks(t *testing.T) {
 					ConfidenceThreshold float64 `mapstructure:"confidence_threshold" yaml:"confidence_threshold"`
 					CaseSensitive       bool    `mapstructure:"case_sensitive" yaml:"case_sensitive"`
 					SemanticThreshold   float64 `mapstructure:"semantic_threshold" yaml:"semantic_threshold"`
+					MatchMode           string  `mapstructure:"match_mode" yaml:"match_mode"`
 				}{
 					ConfidenceThreshold: 0.8,
 					SemanticThreshold:   0.70,
@@ -505,3 +622,11 @@ func clearTestEnvVars(t *testing.T) {
 		}
 	}
 }
+
+func TestConfig_GetAutoLearnEnabled(t *testing.T) {
+	cfg := &Config{}
+	assert.False(t, cfg.GetAutoLearnEnabled())
+
+	cfg.Categorization.AutoLearn = true
+	assert.True(t, cfg.GetAutoLearnEnabled())
+}
@@ -4,25 +4,36 @@
 package container
 
 import (
+	"context"
 	"fmt"
 	"os"
 
-	"fjacquet/camt-csv/internal/camtparser"
 	"fjacquet/camt-csv/internal/categorizer"
 	"fjacquet/camt-csv/internal/config"
-	"fjacquet/camt-csv/internal/debitparser"
 	"fjacquet/camt-csv/internal/formatter"
 	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
 	"fjacquet/camt-csv/internal/parser"
-	"fjacquet/camt-csv/internal/pdfparser"
-	"fjacquet/camt-csv/internal/revolutcryptoparser"
-	"fjacquet/camt-csv/internal/revolutinvestmentparser"
-	"fjacquet/camt-csv/internal/revolutparser"
-	"fjacquet/camt-csv/internal/selmaparser"
 	"fjacquet/camt-csv/internal/store"
+
+	"github.com/shopspring/decimal"
+
+	// Built-in parsers register themselves with the parser registry
+	_ "fjacquet/camt-csv/internal/camtparser"
+	_ "fjacquet/camt-csv/internal/csvparser"
+	_ "fjacquet/camt-csv/internal/debitparser"
+	_ "fjacquet/camt-csv/internal/mt940parser"
+	_ "fjacquet/camt-csv/internal/pdfparser"
+	_ "fjacquet/camt-csv/internal/revolutcryptoparser"
+	_ "fjacquet/camt-csv/internal/revolutinvestmentparser"
+	_ "fjacquet/camt-csv/internal/revolutparser"
+	_ "fjacquet/camt-csv/internal/selmaparser"
+	_ "fjacquet/camt-csv/internal/wiseparser"
 )
 
 // ParserType defines the types of parsers available.
+// Any name registered with parser.RegisterParser is a valid ParserType;
+// the constants below name the built-in parsers.
 type ParserType string
 
 const (
@@ -33,6 +44,9 @@ const (
 	RevolutCrypto     ParserType = "revolut-crypto"
 	Selma             ParserType = "selma"
 	Debit             ParserType = "debit"
+	Wise              ParserType = "wise"
+	MT940             ParserType = "mt940"
+	Reprocess         ParserType = "reprocess"
 )
 
 // Container holds all application dependencies and provides methods to access them.
@@ -80,13 +94,29 @@ func NewContainer(cfg *config.Config) (*Container, error) {
 		cfg.Categories.CreditorsFile,
 		cfg.Categories.DebtorsFile,
 	)
+	categoryStore.TagsFile = cfg.Categories.TagsFile
+	categoryStore.MCCFile = cfg.Categories.MCCFile
+	categoryStore.IBANFile = cfg.Categories.IBANMappingsFile
+	categoryStore.CleanupFile = cfg.Categories.CleanupFile
+	categoryStore.BudgetsFile = cfg.Categories.BudgetsFile
+	categoryStore.ProfilesFile = cfg.Output.ProfilesFile
+	categoryStore.PresetsFile = cfg.Parsers.PresetsFile
 
 	// Create AI clients based on provider selection
 	var chatClient categorizer.AIClient
 	var embeddingClient categorizer.AIClient
 
-	if cfg.AI.Enabled && cfg.AI.APIKey != "" {
+	// The exec provider runs a local command and needs no API key
+	if cfg.AI.Enabled && (cfg.AI.APIKey != "" || cfg.AI.Provider == "exec") {
 		switch cfg.AI.Provider {
+		case "exec":
+			chatClient = categorizer.NewExecClient(logger, cfg.AI.ExecCommand, cfg.AI.TimeoutSeconds)
+			logger.WithFields(
+				logging.Field{Key: "provider", Value: "exec"},
+				logging.Field{Key: "command", Value: cfg.AI.ExecCommand},
+			).Info("AI provider: exec")
+			logger.Info("Semantic tier: skipped (no embedding provider available)")
+
 		case "openrouter":
 			chatClient = categorizer.NewOpenRouterClient(
 				logger,
@@ -119,6 +149,23 @@ func NewContainer(cfg *config.Config) (*Container, error) {
 			).Info("AI provider: gemini")
 			logger.Info("Semantic tier: active (Gemini embeddings)")
 		}
+
+		// Configure the prompt language/template; responses are matched
+		// against the categories from the store, whatever the prompt language
+		if pc, ok := chatClient.(categorizer.PromptConfigurable); ok {
+			var categoryNames []string
+			if categories, err := categoryStore.LoadCategories(); err == nil {
+				for _, c := range categories {
+					categoryNames = append(categoryNames, c.Name)
+				}
+			}
+			prompts, err := categorizer.NewPromptBuilder(cfg.AI.PromptLanguage, cfg.AI.PromptTemplate, categoryNames)
+			if err != nil {
+				return nil, fmt.Errorf("failed to configure AI prompt: %w", err)
+			}
+			prompts.SetBaseCurrency(cfg.AI.BaseCurrency)
+			pc.SetPromptBuilder(prompts)
+		}
 	} else {
 		logger.Info("AI categorization disabled")
 	}
@@ -128,57 +175,35 @@ func NewContainer(cfg *config.Config) (*Container, error) {
 	if semanticThreshold <= 0 {
 		semanticThreshold = 0.70
 	}
-	cat := categorizer.NewCategorizer(chatClient, categoryStore, logger, cfg.Categorization.AutoLearn, float32(semanticThreshold))
+	cat := categorizer.NewCategorizer(chatClient, categoryStore, logger, cfg.GetAutoLearnEnabled(), float32(semanticThreshold))
 
-	// When provider is openrouter, rewire semantic tier to the dedicated embedding client
-	if cfg.AI.Provider == "openrouter" {
+	matchMode, err := categorizer.ParseMatchMode(cfg.Categorization.MatchMode)
+	if err != nil {
+		return nil, fmt.Errorf("invalid categorization.match_mode: %w", err)
+	}
+	cat.SetMatchMode(matchMode)
+
+	// When provider is openrouter or exec, rewire semantic tier to the dedicated embedding client
+	if cfg.AI.Provider == "openrouter" || cfg.AI.Provider == "exec" {
 		cat.SetEmbeddingClient(embeddingClient)
 	}
 
 	// Wire staging store when AI is enabled but auto-learn is off
-	if cfg.AI.Enabled && !cfg.Categorization.AutoLearn && cfg.Staging.Enabled {
+	if cfg.AI.Enabled && !cfg.GetAutoLearnEnabled() && cfg.Staging.Enabled {
 		stagingStore := store.NewStagingStore(cfg.Staging.CreditorsFile, cfg.Staging.DebtorsFile)
 		cat.SetStagingStore(stagingStore)
 		logger.Info("AI staging enabled: suggestions will be saved to staging files for review")
 	}
 
-	// Create parsers with dependency injection
+	// Create parsers from the registry with dependency injection.
+	// Built-in parsers register themselves from their package init().
 	parsers := make(map[ParserType]parser.FullParser)
-
-	// CAMT parser
-	camtParser := camtparser.NewAdapter(logger)
-	camtParser.SetCategorizer(cat)
-	parsers[CAMT] = camtParser
-
-	// PDF parser - needs special handling for extractor
-	pdfParser := pdfparser.NewAdapter(logger, nil) // nil for real extractor
-	pdfParser.SetCategorizer(cat)
-	parsers[PDF] = pdfParser
-
-	// Revolut parser
-	revolutParser := revolutparser.NewAdapter(logger)
-	revolutParser.SetCategorizer(cat)
-	parsers[Revolut] = revolutParser
-
-	// Revolut Investment parser
-	revolutInvestmentParser := revolutinvestmentparser.NewAdapter(logger)
-	revolutInvestmentParser.SetCategorizer(cat)
-	parsers[RevolutInvestment] = revolutInvestmentParser
-
-	// Revolut Crypto parser
-	revolutCryptoParser := revolutcryptoparser.NewAdapter(logger)
-	revolutCryptoParser.SetCategorizer(cat)
-	parsers[RevolutCrypto] = revolutCryptoParser
-
-	// Selma parser
-	selmaParser := selmaparser.NewAdapter(logger)
-	selmaParser.SetCategorizer(cat)
-	parsers[Selma] = selmaParser
-
-	// Debit parser
-	debitParser := debitparser.NewAdapter(logger)
-	debitParser.SetCategorizer(cat)
-	parsers[Debit] = debitParser
+	for _, name := range parser.RegisteredParsers() {
+		factory, _ := parser.LookupParser(name)
+		p := factory(logger)
+		p.SetCategorizer(cat)
+		parsers[ParserType(name)] = p
+	}
 
 	logger.Info("Container initialized successfully",
 		logging.Field{Key: "parsers_count", Value: len(parsers)},
@@ -223,6 +248,13 @@ func (c *Container) GetCategorizer() *categorizer.Categorizer {
 	return c.categorizer
 }
 
+// WatchCategories reloads the categorizer's categories, mappings and rules
+// whenever their files change, until ctx is done. Meant for
+// long-running commands such as serve.
+func (c *Container) WatchCategories(ctx context.Context) error {
+	return c.store.Watch(ctx, func() { _ = c.categorizer.Reload() }, c.logger)
+}
+
 // GetFormatterRegistry returns the formatter registry for output format selection.
 // The registry is lazily initialized on first access with built-in formatters
 // ("standard" and "icompta") pre-registered.
@@ -236,6 +268,43 @@ func (c *Container) GetFormatterRegistry() *formatter.FormatterRegistry {
 	return c.formatterRegistry
 }
 
+// GetExportProfile returns the export profile called name from the profiles
+// file, or the built-in "default" profile.
+func (c *Container) GetExportProfile(name string) (models.ExportProfile, error) {
+	profiles, err := c.store.LoadExportProfiles()
+	if err != nil {
+		return models.ExportProfile{}, err
+	}
+	return formatter.FindProfile(profiles, name)
+}
+
+// GetBankPreset returns the bank preset called name from the presets file,
+// checking that its parser is registered.
+func (c *Container) GetBankPreset(name string) (models.BankPreset, error) {
+	presets, err := c.store.LoadBankPresets()
+	if err != nil {
+		return models.BankPreset{}, err
+	}
+	preset, err := models.FindBankPreset(presets, name)
+	if err != nil {
+		return models.BankPreset{}, err
+	}
+	if _, ok := c.parsers[ParserType(preset.Parser)]; !ok {
+		return models.BankPreset{}, fmt.Errorf("bank preset %q: unknown parser %q", preset.Name, preset.Parser)
+	}
+	return preset, nil
+}
+
+// GetCategories returns the categories of the categories file, in file order.
+func (c *Container) GetCategories() ([]models.CategoryConfig, error) {
+	return c.store.LoadCategories()
+}
+
+// GetBudgets returns the monthly budget of each category from the budgets file.
+func (c *Container) GetBudgets() (map[string]decimal.Decimal, error) {
+	return c.store.LoadBudgets()
+}
+
 // GetConfig returns the application configuration.
 func (c *Container) GetConfig() *config.Config {
 	return c.config
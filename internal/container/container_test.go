@@ -51,9 +51,14 @@ func TestNewContainer(t *testing.T) {
 					Format: "text",
 				},
 				Categories: struct {
-					File          string `mapstructure:"file" yaml:"file"`
-					CreditorsFile string `mapstructure:"creditors_file" yaml:"creditors_file"`
-					DebtorsFile   string `mapstructure:"debtors_file" yaml:"debtors_file"`
+					File             string `mapstructure:"file" yaml:"file"`
+					CreditorsFile    string `mapstructure:"creditors_file" yaml:"creditors_file"`
+					DebtorsFile      string `mapstructure:"debtors_file" yaml:"debtors_file"`
+					TagsFile         string `mapstructure:"tags_file" yaml:"tags_file"`
+					MCCFile          string `mapstructure:"mcc_file" yaml:"mcc_file"`
+					IBANMappingsFile string `mapstructure:"iban_mappings_file" yaml:"iban_mappings_file"`
+					CleanupFile      string `mapstructure:"cleanup_file" yaml:"cleanup_file"`
+					BudgetsFile      string `mapstructure:"budgets_file" yaml:"budgets_file"`
 				}{
 					File:          "categories.yaml",
 					CreditorsFile: "creditors.yaml",
@@ -67,6 +72,10 @@ func TestNewContainer(t *testing.T) {
 					RequestsPerMinute int    `mapstructure:"requests_per_minute" yaml:"requests_per_minute"`
 					TimeoutSeconds    int    `mapstructure:"timeout_seconds" yaml:"timeout_seconds"`
 					FallbackCategory  string `mapstructure:"fallback_category" yaml:"fallback_category"`
+					PromptLanguage    string `mapstructure:"prompt_language" yaml:"prompt_language"`
+					PromptTemplate    string `mapstructure:"prompt_template_file" yaml:"prompt_template_file"`
+					BaseCurrency      string `mapstructure:"base_currency" yaml:"base_currency"`
+					ExecCommand       string `mapstructure:"exec_command" yaml:"exec_command"`
 					APIKey            string `mapstructure:"api_key" yaml:"-" json:"-"`
 				}{
 					Enabled: false,
@@ -85,9 +94,14 @@ func TestNewContainer(t *testing.T) {
 					Format: "json",
 				},
 				Categories: struct {
-					File          string `mapstructure:"file" yaml:"file"`
-					CreditorsFile string `mapstructure:"creditors_file" yaml:"creditors_file"`
-					DebtorsFile   string `mapstructure:"debtors_file" yaml:"debtors_file"`
+					File             string `mapstructure:"file" yaml:"file"`
+					CreditorsFile    string `mapstructure:"creditors_file" yaml:"creditors_file"`
+					DebtorsFile      string `mapstructure:"debtors_file" yaml:"debtors_file"`
+					TagsFile         string `mapstructure:"tags_file" yaml:"tags_file"`
+					MCCFile          string `mapstructure:"mcc_file" yaml:"mcc_file"`
+					IBANMappingsFile string `mapstructure:"iban_mappings_file" yaml:"iban_mappings_file"`
+					CleanupFile      string `mapstructure:"cleanup_file" yaml:"cleanup_file"`
+					BudgetsFile      string `mapstructure:"budgets_file" yaml:"budgets_file"`
 				}{
 					File:          "categories.yaml",
 					CreditorsFile: "creditors.yaml",
@@ -101,6 +115,10 @@ func TestNewContainer(t *testing.T) {
 					RequestsPerMinute int    `mapstructure:"requests_per_minute" yaml:"requests_per_minute"`
 					TimeoutSeconds    int    `mapstructure:"timeout_seconds" yaml:"timeout_seconds"`
 					FallbackCategory  string `mapstructure:"fallback_category" yaml:"fallback_category"`
+					PromptLanguage    string `mapstructure:"prompt_language" yaml:"prompt_language"`
+					PromptTemplate    string `mapstructure:"prompt_template_file" yaml:"prompt_template_file"`
+					BaseCurrency      string `mapstructure:"base_currency" yaml:"base_currency"`
+					ExecCommand       string `mapstructure:"exec_command" yaml:"exec_command"`
 					APIKey            string `mapstructure:"api_key" yaml:"-" json:"-"`
 				}{
 					Enabled: true,
@@ -149,9 +167,14 @@ func TestContainer_GetParser(t *testing.T) {
 			Format: "text",
 		},
 		Categories: struct {
-			File          string `mapstructure:"file" yaml:"file"`
-			CreditorsFile string `mapstructure:"creditors_file" yaml:"creditors_file"`
-			DebtorsFile   string `mapstructure:"debtors_file" yaml:"debtors_file"`
+			File             string `mapstructure:"file" yaml:"file"`
+			CreditorsFile    string `mapstructure:"creditors_file" yaml:"creditors_file"`
+			DebtorsFile      string `mapstructure:"debtors_file" yaml:"debtors_file"`
+			TagsFile         string `mapstructure:"tags_file" yaml:"tags_file"`
+			MCCFile          string `mapstructure:"mcc_file" yaml:"mcc_file"`
+			IBANMappingsFile string `mapstructure:"iban_mappings_file" yaml:"iban_mappings_file"`
+			CleanupFile      string `mapstructure:"cleanup_file" yaml:"cleanup_file"`
+			BudgetsFile      string `mapstructure:"budgets_file" yaml:"budgets_file"`
 		}{
 			File:          "categories.yaml",
 			CreditorsFile: "creditors.yaml",
@@ -165,6 +188,10 @@ func TestContainer_GetParser(t *testing.T) {
 			RequestsPerMinute int    `mapstructure:"requests_per_minute" yaml:"requests_per_minute"`
 			TimeoutSeconds    int    `mapstructure:"timeout_seconds" yaml:"timeout_seconds"`
 			FallbackCategory  string `mapstructure:"fallback_category" yaml:"fallback_category"`
+			PromptLanguage    string `mapstructure:"prompt_language" yaml:"prompt_language"`
+			PromptTemplate    string `mapstructure:"prompt_template_file" yaml:"prompt_template_file"`
+			BaseCurrency      string `mapstructure:"base_currency" yaml:"base_currency"`
+			ExecCommand       string `mapstructure:"exec_command" yaml:"exec_command"`
 			APIKey            string `mapstructure:"api_key" yaml:"-" json:"-"`
 		}{
 			Enabled: false,
@@ -242,9 +269,14 @@ func TestContainer_ConvenienceMethods(t *testing.T) {
 			Format: "text",
 		},
 		Categories: struct {
-			File          string `mapstructure:"file" yaml:"file"`
-			CreditorsFile string `mapstructure:"creditors_file" yaml:"creditors_file"`
-			DebtorsFile   string `mapstructure:"debtors_file" yaml:"debtors_file"`
+			File             string `mapstructure:"file" yaml:"file"`
+			CreditorsFile    string `mapstructure:"creditors_file" yaml:"creditors_file"`
+			DebtorsFile      string `mapstructure:"debtors_file" yaml:"debtors_file"`
+			TagsFile         string `mapstructure:"tags_file" yaml:"tags_file"`
+			MCCFile          string `mapstructure:"mcc_file" yaml:"mcc_file"`
+			IBANMappingsFile string `mapstructure:"iban_mappings_file" yaml:"iban_mappings_file"`
+			CleanupFile      string `mapstructure:"cleanup_file" yaml:"cleanup_file"`
+			BudgetsFile      string `mapstructure:"budgets_file" yaml:"budgets_file"`
 		}{
 			File:          "categories.yaml",
 			CreditorsFile: "creditors.yaml",
@@ -258,6 +290,10 @@ func TestContainer_ConvenienceMethods(t *testing.T) {
 			RequestsPerMinute int    `mapstructure:"requests_per_minute" yaml:"requests_per_minute"`
 			TimeoutSeconds    int    `mapstructure:"timeout_seconds" yaml:"timeout_seconds"`
 			FallbackCategory  string `mapstructure:"fallback_category" yaml:"fallback_category"`
+			PromptLanguage    string `mapstructure:"prompt_language" yaml:"prompt_language"`
+			PromptTemplate    string `mapstructure:"prompt_template_file" yaml:"prompt_template_file"`
+			BaseCurrency      string `mapstructure:"base_currency" yaml:"base_currency"`
+			ExecCommand       string `mapstructure:"exec_command" yaml:"exec_command"`
 			APIKey            string `mapstructure:"api_key" yaml:"-" json:"-"`
 		}{
 			Enabled: true,
@@ -271,6 +307,9 @@ func TestContainer_ConvenienceMethods(t *testing.T) {
 	// Test convenience methods
 	assert.NotNil(t, container.GetLogger())
 	assert.NotNil(t, container.GetCategorizer())
+
+	_, err = container.GetCategories()
+	assert.NoError(t, err)
 }
 
 // **Feature: parser-enhancements, Property 11: Configuration consistency**
@@ -309,9 +348,14 @@ func TestProperty_ConfigurationConsistency(t *testing.T) {
 					Format: randomLogFormat(),
 				},
 				Categories: struct {
-					File          string `mapstructure:"file" yaml:"file"`
-					CreditorsFile string `mapstructure:"creditors_file" yaml:"creditors_file"`
-					DebtorsFile   string `mapstructure:"debtors_file" yaml:"debtors_file"`
+					File             string `mapstructure:"file" yaml:"file"`
+					CreditorsFile    string `mapstructure:"creditors_file" yaml:"creditors_file"`
+					DebtorsFile      string `mapstructure:"debtors_file" yaml:"debtors_file"`
+					TagsFile         string `mapstructure:"tags_file" yaml:"tags_file"`
+					MCCFile          string `mapstructure:"mcc_file" yaml:"mcc_file"`
+					IBANMappingsFile string `mapstructure:"iban_mappings_file" yaml:"iban_mappings_file"`
+					CleanupFile      string `mapstructure:"cleanup_file" yaml:"cleanup_file"`
+					BudgetsFile      string `mapstructure:"budgets_file" yaml:"budgets_file"`
 				}{
 					File:          categoriesFile,
 					CreditorsFile: creditorsFile,
@@ -325,6 +369,10 @@ func TestProperty_ConfigurationConsistency(t *testing.T) {
 					RequestsPerMinute int    `mapstructure:"requests_per_minute" yaml:"requests_per_minute"`
 					TimeoutSeconds    int    `mapstructure:"timeout_seconds" yaml:"timeout_seconds"`
 					FallbackCategory  string `mapstructure:"fallback_category" yaml:"fallback_category"`
+					PromptLanguage    string `mapstructure:"prompt_language" yaml:"prompt_language"`
+					PromptTemplate    string `mapstructure:"prompt_template_file" yaml:"prompt_template_file"`
+					BaseCurrency      string `mapstructure:"base_currency" yaml:"base_currency"`
+					ExecCommand       string `mapstructure:"exec_command" yaml:"exec_command"`
 					APIKey            string `mapstructure:"api_key" yaml:"-" json:"-"`
 				}{
 					Enabled: aiEnabled,
@@ -387,3 +435,27 @@ func randomLogFormat() string {
 	formats := []string{"text", "json"}
 	return formats[cryptoRandIntn(len(formats))]
 }
+
+func TestContainer_GetBankPreset(t *testing.T) {
+	presetsFile := filepath.Join(t.TempDir(), "presets.yaml")
+	require.NoError(t, os.WriteFile(presetsFile, []byte(`presets:
+  - name: bcv
+    parser: camt
+  - name: ubs
+    parser: ubs-csv
+`), 0600))
+
+	cfg := &config.Config{}
+	cfg.Log.Level = "info"
+	cfg.Log.Format = "text"
+	cfg.Parsers.PresetsFile = presetsFile
+	container, err := NewContainer(cfg)
+	require.NoError(t, err)
+
+	preset, err := container.GetBankPreset("bcv")
+	require.NoError(t, err)
+	assert.Equal(t, "camt", preset.Parser)
+
+	_, err = container.GetBankPreset("ubs")
+	assert.EqualError(t, err, `bank preset "ubs": unknown parser "ubs-csv"`)
+}
@@ -0,0 +1,106 @@
+package csvparser
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"fjacquet/camt-csv/internal/batch"
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parser"
+	"fjacquet/camt-csv/internal/parsererror"
+)
+
+// Adapter implements the parser.FullParser interface for camt-csv exports.
+type Adapter struct {
+	parser.BaseParser
+}
+
+func init() {
+	parser.RegisterParser("reprocess", func(logger logging.Logger) parser.FullParser {
+		return NewAdapter(logger)
+	})
+}
+
+// NewAdapter creates a new Adapter for the csvparser.
+func NewAdapter(logger logging.Logger) *Adapter {
+	return &Adapter{
+		BaseParser: parser.NewBaseParser(logger),
+	}
+}
+
+// Parse reads data from the provided io.Reader and returns a slice of Transaction models.
+func (a *Adapter) Parse(ctx context.Context, r io.Reader) ([]models.Transaction, error) {
+	r, err := parser.DecodeInput(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseWithCategorizer(ctx, r, a.GetLogger(), a.GetCategorizer())
+}
+
+// ConvertToCSV implements parser.FullParser.ConvertToCSV.
+func (a *Adapter) ConvertToCSV(ctx context.Context, inputFile, outputFile string) error {
+	return a.ConvertToCSVDefault(ctx, inputFile, outputFile, a.Parse)
+}
+
+// ValidateFormat checks if a file is a CSV file written by camt-csv.
+func (a *Adapter) ValidateFormat(file string) (bool, error) {
+	f, err := os.Open(file) // #nosec G304 -- CLI tool requires user-provided file paths
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", parsererror.ErrReadFailed, err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			a.GetLogger().WithError(err).Warn("Failed to close file during format validation",
+				logging.Field{Key: "file", Value: file})
+		}
+	}()
+
+	// The header is the first line that is not a comment
+	br := bufio.NewReader(f)
+	line, err := br.ReadBytes('\n')
+	for err == nil && bytes.HasPrefix(line, []byte(models.CSVCommentPrefix)) {
+		line, err = br.ReadBytes('\n')
+	}
+	if err != nil && err != io.EOF {
+		return false, nil
+	}
+	reader := csv.NewReader(bytes.NewReader(line))
+	reader.Comma = delimiter(line)
+	header, err := reader.Read()
+	if err != nil {
+		return false, nil
+	}
+	_, err = newColumnIndex(header)
+	return err == nil, nil
+}
+
+// BatchConvert converts all camt-csv exports in inputDir to outputDir.
+// Formatter is nil here - CLI layer handles formatter resolution.
+func (a *Adapter) BatchConvert(ctx context.Context, inputDir, outputDir string) (int, error) {
+	processor := batch.NewBatchProcessor(a, a.GetLogger(), nil)
+
+	manifest, err := processor.ProcessDirectory(ctx, inputDir, outputDir)
+	if err != nil {
+		// Config/permission error (not file-level errors)
+		return 0, err
+	}
+
+	a.GetLogger().Info("Batch processing completed",
+		logging.Field{Key: "total", Value: manifest.TotalFiles},
+		logging.Field{Key: "succeeded", Value: manifest.SuccessCount},
+		logging.Field{Key: "failed", Value: manifest.FailureCount})
+
+	manifestPath := filepath.Join(outputDir, ".manifest.json")
+	if err := manifest.WriteManifest(manifestPath); err != nil {
+		a.GetLogger().WithError(err).Warn("Failed to write manifest")
+	}
+
+	return manifest.SuccessCount, nil
+}
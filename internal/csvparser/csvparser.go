@@ -0,0 +1,189 @@
+// Package csvparser reads CSV files previously written by camt-csv, so that
+// converted transactions can go through the pipeline again: re-categorized,
+// filtered, split or sorted. It accepts the standard layout, the signed-amount
+// layout without a CreditDebit column and exports with fewer columns, such as
+// the iCompta format, with comma or semicolon delimiters. Columns outside
+// StandardCSVHeader are ignored.
+package csvparser
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"fjacquet/camt-csv/internal/common"
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parser"
+	"fjacquet/camt-csv/internal/parsererror"
+)
+
+// requiredColumns must be present in the header of a camt-csv export.
+var requiredColumns = []string{"Date", "Amount"}
+
+// numberColumns hold numbers that UnmarshalCSV cannot read when empty.
+var numberColumns = map[string]bool{
+	"Amount": true, "AmountExclTax": true, "TaxRate": true, "NumberOfShares": true,
+	"Fees": true, "OriginalAmount": true, "ExchangeRate": true,
+}
+
+// columnIndex maps StandardCSVHeader columns to their position in the header.
+type columnIndex map[string]int
+
+// newColumnIndex indexes the standard columns of header and checks that the
+// required columns are present.
+func newColumnIndex(header []string) (columnIndex, error) {
+	idx := make(columnIndex, len(header))
+	for i, h := range header {
+		// Exports opened in a spreadsheet may be saved with a UTF-8 byte order mark
+		h = strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))
+		if models.IsStandardCSVColumn(h) {
+			idx[h] = i
+		}
+	}
+	for _, col := range requiredColumns {
+		if _, ok := idx[col]; !ok {
+			return nil, fmt.Errorf("missing required column %q", col)
+		}
+	}
+	return idx, nil
+}
+
+// signed reports whether the export used the signed-amount layout, which
+// has no CreditDebit column.
+func (c columnIndex) signed() bool {
+	_, ok := c["CreditDebit"]
+	return !ok
+}
+
+// standardRecord rearranges record into a full standard CSV record, filling
+// the columns the export left out or empty.
+func (c columnIndex) standardRecord(record []string) []string {
+	full := make([]string, len(models.StandardCSVHeader))
+	for i, col := range models.StandardCSVHeader {
+		if j, ok := c[col]; ok && j < len(record) {
+			full[i] = strings.TrimSpace(record[j])
+		}
+		if full[i] == "" && numberColumns[col] {
+			full[i] = "0"
+		}
+	}
+	return full
+}
+
+// delimiter returns ';' when the first line of data has more semicolons than
+// commas, ',' otherwise.
+func delimiter(data []byte) rune {
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(line, []byte(";")) > bytes.Count(line, []byte(",")) {
+		return ';'
+	}
+	return ','
+}
+
+// ParseWithCategorizer parses a camt-csv export and returns its transactions.
+// With a categorizer, transactions are categorized again; without one, they
+// keep the category recorded in the file.
+func ParseWithCategorizer(ctx context.Context, r io.Reader, logger logging.Logger, categorizer models.TransactionCategorizer) ([]models.Transaction, error) {
+	if logger == nil {
+		logger = logging.NewLogrusAdapter("info", "text")
+	}
+	logger.Info("Parsing camt-csv export from reader")
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read CSV: %w", parsererror.ErrReadFailed, err)
+	}
+	// Statement notes written above the header are not records
+	data = models.StripCSVComments(data)
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = delimiter(data)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read CSV: %w", parsererror.ErrReadFailed, err)
+	}
+
+	if len(records) < 2 {
+		return nil, &parsererror.InvalidFormatError{
+			FilePath:       "(from reader)",
+			ExpectedFormat: "camt-csv export",
+			Msg:            "CSV file is empty or contains only headers",
+			Err:            parsererror.ErrNoTransactions,
+		}
+	}
+
+	cols, err := newColumnIndex(records[0])
+	if err != nil {
+		return nil, &parsererror.InvalidFormatError{
+			FilePath:       "(from reader)",
+			ExpectedFormat: "camt-csv export",
+			Msg:            err.Error(),
+		}
+	}
+
+	var transactions []models.Transaction
+
+	for i, record := range records[1:] {
+		tx, err := convertRecord(cols, record)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to convert row to transaction",
+				logging.Field{Key: "row", Value: i + 2})
+			continue
+		}
+
+		if err := parser.CheckTransactionLimit(ctx, len(transactions)+1); err != nil {
+			return nil, err
+		}
+
+		transactions = append(transactions, tx)
+	}
+
+	// The categories recorded in the file are replaced, not kept, unless
+	// categorization is turned off
+	if _, cleanupOnly := categorizer.(models.CleanupOnly); categorizer != nil && !cleanupOnly {
+		for i := range transactions {
+			transactions[i].Category = ""
+			transactions[i].CategorySource = ""
+		}
+	}
+	transactions = common.ProcessTransactionsWithCategorizationStats(ctx, transactions, logger, categorizer, "reprocess")
+
+	logger.Info("Successfully parsed transactions from camt-csv export",
+		logging.Field{Key: "count", Value: len(transactions)})
+	return transactions, nil
+}
+
+// convertRecord converts one row of the export to a models.Transaction.
+func convertRecord(cols columnIndex, record []string) (models.Transaction, error) {
+	var tx models.Transaction
+	if err := tx.UnmarshalCSV(cols.standardRecord(record)); err != nil {
+		return models.Transaction{}, err
+	}
+
+	if cols.signed() || (tx.CreditDebit != models.TransactionTypeDebit && tx.CreditDebit != models.TransactionTypeCredit) {
+		tx.CreditDebit = models.TransactionTypeCredit
+		if tx.Amount.IsNegative() {
+			tx.CreditDebit = models.TransactionTypeDebit
+		}
+	}
+	tx.DebitFlag = tx.CreditDebit == models.TransactionTypeDebit
+
+	// Exports without a PartyName column only name the counterparty in Name
+	if tx.PartyName == "" {
+		tx.PartyName = tx.Name
+	}
+
+	// Name is derived from the payer or payee when the CSV is written again
+	if tx.DebitFlag {
+		tx.Payee = tx.Name
+	} else {
+		tx.Payer = tx.Name
+	}
+	tx.UpdateDebitCreditAmounts()
+	return tx, nil
+}
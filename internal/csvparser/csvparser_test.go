@@ -0,0 +1,223 @@
+package csvparser
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parser"
+	"fjacquet/camt-csv/internal/parsererror"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCategorizer struct {
+	mock.Mock
+}
+
+func (m *mockCategorizer) Categorize(ctx context.Context, partyName string, isDebtor bool, amount, date, description string) (models.Category, error) {
+	args := m.Called(ctx, partyName, isDebtor, amount, date, description)
+	return args.Get(0).(models.Category), args.Error(1)
+}
+
+func newTestLogger() logging.Logger {
+	return logging.NewLogrusAdapter("info", "text")
+}
+
+func testTransactions(t *testing.T) []models.Transaction {
+	t.Helper()
+	coffee, err := models.NewTransactionBuilder().
+		WithDate("2025-01-05").
+		WithAmount(decimal.RequireFromString("4.50"), "CHF").
+		WithDescription("Coffee").
+		WithPayee("Cafe Central", "").
+		WithPartyName("Cafe Central").
+		WithCategory("Food").
+		WithEntryReference("E-1").
+		AsDebit().
+		Build()
+	require.NoError(t, err)
+	salary, err := models.NewTransactionBuilder().
+		WithDate("2025-01-25").
+		WithAmount(decimal.NewFromInt(2500), "CHF").
+		WithDescription("Salary January").
+		WithPayer("ACME SA", "CH9300762011623852957").
+		WithPartyName("ACME SA").
+		WithCategory("Salary").
+		WithOriginalAmount(decimal.RequireFromString("2650.00"), "EUR").
+		WithExchangeRate(decimal.RequireFromString("0.9434")).
+		AsCredit().
+		Build()
+	require.NoError(t, err)
+	return []models.Transaction{coffee, salary}
+}
+
+// export writes transactions in the standard layout with opts.
+func export(t *testing.T, transactions []models.Transaction, opts models.CSVOptions, comma rune) string {
+	t.Helper()
+	header := models.StandardCSVHeader
+	if opts.SignedAmount {
+		header = append(append([]string{}, header[:9]...), header[10:]...)
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = comma
+	require.NoError(t, w.Write(header))
+	for i := range transactions {
+		record, err := transactions[i].MarshalCSVWithOptions(opts)
+		require.NoError(t, err)
+		require.NoError(t, w.Write(record))
+	}
+	w.Flush()
+	require.NoError(t, w.Error())
+	return buf.String()
+}
+
+func TestParse_RoundTrip(t *testing.T) {
+	want := testTransactions(t)
+	input := export(t, want, models.CSVOptions{}, ',')
+
+	txs, err := ParseWithCategorizer(context.Background(), strings.NewReader(input), newTestLogger(), nil)
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+
+	assert.Equal(t, time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC), txs[0].Date)
+	assert.Equal(t, "Cafe Central", txs[0].PartyName)
+	assert.Equal(t, models.TransactionTypeDebit, txs[0].CreditDebit)
+	assert.True(t, txs[0].IsDebit())
+	assert.True(t, decimal.RequireFromString("-4.50").Equal(txs[0].Amount))
+	assert.Equal(t, "Food", txs[0].Category)
+	assert.Equal(t, "E-1", txs[0].EntryReference)
+
+	assert.Equal(t, models.TransactionTypeCredit, txs[1].CreditDebit)
+	assert.Equal(t, "Salary", txs[1].Category)
+	assert.Equal(t, "EUR", txs[1].OriginalCurrency)
+	assert.True(t, decimal.RequireFromString("0.9434").Equal(txs[1].ExchangeRate))
+
+	// Writing the parsed transactions again gives the same file
+	assert.Equal(t, input, export(t, txs, models.CSVOptions{}, ','))
+}
+
+func TestParse_SignedAmountSemicolon(t *testing.T) {
+	input := export(t, testTransactions(t), models.CSVOptions{SignedAmount: true}, ';')
+
+	txs, err := ParseWithCategorizer(context.Background(), strings.NewReader(input), newTestLogger(), nil)
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+
+	assert.Equal(t, models.TransactionTypeDebit, txs[0].CreditDebit)
+	assert.True(t, txs[0].IsDebit())
+	assert.Equal(t, models.TransactionTypeCredit, txs[1].CreditDebit)
+	assert.Equal(t, "ACME SA", txs[1].Name)
+}
+
+func TestParse_StatementNoteComment(t *testing.T) {
+	input := "# Statement note: Corrected statement, see letter\n" + export(t, testTransactions(t), models.CSVOptions{}, ',')
+
+	txs, err := ParseWithCategorizer(context.Background(), strings.NewReader(input), newTestLogger(), nil)
+	require.NoError(t, err)
+	assert.Len(t, txs, 2)
+}
+
+func TestParse_ColumnSubset(t *testing.T) {
+	input := "Category,Date,Amount,CreditDebit,Currency,PartyName,Card\n" +
+		"Food,05.01.2025,4.50,DBIT,CHF,Cafe Central,1234\n" +
+		"Food,not a date,1.00,DBIT,CHF,Bakery,\n"
+
+	txs, err := ParseWithCategorizer(context.Background(), strings.NewReader(input), newTestLogger(), nil)
+	require.NoError(t, err)
+
+	// The row with an invalid date is skipped
+	require.Len(t, txs, 1)
+	assert.Equal(t, "Cafe Central", txs[0].PartyName)
+	assert.Equal(t, "Food", txs[0].Category)
+	assert.True(t, txs[0].Fees.IsZero())
+}
+
+func TestParse_ICompta(t *testing.T) {
+	input := "Date;Name;Amount;Description;Status;Category;SplitAmount;SplitAmountExclTax;SplitTaxRate;Type\n" +
+		"05.01.2025;Cafe Central;-4.50;Coffee;cleared;Food;-4.50;0.00;0.00;\n"
+
+	txs, err := ParseWithCategorizer(context.Background(), strings.NewReader(input), newTestLogger(), nil)
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	assert.Equal(t, "Cafe Central", txs[0].Name)
+	assert.Equal(t, "Cafe Central", txs[0].PartyName)
+	assert.Equal(t, models.TransactionTypeDebit, txs[0].CreditDebit)
+	assert.Equal(t, "Food", txs[0].Category)
+}
+
+func TestParse_Categorizer(t *testing.T) {
+	input := export(t, testTransactions(t)[:1], models.CSVOptions{}, ',')
+	cat := &mockCategorizer{}
+	cat.On("Categorize", mock.Anything, "Cafe Central", true, "-4.5", "2025-01-05", "Coffee").
+		Return(models.Category{Name: "Restaurants", Source: models.CategorySourceKeyword}, nil)
+
+	txs, err := ParseWithCategorizer(context.Background(), strings.NewReader(input), newTestLogger(), cat)
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	assert.Equal(t, "Restaurants", txs[0].Category)
+	assert.Equal(t, models.CategorySourceKeyword, txs[0].CategorySource)
+	cat.AssertExpectations(t)
+}
+
+func TestParse_MaxTransactions(t *testing.T) {
+	input := export(t, testTransactions(t), models.CSVOptions{}, ',')
+
+	// Parsing stops at the row over the limit, before any row is categorized
+	categorizer := new(mockCategorizer)
+
+	ctx := parser.WithMaxTransactions(context.Background(), 1)
+	_, err := ParseWithCategorizer(ctx, strings.NewReader(input), newTestLogger(), categorizer)
+	assert.ErrorIs(t, err, parsererror.ErrTooManyTransactions)
+	categorizer.AssertNotCalled(t, "Categorize", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	txs, err := ParseWithCategorizer(parser.WithMaxTransactions(context.Background(), 2), strings.NewReader(input), newTestLogger(), nil)
+	require.NoError(t, err)
+	assert.Len(t, txs, 2)
+}
+
+func TestParse_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"header only", "Date,Amount,Currency\n"},
+		{"missing column", "Date,Currency\n05.01.2025,CHF\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWithCategorizer(context.Background(), strings.NewReader(tt.input), newTestLogger(), nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	a := NewAdapter(newTestLogger())
+	dir := t.TempDir()
+
+	export := filepath.Join(dir, "export.csv")
+	require.NoError(t, os.WriteFile(export, []byte(strings.Join(models.StandardCSVHeader, ";")+"\n"), 0600))
+	valid, err := a.ValidateFormat(export)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	other := filepath.Join(dir, "other.csv")
+	require.NoError(t, os.WriteFile(other, []byte("Symbol,Type,Date\n"), 0600))
+	valid, err = a.ValidateFormat(other)
+	require.NoError(t, err)
+	assert.False(t, valid)
+}
@@ -0,0 +1,158 @@
+// Package currency converts transaction amounts to a base currency, using the
+// exchange information carried by the statement or a user-supplied rate table.
+package currency
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+)
+
+// rateDateLayout is the date format used for the keys of a rates file.
+const rateDateLayout = "2006-01-02"
+
+// datedRate is an exchange rate effective from a given date.
+type datedRate struct {
+	from time.Time
+	rate decimal.Decimal
+}
+
+// RateTable holds exchange rates to the base currency, by currency and date.
+// A rate is the number of base-currency units for one unit of the currency.
+type RateTable struct {
+	rates map[string][]datedRate // sorted by date, ascending
+}
+
+// LoadRates reads a YAML rate table of the form:
+//
+//	EUR:
+//	  "2025-01-01": 0.9412
+//	  "2025-02-01": 0.9387
+//	USD:
+//	  "2025-01-01": 0.8801
+//
+// Each rate applies from its date until the next listed date.
+func LoadRates(path string) (*RateTable, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- CLI tool requires user-provided file paths
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates file: %w", err)
+	}
+
+	var raw map[string]map[string]string
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse rates file %s: %w", path, err)
+	}
+
+	table := &RateTable{rates: make(map[string][]datedRate, len(raw))}
+	for ccy, byDate := range raw {
+		ccy = strings.ToUpper(strings.TrimSpace(ccy))
+		for dateStr, rateStr := range byDate {
+			from, err := time.Parse(rateDateLayout, dateStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid date %q for %s in rates file: %w", dateStr, ccy, err)
+			}
+			rate, err := decimal.NewFromString(rateStr)
+			if err != nil || !rate.IsPositive() {
+				return nil, fmt.Errorf("invalid rate %q for %s on %s in rates file", rateStr, ccy, dateStr)
+			}
+			table.rates[ccy] = append(table.rates[ccy], datedRate{from: from, rate: rate})
+		}
+		sort.Slice(table.rates[ccy], func(i, j int) bool {
+			return table.rates[ccy][i].from.Before(table.rates[ccy][j].from)
+		})
+	}
+
+	return table, nil
+}
+
+// Rate returns the rate for currency in effect on date, i.e. the latest rate
+// dated on or before it. The second result is false when no rate applies.
+func (t *RateTable) Rate(currency string, date time.Time) (decimal.Decimal, bool) {
+	if t == nil {
+		return decimal.Zero, false
+	}
+	rates := t.rates[strings.ToUpper(currency)]
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	i := sort.Search(len(rates), func(i int) bool { return rates[i].from.After(day) })
+	if i == 0 {
+		return decimal.Zero, false
+	}
+	return rates[i-1].rate, true
+}
+
+// Converter computes the base-currency amount of transactions.
+type Converter struct {
+	base   string
+	rates  *RateTable
+	logger logging.Logger
+}
+
+// NewConverter creates a Converter to the base currency. rates may be nil, in
+// which case only the exchange information of the statement is used.
+func NewConverter(base string, rates *RateTable, logger logging.Logger) *Converter {
+	return &Converter{
+		base:   strings.ToUpper(strings.TrimSpace(base)),
+		rates:  rates,
+		logger: logger,
+	}
+}
+
+// Base returns the base currency code.
+func (c *Converter) Base() string {
+	return c.base
+}
+
+// Convert returns the amount of tx in the base currency, keeping the sign of
+// tx.Amount. Sources are tried in order:
+//  1. the transaction is already in the base currency;
+//  2. the statement's original amount, when it is in the base currency;
+//  3. the statement's exchange rate (base units per unit of tx.Currency),
+//     when the original currency is the base currency;
+//  4. the rate table, for tx.Currency on the transaction date.
+//
+// When no source applies, the second result is false and a warning is logged;
+// the caller should leave the base amount empty rather than guess.
+func (c *Converter) Convert(tx models.Transaction) (decimal.Decimal, bool) {
+	if strings.EqualFold(tx.Currency, c.base) {
+		return tx.Amount, true
+	}
+
+	if strings.EqualFold(tx.OriginalCurrency, c.base) {
+		if !tx.OriginalAmount.IsZero() {
+			return withSignOf(tx.OriginalAmount, tx.Amount), true
+		}
+		if tx.ExchangeRate.IsPositive() {
+			return tx.Amount.Mul(tx.ExchangeRate), true
+		}
+	}
+
+	if rate, ok := c.rates.Rate(tx.Currency, tx.Date); ok {
+		return tx.Amount.Mul(rate), true
+	}
+
+	if c.logger != nil {
+		c.logger.Warn("No exchange rate available, leaving base amount empty",
+			logging.Field{Key: "currency", Value: tx.Currency},
+			logging.Field{Key: "base_currency", Value: c.base},
+			logging.Field{Key: "date", Value: tx.Date.Format(rateDateLayout)},
+			logging.Field{Key: "party", Value: tx.GetCounterparty()})
+	}
+	return decimal.Zero, false
+}
+
+// withSignOf returns the absolute value of amount with the sign of ref.
+func withSignOf(amount, ref decimal.Decimal) decimal.Decimal {
+	if ref.IsNegative() {
+		return amount.Abs().Neg()
+	}
+	return amount.Abs()
+}
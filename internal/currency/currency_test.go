@@ -0,0 +1,152 @@
+package currency
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeRates(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rates.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func date(s string) time.Time {
+	d, _ := time.Parse("2006-01-02", s)
+	return d
+}
+
+func TestLoadRates(t *testing.T) {
+	path := writeRates(t, `
+EUR:
+  2025-01-01: 0.94
+  "2025-02-01": 0.95
+usd:
+  2025-01-15: 0.88
+`)
+	rates, err := LoadRates(path)
+	require.NoError(t, err)
+
+	tests := []struct {
+		currency string
+		date     string
+		expected string
+		ok       bool
+	}{
+		{"EUR", "2024-12-31", "", false},
+		{"EUR", "2025-01-01", "0.94", true},
+		{"EUR", "2025-01-31", "0.94", true},
+		{"EUR", "2025-02-01", "0.95", true},
+		{"eur", "2025-06-01", "0.95", true},
+		{"USD", "2025-01-20", "0.88", true},
+		{"GBP", "2025-01-20", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currency+"_"+tt.date, func(t *testing.T) {
+			rate, ok := rates.Rate(tt.currency, date(tt.date))
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.expected, rate.String())
+			}
+		})
+	}
+}
+
+func TestLoadRates_Errors(t *testing.T) {
+	_, err := LoadRates(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+
+	_, err = LoadRates(writeRates(t, "EUR:\n  01.01.2025: 0.94\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid date")
+
+	_, err = LoadRates(writeRates(t, "EUR:\n  2025-01-01: abc\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid rate")
+
+	_, err = LoadRates(writeRates(t, "EUR:\n  2025-01-01: -1\n"))
+	require.Error(t, err)
+}
+
+func TestConverter_Convert(t *testing.T) {
+	rates, err := LoadRates(writeRates(t, "EUR:\n  2025-01-01: 0.94\n"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		tx       models.Transaction
+		expected string
+		ok       bool
+	}{
+		{
+			name:     "already in base currency",
+			tx:       models.Transaction{Amount: decimal.RequireFromString("12.50"), Currency: "CHF"},
+			expected: "12.5",
+			ok:       true,
+		},
+		{
+			name: "statement original amount in base currency",
+			tx: models.Transaction{
+				Amount: decimal.RequireFromString("-100"), Currency: "EUR",
+				OriginalAmount: decimal.RequireFromString("93.80"), OriginalCurrency: "CHF",
+			},
+			expected: "-93.8",
+			ok:       true,
+		},
+		{
+			name: "statement exchange rate",
+			tx: models.Transaction{
+				Amount: decimal.RequireFromString("100"), Currency: "EUR",
+				OriginalCurrency: "CHF", ExchangeRate: decimal.RequireFromString("0.93"),
+			},
+			expected: "93",
+			ok:       true,
+		},
+		{
+			name:     "rates table by date",
+			tx:       models.Transaction{Amount: decimal.RequireFromString("50"), Currency: "EUR", Date: date("2025-03-10")},
+			expected: "47",
+			ok:       true,
+		},
+		{
+			name: "missing rate",
+			tx:   models.Transaction{Amount: decimal.RequireFromString("50"), Currency: "USD", Date: date("2025-03-10")},
+			ok:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := logging.NewMockLogger()
+			c := NewConverter("chf", rates, logger)
+
+			amount, ok := c.Convert(tt.tx)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.expected, amount.String())
+				assert.Empty(t, logger.GetEntriesByLevel("WARN"))
+			} else {
+				assert.NotEmpty(t, logger.GetEntriesByLevel("WARN"))
+			}
+		})
+	}
+}
+
+func TestConverter_WithoutRates(t *testing.T) {
+	c := NewConverter("CHF", nil, logging.NewMockLogger())
+	assert.Equal(t, "CHF", c.Base())
+
+	_, ok := c.Convert(models.Transaction{Amount: decimal.NewFromInt(1), Currency: "EUR"})
+	assert.False(t, ok)
+}
@@ -16,16 +16,24 @@ const (
 	DateLayoutUS        = "01/02/2006"
 	DateLayoutFull      = "2006-01-02 15:04:05"
 	DateLayoutWithMonth = "2-Jan-2006"
+	DateLayoutDashed    = "02-01-2006"
+
+	// Layouts carrying a time of day (card and Revolut exports)
+	DateLayoutISOMinutes      = "2006-01-02 15:04"
+	DateLayoutEuropeanTime    = "02.01.2006 15:04"
+	DateLayoutEuropeanSeconds = "02.01.2006 15:04:05"
 )
 
+// whitespacePattern matches the runs of whitespace CleanDateString collapses.
+var whitespacePattern = regexp.MustCompile(`\s+`)
+
 // CleanDateString removes unwanted characters and normalizes a date string
 func CleanDateString(dateStr string) string {
 	// Trim whitespace
 	dateStr = strings.TrimSpace(dateStr)
 
 	// Replace multiple spaces with a single space
-	re := regexp.MustCompile(`\s+`)
-	dateStr = re.ReplaceAllString(dateStr, " ")
+	dateStr = whitespacePattern.ReplaceAllString(dateStr, " ")
 
 	return dateStr
 }
@@ -47,11 +55,15 @@ func ParseDateString(dateStr string) (time.Time, error) {
 		DateLayoutEuropean,                // DD.MM.YYYY (Swiss/European)
 		DateLayoutISO,                     // YYYY-MM-DD (ISO)
 		DateLayoutFull,                    // YYYY-MM-DD HH:MM:SS
+		DateLayoutISOMinutes,              // YYYY-MM-DD HH:MM
+		DateLayoutISO + "T15:04:05",       // ISO 8601 without zone
+		DateLayoutEuropeanSeconds,         // DD.MM.YYYY HH:MM:SS
+		DateLayoutEuropeanTime,            // DD.MM.YYYY HH:MM
 		DateLayoutISO + "T15:04:05Z",      // ISO 8601
 		DateLayoutISO + "T15:04:05-07:00", // ISO 8601 with timezone
 		"02/01/2006",                      // DD/MM/YYYY (European)
 		DateLayoutUS,                      // MM/DD/YYYY (US format)
-		"02-01-2006",                      // DD-MM-YYYY
+		DateLayoutDashed,                  // DD-MM-YYYY
 		"01-02-2006",                      // MM-DD-YYYY
 		"2.1.2006",                        // D.M.YYYY
 		"January 2, 2006",                 // Month D, YYYY
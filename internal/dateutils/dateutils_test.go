@@ -37,6 +37,9 @@ func TestParseDateString(t *testing.T) {
 		{"ISO format", "2023-01-15", false, 2023},
 		{"European format", "15.01.2023", false, 2023},
 		{"Full timestamp", "2023-01-15 10:30:45", false, 2023},
+		{"ISO timestamp without seconds", "2023-01-15 10:30", false, 2023},
+		{"European timestamp", "15.01.2023 10:30", false, 2023},
+		{"European timestamp with seconds", "15.01.2023 10:30:45", false, 2023},
 		{"Empty string", "", false, 0},
 		{"Invalid format", "not a date", true, 0},
 	}
@@ -55,3 +58,10 @@ func TestParseDateString(t *testing.T) {
 		})
 	}
 }
+
+func TestParseDateString_PreservesTimeOfDay(t *testing.T) {
+	result, err := ParseDateString("15.01.2023 14:22")
+	assert.NoError(t, err)
+	assert.Equal(t, 14, result.Hour())
+	assert.Equal(t, 22, result.Minute())
+}
@@ -14,6 +14,12 @@ type Adapter struct {
 	parser.BaseParser
 }
 
+func init() {
+	parser.RegisterParser("debit", func(logger logging.Logger) parser.FullParser {
+		return NewAdapter(logger)
+	})
+}
+
 // NewAdapter creates a new adapter for the debitparser.
 func NewAdapter(logger logging.Logger) *Adapter {
 	return &Adapter{
@@ -23,7 +29,11 @@ func NewAdapter(logger logging.Logger) *Adapter {
 
 // Parse reads data from the provided io.Reader and returns a slice of Transaction models.
 func (a *Adapter) Parse(ctx context.Context, r io.Reader) ([]models.Transaction, error) {
-	return ParseWithCategorizer(r, a.GetLogger(), a.GetCategorizer())
+	r, err := parser.DecodeInput(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseWithCategorizer(ctx, r, a.GetLogger(), a.GetCategorizer())
 }
 
 // ConvertToCSV implements parser.FullParser.ConvertToCSV
@@ -15,6 +15,8 @@ import (
 	"fjacquet/camt-csv/internal/dateutils"
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parser"
+	"fjacquet/camt-csv/internal/parsererror"
 
 	"github.com/gocarina/gocsv"
 	"github.com/shopspring/decimal"
@@ -33,10 +35,12 @@ type DebitCSVRow struct {
 }
 
 // ParseWithCategorizer parses a Visa Debit CSV file and categorizes transactions using the provided categorizer.
-func ParseWithCategorizer(r io.Reader, logger logging.Logger, categorizer models.TransactionCategorizer) ([]models.Transaction, error) {
+// Amounts are read with the number format in ctx (see parser.WithNumberFormat).
+func ParseWithCategorizer(ctx context.Context, r io.Reader, logger logging.Logger, categorizer models.TransactionCategorizer) ([]models.Transaction, error) {
 	if logger == nil {
 		logger = logging.NewLogrusAdapter("info", "text")
 	}
+	numberFormat := parser.NumberFormat(ctx)
 	logger.Info("Parsing Visa Debit CSV from reader")
 
 	// Configure gocsv for semicolon delimiter
@@ -49,7 +53,7 @@ func ParseWithCategorizer(r io.Reader, logger logging.Logger, categorizer models
 	var debitRows []*DebitCSVRow
 	if err := gocsv.Unmarshal(r, &debitRows); err != nil {
 		logger.WithError(err).Error("Failed to read Visa Debit CSV from reader")
-		return nil, fmt.Errorf("error reading Visa Debit CSV: %w", err)
+		return nil, fmt.Errorf("%w: error reading Visa Debit CSV: %w", parsererror.ErrReadFailed, err)
 	}
 
 	// Reset the CSV reader to default for other parsers
@@ -69,41 +73,22 @@ func ParseWithCategorizer(r io.Reader, logger logging.Logger, categorizer models
 		}
 
 		// Convert Debit row to Transaction
-		tx, err := convertDebitRowToTransaction(*row)
+		tx, err := convertDebitRowToTransaction(*row, numberFormat)
 		if err != nil {
 			logger.WithError(err).Warn("Failed to convert row to transaction, skipping")
 			continue
 		}
 
-		// Categorize the transaction using the injected categorizer
-		if categorizer != nil {
-			isDebtor := tx.CreditDebit == models.TransactionTypeDebit
-			catAmount := tx.Amount.String()
-			catDate := ""
-			if !tx.Date.IsZero() {
-				catDate = tx.Date.Format("02.01.2006")
-			}
-
-			category, catErr := categorizer.Categorize(context.Background(), tx.Description, isDebtor, catAmount, catDate, "")
-			if catErr != nil {
-				logger.WithError(catErr).WithFields(
-					logging.Field{Key: "party", Value: tx.Description},
-				).Warn("Failed to categorize transaction")
-				tx.Category = models.CategoryUncategorized
-			} else {
-				tx.Category = category.Name
-				logger.WithFields(
-					logging.Field{Key: "party", Value: tx.Description},
-					logging.Field{Key: "category", Value: category.Name},
-				).Debug("Transaction categorized successfully")
-			}
-		} else {
-			tx.Category = models.CategoryUncategorized
+		if err := parser.CheckTransactionLimit(ctx, len(transactions)+1); err != nil {
+			return nil, err
 		}
 
 		transactions = append(transactions, tx)
 	}
 
+	// Categorize the transactions using the injected categorizer
+	transactions = common.ProcessTransactionsWithCategorizationStats(ctx, transactions, logger, categorizer, "Visa Debit")
+
 	logger.Info("Successfully parsed Visa Debit CSV file",
 		logging.Field{Key: "count", Value: len(transactions)})
 	return transactions, nil
@@ -127,7 +112,7 @@ func ParseFileWithLogger(filePath string, logger logging.Logger) ([]models.Trans
 		return nil, fmt.Errorf("validation error: %w", err)
 	}
 	if !valid {
-		return nil, fmt.Errorf("invalid Visa Debit CSV format")
+		return nil, fmt.Errorf("%w: invalid Visa Debit CSV format", parsererror.ErrInvalidFormat)
 	}
 
 	// Configure gocsv for semicolon delimiter
@@ -141,7 +126,7 @@ func ParseFileWithLogger(filePath string, logger logging.Logger) ([]models.Trans
 	debitRows, err := common.ReadCSVFile[DebitCSVRow](filePath, logger)
 	if err != nil {
 		logger.WithError(err).Error("Failed to read Visa Debit CSV file")
-		return nil, fmt.Errorf("error reading Visa Debit CSV: %w", err)
+		return nil, fmt.Errorf("%w: error reading Visa Debit CSV: %w", parsererror.ErrReadFailed, err)
 	}
 
 	// Reset the CSV reader to default for other parsers
@@ -161,7 +146,7 @@ func ParseFileWithLogger(filePath string, logger logging.Logger) ([]models.Trans
 		}
 
 		// Convert Debit row to Transaction
-		tx, err := convertDebitRowToTransaction(row)
+		tx, err := convertDebitRowToTransaction(row, models.NumberFormatAuto)
 		if err != nil {
 			logger.WithError(err).Warn("Failed to convert row to transaction, skipping")
 			continue
@@ -175,8 +160,9 @@ func ParseFileWithLogger(filePath string, logger logging.Logger) ([]models.Trans
 	return transactions, nil
 }
 
-// convertDebitRowToTransaction converts a DebitCSVRow to a Transaction
-func convertDebitRowToTransaction(row DebitCSVRow) (models.Transaction, error) {
+// convertDebitRowToTransaction converts a DebitCSVRow to a Transaction, reading
+// its amount written in numberFormat
+func convertDebitRowToTransaction(row DebitCSVRow, numberFormat models.NumberFormat) (models.Transaction, error) {
 	// Simple validation
 	if row.Datum == "" {
 		return models.Transaction{}, fmt.Errorf("date is empty")
@@ -193,9 +179,9 @@ func convertDebitRowToTransaction(row DebitCSVRow) (models.Transaction, error) {
 		amount = decimal.NewFromFloat(0)
 		creditDebit = models.TransactionTypeCredit
 	} else {
-		// Use StandardizeAmount to handle formatting (comma vs. decimal point)
+		// Exports use either separator convention, e.g. 1.234,56 or 1,234.56
 		var err error
-		amount, err = decimal.NewFromString(models.StandardizeAmount(row.Betrag))
+		amount, err = models.ParseAmountWithFormat(row.Betrag, numberFormat)
 		if err != nil {
 			return models.Transaction{}, fmt.Errorf("error parsing amount: %w", err)
 		}
@@ -232,11 +218,11 @@ func convertDebitRowToTransaction(row DebitCSVRow) (models.Transaction, error) {
 		WithEntryReference(row.Referenznummer).
 		WithStatus(row.StatusKontofuhrung)
 
-	// Set transaction direction
+	// Set transaction direction; the merchant is the party
 	if creditDebit == models.TransactionTypeDebit {
-		builder = builder.AsDebit()
+		builder = builder.AsDebit().WithPayee(description, "")
 	} else {
-		builder = builder.AsCredit()
+		builder = builder.AsCredit().WithPayer(description, "")
 	}
 
 	// Build the transaction
@@ -244,6 +230,7 @@ func convertDebitRowToTransaction(row DebitCSVRow) (models.Transaction, error) {
 	if err != nil {
 		return models.Transaction{}, fmt.Errorf("error building transaction: %w", err)
 	}
+	transaction.CardLast4 = models.ExtractCardLast4(row.Beneficiaire)
 
 	return transaction, nil
 }
@@ -264,7 +251,7 @@ func ValidateFormatWithLogger(filePath string, logger logging.Logger) (bool, err
 	file, err := os.Open(filePath) // #nosec G304 -- CLI tool requires user-provided file paths
 	if err != nil {
 		logger.WithError(err).Error("Failed to open file for validation")
-		return false, fmt.Errorf("error opening file for validation: %w", err)
+		return false, fmt.Errorf("%w: error opening file for validation: %w", parsererror.ErrReadFailed, err)
 	}
 	defer func() {
 		if err := file.Close(); err != nil {
@@ -11,6 +11,7 @@ import (
 
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parser"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -147,12 +148,49 @@ PMT CARTE RATP;15.04.2025;-4,21;CHF;12345;REF123;COMPLETED`
 		category: models.Category{Name: "Transport"},
 	}
 
-	transactions, err := ParseWithCategorizer(reader, logger, mockCategorizer)
+	transactions, err := ParseWithCategorizer(context.Background(), reader, logger, mockCategorizer)
 	assert.NoError(t, err)
 	assert.Len(t, transactions, 1)
 	assert.Equal(t, "Transport", transactions[0].Category)
 }
 
+func TestParseWithCategorizer_CardLast4(t *testing.T) {
+	content := `Bénéficiaire;Date;Montant;Monnaie
+PMT CARTE XXXX 1234 RATP;15.04.2025;-4,21;CHF
+PMT CARTE Parking-Relais Lausa;02.04.2025;-4,00;CHF`
+
+	transactions, err := ParseWithCategorizer(context.Background(), strings.NewReader(content), logging.NewMockLogger(), nil)
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, "1234", transactions[0].CardLast4)
+	assert.Equal(t, "", transactions[1].CardLast4)
+}
+
+func TestParseWithCategorizer_NumberFormat(t *testing.T) {
+	content := `Bénéficiaire;Date;Montant;Monnaie
+PMT CARTE Galaxus;15.04.2025;-1.234,56;CHF
+PMT CARTE Digitec;16.04.2025;-1,234.56;CHF
+PMT CARTE Interdiscount;17.04.2025;-1234.56;CHF`
+
+	transactions, err := ParseWithCategorizer(context.Background(), strings.NewReader(content), logging.NewMockLogger(), nil)
+	require.NoError(t, err)
+	require.Len(t, transactions, 3)
+	for _, tx := range transactions {
+		assert.Equal(t, "-1234.56", tx.Amount.String(), tx.Description)
+		assert.True(t, tx.IsDebit())
+	}
+
+	// An explicit format rejects amounts written the other way
+	ctx := parser.WithNumberFormat(context.Background(), models.NumberFormatComma)
+	transactions, err = ParseWithCategorizer(ctx, strings.NewReader(content), logging.NewMockLogger(), nil)
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, "Galaxus", transactions[0].Description)
+	assert.Equal(t, "-1234.56", transactions[0].Amount.String())
+	assert.Equal(t, "Interdiscount", transactions[1].Description)
+	assert.Equal(t, "-123456", transactions[1].Amount.String())
+}
+
 func TestParseWithCategorizerError(t *testing.T) {
 	validContent := `Bénéficiaire;Date;Montant;Monnaie;Buchungs-Nr.;Referenznummer;Status Kontoführung
 PMT CARTE RATP;15.04.2025;-4,21;CHF;12345;REF123;COMPLETED`
@@ -163,7 +201,7 @@ PMT CARTE RATP;15.04.2025;-4,21;CHF;12345;REF123;COMPLETED`
 	// Mock categorizer that returns error
 	mockCategorizer := &mockCategorizerError{}
 
-	transactions, err := ParseWithCategorizer(reader, logger, mockCategorizer)
+	transactions, err := ParseWithCategorizer(context.Background(), reader, logger, mockCategorizer)
 	assert.NoError(t, err)
 	assert.Len(t, transactions, 1)
 	assert.Equal(t, models.CategoryUncategorized, transactions[0].Category)
@@ -268,7 +306,7 @@ func TestConvertDebitRowToTransaction(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tx, err := convertDebitRowToTransaction(tt.row)
+			tx, err := convertDebitRowToTransaction(tt.row, models.NumberFormatAuto)
 
 			if tt.expectError {
 				assert.Error(t, err)
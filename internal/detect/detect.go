@@ -0,0 +1,153 @@
+// Package detect recognizes the bank or broker a statement file comes from, so
+// that the auto command can pick the parser without being told.
+package detect
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// sniffSize is how much of a file is read to detect its format.
+const sniffSize = 8 * 1024
+
+// Parser names returned by Format. They are the names the built-in parsers
+// are registered under.
+const (
+	CAMT              = "camt"
+	PDF               = "pdf"
+	Revolut           = "revolut"
+	RevolutInvestment = "revolut-investment"
+	RevolutCrypto     = "revolut-crypto"
+	Selma             = "selma"
+	Debit             = "debit"
+	Wise              = "wise"
+	MT940             = "mt940"
+)
+
+// csvSignature is a CSV export recognized by the columns of its header. Any
+// one of alternatives is enough for a column, for exports whose header is
+// translated.
+type csvSignature struct {
+	parser  string
+	columns [][]string
+}
+
+// csvSignatures are tried in order: exports with distinctive columns come
+// before the Revolut account export, whose Type and Date columns appear in
+// the crypto and investment exports too.
+var csvSignatures = []csvSignature{
+	{RevolutInvestment, [][]string{{"Date"}, {"Ticker"}, {"Type"}, {"Quantity"}, {"Price per share"}, {"Total Amount"}}},
+	{RevolutCrypto, [][]string{{"Symbol"}, {"Type"}, {"Date"}}},
+	{Selma, [][]string{{"Date"}, {"Description"}, {"Bookkeeping No."}, {"Fund"}}},
+	{Wise, [][]string{{"TransferWise ID"}, {"Date"}, {"Amount"}, {"Currency"}}},
+	{Revolut, [][]string{{"Type"}, {"Product", "Produit"}, {"Started Date", "Date de début"}, {"Description"}}},
+	{Debit, [][]string{{"Bénéficiaire"}, {"Date"}, {"Montant"}, {"Monnaie"}}},
+}
+
+// File returns the parser for the statement at path, or "" when no parser
+// recognizes it.
+func File(path string) (string, error) {
+	file, err := os.Open(path) // #nosec G304 -- CLI tool requires user-provided file paths
+	if err != nil {
+		return "", fmt.Errorf("error opening %s: %w", path, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	head := make([]byte, sniffSize)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", fmt.Errorf("error reading %s: %w", path, err)
+	}
+	return Format(head[:n]), nil
+}
+
+// Format returns the parser for a statement starting with head, or "" when no
+// parser recognizes it. PDF statements, Viseca card statements included, go
+// to the pdf parser; XML files to the CAMT parser when they declare an ISO
+// 20022 cash management namespace; SWIFT tagged text to the MT940 parser;
+// CSV exports are told apart by their header.
+func Format(head []byte) string {
+	head = bytes.TrimPrefix(head, []byte("\xef\xbb\xbf"))
+
+	switch {
+	case bytes.HasPrefix(head, []byte("%PDF-")):
+		return PDF
+	case bytes.Contains(head, []byte("urn:iso:std:iso:20022:tech:xsd:camt.05")):
+		return CAMT
+	case isMT940(head):
+		return MT940
+	}
+	return csvFormat(head)
+}
+
+// isMT940 reports whether head has the reference and account tags every
+// MT940 statement starts with.
+func isMT940(head []byte) bool {
+	hasReference, hasAccount := false, false
+	scanner := bufio.NewScanner(bytes.NewReader(head))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, ":20:"):
+			hasReference = true
+		case strings.HasPrefix(line, ":25:"):
+			hasAccount = true
+		}
+	}
+	return hasReference && hasAccount
+}
+
+// csvFormat matches the first line of head that is not a comment, split on
+// commas or semicolons, against csvSignatures.
+func csvFormat(head []byte) string {
+	// Skip the comment lines camt-csv writes above the header of its exports
+	line, rest, _ := bytes.Cut(head, []byte("\n"))
+	for bytes.HasPrefix(line, []byte("#")) && len(rest) > 0 {
+		line, rest, _ = bytes.Cut(rest, []byte("\n"))
+	}
+	delimiter := ','
+	if bytes.Count(line, []byte(";")) > bytes.Count(line, []byte(",")) {
+		delimiter = ';'
+	}
+
+	reader := csv.NewReader(bytes.NewReader(line))
+	reader.Comma = delimiter
+	reader.LazyQuotes = true
+	header, err := reader.Read()
+	if err != nil {
+		return ""
+	}
+
+	columns := make(map[string]bool, len(header))
+	for _, column := range header {
+		columns[strings.TrimSpace(column)] = true
+	}
+
+	for _, signature := range csvSignatures {
+		if hasColumns(columns, signature.columns) {
+			return signature.parser
+		}
+	}
+	return ""
+}
+
+func hasColumns(columns map[string]bool, required [][]string) bool {
+	for _, alternatives := range required {
+		found := false
+		for _, name := range alternatives {
+			if columns[name] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
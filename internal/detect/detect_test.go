@@ -0,0 +1,74 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		head string
+		want string
+	}{
+		{"pdf", "%PDF-1.7\n%âãÏÓ\n", PDF},
+		{"camt.053", `<?xml version="1.0" encoding="UTF-8"?><Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08">`, CAMT},
+		{"camt.054", `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.054.001.04">`, CAMT},
+		{"other xml", `<?xml version="1.0"?><Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.03">`, ""},
+		{"mt940", ":20:STARTUMSE\r\n:25:DE89370400440532013000\r\n:28C:00012/001\r\n", MT940},
+		{"revolut", "Type,Product,Started Date,Completed Date,Description,Amount,Fee,Currency,State,Balance\n", Revolut},
+		{"revolut french", "Type,Produit,Date de début,Date de fin,Description,Montant,Frais,Devise,État,Solde\n", Revolut},
+		{"revolut crypto", "Symbol,Type,Quantity,Price,Value,Fees,Date\n", RevolutCrypto},
+		{"revolut investment", "Date,Ticker,Type,Quantity,Price per share,Total Amount,Currency,FX Rate\n", RevolutInvestment},
+		{"selma", "Date,Description,Bookkeeping No.,Fund,Amount,Currency,Number of Shares\n", Selma},
+		{"wise with bom", "\xef\xbb\xbf\"TransferWise ID\",Date,Amount,Currency,Description\n", Wise},
+		{"debit", "Bénéficiaire;Date;Montant;Monnaie\n", Debit},
+		{"unknown csv", "Name,Value\nfoo,1\n", ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Format([]byte(tt.head)))
+		})
+	}
+}
+
+func TestFile(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"../camtparser/testdata/camt053_v08.xml", CAMT},
+		{"../mt940parser/testdata/postbank.sta", MT940},
+		{"../revolutparser/testdata/revolut_timestamps.csv", Revolut},
+		{"../selmaparser/testdata/selma_fee_refund.csv", Selma},
+		{"../wiseparser/testdata/wise_statement.csv", Wise},
+	}
+
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.path), func(t *testing.T) {
+			got, err := File(tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFile_Unrecognized(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	require.NoError(t, os.WriteFile(path, []byte("shopping list\n"), 0600))
+
+	got, err := File(path)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestFile_Missing(t *testing.T) {
+	_, err := File(filepath.Join(t.TempDir(), "missing.csv"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,335 @@
+// Package doctor checks that the environment camt-csv runs in is set up: the
+// pdftotext binary, the AI API key, the configuration and the category files.
+package doctor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"fjacquet/camt-csv/internal/config"
+	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/store"
+)
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	// StatusPass means the check succeeded.
+	StatusPass Status = "PASS"
+	// StatusWarn means a feature will not work, but conversions will.
+	StatusWarn Status = "WARN"
+	// StatusFail means camt-csv will not work until the problem is fixed.
+	StatusFail Status = "FAIL"
+)
+
+// Result is the outcome of one check, with a hint on how to fix it when it
+// did not pass.
+type Result struct {
+	Name   string
+	Status Status
+	Detail string
+	Hint   string
+}
+
+// Environment is what the checks inspect. Zero-value functions fall back to
+// the real system.
+type Environment struct {
+	// Config is the loaded configuration, nil when loading it failed.
+	Config *config.Config
+	// ConfigErr is the error returned while loading the configuration.
+	ConfigErr error
+	// ConfigDirs are the directories config.yaml and camt-csv.toml are looked up in.
+	ConfigDirs []string
+	// LookPath finds an executable, exec.LookPath by default.
+	LookPath func(file string) (string, error)
+	// Getenv reads an environment variable, os.Getenv by default.
+	Getenv func(key string) string
+}
+
+// Run performs all checks and returns their results in display order.
+func Run(env Environment) []Result {
+	if env.LookPath == nil {
+		env.LookPath = exec.LookPath
+	}
+	if env.Getenv == nil {
+		env.Getenv = os.Getenv
+	}
+
+	categoryStore := newStore(env.Config)
+	results := []Result{
+		checkPDFToText(env.LookPath),
+		checkConfig(env.ConfigErr),
+		checkConfigDirs(env.ConfigDirs),
+		checkAPIKey(env.Config, env.Getenv),
+	}
+	results = append(results, checkCategoryFiles(categoryStore)...)
+	results = append(results, checkMappingsWritable(categoryStore)...)
+	return results
+}
+
+// HasFailures reports whether any check failed.
+func HasFailures(results []Result) bool {
+	for _, r := range results {
+		if r.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Print writes results as a checklist, followed by a summary line.
+func Print(w io.Writer, results []Result) {
+	failed, warned := 0, 0
+	for _, r := range results {
+		_, _ = fmt.Fprintf(w, "[%s] %s: %s\n", r.Status, r.Name, r.Detail)
+		if r.Status != StatusPass && r.Hint != "" {
+			_, _ = fmt.Fprintf(w, "       -> %s\n", r.Hint)
+		}
+		switch r.Status {
+		case StatusFail:
+			failed++
+		case StatusWarn:
+			warned++
+		}
+	}
+	_, _ = fmt.Fprintf(w, "\n%d checks, %d failed, %d warnings\n", len(results), failed, warned)
+}
+
+// newStore builds the category store the way the container does, with the
+// default file names when the configuration could not be loaded.
+func newStore(cfg *config.Config) *store.CategoryStore {
+	if cfg == nil {
+		return store.NewCategoryStore("", "", "")
+	}
+	categoryStore := store.NewCategoryStore(cfg.Categories.File, cfg.Categories.CreditorsFile, cfg.Categories.DebtorsFile)
+	categoryStore.TagsFile = cfg.Categories.TagsFile
+	categoryStore.MCCFile = cfg.Categories.MCCFile
+	categoryStore.IBANFile = cfg.Categories.IBANMappingsFile
+	categoryStore.CleanupFile = cfg.Categories.CleanupFile
+	categoryStore.BudgetsFile = cfg.Categories.BudgetsFile
+	return categoryStore
+}
+
+func checkPDFToText(lookPath func(string) (string, error)) Result {
+	result := Result{Name: "pdftotext"}
+	path, err := lookPath("pdftotext")
+	if err != nil {
+		result.Status = StatusWarn
+		result.Detail = "not found in PATH, the pdf command will not work"
+		result.Hint = "Install poppler: 'brew install poppler' on macOS, 'apt install poppler-utils' on Debian/Ubuntu"
+		return result
+	}
+	result.Status = StatusPass
+	result.Detail = path
+	return result
+}
+
+func checkConfig(configErr error) Result {
+	result := Result{Name: "Configuration"}
+	if configErr != nil {
+		result.Status = StatusFail
+		result.Detail = configErr.Error()
+		result.Hint = "Fix config.yaml, camt-csv.toml or the CAMT_* environment variables named in the error"
+		return result
+	}
+	result.Status = StatusPass
+	result.Detail = "loaded"
+	return result
+}
+
+// checkConfigDirs passes when every existing config directory can be listed.
+// Having none is fine, the defaults apply.
+func checkConfigDirs(dirs []string) Result {
+	result := Result{Name: "Config directory", Status: StatusPass, Detail: "none found, using defaults"}
+
+	var found []string
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		if _, err := os.ReadDir(dir); err != nil {
+			result.Status = StatusFail
+			result.Detail = fmt.Sprintf("%s is not readable: %v", dir, err)
+			result.Hint = fmt.Sprintf("Give your user read access to %s", dir)
+			return result
+		}
+		found = append(found, dir)
+	}
+	if len(found) > 0 {
+		result.Detail = strings.Join(found, ", ")
+	}
+	return result
+}
+
+// checkAPIKey fails when AI categorization is enabled without a usable key,
+// and warns when no key is set at all.
+func checkAPIKey(cfg *config.Config, getenv func(string) string) Result {
+	result := Result{Name: "AI API key"}
+	aiEnabled := cfg != nil && cfg.AI.Enabled
+
+	for _, name := range config.APIKeyEnvVars {
+		key := getenv(name)
+		if key == "" {
+			continue
+		}
+		if strings.ContainsAny(key, " \t\r\n\"'") {
+			result.Status = StatusFail
+			result.Detail = fmt.Sprintf("%s contains whitespace or quotes", name)
+			result.Hint = fmt.Sprintf("Remove spaces and quotes around the key in %s", name)
+			return result
+		}
+		result.Status = StatusPass
+		result.Detail = fmt.Sprintf("set in %s", name)
+		return result
+	}
+
+	result.Hint = fmt.Sprintf("Set %s (or %s) in your environment or .env file",
+		config.APIKeyEnvVars[0], strings.Join(config.APIKeyEnvVars[1:], " / "))
+	if aiEnabled {
+		result.Status = StatusFail
+		result.Detail = "AI categorization is enabled but no API key is set"
+		return result
+	}
+	result.Status = StatusWarn
+	result.Detail = "not set, AI categorization is unavailable"
+	return result
+}
+
+// checkCategoryFiles loads each YAML file the categorizer reads. Missing files
+// only disable their part of the categorization; unparsable ones are fatal.
+func checkCategoryFiles(s *store.CategoryStore) []Result {
+	files := []struct {
+		name     string
+		filename string
+		fallback string
+		load     func() error
+	}{
+		{"Categories file", s.CategoriesFile, "categories.yaml", func() error { _, err := s.LoadCategories(); return err }},
+		{"Creditors file", s.CreditorsFile, "creditors.yaml", func() error { _, err := s.LoadCreditorMappings(); return err }},
+		{"Debtors file", s.DebtorsFile, "debtors.yaml", func() error { _, err := s.LoadDebtorMappings(); return err }},
+		{"Tags file", s.TagsFile, "tags.yaml", func() error { _, err := s.LoadTagRules(); return err }},
+		{"MCC file", s.MCCFile, "mcc.yaml", func() error { _, err := s.LoadMCCMappings(); return err }},
+		{"IBAN mappings file", s.IBANFile, "iban_mappings.yaml", func() error { _, err := s.LoadIBANMappings(); return err }},
+		{"Cleanup file", s.CleanupFile, "cleanup.yaml", func() error {
+			rules, err := s.LoadNameCleanupRules()
+			if err != nil {
+				return err
+			}
+			_, err = models.NewNameCleaner(rules)
+			return err
+		}},
+		{"Budgets file", s.BudgetsFile, "budgets.yaml", func() error { _, err := s.LoadBudgets(); return err }},
+	}
+
+	results := make([]Result, 0, len(files))
+	for _, f := range files {
+		filename := f.filename
+		if filename == "" {
+			filename = f.fallback
+		}
+		result := Result{Name: f.name}
+
+		path, err := s.FindConfigFile(filename)
+		if err != nil {
+			result.Status = StatusWarn
+			result.Detail = fmt.Sprintf("%s not found", filename)
+			result.Hint = fmt.Sprintf("Create %s in ./database or ~/.config/camt-csv", filename)
+			results = append(results, result)
+			continue
+		}
+		if err := f.load(); err != nil {
+			result.Status = StatusFail
+			result.Detail = err.Error()
+			result.Hint = fmt.Sprintf("Fix the YAML syntax in %s", path)
+			results = append(results, result)
+			continue
+		}
+		result.Status = StatusPass
+		result.Detail = path
+		results = append(results, result)
+	}
+	return results
+}
+
+// checkMappingsWritable checks that learned mappings can be saved: the
+// existing mapping files must be writable, or the directory they would be
+// created in.
+func checkMappingsWritable(s *store.CategoryStore) []Result {
+	files := []struct {
+		name     string
+		filename string
+		fallback string
+	}{
+		{"Creditors file writable", s.CreditorsFile, "creditors.yaml"},
+		{"Debtors file writable", s.DebtorsFile, "debtors.yaml"},
+	}
+
+	results := make([]Result, 0, len(files))
+	for _, f := range files {
+		filename := f.filename
+		if filename == "" {
+			filename = f.fallback
+		}
+		result := Result{Name: f.name, Status: StatusPass}
+
+		// Same location SaveCreditorMappings and SaveDebtorMappings use
+		path, err := s.FindConfigFile(filename)
+		if err != nil {
+			path = filename
+			if !filepath.IsAbs(filename) {
+				path = filepath.Join("database", filename)
+			}
+		}
+
+		if err := checkWritable(path); err != nil {
+			result.Status = StatusFail
+			result.Detail = err.Error()
+			result.Hint = fmt.Sprintf("Give your user write access to %s and its directory", path)
+		} else {
+			result.Detail = path
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// checkWritable reports whether path can be written, or created when it does
+// not exist, without modifying it. Saving also writes a backup next to the
+// file, so its directory must be writable too.
+func checkWritable(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0) // #nosec G304 -- config file path resolved internally
+		if err != nil {
+			return fmt.Errorf("cannot write %s: %w", path, err)
+		}
+		_ = file.Close()
+	}
+
+	// The directory may not exist yet; saving creates it, so check the
+	// closest existing parent
+	dir := filepath.Dir(path)
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	probe, err := os.CreateTemp(dir, ".camt-csv-doctor-*")
+	if err != nil {
+		return fmt.Errorf("cannot write in %s: %w", dir, err)
+	}
+	_ = probe.Close()
+	_ = os.Remove(probe.Name())
+	return nil
+}
@@ -0,0 +1,148 @@
+package doctor
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"fjacquet/camt-csv/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func findResult(t *testing.T, results []Result, name string) Result {
+	t.Helper()
+	for _, r := range results {
+		if r.Name == name {
+			return r
+		}
+	}
+	require.Failf(t, "missing result", "no check named %q", name)
+	return Result{}
+}
+
+func getenvFrom(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+func TestCheckPDFToText(t *testing.T) {
+	found := checkPDFToText(func(string) (string, error) { return "/usr/bin/pdftotext", nil })
+	assert.Equal(t, StatusPass, found.Status)
+	assert.Equal(t, "/usr/bin/pdftotext", found.Detail)
+
+	missing := checkPDFToText(func(string) (string, error) { return "", exec.ErrNotFound })
+	assert.Equal(t, StatusWarn, missing.Status)
+	assert.Contains(t, missing.Hint, "poppler")
+}
+
+func TestCheckAPIKey(t *testing.T) {
+	enabled := &config.Config{}
+	enabled.AI.Enabled = true
+
+	tests := []struct {
+		name   string
+		cfg    *config.Config
+		vars   map[string]string
+		status Status
+		detail string
+	}{
+		{"key set", enabled, map[string]string{"GEMINI_API_KEY": "abc123"}, StatusPass, "set in GEMINI_API_KEY"},
+		{"first variable wins", enabled, map[string]string{"CAMT_AI_API_KEY": "abc", "GEMINI_API_KEY": "x y"}, StatusPass, "set in CAMT_AI_API_KEY"},
+		{"quoted key", enabled, map[string]string{"OPENROUTER_API_KEY": `"abc"`}, StatusFail, "OPENROUTER_API_KEY contains whitespace or quotes"},
+		{"missing with AI enabled", enabled, nil, StatusFail, "AI categorization is enabled but no API key is set"},
+		{"missing with AI disabled", &config.Config{}, nil, StatusWarn, "not set, AI categorization is unavailable"},
+		{"missing without config", nil, nil, StatusWarn, "not set, AI categorization is unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := checkAPIKey(tt.cfg, getenvFrom(tt.vars))
+			assert.Equal(t, tt.status, result.Status)
+			assert.Equal(t, tt.detail, result.Detail)
+		})
+	}
+}
+
+func TestCheckConfigDirs(t *testing.T) {
+	dir := t.TempDir()
+
+	result := checkConfigDirs([]string{filepath.Join(dir, "missing")})
+	assert.Equal(t, StatusPass, result.Status)
+	assert.Equal(t, "none found, using defaults", result.Detail)
+
+	result = checkConfigDirs([]string{filepath.Join(dir, "missing"), dir})
+	assert.Equal(t, StatusPass, result.Status)
+	assert.Equal(t, dir, result.Detail)
+}
+
+func TestRun_CategoryFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("HOME", dir)
+	require.NoError(t, os.MkdirAll("database", 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join("database", "categories.yaml"), []byte("categories:\n  - name: Food\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join("database", "creditors.yaml"), []byte("coop: [unclosed"), 0o600))
+
+	results := Run(Environment{
+		LookPath: func(string) (string, error) { return "/usr/bin/pdftotext", nil },
+		Getenv:   getenvFrom(nil),
+	})
+
+	assert.Equal(t, StatusPass, findResult(t, results, "Categories file").Status)
+	assert.Equal(t, StatusFail, findResult(t, results, "Creditors file").Status)
+	debtors := findResult(t, results, "Debtors file")
+	assert.Equal(t, StatusWarn, debtors.Status)
+	assert.Equal(t, "debtors.yaml not found", debtors.Detail)
+
+	writable := findResult(t, results, "Debtors file writable")
+	assert.Equal(t, StatusPass, writable.Status)
+	assert.Equal(t, filepath.Join("database", "debtors.yaml"), writable.Detail)
+	assert.True(t, HasFailures(results))
+}
+
+func TestRun_ConfigError(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	results := Run(Environment{
+		ConfigErr: errors.New("invalid log level: loud"),
+		LookPath:  func(string) (string, error) { return "/usr/bin/pdftotext", nil },
+		Getenv:    getenvFrom(nil),
+	})
+
+	result := findResult(t, results, "Configuration")
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Equal(t, "invalid log level: loud", result.Detail)
+	assert.True(t, HasFailures(results))
+}
+
+func TestCheckWritable_CreatesNothing(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "database", "creditors.yaml")
+
+	require.NoError(t, checkWritable(path))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestPrint(t *testing.T) {
+	var buf bytes.Buffer
+	Print(&buf, []Result{
+		{Name: "pdftotext", Status: StatusPass, Detail: "/usr/bin/pdftotext", Hint: "unused"},
+		{Name: "AI API key", Status: StatusWarn, Detail: "not set", Hint: "Set CAMT_AI_API_KEY"},
+	})
+
+	assert.Equal(t, `[PASS] pdftotext: /usr/bin/pdftotext
+[WARN] AI API key: not set
+       -> Set CAMT_AI_API_KEY
+
+2 checks, 0 failed, 1 warnings
+`, buf.String())
+	assert.False(t, HasFailures([]Result{{Status: StatusWarn}}))
+}
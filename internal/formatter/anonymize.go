@@ -0,0 +1,106 @@
+package formatter
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+
+	"fjacquet/camt-csv/internal/models"
+)
+
+// anonymizeFormatter wraps another formatter and replaces party names, IBANs,
+// free text and references with pseudonyms before formatting, so that output
+// can be shared without exposing them. Amounts, dates and categories are kept.
+type anonymizeFormatter struct {
+	inner OutputFormatter
+	key   []byte
+}
+
+// newAnonymizeFormatter returns an anonymizeFormatter keying its pseudonyms
+// with key, or with a random key generated once per run when key is empty.
+func newAnonymizeFormatter(inner OutputFormatter, key []byte) *anonymizeFormatter {
+	if len(key) == 0 {
+		key = runKey()
+	}
+	return &anonymizeFormatter{inner: inner, key: key}
+}
+
+// Anonymizes reports whether f pseudonymizes its output. Free text that f
+// does not format, such as statement notes, must then be left out of it.
+func Anonymizes(f OutputFormatter) bool {
+	_, ok := f.(*anonymizeFormatter)
+	return ok
+}
+
+// runKey is the random pseudonym key used when none is configured: the same
+// value gets the same pseudonym in all the files of a run, but not across
+// runs.
+var runKey = sync.OnceValue(func() []byte {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key) // never fails, see crypto/rand.Read
+	return key
+})
+
+// Header returns the wrapped formatter's columns.
+func (f *anonymizeFormatter) Header() []string {
+	return f.inner.Header()
+}
+
+// Format pseudonymizes a copy of transactions and formats it with the wrapped
+// formatter. The input transactions are not modified.
+func (f *anonymizeFormatter) Format(transactions []models.Transaction) ([][]string, error) {
+	return f.inner.Format(f.anonymize(transactions))
+}
+
+// anonymize returns a copy of transactions with party names, IBANs, free text
+// and references replaced by their pseudonyms.
+func (f *anonymizeFormatter) anonymize(transactions []models.Transaction) []models.Transaction {
+	anonymized := make([]models.Transaction, len(transactions))
+	for i, tx := range transactions {
+		tx.PartyName = f.pseudonym("Party", tx.PartyName)
+		tx.Name = f.pseudonym("Party", tx.Name)
+		tx.Payee = f.pseudonym("Party", tx.Payee)
+		tx.Payer = f.pseudonym("Party", tx.Payer)
+		tx.Recipient = f.pseudonym("Party", tx.Recipient)
+		tx.PartyIBAN = f.pseudonym("IBAN", compactIBAN(tx.PartyIBAN))
+		tx.IBAN = f.pseudonym("IBAN", compactIBAN(tx.IBAN))
+		// Free text often names the parties or carries account numbers
+		tx.Description = f.pseudonym("Text", tx.Description)
+		tx.RemittanceInfo = f.pseudonym("Text", tx.RemittanceInfo)
+		tx.Reference = f.pseudonym("Ref", tx.Reference)
+		tx.EntryReference = f.pseudonym("Ref", tx.EntryReference)
+		tx.AccountServicer = f.pseudonym("Ref", tx.AccountServicer)
+		tx.CreditorReference = f.pseudonym("Ref", tx.CreditorReference)
+		tx.StructuredReference = f.pseudonym("Ref", tx.StructuredReference)
+		anonymized[i] = tx
+	}
+	return anonymized
+}
+
+// Delimiter returns the wrapped formatter's delimiter.
+func (f *anonymizeFormatter) Delimiter() rune {
+	return f.inner.Delimiter()
+}
+
+// pseudonym returns a token derived from an HMAC of value keyed by the
+// formatter's key, so that the same value always gets the same token while
+// the key is unchanged, and tokens cannot be reversed by hashing guessed
+// values. Values differing only in case or surrounding spaces share a token;
+// an empty value stays empty.
+func (f *anonymizeFormatter) pseudonym(prefix, value string) string {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, f.key)
+	mac.Write([]byte(prefix + ":" + value))
+	return prefix + "-" + hex.EncodeToString(mac.Sum(nil)[:4])
+}
+
+// compactIBAN removes the spaces of a printed IBAN.
+func compactIBAN(iban string) string {
+	return strings.ReplaceAll(iban, " ", "")
+}
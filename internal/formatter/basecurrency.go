@@ -0,0 +1,43 @@
+package formatter
+
+import (
+	"fjacquet/camt-csv/internal/currency"
+	"fjacquet/camt-csv/internal/models"
+)
+
+// baseCurrencyFormatter wraps another formatter and appends BaseAmount and
+// BaseCurrency columns computed by a currency.Converter.
+type baseCurrencyFormatter struct {
+	inner     OutputFormatter
+	converter *currency.Converter
+}
+
+// Header returns the wrapped formatter's columns followed by BaseAmount and BaseCurrency.
+func (f *baseCurrencyFormatter) Header() []string {
+	return append(f.inner.Header(), "BaseAmount", "BaseCurrency")
+}
+
+// Format formats transactions with the wrapped formatter and appends the base
+// amount of each row. Rows without an applicable rate get empty base columns.
+func (f *baseCurrencyFormatter) Format(transactions []models.Transaction) ([][]string, error) {
+	rows, err := f.inner.Format(transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		baseAmount, baseCurrency := "", ""
+		if amount, ok := f.converter.Convert(transactions[i]); ok {
+			baseAmount = models.FormatAmount(amount, f.converter.Base())
+			baseCurrency = f.converter.Base()
+		}
+		rows[i] = append(rows[i], baseAmount, baseCurrency)
+	}
+
+	return rows, nil
+}
+
+// Delimiter returns the wrapped formatter's delimiter.
+func (f *baseCurrencyFormatter) Delimiter() rune {
+	return f.inner.Delimiter()
+}
@@ -0,0 +1,39 @@
+package formatter
+
+import (
+	"fjacquet/camt-csv/internal/models"
+)
+
+// descriptionFormatter wraps another formatter and replaces the description
+// of each transaction with the one rendered from a template.
+type descriptionFormatter struct {
+	inner    OutputFormatter
+	template models.DescriptionTemplate
+}
+
+// Header returns the wrapped formatter's columns.
+func (f *descriptionFormatter) Header() []string {
+	return f.inner.Header()
+}
+
+// Format renders the description of each transaction and formats the
+// result with the wrapped formatter. The input transactions are not modified.
+func (f *descriptionFormatter) Format(transactions []models.Transaction) ([][]string, error) {
+	return f.inner.Format(renderDescriptions(transactions, f.template))
+}
+
+// renderDescriptions returns a copy of transactions with each description
+// rendered from template.
+func renderDescriptions(transactions []models.Transaction, template models.DescriptionTemplate) []models.Transaction {
+	rendered := make([]models.Transaction, len(transactions))
+	for i, tx := range transactions {
+		tx.Description = template.Render(tx)
+		rendered[i] = tx
+	}
+	return rendered
+}
+
+// Delimiter returns the wrapped formatter's delimiter.
+func (f *descriptionFormatter) Delimiter() rune {
+	return f.inner.Delimiter()
+}
@@ -0,0 +1,110 @@
+package formatter
+
+import (
+	"strconv"
+	"strings"
+
+	"fjacquet/camt-csv/internal/models"
+)
+
+// extraColumnFormatter wraps another formatter and appends one column whose
+// value is computed from each transaction.
+type extraColumnFormatter struct {
+	inner OutputFormatter
+	name  string
+	value func(tx models.Transaction) string
+}
+
+// categorySourceColumn returns the categorization method of a transaction.
+func categorySourceColumn(tx models.Transaction) string {
+	return string(tx.CategorySource)
+}
+
+// tagsColumn returns the tags of a transaction joined with semicolons.
+func tagsColumn(tx models.Transaction) string {
+	return strings.Join(tx.Tags, ";")
+}
+
+// sequenceNumberColumn returns the entry's position in its source file, or ""
+// when the parser does not record one.
+func sequenceNumberColumn(tx models.Transaction) string {
+	if tx.SequenceNumber == 0 {
+		return ""
+	}
+	return strconv.Itoa(tx.SequenceNumber)
+}
+
+// partyBICColumn returns the BIC of the counterparty's bank.
+func partyBICColumn(tx models.Transaction) string {
+	return tx.PartyBIC
+}
+
+// creditorReferenceColumn returns the ISO 11649 creditor reference of a transaction.
+func creditorReferenceColumn(tx models.Transaction) string {
+	return tx.CreditorReference
+}
+
+// structuredReferenceColumn returns the structured remittance reference of a transaction.
+func structuredReferenceColumn(tx models.Transaction) string {
+	return tx.StructuredReference
+}
+
+// referenceTypeColumn returns the type (QRR, SCOR, NON) of the structured reference.
+func referenceTypeColumn(tx models.Transaction) string {
+	return tx.ReferenceType
+}
+
+// cardLast4Column returns the last four digits of the card a transaction was paid with.
+func cardLast4Column(tx models.Transaction) string {
+	return tx.CardLast4
+}
+
+// mccColumn returns the merchant category code of a card transaction.
+func mccColumn(tx models.Transaction) string {
+	return tx.MCC
+}
+
+// bankTxCodeDescriptionColumn returns the meaning of a transaction's bank
+// transaction code. Transactions read back from CSV only have the code, so it
+// is resolved again when the parser left no description.
+func bankTxCodeDescriptionColumn(tx models.Transaction) string {
+	if tx.BankTxCodeDescription != "" {
+		return tx.BankTxCodeDescription
+	}
+	return models.BankTxCodeDescription(tx.BankTxCode)
+}
+
+// fxDifferenceColumn returns the difference between the booked amount and the
+// converted original amount of a transaction, or "" when it has no conversion.
+func fxDifferenceColumn(tx models.Transaction) string {
+	difference, ok := tx.FXDifference()
+	if !ok {
+		return ""
+	}
+	return models.FormatAmount(difference, tx.Currency)
+}
+
+// Header returns the wrapped formatter's columns followed by the extra column.
+func (f *extraColumnFormatter) Header() []string {
+	return append(f.inner.Header(), f.name)
+}
+
+// Format formats transactions with the wrapped formatter and appends the
+// extra column to each row.
+func (f *extraColumnFormatter) Format(transactions []models.Transaction) ([][]string, error) {
+	rows, err := f.inner.Format(transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		rows[i] = append(rows[i], f.value(transactions[i]))
+	}
+
+	return rows, nil
+}
+
+// Delimiter returns the wrapped formatter's delimiter.
+func (f *extraColumnFormatter) Delimiter() rune {
+	return f.inner.Delimiter()
+}
@@ -6,6 +6,7 @@ package formatter
 import (
 	"fmt"
 
+	"fjacquet/camt-csv/internal/currency"
 	"fjacquet/camt-csv/internal/models"
 )
 
@@ -36,6 +37,181 @@ type OutputFormatter interface {
 	Delimiter() rune
 }
 
+// Options holds optional output settings shared by the built-in formatters.
+// The zero value keeps each formatter's default layout.
+type Options struct {
+	// IncludeTime appends the time of day (HH:MM) to date columns.
+	IncludeTime bool
+
+	// Locale sets the decimal separator and date layout of the formatters
+	// built on the standard layout (standard format and export profiles).
+	// The zero value keeps the default formatting.
+	Locale models.Locale
+
+	// SignedAmount writes a single signed Amount column (negative for debits)
+	// instead of an Amount plus CreditDebit indicator. Only affects formatters
+	// that emit a separate direction column.
+	SignedAmount bool
+
+	// BaseCurrency, when set, appends BaseAmount and BaseCurrency columns with
+	// each amount converted to the converter's base currency.
+	BaseCurrency *currency.Converter
+
+	// CategorySource appends a CategorySource column with the categorization
+	// method (mapping, iban, mcc, keyword, ai, internal, override or fallback) of each transaction.
+	CategorySource bool
+
+	// Tags appends a Tags column with the semicolon-joined tags of each transaction.
+	Tags bool
+
+	// SequenceNumber appends a SequenceNumber column with each entry's position
+	// in its source file (empty when the parser does not record it).
+	SequenceNumber bool
+
+	// PartyBIC appends a PartyBIC column with the BIC of the counterparty's
+	// bank (empty when the source does not provide it).
+	PartyBIC bool
+
+	// CreditorReference appends a CreditorReference column with the ISO 11649
+	// (RF) creditor reference of each transaction (empty when there is none).
+	CreditorReference bool
+
+	// ReferenceType appends a StructuredReference and a ReferenceType column
+	// with the first structured remittance reference and its type (QRR, SCOR
+	// or NON), so QR and ISO 11649 references can be told apart.
+	ReferenceType bool
+
+	// CardLast4 appends a CardLast4 column with the last four digits of the
+	// masked card number (empty when the source does not print one).
+	CardLast4 bool
+
+	// MCC appends an MCC column with the merchant category code of card
+	// transactions (empty when the source does not print one).
+	MCC bool
+
+	// BankTxCodeDescription appends a BankTxCodeDescription column with the
+	// meaning of each transaction's bank transaction code, e.g. "SEPA Credit
+	// Transfer" for PMNT/RCDT/ESCT (empty when the code is unknown).
+	BankTxCodeDescription bool
+
+	// FXDifference appends an FXDifference column with the difference between
+	// the booked amount and the original amount converted at the exchange rate
+	// (empty when the transaction has no conversion).
+	FXDifference bool
+
+	// Profile, when set, replaces the selected format with the export
+	// profile's column layout. It must have passed ValidateProfile.
+	Profile *models.ExportProfile
+
+	// DescriptionTemplate, when not zero, replaces the description of each
+	// transaction with the one rendered from the template.
+	DescriptionTemplate models.DescriptionTemplate
+
+	// Sort, when not empty, orders the rows by its keys. Otherwise rows keep
+	// the order of the transactions passed to the formatter.
+	Sort models.SortOrder
+
+	// Anonymize replaces party names, IBANs, descriptions and references with
+	// pseudonyms derived from an HMAC of the value, the same value always
+	// getting the same pseudonym.
+	Anonymize bool
+
+	// AnonymizeKey keys the pseudonyms of Anonymize. Empty uses a random key
+	// generated once per run, so pseudonyms only match within the run.
+	AnonymizeKey []byte
+}
+
+// dateLayout returns layout extended with the time of day when IncludeTime is set.
+func (o Options) dateLayout(layout string) string {
+	if o.IncludeTime {
+		return layout + " 15:04"
+	}
+	return layout
+}
+
+// Configurable is implemented by formatters that honour Options.
+type Configurable interface {
+	// WithOptions returns a copy of the formatter configured with opts.
+	WithOptions(opts Options) OutputFormatter
+}
+
+// ApplyOptions returns f configured with opts when it implements Configurable,
+// or f unchanged otherwise. Base-currency columns, description templates,
+// sort orders and anonymization apply to any formatter.
+// An export profile in opts takes the place of f.
+func ApplyOptions(f OutputFormatter, opts Options) OutputFormatter {
+	if opts.Profile != nil {
+		f = NewProfileFormatter(*opts.Profile)
+	}
+	if c, ok := f.(Configurable); ok {
+		f = c.WithOptions(opts)
+	}
+	if opts.CategorySource {
+		f = &extraColumnFormatter{inner: f, name: "CategorySource", value: categorySourceColumn}
+	}
+	if opts.Tags {
+		f = &extraColumnFormatter{inner: f, name: "Tags", value: tagsColumn}
+	}
+	if opts.SequenceNumber {
+		f = &extraColumnFormatter{inner: f, name: "SequenceNumber", value: sequenceNumberColumn}
+	}
+	if opts.PartyBIC {
+		f = &extraColumnFormatter{inner: f, name: "PartyBIC", value: partyBICColumn}
+	}
+	if opts.CreditorReference {
+		f = &extraColumnFormatter{inner: f, name: "CreditorReference", value: creditorReferenceColumn}
+	}
+	if opts.ReferenceType {
+		f = &extraColumnFormatter{inner: f, name: "StructuredReference", value: structuredReferenceColumn}
+		f = &extraColumnFormatter{inner: f, name: "ReferenceType", value: referenceTypeColumn}
+	}
+	if opts.CardLast4 {
+		f = &extraColumnFormatter{inner: f, name: "CardLast4", value: cardLast4Column}
+	}
+	if opts.MCC {
+		f = &extraColumnFormatter{inner: f, name: "MCC", value: mccColumn}
+	}
+	if opts.BankTxCodeDescription {
+		f = &extraColumnFormatter{inner: f, name: "BankTxCodeDescription", value: bankTxCodeDescriptionColumn}
+	}
+	if opts.FXDifference {
+		f = &extraColumnFormatter{inner: f, name: "FXDifference", value: fxDifferenceColumn}
+	}
+	if opts.BaseCurrency != nil {
+		f = &baseCurrencyFormatter{inner: f, converter: opts.BaseCurrency}
+	}
+	if !opts.DescriptionTemplate.IsZero() {
+		f = &descriptionFormatter{inner: f, template: opts.DescriptionTemplate}
+	}
+	if len(opts.Sort) > 0 {
+		f = &sortFormatter{inner: f, order: opts.Sort}
+	}
+	// Outermost, so that descriptions are rendered and rows sorted from the
+	// pseudonyms rather than the real names
+	if opts.Anonymize {
+		f = newAnonymizeFormatter(f, opts.AnonymizeKey)
+	}
+	return f
+}
+
+// ApplyTransactionOptions returns transactions with the options of opts that
+// rewrite transactions rather than add columns applied in the order
+// ApplyOptions applies them: anonymization, then the sort order and the
+// description template. It is for output written without a formatter, such
+// as a ledger journal. The input transactions are not modified.
+func ApplyTransactionOptions(transactions []models.Transaction, opts Options) []models.Transaction {
+	if opts.Anonymize {
+		transactions = newAnonymizeFormatter(nil, opts.AnonymizeKey).anonymize(transactions)
+	}
+	if len(opts.Sort) > 0 {
+		transactions = sortedCopy(transactions, opts.Sort)
+	}
+	if !opts.DescriptionTemplate.IsZero() {
+		transactions = renderDescriptions(transactions, opts.DescriptionTemplate)
+	}
+	return transactions
+}
+
 // FormatterRegistry manages available output formatters.
 // It provides a centralized registry for looking up formatters by name
 // and supports extensibility through the Register method.
@@ -1,9 +1,13 @@
 package formatter
 
 import (
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"fjacquet/camt-csv/internal/currency"
+	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
 
 	"github.com/shopspring/decimal"
@@ -413,3 +417,456 @@ func TestMapStatusToICompta(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatters_WithTimeOption(t *testing.T) {
+	tx := createTestTransaction()
+	tx.Date = time.Date(2026, 2, 15, 9, 45, 12, 0, time.UTC)
+	opts := Options{IncludeTime: true}
+
+	tests := []struct {
+		name      string
+		formatter OutputFormatter
+		expected  string
+	}{
+		{"standard", NewStandardFormatter(), "15.02.2026 09:45"},
+		{"icompta", NewIComptaFormatter(), "15.02.2026 09:45"},
+		{"jumpsoft", NewJumpsoftFormatter(), "2026-02-15 09:45"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configured := ApplyOptions(tt.formatter, opts)
+			rows, err := configured.Format([]models.Transaction{tx})
+			require.NoError(t, err)
+			assert.Contains(t, rows[0], tt.expected)
+
+			// The original formatter keeps its date-only layout
+			rows, err = tt.formatter.Format([]models.Transaction{tx})
+			require.NoError(t, err)
+			assert.NotContains(t, rows[0], tt.expected)
+		})
+	}
+}
+
+func TestStandardFormatter_SignedAmount(t *testing.T) {
+	f := ApplyOptions(NewStandardFormatter(), Options{SignedAmount: true})
+
+	header := f.Header()
+	assert.Len(t, header, 28)
+	assert.NotContains(t, header, "CreditDebit")
+
+	tx := createTestTransaction()
+	tx.Amount = decimal.NewFromFloat(15.50) // debit stored unsigned
+	rows, err := f.Format([]models.Transaction{tx})
+	require.NoError(t, err)
+	assert.Len(t, rows[0], len(header))
+	assert.Equal(t, "-15.50", rows[0][8])
+
+	// Default formatter keeps the 29-column layout
+	assert.Len(t, NewStandardFormatter().Header(), 29)
+}
+
+func TestStandardFormatter_Locale(t *testing.T) {
+	locale, err := models.LookupLocale("de-CH")
+	require.NoError(t, err)
+	f := ApplyOptions(NewStandardFormatter(), Options{Locale: locale})
+
+	rows, err := f.Format([]models.Transaction{createTestTransaction()})
+	require.NoError(t, err)
+	assert.Equal(t, "-15.50", rows[0][8])
+
+	locale, err = models.LookupLocale("fr-FR")
+	require.NoError(t, err)
+	f = ApplyOptions(NewStandardFormatter(), Options{Locale: locale})
+	rows, err = f.Format([]models.Transaction{createTestTransaction()})
+	require.NoError(t, err)
+	assert.Equal(t, "-15,50", rows[0][8])
+	assert.Equal(t, "15/02/2026", rows[0][1])
+}
+
+func TestFormatters_BaseCurrencyOption(t *testing.T) {
+	chf := createTestTransaction()
+	chf.Currency = "CHF"
+	chf.Amount = decimal.RequireFromString("42.00")
+
+	usd := createTestTransaction()
+	usd.Currency = "USD"
+
+	opts := Options{BaseCurrency: currency.NewConverter("CHF", nil, logging.NewMockLogger())}
+
+	for _, f := range []OutputFormatter{NewStandardFormatter(), NewIComptaFormatter(), NewJumpsoftFormatter()} {
+		configured := ApplyOptions(f, opts)
+		header := configured.Header()
+		assert.Equal(t, []string{"BaseAmount", "BaseCurrency"}, header[len(header)-2:])
+		assert.Equal(t, f.Delimiter(), configured.Delimiter())
+
+		rows, err := configured.Format([]models.Transaction{chf, usd})
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		for _, row := range rows {
+			assert.Len(t, row, len(header))
+		}
+		assert.Equal(t, []string{"42.00", "CHF"}, rows[0][len(header)-2:])
+		assert.Equal(t, []string{"", ""}, rows[1][len(header)-2:], "missing rate leaves base columns empty")
+	}
+}
+
+func TestFormatters_CategorySourceOption(t *testing.T) {
+	mapped := createTestTransaction()
+	mapped.CategorySource = models.CategorySourceMapping
+
+	parserSet := createTestTransaction()
+
+	for _, f := range []OutputFormatter{NewStandardFormatter(), NewIComptaFormatter(), NewJumpsoftFormatter()} {
+		configured := ApplyOptions(f, Options{CategorySource: true})
+		header := configured.Header()
+		assert.Equal(t, "CategorySource", header[len(header)-1])
+		assert.Len(t, header, len(f.Header())+1)
+
+		rows, err := configured.Format([]models.Transaction{mapped, parserSet})
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		assert.Equal(t, "mapping", rows[0][len(header)-1])
+		assert.Equal(t, "", rows[1][len(header)-1], "category set by the parser has no source")
+	}
+}
+
+func TestFormatters_TagsOption(t *testing.T) {
+	tagged := createTestTransaction()
+	tagged.Tags = []string{"business", "reimbursable"}
+
+	untagged := createTestTransaction()
+
+	for _, f := range []OutputFormatter{NewStandardFormatter(), NewIComptaFormatter(), NewJumpsoftFormatter()} {
+		configured := ApplyOptions(f, Options{CategorySource: true, Tags: true})
+		header := configured.Header()
+		assert.Equal(t, []string{"CategorySource", "Tags"}, header[len(header)-2:])
+
+		rows, err := configured.Format([]models.Transaction{tagged, untagged})
+		require.NoError(t, err)
+		assert.Equal(t, "business;reimbursable", rows[0][len(header)-1])
+		assert.Equal(t, "", rows[1][len(header)-1])
+	}
+}
+
+func TestFormatters_SequenceNumberOption(t *testing.T) {
+	sequenced := createTestTransaction()
+	sequenced.SequenceNumber = 7
+
+	unsequenced := createTestTransaction()
+
+	for _, f := range []OutputFormatter{NewStandardFormatter(), NewIComptaFormatter(), NewJumpsoftFormatter()} {
+		configured := ApplyOptions(f, Options{Tags: true, SequenceNumber: true})
+		header := configured.Header()
+		assert.Equal(t, []string{"Tags", "SequenceNumber"}, header[len(header)-2:])
+
+		rows, err := configured.Format([]models.Transaction{sequenced, unsequenced})
+		require.NoError(t, err)
+		assert.Equal(t, "7", rows[0][len(header)-1])
+		assert.Equal(t, "", rows[1][len(header)-1], "parsers without an entry order leave the column empty")
+	}
+}
+
+func TestFormatters_PartyBICOption(t *testing.T) {
+	wire := createTestTransaction()
+	wire.PartyBIC = "BCGECHGGXXX"
+
+	card := createTestTransaction()
+
+	for _, f := range []OutputFormatter{NewStandardFormatter(), NewIComptaFormatter(), NewJumpsoftFormatter()} {
+		assert.NotContains(t, ApplyOptions(f, Options{}).Header(), "PartyBIC")
+
+		configured := ApplyOptions(f, Options{PartyBIC: true})
+		header := configured.Header()
+		assert.Equal(t, "PartyBIC", header[len(header)-1])
+
+		rows, err := configured.Format([]models.Transaction{wire, card})
+		require.NoError(t, err)
+		assert.Equal(t, "BCGECHGGXXX", rows[0][len(header)-1])
+		assert.Equal(t, "", rows[1][len(header)-1])
+	}
+}
+
+func TestFormatters_CurrencyPrecision(t *testing.T) {
+	tx := createTestTransaction()
+	tx.Amount = decimal.RequireFromString("-1499.6")
+	tx.Currency = "JPY"
+
+	rows, err := NewIComptaFormatter().Format([]models.Transaction{tx})
+	require.NoError(t, err)
+	assert.Contains(t, rows[0], "-1500")
+
+	rows, err = NewJumpsoftFormatter().Format([]models.Transaction{tx})
+	require.NoError(t, err)
+	assert.Contains(t, rows[0], "-1500")
+}
+
+func TestFormatters_CreditorReferenceOption(t *testing.T) {
+	invoice := createTestTransaction()
+	invoice.CreditorReference = "RF18539007547034"
+
+	for _, f := range []OutputFormatter{NewStandardFormatter(), NewIComptaFormatter(), NewJumpsoftFormatter()} {
+		configured := ApplyOptions(f, Options{CreditorReference: true})
+		header := configured.Header()
+		assert.Equal(t, "CreditorReference", header[len(header)-1])
+
+		rows, err := configured.Format([]models.Transaction{invoice, createTestTransaction()})
+		require.NoError(t, err)
+		assert.Equal(t, "RF18539007547034", rows[0][len(header)-1])
+		assert.Equal(t, "", rows[1][len(header)-1])
+	}
+}
+
+func TestFormatters_ReferenceTypeOption(t *testing.T) {
+	qrBill := createTestTransaction()
+	qrBill.StructuredReference = "210000000003139471430009017"
+	qrBill.ReferenceType = "QRR"
+
+	configured := ApplyOptions(NewStandardFormatter(), Options{ReferenceType: true})
+	header := configured.Header()
+	assert.Equal(t, []string{"StructuredReference", "ReferenceType"}, header[len(header)-2:])
+
+	rows, err := configured.Format([]models.Transaction{qrBill, createTestTransaction()})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"210000000003139471430009017", "QRR"}, rows[0][len(header)-2:])
+	assert.Equal(t, []string{"", ""}, rows[1][len(header)-2:])
+}
+
+func TestFormatters_BankTxCodeDescriptionOption(t *testing.T) {
+	parsed := createTestTransaction()
+	parsed.BankTxCode = "PMNT/RCDT/ESCT"
+	parsed.BankTxCodeDescription = "SEPA Credit Transfer"
+	// Read back from CSV: the code without its description
+	reread := createTestTransaction()
+	reread.BankTxCode = "PMNT/IDDT/ESDD"
+	unknown := createTestTransaction()
+	unknown.BankTxCode = "VIRT-IN"
+
+	for _, f := range []OutputFormatter{NewStandardFormatter(), NewIComptaFormatter(), NewJumpsoftFormatter()} {
+		configured := ApplyOptions(f, Options{BankTxCodeDescription: true})
+		header := configured.Header()
+		assert.Equal(t, "BankTxCodeDescription", header[len(header)-1])
+
+		rows, err := configured.Format([]models.Transaction{parsed, reread, unknown})
+		require.NoError(t, err)
+		assert.Equal(t, "SEPA Credit Transfer", rows[0][len(header)-1])
+		assert.Equal(t, "SEPA Core Direct Debit", rows[1][len(header)-1])
+		assert.Equal(t, "", rows[2][len(header)-1])
+	}
+}
+
+func TestFormatters_CardLast4Option(t *testing.T) {
+	card := createTestTransaction()
+	card.CardLast4 = "1234"
+
+	for _, f := range []OutputFormatter{NewStandardFormatter(), NewIComptaFormatter(), NewJumpsoftFormatter()} {
+		configured := ApplyOptions(f, Options{CardLast4: true})
+		header := configured.Header()
+		assert.Equal(t, "CardLast4", header[len(header)-1])
+
+		rows, err := configured.Format([]models.Transaction{card, createTestTransaction()})
+		require.NoError(t, err)
+		assert.Equal(t, "1234", rows[0][len(header)-1])
+		assert.Equal(t, "", rows[1][len(header)-1])
+	}
+}
+
+func TestFormatters_MCCOption(t *testing.T) {
+	card := createTestTransaction()
+	card.MCC = "5411"
+
+	for _, f := range []OutputFormatter{NewStandardFormatter(), NewIComptaFormatter(), NewJumpsoftFormatter()} {
+		configured := ApplyOptions(f, Options{MCC: true})
+		header := configured.Header()
+		assert.Equal(t, "MCC", header[len(header)-1])
+
+		rows, err := configured.Format([]models.Transaction{card, createTestTransaction()})
+		require.NoError(t, err)
+		assert.Equal(t, "5411", rows[0][len(header)-1])
+		assert.Equal(t, "", rows[1][len(header)-1])
+	}
+}
+
+func TestFormatters_FXDifferenceOption(t *testing.T) {
+	converted := createTestTransaction()
+	converted.Amount = decimal.RequireFromString("42.80")
+	converted.Currency = "CHF"
+	converted.OriginalAmount = decimal.RequireFromString("40.00")
+	converted.OriginalCurrency = "EUR"
+	converted.ExchangeRate = decimal.RequireFromString("1.0712")
+
+	for _, f := range []OutputFormatter{NewStandardFormatter(), NewIComptaFormatter(), NewJumpsoftFormatter()} {
+		configured := ApplyOptions(f, Options{FXDifference: true})
+		header := configured.Header()
+		assert.Equal(t, "FXDifference", header[len(header)-1])
+
+		rows, err := configured.Format([]models.Transaction{converted, createTestTransaction()})
+		require.NoError(t, err)
+		assert.Equal(t, "-0.05", rows[0][len(header)-1])
+		assert.Equal(t, "", rows[1][len(header)-1])
+	}
+}
+
+func TestFormatters_AnonymizeOption(t *testing.T) {
+	tx := createTestTransaction()
+	tx.PartyName = "Jean Dupont"
+	tx.Name = "Jean Dupont"
+	tx.PartyIBAN = "CH93 0076 2011 6238 5295 7"
+	tx.IBAN = "CH5604835012345678009"
+	tx.Amount = decimal.RequireFromString("125.40")
+	tx.Category = "Loyer"
+	tx.Description = "Loyer Dupont mars"
+	tx.RemittanceInfo = "Appartement rue du Lac 12"
+	tx.Reference = "DUPONT-2025-03"
+	tx.EntryReference = "E-DUPONT-1"
+
+	other := tx
+	other.PartyName = " jean dupont "
+	other.PartyIBAN = "CH9300762011623852957"
+
+	stranger := tx
+	stranger.PartyName = "Marie Martin"
+
+	configured := ApplyOptions(NewStandardFormatter(), Options{Anonymize: true, AnonymizeKey: []byte("secret")})
+	rows, err := configured.Format([]models.Transaction{tx, other, stranger})
+	require.NoError(t, err)
+	header := configured.Header()
+	column := func(row []string, name string) string {
+		return row[slices.Index(header, name)]
+	}
+
+	for _, row := range rows {
+		assert.NotContains(t, strings.ToUpper(strings.Join(row, ",")), "DUPONT")
+		assert.NotContains(t, strings.Join(row, ","), "CH93")
+		assert.NotContains(t, strings.Join(row, ","), "rue du Lac")
+		assert.Equal(t, "125.40", column(row, "Amount"))
+		assert.Equal(t, "Loyer", column(row, "Category"))
+	}
+	assert.Regexp(t, `^Party-[0-9a-f]{8}$`, column(rows[0], "PartyName"))
+	assert.Regexp(t, `^IBAN-[0-9a-f]{8}$`, column(rows[0], "PartyIBAN"))
+
+	// The same party gets the same pseudonym, whatever its case or spacing
+	assert.Equal(t, column(rows[0], "PartyName"), column(rows[1], "PartyName"))
+	assert.Equal(t, column(rows[0], "PartyIBAN"), column(rows[1], "PartyIBAN"))
+	assert.NotEqual(t, column(rows[0], "PartyName"), column(rows[2], "PartyName"))
+	assert.Regexp(t, `^Text-[0-9a-f]{8}$`, column(rows[0], "Description"))
+	assert.Regexp(t, `^Text-[0-9a-f]{8}$`, column(rows[0], "RemittanceInfo"))
+	assert.Regexp(t, `^Ref-[0-9a-f]{8}$`, column(rows[0], "Reference"))
+	assert.Regexp(t, `^Ref-[0-9a-f]{8}$`, column(rows[0], "EntryReference"))
+
+	// Pseudonyms depend on the key: the same key gives the same tokens, a
+	// different or random key other ones
+	again, err := ApplyOptions(NewStandardFormatter(), Options{Anonymize: true, AnonymizeKey: []byte("secret")}).
+		Format([]models.Transaction{tx})
+	require.NoError(t, err)
+	assert.Equal(t, rows[0], again[0])
+	for _, opts := range []Options{{Anonymize: true, AnonymizeKey: []byte("other")}, {Anonymize: true}} {
+		keyed, err := ApplyOptions(NewStandardFormatter(), opts).Format([]models.Transaction{tx})
+		require.NoError(t, err)
+		assert.NotEqual(t, column(rows[0], "PartyName"), column(keyed[0], "PartyName"))
+	}
+
+	assert.Equal(t, "Jean Dupont", tx.PartyName, "input transactions are not modified")
+}
+
+func TestFormatters_DescriptionTemplateOption(t *testing.T) {
+	tx := createTestTransaction()
+	tx.Description = "Parser description"
+	tx.PartyName = "Migros"
+	tx.RemittanceInfo = "Groceries"
+	tx.Reference = ""
+	template, err := models.ParseDescriptionTemplate("{PartyName} - {RemittanceInfo} ({Reference})")
+	require.NoError(t, err)
+
+	for _, f := range []OutputFormatter{NewStandardFormatter(), NewIComptaFormatter(), NewJumpsoftFormatter()} {
+		configured := ApplyOptions(f, Options{DescriptionTemplate: template})
+		assert.Equal(t, f.Header(), configured.Header())
+		assert.Equal(t, f.Delimiter(), configured.Delimiter())
+
+		rows, err := configured.Format([]models.Transaction{tx})
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.Contains(t, rows[0], "Migros - Groceries")
+		assert.NotContains(t, rows[0], "Parser description")
+	}
+	assert.Equal(t, "Parser description", tx.Description, "input transactions are not modified")
+}
+
+func TestFormatters_SortOption(t *testing.T) {
+	small := createTestTransaction()
+	small.Amount = decimal.RequireFromString("5.00")
+	small.CardLast4 = "1111"
+	large := createTestTransaction()
+	large.Amount = decimal.RequireFromString("500.00")
+	large.CardLast4 = "2222"
+	// Debits sort by signed amount: the larger payment comes first ascending
+	order, err := models.ParseSortOrder("amount")
+	require.NoError(t, err)
+
+	for _, f := range []OutputFormatter{NewStandardFormatter(), NewIComptaFormatter(), NewJumpsoftFormatter()} {
+		// Extra columns stay aligned with their rows
+		configured := ApplyOptions(f, Options{Sort: order, CardLast4: true})
+		rows, err := configured.Format([]models.Transaction{small, large})
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		last := len(configured.Header()) - 1
+		assert.Equal(t, "2222", rows[0][last])
+		assert.Equal(t, "1111", rows[1][last])
+	}
+}
+
+func TestCategoryColumns(t *testing.T) {
+	assert.Equal(t, map[int]bool{4: true}, CategoryColumns(NewJumpsoftFormatter()))
+
+	f := ApplyOptions(NewIComptaFormatter(), Options{CategorySource: true, Tags: true})
+	assert.Equal(t, map[int]bool{5: true, 10: true, 11: true}, CategoryColumns(f))
+
+	profile := models.ExportProfile{Name: "bank", Columns: []string{"Date", "Amount"}}
+	assert.Empty(t, CategoryColumns(NewProfileFormatter(profile)))
+}
+
+func TestDescribeSchema(t *testing.T) {
+	t.Run("standard", func(t *testing.T) {
+		opts := Options{SignedAmount: true}
+		f := ApplyOptions(NewStandardFormatter(), opts)
+		schema := DescribeSchema(f, opts)
+
+		assert.Equal(t, ",", schema.Delimiter)
+		require.Len(t, schema.Columns, len(f.Header()))
+		for i, name := range f.Header() {
+			assert.Equal(t, name, schema.Columns[i].Name)
+		}
+		assert.Contains(t, schema.Columns, Column{Name: "Date", Type: models.ColumnTypeDate})
+		assert.Contains(t, schema.Columns, Column{Name: "Amount", Type: models.ColumnTypeDecimal})
+		assert.Contains(t, schema.Columns, Column{Name: "NumberOfShares", Type: models.ColumnTypeInteger})
+		assert.Contains(t, schema.Columns, Column{Name: "PartyIBAN", Type: models.ColumnTypeString})
+		assert.NotContains(t, f.Header(), "CreditDebit")
+	})
+
+	t.Run("appended columns", func(t *testing.T) {
+		opts := Options{IncludeTime: true, SequenceNumber: true, FXDifference: true}
+		schema := DescribeSchema(ApplyOptions(NewIComptaFormatter(), opts), opts)
+
+		assert.Equal(t, ";", schema.Delimiter)
+		assert.Equal(t, Column{Name: "Date", Type: models.ColumnTypeDateTime}, schema.Columns[0])
+		assert.Contains(t, schema.Columns, Column{Name: "SplitAmount", Type: models.ColumnTypeDecimal})
+		n := len(schema.Columns)
+		assert.Equal(t, []Column{
+			{Name: "SequenceNumber", Type: models.ColumnTypeInteger},
+			{Name: "FXDifference", Type: models.ColumnTypeDecimal},
+		}, schema.Columns[n-2:])
+	})
+
+	t.Run("profile", func(t *testing.T) {
+		profile := models.ExportProfile{Name: "erp", Columns: []string{"Date", "Amount", "Category"}, Delimiter: ";"}
+		opts := Options{Profile: &profile}
+		schema := DescribeSchema(ApplyOptions(NewStandardFormatter(), opts), opts)
+
+		assert.Equal(t, ";", schema.Delimiter)
+		assert.Equal(t, []Column{
+			{Name: "Date", Type: models.ColumnTypeDate},
+			{Name: "Amount", Type: models.ColumnTypeDecimal},
+			{Name: "Category", Type: models.ColumnTypeString},
+		}, schema.Columns)
+	})
+}
@@ -7,7 +7,9 @@ import (
 // iComptaFormatter produces 10-column semicolon-delimited output compatible with
 // iCompta's CSV import plugins. It projects Transaction fields to match the schema
 // expected by iCompta (see .planning/reference/icompta-schema.sql).
-type iComptaFormatter struct{}
+type iComptaFormatter struct {
+	opts Options
+}
 
 // NewIComptaFormatter creates a new iComptaFormatter instance.
 func NewIComptaFormatter() *iComptaFormatter {
@@ -41,7 +43,7 @@ func (f *iComptaFormatter) Format(transactions []models.Transaction) ([][]string
 		// Date: dd.MM.yyyy format
 		dateStr := ""
 		if !tx.Date.IsZero() {
-			dateStr = tx.Date.Format("02.01.2006")
+			dateStr = tx.Date.Format(f.opts.dateLayout("02.01.2006"))
 		}
 
 		// Name: prefer tx.Name, fall back to PartyName
@@ -50,8 +52,8 @@ func (f *iComptaFormatter) Format(transactions []models.Transaction) ([][]string
 			name = tx.PartyName
 		}
 
-		// Amount: always 2 decimal places
-		amount := tx.Amount.StringFixed(2)
+		// Amount: with the precision of the currency (2 decimal places for most)
+		amount := models.FormatAmount(tx.Amount, tx.Currency)
 
 		// Description
 		description := tx.Description
@@ -67,10 +69,10 @@ func (f *iComptaFormatter) Format(transactions []models.Transaction) ([][]string
 		}
 
 		// SplitAmount: same as Amount for v1 (no split support yet)
-		splitAmount := tx.Amount.StringFixed(2)
+		splitAmount := models.FormatAmount(tx.Amount, tx.Currency)
 
 		// SplitAmountExclTax
-		splitAmountExclTax := tx.AmountExclTax.StringFixed(2)
+		splitAmountExclTax := models.FormatAmount(tx.AmountExclTax, tx.Currency)
 
 		// SplitTaxRate
 		splitTaxRate := tx.TaxRate.StringFixed(2)
@@ -96,6 +98,11 @@ func (f *iComptaFormatter) Format(transactions []models.Transaction) ([][]string
 	return rows, nil
 }
 
+// WithOptions returns a copy of the formatter configured with opts.
+func (f *iComptaFormatter) WithOptions(opts Options) OutputFormatter {
+	return &iComptaFormatter{opts: opts}
+}
+
 // Delimiter returns semicolon as the delimiter for iCompta format.
 func (f *iComptaFormatter) Delimiter() rune {
 	return ';'
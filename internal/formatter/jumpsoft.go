@@ -6,7 +6,9 @@ import (
 
 // JumpsoftFormatter produces 7-column comma-delimited output compatible with
 // Jumpsoft Money CSV import. Columns: Date,Description,Amount,Currency,Category,Type,Notes
-type JumpsoftFormatter struct{}
+type JumpsoftFormatter struct {
+	opts Options
+}
 
 // NewJumpsoftFormatter creates a new JumpsoftFormatter instance.
 func NewJumpsoftFormatter() *JumpsoftFormatter {
@@ -30,7 +32,7 @@ func (f *JumpsoftFormatter) Format(transactions []models.Transaction) ([][]strin
 		// Date: YYYY-MM-DD (ISO 8601)
 		dateStr := ""
 		if !tx.Date.IsZero() {
-			dateStr = tx.Date.Format("2006-01-02")
+			dateStr = tx.Date.Format(f.opts.dateLayout("2006-01-02"))
 		}
 
 		// Description: prefer tx.Description, fall back to tx.Name
@@ -46,7 +48,7 @@ func (f *JumpsoftFormatter) Format(transactions []models.Transaction) ([][]strin
 		if tx.DebitFlag && amount.IsPositive() {
 			amount = amount.Neg()
 		}
-		amountStr := amount.StringFixed(2)
+		amountStr := models.FormatAmount(amount, tx.Currency)
 
 		// Currency
 		currency := tx.Currency
@@ -72,6 +74,11 @@ func (f *JumpsoftFormatter) Format(transactions []models.Transaction) ([][]strin
 	return rows, nil
 }
 
+// WithOptions returns a copy of the formatter configured with opts.
+func (f *JumpsoftFormatter) WithOptions(opts Options) OutputFormatter {
+	return &JumpsoftFormatter{opts: opts}
+}
+
 // Delimiter returns comma as the delimiter for Jumpsoft Money format.
 func (f *JumpsoftFormatter) Delimiter() rune {
 	return ','
@@ -0,0 +1,144 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"fjacquet/camt-csv/internal/models"
+)
+
+// DefaultProfileName is the built-in profile matching the standard format.
+const DefaultProfileName = "default"
+
+// DefaultProfile returns the built-in profile: every standard column with
+// unsigned amounts, like the standard format.
+func DefaultProfile() models.ExportProfile {
+	return models.ExportProfile{
+		Name:    DefaultProfileName,
+		Columns: append([]string(nil), models.StandardCSVHeader...),
+	}
+}
+
+// FindProfile returns the profile called name from profiles. The default
+// profile is always available unless profiles redefines it.
+func FindProfile(profiles []models.ExportProfile, name string) (models.ExportProfile, error) {
+	for _, p := range profiles {
+		if p.Name == name {
+			return p, ValidateProfile(p)
+		}
+	}
+	if name == DefaultProfileName {
+		return DefaultProfile(), nil
+	}
+	return models.ExportProfile{}, fmt.Errorf("export profile not found: %s", name)
+}
+
+// ValidateProfile checks that p names at least one known column, has no
+// duplicate columns, and uses a single-character delimiter.
+func ValidateProfile(p models.ExportProfile) error {
+	if len(p.Columns) == 0 {
+		return fmt.Errorf("export profile %q has no columns", p.Name)
+	}
+	seen := make(map[string]bool, len(p.Columns))
+	for _, column := range p.Columns {
+		if !models.IsStandardCSVColumn(column) {
+			return fmt.Errorf("export profile %q: unknown column %q", p.Name, column)
+		}
+		if seen[column] {
+			return fmt.Errorf("export profile %q: duplicate column %q", p.Name, column)
+		}
+		seen[column] = true
+	}
+	if p.Delimiter != "" && utf8.RuneCountInString(p.Delimiter) != 1 {
+		return fmt.Errorf("export profile %q: delimiter must be a single character, got %q", p.Name, p.Delimiter)
+	}
+	return nil
+}
+
+// ColumnsProfileName names the profile built from --columns.
+const ColumnsProfileName = "columns"
+
+// ParseColumns splits a comma-separated list of standard column names, as
+// given to --columns, and checks each name against the standard header.
+func ParseColumns(list string) ([]string, error) {
+	var columns []string
+	for _, column := range strings.Split(list, ",") {
+		column = strings.TrimSpace(column)
+		if column == "" {
+			continue
+		}
+		if !models.IsStandardCSVColumn(column) {
+			return nil, fmt.Errorf("unknown column %q: valid columns are %s",
+				column, strings.Join(models.StandardCSVHeader, ", "))
+		}
+		columns = append(columns, column)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns given")
+	}
+	return columns, nil
+}
+
+// ColumnsProfile returns a profile writing columns in the given order. It keeps
+// the sign convention and delimiter of base, or of the default profile when
+// base is nil.
+func ColumnsProfile(columns []string, base *models.ExportProfile) (models.ExportProfile, error) {
+	profile := DefaultProfile()
+	if base != nil {
+		profile = *base
+	}
+	profile.Name = ColumnsProfileName
+	profile.Columns = columns
+	return profile, ValidateProfile(profile)
+}
+
+// profileFormatter writes the columns selected by an export profile.
+type profileFormatter struct {
+	profile models.ExportProfile
+	opts    Options
+}
+
+// NewProfileFormatter creates a formatter for profile, which must be valid.
+func NewProfileFormatter(profile models.ExportProfile) OutputFormatter {
+	return &profileFormatter{profile: profile}
+}
+
+// Header returns the profile's columns.
+func (f *profileFormatter) Header() []string {
+	return append([]string(nil), f.profile.Columns...)
+}
+
+// Format converts transactions to rows holding the profile's columns.
+func (f *profileFormatter) Format(transactions []models.Transaction) ([][]string, error) {
+	rows := make([][]string, 0, len(transactions))
+
+	for _, tx := range transactions {
+		row, err := tx.MarshalCSVWithOptions(models.CSVOptions{
+			IncludeTime:  f.opts.IncludeTime,
+			SignedAmount: f.profile.SignedAmount || f.opts.SignedAmount,
+			Columns:      f.profile.Columns,
+			Locale:       f.opts.Locale,
+		})
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// WithOptions returns a copy of the formatter configured with opts.
+func (f *profileFormatter) WithOptions(opts Options) OutputFormatter {
+	return &profileFormatter{profile: f.profile, opts: opts}
+}
+
+// Delimiter returns the profile's delimiter, or comma when it sets none.
+func (f *profileFormatter) Delimiter() rune {
+	if f.profile.Delimiter == "" {
+		return ','
+	}
+	r, _ := utf8.DecodeRuneInString(f.profile.Delimiter)
+	return r
+}
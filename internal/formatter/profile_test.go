@@ -0,0 +1,129 @@
+package formatter
+
+import (
+	"testing"
+
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindProfile(t *testing.T) {
+	erp := models.ExportProfile{Name: "erp", Columns: []string{"Date", "Amount"}, SignedAmount: true}
+
+	p, err := FindProfile([]models.ExportProfile{erp}, "erp")
+	require.NoError(t, err)
+	assert.Equal(t, erp, p)
+
+	// The default profile is built in
+	p, err = FindProfile(nil, DefaultProfileName)
+	require.NoError(t, err)
+	assert.Equal(t, models.StandardCSVHeader, p.Columns)
+
+	_, err = FindProfile([]models.ExportProfile{erp}, "missing")
+	assert.Error(t, err)
+
+	// Invalid profiles are reported when selected
+	_, err = FindProfile([]models.ExportProfile{{Name: "bad", Columns: []string{"Debit"}}}, "bad")
+	assert.Error(t, err)
+}
+
+func TestValidateProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile models.ExportProfile
+		wantErr bool
+	}{
+		{"valid", models.ExportProfile{Name: "p", Columns: []string{"Date", "Amount"}, Delimiter: ";"}, false},
+		{"no columns", models.ExportProfile{Name: "p"}, true},
+		{"unknown column", models.ExportProfile{Name: "p", Columns: []string{"Credit"}}, true},
+		{"duplicate column", models.ExportProfile{Name: "p", Columns: []string{"Date", "Date"}}, true},
+		{"long delimiter", models.ExportProfile{Name: "p", Columns: []string{"Date"}, Delimiter: ";;"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateProfile(tt.profile)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestProfileFormatter(t *testing.T) {
+	profile := models.ExportProfile{
+		Name:         "erp",
+		Columns:      []string{"PartyName", "Amount", "Currency"},
+		SignedAmount: true,
+		Delimiter:    ";",
+	}
+	f := ApplyOptions(NewStandardFormatter(), Options{Profile: &profile, CategorySource: true})
+
+	assert.Equal(t, []string{"PartyName", "Amount", "Currency", "CategorySource"}, f.Header())
+	assert.Equal(t, ';', f.Delimiter())
+
+	debit := createTestTransaction()
+	debit.CreditDebit = models.TransactionTypeDebit
+	debit.DebitFlag = true
+
+	rows, err := f.Format([]models.Transaction{debit})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, debit.PartyName, rows[0][0])
+	assert.Equal(t, "-"+debit.Amount.Abs().StringFixed(2), rows[0][1])
+	assert.Equal(t, debit.Currency, rows[0][2])
+}
+
+func TestProfileFormatter_DefaultMatchesStandard(t *testing.T) {
+	profile := DefaultProfile()
+	f := ApplyOptions(NewStandardFormatter(), Options{Profile: &profile})
+	standard := NewStandardFormatter()
+
+	assert.Equal(t, standard.Header(), f.Header())
+
+	tx := createTestTransaction()
+	want, err := standard.Format([]models.Transaction{tx})
+	require.NoError(t, err)
+	got, err := f.Format([]models.Transaction{tx})
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestParseColumns(t *testing.T) {
+	columns, err := ParseColumns(" Date , Amount,,Currency ")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Date", "Amount", "Currency"}, columns)
+
+	_, err = ParseColumns("Date,amount")
+	assert.ErrorContains(t, err, `unknown column "amount"`)
+
+	_, err = ParseColumns(" , ")
+	assert.Error(t, err)
+}
+
+func TestColumnsProfile(t *testing.T) {
+	p, err := ColumnsProfile([]string{"Amount", "Date"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, ColumnsProfileName, p.Name)
+	assert.Equal(t, []string{"Amount", "Date"}, p.Columns)
+	assert.False(t, p.SignedAmount)
+
+	// Columns replace the base profile's columns but keep its sign convention and delimiter
+	erp := models.ExportProfile{Name: "erp", Columns: []string{"Date"}, SignedAmount: true, Delimiter: ";"}
+	p, err = ColumnsProfile([]string{"Name", "Amount"}, &erp)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Name", "Amount"}, p.Columns)
+	assert.True(t, p.SignedAmount)
+	assert.Equal(t, ";", p.Delimiter)
+	assert.Equal(t, []string{"Date"}, erp.Columns)
+
+	_, err = ColumnsProfile([]string{"Date", "Date"}, nil)
+	assert.Error(t, err)
+
+	f := NewProfileFormatter(p)
+	assert.Equal(t, []string{"Name", "Amount"}, f.Header())
+}
@@ -0,0 +1,65 @@
+package formatter
+
+import "fjacquet/camt-csv/internal/models"
+
+// Column describes one column of the CSV output: its header name and the
+// type of its values (see the models.ColumnType constants).
+type Column struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Schema describes the CSV output of a formatter.
+type Schema struct {
+	Delimiter string   `json:"delimiter"`
+	Columns   []Column `json:"columns"`
+}
+
+// columnTypes gives the type of the columns that are not standard CSV
+// columns: those of the iCompta and Jumpsoft layouts and the appended ones.
+// Columns missing from it and from the standard layout are strings.
+var columnTypes = map[string]string{
+	"SplitAmount":        models.ColumnTypeDecimal,
+	"SplitAmountExclTax": models.ColumnTypeDecimal,
+	"SplitTaxRate":       models.ColumnTypeDecimal,
+	"SequenceNumber":     models.ColumnTypeInteger,
+	"FXDifference":       models.ColumnTypeDecimal,
+	"BaseAmount":         models.ColumnTypeDecimal,
+}
+
+// categoryColumnNames are the columns that hold the categorization of a
+// transaction (its category, the method that set it and its tags) rather than
+// data from the statement.
+var categoryColumnNames = map[string]bool{
+	"Category":       true,
+	"CategorySource": true,
+	"Tags":           true,
+}
+
+// CategoryColumns returns the indexes of the columns of f that hold the
+// categorization of a transaction, looked up by name in f.Header().
+func CategoryColumns(f OutputFormatter) map[int]bool {
+	columns := make(map[int]bool)
+	for i, name := range f.Header() {
+		if categoryColumnNames[name] {
+			columns[i] = true
+		}
+	}
+	return columns
+}
+
+// DescribeSchema returns the schema of the rows f writes, f having been
+// configured with opts by ApplyOptions. The columns are those of f.Header(),
+// in order, so the schema follows any change to the layouts.
+func DescribeSchema(f OutputFormatter, opts Options) Schema {
+	header := f.Header()
+	columns := make([]Column, 0, len(header))
+	for _, name := range header {
+		columnType, ok := columnTypes[name]
+		if !ok {
+			columnType = models.StandardCSVColumnType(name, opts.IncludeTime)
+		}
+		columns = append(columns, Column{Name: name, Type: columnType})
+	}
+	return Schema{Delimiter: string(f.Delimiter()), Columns: columns}
+}
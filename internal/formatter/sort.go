@@ -0,0 +1,36 @@
+package formatter
+
+import (
+	"fjacquet/camt-csv/internal/models"
+)
+
+// sortFormatter wraps another formatter and sorts the transactions before
+// formatting them.
+type sortFormatter struct {
+	inner OutputFormatter
+	order models.SortOrder
+}
+
+// Header returns the wrapped formatter's columns.
+func (f *sortFormatter) Header() []string {
+	return f.inner.Header()
+}
+
+// Format formats a sorted copy of transactions with the wrapped formatter.
+// The input slice is not reordered.
+func (f *sortFormatter) Format(transactions []models.Transaction) ([][]string, error) {
+	return f.inner.Format(sortedCopy(transactions, f.order))
+}
+
+// sortedCopy returns a copy of transactions ordered by order.
+func sortedCopy(transactions []models.Transaction, order models.SortOrder) []models.Transaction {
+	sorted := make([]models.Transaction, len(transactions))
+	copy(sorted, transactions)
+	order.Sort(sorted)
+	return sorted
+}
+
+// Delimiter returns the wrapped formatter's delimiter.
+func (f *sortFormatter) Delimiter() rune {
+	return f.inner.Delimiter()
+}
@@ -7,22 +7,29 @@ import (
 // StandardFormatter produces the standard 29-column CSV format.
 // This formatter maintains compatibility with existing camt-csv output,
 // using comma delimiters and delegating to Transaction.MarshalCSV().
-type StandardFormatter struct{}
+type StandardFormatter struct {
+	opts Options
+}
 
 // NewStandardFormatter creates a new StandardFormatter instance.
 func NewStandardFormatter() *StandardFormatter {
 	return &StandardFormatter{}
 }
 
-// Header returns the 29 standard column names.
+// Header returns the 29 standard column names, or 28 when SignedAmount drops CreditDebit.
 func (f *StandardFormatter) Header() []string {
-	return []string{
-		"Status", "Date", "ValueDate", "Name", "PartyName", "PartyIBAN",
-		"Description", "RemittanceInfo", "Amount", "CreditDebit", "Currency",
-		"Product", "AmountExclTax", "TaxRate", "InvestmentType", "Number", "Category",
-		"Type", "Fund", "NumberOfShares", "Fees", "IBAN", "EntryReference", "Reference",
-		"AccountServicer", "BankTxCode", "OriginalCurrency", "OriginalAmount", "ExchangeRate",
+	header := append([]string(nil), models.StandardCSVHeader...)
+
+	if f.opts.SignedAmount {
+		filtered := make([]string, 0, len(header)-1)
+		for _, column := range header {
+			if column != "CreditDebit" {
+				filtered = append(filtered, column)
+			}
+		}
+		return filtered
 	}
+	return header
 }
 
 // Format converts transactions to CSV rows using the existing MarshalCSV method.
@@ -31,7 +38,11 @@ func (f *StandardFormatter) Format(transactions []models.Transaction) ([][]strin
 	rows := make([][]string, 0, len(transactions))
 
 	for _, tx := range transactions {
-		row, err := tx.MarshalCSV()
+		row, err := tx.MarshalCSVWithOptions(models.CSVOptions{
+			IncludeTime:  f.opts.IncludeTime,
+			SignedAmount: f.opts.SignedAmount,
+			Locale:       f.opts.Locale,
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -41,6 +52,11 @@ func (f *StandardFormatter) Format(transactions []models.Transaction) ([][]strin
 	return rows, nil
 }
 
+// WithOptions returns a copy of the formatter configured with opts.
+func (f *StandardFormatter) WithOptions(opts Options) OutputFormatter {
+	return &StandardFormatter{opts: opts}
+}
+
 // Delimiter returns comma as the delimiter for standard CSV format.
 func (f *StandardFormatter) Delimiter() rune {
 	return ','
@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAuditLogger_AppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"msg":"earlier run"}`+"\n"), 0600))
+
+	logger, closer, err := NewAuditLogger(path)
+	require.NoError(t, err)
+	logger.Debug("not written")
+	logger.Info("categorized", Field{Key: "party", Value: "Migros"}, Field{Key: "category", Value: "Food"})
+	require.NoError(t, closer.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2, "appended after the earlier run, debug left out")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, "categorized", entry["msg"])
+	assert.Equal(t, "Migros", entry["party"])
+	assert.Equal(t, "Food", entry["category"])
+}
+
+func TestNewAuditLogger_InvalidPath(t *testing.T) {
+	_, _, err := NewAuditLogger(filepath.Join(t.TempDir(), "missing", "audit.jsonl"))
+	assert.Error(t, err)
+}
@@ -1,6 +1,8 @@
 package logging
 
 import (
+	"io"
+
 	"github.com/sirupsen/logrus"
 )
 
@@ -101,6 +103,12 @@ func (l *LogrusAdapter) WithFields(fields ...Field) Logger {
 	}
 }
 
+// SetOutput sends the log lines to w. It applies to every logger derived
+// from the same logrus.Logger, including those returned by WithField.
+func (l *LogrusAdapter) SetOutput(w io.Writer) {
+	l.logger.SetOutput(w)
+}
+
 // convertFields converts our Field slice to logrus.Fields map
 func convertFields(fields []Field) logrus.Fields {
 	logrusFields := make(logrus.Fields, len(fields))
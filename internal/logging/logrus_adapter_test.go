@@ -179,6 +179,17 @@ func TestLogrusAdapter_WithError(t *testing.T) {
 	assert.Contains(t, output, "test error")
 }
 
+func TestLogrusAdapter_SetOutput(t *testing.T) {
+	logger := NewLogrusAdapter("info", "text").(*LogrusAdapter)
+	derived := logger.WithField("file", "a.xml")
+
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	derived.Info("redirected")
+
+	assert.Contains(t, buf.String(), "redirected")
+}
+
 func TestLogrusAdapter_WithField(t *testing.T) {
 	logrusLogger := logrus.New()
 	var buf bytes.Buffer
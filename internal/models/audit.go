@@ -0,0 +1,18 @@
+package models
+
+// CategorizationAuditor is implemented by categorizers that record each
+// categorization decision, as the --audit-log flag does. The Apply functions
+// categorize without calling Categorize, so they report the categories they
+// set through it.
+type CategorizationAuditor interface {
+	// AuditCategorization records the category and category source of tx.
+	AuditCategorization(tx Transaction)
+}
+
+// auditCategorization reports the category of tx to categorizer when it
+// implements CategorizationAuditor.
+func auditCategorization(tx *Transaction, categorizer TransactionCategorizer) {
+	if auditor, ok := categorizer.(CategorizationAuditor); ok {
+		auditor.AuditCategorization(*tx)
+	}
+}
@@ -0,0 +1,111 @@
+package models
+
+import "strings"
+
+// bankTxCodeDescriptions gives the meaning of common ISO 20022 bank
+// transaction codes (the external Domain/Family/SubFamily code set), keyed by
+// the code as GetBankTxCode returns it. Families and domains are listed too,
+// so that a code whose sub-family is missing is still described.
+var bankTxCodeDescriptions = map[string]string{
+	// Domains
+	"PMNT": "Payments",
+	"ACMT": "Account Management",
+	"CAMT": "Cash Management",
+	"SECU": "Securities",
+	"LDAS": "Loans, Deposits & Syndications",
+	"FORX": "Foreign Exchange",
+	"DERV": "Derivatives",
+	"TRAD": "Trade Services",
+
+	// Payment families
+	"PMNT/RCDT": "Received Credit Transfer",
+	"PMNT/ICDT": "Issued Credit Transfer",
+	"PMNT/RDDT": "Received Direct Debit",
+	"PMNT/IDDT": "Issued Direct Debit",
+	"PMNT/CCRD": "Customer Card Transaction",
+	"PMNT/MCRD": "Merchant Card Transaction",
+	"PMNT/CNTR": "Counter Transaction",
+	"PMNT/RCHQ": "Received Cheque",
+	"PMNT/ICHQ": "Issued Cheque",
+
+	// Credit transfers
+	"PMNT/RCDT/ESCT": "SEPA Credit Transfer",
+	"PMNT/ICDT/ESCT": "SEPA Credit Transfer",
+	"PMNT/RCDT/DMCT": "Domestic Credit Transfer",
+	"PMNT/ICDT/DMCT": "Domestic Credit Transfer",
+	"PMNT/RCDT/XBCT": "Cross-Border Credit Transfer",
+	"PMNT/ICDT/XBCT": "Cross-Border Credit Transfer",
+	"PMNT/RCDT/SDVA": "Same Day Value Credit Transfer",
+	"PMNT/ICDT/SDVA": "Same Day Value Credit Transfer",
+	"PMNT/RCDT/BOOK": "Internal Book Transfer",
+	"PMNT/ICDT/BOOK": "Internal Book Transfer",
+	"PMNT/RCDT/AUTT": "Automatic Transfer",
+	"PMNT/ICDT/AUTT": "Automatic Transfer",
+	"PMNT/RCDT/STDO": "Standing Order",
+	"PMNT/ICDT/STDO": "Standing Order",
+	"PMNT/RCDT/SALA": "Payroll/Salary Payment",
+	"PMNT/ICDT/SALA": "Payroll/Salary Payment",
+	"PMNT/RCDT/RRTN": "Reversal Due To Payment Return",
+	"PMNT/ICDT/RRTN": "Reversal Due To Payment Return",
+
+	// Direct debits
+	"PMNT/IDDT/ESDD": "SEPA Core Direct Debit",
+	"PMNT/RDDT/ESDD": "SEPA Core Direct Debit",
+	"PMNT/IDDT/BBDD": "SEPA B2B Direct Debit",
+	"PMNT/RDDT/BBDD": "SEPA B2B Direct Debit",
+	"PMNT/IDDT/PMDD": "Direct Debit",
+	"PMNT/RDDT/PMDD": "Direct Debit",
+	"PMNT/IDDT/UPDD": "Reversal Due To Return/Unpaid Direct Debit",
+	"PMNT/RDDT/UPDD": "Reversal Due To Return/Unpaid Direct Debit",
+
+	// Cards and cash
+	"PMNT/CCRD/POSD": "Point-of-Sale Payment - Debit Card",
+	"PMNT/CCRD/POSC": "Credit Card Payment",
+	"PMNT/CCRD/SMRT": "Smart-Card Payment",
+	"PMNT/CCRD/CWDL": "Cash Withdrawal",
+	"PMNT/CCRD/CDPT": "Cash Deposit",
+	"PMNT/MCRD/POSP": "Point-of-Sale Payment",
+	"PMNT/CNTR/CWDL": "Cash Withdrawal",
+	"PMNT/CNTR/CDPT": "Cash Deposit",
+	"PMNT/ICHQ/CCHQ": "Cheque",
+	"PMNT/RCHQ/CCHQ": "Cheque",
+
+	// Account management
+	"ACMT/MDOP":      "Miscellaneous Debit Operation",
+	"ACMT/MCOP":      "Miscellaneous Credit Operation",
+	"ACMT/MDOP/CHRG": "Charges",
+	"ACMT/MCOP/CHRG": "Charges",
+	"ACMT/MDOP/COMM": "Commission",
+	"ACMT/MCOP/COMM": "Commission",
+	"ACMT/MDOP/INTR": "Interest",
+	"ACMT/MCOP/INTR": "Interest",
+	"ACMT/MDOP/TAXE": "Taxes",
+	"ACMT/MCOP/TAXE": "Taxes",
+	"ACMT/MDOP/ADJT": "Adjustment",
+	"ACMT/MCOP/ADJT": "Adjustment",
+
+	// Securities
+	"SECU/SETT/TRAD": "Securities Trade",
+	"SECU/CUST/DVCA": "Cash Dividend",
+	"SECU/CUST/INTR": "Interest Payment",
+	"SECU/CUST/REDM": "Final Maturity",
+}
+
+// BankTxCodeDescription returns the human-readable meaning of an ISO 20022
+// bank transaction code such as "PMNT/RCDT/ESCT" ("SEPA Credit Transfer").
+// A code with an unknown sub-family is described by its family; an unknown
+// code, or a proprietary one, gives "".
+func BankTxCodeDescription(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	for code != "" {
+		if description, ok := bankTxCodeDescriptions[code]; ok {
+			return description
+		}
+		i := strings.LastIndex(code, "/")
+		if i < 0 {
+			break
+		}
+		code = code[:i]
+	}
+	return ""
+}
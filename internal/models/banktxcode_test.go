@@ -0,0 +1,47 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBankTxCodeDescription(t *testing.T) {
+	tests := []struct {
+		code, description string
+	}{
+		{"PMNT/RCDT/ESCT", "SEPA Credit Transfer"},
+		{"PMNT/ICDT/DMCT", "Domestic Credit Transfer"},
+		{"PMNT/IDDT/ESDD", "SEPA Core Direct Debit"},
+		{"PMNT/CCRD/POSD", "Point-of-Sale Payment - Debit Card"},
+		{"PMNT/CCRD/CWDL", "Cash Withdrawal"},
+		{"ACMT/MDOP/CHRG", "Charges"},
+		{" pmnt/rcdt/esct ", "SEPA Credit Transfer"},
+		// Unknown sub-family: described by its family, then its domain
+		{"PMNT/RCDT/OTHR", "Received Credit Transfer"},
+		{"PMNT/XXXX/OTHR", "Payments"},
+		{"PMNT", "Payments"},
+		// Unknown and proprietary codes
+		{"XXXX/YYYY/ZZZZ", ""},
+		{"VIRT-IN", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.description, BankTxCodeDescription(tt.code), tt.code)
+	}
+}
+
+func TestBankTxCode_Code(t *testing.T) {
+	var code BankTxCode
+	assert.Equal(t, "", code.Code())
+
+	code.Prtry.Cd = "VIRT-IN"
+	assert.Equal(t, "VIRT-IN", code.Code())
+
+	code.Domn.Cd = "PMNT"
+	assert.Equal(t, "PMNT", code.Code(), "domain alone without a complete family")
+
+	code.Domn.Fmly.Cd = "RCDT"
+	code.Domn.Fmly.SubFmlyCd = "ESCT"
+	assert.Equal(t, "PMNT/RCDT/ESCT", code.Code())
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// BudgetsConfig represents the structure of the budgets YAML file: the monthly
+// budget of each category, by category name.
+type BudgetsConfig struct {
+	Budgets map[string]decimal.Decimal `yaml:"budgets"`
+}
+
+// BudgetLine compares the spending of one category with its budget.
+type BudgetLine struct {
+	Category string
+	// HasBudget is false for categories with spending but no budget.
+	HasBudget bool
+	// Budget is the monthly budget times the number of months covered.
+	Budget decimal.Decimal
+	// Actual is the net spending: debits minus credits such as refunds.
+	Actual decimal.Decimal
+	// Delta is Budget minus Actual, negative when the category is over budget.
+	Delta decimal.Decimal
+}
+
+// OverBudget reports whether the category has a budget and spent more than it.
+func (l BudgetLine) OverBudget() bool {
+	return l.HasBudget && l.Actual.GreaterThan(l.Budget)
+}
+
+// BudgetReport is the spending per category over the period covered by a set
+// of transactions.
+type BudgetReport struct {
+	From   time.Time
+	To     time.Time
+	Months int
+	Lines  []BudgetLine
+}
+
+// CompareBudgets sums the spending of transactions per category and compares
+// it with the monthly budgets, scaled to the number of calendar months from
+// the first to the last transaction. Lines are sorted by category name and
+// include budgeted categories without spending. Amounts are summed as they
+// are, whatever their currency.
+func CompareBudgets(transactions []Transaction, budgets map[string]decimal.Decimal) BudgetReport {
+	var report BudgetReport
+	actual := make(map[string]decimal.Decimal)
+	for _, tx := range transactions {
+		if report.From.IsZero() || tx.Date.Before(report.From) {
+			report.From = tx.Date
+		}
+		if tx.Date.After(report.To) {
+			report.To = tx.Date
+		}
+
+		category := tx.Category
+		if category == "" {
+			category = CategoryUncategorized
+		}
+		amount := tx.Amount.Abs()
+		if !tx.IsDebit() {
+			amount = amount.Neg()
+		}
+		actual[category] = actual[category].Add(amount)
+	}
+	if len(transactions) > 0 {
+		report.Months = (report.To.Year()-report.From.Year())*12 + int(report.To.Month()) - int(report.From.Month()) + 1
+	}
+
+	categories := make([]string, 0, len(actual)+len(budgets))
+	for category := range actual {
+		categories = append(categories, category)
+	}
+	for category := range budgets {
+		if _, ok := actual[category]; !ok {
+			categories = append(categories, category)
+		}
+	}
+	sort.Strings(categories)
+
+	months := decimal.NewFromInt(int64(report.Months))
+	for _, category := range categories {
+		line := BudgetLine{Category: category, Actual: actual[category]}
+		if monthly, ok := budgets[category]; ok {
+			line.HasBudget = true
+			line.Budget = monthly.Mul(months)
+			line.Delta = line.Budget.Sub(line.Actual)
+		}
+		report.Lines = append(report.Lines, line)
+	}
+	return report
+}
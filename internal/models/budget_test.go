@@ -0,0 +1,78 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func budgetTx(date string, amount string, creditDebit string, category string) Transaction {
+	d, _ := time.Parse("2006-01-02", date)
+	return Transaction{Date: d, Amount: decimal.RequireFromString(amount), CreditDebit: creditDebit, Category: category}
+}
+
+func TestCompareBudgets(t *testing.T) {
+	transactions := []Transaction{
+		budgetTx("2025-01-05", "450.00", TransactionTypeDebit, "Courses"),
+		budgetTx("2025-02-20", "700.00", TransactionTypeDebit, "Courses"),
+		budgetTx("2025-02-21", "50.00", TransactionTypeCredit, "Courses"), // refund
+		budgetTx("2025-03-01", "80.00", TransactionTypeDebit, "Transports"),
+		budgetTx("2025-03-15", "4200.00", TransactionTypeCredit, "Salaire"),
+		budgetTx("2025-03-31", "12.00", TransactionTypeDebit, ""),
+	}
+	budgets := map[string]decimal.Decimal{
+		"Courses":     decimal.RequireFromString("300"),
+		"Transports":  decimal.RequireFromString("100"),
+		"Restaurants": decimal.RequireFromString("150"),
+	}
+
+	report := CompareBudgets(transactions, budgets)
+
+	assert.Equal(t, "2025-01-05", report.From.Format("2006-01-02"))
+	assert.Equal(t, "2025-03-31", report.To.Format("2006-01-02"))
+	assert.Equal(t, 3, report.Months)
+
+	require.Len(t, report.Lines, 5)
+	byCategory := make(map[string]BudgetLine)
+	var order []string
+	for _, line := range report.Lines {
+		byCategory[line.Category] = line
+		order = append(order, line.Category)
+	}
+	assert.Equal(t, []string{"Courses", "Restaurants", "Salaire", "Transports", CategoryUncategorized}, order)
+
+	courses := byCategory["Courses"]
+	assert.True(t, courses.HasBudget)
+	assert.Equal(t, "900", courses.Budget.String())
+	assert.Equal(t, "1100", courses.Actual.String())
+	assert.Equal(t, "-200", courses.Delta.String())
+	assert.True(t, courses.OverBudget())
+
+	transports := byCategory["Transports"]
+	assert.Equal(t, "220", transports.Delta.String())
+	assert.False(t, transports.OverBudget())
+
+	// Budgeted but nothing spent
+	restaurants := byCategory["Restaurants"]
+	assert.True(t, restaurants.Actual.IsZero())
+	assert.Equal(t, "450", restaurants.Delta.String())
+
+	// Income shows as negative spending, without budget
+	salary := byCategory["Salaire"]
+	assert.False(t, salary.HasBudget)
+	assert.Equal(t, "-4200", salary.Actual.String())
+	assert.False(t, salary.OverBudget())
+
+	assert.Equal(t, "12", byCategory[CategoryUncategorized].Actual.String())
+}
+
+func TestCompareBudgets_NoTransactions(t *testing.T) {
+	report := CompareBudgets(nil, map[string]decimal.Decimal{"Courses": decimal.NewFromInt(300)})
+	assert.Equal(t, 0, report.Months)
+	require.Len(t, report.Lines, 1)
+	assert.True(t, report.Lines[0].Budget.IsZero())
+	assert.False(t, report.Lines[0].OverBudget())
+}
@@ -1,8 +1,8 @@
 package models
 
 import (
-	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"fjacquet/camt-csv/internal/dateutils"
@@ -102,20 +102,35 @@ func (b *TransactionBuilder) WithValueDatetime(date time.Time) *TransactionBuild
 	return b
 }
 
+// datetimeLayouts are tried in order by parseDatetime; the time of day is kept when present.
+var datetimeLayouts = []string{
+	dateutils.DateLayoutFull,
+	dateutils.DateLayoutISOMinutes,
+	dateutils.DateLayoutISO + "T15:04:05",
+	dateutils.DateLayoutISO,
+}
+
+// parseDatetime parses an ISO date with an optional time of day
+func parseDatetime(datetimeStr string) (time.Time, error) {
+	var err error
+	for _, layout := range datetimeLayouts {
+		var date time.Time
+		if date, err = time.Parse(layout, datetimeStr); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, err
+}
+
 // WithDateFromDatetime sets the transaction date from a datetime string (YYYY-MM-DD HH:MM:SS)
 func (b *TransactionBuilder) WithDateFromDatetime(datetimeStr string) *TransactionBuilder {
 	if b.err != nil {
 		return b
 	}
-	// Try parsing as datetime first
-	date, err := time.Parse(dateutils.DateLayoutFull, datetimeStr)
+	date, err := parseDatetime(datetimeStr)
 	if err != nil {
-		// If that fails, try parsing as date only
-		date, err = time.Parse(dateutils.DateLayoutISO, datetimeStr)
-		if err != nil {
-			b.err = fmt.Errorf("invalid datetime format '%s': %w", datetimeStr, err)
-			return b
-		}
+		b.err = fmt.Errorf("invalid datetime format '%s': %w", datetimeStr, err)
+		return b
 	}
 	b.tx.Date = date
 	return b
@@ -126,15 +141,10 @@ func (b *TransactionBuilder) WithValueDateFromDatetime(datetimeStr string) *Tran
 	if b.err != nil {
 		return b
 	}
-	// Try parsing as datetime first
-	date, err := time.Parse(dateutils.DateLayoutFull, datetimeStr)
+	date, err := parseDatetime(datetimeStr)
 	if err != nil {
-		// If that fails, try parsing as date only
-		date, err = time.Parse(dateutils.DateLayoutISO, datetimeStr)
-		if err != nil {
-			b.err = fmt.Errorf("invalid datetime format '%s': %w", datetimeStr, err)
-			return b
-		}
+		b.err = fmt.Errorf("invalid datetime format '%s': %w", datetimeStr, err)
+		return b
 	}
 	b.tx.ValueDate = date
 	return b
@@ -225,6 +235,15 @@ func (b *TransactionBuilder) WithPartyIBAN(iban string) *TransactionBuilder {
 	return b
 }
 
+// WithIBAN sets the IBAN of the account holder's own account
+func (b *TransactionBuilder) WithIBAN(iban string) *TransactionBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.tx.IBAN = iban
+	return b
+}
+
 // WithReference sets the transaction reference
 func (b *TransactionBuilder) WithReference(reference string) *TransactionBuilder {
 	if b.err != nil {
@@ -370,29 +389,63 @@ func (b *TransactionBuilder) AsCredit() *TransactionBuilder {
 	return b
 }
 
-// Build validates and constructs the final Transaction
+// Build validates and constructs the final Transaction. A transaction needs a
+// date, a non-zero amount, a currency and a direction, given by AsDebit/AsCredit
+// or derived from the sign of the amount. The error lists every missing field,
+// so a parser reports a malformed row in one go.
 func (b *TransactionBuilder) Build() (Transaction, error) {
 	if b.err != nil {
 		return Transaction{}, b.err
 	}
 
-	// Validate required fields
+	if problems := b.validate(); len(problems) > 0 {
+		return Transaction{}, fmt.Errorf("invalid transaction: %s", strings.Join(problems, "; "))
+	}
+
+	// Populate derived fields
+	b.populateDerivedFields()
+
+	return b.tx, nil
+}
+
+// MustBuild is like Build but panics on error. It is meant for tests and
+// fixtures whose transactions are known to be complete.
+func (b *TransactionBuilder) MustBuild() Transaction {
+	tx, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return tx
+}
+
+// validate returns a description of each required field that is missing.
+func (b *TransactionBuilder) validate() []string {
+	var problems []string
 	if b.tx.Date.IsZero() {
-		return Transaction{}, errors.New("transaction date is required")
+		problems = append(problems, "transaction date is required")
 	}
 
-	if b.tx.Amount.IsZero() && b.tx.Debit.IsZero() && b.tx.Credit.IsZero() {
-		return Transaction{}, errors.New("transaction amount is required")
+	amountMissing := b.tx.Amount.IsZero() && b.tx.Debit.IsZero() && b.tx.Credit.IsZero()
+	if amountMissing {
+		problems = append(problems, "transaction amount is required")
 	}
 
 	if b.tx.Currency == "" {
-		return Transaction{}, errors.New("currency is required")
+		problems = append(problems, "currency is required")
 	}
 
-	// Populate derived fields
-	b.populateDerivedFields()
-
-	return b.tx, nil
+	switch b.tx.CreditDebit {
+	case TransactionTypeDebit, TransactionTypeCredit:
+	case "":
+		// The direction comes from the sign of the amount
+		if b.tx.Amount.IsZero() && !amountMissing {
+			problems = append(problems, "transaction direction is required: amount is zero and neither AsDebit nor AsCredit was set")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("transaction direction %q is invalid (expected %s or %s)",
+			b.tx.CreditDebit, TransactionTypeDebit, TransactionTypeCredit))
+	}
+	return problems
 }
 
 // populateDerivedFields sets derived fields based on the transaction data
@@ -937,3 +937,48 @@ func TestTransactionBuilder_UncoveredMethods(t *testing.T) {
 		assert.True(t, taxRate.Equal(tx.TaxRate))
 	})
 }
+
+func TestTransactionBuilder_Build_ListsAllMissingFields(t *testing.T) {
+	_, err := NewTransactionBuilder().WithAmount(decimal.Zero, "").Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "transaction date is required")
+	assert.Contains(t, err.Error(), "transaction amount is required")
+	assert.Contains(t, err.Error(), "currency is required")
+}
+
+func TestTransactionBuilder_Build_Direction(t *testing.T) {
+	builder := NewTransactionBuilder().
+		WithDate("2025-01-15").
+		WithAmount(decimal.NewFromFloat(100), "CHF")
+	builder.tx.CreditDebit = "SIDEWAYS"
+	_, err := builder.Build()
+	assert.ErrorContains(t, err, `transaction direction "SIDEWAYS" is invalid`)
+
+	// A zero amount with split debit/credit values leaves no sign to derive the direction from
+	builder = NewTransactionBuilder().
+		WithDate("2025-01-15").
+		WithAmount(decimal.Zero, "CHF")
+	builder.tx.Debit = decimal.NewFromFloat(100)
+	_, err = builder.Build()
+	assert.ErrorContains(t, err, "transaction direction is required")
+
+	builder = NewTransactionBuilder().
+		WithDate("2025-01-15").
+		WithAmount(decimal.Zero, "CHF").
+		AsDebit()
+	builder.tx.Debit = decimal.NewFromFloat(100)
+	_, err = builder.Build()
+	assert.NoError(t, err)
+}
+
+func TestTransactionBuilder_MustBuild(t *testing.T) {
+	tx := NewTransactionBuilder().
+		WithDate("2025-01-15").
+		WithAmount(decimal.NewFromFloat(-42), "CHF").
+		MustBuild()
+	assert.Equal(t, TransactionTypeDebit, tx.CreditDebit)
+
+	assert.Panics(t, func() {
+		NewTransactionBuilder().WithDate("2025-01-15").MustBuild()
+	})
+}
@@ -0,0 +1,17 @@
+package models
+
+import "regexp"
+
+// maskedCardPattern matches a masked card number as printed on statements:
+// one to three groups of four X or * followed by the last four digits, as in
+// "XXXX 1234", "XXXX1234" or "**** **** **** 1234".
+var maskedCardPattern = regexp.MustCompile(`(?i)(?:[X*]{4}[ -]?){1,3}(\d{4})\b`)
+
+// ExtractCardLast4 returns the last four digits of the first masked card
+// number in text, or "" when there is none.
+func ExtractCardLast4(text string) string {
+	if match := maskedCardPattern.FindStringSubmatch(text); match != nil {
+		return match[1]
+	}
+	return ""
+}
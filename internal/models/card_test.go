@@ -0,0 +1,23 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractCardLast4(t *testing.T) {
+	tests := map[string]string{
+		"Visa Gold XXXX 1234":               "1234",
+		"PMT CARTE XXXX5678 RATP":           "5678",
+		"Card **** **** **** 4321":          "4321",
+		"Mastercard xxxx-9876":              "9876",
+		"RATP Paris":                        "",
+		"Order 12345678":                    "",
+		"XXXX 123":                          "",
+		"Visa XXXX 1111 and Visa XXXX 2222": "1111",
+	}
+	for text, want := range tests {
+		assert.Equal(t, want, ExtractCardLast4(text), text)
+	}
+}
@@ -11,6 +11,7 @@ type CategorizationStats struct {
 	Successful    int // Number of transactions successfully categorized
 	Failed        int // Number of transactions that failed categorization
 	Uncategorized int // Number of transactions left uncategorized
+	Excluded      int // Number of transactions left out of the other counts (e.g. internal transfers)
 }
 
 // LogSummary logs a summary of categorization statistics
@@ -25,6 +26,7 @@ func (cs CategorizationStats) LogSummary(logger logging.Logger, parserType strin
 		logging.Field{Key: "successful", Value: cs.Successful},
 		logging.Field{Key: "failed", Value: cs.Failed},
 		logging.Field{Key: "uncategorized", Value: cs.Uncategorized},
+		logging.Field{Key: "excluded", Value: cs.Excluded},
 		logging.Field{Key: "success_rate", Value: cs.GetSuccessRate()},
 	)
 }
@@ -52,6 +54,12 @@ func (cs *CategorizationStats) IncrementFailed() {
 	cs.Failed++
 }
 
+// IncrementExcluded moves one transaction from the total to the excluded count
+func (cs *CategorizationStats) IncrementExcluded() {
+	cs.Total--
+	cs.Excluded++
+}
+
 // IncrementUncategorized increments the uncategorized count
 func (cs *CategorizationStats) IncrementUncategorized() {
 	cs.Uncategorized++
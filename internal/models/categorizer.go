@@ -1,16 +1,43 @@
 // Package models provides the data structures used throughout the application.
 package models
 
-import "context"
+import (
+	"context"
+
+	"github.com/shopspring/decimal"
+)
 
 // Category represents a transaction category
 type Category struct {
 	Name        string
 	Description string
-	Confidence  float64 // Range 0.0-1.0, representing confidence score
-	Source      string  // Strategy that produced this categorization (e.g., "direct_mapping", "keyword", "semantic", "ai")
+	Confidence  float64        // Range 0.0-1.0, representing confidence score
+	Source      CategorySource // Method that produced this categorization (e.g., "mapping", "keyword", "ai")
 }
 
+// CategorySource identifies the categorization method that produced a category.
+// It is what gets written to the output.
+type CategorySource string
+
+const (
+	// CategorySourceMapping means the party was found in the creditor or debtor mappings.
+	CategorySourceMapping CategorySource = "mapping"
+	// CategorySourceKeyword means a keyword rule from the categories file matched.
+	CategorySourceKeyword CategorySource = "keyword"
+	// CategorySourceAI means the category came from the AI model (semantic or generative).
+	CategorySourceAI CategorySource = "ai"
+	// CategorySourceFallback means no method matched and the transaction was left uncategorized.
+	CategorySourceFallback CategorySource = "fallback"
+	// CategorySourceInternal means the party is one of the user's own internal parties.
+	CategorySourceInternal CategorySource = "internal"
+	// CategorySourceMCC means the merchant category code was found in the MCC mappings.
+	CategorySourceMCC CategorySource = "mcc"
+	// CategorySourceIBAN means the counterparty IBAN was found in the IBAN mappings.
+	CategorySourceIBAN CategorySource = "iban"
+	// CategorySourceOverride means the party was forced into its category for the run, e.g. with --map.
+	CategorySourceOverride CategorySource = "override"
+)
+
 // TransactionCategorizer defines the interface for categorizing transactions.
 // This interface is used by parsers to categorize transactions without
 // depending on the concrete categorizer implementation.
@@ -36,12 +63,112 @@ type TransactionCategorizer interface {
 	Categorize(ctx context.Context, partyName string, isDebtor bool, amount, date, info string) (Category, error)
 }
 
-// CategoryConfig represents a category configuration in the YAML file
+// CategoryConfig represents a category configuration in the YAML file.
+// MinAmount and MaxAmount optionally restrict its keywords to transactions
+// whose absolute amount lies within the bounds, both inclusive.
 type CategoryConfig struct {
+	Name      string           `yaml:"name" json:"name"`
+	Keywords  []string         `yaml:"keywords" json:"keywords"`
+	MinAmount *decimal.Decimal `yaml:"min_amount,omitempty" json:"min_amount,omitempty"`
+	MaxAmount *decimal.Decimal `yaml:"max_amount,omitempty" json:"max_amount,omitempty"`
+}
+
+// AmountInRange reports whether the absolute value of amount lies within the
+// MinAmount and MaxAmount bounds. A category without bounds accepts any amount.
+func (c CategoryConfig) AmountInRange(amount decimal.Decimal) bool {
+	amount = amount.Abs()
+	if c.MinAmount != nil && amount.LessThan(*c.MinAmount) {
+		return false
+	}
+	if c.MaxAmount != nil && amount.GreaterThan(*c.MaxAmount) {
+		return false
+	}
+	return true
+}
+
+// InternalPartiesConfig lists the party names that belong to the user (own
+// accounts, household members) and the IBANs of the user's own accounts.
+// Transactions with these parties are transfers between the user's own
+// accounts rather than income or spending.
+type InternalPartiesConfig struct {
+	Names            []string `yaml:"internal_parties"`
+	OwnIBANs         []string `yaml:"own_ibans"`
+	Category         string   `yaml:"internal_transfer_category"`
+	ExcludeFromStats bool     `yaml:"exclude_internal_transfers_from_stats"`
+}
+
+// DirectionalCategory is a creditor or debtor mapping entry whose category
+// depends on the transaction direction, for parties that both charge and
+// refund (e.g. a marketplace). In the mapping files it is written as
+//
+//	galaxus:
+//	  debit: Shopping
+//	  credit: Remboursements
+type DirectionalCategory struct {
+	Debit  string `yaml:"debit,omitempty"`
+	Credit string `yaml:"credit,omitempty"`
+}
+
+// For returns the category for a debit or a credit, or "" when the entry has
+// none for that direction.
+func (d DirectionalCategory) For(isDebit bool) string {
+	if isDebit {
+		return d.Debit
+	}
+	return d.Credit
+}
+
+// TagRule represents a tag definition in the tags YAML file. A transaction gets
+// the tag when any keyword appears in its party name or description.
+type TagRule struct {
 	Name     string   `yaml:"name"`
 	Keywords []string `yaml:"keywords"`
 }
 
+// TagsConfig represents the structure of the tags YAML file
+type TagsConfig struct {
+	Tags []TagRule `yaml:"tags"`
+}
+
+// TransactionTagger is implemented by categorizers that can also attach free-form
+// tags to a transaction. Tags are independent of the single category.
+type TransactionTagger interface {
+	// Tags returns the names of all tag rules matching the party name or description.
+	Tags(partyName, description string) []string
+}
+
+// ApplyTags sets tx.Tags from categorizer when it implements TransactionTagger.
+// The party is PartyName, falling back to the payee/payer and then Name.
+func ApplyTags(tx *Transaction, categorizer TransactionCategorizer) {
+	tagger, ok := categorizer.(TransactionTagger)
+	if !ok {
+		return
+	}
+
+	party := tx.PartyName
+	if party == "" {
+		party = tx.GetPartyName()
+	}
+	if party == "" {
+		party = tx.Name
+	}
+	tx.Tags = tagger.Tags(party, tx.Description)
+}
+
+// StatsExcluder is implemented by categorizers that keep some categories out
+// of the categorization statistics, such as transfers between own accounts.
+type StatsExcluder interface {
+	// ExcludeFromStats reports whether transactions in category are left out of the statistics.
+	ExcludeFromStats(category Category) bool
+}
+
+// ExcludedFromStats reports whether categorizer implements StatsExcluder and
+// excludes category.
+func ExcludedFromStats(categorizer TransactionCategorizer, category Category) bool {
+	excluder, ok := categorizer.(StatsExcluder)
+	return ok && excluder.ExcludeFromStats(category)
+}
+
 // CategoriesConfig represents the structure of the categories YAML file
 type CategoriesConfig struct {
 	Categories []CategoryConfig `yaml:"categories"`
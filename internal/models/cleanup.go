@@ -0,0 +1,155 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Party-name cleanup actions.
+const (
+	// CleanupStripPrefix removes the first of Values the name starts with.
+	CleanupStripPrefix = "strip_prefix"
+	// CleanupStripSuffix removes the first of Values the name ends with.
+	CleanupStripSuffix = "strip_suffix"
+	// CleanupReplace replaces every match of Pattern with Replacement.
+	CleanupReplace = "replace"
+)
+
+// NameCleanupRule is one step of the party-name cleanup pipeline, defined in
+// the cleanup YAML file. Steps run in file order, each on the result of the
+// previous one. A step that would leave the name empty is skipped, so a name
+// consisting only of a prefix such as "PMT TWINT" is kept.
+type NameCleanupRule struct {
+	Action      string   `yaml:"action"`
+	Values      []string `yaml:"values,omitempty"`
+	Pattern     string   `yaml:"pattern,omitempty"`
+	Replacement string   `yaml:"replacement,omitempty"`
+}
+
+// NameCleanupConfig represents the structure of the cleanup YAML file
+type NameCleanupConfig struct {
+	Rules []NameCleanupRule `yaml:"rules"`
+}
+
+// DefaultNameCleanupRules returns the rules used when no cleanup file exists:
+// the payment method prefixes of Swiss bank statements.
+func DefaultNameCleanupRules() []NameCleanupRule {
+	return []NameCleanupRule{
+		{Action: CleanupStripPrefix, Values: []string{"PMT CARTE", "PMT TWINT", "BCV-NET", "VIRT BANC"}},
+	}
+}
+
+// NameCleaner applies a compiled list of cleanup rules to party names.
+type NameCleaner struct {
+	steps []func(string) string
+}
+
+// NewNameCleaner compiles rules. It returns an error naming the first rule
+// with an unknown action, no values, or an invalid pattern.
+func NewNameCleaner(rules []NameCleanupRule) (*NameCleaner, error) {
+	cleaner := &NameCleaner{}
+	for i, rule := range rules {
+		step, err := compileCleanupRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("cleanup rule %d: %w", i+1, err)
+		}
+		cleaner.steps = append(cleaner.steps, step)
+	}
+	return cleaner, nil
+}
+
+func compileCleanupRule(rule NameCleanupRule) (func(string) string, error) {
+	switch rule.Action {
+	case CleanupStripPrefix, CleanupStripSuffix:
+		if len(rule.Values) == 0 {
+			return nil, fmt.Errorf("%s needs at least one value", rule.Action)
+		}
+		values := rule.Values
+		if rule.Action == CleanupStripPrefix {
+			return func(name string) string {
+				for _, value := range values {
+					if strings.HasPrefix(name, value) {
+						return name[len(value):]
+					}
+				}
+				return name
+			}, nil
+		}
+		return func(name string) string {
+			for _, value := range values {
+				if strings.HasSuffix(name, value) {
+					return name[:len(name)-len(value)]
+				}
+			}
+			return name
+		}, nil
+	case CleanupReplace:
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+		replacement := rule.Replacement
+		return func(name string) string {
+			return re.ReplaceAllString(name, replacement)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown action %q (expected %s, %s or %s)",
+			rule.Action, CleanupStripPrefix, CleanupStripSuffix, CleanupReplace)
+	}
+}
+
+// Clean runs name through the rules and trims the result.
+func (c *NameCleaner) Clean(name string) string {
+	if c == nil {
+		return name
+	}
+	for _, step := range c.steps {
+		if cleaned := strings.TrimSpace(step(name)); cleaned != "" {
+			name = cleaned
+		}
+	}
+	return name
+}
+
+// PartyNameCleaner is implemented by categorizers that clean party names
+// with the configured cleanup rules.
+type PartyNameCleaner interface {
+	CleanPartyName(name string) string
+}
+
+// CleanName returns name cleaned by categorizer when it implements
+// PartyNameCleaner, and name unchanged otherwise.
+func CleanName(name string, categorizer TransactionCategorizer) string {
+	cleaner, ok := categorizer.(PartyNameCleaner)
+	if !ok || name == "" {
+		return name
+	}
+	return cleaner.CleanPartyName(name)
+}
+
+// CleanPartyName cleans the Name and PartyName of tx with categorizer, so
+// that categorization and output see the same cleaned party. Parsers call it
+// before ApplyTags and categorization.
+func CleanPartyName(tx *Transaction, categorizer TransactionCategorizer) {
+	tx.PartyName = CleanName(tx.PartyName, categorizer)
+	tx.Name = CleanName(tx.Name, categorizer)
+}
+
+// CleanupOnly is a categorizer that cleans party names with Cleaner and
+// leaves every transaction Uncategorized. It stands in for the categorizer
+// when categorization is turned off, so that the output is still cleaned.
+type CleanupOnly struct {
+	Cleaner PartyNameCleaner
+}
+
+// Categorize implements TransactionCategorizer without categorizing.
+func (c CleanupOnly) Categorize(context.Context, string, bool, string, string, string) (Category, error) {
+	return Category{Name: CategoryUncategorized, Source: CategorySourceFallback}, nil
+}
+
+// CleanPartyName implements PartyNameCleaner with Cleaner.
+func (c CleanupOnly) CleanPartyName(name string) string {
+	return c.Cleaner.CleanPartyName(name)
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNameCleaner_Defaults(t *testing.T) {
+	cleaner, err := NewNameCleaner(DefaultNameCleanupRules())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"PMT CARTE only", "PMT CARTE", "PMT CARTE"},
+		{"PMT CARTE with merchant", "PMT CARTE Starbucks", "Starbucks"},
+		{"PMT TWINT only", "PMT TWINT", "PMT TWINT"},
+		{"PMT TWINT with merchant", "PMT TWINT Coffee Shop", "Coffee Shop"},
+		{"BCV-NET only", "BCV-NET", "BCV-NET"},
+		{"BCV-NET with description", "BCV-NET Transfer", "Transfer"},
+		{"VIRT BANC only", "VIRT BANC", "VIRT BANC"},
+		{"VIRT BANC with description", "VIRT BANC Payment", "Payment"},
+		{"no prefix", "Regular Payment", "Regular Payment"},
+		{"only one prefix removed", "PMT CARTE PMT TWINT Shop", "PMT TWINT Shop"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, cleaner.Clean(tt.input))
+		})
+	}
+}
+
+func TestNameCleaner_Pipeline(t *testing.T) {
+	cleaner, err := NewNameCleaner([]NameCleanupRule{
+		{Action: CleanupStripPrefix, Values: []string{"PMT CARTE"}},
+		{Action: CleanupReplace, Pattern: `X{4,}\d*`, Replacement: ""},
+		{Action: CleanupStripSuffix, Values: []string{" CH", " Lausanne"}},
+	})
+	require.NoError(t, err)
+
+	// Steps run in order, each on the previous result
+	assert.Equal(t, "Migros", cleaner.Clean("PMT CARTE XXXX1234 Migros CH"))
+	assert.Equal(t, "Coop", cleaner.Clean("Coop Lausanne"))
+
+	// A nil cleaner leaves names alone
+	var none *NameCleaner
+	assert.Equal(t, "PMT CARTE Coop", none.Clean("PMT CARTE Coop"))
+}
+
+func TestNewNameCleaner_InvalidRules(t *testing.T) {
+	_, err := NewNameCleaner([]NameCleanupRule{{Action: "uppercase"}})
+	assert.ErrorContains(t, err, `cleanup rule 1: unknown action "uppercase"`)
+
+	_, err = NewNameCleaner([]NameCleanupRule{
+		{Action: CleanupStripPrefix, Values: []string{"A"}},
+		{Action: CleanupStripSuffix},
+	})
+	assert.ErrorContains(t, err, "cleanup rule 2: strip_suffix needs at least one value")
+
+	_, err = NewNameCleaner([]NameCleanupRule{{Action: CleanupReplace, Pattern: "("}})
+	assert.ErrorContains(t, err, "invalid pattern")
+}
+
+type plainCategorizer struct{}
+
+func (plainCategorizer) Categorize(context.Context, string, bool, string, string, string) (Category, error) {
+	return Category{}, nil
+}
+
+type cleaningCategorizer struct {
+	plainCategorizer
+}
+
+func (cleaningCategorizer) CleanPartyName(name string) string {
+	cleaner, _ := NewNameCleaner(DefaultNameCleanupRules())
+	return cleaner.Clean(name)
+}
+
+func TestCleanPartyName(t *testing.T) {
+	tx := Transaction{Name: "PMT TWINT Coffee Shop", PartyName: "PMT TWINT Coffee Shop"}
+	CleanPartyName(&tx, cleaningCategorizer{})
+	assert.Equal(t, "Coffee Shop", tx.Name)
+	assert.Equal(t, "Coffee Shop", tx.PartyName)
+
+	// Categorizers without cleanup, and no categorizer, leave the names alone
+	tx = Transaction{PartyName: "PMT TWINT Coffee Shop"}
+	CleanPartyName(&tx, plainCategorizer{})
+	assert.Equal(t, "PMT TWINT Coffee Shop", tx.PartyName)
+	CleanPartyName(&tx, nil)
+	assert.Equal(t, "PMT TWINT Coffee Shop", tx.PartyName)
+}
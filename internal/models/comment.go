@@ -0,0 +1,49 @@
+package models
+
+import (
+	"bytes"
+	"strings"
+)
+
+// CSVCommentPrefix starts the informational lines written above the header of
+// a CSV export, such as statement notes. Readers of camt-csv exports skip them.
+const CSVCommentPrefix = "#"
+
+// UTF8BOM is the byte order mark written at the start of a CSV export with
+// --bom, so that Excel on Windows reads it as UTF-8.
+const UTF8BOM = "\ufeff"
+
+// StatementNotes returns the distinct statement notes of transactions, in the
+// order they first appear.
+func StatementNotes(transactions []Transaction) []string {
+	var notes []string
+	seen := make(map[string]bool)
+	for _, tx := range transactions {
+		if tx.StatementNote == "" || seen[tx.StatementNote] {
+			continue
+		}
+		seen[tx.StatementNote] = true
+		notes = append(notes, tx.StatementNote)
+	}
+	return notes
+}
+
+// StatementNoteComment returns note as a CSV comment line, without the line
+// break.
+func StatementNoteComment(note string) string {
+	return CSVCommentPrefix + " Statement note: " + strings.Join(strings.Fields(note), " ")
+}
+
+// StripCSVComments returns data without the byte order mark and the comment
+// lines preceding its header.
+func StripCSVComments(data []byte) []byte {
+	data = bytes.TrimPrefix(data, []byte(UTF8BOM))
+	for bytes.HasPrefix(data, []byte(CSVCommentPrefix)) {
+		_, rest, found := bytes.Cut(data, []byte("\n"))
+		if !found {
+			return nil
+		}
+		data = rest
+	}
+	return data
+}
@@ -6,6 +6,12 @@ const (
 	TransactionTypeCredit = "CRDT"
 )
 
+// TypeReversal is the Type of an entry that reverses an earlier booking
+const TypeReversal = "Reversal"
+
+// TypeRefund is the Type of a card entry crediting back an earlier purchase
+const TypeRefund = "Refund"
+
 // Transaction statuses
 const (
 	StatusCompleted = "COMPLETED"
@@ -13,6 +19,14 @@ const (
 	StatusFailed    = "FAILED"
 )
 
+// ISO 20022 entry statuses (Ntry/Sts)
+const (
+	EntryStatusBooked  = "BOOK"
+	EntryStatusPending = "PDNG"
+	EntryStatusInfo    = "INFO"
+	EntryStatusFuture  = "FUTR"
+)
+
 // Category constants
 const (
 	CategoryUncategorized = "Uncategorized"
@@ -39,7 +53,9 @@ const (
 const (
 	DefaultCSVDelimiter = ',' // Aligned with config default
 	DateFormatCSV       = "02.01.2006"
-	DecimalPlaces       = 2
+	DateTimeFormatCSV   = "02.01.2006 15:04"
+	DecimalPlaces       = 2 // Amount precision for currencies without an entry in the precision table
+	RateDecimalPlaces   = 4 // Precision of exchange rates
 )
 
 // Performance tuning constants
@@ -0,0 +1,36 @@
+package models
+
+import "strings"
+
+// NormalizeCreditorReference removes the spaces of the printed form of a
+// creditor reference ("RF18 5390 0754 7034") and upper-cases it.
+func NormalizeCreditorReference(ref string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(ref), ""))
+}
+
+// IsValidCreditorReference reports whether ref is an ISO 11649 creditor
+// reference: "RF", two check digits and 1 to 21 letters or digits, such that
+// the ISO 7064 MOD 97-10 check passes. ref must be normalized.
+func IsValidCreditorReference(ref string) bool {
+	if len(ref) < 5 || len(ref) > 25 || !strings.HasPrefix(ref, "RF") {
+		return false
+	}
+	if ref[2] < '0' || ref[2] > '9' || ref[3] < '0' || ref[3] > '9' {
+		return false
+	}
+
+	// Move "RFnn" to the end, turn letters into 10..35 and reduce modulo 97
+	// digit by digit
+	remainder := 0
+	for _, c := range ref[4:] + ref[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			remainder = (remainder*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			remainder = (remainder*100 + int(c-'A') + 10) % 97
+		default:
+			return false
+		}
+	}
+	return remainder == 1
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidCreditorReference(t *testing.T) {
+	tests := []struct {
+		ref   string
+		valid bool
+	}{
+		{"RF18539007547034", true},
+		{"RF712348231", true},
+		{"RF18000000000539007547034", true},
+		{"RF19539007547034", false}, // wrong check digits
+		{"RF18", false},
+		{"RF1853900754703400000000000", false}, // too long
+		{"RFAB539007547034", false},
+		{"RF18-5390-0754-7034", false},
+		{"210000000003139471430009017", false}, // Swiss QR reference
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			assert.Equal(t, tt.valid, IsValidCreditorReference(tt.ref))
+		})
+	}
+}
+
+func TestNormalizeCreditorReference(t *testing.T) {
+	assert.Equal(t, "RF18539007547034", NormalizeCreditorReference(" rf18 5390 0754 7034 "))
+}
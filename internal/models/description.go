@@ -0,0 +1,158 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// descriptionFields are the transaction fields a description template can
+// reference, by the name used between braces.
+var descriptionFields = map[string]func(tx Transaction) string{
+	"Description":         func(tx Transaction) string { return tx.Description },
+	"Name":                func(tx Transaction) string { return tx.Name },
+	"PartyName":           func(tx Transaction) string { return tx.PartyName },
+	"PartyIBAN":           func(tx Transaction) string { return tx.PartyIBAN },
+	"RemittanceInfo":      func(tx Transaction) string { return tx.RemittanceInfo },
+	"Reference":           func(tx Transaction) string { return tx.Reference },
+	"EntryReference":      func(tx Transaction) string { return tx.EntryReference },
+	"CreditorReference":   func(tx Transaction) string { return tx.CreditorReference },
+	"StructuredReference": func(tx Transaction) string { return tx.StructuredReference },
+	"ReferenceType":       func(tx Transaction) string { return tx.ReferenceType },
+	"Recipient":           func(tx Transaction) string { return tx.Recipient },
+	"Product":             func(tx Transaction) string { return tx.Product },
+	"Type":                func(tx Transaction) string { return tx.Type },
+	"Investment":          func(tx Transaction) string { return tx.Investment },
+	"Fund":                func(tx Transaction) string { return tx.Fund },
+	"Number":              func(tx Transaction) string { return tx.Number },
+	"BankTxCode":          func(tx Transaction) string { return tx.BankTxCode },
+	"Currency":            func(tx Transaction) string { return tx.Currency },
+	"Category":            func(tx Transaction) string { return tx.Category },
+}
+
+// descriptionPart is one field of a description template with the text that
+// belongs to it.
+type descriptionPart struct {
+	field string
+	// separator is written before the field when an earlier field was written.
+	separator string
+	// prefix and suffix enclose the field, e.g. "(" and ")", and are only
+	// written with it.
+	prefix string
+	suffix string
+}
+
+// DescriptionTemplate builds transaction descriptions from other fields, as in
+// "{PartyName} - {RemittanceInfo} ({Reference})". Empty fields are left out
+// together with their separator and enclosing brackets, so no dangling " - "
+// or "()" remains.
+type DescriptionTemplate struct {
+	parts []descriptionPart
+	// trailing is the text after the last field, such as a final ".", written
+	// after whichever field comes last.
+	trailing string
+}
+
+// ParseDescriptionTemplate parses a template of {Field} placeholders and
+// literal text. It returns an error for unknown fields, unbalanced braces or a
+// template without any field.
+func ParseDescriptionTemplate(template string) (DescriptionTemplate, error) {
+	var fields []string
+	var literals []string // literals[i] precedes fields[i]; the last one trails
+	rest := template
+	for {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			if strings.Contains(rest, "}") {
+				return DescriptionTemplate{}, fmt.Errorf("unbalanced '}' in description template %q", template)
+			}
+			literals = append(literals, rest)
+			break
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return DescriptionTemplate{}, fmt.Errorf("unclosed '{' in description template %q", template)
+		}
+		literal, field := rest[:open], rest[open+1:open+end]
+		if strings.Contains(literal, "}") {
+			return DescriptionTemplate{}, fmt.Errorf("unbalanced '}' in description template %q", template)
+		}
+		if _, ok := descriptionFields[field]; !ok {
+			return DescriptionTemplate{}, fmt.Errorf("unknown field {%s} in description template; valid fields: %s",
+				field, strings.Join(DescriptionFields(), ", "))
+		}
+		literals = append(literals, literal)
+		fields = append(fields, field)
+		rest = rest[open+end+1:]
+	}
+	if len(fields) == 0 {
+		return DescriptionTemplate{}, fmt.Errorf("description template %q has no {Field}", template)
+	}
+
+	parts := make([]descriptionPart, len(fields))
+	for i, field := range fields {
+		parts[i].field = field
+		if i == 0 {
+			// Leading text, such as "Ref: ", only makes sense with its field
+			parts[i].prefix = literals[0]
+			continue
+		}
+		// Closing brackets after a field close it; opening brackets before
+		// the next field open it; what lies between separates the two.
+		between := literals[i]
+		closing := len(between) - len(strings.TrimLeft(between, ")]"))
+		opening := len(strings.TrimRight(between, "(["))
+		if opening < closing {
+			opening = closing
+		}
+		parts[i-1].suffix = between[:closing]
+		parts[i].separator = between[closing:opening]
+		parts[i].prefix = between[opening:]
+	}
+	trailing := literals[len(literals)-1]
+	closing := len(trailing) - len(strings.TrimLeft(trailing, ")]"))
+	parts[len(parts)-1].suffix = trailing[:closing]
+	return DescriptionTemplate{parts: parts, trailing: trailing[closing:]}, nil
+}
+
+// IsZero reports whether t is the zero template, which keeps descriptions as
+// the parsers build them.
+func (t DescriptionTemplate) IsZero() bool {
+	return len(t.parts) == 0
+}
+
+// Render returns the description of tx built from the template, with
+// surrounding whitespace trimmed from each field. When every field is empty,
+// the description of tx is returned unchanged.
+func (t DescriptionTemplate) Render(tx Transaction) string {
+	var b strings.Builder
+	written := false
+	for _, part := range t.parts {
+		value := strings.TrimSpace(descriptionFields[part.field](tx))
+		if value == "" {
+			continue
+		}
+		if written {
+			b.WriteString(part.separator)
+		}
+		b.WriteString(part.prefix)
+		b.WriteString(value)
+		b.WriteString(part.suffix)
+		written = true
+	}
+	if !written {
+		return tx.Description
+	}
+	b.WriteString(t.trailing)
+	return b.String()
+}
+
+// DescriptionFields returns the field names a description template can use, sorted.
+func DescriptionFields() []string {
+	names := make([]string, 0, len(descriptionFields))
+	for name := range descriptionFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescriptionTemplate_Render(t *testing.T) {
+	template, err := ParseDescriptionTemplate("{PartyName} - {RemittanceInfo} ({Reference})")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		tx   Transaction
+		want string
+	}{
+		{"all fields", Transaction{PartyName: "Migros", RemittanceInfo: "Groceries", Reference: "R1"}, "Migros - Groceries (R1)"},
+		{"no reference", Transaction{PartyName: "Migros", RemittanceInfo: "Groceries"}, "Migros - Groceries"},
+		{"no remittance", Transaction{PartyName: "Migros", Reference: "R1"}, "Migros (R1)"},
+		{"no party", Transaction{RemittanceInfo: "Groceries", Reference: "R1"}, "Groceries (R1)"},
+		{"only reference", Transaction{Reference: "R1"}, "(R1)"},
+		{"whitespace is empty", Transaction{PartyName: "  ", RemittanceInfo: " Groceries "}, "Groceries"},
+		{"nothing keeps description", Transaction{Description: "Parser text"}, "Parser text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, template.Render(tt.tx))
+		})
+	}
+}
+
+func TestDescriptionTemplate_LeadingAndTrailingText(t *testing.T) {
+	template, err := ParseDescriptionTemplate("Ref: {Reference}, {Name}.")
+	require.NoError(t, err)
+	assert.Equal(t, "Ref: R1, Coop.", template.Render(Transaction{Reference: "R1", Name: "Coop"}))
+	assert.Equal(t, "Coop.", template.Render(Transaction{Name: "Coop"}))
+	assert.Equal(t, "Ref: R1.", template.Render(Transaction{Reference: "R1"}))
+}
+
+func TestParseDescriptionTemplate_Errors(t *testing.T) {
+	for _, template := range []string{
+		"{Payee}",
+		"{PartyName",
+		"PartyName}",
+		"{PartyName} }",
+		"no fields",
+		"",
+	} {
+		_, err := ParseDescriptionTemplate(template)
+		assert.Error(t, err, template)
+	}
+
+	_, err := ParseDescriptionTemplate("{Payee}")
+	assert.ErrorContains(t, err, "PartyName")
+}
+
+func TestDescriptionTemplate_IsZero(t *testing.T) {
+	assert.True(t, DescriptionTemplate{}.IsZero())
+	template, err := ParseDescriptionTemplate("{Description}")
+	require.NoError(t, err)
+	assert.False(t, template.IsZero())
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// TransactionHash returns a SHA-256 hash identifying a transaction by what the
+// bank recorded: dates, party, description, amount, direction and references.
+// The category and other fields derived by camt-csv are left out, so the same
+// transaction hashes alike before and after it is categorized again.
+func TransactionHash(tx Transaction) string {
+	h := sha256.New()
+	for _, field := range []string{
+		tx.Date.Format(time.DateOnly),
+		tx.ValueDate.Format(time.DateOnly),
+		tx.PartyName,
+		tx.PartyIBAN,
+		tx.Description,
+		tx.RemittanceInfo,
+		tx.Amount.Abs().String(),
+		tx.CreditDebit,
+		tx.Currency,
+		tx.EntryReference,
+		tx.Reference,
+	} {
+		h.Write([]byte(field))
+		h.Write([]byte{0x1f})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// CategoryChange is a transaction found in both sides of a diff with a
+// different category.
+type CategoryChange struct {
+	Transaction Transaction
+	OldCategory string
+	NewCategory string
+}
+
+// TransactionDiff lists how a set of transactions changed.
+type TransactionDiff struct {
+	Added         []Transaction
+	Removed       []Transaction
+	Recategorized []CategoryChange
+}
+
+// Empty reports whether nothing changed.
+func (d TransactionDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Recategorized) == 0
+}
+
+// DiffTransactions compares old and updated transactions, matched by
+// TransactionHash. Identical transactions, such as two equal purchases on the
+// same day, are matched in order, so an extra copy shows as added or removed.
+// Results keep the order of the input they come from.
+func DiffTransactions(old, updated []Transaction) TransactionDiff {
+	unmatched := make(map[string][]int, len(old))
+	for i, tx := range old {
+		hash := TransactionHash(tx)
+		unmatched[hash] = append(unmatched[hash], i)
+	}
+
+	var diff TransactionDiff
+	matched := make([]bool, len(old))
+	for _, tx := range updated {
+		hash := TransactionHash(tx)
+		candidates := unmatched[hash]
+		if len(candidates) == 0 {
+			diff.Added = append(diff.Added, tx)
+			continue
+		}
+		i := candidates[0]
+		unmatched[hash] = candidates[1:]
+		matched[i] = true
+		if old[i].Category != tx.Category {
+			diff.Recategorized = append(diff.Recategorized, CategoryChange{
+				Transaction: tx,
+				OldCategory: old[i].Category,
+				NewCategory: tx.Category,
+			})
+		}
+	}
+	for i, tx := range old {
+		if !matched[i] {
+			diff.Removed = append(diff.Removed, tx)
+		}
+	}
+	return diff
+}
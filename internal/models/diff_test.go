@@ -0,0 +1,71 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func diffTransaction(party, amount, category string) Transaction {
+	return Transaction{
+		Date:        time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC),
+		ValueDate:   time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC),
+		PartyName:   party,
+		Description: "Card payment " + party,
+		Amount:      decimal.RequireFromString(amount),
+		CreditDebit: TransactionTypeDebit,
+		Currency:    "CHF",
+		Category:    category,
+	}
+}
+
+func TestTransactionHash(t *testing.T) {
+	tx := diffTransaction("Migros", "42.10", "Courses")
+
+	recategorized := tx
+	recategorized.Category = "Restaurants"
+	recategorized.CategorySource = CategorySourceAI
+	assert.Equal(t, TransactionHash(tx), TransactionHash(recategorized), "the category is not part of the hash")
+
+	// Signed-amount exports hold negative debits
+	signed := tx
+	signed.Amount = signed.Amount.Neg()
+	assert.Equal(t, TransactionHash(tx), TransactionHash(signed))
+
+	other := tx
+	other.Amount = decimal.RequireFromString("42.20")
+	assert.NotEqual(t, TransactionHash(tx), TransactionHash(other))
+}
+
+func TestDiffTransactions(t *testing.T) {
+	coffee := diffTransaction("Café du Lac", "4.50", "Restaurants")
+	old := []Transaction{
+		diffTransaction("Migros", "42.10", "Uncategorized"),
+		diffTransaction("SBB", "18.00", "Transport"),
+		coffee,
+		coffee,
+	}
+	updated := []Transaction{
+		diffTransaction("Migros", "42.10", "Courses"),
+		coffee,
+		diffTransaction("Coop", "12.30", "Courses"),
+	}
+
+	diff := DiffTransactions(old, updated)
+
+	require.Len(t, diff.Added, 1)
+	assert.Equal(t, "Coop", diff.Added[0].PartyName)
+	require.Len(t, diff.Removed, 2)
+	assert.Equal(t, "SBB", diff.Removed[0].PartyName)
+	assert.Equal(t, "Café du Lac", diff.Removed[1].PartyName, "a second identical coffee is removed")
+	require.Len(t, diff.Recategorized, 1)
+	assert.Equal(t, "Migros", diff.Recategorized[0].Transaction.PartyName)
+	assert.Equal(t, "Uncategorized", diff.Recategorized[0].OldCategory)
+	assert.Equal(t, "Courses", diff.Recategorized[0].NewCategory)
+	assert.False(t, diff.Empty())
+
+	assert.True(t, DiffTransactions(old, old).Empty())
+}
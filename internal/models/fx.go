@@ -0,0 +1,23 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// FXDifference returns the difference between the booked amount and the
+// original amount converted at ExchangeRate, in the transaction's currency:
+// positive when more was booked than the rate gives, negative when less. The
+// rate may be quoted either way round (booked per original or original per
+// booked); the reading that comes closer to the booked amount is used. ok is
+// false unless Amount, OriginalAmount and ExchangeRate are all non-zero.
+func (t *Transaction) FXDifference() (difference decimal.Decimal, ok bool) {
+	if t.Amount.IsZero() || t.OriginalAmount.IsZero() || t.ExchangeRate.IsZero() {
+		return decimal.Zero, false
+	}
+
+	booked := t.Amount.Abs()
+	original := t.OriginalAmount.Abs()
+	converted := original.Mul(t.ExchangeRate)
+	if inverse := original.Div(t.ExchangeRate); booked.Sub(inverse).Abs().LessThan(booked.Sub(converted).Abs()) {
+		converted = inverse
+	}
+	return booked.Sub(converted), true
+}
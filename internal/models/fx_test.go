@@ -0,0 +1,41 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_FXDifference(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		original string
+		rate     string
+		want     string
+		wantOK   bool
+	}{
+		// 40.00 EUR at 1.0712 CHF/EUR is 42.848 CHF; 42.80 CHF was booked
+		{"booked per original", "-42.80", "40.00", "1.0712", "-0.048", true},
+		// 500.00 CHF converted to 530.50 EUR at 1.061 EUR/CHF, with no spread
+		{"original per booked", "500.00", "530.50", "1.061", "0", true},
+		{"booked more than the rate gives", "108.50", "100.00", "1.08", "0.5", true},
+		{"no original amount", "42.80", "0", "1.0712", "0", false},
+		{"no rate", "42.80", "40.00", "0", "0", false},
+		{"no amount", "0", "40.00", "1.0712", "0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := Transaction{
+				Amount:         decimal.RequireFromString(tt.amount),
+				OriginalAmount: decimal.RequireFromString(tt.original),
+				ExchangeRate:   decimal.RequireFromString(tt.rate),
+			}
+			got, ok := tx.FXDifference()
+			assert.Equal(t, tt.wantOK, ok)
+			assert.True(t, decimal.RequireFromString(tt.want).Equal(got), "got %s", got)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package models
+
+// IBANCategorizer is implemented by categorizers that map counterparty IBANs
+// to categories. An account number does not change with the way a bank
+// prints the party name, so a mapped IBAN is consulted before any other
+// categorization except the recognition of transfers between own accounts.
+type IBANCategorizer interface {
+	// CategoryForIBAN returns the category mapped to iban, if any.
+	CategoryForIBAN(iban string) (string, bool)
+}
+
+// ApplyIBANMapping sets the category of tx from its counterparty IBAN when
+// categorizer implements IBANCategorizer and maps the IBAN. It returns true
+// when tx was categorized, in which case it needs no further categorization.
+func ApplyIBANMapping(tx *Transaction, categorizer TransactionCategorizer) bool {
+	matcher, ok := categorizer.(IBANCategorizer)
+	if !ok || tx.PartyIBAN == "" {
+		return false
+	}
+	category, ok := matcher.CategoryForIBAN(tx.PartyIBAN)
+	if !ok {
+		return false
+	}
+	tx.Category = category
+	tx.CategorySource = CategorySourceIBAN
+	auditCategorization(tx, categorizer)
+	return true
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ibanCategorizer maps counterparty IBANs and categorizes nothing by name.
+type ibanCategorizer map[string]string
+
+func (m ibanCategorizer) Categorize(context.Context, string, bool, string, string, string) (Category, error) {
+	return Category{Name: CategoryUncategorized}, nil
+}
+
+func (m ibanCategorizer) CategoryForIBAN(iban string) (string, bool) {
+	category, ok := m[NormalizeIBAN(iban)]
+	return category, ok
+}
+
+func TestApplyIBANMapping(t *testing.T) {
+	categorizer := ibanCategorizer{"CH9300762011623852957": "Logement"}
+
+	rent := Transaction{PartyName: "Régie du Centre", PartyIBAN: "CH93 0076 2011 6238 5295 7"}
+	assert.True(t, ApplyIBANMapping(&rent, categorizer))
+	assert.Equal(t, "Logement", rent.Category)
+	assert.Equal(t, CategorySourceIBAN, rent.CategorySource)
+
+	unmapped := Transaction{PartyName: "Migros", PartyIBAN: "CH5604835012345678009"}
+	assert.False(t, ApplyIBANMapping(&unmapped, categorizer))
+	assert.Empty(t, unmapped.Category)
+
+	noIBAN := Transaction{PartyName: "Régie du Centre"}
+	assert.False(t, ApplyIBANMapping(&noIBAN, categorizer))
+	assert.False(t, ApplyIBANMapping(&rent, nil), "a categorizer without IBAN mappings is not consulted")
+}
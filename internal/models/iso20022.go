@@ -3,7 +3,11 @@ package models
 
 import (
 	"encoding/xml"
+	"strconv"
 	"strings"
+	"time"
+
+	"fjacquet/camt-csv/internal/dateutils"
 )
 
 // ISO20022Document represents the root structure of a CAMT.053 XML document
@@ -16,12 +20,78 @@ type ISO20022Document struct {
 
 // Statement represents a bank statement in the CAMT.053 format
 type Statement struct {
-	ID      string    `xml:"Id"`
-	CreDtTm string    `xml:"CreDtTm"`
-	FrToDt  *Period   `xml:"FrToDt"`
-	Acct    Account   `xml:"Acct"`
-	Bal     []Balance `xml:"Bal"`
-	Ntry    []Entry   `xml:"Ntry"`
+	ID           string    `xml:"Id"`
+	ElctrncSeqNb string    `xml:"ElctrncSeqNb"` // Electronic sequence number, incremented per statement
+	LglSeqNb     string    `xml:"LglSeqNb"`     // Legal sequence number, e.g. the paper statement number
+	CreDtTm      string    `xml:"CreDtTm"`
+	FrToDt       *Period   `xml:"FrToDt"`
+	Acct         Account   `xml:"Acct"`
+	Bal          []Balance `xml:"Bal"`
+	Ntry         []Entry   `xml:"Ntry"`
+	AddtlStmtInf string    `xml:"AddtlStmtInf"` // Free-text notes on the whole statement, e.g. a correction
+}
+
+// StatementInfo is the statement-level metadata of a CAMT.053 statement.
+type StatementInfo struct {
+	ID                       string
+	AccountID                string    // IBAN, or the other account ID when there is none
+	CreatedAt                string    // Creation date and time as given in the statement
+	ElectronicSequenceNumber int64     // 0 when the statement has none
+	LegalSequenceNumber      int64     // 0 when the statement has none
+	AdditionalInfo           string    // Statement-level notes (AddtlStmtInf), whitespace collapsed
+	PeriodStart              time.Time // First day of the reporting period (FrToDt), zero when the statement has none
+	PeriodEnd                time.Time // Last day of the reporting period (FrToDt), zero when the statement has none
+	Source                   string    // File the statement was read from, if known
+}
+
+// Info returns the statement's metadata. Sequence numbers that are missing
+// or not integers are reported as 0.
+func (s *Statement) Info() StatementInfo {
+	accountID := s.Acct.ID.IBAN
+	if accountID == "" {
+		accountID = s.Acct.ID.Othr.ID
+	}
+	periodStart, periodEnd := s.Period()
+	return StatementInfo{
+		ID:                       s.ID,
+		AccountID:                accountID,
+		CreatedAt:                s.CreDtTm,
+		ElectronicSequenceNumber: parseSequenceNumber(s.ElctrncSeqNb),
+		LegalSequenceNumber:      parseSequenceNumber(s.LglSeqNb),
+		AdditionalInfo:           strings.Join(strings.Fields(s.AddtlStmtInf), " "),
+		PeriodStart:              periodStart,
+		PeriodEnd:                periodEnd,
+	}
+}
+
+// Period returns the first and last day of the statement's reporting period
+// (FrToDt). Both are zero when the statement has no period or either bound
+// is not a valid date and time.
+func (s *Statement) Period() (start, end time.Time) {
+	if s.FrToDt == nil {
+		return time.Time{}, time.Time{}
+	}
+	start, err := dateutils.ParseDateString(s.FrToDt.FrDtTm)
+	if err != nil || start.IsZero() {
+		return time.Time{}, time.Time{}
+	}
+	end, err = dateutils.ParseDateString(s.FrToDt.ToDtTm)
+	if err != nil || end.IsZero() {
+		return time.Time{}, time.Time{}
+	}
+	// Only the dates matter; the time of day is that of the bank's clock
+	return time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC),
+		time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// parseSequenceNumber parses a statement sequence number, returning 0 when it
+// is empty or invalid.
+func parseSequenceNumber(s string) int64 {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
 }
 
 // Period represents a time period in the CAMT.053 format
@@ -76,7 +146,7 @@ type Entry struct {
 	NtryRef      string       `xml:"NtryRef"`
 	Amt          Amount       `xml:"Amt"`
 	CdtDbtInd    string       `xml:"CdtDbtInd"`    // CRDT or DBIT
-	Sts          string       `xml:"Sts"`          // Status (BOOK, etc.)
+	Sts          EntryStatus  `xml:"Sts"`          // Status (BOOK, etc.)
 	BookgDt      EntryDate    `xml:"BookgDt"`      // Booking date
 	ValDt        EntryDate    `xml:"ValDt"`        // Value date
 	AcctSvcrRef  string       `xml:"AcctSvcrRef"`  // Bank reference
@@ -85,9 +155,69 @@ type Entry struct {
 	AddtlNtryInf string       `xml:"AddtlNtryInf"` // Additional entry information
 }
 
-// EntryDate represents a date in ISO20022 format
+// EntryStatus represents an entry status. Up to camt.053.001.04 the code is the
+// element text (<Sts>BOOK</Sts>); from 001.08 it is nested (<Sts><Cd>BOOK</Cd></Sts>).
+type EntryStatus struct {
+	Value string `xml:",chardata"`
+	Cd    string `xml:"Cd"`
+}
+
+// String returns the status code from either layout.
+func (s EntryStatus) String() string {
+	if code := strings.TrimSpace(s.Cd); code != "" {
+		return code
+	}
+	return strings.TrimSpace(s.Value)
+}
+
+// EntryDate represents a date in ISO20022 format. Banks send either a date
+// (Dt) or a date-time (DtTm); camt.053.001.08 exports often use DtTm only.
 type EntryDate struct {
-	Dt string `xml:"Dt"`
+	Dt   string `xml:"Dt"`
+	DtTm string `xml:"DtTm"`
+}
+
+// entryDateTimeLayouts are the accepted DtTm layouts, with and without offset.
+var entryDateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+}
+
+// Time returns Dt, falling back to DtTm when Dt is absent or invalid.
+// The zero time is returned when neither can be parsed.
+func (d EntryDate) Time() time.Time {
+	if t, err := time.Parse("2006-01-02", strings.TrimSpace(d.Dt)); err == nil {
+		return t
+	}
+	dateTime := strings.TrimSpace(d.DtTm)
+	for _, layout := range entryDateTimeLayouts {
+		if t, err := time.Parse(layout, dateTime); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// EntryDates returns the parsed booking and value dates of an entry. Some
+// banks send only one of them; the missing date then takes the value of the
+// other. fallback names the date that was filled in ("booking" or "value"),
+// or is empty when both were present or both missing.
+func EntryDates(booking, value EntryDate) (bookingDate, valueDate time.Time, fallback string) {
+	bookingDate = booking.Time()
+	valueDate = value.Time()
+	switch {
+	case bookingDate.IsZero() && !valueDate.IsZero():
+		return valueDate, valueDate, "booking"
+	case valueDate.IsZero() && !bookingDate.IsZero():
+		return bookingDate, bookingDate, "value"
+	default:
+		return bookingDate, valueDate, ""
+	}
+}
+
+// Dates returns the entry's booking and value dates as described by EntryDates.
+func (e *Entry) Dates() (bookingDate, valueDate time.Time, fallback string) {
+	return EntryDates(e.BookgDt, e.ValDt)
 }
 
 // BankTxCode represents a bank transaction code in the CAMT.053 format
@@ -148,7 +278,8 @@ type References struct {
 // RelatedParties represents parties involved in the transaction in the CAMT.053 format
 type RelatedParties struct {
 	Dbtr struct {
-		Nm      string `xml:"Nm"`
+		Nm      string    `xml:"Nm"`
+		Pty     PartyName `xml:"Pty"` // camt.053.001.08 wraps the name in Pty
 		PstlAdr struct {
 			AdrLine []string `xml:"AdrLine"`
 			StrtNm  string   `xml:"StrtNm"`
@@ -170,7 +301,8 @@ type RelatedParties struct {
 		Nm string `xml:"Nm"`
 	} `xml:"UltmtDbtr"`
 	Cdtr struct {
-		Nm      string `xml:"Nm"`
+		Nm      string    `xml:"Nm"`
+		Pty     PartyName `xml:"Pty"` // camt.053.001.08 wraps the name in Pty
 		PstlAdr struct {
 			AdrLine []string `xml:"AdrLine"`
 			StrtNm  string   `xml:"StrtNm"`
@@ -193,6 +325,32 @@ type RelatedParties struct {
 	} `xml:"UltmtCdtr"`
 }
 
+// PartyName holds a party name nested in Pty. From camt.053.001.08 the debtor
+// and creditor may be a party (Pty) or an agent; ultimate parties stay flat.
+type PartyName struct {
+	Nm string `xml:"Nm"`
+}
+
+// DebtorName returns the debtor name from either the flat or the Pty layout.
+func (p *RelatedParties) DebtorName() string {
+	return firstNonBlank(p.Dbtr.Nm, p.Dbtr.Pty.Nm)
+}
+
+// CreditorName returns the creditor name from either the flat or the Pty layout.
+func (p *RelatedParties) CreditorName() string {
+	return firstNonBlank(p.Cdtr.Nm, p.Cdtr.Pty.Nm)
+}
+
+// firstNonBlank returns the first value that is not empty or whitespace.
+func firstNonBlank(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
 // RelatedAgents represents financial institutions involved in the transaction
 type RelatedAgents struct {
 	DbtrAgt struct {
@@ -235,30 +393,41 @@ func (e *Entry) GetFirstTxDetails() *TransactionDetails {
 	return nil
 }
 
-// GetCreditDebit returns the credit/debit indicator
+// GetCreditDebit returns the credit/debit indicator. Some banks leave it out
+// and give debits a negative amount, so without one it is taken from the sign.
 func (e *Entry) GetCreditDebit() string {
-	return e.CdtDbtInd
+	if e.CdtDbtInd != "" {
+		return e.CdtDbtInd
+	}
+	if strings.HasPrefix(strings.TrimSpace(e.Amt.Value), "-") {
+		return TransactionTypeDebit
+	}
+	return TransactionTypeCredit
 }
 
 // IsCredit returns true if the entry is a credit transaction
 func (e *Entry) IsCredit() bool {
-	return e.CdtDbtInd == TransactionTypeCredit
+	return e.GetCreditDebit() == TransactionTypeCredit
 }
 
 // GetBankTxCode returns the bank transaction code
 func (e *Entry) GetBankTxCode() string {
-	if e.BkTxCd.Domn.Cd != "" {
-		family := e.BkTxCd.Domn.Fmly.Cd
-		subFamily := e.BkTxCd.Domn.Fmly.SubFmlyCd
+	return e.BkTxCd.Code()
+}
+
+// Code returns the code as Domain/Family/SubFamily (e.g. "PMNT/RCDT/ESCT"),
+// the domain alone when the family is incomplete, or the proprietary code
+// when there is no domain.
+func (c BankTxCode) Code() string {
+	if c.Domn.Cd != "" {
+		family := c.Domn.Fmly.Cd
+		subFamily := c.Domn.Fmly.SubFmlyCd
 		if family != "" && subFamily != "" {
-			return e.BkTxCd.Domn.Cd + "/" + family + "/" + subFamily
+			return c.Domn.Cd + "/" + family + "/" + subFamily
 		}
-		return e.BkTxCd.Domn.Cd
-	}
-	if e.BkTxCd.Prtry.Cd != "" {
-		return e.BkTxCd.Prtry.Cd
+		return c.Domn.Cd
 	}
-	return ""
+	return c.Prtry.Cd
 }
 
 // GetRemittanceInfo returns all remittance information
@@ -273,7 +442,7 @@ func (e *Entry) GetRemittanceInfo() string {
 
 // GetPayer returns the payer name
 func (e *Entry) GetPayer() string {
-	if e.CdtDbtInd == TransactionTypeCredit {
+	if e.GetCreditDebit() == TransactionTypeCredit {
 		// For credit transactions (incoming money), debtor is the payer
 		txDetails := e.GetFirstTxDetails()
 		if txDetails != nil {
@@ -282,8 +451,8 @@ func (e *Entry) GetPayer() string {
 				return txDetails.RltdPties.UltmtDbtr.Nm
 			}
 			// Then regular debtor
-			if txDetails.RltdPties.Dbtr.Nm != "" {
-				return txDetails.RltdPties.Dbtr.Nm
+			if name := txDetails.RltdPties.DebtorName(); name != "" {
+				return name
 			}
 			// Try debtor agent (bank)
 			if txDetails.RltdAgts.DbtrAgt.FinInstnID.Nm != "" {
@@ -297,7 +466,7 @@ func (e *Entry) GetPayer() string {
 
 // GetPayee returns the payee name
 func (e *Entry) GetPayee() string {
-	if e.CdtDbtInd == TransactionTypeDebit {
+	if e.GetCreditDebit() == TransactionTypeDebit {
 		// For debit transactions (outgoing money), creditor is the payee
 		txDetails := e.GetFirstTxDetails()
 		if txDetails != nil {
@@ -306,8 +475,8 @@ func (e *Entry) GetPayee() string {
 				return txDetails.RltdPties.UltmtCdtr.Nm
 			}
 			// Then regular creditor
-			if txDetails.RltdPties.Cdtr.Nm != "" {
-				return txDetails.RltdPties.Cdtr.Nm
+			if name := txDetails.RltdPties.CreditorName(); name != "" {
+				return name
 			}
 			// Try creditor agent (bank)
 			if txDetails.RltdAgts.CdtrAgt.FinInstnID.Nm != "" {
@@ -366,7 +535,7 @@ func (e *Entry) GetIBAN() string {
 		return ""
 	}
 
-	if e.CdtDbtInd == TransactionTypeDebit {
+	if e.GetCreditDebit() == TransactionTypeDebit {
 		// For debit transactions, get creditor IBAN
 		if txDetails.RltdPties.CdtrAcct.ID.IBAN != "" {
 			return txDetails.RltdPties.CdtrAcct.ID.IBAN
@@ -1,9 +1,12 @@
 package models
 
 import (
+	"encoding/xml"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestEntry_GetFirstTxDetails(t *testing.T) {
@@ -75,6 +78,20 @@ func TestEntry_GetCreditDebit(t *testing.T) {
 			},
 			expected: TransactionTypeCredit,
 		},
+		{
+			name: "negative amount without indicator",
+			entry: Entry{
+				Amt: Amount{Value: "-85.40"},
+			},
+			expected: TransactionTypeDebit,
+		},
+		{
+			name: "positive amount without indicator",
+			entry: Entry{
+				Amt: Amount{Value: "85.40"},
+			},
+			expected: TransactionTypeCredit,
+		},
 	}
 
 	for _, tt := range tests {
@@ -291,7 +308,8 @@ func TestEntry_GetPayer(t *testing.T) {
 						{
 							RltdPties: RelatedParties{
 								Dbtr: struct {
-									Nm      string `xml:"Nm"`
+									Nm      string    `xml:"Nm"`
+									Pty     PartyName `xml:"Pty"`
 									PstlAdr struct {
 										AdrLine []string `xml:"AdrLine"`
 										StrtNm  string   `xml:"StrtNm"`
@@ -408,7 +426,8 @@ func TestEntry_GetPayee(t *testing.T) {
 						{
 							RltdPties: RelatedParties{
 								Cdtr: struct {
-									Nm      string `xml:"Nm"`
+									Nm      string    `xml:"Nm"`
+									Pty     PartyName `xml:"Pty"`
 									PstlAdr struct {
 										AdrLine []string `xml:"AdrLine"`
 										StrtNm  string   `xml:"StrtNm"`
@@ -822,3 +841,121 @@ func TestEntry_BuildDescription(t *testing.T) {
 		})
 	}
 }
+
+func TestEntryDate_Time(t *testing.T) {
+	tests := []struct {
+		name string
+		date EntryDate
+		want string
+	}{
+		{"date", EntryDate{Dt: "2025-01-15"}, "2025-01-15T00:00:00Z"},
+		{"date time with offset", EntryDate{DtTm: "2025-01-15T10:30:00+01:00"}, "2025-01-15T10:30:00+01:00"},
+		{"date time with fraction", EntryDate{DtTm: "2025-01-25T08:00:00.000+01:00"}, "2025-01-25T08:00:00+01:00"},
+		{"local date time", EntryDate{DtTm: "2025-01-25T00:00:00"}, "2025-01-25T00:00:00Z"},
+		{"empty", EntryDate{}, "0001-01-01T00:00:00Z"},
+		{"invalid", EntryDate{Dt: "15.01.2025"}, "0001-01-01T00:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.date.Time().Format("2006-01-02T15:04:05Z07:00"))
+		})
+	}
+}
+
+func TestEntryStatus_String(t *testing.T) {
+	assert.Equal(t, "BOOK", EntryStatus{Value: " BOOK "}.String())
+	assert.Equal(t, "PDNG", EntryStatus{Cd: "PDNG"}.String())
+	assert.Equal(t, "", EntryStatus{}.String())
+}
+
+func TestEntryDates(t *testing.T) {
+	booking := EntryDate{Dt: "2025-02-20"}
+	value := EntryDate{DtTm: "2025-02-21T00:00:00"}
+
+	b, v, fallback := EntryDates(booking, value)
+	assert.Equal(t, "2025-02-20", b.Format("2006-01-02"))
+	assert.Equal(t, "2025-02-21", v.Format("2006-01-02"))
+	assert.Empty(t, fallback)
+
+	b, v, fallback = EntryDates(EntryDate{}, value)
+	assert.True(t, b.Equal(v))
+	assert.Equal(t, "2025-02-21", b.Format("2006-01-02"))
+	assert.Equal(t, "booking", fallback)
+
+	b, v, fallback = EntryDates(booking, EntryDate{})
+	assert.True(t, v.Equal(b))
+	assert.Equal(t, "value", fallback)
+
+	b, v, fallback = EntryDates(EntryDate{}, EntryDate{})
+	assert.True(t, b.IsZero())
+	assert.True(t, v.IsZero())
+	assert.Empty(t, fallback)
+
+	entry := Entry{BookgDt: booking}
+	_, v, fallback = entry.Dates()
+	assert.Equal(t, "2025-02-20", v.Format("2006-01-02"))
+	assert.Equal(t, "value", fallback)
+}
+
+func TestStatement_Info(t *testing.T) {
+	var doc ISO20022Document
+	err := xml.Unmarshal([]byte(`<Document><BkToCstmrStmt><Stmt>
+		<Id>STMT-7</Id>
+		<ElctrncSeqNb>42</ElctrncSeqNb>
+		<LglSeqNb> 7 </LglSeqNb>
+		<CreDtTm>2025-03-01T06:00:00</CreDtTm>
+		<Acct><Id><Othr><Id>123-456</Id></Othr></Id></Acct>
+	</Stmt></BkToCstmrStmt></Document>`), &doc)
+	require.NoError(t, err)
+
+	info := doc.BkToCstmrStmt.Stmt[0].Info()
+	assert.Equal(t, StatementInfo{
+		ID:                       "STMT-7",
+		AccountID:                "123-456",
+		CreatedAt:                "2025-03-01T06:00:00",
+		ElectronicSequenceNumber: 42,
+		LegalSequenceNumber:      7,
+	}, info)
+
+	// Missing or invalid sequence numbers are 0
+	stmt := Statement{ElctrncSeqNb: "abc"}
+	assert.Equal(t, int64(0), stmt.Info().ElectronicSequenceNumber)
+	assert.Equal(t, int64(0), stmt.Info().LegalSequenceNumber)
+}
+
+func TestStatement_Period(t *testing.T) {
+	stmt := Statement{FrToDt: &Period{FrDtTm: "2025-06-01T00:00:00+02:00", ToDtTm: "2025-06-30T23:59:59.999+02:00"}}
+	start, end := stmt.Period()
+	assert.Equal(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC), end)
+
+	info := stmt.Info()
+	assert.Equal(t, start, info.PeriodStart)
+	assert.Equal(t, end, info.PeriodEnd)
+
+	// No period, or an invalid bound, leaves both dates zero
+	for _, stmt := range []Statement{{}, {FrToDt: &Period{FrDtTm: "2025-06-01T00:00:00", ToDtTm: "end"}}} {
+		start, end := stmt.Period()
+		assert.True(t, start.IsZero())
+		assert.True(t, end.IsZero())
+	}
+}
+
+func TestStatementNotes(t *testing.T) {
+	transactions := []Transaction{
+		{StatementNote: "Corrected statement"},
+		{},
+		{StatementNote: "Corrected statement"},
+		{StatementNote: "Interest rate change"},
+	}
+	assert.Equal(t, []string{"Corrected statement", "Interest rate change"}, StatementNotes(transactions))
+	assert.Equal(t, "# Statement note: Corrected statement", StatementNoteComment("Corrected\n  statement"))
+}
+
+func TestStripCSVComments(t *testing.T) {
+	data := []byte("# Statement note: one\n# Statement note: two\nDate,Amount\n01.07.2025,#1\n")
+	assert.Equal(t, "Date,Amount\n01.07.2025,#1\n", string(StripCSVComments(data)))
+	assert.Equal(t, "Date\n", string(StripCSVComments([]byte("Date\n"))))
+	assert.Empty(t, StripCSVComments([]byte("# only a comment")))
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Locale controls how numbers and dates are written in the standard CSV
+// layout. The zero value is the default formatting: a dot decimal separator
+// and DD.MM.YYYY dates.
+type Locale struct {
+	Name             string
+	DecimalSeparator string // "." or ","
+	DateLayout       string // Go layout of the date columns, without time of day
+}
+
+// locales lists the supported locales by lower-cased name.
+var locales = map[string]Locale{
+	"de-at": {Name: "de-AT", DecimalSeparator: ",", DateLayout: "02.01.2006"},
+	"de-ch": {Name: "de-CH", DecimalSeparator: ".", DateLayout: "02.01.2006"},
+	"de-de": {Name: "de-DE", DecimalSeparator: ",", DateLayout: "02.01.2006"},
+	"en-gb": {Name: "en-GB", DecimalSeparator: ".", DateLayout: "02/01/2006"},
+	"en-us": {Name: "en-US", DecimalSeparator: ".", DateLayout: "01/02/2006"},
+	"fr-ch": {Name: "fr-CH", DecimalSeparator: ",", DateLayout: "02.01.2006"},
+	"fr-fr": {Name: "fr-FR", DecimalSeparator: ",", DateLayout: "02/01/2006"},
+	"it-ch": {Name: "it-CH", DecimalSeparator: ".", DateLayout: "02.01.2006"},
+}
+
+// LookupLocale returns the locale named name (e.g. "de-CH"; case and "_"
+// instead of "-" are accepted). An empty name gives the default formatting.
+func LookupLocale(name string) (Locale, error) {
+	if name == "" {
+		return Locale{}, nil
+	}
+	locale, ok := locales[strings.ToLower(strings.ReplaceAll(name, "_", "-"))]
+	if !ok {
+		return Locale{}, fmt.Errorf("unknown locale %q (supported: %s)", name, strings.Join(LocaleNames(), ", "))
+	}
+	return locale, nil
+}
+
+// LocaleNames returns the names of the supported locales, sorted.
+func LocaleNames() []string {
+	names := make([]string, 0, len(locales))
+	for _, l := range locales {
+		names = append(names, l.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// decimalSeparator returns the locale's decimal separator, "." by default.
+func (l Locale) decimalSeparator() string {
+	if l.DecimalSeparator == "" {
+		return "."
+	}
+	return l.DecimalSeparator
+}
+
+// dateLayout returns the layout of the date columns, with the time of day
+// when includeTime is set.
+func (l Locale) dateLayout(includeTime bool) string {
+	layout := l.DateLayout
+	if layout == "" {
+		layout = DateFormatCSV
+	}
+	if includeTime {
+		layout += " 15:04"
+	}
+	return layout
+}
+
+// formatNumber replaces the decimal point of a formatted number with the
+// locale's separator.
+func (l Locale) formatNumber(s string) string {
+	return strings.Replace(s, ".", l.decimalSeparator(), 1)
+}
+
+// isDefault reports whether l formats like the zero Locale.
+func (l Locale) isDefault() bool {
+	return l.decimalSeparator() == "." && l.dateLayout(false) == DateFormatCSV
+}
+
+// standardCSVNumberColumns are the StandardCSVHeader columns holding decimals.
+var standardCSVNumberColumns = []string{"Amount", "AmountExclTax", "TaxRate", "Fees", "OriginalAmount", "ExchangeRate"}
+
+// standardCSVDateColumns are the StandardCSVHeader columns holding dates.
+var standardCSVDateColumns = []string{"Date", "ValueDate"}
+
+// normalizeRecord returns a copy of a full standard CSV record written with
+// locale l, with its numbers and dates in the default formatting.
+func (l Locale) normalizeRecord(record []string) ([]string, error) {
+	normalized := append([]string(nil), record...)
+	for _, column := range standardCSVNumberColumns {
+		i := standardCSVColumnIndex[column]
+		normalized[i] = strings.Replace(normalized[i], l.decimalSeparator(), ".", 1)
+	}
+	for _, column := range standardCSVDateColumns {
+		i := standardCSVColumnIndex[column]
+		if normalized[i] == "" {
+			continue
+		}
+		withTime := strings.Contains(normalized[i], " ")
+		date, err := time.Parse(l.dateLayout(withTime), normalized[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", column, err)
+		}
+		normalized[i] = date.Format(Locale{}.dateLayout(withTime))
+	}
+	return normalized, nil
+}
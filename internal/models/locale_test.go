@@ -0,0 +1,86 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupLocale(t *testing.T) {
+	locale, err := LookupLocale("de_de")
+	require.NoError(t, err)
+	assert.Equal(t, "de-DE", locale.Name)
+	assert.Equal(t, ",", locale.DecimalSeparator)
+
+	locale, err = LookupLocale("")
+	require.NoError(t, err)
+	assert.True(t, locale.isDefault())
+
+	_, err = LookupLocale("xx-YY")
+	assert.ErrorContains(t, err, `unknown locale "xx-YY"`)
+	assert.Contains(t, LocaleNames(), "en-US")
+}
+
+func TestTransaction_LocaleRoundTrip(t *testing.T) {
+	tx := Transaction{
+		Date:        time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC),
+		ValueDate:   time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC),
+		Amount:      decimal.NewFromFloat(1234.5),
+		Fees:        decimal.NewFromFloat(0.75),
+		CreditDebit: TransactionTypeDebit,
+		DebitFlag:   true,
+		Currency:    "EUR",
+	}
+
+	tests := []struct {
+		name         string
+		locale       string
+		opts         CSVOptions
+		expectedDate string
+		expectedAmt  string
+	}{
+		{"de-DE", "de-DE", CSVOptions{}, "14.03.2025", "1234,50"},
+		{"en-US", "en-US", CSVOptions{}, "03/14/2025", "1234.50"},
+		{"fr-FR signed with time", "fr-FR", CSVOptions{SignedAmount: true, IncludeTime: true}, "14/03/2025 09:30", "-1234,50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locale, err := LookupLocale(tt.locale)
+			require.NoError(t, err)
+			opts := tt.opts
+			opts.Locale = locale
+
+			record, err := tx.MarshalCSVWithOptions(opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedDate, record[1])
+			assert.Equal(t, tt.expectedAmt, record[8])
+
+			var restored Transaction
+			require.NoError(t, restored.UnmarshalCSVWithOptions(record, opts))
+			assert.True(t, restored.Amount.Abs().Equal(tx.Amount), restored.Amount.String())
+			assert.True(t, restored.Fees.Equal(tx.Fees))
+			assert.Equal(t, tx.ValueDate, restored.ValueDate)
+			assert.Equal(t, TransactionTypeDebit, restored.CreditDebit)
+		})
+	}
+
+	t.Run("default formatting unchanged", func(t *testing.T) {
+		record, err := tx.MarshalCSVWithOptions(CSVOptions{Locale: Locale{}})
+		require.NoError(t, err)
+		assert.Equal(t, "14.03.2025", record[1])
+		assert.Equal(t, "1234.50", record[8])
+	})
+
+	t.Run("date in the wrong layout", func(t *testing.T) {
+		record, err := tx.MarshalCSV()
+		require.NoError(t, err)
+		record[1] = "2025-03-14"
+		locale, _ := LookupLocale("en-US")
+		var restored Transaction
+		assert.ErrorContains(t, restored.UnmarshalCSVWithOptions(record, CSVOptions{Locale: locale}), "failed to parse Date")
+	})
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// mccPattern matches a merchant category code as printed on statements:
+// "MCC" followed by the four-digit ISO 18245 code, as in "MCC 5411" or
+// "MCC:5812".
+var mccPattern = regexp.MustCompile(`(?i)\bMCC\s*:?\s*(\d{4})\b`)
+
+// MCCConfig represents the structure of the MCC mapping file, which maps
+// merchant category codes to categories:
+//
+//	mcc:
+//	  "5411": Alimentation
+//	  "5812": Restaurants
+type MCCConfig struct {
+	MCC map[string]string `yaml:"mcc"`
+}
+
+// ExtractMCC returns the first merchant category code in text, and text
+// without it, or "" and text unchanged when there is none.
+func ExtractMCC(text string) (mcc, rest string) {
+	loc := mccPattern.FindStringSubmatchIndex(text)
+	if loc == nil {
+		return "", text
+	}
+	rest = strings.Join(strings.Fields(text[:loc[0]]+" "+text[loc[1]:]), " ")
+	return text[loc[2]:loc[3]], rest
+}
+
+// MCCCategorizer is implemented by categorizers that map merchant category
+// codes to categories. The code classifies card spend more reliably than the
+// merchant name, so it is consulted before the name-based rules.
+type MCCCategorizer interface {
+	// CategoryForMCC returns the category mapped to mcc, if any.
+	CategoryForMCC(mcc string) (string, bool)
+}
+
+// ApplyMCC sets the category of tx from its merchant category code when
+// categorizer implements MCCCategorizer and maps the code. It returns true
+// when tx was categorized, in which case it needs no further categorization.
+func ApplyMCC(tx *Transaction, categorizer TransactionCategorizer) bool {
+	matcher, ok := categorizer.(MCCCategorizer)
+	if !ok || tx.MCC == "" {
+		return false
+	}
+	category, ok := matcher.CategoryForMCC(tx.MCC)
+	if !ok {
+		return false
+	}
+	tx.Category = category
+	tx.CategorySource = CategorySourceMCC
+	auditCategorization(tx, categorizer)
+	return true
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractMCC(t *testing.T) {
+	tests := []struct {
+		text, mcc, rest string
+	}{
+		{"Migros Lausanne MCC 5411", "5411", "Migros Lausanne"},
+		{"Café du Commerce MCC:5812 Pully", "5812", "Café du Commerce Pully"},
+		{"mcc 4111", "4111", ""},
+		{"Migros Lausanne", "", "Migros Lausanne"},
+		{"MCC 541", "", "MCC 541"},
+		{"Order 15411", "", "Order 15411"},
+	}
+	for _, tt := range tests {
+		mcc, rest := ExtractMCC(tt.text)
+		assert.Equal(t, tt.mcc, mcc, tt.text)
+		assert.Equal(t, tt.rest, rest, tt.text)
+	}
+}
+
+// mccCategorizer maps merchant category codes and categorizes nothing by name.
+type mccCategorizer map[string]string
+
+func (m mccCategorizer) Categorize(context.Context, string, bool, string, string, string) (Category, error) {
+	return Category{Name: CategoryUncategorized}, nil
+}
+
+func (m mccCategorizer) CategoryForMCC(mcc string) (string, bool) {
+	category, ok := m[mcc]
+	return category, ok
+}
+
+func TestApplyMCC(t *testing.T) {
+	categorizer := mccCategorizer{"5411": "Alimentation", "5812": "Restaurants"}
+
+	groceries := Transaction{PartyName: "Migros", MCC: "5411"}
+	assert.True(t, ApplyMCC(&groceries, categorizer))
+	assert.Equal(t, "Alimentation", groceries.Category)
+	assert.Equal(t, CategorySourceMCC, groceries.CategorySource)
+
+	restaurant := Transaction{PartyName: "Café du Commerce", MCC: "5812"}
+	assert.True(t, ApplyMCC(&restaurant, categorizer))
+	assert.Equal(t, "Restaurants", restaurant.Category)
+
+	unmapped := Transaction{PartyName: "SBB", MCC: "4111"}
+	assert.False(t, ApplyMCC(&unmapped, categorizer))
+	assert.Empty(t, unmapped.Category)
+
+	noCode := Transaction{PartyName: "Migros"}
+	assert.False(t, ApplyMCC(&noCode, categorizer))
+	assert.False(t, ApplyMCC(&groceries, nil), "a categorizer without MCC mappings is not consulted")
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// NumberFormat tells which separators an amount is written with.
+type NumberFormat string
+
+// Number formats of amounts in input files.
+const (
+	// NumberFormatAuto takes the last '.' or ',' as the decimal separator,
+	// unless it appears several times
+	NumberFormatAuto NumberFormat = "auto"
+	// NumberFormatDot is a decimal point with comma thousands: 1,234.56
+	NumberFormatDot NumberFormat = "dot"
+	// NumberFormatComma is a decimal comma with dot thousands: 1.234,56
+	NumberFormatComma NumberFormat = "comma"
+)
+
+// ParseNumberFormat returns the number format named name; an empty name is
+// NumberFormatAuto.
+func ParseNumberFormat(name string) (NumberFormat, error) {
+	switch format := NumberFormat(strings.ToLower(strings.TrimSpace(name))); format {
+	case "":
+		return NumberFormatAuto, nil
+	case NumberFormatAuto, NumberFormatDot, NumberFormatComma:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported number format %q (supported: %s, %s, %s)",
+			name, NumberFormatAuto, NumberFormatDot, NumberFormatComma)
+	}
+}
+
+// ParseAmountWithFormat parses amountStr written in format. Currency symbols,
+// spaces and Swiss apostrophe thousand separators are ignored, as in
+// ParseAmountChecked, which reads "1.234,56" as a malformed number.
+func ParseAmountWithFormat(amountStr string, format NumberFormat) (decimal.Decimal, error) {
+	amount := strings.TrimSpace(amountStr)
+	for _, symbol := range []string{" ", "'", "CHF", "EUR", "USD", "$", "€"} {
+		amount = strings.ReplaceAll(amount, symbol, "")
+	}
+
+	var decimalSep, thousandsSep string
+	switch format {
+	case NumberFormatDot:
+		decimalSep, thousandsSep = ".", ","
+	case NumberFormatComma:
+		decimalSep, thousandsSep = ",", "."
+	default:
+		decimalSep, thousandsSep = detectDecimalSeparator(amount)
+	}
+
+	// Thousands come before the decimals: "1,234.56" is not a decimal comma
+	if decimalAt := strings.Index(amount, decimalSep); decimalAt >= 0 &&
+		(strings.Count(amount, decimalSep) > 1 || strings.LastIndex(amount, thousandsSep) > decimalAt) {
+		return decimal.Zero, fmt.Errorf("invalid amount %q: separators do not match the %s number format", amountStr, format)
+	}
+
+	amount = strings.ReplaceAll(amount, thousandsSep, "")
+	amount = strings.Replace(amount, decimalSep, ".", 1)
+
+	dec, err := decimal.NewFromString(amount)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid amount %q: %w", amountStr, err)
+	}
+	return dec, nil
+}
+
+// detectDecimalSeparator returns the decimal and thousands separators of
+// amount: the last '.' or ',' is the decimal separator, unless it appears
+// more than once, in which case it separates thousands.
+func detectDecimalSeparator(amount string) (decimalSep, thousandsSep string) {
+	last := strings.LastIndexAny(amount, ".,")
+	if last < 0 {
+		return ".", ","
+	}
+	sep := amount[last : last+1]
+	other := ","
+	if sep == "," {
+		other = "."
+	}
+	if strings.Count(amount, sep) > 1 {
+		return other, sep
+	}
+	return sep, other
+}
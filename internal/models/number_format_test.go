@@ -0,0 +1,57 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAmountWithFormat(t *testing.T) {
+	tests := []struct {
+		amount string
+		format NumberFormat
+		want   string
+	}{
+		{"1.234,56", NumberFormatAuto, "1234.56"},
+		{"1,234.56", NumberFormatAuto, "1234.56"},
+		{"1234.56", NumberFormatAuto, "1234.56"},
+		{"-1.234,56", NumberFormatAuto, "-1234.56"},
+		{"12,50", NumberFormatAuto, "12.5"},
+		{"1.234.567", NumberFormatAuto, "1234567"},
+		{"1'234.56 CHF", NumberFormatAuto, "1234.56"},
+		{"1.234,56", NumberFormatComma, "1234.56"},
+		{"1234,56", NumberFormatComma, "1234.56"},
+		{"1,234.56", NumberFormatDot, "1234.56"},
+		{"1234.56", NumberFormatDot, "1234.56"},
+		// The convention wins over detection: a lone comma separates thousands
+		{"1,234", NumberFormatDot, "1234"},
+		{"1,234", NumberFormatAuto, "1.234"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format)+" "+tt.amount, func(t *testing.T) {
+			got, err := ParseAmountWithFormat(tt.amount, tt.format)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+
+	_, err := ParseAmountWithFormat("1,234.56", NumberFormatComma)
+	assert.ErrorContains(t, err, `invalid amount "1,234.56"`)
+}
+
+func TestParseNumberFormat(t *testing.T) {
+	for name, want := range map[string]NumberFormat{
+		"":      NumberFormatAuto,
+		"auto":  NumberFormatAuto,
+		"Dot":   NumberFormatDot,
+		"comma": NumberFormatComma,
+	} {
+		got, err := ParseNumberFormat(name)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	_, err := ParseNumberFormat("swiss")
+	assert.ErrorContains(t, err, "unsupported number format")
+}
@@ -0,0 +1,30 @@
+package models
+
+// CategoryOverrider is implemented by categorizers given per-run category
+// overrides of party names, such as those of the --map flag. An override is
+// an explicit correction for the run, so it is consulted before any other
+// categorization, transfers between own accounts included.
+type CategoryOverrider interface {
+	// CategoryOverride returns the category party is forced into, if any.
+	CategoryOverride(party string) (string, bool)
+}
+
+// ApplyCategoryOverride sets the category of tx from the override of its
+// party name when categorizer implements CategoryOverrider and has one. It
+// returns true when tx was categorized, in which case it needs no further
+// categorization. Overridden categories show as override in the
+// CategorySource column.
+func ApplyCategoryOverride(tx *Transaction, categorizer TransactionCategorizer) bool {
+	overrider, ok := categorizer.(CategoryOverrider)
+	if !ok || tx.PartyName == "" {
+		return false
+	}
+	category, ok := overrider.CategoryOverride(tx.PartyName)
+	if !ok {
+		return false
+	}
+	tx.Category = category
+	tx.CategorySource = CategorySourceOverride
+	auditCategorization(tx, categorizer)
+	return true
+}
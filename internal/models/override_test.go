@@ -0,0 +1,39 @@
+package models
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// overrideCategorizer forces lowercased party names into categories and
+// categorizes nothing else.
+type overrideCategorizer map[string]string
+
+func (m overrideCategorizer) Categorize(context.Context, string, bool, string, string, string) (Category, error) {
+	return Category{Name: CategoryUncategorized}, nil
+}
+
+func (m overrideCategorizer) CategoryOverride(party string) (string, bool) {
+	category, ok := m[strings.ToLower(party)]
+	return category, ok
+}
+
+func TestApplyCategoryOverride(t *testing.T) {
+	categorizer := overrideCategorizer{"migros": "Loisirs"}
+
+	tx := Transaction{PartyName: "MIGROS", Category: "Alimentation", CategorySource: CategorySourceMCC}
+	assert.True(t, ApplyCategoryOverride(&tx, categorizer))
+	assert.Equal(t, "Loisirs", tx.Category)
+	assert.Equal(t, CategorySourceOverride, tx.CategorySource)
+
+	other := Transaction{PartyName: "Coop"}
+	assert.False(t, ApplyCategoryOverride(&other, categorizer))
+	assert.Empty(t, other.Category)
+
+	noParty := Transaction{Description: "Migros"}
+	assert.False(t, ApplyCategoryOverride(&noParty, categorizer))
+	assert.False(t, ApplyCategoryOverride(&tx, nil), "a categorizer without overrides is not consulted")
+}
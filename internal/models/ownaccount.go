@@ -0,0 +1,63 @@
+package models
+
+import "strings"
+
+// OwnAccountMatcher is implemented by categorizers that know the IBANs of the
+// user's own accounts, so that transfers between them are recognized whatever
+// the party name says.
+type OwnAccountMatcher interface {
+	// IsOwnAccount reports whether iban is one of the user's own accounts.
+	IsOwnAccount(iban string) bool
+
+	// InternalTransferCategory returns the category of transfers between own accounts.
+	InternalTransferCategory() string
+}
+
+// NormalizeIBAN removes spaces and uppercases iban, so that printed and
+// electronic forms of an IBAN compare equal.
+func NormalizeIBAN(iban string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(iban), ""))
+}
+
+// ApplyOwnAccounts handles transactions whose counterparty IBAN is one of the
+// user's own accounts, when categorizer implements OwnAccountMatcher. It
+// returns true when tx was categorized as an internal transfer, in which case
+// it needs no further categorization.
+//
+// Directions follow the statement's account. When that account is known and
+// is not one of the user's, the transaction was seen from the other side: its
+// direction is reversed and the IBANs swapped, so that it reads from the
+// user's account. It is then not an internal transfer and is left to the
+// categorizer.
+func ApplyOwnAccounts(tx *Transaction, categorizer TransactionCategorizer) bool {
+	matcher, ok := categorizer.(OwnAccountMatcher)
+	if !ok || tx.PartyIBAN == "" || !matcher.IsOwnAccount(tx.PartyIBAN) {
+		return false
+	}
+
+	if tx.IBAN != "" && !matcher.IsOwnAccount(tx.IBAN) {
+		tx.reverseDirection()
+		tx.IBAN, tx.PartyIBAN = tx.PartyIBAN, tx.IBAN
+		return false
+	}
+
+	tx.Category = matcher.InternalTransferCategory()
+	tx.CategorySource = CategorySourceInternal
+	auditCategorization(tx, categorizer)
+	return true
+}
+
+// reverseDirection turns a debit into a credit and the other way round.
+func (t *Transaction) reverseDirection() {
+	debit := t.IsDebit()
+	t.Amount = t.Amount.Abs()
+	if debit {
+		t.CreditDebit = TransactionTypeCredit
+	} else {
+		t.Amount = t.Amount.Neg()
+		t.CreditDebit = TransactionTypeDebit
+	}
+	t.DebitFlag = !debit
+	t.Payee, t.Payer = t.Payer, t.Payee
+	t.UpdateDebitCreditAmounts()
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// ownAccounts is a categorizer that knows the user's own IBANs.
+type ownAccounts map[string]bool
+
+func (o ownAccounts) Categorize(context.Context, string, bool, string, string, string) (Category, error) {
+	return Category{}, nil
+}
+
+func (o ownAccounts) IsOwnAccount(iban string) bool {
+	return o[NormalizeIBAN(iban)]
+}
+
+func (o ownAccounts) InternalTransferCategory() string {
+	return CategoryTransfers
+}
+
+func TestApplyOwnAccounts(t *testing.T) {
+	own := ownAccounts{"CH9300762011623852957": true, "CH5604835012345678009": true}
+	debit := func(iban, partyIBAN string) Transaction {
+		tx := Transaction{
+			IBAN:        iban,
+			PartyIBAN:   partyIBAN,
+			PartyName:   "Savings",
+			Payee:       "Savings",
+			Amount:      decimal.RequireFromString("-500"),
+			CreditDebit: TransactionTypeDebit,
+			DebitFlag:   true,
+		}
+		tx.UpdateDebitCreditAmounts()
+		return tx
+	}
+
+	// Between two own accounts: an internal transfer, direction unchanged
+	tx := debit("CH9300762011623852957", "CH56 0483 5012 3456 7800 9")
+	assert.True(t, ApplyOwnAccounts(&tx, own))
+	assert.Equal(t, CategoryTransfers, tx.Category)
+	assert.Equal(t, CategorySourceInternal, tx.CategorySource)
+	assert.True(t, tx.IsDebit())
+
+	// Seen from an account that is not the user's: reversed to read from the user's account
+	tx = debit("CH4431999123000889012", "CH5604835012345678009")
+	assert.False(t, ApplyOwnAccounts(&tx, own))
+	assert.True(t, tx.IsCredit())
+	assert.Equal(t, "500", tx.Amount.String())
+	assert.True(t, tx.Credit.Equal(decimal.NewFromInt(500)))
+	assert.True(t, tx.Debit.IsZero())
+	assert.Equal(t, "CH5604835012345678009", tx.IBAN)
+	assert.Equal(t, "CH4431999123000889012", tx.PartyIBAN)
+	assert.Equal(t, "Savings", tx.Payer)
+	assert.Empty(t, tx.Category)
+
+	// Third-party counterparty, unknown IBAN or no own accounts: unchanged
+	for _, tc := range []struct {
+		tx          Transaction
+		categorizer TransactionCategorizer
+	}{
+		{debit("CH9300762011623852957", "CH4431999123000889012"), own},
+		{debit("CH9300762011623852957", ""), own},
+		{debit("CH9300762011623852957", "CH5604835012345678009"), nil},
+	} {
+		before := tc.tx
+		assert.False(t, ApplyOwnAccounts(&tc.tx, tc.categorizer))
+		assert.Equal(t, before, tc.tx)
+	}
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// defaultCurrencyPrecision lists the currencies whose amounts do not use
+// DecimalPlaces decimal places.
+var defaultCurrencyPrecision = map[string]int32{
+	"JPY": 0,
+	"KRW": 0,
+	"ISK": 0,
+	"BHD": 3,
+	"KWD": 3,
+	"OMR": 3,
+}
+
+var (
+	precisionMu       sync.RWMutex
+	currencyPrecision = copyPrecision(defaultCurrencyPrecision)
+)
+
+func copyPrecision(m map[string]int32) map[string]int32 {
+	c := make(map[string]int32, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// SetCurrencyPrecision sets the number of decimal places of the given
+// currencies, on top of the built-in table (e.g. {"USD": 4}). Codes are case
+// insensitive. A nil or empty map restores the built-in table.
+func SetCurrencyPrecision(overrides map[string]int) error {
+	precision := copyPrecision(defaultCurrencyPrecision)
+	for code, places := range overrides {
+		if places < 0 || places > 8 {
+			return fmt.Errorf("invalid precision %d for currency %s (must be 0-8)", places, code)
+		}
+		precision[strings.ToUpper(strings.TrimSpace(code))] = int32(places) // #nosec G115 -- bounded above
+	}
+
+	precisionMu.Lock()
+	currencyPrecision = precision
+	precisionMu.Unlock()
+	return nil
+}
+
+// AmountPrecision returns the number of decimal places used for amounts in
+// currency, DecimalPlaces unless the currency has an entry in the table.
+func AmountPrecision(currency string) int32 {
+	precisionMu.RLock()
+	defer precisionMu.RUnlock()
+	if places, ok := currencyPrecision[strings.ToUpper(currency)]; ok {
+		return places
+	}
+	return DecimalPlaces
+}
+
+// FormatAmount formats amount with the precision of currency, e.g. "1500"
+// for JPY and "12.50" for CHF.
+func FormatAmount(amount decimal.Decimal, currency string) string {
+	return amount.StringFixed(AmountPrecision(currency))
+}
+
+// FormatRate formats an exchange rate with RateDecimalPlaces decimal places.
+// A zero rate means there is none and keeps the plain "0.00".
+func FormatRate(rate decimal.Decimal) string {
+	if rate.IsZero() {
+		return rate.StringFixed(DecimalPlaces)
+	}
+	return rate.StringFixed(RateDecimalPlaces)
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAmountPrecision(t *testing.T) {
+	assert.Equal(t, int32(0), AmountPrecision("JPY"))
+	assert.Equal(t, int32(0), AmountPrecision("jpy"))
+	assert.Equal(t, int32(3), AmountPrecision("BHD"))
+	assert.Equal(t, int32(DecimalPlaces), AmountPrecision("CHF"))
+	assert.Equal(t, int32(DecimalPlaces), AmountPrecision(""))
+}
+
+func TestFormatRate(t *testing.T) {
+	assert.Equal(t, "0.9412", FormatRate(decimal.RequireFromString("0.941234")))
+	assert.Equal(t, "1.1000", FormatRate(decimal.RequireFromString("1.1")))
+	assert.Equal(t, "0.00", FormatRate(decimal.Zero))
+}
+
+func TestSetCurrencyPrecision(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, SetCurrencyPrecision(nil)) })
+
+	// Viper lowercases map keys read from the config file
+	require.NoError(t, SetCurrencyPrecision(map[string]int{"usd": 4, "jpy": 2}))
+	assert.Equal(t, "1.2346", FormatAmount(decimal.RequireFromString("1.23456"), "USD"))
+	assert.Equal(t, "1500.00", FormatAmount(decimal.NewFromInt(1500), "JPY"))
+	assert.Equal(t, int32(0), AmountPrecision("KRW"))
+
+	assert.Error(t, SetCurrencyPrecision(map[string]int{"EUR": -1}))
+	assert.Equal(t, int32(4), AmountPrecision("USD"), "a rejected table leaves the current one in place")
+
+	require.NoError(t, SetCurrencyPrecision(nil))
+	assert.Equal(t, int32(DecimalPlaces), AmountPrecision("USD"))
+	assert.Equal(t, int32(0), AmountPrecision("JPY"))
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BankPreset is a named bundle of the settings needed to convert one bank's
+// statements, defined in the presets file and selected with --preset: the
+// parser, how input amounts and text are read, the output locale and the
+// party-name cleanup rules.
+type BankPreset struct {
+	Name          string            `yaml:"name"`
+	Description   string            `yaml:"description,omitempty"`
+	Parser        string            `yaml:"parser"`                   // Registered parser name, e.g. camt or pdf
+	NumberFormat  string            `yaml:"number_format,omitempty"`  // auto, dot or comma
+	InputEncoding string            `yaml:"input_encoding,omitempty"` // As for --input-encoding
+	Locale        string            `yaml:"locale,omitempty"`         // As for --locale
+	Cleanup       []NameCleanupRule `yaml:"cleanup,omitempty"`        // Replace the cleanup file's rules when set
+}
+
+// BankPresetsConfig represents the structure of the presets YAML file
+type BankPresetsConfig struct {
+	Presets []BankPreset `yaml:"presets"`
+}
+
+// FindBankPreset returns the preset called name (case-insensitive) from
+// presets, checked with ValidateBankPreset.
+func FindBankPreset(presets []BankPreset, name string) (BankPreset, error) {
+	for _, p := range presets {
+		if strings.EqualFold(p.Name, strings.TrimSpace(name)) {
+			return p, ValidateBankPreset(p)
+		}
+	}
+	names := make([]string, 0, len(presets))
+	for _, p := range presets {
+		names = append(names, p.Name)
+	}
+	if len(names) == 0 {
+		return BankPreset{}, fmt.Errorf("bank preset not found: %s (the presets file defines none)", name)
+	}
+	return BankPreset{}, fmt.Errorf("bank preset not found: %s (available: %s)", name, strings.Join(names, ", "))
+}
+
+// ValidateBankPreset checks that p names a parser, and that its number
+// format, locale and cleanup rules are valid. Whether the parser and input
+// encoding exist is checked where they are used.
+func ValidateBankPreset(p BankPreset) error {
+	if strings.TrimSpace(p.Parser) == "" {
+		return fmt.Errorf("bank preset %q has no parser", p.Name)
+	}
+	if _, err := ParseNumberFormat(p.NumberFormat); err != nil {
+		return fmt.Errorf("bank preset %q: %w", p.Name, err)
+	}
+	if _, err := LookupLocale(p.Locale); err != nil {
+		return fmt.Errorf("bank preset %q: %w", p.Name, err)
+	}
+	if _, err := NewNameCleaner(p.Cleanup); err != nil {
+		return fmt.Errorf("bank preset %q: %w", p.Name, err)
+	}
+	return nil
+}
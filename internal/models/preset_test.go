@@ -0,0 +1,35 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindBankPreset(t *testing.T) {
+	presets := []BankPreset{
+		{Name: "bcv", Parser: "camt", Locale: "fr-CH"},
+		{Name: "broken", Parser: "camt", NumberFormat: "roman"},
+	}
+
+	preset, err := FindBankPreset(presets, "BCV")
+	require.NoError(t, err)
+	assert.Equal(t, "camt", preset.Parser)
+
+	_, err = FindBankPreset(presets, "broken")
+	assert.ErrorContains(t, err, `bank preset "broken": unsupported number format "roman"`)
+
+	_, err = FindBankPreset(presets, "ubs")
+	assert.EqualError(t, err, "bank preset not found: ubs (available: bcv, broken)")
+	_, err = FindBankPreset(nil, "ubs")
+	assert.EqualError(t, err, "bank preset not found: ubs (the presets file defines none)")
+}
+
+func TestValidateBankPreset(t *testing.T) {
+	assert.NoError(t, ValidateBankPreset(BankPreset{Name: "wise", Parser: "wise"}))
+	assert.EqualError(t, ValidateBankPreset(BankPreset{Name: "none"}), `bank preset "none" has no parser`)
+	assert.ErrorContains(t, ValidateBankPreset(BankPreset{Name: "x", Parser: "camt", Locale: "xx-XX"}), "unknown locale")
+	assert.ErrorContains(t, ValidateBankPreset(BankPreset{Name: "x", Parser: "camt",
+		Cleanup: []NameCleanupRule{{Action: CleanupStripPrefix}}}), "cleanup rule 1")
+}
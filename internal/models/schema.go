@@ -0,0 +1,31 @@
+package models
+
+import "slices"
+
+// Types of CSV columns, as reported by the schema command.
+const (
+	ColumnTypeString   = "string"
+	ColumnTypeDate     = "date"
+	ColumnTypeDateTime = "datetime" // Date followed by the time of day (HH:MM)
+	ColumnTypeDecimal  = "decimal"
+	ColumnTypeInteger  = "integer"
+)
+
+// StandardCSVColumnType returns the type of the values MarshalCSVWithOptions
+// writes in column, a StandardCSVHeader column, with the time of day in date
+// columns when includeTime is set. Other columns are strings.
+func StandardCSVColumnType(column string, includeTime bool) string {
+	switch {
+	case slices.Contains(standardCSVDateColumns, column):
+		if includeTime {
+			return ColumnTypeDateTime
+		}
+		return ColumnTypeDate
+	case slices.Contains(standardCSVNumberColumns, column):
+		return ColumnTypeDecimal
+	case column == "NumberOfShares":
+		return ColumnTypeInteger
+	default:
+		return ColumnTypeString
+	}
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStandardCSVColumnType(t *testing.T) {
+	assert.Equal(t, ColumnTypeDate, StandardCSVColumnType("Date", false))
+	assert.Equal(t, ColumnTypeDateTime, StandardCSVColumnType("ValueDate", true))
+	assert.Equal(t, ColumnTypeDecimal, StandardCSVColumnType("ExchangeRate", false))
+	assert.Equal(t, ColumnTypeInteger, StandardCSVColumnType("NumberOfShares", false))
+	assert.Equal(t, ColumnTypeString, StandardCSVColumnType("PartyIBAN", true))
+	assert.Equal(t, ColumnTypeString, StandardCSVColumnType("Unknown", false))
+
+	// Every standard column has a type
+	for _, column := range StandardCSVHeader {
+		assert.NotEmpty(t, StandardCSVColumnType(column, false), column)
+	}
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// sortFields compares two transactions on one field, returning a negative
+// number, zero or a positive number like strings.Compare.
+var sortFields = map[string]func(a, b *Transaction) int{
+	"date":        func(a, b *Transaction) int { return a.Date.Compare(b.Date) },
+	"value-date":  func(a, b *Transaction) int { return a.ValueDate.Compare(b.ValueDate) },
+	"amount":      func(a, b *Transaction) int { return signedAmount(a).Cmp(signedAmount(b)) },
+	"currency":    func(a, b *Transaction) int { return compareText(a.Currency, b.Currency) },
+	"category":    func(a, b *Transaction) int { return compareText(a.Category, b.Category) },
+	"party":       func(a, b *Transaction) int { return compareText(a.PartyName, b.PartyName) },
+	"description": func(a, b *Transaction) int { return compareText(a.Description, b.Description) },
+}
+
+// SortKey is one key of a SortOrder.
+type SortKey struct {
+	Field      string
+	Descending bool
+}
+
+// SortOrder orders transactions by several keys, the first one deciding
+// unless two transactions are equal on it.
+type SortOrder []SortKey
+
+// ParseSortOrder parses comma-separated sort keys, each optionally prefixed
+// with "-" for descending order, such as "category,-amount". Valid keys are
+// date, value-date, amount, currency, category, party and description.
+func ParseSortOrder(spec string) (SortOrder, error) {
+	var order SortOrder
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		key := SortKey{Field: strings.TrimPrefix(item, "-"), Descending: strings.HasPrefix(item, "-")}
+		if _, ok := sortFields[key.Field]; !ok {
+			return nil, fmt.Errorf("unknown sort key %q: valid keys are %s", item, strings.Join(SortFields(), ", "))
+		}
+		order = append(order, key)
+	}
+	return order, nil
+}
+
+// Sort sorts transactions in place. Transactions equal on every key keep
+// their relative order.
+func (o SortOrder) Sort(transactions []Transaction) {
+	sort.SliceStable(transactions, func(i, j int) bool {
+		for _, key := range o {
+			c := sortFields[key.Field](&transactions[i], &transactions[j])
+			if c == 0 {
+				continue
+			}
+			if key.Descending {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+// SortChronologically sorts transactions by date, then value date, then
+// amount, then source sequence number. Dates keep their time of day when the
+// source provides one, so intraday order is preserved.
+func SortChronologically(transactions []Transaction) {
+	sort.SliceStable(transactions, func(i, j int) bool {
+		// Primary sort: by transaction date
+		if !transactions[i].Date.Equal(transactions[j].Date) {
+			return transactions[i].Date.Before(transactions[j].Date)
+		}
+
+		// Secondary sort: by value date
+		if !transactions[i].ValueDate.Equal(transactions[j].ValueDate) {
+			return transactions[i].ValueDate.Before(transactions[j].ValueDate)
+		}
+
+		// Tertiary sort: by amount (for consistency)
+		if !transactions[i].Amount.Equal(transactions[j].Amount) {
+			return transactions[i].Amount.LessThan(transactions[j].Amount)
+		}
+
+		// Final tiebreaker: the entry's position in the source file
+		return transactions[i].SequenceNumber < transactions[j].SequenceNumber
+	})
+}
+
+// SortFields returns the keys ParseSortOrder accepts, sorted.
+func SortFields() []string {
+	names := make([]string, 0, len(sortFields))
+	for name := range sortFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// signedAmount returns the amount of tx, negative for debits, whatever sign
+// convention its parser used.
+func signedAmount(tx *Transaction) decimal.Decimal {
+	if tx.IsDebit() {
+		return tx.Amount.Abs().Neg()
+	}
+	return tx.Amount.Abs()
+}
+
+// compareText compares two strings case-insensitively.
+func compareText(a, b string) int {
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
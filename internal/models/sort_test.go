@@ -0,0 +1,74 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sortedNames(transactions []Transaction) []string {
+	names := make([]string, len(transactions))
+	for i, tx := range transactions {
+		names[i] = tx.PartyName
+	}
+	return names
+}
+
+func TestSortOrder_Sort(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+	transactions := []Transaction{
+		{PartyName: "Coop", Date: day(3), Category: "Food", Amount: decimal.NewFromInt(20), CreditDebit: TransactionTypeDebit},
+		{PartyName: "Employer", Date: day(1), Category: "Salary", Amount: decimal.NewFromInt(3000), CreditDebit: TransactionTypeCredit},
+		{PartyName: "Migros", Date: day(2), Category: "food", Amount: decimal.NewFromInt(-80), CreditDebit: TransactionTypeDebit},
+		{PartyName: "Denner", Date: day(4), Category: "Food", Amount: decimal.NewFromInt(20), CreditDebit: TransactionTypeDebit},
+	}
+
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{"date", []string{"Employer", "Migros", "Coop", "Denner"}},
+		{"-date", []string{"Denner", "Coop", "Migros", "Employer"}},
+		// Debits sort below credits whatever the sign the parser used
+		{"-amount", []string{"Employer", "Coop", "Denner", "Migros"}},
+		{"category,-amount", []string{"Coop", "Denner", "Migros", "Employer"}},
+		{"category, date", []string{"Migros", "Coop", "Denner", "Employer"}},
+		{"party", []string{"Coop", "Denner", "Employer", "Migros"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			order, err := ParseSortOrder(tt.spec)
+			require.NoError(t, err)
+			sorted := append([]Transaction(nil), transactions...)
+			order.Sort(sorted)
+			assert.Equal(t, tt.want, sortedNames(sorted))
+		})
+	}
+}
+
+func TestParseSortOrder(t *testing.T) {
+	order, err := ParseSortOrder("Category,-AMOUNT")
+	require.NoError(t, err)
+	assert.Equal(t, SortOrder{{Field: "category"}, {Field: "amount", Descending: true}}, order)
+
+	for _, spec := range []string{"", "size", "category,", "--date"} {
+		_, err := ParseSortOrder(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestSortChronologically(t *testing.T) {
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	transactions := []Transaction{
+		{PartyName: "later", Date: day.AddDate(0, 0, 1), Amount: decimal.NewFromInt(1)},
+		{PartyName: "third", Date: day, Amount: decimal.NewFromInt(5), SequenceNumber: 3},
+		{PartyName: "second", Date: day, Amount: decimal.NewFromInt(5), SequenceNumber: 2},
+		{PartyName: "smaller", Date: day, Amount: decimal.NewFromInt(1), SequenceNumber: 9},
+	}
+
+	SortChronologically(transactions)
+	assert.Equal(t, []string{"smaller", "second", "third", "later"}, sortedNames(transactions))
+}
@@ -51,11 +51,33 @@ type Transaction struct {
 	// Fields not exported to CSV but used internally
 	Payee string `csv:"-"` // Beneficiary/recipient name (kept for backwards compatibility)
 	Payer string `csv:"-"` // Payer name (kept for backwards compatibility)
+
+	CategorySource        CategorySource `csv:"-"` // Categorization method that set Category (empty if set by the parser itself)
+	Tags                  []string       `csv:"-"` // Free-form tags from the tag rules, independent of Category
+	SequenceNumber        int            `csv:"-"` // 1-based position of the entry in the source file (0 if unknown)
+	PartyBIC              string         `csv:"-"` // BIC of the other party's bank (CAMT only)
+	CreditorReference     string         `csv:"-"` // ISO 11649 (RF) creditor reference for invoice matching (CAMT only)
+	StructuredReference   string         `csv:"-"` // First structured remittance reference of any type, e.g. a QR reference (CAMT only)
+	ReferenceType         string         `csv:"-"` // Type of StructuredReference: QRR, SCOR or NON (CAMT only)
+	CardLast4             string         `csv:"-"` // Last four digits of the masked card number (Viseca PDF and debit only)
+	MCC                   string         `csv:"-"` // ISO 18245 merchant category code of card spend (Viseca PDF and CAMT, when printed)
+	BankTxCodeDescription string         `csv:"-"` // Meaning of BankTxCode, e.g. "SEPA Credit Transfer" (empty for unknown codes)
+	Reversal              bool           `csv:"-"` // True if the entry reverses an earlier booking; its direction is the one booked
+	StatementNote         string         `csv:"-"` // Statement-level notes (AddtlStmtInf) of the entry's statement (CAMT only)
 }
 
 // ParseAmount parses a string amount to decimal.Decimal with proper formatting
 // This is a utility function for converting string representations to the decimal type
+// It returns zero for an amount that does not parse; use ParseAmountChecked to
+// tell such an amount from a real zero.
 func ParseAmount(amountStr string) decimal.Decimal {
+	dec, _ := ParseAmountChecked(amountStr)
+	return dec
+}
+
+// ParseAmountChecked is ParseAmount returning an error, along with zero, when
+// amountStr is not a number.
+func ParseAmountChecked(amountStr string) (decimal.Decimal, error) {
 	// Replace comma with dot for decimal separator
 	amount := strings.ReplaceAll(amountStr, ",", ".")
 	// Remove any currency symbols or spaces
@@ -72,9 +94,9 @@ func ParseAmount(amountStr string) decimal.Decimal {
 	// Convert to decimal
 	dec, err := decimal.NewFromString(amount)
 	if err != nil {
-		return decimal.Zero
+		return decimal.Zero, fmt.Errorf("invalid amount %q: %w", amountStr, err)
 	}
-	return dec
+	return dec, nil
 }
 
 // GetCounterparty returns the relevant party name based on transaction direction
@@ -131,6 +153,34 @@ func (t *Transaction) IsCredit() bool {
 	return t.CreditDebit == TransactionTypeCredit || (t.CreditDebit != TransactionTypeDebit && t.CreditDebit != "UNKNOWN" && !t.DebitFlag && !t.Amount.IsNegative())
 }
 
+// IsBooked returns false for transactions the source marks as not booked
+// (yet): the pending, information and future CAMT entry statuses, and pending
+// card payments. Transactions without a status count as booked.
+func (t *Transaction) IsBooked() bool {
+	switch strings.ToUpper(strings.TrimSpace(t.Status)) {
+	case EntryStatusPending, EntryStatusInfo, EntryStatusFuture, StatusPending:
+		return false
+	}
+	return true
+}
+
+// IsEmpty reports whether t has neither a party name nor a description, so
+// that nothing in the row tells what it was for and it cannot be categorized.
+func (t *Transaction) IsEmpty() bool {
+	return strings.TrimSpace(t.PartyName) == "" && strings.TrimSpace(t.Description) == ""
+}
+
+// SampleReference returns the first reference identifying t in its source
+// statement: its reference, account servicer reference or entry reference.
+func (t *Transaction) SampleReference() string {
+	for _, ref := range []string{t.Reference, t.AccountServicer, t.EntryReference} {
+		if ref = strings.TrimSpace(ref); ref != "" {
+			return ref
+		}
+	}
+	return ""
+}
+
 // UpdateNameFromParties sets the Name field based on the transaction type
 // - For debits, Name is set to Payee
 // - For credits, Name is set to Payer
@@ -175,8 +225,14 @@ func (t *Transaction) GetPartyName() string {
 	return t.Payer
 }
 
-// StandardizeAmount formats amount consistently with 2 decimal places
+// StandardizeAmount formats amount consistently with DecimalPlaces decimal places
 func StandardizeAmount(amountStr string) string {
+	return StandardizeAmountForCurrency(amountStr, "")
+}
+
+// StandardizeAmountForCurrency formats amount consistently with the precision
+// of currency (see AmountPrecision)
+func StandardizeAmountForCurrency(amountStr, currency string) string {
 	// Remove any currency symbols, spaces, commas, etc.
 	amount := strings.TrimSpace(amountStr)
 	amount = strings.ReplaceAll(amount, " ", "")
@@ -202,8 +258,7 @@ func StandardizeAmount(amountStr string) string {
 		return amountStr // Return original if parsing fails
 	}
 
-	// Format with exactly 2 decimal places
-	formatted := dec.StringFixed(2)
+	formatted := FormatAmount(dec, currency)
 
 	// Add back minus sign if it was negative
 	if isNegative {
@@ -213,27 +268,88 @@ func StandardizeAmount(amountStr string) string {
 	return formatted
 }
 
+// CSVOptions controls optional variations of the standard CSV layout.
+// The zero value produces the default output.
+type CSVOptions struct {
+	IncludeTime  bool     // Render Date and ValueDate as DD.MM.YYYY HH:MM
+	SignedAmount bool     // Single signed Amount column (negative for debits); the CreditDebit column is omitted
+	Columns      []string // Emit only these StandardCSVHeader columns, in this order (nil = all)
+	Locale       Locale   // Decimal separator and date layout (zero value = dot and DD.MM.YYYY)
+}
+
+// ExportProfile is a named CSV layout defined in the profiles file: which
+// standard columns to write and in what order, and how to sign amounts.
+type ExportProfile struct {
+	Name         string   `yaml:"name"`
+	Columns      []string `yaml:"columns"`       // StandardCSVHeader columns, in output order
+	SignedAmount bool     `yaml:"signed_amount"` // Negative Amount for debits
+	Delimiter    string   `yaml:"delimiter"`     // Single-character delimiter (default ",")
+}
+
+// ExportProfilesConfig represents the structure of the profiles YAML file
+type ExportProfilesConfig struct {
+	Profiles []ExportProfile `yaml:"profiles"`
+}
+
+// StandardCSVHeader lists the columns of the standard CSV layout in order.
+var StandardCSVHeader = []string{
+	"Status", "Date", "ValueDate", "Name", "PartyName", "PartyIBAN",
+	"Description", "RemittanceInfo", "Amount", "CreditDebit", "Currency",
+	"Product", "AmountExclTax", "TaxRate", "InvestmentType", "Number", "Category",
+	"Type", "Fund", "NumberOfShares", "Fees", "IBAN", "EntryReference", "Reference",
+	"AccountServicer", "BankTxCode", "OriginalCurrency", "OriginalAmount", "ExchangeRate",
+}
+
+// standardCSVColumnIndex maps each StandardCSVHeader column to its position.
+var standardCSVColumnIndex = func() map[string]int {
+	index := make(map[string]int, len(StandardCSVHeader))
+	for i, column := range StandardCSVHeader {
+		index[column] = i
+	}
+	return index
+}()
+
+// IsStandardCSVColumn reports whether column is part of StandardCSVHeader.
+func IsStandardCSVColumn(column string) bool {
+	_, ok := standardCSVColumnIndex[column]
+	return ok
+}
+
+// creditDebitColumn is the index of the CreditDebit column in the standard CSV layout
+const creditDebitColumn = 9
+
+// standardCSVColumns is the number of columns in the standard CSV layout
+const standardCSVColumns = 29
+
+// MarshalCSV converts the transaction to a standard CSV record
 func (t *Transaction) MarshalCSV() ([]string, error) {
+	return t.MarshalCSVWithOptions(CSVOptions{})
+}
+
+// MarshalCSVWithOptions converts the transaction to a standard CSV record using the given options
+func (t *Transaction) MarshalCSVWithOptions(opts CSVOptions) ([]string, error) {
+	dateLayout := opts.Locale.dateLayout(opts.IncludeTime)
+
 	// Make sure the derived fields are populated correctly
 	t.UpdateNameFromParties()
 	t.UpdateRecipientFromPayee()
 	t.UpdateDebitCreditAmounts()
 	t.UpdateInvestmentTypeFromLegacyField()
 
-	return []string{
+	record := []string{
 		t.Status,
-		t.formatDateForCSV(t.Date),
-		t.formatDateForCSV(t.ValueDate),
+		t.formatDateForCSV(t.Date, dateLayout),
+		t.formatDateForCSV(t.ValueDate, dateLayout),
 		t.Name,
 		t.PartyName,
 		t.PartyIBAN,
 		t.Description,
 		t.RemittanceInfo,
-		t.Amount.StringFixed(2),
+		FormatAmount(t.Amount, t.Currency),
 		t.CreditDebit,
 		t.Currency,
 		t.Product,
-		t.AmountExclTax.StringFixed(2),
+		FormatAmount(t.AmountExclTax, t.Currency),
 		t.TaxRate.StringFixed(2),
 		t.Investment,
 		t.Number,
@@ -241,20 +357,94 @@ func (t *Transaction) MarshalCSV() ([]string, error) {
 		t.Type,
 		t.Fund,
 		fmt.Sprintf("%d", t.NumberOfShares),
-		t.Fees.StringFixed(2),
+		FormatAmount(t.Fees, t.Currency),
 		t.IBAN,
 		t.EntryReference,
 		t.Reference,
 		t.AccountServicer,
 		t.BankTxCode,
 		t.OriginalCurrency,
-		t.OriginalAmount.StringFixed(2),
-		t.ExchangeRate.StringFixed(2),
-	}, nil
+		FormatAmount(t.OriginalAmount, t.OriginalCurrency),
+		FormatRate(t.ExchangeRate),
+	}
+	for _, column := range standardCSVNumberColumns {
+		i := standardCSVColumnIndex[column]
+		record[i] = opts.Locale.formatNumber(record[i])
+	}
+
+	if opts.SignedAmount {
+		// Derive the sign from the direction rather than trusting the stored sign
+		amount := t.Amount.Abs()
+		if t.IsDebit() {
+			amount = amount.Neg()
+		}
+		record[8] = opts.Locale.formatNumber(FormatAmount(amount, t.Currency))
+		if opts.Columns == nil {
+			record = append(record[:creditDebitColumn], record[creditDebitColumn+1:]...)
+		}
+	}
+
+	if opts.Columns != nil {
+		selected := make([]string, len(opts.Columns))
+		for i, column := range opts.Columns {
+			index, ok := standardCSVColumnIndex[column]
+			if !ok {
+				return nil, fmt.Errorf("unknown CSV column %q", column)
+			}
+			selected[i] = record[index]
+		}
+		return selected, nil
+	}
+
+	return record, nil
 }
 
+// UnmarshalCSVWithOptions populates the transaction from a CSV record written
+// by MarshalCSVWithOptions with the same options. In signed-amount mode the
+// direction (CreditDebit, DebitFlag) is restored from the sign of Amount;
+// numbers and dates are read in the format of opts.Locale.
+func (t *Transaction) UnmarshalCSVWithOptions(record []string, opts CSVOptions) error {
+	full := record
+	if opts.SignedAmount {
+		if len(record) != standardCSVColumns-1 {
+			return fmt.Errorf("expected %d columns for signed-amount CSV, got %d", standardCSVColumns-1, len(record))
+		}
+		full = make([]string, 0, standardCSVColumns)
+		full = append(full, record[:creditDebitColumn]...)
+		full = append(full, "")
+		full = append(full, record[creditDebitColumn:]...)
+	}
+
+	if !opts.Locale.isDefault() {
+		if len(full) != standardCSVColumns {
+			return fmt.Errorf("expected %d columns, got %d", standardCSVColumns, len(full))
+		}
+		var err error
+		if full, err = opts.Locale.normalizeRecord(full); err != nil {
+			return err
+		}
+	}
+
+	if err := t.UnmarshalCSV(full); err != nil {
+		return err
+	}
+	if !opts.SignedAmount {
+		return nil
+	}
+
+	t.DebitFlag = t.Amount.IsNegative()
+	if t.DebitFlag {
+		t.CreditDebit = TransactionTypeDebit
+	} else {
+		t.CreditDebit = TransactionTypeCredit
+	}
+	return nil
+}
+
+// UnmarshalCSV populates the transaction from a standard CSV record
 func (t *Transaction) UnmarshalCSV(record []string) error {
-	t.Status = record[0]
+	// The first record of a file written with --bom carries the byte order mark
+	t.Status = strings.TrimPrefix(record[0], UTF8BOM)
 	var err error
 	t.Date, err = t.parseDateFromCSV(record[1])
 	if err != nil {
@@ -317,20 +507,24 @@ func (t *Transaction) UnmarshalCSV(record []string) error {
 	return nil
 }
 
-// formatDateForCSV formats a time.Time as DD.MM.YYYY for CSV output
+// formatDateForCSV formats a time.Time with the given layout for CSV output
 // Returns empty string for zero time
-func (t *Transaction) formatDateForCSV(date time.Time) string {
+func (t *Transaction) formatDateForCSV(date time.Time, layout string) string {
 	if date.IsZero() {
 		return ""
 	}
-	return date.Format(DateFormatCSV)
+	return date.Format(layout)
 }
 
-// parseDateFromCSV parses a date string from CSV format (DD.MM.YYYY) to time.Time
+// parseDateFromCSV parses a date string from CSV format (DD.MM.YYYY, optionally
+// followed by HH:MM) to time.Time
 // Returns zero time for empty strings
 func (t *Transaction) parseDateFromCSV(dateStr string) (time.Time, error) {
 	if dateStr == "" {
 		return time.Time{}, nil
 	}
+	if strings.Contains(dateStr, " ") {
+		return time.Parse(DateTimeFormatCSV, dateStr)
+	}
 	return time.Parse(DateFormatCSV, dateStr)
 }
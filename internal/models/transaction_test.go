@@ -37,6 +37,20 @@ func TestGetAmountAsDecimal(t *testing.T) {
 	}
 }
 
+func TestParseAmountChecked(t *testing.T) {
+	amount, err := ParseAmountChecked("1'234,50")
+	require.NoError(t, err)
+	assert.Equal(t, "1234.5", amount.String())
+
+	amount, err = ParseAmountChecked("0.00")
+	require.NoError(t, err)
+	assert.True(t, amount.IsZero())
+
+	amount, err = ParseAmountChecked("12.x")
+	assert.ErrorContains(t, err, `invalid amount "12.x"`)
+	assert.True(t, amount.IsZero())
+}
+
 func TestCreditDebitMethods(t *testing.T) {
 	t.Run("IsDebit", func(t *testing.T) {
 		debitTx := &Transaction{CreditDebit: TransactionTypeDebit}
@@ -59,6 +73,36 @@ func TestCreditDebitMethods(t *testing.T) {
 	})
 }
 
+func TestIsBooked(t *testing.T) {
+	for status, want := range map[string]bool{
+		"":                 true,
+		EntryStatusBooked:  true,
+		StatusCompleted:    true,
+		EntryStatusPending: false,
+		"pdng":             false,
+		EntryStatusInfo:    false,
+		EntryStatusFuture:  false,
+		StatusPending:      false,
+	} {
+		tx := &Transaction{Status: status}
+		assert.Equal(t, want, tx.IsBooked(), "status %q", status)
+	}
+}
+
+func TestIsEmpty(t *testing.T) {
+	assert.True(t, (&Transaction{}).IsEmpty())
+	assert.True(t, (&Transaction{PartyName: " ", Description: "\t", Reference: "REF-1"}).IsEmpty())
+	assert.False(t, (&Transaction{PartyName: "Coop"}).IsEmpty())
+	assert.False(t, (&Transaction{Description: "Card payment"}).IsEmpty())
+}
+
+func TestSampleReference(t *testing.T) {
+	assert.Equal(t, "REF-1", (&Transaction{Reference: "REF-1", AccountServicer: "ASR-1"}).SampleReference())
+	assert.Equal(t, "ASR-1", (&Transaction{AccountServicer: "ASR-1", EntryReference: "7"}).SampleReference())
+	assert.Equal(t, "7", (&Transaction{EntryReference: " 7 "}).SampleReference())
+	assert.Empty(t, (&Transaction{}).SampleReference())
+}
+
 func TestGetPartyName(t *testing.T) {
 	testCases := []struct {
 		name        string
@@ -113,6 +157,13 @@ func TestStandardizeAmount(t *testing.T) {
 	}
 }
 
+func TestStandardizeAmountForCurrency(t *testing.T) {
+	assert.Equal(t, "1500", StandardizeAmountForCurrency("1'499.6", "JPY"))
+	assert.Equal(t, "-1500", StandardizeAmountForCurrency("-1500", "jpy"))
+	assert.Equal(t, "12.500", StandardizeAmountForCurrency("12,5", "KWD"))
+	assert.Equal(t, "12.50", StandardizeAmountForCurrency("12,5", "CHF"))
+}
+
 func TestUpdateInvestmentTypeFromLegacyField(t *testing.T) {
 	t.Run("CopyFromType", func(t *testing.T) {
 		tx := &Transaction{Type: "Buy"}
@@ -280,6 +331,99 @@ func TestTransaction_DateFormattingIndirect(t *testing.T) {
 	})
 }
 
+func TestTransaction_MarshalCSVWithTime(t *testing.T) {
+	tx := Transaction{
+		Date:      time.Date(2025, 1, 15, 14, 30, 45, 0, time.UTC),
+		ValueDate: time.Date(2025, 1, 16, 10, 0, 0, 0, time.UTC),
+		Amount:    decimal.NewFromFloat(100),
+		Currency:  "CHF",
+	}
+
+	defaultRecord, err := tx.MarshalCSV()
+	require.NoError(t, err)
+	assert.Equal(t, "15.01.2025", defaultRecord[1])
+
+	record, err := tx.MarshalCSVWithOptions(CSVOptions{IncludeTime: true})
+	require.NoError(t, err)
+	assert.Equal(t, "15.01.2025 14:30", record[1])
+	assert.Equal(t, "16.01.2025 10:00", record[2])
+
+	var restored Transaction
+	require.NoError(t, restored.UnmarshalCSV(record))
+	assert.Equal(t, time.Date(2025, 1, 15, 14, 30, 0, 0, time.UTC), restored.Date)
+	assert.Equal(t, time.Date(2025, 1, 16, 10, 0, 0, 0, time.UTC), restored.ValueDate)
+}
+
+func TestTransaction_UnmarshalCSVStripsBOM(t *testing.T) {
+	tx := Transaction{
+		Status:   StatusCompleted,
+		Date:     time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+		Amount:   decimal.NewFromFloat(100),
+		Currency: "CHF",
+	}
+	record, err := tx.MarshalCSV()
+	require.NoError(t, err)
+	record[0] = UTF8BOM + record[0]
+
+	var restored Transaction
+	require.NoError(t, restored.UnmarshalCSV(record))
+	assert.Equal(t, StatusCompleted, restored.Status)
+
+	assert.Equal(t, []byte("Status\n"), StripCSVComments([]byte(UTF8BOM+"# Statement note: x\nStatus\n")))
+}
+
+func TestTransaction_SignedAmountRoundTrip(t *testing.T) {
+	tests := []struct {
+		name           string
+		tx             Transaction
+		expectedAmount string
+		expectedDir    string
+	}{
+		{
+			name:           "debit stored as positive amount",
+			tx:             Transaction{Date: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), Amount: decimal.NewFromFloat(42.5), CreditDebit: TransactionTypeDebit, DebitFlag: true, Currency: "CHF"},
+			expectedAmount: "-42.50",
+			expectedDir:    TransactionTypeDebit,
+		},
+		{
+			name:           "credit",
+			tx:             Transaction{Date: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), Amount: decimal.NewFromFloat(100), CreditDebit: TransactionTypeCredit, Currency: "CHF"},
+			expectedAmount: "100.00",
+			expectedDir:    TransactionTypeCredit,
+		},
+	}
+
+	opts := CSVOptions{SignedAmount: true}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, err := tt.tx.MarshalCSVWithOptions(opts)
+			require.NoError(t, err)
+			assert.Len(t, record, 28)
+			assert.Equal(t, tt.expectedAmount, record[8])
+			assert.Equal(t, "CHF", record[9]) // Currency follows Amount directly
+
+			var restored Transaction
+			require.NoError(t, restored.UnmarshalCSVWithOptions(record, opts))
+			assert.Equal(t, tt.expectedAmount, restored.Amount.StringFixed(2))
+			assert.Equal(t, tt.expectedDir, restored.CreditDebit)
+			assert.Equal(t, tt.expectedDir == TransactionTypeDebit, restored.DebitFlag)
+		})
+	}
+
+	t.Run("default layout unchanged", func(t *testing.T) {
+		record, err := tests[0].tx.MarshalCSV()
+		require.NoError(t, err)
+		assert.Len(t, record, 29)
+		assert.Equal(t, TransactionTypeDebit, record[9])
+	})
+
+	t.Run("wrong column count", func(t *testing.T) {
+		var restored Transaction
+		err := restored.UnmarshalCSVWithOptions(make([]string, 29), opts)
+		assert.Error(t, err)
+	})
+}
+
 // Test categorization stats methods
 func TestCategorizationStats_UncoveredMethods(t *testing.T) {
 	t.Run("NewCategorizationStats", func(t *testing.T) {
@@ -380,3 +524,24 @@ func (m *MockLogger) Fatalf(format string, args ...any)                 {}
 func (m *MockLogger) WithError(err error) logging.Logger                { return m }
 func (m *MockLogger) WithField(key string, value any) logging.Logger    { return m }
 func (m *MockLogger) WithFields(fields ...logging.Field) logging.Logger { return m }
+
+func TestTransaction_MarshalCSVPrecision(t *testing.T) {
+	tx := &Transaction{
+		Date:             time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		Amount:           decimal.RequireFromString("1234.5678"),
+		Currency:         "JPY",
+		Fees:             decimal.RequireFromString("110.4"),
+		CreditDebit:      TransactionTypeDebit,
+		OriginalAmount:   decimal.RequireFromString("8.1234"),
+		OriginalCurrency: "CHF",
+		ExchangeRate:     decimal.RequireFromString("151.98765"),
+	}
+
+	record, err := tx.MarshalCSVWithOptions(CSVOptions{Columns: []string{"Amount", "Fees", "OriginalAmount", "ExchangeRate"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1235", "110", "8.12", "151.9877"}, record)
+
+	record, err = tx.MarshalCSVWithOptions(CSVOptions{SignedAmount: true, Columns: []string{"Amount"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"-1235"}, record)
+}
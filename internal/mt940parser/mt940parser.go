@@ -0,0 +1,360 @@
+// Package mt940parser parses SWIFT MT940 account statements (.sta files) as
+// exported by Postbank, Deutsche Bank and most German banks. A statement is a
+// sequence of :tag: fields; each :61: statement line is one booking and is
+// usually followed by a :86: field holding its details.
+package mt940parser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"fjacquet/camt-csv/internal/common"
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parser"
+	"fjacquet/camt-csv/internal/parsererror"
+
+	"github.com/shopspring/decimal"
+)
+
+// MT940 field tags used by the parser.
+const (
+	tagReference      = "20"
+	tagAccount        = "25"
+	tagOpeningBalance = "60F"
+	tagInterimBalance = "60M"
+	tagStatementLine  = "61"
+	tagDetails        = "86"
+)
+
+// noReference is the placeholder banks write when there is no customer reference.
+const noReference = "NONREF"
+
+// tagPattern matches the start of a field, e.g. ":61:" or ":60F:".
+var tagPattern = regexp.MustCompile(`^:(\d{2}[A-Z]?):(.*)$`)
+
+// statementLinePattern splits a :61: field into value date (YYMMDD), optional
+// entry date (MMDD), debit/credit mark (D, C, RD, RC), optional funds code
+// (the "R" of "DR"/"CR", the "M" of "DM", the "RF" some banks write, as in
+// "CRF"), amount, transaction type and the references.
+var statementLinePattern = regexp.MustCompile(`^(\d{6})(\d{4})?(R?[CD])([A-Z]{1,2})?(\d+,\d*)([NSF][A-Z0-9]{3})(.*)$`)
+
+// sepaKeys are the identifiers German banks use to structure the purpose of
+// SEPA bookings in the :86: field.
+var sepaKeys = []string{"EREF+", "KREF+", "MREF+", "CRED+", "DEBT+", "COAM+", "OAMT+", "SVWZ+", "ABWA+", "ABWE+"}
+
+// field is one :tag: field of a statement, with continuation lines joined by "\n".
+type field struct {
+	tag   string
+	value string
+}
+
+// entry is a :61: statement line with the :86: details that follow it and
+// the statement it belongs to.
+type entry struct {
+	line     string
+	details  string
+	account  string
+	currency string
+}
+
+// details holds the parts of a :86: field.
+type details struct {
+	bookingText string
+	purpose     string
+	bic         string
+	account     string
+	name        string
+	sepa        map[string]string
+}
+
+// readFields splits an MT940 file into its fields. SWIFT header blocks ("{1:...}")
+// and message separators ("-") are skipped.
+func readFields(data string) []field {
+	var fields []field
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if m := tagPattern.FindStringSubmatch(line); m != nil {
+			fields = append(fields, field{tag: m[1], value: m[2]})
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "-" || trimmed == "-}" || strings.HasPrefix(trimmed, "{") {
+			continue
+		}
+		if len(fields) > 0 {
+			fields[len(fields)-1].value += "\n" + line
+		}
+	}
+	return fields
+}
+
+// collectEntries groups the fields into statement lines, carrying over the
+// account and currency of the statement they belong to.
+func collectEntries(fields []field) []entry {
+	var entries []entry
+	var account, currency string
+	lastWasLine := false
+	for _, f := range fields {
+		switch f.tag {
+		case tagReference:
+			account, currency = "", ""
+		case tagAccount:
+			account = strings.TrimSpace(f.value)
+		case tagOpeningBalance, tagInterimBalance:
+			// Mark (1), date (6), currency (3), amount
+			if len(f.value) >= 10 {
+				currency = f.value[7:10]
+			}
+		case tagStatementLine:
+			entries = append(entries, entry{line: f.value, account: account, currency: currency})
+		case tagDetails:
+			if lastWasLine {
+				entries[len(entries)-1].details = f.value
+			}
+		}
+		lastWasLine = f.tag == tagStatementLine
+	}
+	return entries
+}
+
+// parseDetails splits a :86: field. Structured fields start with a three-digit
+// business transaction code followed by "?NN" subfields; anything else is
+// taken as free text.
+func parseDetails(value string) details {
+	if !isStructured(value) {
+		return details{purpose: strings.Join(strings.Fields(value), " ")}
+	}
+
+	var d details
+	var purpose, name strings.Builder
+	joined := strings.ReplaceAll(value, "\n", "")
+	for _, sub := range strings.Split(joined[4:], "?") {
+		if len(sub) < 2 {
+			continue
+		}
+		code, text := sub[:2], sub[2:]
+		switch {
+		case code == "00":
+			d.bookingText = strings.TrimSpace(text)
+		case code >= "20" && code <= "29", code >= "60" && code <= "63":
+			purpose.WriteString(text)
+		case code == "30":
+			d.bic = strings.TrimSpace(text)
+		case code == "31":
+			d.account = strings.TrimSpace(text)
+		case code == "32", code == "33":
+			name.WriteString(text)
+		}
+	}
+	d.purpose = strings.TrimSpace(purpose.String())
+	d.name = strings.TrimSpace(name.String())
+	d.sepa = sepaFields(d.purpose)
+	return d
+}
+
+// isStructured reports whether a :86: field uses the "NNN?00..." layout.
+func isStructured(value string) bool {
+	if len(value) < 4 || value[3] != '?' {
+		return false
+	}
+	for _, c := range value[:3] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// sepaFields splits a purpose text at the SEPA identifiers it contains,
+// e.g. "EREF+123SVWZ+Rent" gives {"EREF+": "123", "SVWZ+": "Rent"}.
+func sepaFields(purpose string) map[string]string {
+	type mark struct {
+		key string
+		at  int
+	}
+	var marks []mark
+	for _, key := range sepaKeys {
+		if i := strings.Index(purpose, key); i >= 0 {
+			marks = append(marks, mark{key, i})
+		}
+	}
+	if len(marks) == 0 {
+		return nil
+	}
+	sort.Slice(marks, func(i, j int) bool { return marks[i].at < marks[j].at })
+
+	fields := make(map[string]string, len(marks))
+	for i, m := range marks {
+		end := len(purpose)
+		if i+1 < len(marks) {
+			end = marks[i+1].at
+		}
+		fields[m.key] = strings.TrimSpace(purpose[m.at+len(m.key) : end])
+	}
+	return fields
+}
+
+// parseEntryDate returns the MMDD entry date in the year closest to the value
+// date, so that a booking on 31.12. valued on 02.01. lands in the previous year.
+func parseEntryDate(mmdd string, valueDate time.Time) (time.Time, error) {
+	t, err := time.Parse("0102", mmdd)
+	if err != nil {
+		return time.Time{}, err
+	}
+	year := valueDate.Year()
+	switch diff := int(t.Month()) - int(valueDate.Month()); {
+	case diff > 6:
+		year--
+	case diff < -6:
+		year++
+	}
+	return time.Date(year, t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+// decode returns data as a string, reading it as ISO-8859-1 when it is not
+// valid UTF-8, the encoding most German banks still use for MT940 exports.
+func decode(data []byte) string {
+	if utf8.Valid(data) {
+		return string(data)
+	}
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+// ParseWithCategorizer parses an MT940 statement reader and returns transactions.
+func ParseWithCategorizer(ctx context.Context, r io.Reader, logger logging.Logger, categorizer models.TransactionCategorizer) ([]models.Transaction, error) {
+	if logger == nil {
+		logger = logging.NewLogrusAdapter("info", "text")
+	}
+	logger.Info("Parsing MT940 statement from reader")
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", parsererror.ErrReadFailed, err)
+	}
+
+	fields := readFields(decode(data))
+	if len(fields) == 0 {
+		return nil, &parsererror.InvalidFormatError{
+			FilePath:       "(from reader)",
+			ExpectedFormat: "MT940 statement",
+			Msg:            "no :tag: fields found",
+		}
+	}
+
+	var transactions []models.Transaction
+
+	for i, e := range collectEntries(fields) {
+		tx, err := convertEntryToTransaction(e)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to convert statement line to transaction",
+				logging.Field{Key: "entry", Value: i + 1})
+			continue
+		}
+
+		if err := parser.CheckTransactionLimit(ctx, len(transactions)+1); err != nil {
+			return nil, err
+		}
+
+		transactions = append(transactions, tx)
+	}
+
+	// Categorize the transactions using the injected categorizer
+	transactions = common.ProcessTransactionsWithCategorizationStats(ctx, transactions, logger, categorizer, "MT940")
+
+	logger.Info("Successfully parsed transactions from MT940 statement",
+		logging.Field{Key: "count", Value: len(transactions)})
+	return transactions, nil
+}
+
+// convertEntryToTransaction converts a statement line and its details to a
+// models.Transaction.
+func convertEntryToTransaction(e entry) (models.Transaction, error) {
+	line, supplementary, _ := strings.Cut(e.line, "\n")
+	m := statementLinePattern.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return models.Transaction{}, fmt.Errorf("invalid statement line %q", line)
+	}
+	valueStr, entryStr, mark, amountStr, refs := m[1], m[2], m[3], m[5], m[7]
+
+	valueDate, err := time.Parse("060102", valueStr)
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("invalid value date %q: %w", valueStr, err)
+	}
+	date := valueDate
+	if entryStr != "" {
+		if date, err = parseEntryDate(entryStr, valueDate); err != nil {
+			return models.Transaction{}, fmt.Errorf("invalid entry date %q: %w", entryStr, err)
+		}
+	}
+
+	amount, err := decimal.NewFromString(strings.Replace(amountStr, ",", ".", 1))
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("invalid amount %q: %w", amountStr, err)
+	}
+
+	// A reversal of a credit (RC) takes money out, a reversal of a debit (RD) puts it back
+	reversal := strings.HasPrefix(mark, "R")
+	isDebit := strings.HasSuffix(mark, "D") != reversal
+
+	customerRef, bankRef, _ := strings.Cut(refs, "//")
+	if customerRef == noReference {
+		customerRef = ""
+	}
+
+	d := parseDetails(e.details)
+	description := firstNonEmpty(d.sepa["SVWZ+"], d.purpose, d.bookingText, strings.TrimSpace(supplementary))
+	reference := firstNonEmpty(d.sepa["EREF+"], customerRef)
+
+	builder := models.NewTransactionBuilder().
+		WithDatetime(date).
+		WithValueDatetime(valueDate).
+		WithAmount(amount, e.currency).
+		WithDescription(description).
+		WithRemittanceInfo(d.purpose).
+		WithPartyName(d.name).
+		WithIBAN(e.account).
+		WithReference(reference).
+		WithEntryReference(strings.TrimSpace(bankRef))
+
+	switch {
+	case reversal:
+		builder = builder.WithType(models.TypeReversal)
+	case d.bookingText != "":
+		builder = builder.WithType(d.bookingText)
+	}
+
+	if isDebit {
+		builder = builder.WithPayee(d.name, d.account).AsDebit()
+	} else {
+		builder = builder.WithPayer(d.name, d.account).AsCredit()
+	}
+
+	tx, err := builder.Build()
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("error building transaction: %w", err)
+	}
+	tx.Reversal = reversal
+	tx.PartyBIC = d.bic
+	return tx, nil
+}
+
+// firstNonEmpty returns the first non-empty value, or "".
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,252 @@
+package mt940parser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const fixture = "testdata/postbank.sta"
+
+type mockCategorizer struct {
+	mock.Mock
+}
+
+func (m *mockCategorizer) Categorize(ctx context.Context, partyName string, isDebtor bool, amount, date, description string) (models.Category, error) {
+	args := m.Called(ctx, partyName, isDebtor, amount, date, description)
+	return args.Get(0).(models.Category), args.Error(1)
+}
+
+func newTestLogger() logging.Logger {
+	return logging.NewLogrusAdapter("info", "text")
+}
+
+func parseFixture(t *testing.T) []models.Transaction {
+	t.Helper()
+	f, err := os.Open(fixture)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	txs, err := NewAdapter(newTestLogger()).Parse(context.Background(), f)
+	require.NoError(t, err)
+	return txs
+}
+
+func TestParse_Fixture(t *testing.T) {
+	txs := parseFixture(t)
+	require.Len(t, txs, 5)
+
+	power := txs[0]
+	assert.Equal(t, time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), power.Date)
+	assert.Equal(t, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), power.ValueDate)
+	assert.True(t, power.Amount.Abs().Equal(decimal.RequireFromString("45.90")))
+	assert.Equal(t, "EUR", power.Currency)
+	assert.Equal(t, models.TransactionTypeDebit, power.CreditDebit)
+	assert.Equal(t, "Stadtwerke Muenchen GmbH", power.PartyName)
+	assert.Equal(t, "DE44500105175407324931", power.PartyIBAN)
+	assert.Equal(t, "COBADEFFXXX", power.PartyBIC)
+	assert.Equal(t, "Strom Januar 2025", power.Description)
+	assert.Equal(t, "ABC123456789", power.Reference)
+	assert.Equal(t, "POSTBANK0001", power.EntryReference)
+	assert.Equal(t, "LASTSCHRIFT", power.Type)
+	assert.Equal(t, "DE89370400440532013000", power.IBAN)
+
+	salary := txs[1]
+	assert.Equal(t, time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), salary.Date)
+	assert.Equal(t, models.TransactionTypeCredit, salary.CreditDebit)
+	assert.Equal(t, "ACME Software AG", salary.PartyName)
+	assert.Equal(t, "Gehalt Dezember", salary.Description)
+	assert.Empty(t, salary.Reference)
+
+	// The returned direct debit (RD) puts the money back
+	reversal := txs[2]
+	assert.True(t, reversal.Reversal)
+	assert.Equal(t, models.TypeReversal, reversal.Type)
+	assert.Equal(t, models.TransactionTypeCredit, reversal.CreditDebit)
+	assert.Equal(t, "Stadtwerke Muenchen GmbH", reversal.PartyName)
+	assert.Equal(t, "ABC123456789", reversal.Reference)
+
+	fee := txs[3]
+	assert.Equal(t, models.TransactionTypeDebit, fee.CreditDebit)
+	assert.True(t, fee.Amount.Abs().Equal(decimal.RequireFromString("12")))
+	assert.Equal(t, "Kontofuehrungsgebuehr Januar", fee.Description)
+	assert.Empty(t, fee.PartyName)
+
+	// CRF: a credit with a two-letter funds code
+	refund := txs[4]
+	assert.Equal(t, models.TransactionTypeCredit, refund.CreditDebit)
+	assert.True(t, refund.Amount.Equal(decimal.RequireFromString("120")))
+	assert.Equal(t, "Krankenkasse Nord", refund.PartyName)
+	assert.Equal(t, "POSTBANK0005", refund.EntryReference)
+}
+
+func TestConvertEntryToTransaction_Marks(t *testing.T) {
+	tests := []struct {
+		line     string
+		debit    bool
+		reversal bool
+	}{
+		{"250102D10,00NTRFNONREF", true, false},
+		{"250102C10,00NTRFNONREF", false, false},
+		{"250102DR10,00NTRFNONREF", true, false},
+		{"250102CR10,00NTRFNONREF", false, false},
+		{"250102DM10,00NTRFNONREF", true, false},
+		{"250102CRF10,00NTRFNONREF", false, false},
+		{"250102DRF10,00NTRFNONREF", true, false},
+		{"250102RD10,00NTRFNONREF", false, true},
+		{"250102RC10,00NTRFNONREF", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			tx, err := convertEntryToTransaction(entry{line: tt.line, currency: "EUR"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.debit, tx.IsDebit())
+			assert.Equal(t, tt.reversal, tx.Reversal)
+			assert.True(t, tx.Amount.Abs().Equal(decimal.NewFromInt(10)))
+		})
+	}
+}
+
+func TestConvertEntryToTransaction_Invalid(t *testing.T) {
+	for _, line := range []string{"garbage", "251302D10,00NTRF", "250102X10,00NTRF", "250102D0,00NTRF"} {
+		_, err := convertEntryToTransaction(entry{line: line, currency: "EUR"})
+		assert.Error(t, err, line)
+	}
+
+	_, err := convertEntryToTransaction(entry{line: "250102D10,00NTRF"})
+	assert.Error(t, err, "a statement without opening balance has no currency")
+}
+
+func TestParseEntryDate(t *testing.T) {
+	tests := []struct {
+		mmdd      string
+		valueDate time.Time
+		want      time.Time
+	}{
+		{"0105", time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{"1231", time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{"0102", time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		got, err := parseEntryDate(tt.mmdd, tt.valueDate)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, tt.mmdd)
+	}
+}
+
+func TestParseDetails(t *testing.T) {
+	free := parseDetails("Miete\nWohnung 3")
+	assert.Equal(t, "Miete Wohnung 3", free.purpose)
+	assert.Empty(t, free.name)
+
+	// Lines break anywhere, even inside a subfield
+	structured := parseDetails("177?00SEPA-UEBERWEISUNG?20SVWZ+Miete Ja\nnuar?32Haus\nverwaltung?33 Meier")
+	assert.Equal(t, "SEPA-UEBERWEISUNG", structured.bookingText)
+	assert.Equal(t, "Miete Januar", structured.sepa["SVWZ+"])
+	assert.Equal(t, "Hausverwaltung Meier", structured.name)
+}
+
+func TestParse_Latin1(t *testing.T) {
+	data := []byte(":20:X\n:25:DE89370400440532013000\n:60F:C250101EUR0,00\n:61:250102D5,00NTRFNONREF\n:86:B\xe4ckerei M\xfcller\n")
+
+	txs, err := ParseWithCategorizer(context.Background(), strings.NewReader(string(data)), newTestLogger(), nil)
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	assert.Equal(t, "Bäckerei Müller", txs[0].Description)
+}
+
+func TestParse_NotMT940(t *testing.T) {
+	_, err := ParseWithCategorizer(context.Background(), strings.NewReader("Date,Amount\n2025-01-01,10\n"), newTestLogger(), nil)
+	assert.Error(t, err)
+}
+
+func TestParse_Categorizer(t *testing.T) {
+	f, err := os.Open(fixture)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	cat := &mockCategorizer{}
+	cat.On("Categorize", mock.Anything, "Stadtwerke Muenchen GmbH", true, "-45.9", "2024-12-31", "Strom Januar 2025").
+		Return(models.Category{Name: "Utilities", Source: models.CategorySourceMapping}, nil)
+	cat.On("Categorize", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(models.Category{Name: models.CategoryUncategorized}, nil)
+
+	txs, err := ParseWithCategorizer(context.Background(), f, newTestLogger(), cat)
+	require.NoError(t, err)
+	require.Len(t, txs, 5)
+	assert.Equal(t, "Utilities", txs[0].Category)
+	assert.Equal(t, models.CategorySourceMapping, txs[0].CategorySource)
+	assert.Equal(t, models.CategoryUncategorized, txs[1].Category)
+}
+
+// ibanCategorizer maps counterparty IBANs to categories on top of mockCategorizer.
+type ibanCategorizer struct {
+	mockCategorizer
+	categories map[string]string
+}
+
+func (c *ibanCategorizer) CategoryForIBAN(iban string) (string, bool) {
+	category, ok := c.categories[iban]
+	return category, ok
+}
+
+func TestParse_CategorizerIBANMapping(t *testing.T) {
+	f, err := os.Open(fixture)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	cat := &ibanCategorizer{categories: map[string]string{"DE02120300000000202051": "Salary"}}
+	cat.On("Categorize", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(models.Category{Name: models.CategoryUncategorized}, nil)
+
+	txs, err := ParseWithCategorizer(context.Background(), f, newTestLogger(), cat)
+	require.NoError(t, err)
+	require.Len(t, txs, 5)
+	assert.Equal(t, "Salary", txs[1].Category)
+	assert.Equal(t, models.CategorySourceIBAN, txs[1].CategorySource)
+	cat.AssertNotCalled(t, "Categorize", mock.Anything, "ACME Software AG", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAdapter_ValidateFormat(t *testing.T) {
+	a := NewAdapter(newTestLogger())
+
+	valid, err := a.ValidateFormat(fixture)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	other := filepath.Join(t.TempDir(), "other.csv")
+	require.NoError(t, os.WriteFile(other, []byte("Date,Amount\n"), 0o600))
+	valid, err = a.ValidateFormat(other)
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	_, err = a.ValidateFormat(filepath.Join(t.TempDir(), "missing.sta"))
+	assert.Error(t, err)
+}
+
+func TestAdapter_BatchConvert(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+	data, err := os.ReadFile(fixture)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(inputDir, "january.sta"), data, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(inputDir, "notes.txt"), data, 0o600))
+
+	count, err := NewAdapter(newTestLogger()).BatchConvert(context.Background(), inputDir, outputDir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.FileExists(t, filepath.Join(outputDir, "january.csv"))
+}
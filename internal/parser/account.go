@@ -0,0 +1,42 @@
+package parser
+
+import (
+	"context"
+	"strings"
+)
+
+type accountKey struct{}
+
+// WithAccount returns a context in which parsers of multi-account statements
+// only keep the statements of account, an IBAN or another account id.
+// An empty account keeps every statement.
+func WithAccount(ctx context.Context, account string) context.Context {
+	return context.WithValue(ctx, accountKey{}, account)
+}
+
+// Account returns the account set with WithAccount, or "" when there is none.
+func Account(ctx context.Context) string {
+	account, _ := ctx.Value(accountKey{}).(string)
+	return account
+}
+
+// AccountSelected reports whether a statement of the account identified by ids
+// (its IBAN, other id, ...) is kept. Spaces and case are ignored, so an IBAN
+// matches in its printed form too.
+func AccountSelected(ctx context.Context, ids ...string) bool {
+	requested := normalizeAccount(Account(ctx))
+	if requested == "" {
+		return true
+	}
+	for _, id := range ids {
+		if id != "" && normalizeAccount(id) == requested {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeAccount returns account without spaces, in upper case.
+func normalizeAccount(account string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(account), ""))
+}
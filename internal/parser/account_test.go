@@ -0,0 +1,23 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountSelected(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, Account(ctx))
+	assert.True(t, AccountSelected(ctx, "CH9300762011623852957"), "no account keeps every statement")
+
+	ctx = WithAccount(ctx, "ch93 0076 2011 6238 5295 7")
+	assert.Equal(t, "ch93 0076 2011 6238 5295 7", Account(ctx))
+	assert.True(t, AccountSelected(ctx, "CH9300762011623852957"))
+	assert.True(t, AccountSelected(ctx, "", "CH9300762011623852957"))
+	assert.False(t, AccountSelected(ctx, "CH5604835012345678009"))
+	assert.False(t, AccountSelected(ctx))
+
+	assert.True(t, AccountSelected(WithAccount(context.Background(), "0123-456789"), "CH5604835012345678009", "0123-456789"))
+}
@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"context"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+)
+
+// WarnEmptyTransactions logs a warning when transactions, parsed from file,
+// hold transactions with neither a party name nor a description. Such rows
+// cannot be categorized and usually point to a format variant the parser does
+// not extract names from, so the warning names the first one by its
+// reference and date. It returns the number of empty transactions.
+func WarnEmptyTransactions(ctx context.Context, transactions []models.Transaction, file string, logger logging.Logger) int {
+	count := 0
+	var sample *models.Transaction
+	for i := range transactions {
+		if transactions[i].IsEmpty() {
+			if sample == nil {
+				sample = &transactions[i]
+			}
+			count++
+		}
+	}
+	if count == 0 || logger == nil {
+		return count
+	}
+
+	fields := []logging.Field{
+		{Key: "file", Value: file},
+		{Key: "count", Value: count},
+		{Key: "sample_reference", Value: sample.SampleReference()},
+	}
+	if !sample.Date.IsZero() {
+		fields = append(fields, logging.Field{Key: "sample_date", Value: sample.Date.Format("2006-01-02")})
+	}
+	if filter, ok := ctx.Value(transactionFilterKey{}).(TransactionFilter); ok && filter.DropEmpty {
+		logger.Warn("Dropping transactions with neither a party name nor a description", fields...)
+	} else {
+		logger.Warn("Transactions have neither a party name nor a description (use --drop-empty to remove them)", fields...)
+	}
+	return count
+}
@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWarnEmptyTransactions(t *testing.T) {
+	transactions := []models.Transaction{
+		{PartyName: "Coop"},
+		{AccountServicer: "ASR-7", Date: time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{Reference: "REF-9"},
+	}
+
+	logger := logging.NewMockLogger()
+	assert.Equal(t, 2, WarnEmptyTransactions(context.Background(), transactions, "statement.xml", logger))
+	entries := logger.GetEntriesByLevel("WARN")
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "Transactions have neither a party name nor a description (use --drop-empty to remove them)", entries[0].Message)
+		assert.Contains(t, entries[0].Fields, logging.Field{Key: "count", Value: 2})
+		assert.Contains(t, entries[0].Fields, logging.Field{Key: "sample_reference", Value: "ASR-7"})
+		assert.Contains(t, entries[0].Fields, logging.Field{Key: "sample_date", Value: "2025-03-04"})
+	}
+
+	// With --drop-empty the warning says they are dropped
+	logger = logging.NewMockLogger()
+	ctx := WithTransactionFilter(context.Background(), TransactionFilter{DropEmpty: true})
+	WarnEmptyTransactions(ctx, transactions, "statement.xml", logger)
+	assert.True(t, logger.HasEntry("WARN", "Dropping transactions with neither a party name nor a description"))
+
+	// No empty transactions, no warning
+	logger = logging.NewMockLogger()
+	assert.Zero(t, WarnEmptyTransactions(context.Background(), transactions[:1], "statement.xml", logger))
+	assert.Empty(t, logger.GetEntriesByLevel("WARN"))
+}
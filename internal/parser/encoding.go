@@ -0,0 +1,38 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"fjacquet/camt-csv/internal/common"
+	"fjacquet/camt-csv/internal/parsererror"
+)
+
+type inputEncodingKey struct{}
+
+// WithInputEncoding returns a context telling the parsers which encoding text
+// input is in (one of the common.Encoding names).
+func WithInputEncoding(ctx context.Context, encoding string) context.Context {
+	return context.WithValue(ctx, inputEncodingKey{}, encoding)
+}
+
+// InputEncoding returns the encoding set with WithInputEncoding, or
+// common.EncodingAuto when there is none.
+func InputEncoding(ctx context.Context) string {
+	if encoding, ok := ctx.Value(inputEncodingKey{}).(string); ok && encoding != "" {
+		return encoding
+	}
+	return common.EncodingAuto
+}
+
+// DecodeInput returns r converted to UTF-8 from the input encoding in ctx.
+// Text parsers call it before reading, so that files exported in
+// Windows-1252 keep their accented characters.
+func DecodeInput(ctx context.Context, r io.Reader) (io.Reader, error) {
+	decoded, err := common.NewUTF8Reader(r, InputEncoding(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", parsererror.ErrReadFailed, err)
+	}
+	return decoded, nil
+}
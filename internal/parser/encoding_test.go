@@ -0,0 +1,37 @@
+package parser
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"fjacquet/camt-csv/internal/common"
+	"fjacquet/camt-csv/internal/parsererror"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeInput(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, common.EncodingAuto, InputEncoding(ctx))
+
+	r, err := DecodeInput(ctx, strings.NewReader("M\xfcller"))
+	require.NoError(t, err)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "Müller", string(got))
+
+	// Forcing UTF-8 leaves the bytes alone
+	utf8Ctx := WithInputEncoding(ctx, common.EncodingUTF8)
+	assert.Equal(t, common.EncodingUTF8, InputEncoding(utf8Ctx))
+	r, err = DecodeInput(utf8Ctx, strings.NewReader("M\xfcller"))
+	require.NoError(t, err)
+	got, err = io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "M\xfcller", string(got))
+
+	_, err = DecodeInput(WithInputEncoding(ctx, "utf-16"), strings.NewReader("x"))
+	assert.ErrorIs(t, err, parsererror.ErrReadFailed)
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"context"
+	"regexp"
+
+	"fjacquet/camt-csv/internal/models"
+)
+
+// TransactionFilter selects the transactions a conversion writes. A nil field
+// does not filter; set fields must all match.
+type TransactionFilter struct {
+	// Description keeps transactions whose description matches.
+	Description *regexp.Regexp
+	// SkipZero drops transactions with a zero amount, such as the
+	// informational entries of some CAMT statements.
+	SkipZero bool
+	// BookedOnly drops transactions that are not booked yet, such as pending
+	// CAMT entries (see models.Transaction.IsBooked).
+	BookedOnly bool
+	// DropEmpty drops transactions with neither a party name nor a
+	// description (see models.Transaction.IsEmpty).
+	DropEmpty bool
+}
+
+// Matches reports whether tx passes every condition of the filter.
+func (f TransactionFilter) Matches(tx models.Transaction) bool {
+	if f.Description != nil && !f.Description.MatchString(tx.Description) {
+		return false
+	}
+	if f.SkipZero && tx.Amount.IsZero() {
+		return false
+	}
+	if f.BookedOnly && !tx.IsBooked() {
+		return false
+	}
+	if f.DropEmpty && tx.IsEmpty() {
+		return false
+	}
+	return true
+}
+
+type transactionFilterKey struct{}
+
+// WithTransactionFilter returns a context in which the conversion paths only
+// write the transactions matching filter.
+func WithTransactionFilter(ctx context.Context, filter TransactionFilter) context.Context {
+	return context.WithValue(ctx, transactionFilterKey{}, filter)
+}
+
+// FilterTransactions returns the transactions matching the filter set with
+// WithTransactionFilter, or transactions unchanged when there is none, cut to
+// the limit set with WithLimit.
+func FilterTransactions(ctx context.Context, transactions []models.Transaction) []models.Transaction {
+	filter, ok := ctx.Value(transactionFilterKey{}).(TransactionFilter)
+	if !ok {
+		return LimitTransactions(ctx, transactions)
+	}
+	kept := make([]models.Transaction, 0, len(transactions))
+	for _, tx := range transactions {
+		if filter.Matches(tx) {
+			kept = append(kept, tx)
+		}
+	}
+	return LimitTransactions(ctx, kept)
+}
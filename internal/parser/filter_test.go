@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterTransactions(t *testing.T) {
+	transactions := []models.Transaction{
+		{Description: "SBB CFF FFS Ticket"},
+		{Description: "Migros Lausanne"},
+		{Description: "sbb mobile"},
+	}
+
+	// Without a filter everything is kept
+	assert.Len(t, FilterTransactions(context.Background(), transactions), 3)
+
+	ctx := WithTransactionFilter(context.Background(), TransactionFilter{
+		Description: regexp.MustCompile("(?i)^sbb"),
+	})
+	kept := FilterTransactions(ctx, transactions)
+	assert.Equal(t, []models.Transaction{transactions[0], transactions[2]}, kept)
+
+	// An empty filter keeps everything
+	ctx = WithTransactionFilter(context.Background(), TransactionFilter{})
+	assert.Len(t, FilterTransactions(ctx, transactions), 3)
+}
+
+func TestFilterTransactions_SkipZero(t *testing.T) {
+	transactions := []models.Transaction{
+		{Description: "Card payment", Amount: decimal.RequireFromString("-12.50")},
+		{Description: "Interest statement notice", Amount: decimal.Zero},
+		{Description: "Salary", Amount: decimal.RequireFromString("4200")},
+	}
+
+	ctx := WithTransactionFilter(context.Background(), TransactionFilter{SkipZero: true})
+	kept := FilterTransactions(ctx, transactions)
+	assert.Equal(t, []models.Transaction{transactions[0], transactions[2]}, kept)
+
+	// Combined with a description filter, both must match
+	ctx = WithTransactionFilter(context.Background(), TransactionFilter{
+		Description: regexp.MustCompile("(?i)n"),
+		SkipZero:    true,
+	})
+	assert.Equal(t, []models.Transaction{transactions[0]}, FilterTransactions(ctx, transactions))
+}
+
+func TestFilterTransactions_BookedOnly(t *testing.T) {
+	transactions := []models.Transaction{
+		{Description: "Booked", Status: models.EntryStatusBooked},
+		{Description: "Pending", Status: models.EntryStatusPending},
+		{Description: "No status"},
+		{Description: "Notice", Status: models.EntryStatusInfo},
+	}
+
+	ctx := WithTransactionFilter(context.Background(), TransactionFilter{BookedOnly: true})
+	assert.Equal(t, []models.Transaction{transactions[0], transactions[2]}, FilterTransactions(ctx, transactions))
+}
+
+func TestFilterTransactions_DropEmpty(t *testing.T) {
+	transactions := []models.Transaction{
+		{PartyName: "Coop"},
+		{Reference: "REF-1", Description: "  "},
+		{Description: "Card payment"},
+	}
+
+	ctx := WithTransactionFilter(context.Background(), TransactionFilter{DropEmpty: true})
+	assert.Equal(t, []models.Transaction{transactions[0], transactions[2]}, FilterTransactions(ctx, transactions))
+}
+
+func TestFilterTransactions_Limit(t *testing.T) {
+	transactions := []models.Transaction{
+		{Description: "SBB ticket"},
+		{Description: "Migros"},
+		{Description: "SBB pass"},
+		{Description: "SBB parking"},
+	}
+
+	ctx := WithLimit(context.Background(), 2)
+	assert.Equal(t, transactions[:2], FilterTransactions(ctx, transactions))
+
+	// The limit counts the transactions kept by the filter
+	ctx = WithTransactionFilter(ctx, TransactionFilter{Description: regexp.MustCompile("(?i)^sbb")})
+	assert.Equal(t, []models.Transaction{transactions[0], transactions[2]}, FilterTransactions(ctx, transactions))
+}
@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+
+	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parsererror"
+)
+
+type maxTransactionsKey struct{}
+
+// WithMaxTransactions returns a context that limits parsing to max
+// transactions. Zero or a negative max means no limit.
+func WithMaxTransactions(ctx context.Context, max int) context.Context {
+	return context.WithValue(ctx, maxTransactionsKey{}, max)
+}
+
+// MaxTransactions returns the transaction limit set with WithMaxTransactions,
+// or 0 when there is none.
+func MaxTransactions(ctx context.Context) int {
+	if max, ok := ctx.Value(maxTransactionsKey{}).(int); ok && max > 0 {
+		return max
+	}
+	return 0
+}
+
+// CheckTransactionLimit returns an error wrapping
+// parsererror.ErrTooManyTransactions when count exceeds the limit in ctx.
+// Parsers call it as they collect transactions, so that a huge or corrupt file
+// is rejected before it is fully loaded.
+//
+// It also returns the cause of ctx once ctx is done, as when --timeout
+// expires: parsers keep going when categorization fails, so the conversion
+// must not write what they return.
+func CheckTransactionLimit(ctx context.Context, count int) error {
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	if max := MaxTransactions(ctx); max > 0 && count > max {
+		return fmt.Errorf("%w: more than %d transactions (raise --max-transactions to allow more)",
+			parsererror.ErrTooManyTransactions, max)
+	}
+	return nil
+}
+
+type limitKey struct{}
+
+// WithLimit returns a context in which conversions write at most limit
+// transactions, counted after filtering, for quick previews. Zero or a
+// negative limit means no limit.
+func WithLimit(ctx context.Context, limit int) context.Context {
+	return context.WithValue(ctx, limitKey{}, limit)
+}
+
+// Limit returns the limit set with WithLimit, or 0 when there is none.
+func Limit(ctx context.Context) int {
+	if limit, ok := ctx.Value(limitKey{}).(int); ok && limit > 0 {
+		return limit
+	}
+	return 0
+}
+
+// LimitTransactions returns the first transactions up to the limit in ctx.
+func LimitTransactions(ctx context.Context, transactions []models.Transaction) []models.Transaction {
+	if limit := Limit(ctx); limit > 0 && len(transactions) > limit {
+		return transactions[:limit]
+	}
+	return transactions
+}
+
+// LimitReached reports whether a parser holding count transactions may stop
+// reading: a limit is set and reached, and no filter can drop any of them.
+// Parsers that check it skip categorizing transactions that would not be
+// written.
+func LimitReached(ctx context.Context, count int) bool {
+	if _, filtered := ctx.Value(transactionFilterKey{}).(TransactionFilter); filtered {
+		return false
+	}
+	limit := Limit(ctx)
+	return limit > 0 && count >= limit
+}
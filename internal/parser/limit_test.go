@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parsererror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckTransactionLimit(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, 0, MaxTransactions(ctx))
+	assert.NoError(t, CheckTransactionLimit(ctx, 1_000_000))
+
+	limited := WithMaxTransactions(ctx, 3)
+	assert.Equal(t, 3, MaxTransactions(limited))
+	assert.NoError(t, CheckTransactionLimit(limited, 3))
+
+	err := CheckTransactionLimit(limited, 4)
+	assert.ErrorIs(t, err, parsererror.ErrTooManyTransactions)
+	assert.ErrorContains(t, err, "more than 3 transactions")
+
+	// Zero means unlimited
+	assert.NoError(t, CheckTransactionLimit(WithMaxTransactions(ctx, 0), 4))
+}
+
+func TestLimitTransactions(t *testing.T) {
+	transactions := []models.Transaction{{Description: "a"}, {Description: "b"}, {Description: "c"}}
+
+	ctx := context.Background()
+	assert.Equal(t, 0, Limit(ctx))
+	assert.Len(t, LimitTransactions(ctx, transactions), 3)
+
+	limited := WithLimit(ctx, 2)
+	assert.Equal(t, transactions[:2], LimitTransactions(limited, transactions))
+	assert.Len(t, LimitTransactions(WithLimit(ctx, 5), transactions), 3)
+
+	assert.False(t, LimitReached(limited, 1))
+	assert.True(t, LimitReached(limited, 2))
+	assert.False(t, LimitReached(ctx, 100))
+
+	// With a filter, later transactions may still be needed
+	filtered := WithTransactionFilter(limited, TransactionFilter{SkipZero: true})
+	assert.False(t, LimitReached(filtered, 2))
+}
+
+func TestCheckTransactionLimit_ContextDone(t *testing.T) {
+	timeout := errors.New("run exceeded --timeout 1s")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(timeout)
+
+	assert.ErrorIs(t, CheckTransactionLimit(ctx, 0), timeout)
+}
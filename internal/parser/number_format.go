@@ -0,0 +1,24 @@
+package parser
+
+import (
+	"context"
+
+	"fjacquet/camt-csv/internal/models"
+)
+
+type numberFormatKey struct{}
+
+// WithNumberFormat returns a context telling the parsers which separators the
+// amounts of CSV input are written with.
+func WithNumberFormat(ctx context.Context, format models.NumberFormat) context.Context {
+	return context.WithValue(ctx, numberFormatKey{}, format)
+}
+
+// NumberFormat returns the number format set with WithNumberFormat, or
+// models.NumberFormatAuto when there is none.
+func NumberFormat(ctx context.Context) models.NumberFormat {
+	if format, ok := ctx.Value(numberFormatKey{}).(models.NumberFormat); ok && format != "" {
+		return format
+	}
+	return models.NumberFormatAuto
+}
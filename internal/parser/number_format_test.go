@@ -0,0 +1,16 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumberFormat(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, models.NumberFormatAuto, NumberFormat(ctx))
+	assert.Equal(t, models.NumberFormatComma, NumberFormat(WithNumberFormat(ctx, models.NumberFormatComma)))
+}
@@ -1,88 +1,34 @@
+// Package parser provides the shared parsing helpers of the built-in parsers:
+// account selection, transaction filters and limits, encodings and number
+// formats.
+//
+// The parser interfaces, BaseParser and the parser registry are public, in
+// fjacquet/camt-csv/pkg/parser, so that parsers can be added from another
+// module. They are aliased here for the code of this module.
 package parser
 
-import (
-	"context"
-	"io"
-
-	"fjacquet/camt-csv/internal/logging"
-	"fjacquet/camt-csv/internal/models"
+import "fjacquet/camt-csv/pkg/parser"
+
+// Parser interfaces and the base parser, see fjacquet/camt-csv/pkg/parser.
+type (
+	Parser                  = parser.Parser
+	Validator               = parser.Validator
+	CSVConverter            = parser.CSVConverter
+	LoggerConfigurable      = parser.LoggerConfigurable
+	CategorizerConfigurable = parser.CategorizerConfigurable
+	BatchConverter          = parser.BatchConverter
+	StatementInfoReader     = parser.StatementInfoReader
+	FullParser              = parser.FullParser
+	BaseParser              = parser.BaseParser
+	Factory                 = parser.Factory
 )
 
-// Parser defines the core parsing capability.
-// This interface follows the Interface Segregation Principle by containing only
-// the essential parsing method that all parsers must implement.
-type Parser interface {
-	// Parse reads data from the provided io.Reader and returns a slice of Transaction models.
-	// It is responsible for understanding the specific input format (e.g., CAMT XML, PDF, CSV)
-	// and transforming it into the standardized Transaction structure.
-	// Implementations should return custom error types (e.g., InvalidFormatError, DataExtractionError)
-	// for specific parsing failures.
-	//
-	// Parameters:
-	//   - ctx: Context for cancellation and timeout control
-	//   - r: Reader containing the data to parse
-	Parse(ctx context.Context, r io.Reader) ([]models.Transaction, error)
-}
-
-// Validator defines format validation capability.
-// Not all parsers need validation, so this is separated from the core Parser interface.
-type Validator interface {
-	// ValidateFormat checks if the given file path contains data in the expected format.
-	// Returns true if the format is valid, false otherwise, along with any error encountered.
-	ValidateFormat(filePath string) (bool, error)
-}
-
-// CSVConverter defines CSV conversion capability.
-// This interface allows parsers to provide a convenient method for converting
-// input files directly to CSV format without requiring separate Parse and Write steps.
-type CSVConverter interface {
-	// ConvertToCSV converts an input file to CSV format and writes it to the output file.
-	// This is a convenience method that typically combines Parse and WriteToCSV operations.
-	//
-	// Parameters:
-	//   - ctx: Context for cancellation and timeout control
-	//   - inputFile: Path to the input file
-	//   - outputFile: Path to the output CSV file
-	ConvertToCSV(ctx context.Context, inputFile, outputFile string) error
-}
-
-// LoggerConfigurable defines the ability to configure logging.
-// This allows parsers to accept logger instances for structured logging.
-type LoggerConfigurable interface {
-	// SetLogger configures the logger instance for the parser.
-	// Parsers should use this logger for all logging operations.
-	SetLogger(logger logging.Logger)
-}
+// NewBaseParser creates a BaseParser, see parser.NewBaseParser.
+var NewBaseParser = parser.NewBaseParser
 
-// CategorizerConfigurable defines the ability to configure categorization.
-// This allows parsers to accept categorizer instances for transaction categorization.
-type CategorizerConfigurable interface {
-	// SetCategorizer configures the categorizer instance for the parser.
-	// Parsers should use this categorizer for transaction categorization.
-	SetCategorizer(categorizer models.TransactionCategorizer)
-}
-
-// BatchConverter defines batch conversion capability.
-// This interface allows parsers to convert multiple files in a directory.
-type BatchConverter interface {
-	// BatchConvert converts all files in inputDir and writes them to outputDir.
-	// Returns the number of files converted and any error encountered.
-	//
-	// Parameters:
-	//   - ctx: Context for cancellation and timeout control
-	//   - inputDir: Directory containing input files
-	//   - outputDir: Directory for output files
-	BatchConvert(ctx context.Context, inputDir, outputDir string) (int, error)
-}
-
-// FullParser combines all parser capabilities into a single interface.
-// Use this interface when you need a parser with all available features.
-// Individual interfaces should be used when only specific capabilities are required.
-type FullParser interface {
-	Parser
-	Validator
-	CSVConverter
-	LoggerConfigurable
-	CategorizerConfigurable
-	BatchConverter
-}
+// Parser registry, see fjacquet/camt-csv/pkg/parser.
+var (
+	RegisterParser    = parser.RegisterParser
+	RegisteredParsers = parser.RegisteredParsers
+	LookupParser      = parser.LookupParser
+)
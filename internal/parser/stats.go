@@ -0,0 +1,39 @@
+package parser
+
+import (
+	"context"
+
+	"fjacquet/camt-csv/internal/models"
+)
+
+type categorizationStatsKey struct{}
+
+// WithCategorizationStats returns a context in which the transactions written
+// by the conversion paths are counted into stats.
+func WithCategorizationStats(ctx context.Context, stats *models.CategorizationStats) context.Context {
+	return context.WithValue(ctx, categorizationStatsKey{}, stats)
+}
+
+// RecordCategorization counts transactions into the stats set with
+// WithCategorizationStats, if any. A transaction that no categorization method
+// matched counts as uncategorized.
+func RecordCategorization(ctx context.Context, transactions []models.Transaction) {
+	stats, ok := ctx.Value(categorizationStatsKey{}).(*models.CategorizationStats)
+	if !ok || stats == nil {
+		return
+	}
+	for _, tx := range transactions {
+		stats.IncrementTotal()
+		if IsUncategorized(tx) {
+			stats.IncrementUncategorized()
+		} else {
+			stats.IncrementSuccessful()
+		}
+	}
+}
+
+// IsUncategorized reports whether tx was left with the fallback category.
+func IsUncategorized(tx models.Transaction) bool {
+	return tx.CategorySource == models.CategorySourceFallback ||
+		tx.Category == "" || tx.Category == models.CategoryUncategorized
+}
@@ -0,0 +1,31 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordCategorization(t *testing.T) {
+	transactions := []models.Transaction{
+		{Category: "Groceries", CategorySource: models.CategorySourceMapping},
+		{Category: models.CategoryUncategorized, CategorySource: models.CategorySourceFallback},
+		{Category: "Misc", CategorySource: models.CategorySourceFallback}, // ai.fallback_category
+		{},
+	}
+
+	// Without stats in the context nothing is recorded
+	RecordCategorization(context.Background(), transactions)
+
+	stats := models.NewCategorizationStats()
+	ctx := WithCategorizationStats(context.Background(), stats)
+	RecordCategorization(ctx, transactions)
+	RecordCategorization(ctx, transactions[:1])
+
+	assert.Equal(t, 5, stats.Total)
+	assert.Equal(t, 2, stats.Successful)
+	assert.Equal(t, 3, stats.Uncategorized)
+}
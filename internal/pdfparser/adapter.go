@@ -11,12 +11,21 @@ import (
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
 	"fjacquet/camt-csv/internal/parser"
+	"fjacquet/camt-csv/internal/parsererror"
 )
 
 // Adapter implements the parser.FullParser interface for PDF bank statements.
 type Adapter struct {
 	parser.BaseParser
 	extractor PDFExtractor
+	ocr       PDFExtractor
+	viseca    visecaOptions
+}
+
+func init() {
+	parser.RegisterParser("pdf", func(logger logging.Logger) parser.FullParser {
+		return NewAdapter(logger, nil) // nil for real extractor
+	})
 }
 
 // NewAdapter creates a new adapter for the pdfparser with dependency injection.
@@ -30,16 +39,41 @@ func NewAdapter(logger logging.Logger, extractor PDFExtractor) *Adapter {
 	}
 }
 
+// SetStrict makes Parse fail with ErrStatementTotalMismatch, instead of only
+// warning, when a Viseca statement total does not match its transactions.
+func (a *Adapter) SetStrict(strict bool) {
+	a.viseca.strict = strict
+}
+
+// SetRefundCategory gives card refunds the category instead of the
+// category of the merchant they were bought from, so that they do not count
+// as spending there. An empty category keeps the merchant's.
+func (a *Adapter) SetRefundCategory(category string) {
+	a.viseca.refundCategory = category
+}
+
+// EnableOCR makes Parse read PDFs without extractable text, such as scanned
+// statements, with tesseract. It returns ErrTesseractNotFound when tesseract
+// is not installed.
+func (a *Adapter) EnableOCR() error {
+	ocr, err := NewOCRExtractor()
+	if err != nil {
+		return err
+	}
+	a.ocr = ocr
+	return nil
+}
+
 // Parse reads data from the provided io.Reader and returns a slice of Transaction models.
 func (a *Adapter) Parse(ctx context.Context, r io.Reader) ([]models.Transaction, error) {
-	return ParseWithExtractorAndCategorizer(ctx, r, a.extractor, a.GetLogger(), a.GetCategorizer())
+	return parseWithExtractor(ctx, r, a.extractor, a.ocr, a.GetLogger(), a.GetCategorizer(), a.viseca)
 }
 
 // ConvertToCSV implements parser.FullParser.ConvertToCSV
 func (a *Adapter) ConvertToCSV(ctx context.Context, inputFile, outputFile string) error {
 	file, err := os.Open(inputFile) // #nosec G304 -- CLI tool requires user-provided file paths
 	if err != nil {
-		return fmt.Errorf("error opening input file: %w", err)
+		return fmt.Errorf("%w: error opening input file: %w", parsererror.ErrReadFailed, err)
 	}
 	defer func() {
 		if err := file.Close(); err != nil {
@@ -61,8 +95,9 @@ func (a *Adapter) ValidateFormat(file string) (bool, error) {
 	a.GetLogger().Info("Validating PDF format",
 		logging.Field{Key: "file", Value: file})
 
-	// Try to extract text as a validation check using the injected extractor
-	_, err := a.extractor.ExtractText(file)
+	// Try to extract text as a validation check using the injected extractor;
+	// the interface gives validation no context, so it cannot be cancelled
+	_, err := a.extractor.ExtractText(context.Background(), file)
 	if err != nil {
 		a.GetLogger().WithError(err).Error("PDF validation failed")
 		return false, nil
@@ -1,12 +1,15 @@
 package pdfparser
 
+import "context"
+
 // PDFExtractor defines the interface for extracting text from PDF files.
 // This interface allows for dependency injection and makes the PDF parser testable
 // by providing different implementations for production and testing.
 type PDFExtractor interface {
 	// ExtractText extracts text content from a PDF file at the given path.
 	// Returns the extracted text as a string or an error if extraction fails.
-	ExtractText(pdfPath string) (string, error)
+	// The extraction is stopped when ctx is done.
+	ExtractText(ctx context.Context, pdfPath string) (string, error)
 }
 
 // RealPDFExtractor implements PDFExtractor using the actual pdftotext command.
@@ -19,8 +22,8 @@ func NewRealPDFExtractor() *RealPDFExtractor {
 }
 
 // ExtractText extracts text from a PDF file using the pdftotext command.
-func (e *RealPDFExtractor) ExtractText(pdfPath string) (string, error) {
-	return extractTextFromPDF(pdfPath)
+func (e *RealPDFExtractor) ExtractText(ctx context.Context, pdfPath string) (string, error) {
+	return extractTextFromPDF(ctx, pdfPath)
 }
 
 // MockPDFExtractor implements PDFExtractor for testing purposes.
@@ -39,7 +42,7 @@ func NewMockPDFExtractor(mockText string, mockErr error) *MockPDFExtractor {
 }
 
 // ExtractText returns the predefined mock text or error.
-func (e *MockPDFExtractor) ExtractText(pdfPath string) (string, error) {
+func (e *MockPDFExtractor) ExtractText(_ context.Context, pdfPath string) (string, error) {
 	if e.MockErr != nil {
 		return "", e.MockErr
 	}
@@ -0,0 +1,79 @@
+package pdfparser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrTesseractNotFound is returned when OCR is requested but the tesseract
+// binary is not installed.
+var ErrTesseractNotFound = errors.New("--ocr requires tesseract, which was not found in PATH; install it (e.g. apt install tesseract-ocr or brew install tesseract)")
+
+// ocrResolution is the DPI pages are rendered at before OCR. Tesseract reads
+// statement-sized print best at 300 DPI.
+const ocrResolution = "300"
+
+// OCRExtractor implements PDFExtractor by rendering each page to an image with
+// pdftoppm and reading it with tesseract. It is used for scanned statements,
+// where pdftotext finds no text.
+type OCRExtractor struct {
+	tesseract string
+	pdftoppm  string
+}
+
+// NewOCRExtractor creates an OCRExtractor, or returns ErrTesseractNotFound
+// when tesseract is not installed.
+func NewOCRExtractor() (*OCRExtractor, error) {
+	tesseract, err := exec.LookPath("tesseract")
+	if err != nil {
+		return nil, ErrTesseractNotFound
+	}
+	pdftoppm, err := exec.LookPath("pdftoppm")
+	if err != nil {
+		return nil, fmt.Errorf("--ocr requires pdftoppm (part of poppler-utils), which was not found in PATH: %w", err)
+	}
+	return &OCRExtractor{tesseract: tesseract, pdftoppm: pdftoppm}, nil
+}
+
+// ExtractText renders the pages of the PDF and returns their OCR text,
+// separated by form feeds as pdftotext does.
+func (e *OCRExtractor) ExtractText(ctx context.Context, pdfPath string) (string, error) {
+	imageDir, err := os.MkdirTemp("", "pdfocr-*")
+	if err != nil {
+		return "", fmt.Errorf("error creating temporary directory: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(imageDir); err != nil {
+			getDefaultLogger().WithError(err).Warn("Failed to remove temporary directory")
+		}
+	}()
+
+	cmd := exec.CommandContext(ctx, e.pdftoppm, "-r", ocrResolution, "-png", pdfPath, filepath.Join(imageDir, "page")) // #nosec G204 -- Expected subprocess for PDF rendering
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("error running pdftoppm: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	pages, err := filepath.Glob(filepath.Join(imageDir, "page*.png"))
+	if err != nil {
+		return "", fmt.Errorf("error listing rendered pages: %w", err)
+	}
+	// pdftoppm pads page numbers to the same width, so names sort in page order
+	sort.Strings(pages)
+
+	texts := make([]string, 0, len(pages))
+	for _, page := range pages {
+		cmd := exec.CommandContext(ctx, e.tesseract, page, "stdout") // #nosec G204 -- Expected subprocess for OCR
+		output, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("error running tesseract on %s: %w", filepath.Base(page), err)
+		}
+		texts = append(texts, string(output))
+	}
+	return strings.Join(texts, "\f"), nil
+}
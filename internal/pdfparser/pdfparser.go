@@ -2,19 +2,30 @@ package pdfparser
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode"
 
+	"fjacquet/camt-csv/internal/common"
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parser"
 	"fjacquet/camt-csv/internal/parsererror"
 )
 
 // ParseWithExtractorAndCategorizer extracts and parses transaction data from a PDF file using the provided extractor and categorizer.
 func ParseWithExtractorAndCategorizer(ctx context.Context, r io.Reader, extractor PDFExtractor, logger logging.Logger, categorizer models.TransactionCategorizer) ([]models.Transaction, error) {
+	return parseWithExtractor(ctx, r, extractor, nil, logger, categorizer, visecaOptions{})
+}
+
+// parseWithExtractor implements ParseWithExtractorAndCategorizer. When the
+// extracted text is empty and ocr is not nil, the text is read with ocr
+// instead. Viseca statements are parsed with opts.
+func parseWithExtractor(ctx context.Context, r io.Reader, extractor, ocr PDFExtractor, logger logging.Logger, categorizer models.TransactionCategorizer, opts visecaOptions) ([]models.Transaction, error) {
 	if logger == nil {
 		logger = logging.NewLogrusAdapter("info", "text")
 	}
@@ -42,7 +53,7 @@ func ParseWithExtractorAndCategorizer(ctx context.Context, r io.Reader, extracto
 	_, err = io.Copy(pdfFile, r)
 	if err != nil {
 		_ = pdfFile.Close()
-		return nil, fmt.Errorf("failed to write to temporary PDF file: %w", err)
+		return nil, fmt.Errorf("%w: failed to write to temporary PDF file: %w", parsererror.ErrReadFailed, err)
 	}
 
 	// Close the file before external extraction command accesses it
@@ -54,7 +65,7 @@ func ParseWithExtractorAndCategorizer(ctx context.Context, r io.Reader, extracto
 		logging.Field{Key: "file", Value: pdfPath})
 
 	// Extract text from PDF (validates format and extracts in one call)
-	text, err := extractor.ExtractText(pdfPath)
+	text, err := extractor.ExtractText(ctx, pdfPath)
 	if err != nil {
 		return nil, &parsererror.ParseError{
 			Parser: "PDF",
@@ -64,9 +75,43 @@ func ParseWithExtractorAndCategorizer(ctx context.Context, r io.Reader, extracto
 		}
 	}
 
+	// pdftotext builds differ in their default output encoding (poppler writes
+	// UTF-8, xpdf Latin-1), so the text goes through the input decoding too.
+	decoded, err := common.DecodeToUTF8([]byte(text), parser.InputEncoding(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", parsererror.ErrReadFailed, err)
+	}
+	text = string(decoded)
+
 	logger.Debug("Extracted PDF text for processing",
 		logging.Field{Key: "text_length", Value: len(text)})
 
+	// A scanned statement is only images: pdftotext succeeds but finds no text
+	if !hasExtractableText(text) && ocr != nil {
+		logger.Info("PDF contains no text, running OCR",
+			logging.Field{Key: "file", Value: pdfPath})
+		// Tesseract always writes UTF-8
+		text, err = ocr.ExtractText(ctx, pdfPath)
+		if err != nil {
+			return nil, &parsererror.ParseError{
+				Parser: "PDF",
+				Field:  "OCR",
+				Value:  pdfPath,
+				Err:    err,
+			}
+		}
+		logger.Debug("Extracted PDF text with OCR",
+			logging.Field{Key: "text_length", Value: len(text)})
+	}
+	if !hasExtractableText(text) {
+		return nil, &parsererror.ParseError{
+			Parser: "PDF",
+			Field:  "text extraction",
+			Value:  pdfPath,
+			Err:    ErrNoText,
+		}
+	}
+
 	// Preprocess the text to clean it up and identify transaction blocks
 	processedText := preProcessText(text)
 
@@ -74,7 +119,7 @@ func ParseWithExtractorAndCategorizer(ctx context.Context, r io.Reader, extracto
 	lines := strings.Split(processedText, "\n")
 
 	// Parse the lines to extract transactions
-	transactions, err := parseTransactionsWithCategorizer(lines, logger, categorizer)
+	transactions, err := parseTransactionsWithCategorizer(ctx, lines, logger, categorizer, opts)
 	if err != nil {
 		return nil, &parsererror.ParseError{
 			Parser: "PDF",
@@ -87,6 +132,30 @@ func ParseWithExtractorAndCategorizer(ctx context.Context, r io.Reader, extracto
 	return transactions, nil
 }
 
+// ErrNoText is returned when the text extracted from a PDF is empty or
+// nearly so, as with scanned statements that only contain images.
+var ErrNoText = errors.New("PDF contains no extractable text; it is probably a scanned image, convert it with --ocr or run it through OCR (e.g. ocrmypdf) first")
+
+// minTextLength is the number of non-whitespace characters below which the
+// extracted text is taken as empty. Page numbers or a lone header are shorter;
+// a statement with even one transaction is longer.
+const minTextLength = 20
+
+// hasExtractableText reports whether text holds at least minTextLength
+// non-whitespace characters.
+func hasExtractableText(text string) bool {
+	count := 0
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			count++
+			if count >= minTextLength {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // validateFormat checks if a file is a valid PDF.
 // It verifies that the file exists and has the correct format headers.
 //
@@ -0,0 +1,65 @@
+package pdfparser
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"fjacquet/camt-csv/internal/logging"
+)
+
+// benchmarkStatement returns the text of a card statement with n transactions.
+func benchmarkStatement(n int) string {
+	var sb strings.Builder
+	sb.WriteString("Date valeur Détails Monnaie Montant\n")
+	for i := 0; i < n; i++ {
+		day := i%28 + 1
+		fmt.Fprintf(&sb, "%02d.01.25 %02d.01.25 Merchant %d Lausanne CHF %d.%02d\n", day, day, i, 10+i%90, i%100)
+		if i%3 == 0 {
+			sb.WriteString("Taux de conversion 1.0234\n")
+		}
+	}
+	return sb.String()
+}
+
+// BenchmarkParseStatement parses a statement of several hundred lines, with
+// the line patterns compiled once at package level.
+func BenchmarkParseStatement(b *testing.B) {
+	text := benchmarkStatement(500)
+	logger := logging.NewLogrusAdapter("error", "text")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := parseWithExtractor(context.Background(), strings.NewReader("%PDF-1.4"),
+			NewMockPDFExtractor(text, nil), nil, logger, nil, visecaOptions{})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkLineDatePattern compares matching every line of a statement with
+// the package-level date pattern against compiling it for each line.
+func BenchmarkLineDatePattern(b *testing.B) {
+	lines := strings.Split(benchmarkStatement(500), "\n")
+
+	b.Run("package-level", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, line := range lines {
+				dateValuePattern.FindStringSubmatch(line)
+			}
+		}
+	})
+	b.Run("per-line", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, line := range lines {
+				regexp.MustCompile(dateValuePattern.String()).FindStringSubmatch(line)
+			}
+		}
+	})
+}
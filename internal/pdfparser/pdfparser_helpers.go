@@ -2,6 +2,7 @@
 package pdfparser
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -15,6 +16,7 @@ import (
 	"fjacquet/camt-csv/internal/dateutils"
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parser"
 
 	"github.com/shopspring/decimal"
 )
@@ -81,7 +83,7 @@ func getDefaultLogger() logging.Logger {
 // Note: This is intentionally a package-level variable to support testing
 var extractTextFromPDF = extractTextFromPDFImpl
 
-func extractTextFromPDFImpl(pdfFile string) (string, error) {
+func extractTextFromPDFImpl(ctx context.Context, pdfFile string) (string, error) {
 	// SECURITY: Create a temporary file with random unpredictable name to store the text output
 	// This prevents attacks that rely on predictable temp file names
 	tempFile, err := os.CreateTemp("", "pdftext-*.txt")
@@ -104,7 +106,7 @@ func extractTextFromPDFImpl(pdfFile string) (string, error) {
 
 	// Use pdftotext command-line tool to extract text
 	// Add the -raw option to preserve the original text layout
-	cmd := exec.Command("pdftotext", "-layout", "-raw", pdfFile, tempFileName) // #nosec G204,G702 -- Expected subprocess for PDF text extraction
+	cmd := exec.CommandContext(ctx, "pdftotext", "-layout", "-raw", pdfFile, tempFileName) // #nosec G204,G702 -- Expected subprocess for PDF text extraction
 	err = cmd.Run()
 	if err != nil {
 		return "", fmt.Errorf("error running pdftotext: %w", err)
@@ -119,8 +121,9 @@ func extractTextFromPDFImpl(pdfFile string) (string, error) {
 	return string(output), nil
 }
 
-// parseTransactionsWithCategorizer parses transaction data from PDF text content and applies categorization
-func parseTransactionsWithCategorizer(lines []string, logger logging.Logger, categorizer models.TransactionCategorizer) ([]models.Transaction, error) {
+// parseTransactionsWithCategorizer parses transaction data from PDF text content and applies categorization.
+// Viseca statements are parsed with opts.
+func parseTransactionsWithCategorizer(ctx context.Context, lines []string, logger logging.Logger, categorizer models.TransactionCategorizer, opts visecaOptions) ([]models.Transaction, error) {
 	// Pre-allocate slice with estimated capacity (typically 10-50 transactions per PDF)
 	transactions := make([]models.Transaction, 0, 50)
 	var currentTx models.Transaction
@@ -165,12 +168,17 @@ func parseTransactionsWithCategorizer(lines []string, logger logging.Logger, cat
 
 	// For Viseca format, use a specialized transaction extraction approach
 	if isVisecaFormat {
-		return parseVisecaTransactionsWithCategorizer(lines, logger, categorizer)
+		return parseVisecaTransactionsWithCategorizer(ctx, lines, logger, categorizer, opts)
 	}
 
 	// Standard PDF format parsing continues below
 	// Preprocess the lines to identify transaction blocks
 	for i, line := range lines {
+		// Transactions are finalized as later lines are read, so the limit
+		// is checked on those already collected
+		if err := parser.CheckTransactionLimit(ctx, len(transactions)); err != nil {
+			return nil, err
+		}
 		trimmedLine := strings.TrimSpace(line)
 		if trimmedLine == "" {
 			continue
@@ -249,6 +257,9 @@ func parseTransactionsWithCategorizer(lines []string, logger logging.Logger, cat
 		logger.Debug("Finalizing last transaction")
 		finalizeTransactionWithCategorizer(&currentTx, &description, merchant, seen, &transactions, categorizer, logger)
 	}
+	if err := parser.CheckTransactionLimit(ctx, len(transactions)); err != nil {
+		return nil, err
+	}
 
 	// Sort transactions by date
 	sortTransactions(transactions)
@@ -258,6 +269,7 @@ func parseTransactionsWithCategorizer(lines []string, logger logging.Logger, cat
 
 	// Process transactions with categorization statistics
 	processedTransactions := common.ProcessTransactionsWithCategorizationStats(
+		ctx,
 		transactions, logger, categorizer, "PDF")
 
 	logger.Info("Extracted transactions from PDF",
@@ -265,13 +277,28 @@ func parseTransactionsWithCategorizer(lines []string, logger logging.Logger, cat
 	return processedTransactions, nil
 }
 
-// parseVisecaTransactionsWithCategorizer is a specialized parser for Viseca credit card statements with categorization
-func parseVisecaTransactionsWithCategorizer(lines []string, logger logging.Logger, categorizer models.TransactionCategorizer) ([]models.Transaction, error) {
+// visecaOptions configures how Viseca credit card statements are parsed.
+type visecaOptions struct {
+	// strict makes a statement whose total does not match its transactions an error
+	strict bool
+	// refundCategory is given to card refunds; empty keeps the merchant's category
+	refundCategory string
+}
+
+// parseVisecaTransactionsWithCategorizer is a specialized parser for Viseca credit card statements with categorization.
+// The parsed transactions are checked against the statement total, see checkVisecaTotal.
+// Credit lines are card refunds: they get Type Refund and, when
+// opts.refundCategory is set, that category instead of the merchant's.
+func parseVisecaTransactionsWithCategorizer(ctx context.Context, lines []string, logger logging.Logger, categorizer models.TransactionCategorizer, opts visecaOptions) ([]models.Transaction, error) {
 	logger.Debug("Processing Viseca PDF with specialized parser",
 		logging.Field{Key: "lineCount", Value: len(lines)})
 
 	var transactions []models.Transaction
 	var currentCategory string
+	// currentCard is the card of the section being read: statements of
+	// several cards list each card's transactions under its masked number
+	var currentCard string
+	var summary visecaSummary
 
 	// For debugging, dump the first few lines
 	for i := 0; i < min(20, len(lines)); i++ {
@@ -301,10 +328,29 @@ func parseVisecaTransactionsWithCategorizer(lines []string, logger logging.Logge
 			continue
 		}
 
+		// "Montant total" and "Votre paiement" lines are summaries, not transactions;
+		// keep their amounts for the total check
+		if isVisecaSummaryLine(line) {
+			summary.record(line)
+			logger.Debug("Skipping summary line",
+				logging.Field{Key: "line", Value: line})
+			continue
+		}
+
 		// Check if the line starts with a date (DD.MM.YY or DD.MM.YYYY format)
 		if !datePatternCapture.MatchString(line) {
 			// Not a transaction line, could be a category or additional info
 			// Store it to potentially attach to the previous transaction
+			if card := models.ExtractCardLast4(line); card != "" {
+				currentCard = card
+				logger.Debug("Found card section",
+					logging.Field{Key: "card", Value: card})
+				continue
+			}
+			// A merchant category code line belongs to the transaction above it
+			if mcc, _ := models.ExtractMCC(line); mcc != "" {
+				continue
+			}
 			if strings.TrimSpace(line) != "" && !strings.Contains(line, "XXXX") {
 				currentCategory = strings.TrimSpace(line)
 				logger.Debug("Found potential category line",
@@ -333,14 +379,6 @@ func parseVisecaTransactionsWithCategorizer(lines []string, logger logging.Logge
 		// But first, get the remaining text after the dates
 		remainingLine := strings.TrimSpace(line[len(dateValueMatch[0]):])
 
-		// Check for "Montant total" or "Votre paiement" lines - these are summaries, not transactions
-		if strings.Contains(remainingLine, "Montant total") ||
-			strings.Contains(remainingLine, "Votre paiement") {
-			logger.Debug("Skipping summary line",
-				logging.Field{Key: "line", Value: line})
-			continue
-		}
-
 		// The amount is typically right-aligned at the end
 		// Look for a number pattern at the end, possibly followed by a minus sign
 		amountMatch := amountEndPattern.FindStringSubmatch(remainingLine)
@@ -368,6 +406,9 @@ func parseVisecaTransactionsWithCategorizer(lines []string, logger logging.Logge
 
 		description := strings.TrimSpace(remainingLine[:descriptionEndPos])
 
+		// Some statements print the merchant category code next to the merchant
+		mcc, description := models.ExtractMCC(description)
+
 		// Check for foreign currency indicators
 		var originalCurrency, originalAmount string
 		currencyMatch := foreignCurrencyPattern.FindStringSubmatch(description)
@@ -407,6 +448,23 @@ func parseVisecaTransactionsWithCategorizer(lines []string, logger logging.Logge
 			continue
 		}
 
+		tx.MCC = mcc
+		tx.CardLast4 = models.ExtractCardLast4(line)
+		if tx.CardLast4 == "" {
+			tx.CardLast4 = currentCard
+		}
+
+		// A credit keeps the merchant name, so without marking it the refund
+		// would be categorized like the purchase it reverses
+		if isCredit {
+			tx.Type = models.TypeRefund
+			if opts.refundCategory != "" {
+				tx.Category = opts.refundCategory
+			}
+			logger.Debug("Found card refund",
+				logging.Field{Key: "description", Value: description})
+		}
+
 		// Attach category if we have one
 		if currentCategory != "" {
 			tx.Description = tx.Description + " - " + currentCategory
@@ -431,6 +489,15 @@ func parseVisecaTransactionsWithCategorizer(lines []string, logger logging.Logge
 				break
 			}
 
+			// Look for the merchant category code, when it has a line of its own
+			if tx.MCC == "" {
+				if mcc, _ := models.ExtractMCC(nextLine); mcc != "" {
+					tx.MCC = mcc
+					logger.Debug("Found merchant category code",
+						logging.Field{Key: "mcc", Value: mcc})
+				}
+			}
+
 			// Look for exchange rate information
 			if strings.Contains(nextLine, "Taux de conversion") {
 				exchangeRateMatch := exchangeRatePattern.FindStringSubmatch(nextLine)
@@ -455,6 +522,9 @@ func parseVisecaTransactionsWithCategorizer(lines []string, logger logging.Logge
 		}
 
 		// Add transaction to list
+		if err := parser.CheckTransactionLimit(ctx, len(transactions)+1); err != nil {
+			return nil, err
+		}
 		transactions = append(transactions, tx)
 		logger.Debug("Added transaction",
 			logging.Field{Key: "date", Value: tx.Date},
@@ -462,9 +532,14 @@ func parseVisecaTransactionsWithCategorizer(lines []string, logger logging.Logge
 			logging.Field{Key: "amount", Value: tx.Amount.String()})
 	}
 
+	if err := checkVisecaTotal(summary, transactions, opts.strict, logger); err != nil {
+		return nil, err
+	}
+
 	// Log the number of transactions found
 	// Process transactions with categorization statistics
 	processedTransactions := common.ProcessTransactionsWithCategorizationStats(
+		ctx,
 		transactions, logger, categorizer, "PDF-Viseca")
 
 	logger.Info("Extracted transactions from Viseca PDF",
@@ -489,10 +564,16 @@ func finalizeTransactionWithCategorizer(tx *models.Transaction, desc *strings.Bu
 		creditDebit = determineCreditDebit(cleanDesc)
 	}
 
+	// Default to CHF for PDF statements when no currency was parsed
+	currency := tx.Currency
+	if currency == "" {
+		currency = "CHF"
+	}
+
 	// Use TransactionBuilder to construct the final transaction
 	builder := models.NewTransactionBuilder().
 		WithDatetime(tx.Date).
-		WithAmount(tx.Amount, "CHF"). // Default to CHF for PDF statements
+		WithAmount(tx.Amount, currency).
 		WithDescription(cleanDesc).
 		WithPayee(payee, "").
 		WithCategory(models.CategoryUncategorized)
@@ -528,7 +609,7 @@ func finalizeTransactionWithCategorizer(tx *models.Transaction, desc *strings.Bu
 	}
 
 	// Generate a unique key for deduplication
-	key := fmt.Sprintf("%s-%s-%s", finalTx.Date.Format(dateutils.DateLayoutISO), finalTx.Description, finalTx.Amount.String())
+	key := dedupKey(finalTx)
 
 	// Only add if we haven't seen this transaction before
 	if !seen[key] {
@@ -711,7 +792,16 @@ func containsAmount(line string) bool {
 	return amountCurrencyPattern.MatchString(line)
 }
 
-// deduplicateTransactions removes duplicate transactions based on date, description, and amount
+// dedupKey identifies a transaction for deduplication by date, value date,
+// description, amount and currency. Same-amount transactions in different
+// currencies are distinct.
+func dedupKey(tx models.Transaction) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%s",
+		tx.Date.Format(time.RFC3339), tx.ValueDate.Format(time.RFC3339),
+		tx.Description, tx.Amount.String(), tx.Currency)
+}
+
+// deduplicateTransactions removes duplicate transactions based on date, value date, description, amount and currency
 func deduplicateTransactions(transactions []models.Transaction) []models.Transaction {
 	if len(transactions) <= 1 {
 		return transactions
@@ -721,9 +811,7 @@ func deduplicateTransactions(transactions []models.Transaction) []models.Transac
 	var result []models.Transaction
 
 	for _, tx := range transactions {
-		// Create a key using date, description, and amount
-		key := fmt.Sprintf("%s|%s|%s", tx.Date, tx.Description, tx.Amount.String())
-
+		key := dedupKey(tx)
 		if !seen[key] {
 			seen[key] = true
 			result = append(result, tx)
@@ -754,15 +842,30 @@ func determineCreditDebit(description string) string {
 	return models.TransactionTypeDebit
 }
 
+// now returns the current time; tests replace it to pin the century of
+// two-digit years.
+var now = time.Now
+
+// shortDateLayout is the DD.MM.YY layout of card statements.
+const shortDateLayout = "02.01.06"
+
+// maxFutureYears is how far past today a two-digit year may put a date before
+// it is read as 19YY instead of 20YY. Statements are issued after their
+// transactions, so a date further ahead belongs to the previous century.
+const maxFutureYears = 1
+
 // formatDate parses a date string and returns time.Time
 func formatDate(date string) time.Time {
 	// Remove any non-digit or dot characters
 	date = nonDigitDotPattern.ReplaceAllString(date, "")
 
+	if t, err := parseShortDate(date); err == nil {
+		return t
+	}
+
 	// Try to identify the format
 	formats := []string{
 		dateutils.DateLayoutEuropean, // DD.MM.YYYY
-		"02.01.06",                   // DD.MM.YY
 		"2/1/2006",                   // M/D/YYYY
 		"1/2/2006",                   // D/M/YYYY
 		dateutils.DateLayoutISO,      // YYYY-MM-DD
@@ -786,6 +889,22 @@ func formatDate(date string) time.Time {
 	return t
 }
 
+// parseShortDate parses a DD.MM.YY date. time.Parse puts 69-99 in the 1900s
+// and 00-68 in the 2000s; here the year is 20YY unless that is more than
+// maxFutureYears after today, so statements from 2069 on still get 20YY.
+func parseShortDate(date string) (time.Time, error) {
+	t, err := time.Parse(shortDateLayout, date)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	year := 2000 + t.Year()%100
+	if time.Date(year, t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).After(now().AddDate(maxFutureYears, 0, 0)) {
+		year -= 100
+	}
+	return time.Date(year, t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
 // min returns the smaller of x or y
 func min(x, y int) int {
 	if x < y {
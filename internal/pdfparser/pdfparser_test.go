@@ -246,6 +246,81 @@ func TestParseWithExtractorAndCategorizer(t *testing.T) {
 	assert.NotNil(t, transactions)
 }
 
+func TestParseWithExtractor_EmptyText(t *testing.T) {
+	for name, text := range map[string]string{
+		"empty":       "",
+		"form feeds":  "\f\f\n\f",
+		"page number": "  1 / 2\n\f",
+	} {
+		t.Run(name, func(t *testing.T) {
+			transactions, err := ParseWithExtractorAndCategorizer(context.Background(),
+				strings.NewReader("%PDF-1.4 scanned"), NewMockPDFExtractor(text, nil), logging.NewMockLogger(), nil)
+			require.ErrorIs(t, err, ErrNoText)
+			assert.Contains(t, err.Error(), "OCR")
+			assert.Nil(t, transactions)
+		})
+	}
+}
+
+func TestAdapter_ConvertToCSV_EmptyTextWritesNoFile(t *testing.T) {
+	dir := t.TempDir()
+	inputFile := filepath.Join(dir, "scanned.pdf")
+	outputFile := filepath.Join(dir, "scanned.csv")
+	require.NoError(t, os.WriteFile(inputFile, []byte("%PDF-1.4 scanned"), 0600))
+
+	adapter := NewAdapter(logging.NewMockLogger(), NewMockPDFExtractor("\f", nil))
+	err := adapter.ConvertToCSV(context.Background(), inputFile, outputFile)
+	assert.ErrorIs(t, err, ErrNoText)
+	assert.NoFileExists(t, outputFile)
+}
+
+func TestParseWithExtractor_OCRFallback(t *testing.T) {
+	ocrText := "Date valeur Détails Monnaie Montant\n01.01.25 02.01.25 Boulangerie du Coin CHF 8.40"
+	transactions, err := parseWithExtractor(context.Background(), strings.NewReader("%PDF-1.4 scanned"),
+		NewMockPDFExtractor("\f", nil), NewMockPDFExtractor(ocrText, nil), logging.NewMockLogger(), nil, visecaOptions{})
+	require.NoError(t, err)
+	require.NotEmpty(t, transactions)
+	assert.Contains(t, transactions[0].Description, "Boulangerie")
+}
+
+func TestParseWithExtractor_OCROnlyWithoutText(t *testing.T) {
+	// The OCR extractor must not run when pdftotext finds text
+	mockText := "Date valeur Détails Monnaie Montant\n01.01.25 02.01.25 Café du Lac CHF 12.50"
+	transactions, err := parseWithExtractor(context.Background(), strings.NewReader("%PDF-1.4"),
+		NewMockPDFExtractor(mockText, nil), NewMockPDFExtractor("", assert.AnError), logging.NewMockLogger(), nil, visecaOptions{})
+	require.NoError(t, err)
+	require.NotEmpty(t, transactions)
+}
+
+func TestParseWithExtractor_OCRErrors(t *testing.T) {
+	_, err := parseWithExtractor(context.Background(), strings.NewReader("%PDF-1.4 scanned"),
+		NewMockPDFExtractor("", nil), NewMockPDFExtractor("", assert.AnError), logging.NewMockLogger(), nil, visecaOptions{})
+	assert.ErrorIs(t, err, assert.AnError)
+
+	// OCR that finds no text either still reports ErrNoText
+	_, err = parseWithExtractor(context.Background(), strings.NewReader("%PDF-1.4 scanned"),
+		NewMockPDFExtractor("", nil), NewMockPDFExtractor(" \f ", nil), logging.NewMockLogger(), nil, visecaOptions{})
+	assert.ErrorIs(t, err, ErrNoText)
+}
+
+func TestAdapter_EnableOCR_WithoutTesseract(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	adapter := NewAdapter(logging.NewMockLogger(), NewMockPDFExtractor("", nil))
+	assert.ErrorIs(t, adapter.EnableOCR(), ErrTesseractNotFound)
+}
+
+func TestParseWithExtractor_Latin1Text(t *testing.T) {
+	// xpdf's pdftotext writes Latin-1 by default: "Détails" and "Café" arrive as single bytes
+	mockText := "Date valeur D\xe9tails Monnaie Montant\n01.01.25 02.01.25 Caf\xe9 du Lac CHF 12.50"
+	mockExtractor := NewMockPDFExtractor(mockText, nil)
+
+	transactions, err := ParseWithExtractorAndCategorizer(context.Background(), strings.NewReader("dummy content"),
+		mockExtractor, logging.NewMockLogger(), nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, transactions)
+	assert.Contains(t, transactions[0].Description, "Café du Lac")
+}
+
 func TestAdapterConvertToCSV(t *testing.T) {
 	tempDir := t.TempDir()
 	inputFile := filepath.Join(tempDir, "input.pdf")
@@ -700,6 +775,43 @@ func TestDeduplicateTransactions(t *testing.T) {
 	assert.Equal(t, "Gas Station", result[1].Description)
 }
 
+func TestDeduplicateTransactions_DifferentCurrencies(t *testing.T) {
+	day := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	transactions := []models.Transaction{
+		{Date: day, ValueDate: day, Description: "Card payment", Amount: models.ParseAmount("100.00"), Currency: "CHF"},
+		{Date: day, ValueDate: day, Description: "Card payment", Amount: models.ParseAmount("100.00"), Currency: "EUR"},
+		{Date: day, ValueDate: day.AddDate(0, 0, 1), Description: "Card payment", Amount: models.ParseAmount("100.00"), Currency: "EUR"},
+	}
+
+	result := deduplicateTransactions(transactions)
+
+	require.Len(t, result, 3, "different currency or value date must not be merged")
+	assert.Equal(t, "CHF", result[0].Currency)
+	assert.Equal(t, "EUR", result[1].Currency)
+}
+
+func TestFinalizeTransaction_SameAmountDifferentCurrency(t *testing.T) {
+	var transactions []models.Transaction
+	seen := make(map[string]bool)
+	logger := logging.NewLogrusAdapter("error", "text")
+
+	for _, ccy := range []string{"CHF", "EUR", "CHF"} {
+		tx := models.Transaction{
+			Date:        time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			Description: "Card payment",
+			Amount:      models.ParseAmount("100.00"),
+			Currency:    ccy,
+		}
+		desc := &strings.Builder{}
+		desc.WriteString("Card payment")
+		finalizeTransactionWithCategorizer(&tx, desc, "", seen, &transactions, nil, logger)
+	}
+
+	require.Len(t, transactions, 2, "the repeated CHF transaction is a duplicate, the EUR one is not")
+	assert.Equal(t, "CHF", transactions[0].Currency)
+	assert.Equal(t, "EUR", transactions[1].Currency)
+}
+
 func TestFinalizeTransactionWithCategorizer(t *testing.T) {
 	mockCategorizer := &MockCategorizer{
 		categories: map[string]string{
@@ -824,6 +936,42 @@ func TestPreProcessText(t *testing.T) {
 	}
 }
 
+func TestFormatDate_TwoDigitYear(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name  string
+		today time.Time
+		input string
+		want  time.Time
+	}{
+		{"recent statement", date(2025, 6, 1), "15.03.25", date(2025, 3, 15)},
+		{"previous year", date(2025, 1, 10), "28.12.24", date(2024, 12, 28)},
+		{"next year within tolerance", date(2025, 12, 20), "05.01.26", date(2026, 1, 5)},
+		// time.Parse alone would give 1969-1999 from here on
+		{"69 read as 2069 in 2069", date(2069, 2, 1), "31.01.69", date(2069, 1, 31)},
+		{"99 read as 2099 in 2099", date(2099, 6, 1), "01.06.99", date(2099, 6, 1)},
+		{"2049 in 2049", date(2049, 12, 31), "31.12.49", date(2049, 12, 31)},
+		{"2050 in 2049", date(2049, 12, 31), "01.01.50", date(2050, 1, 1)},
+		{"2050 in 2050", date(2050, 1, 2), "01.01.50", date(2050, 1, 1)},
+		// Years implausibly in the future are read as 19YY
+		{"far future 68", date(2025, 6, 1), "01.01.68", date(1968, 1, 1)},
+		{"far future 50", date(2025, 6, 1), "01.01.50", date(1950, 1, 1)},
+		{"far future 99", date(2025, 6, 1), "31.12.99", date(1999, 12, 31)},
+		{"four-digit year unchanged", date(2025, 6, 1), "01.01.1968", date(1968, 1, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = func() time.Time { return tt.today }
+			t.Cleanup(func() { now = time.Now })
+
+			assert.Equal(t, tt.want, formatDate(tt.input))
+		})
+	}
+}
+
 func TestFormatDateEdgeCases(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -907,7 +1055,7 @@ Date Description Amount
 			name:         "empty_content",
 			pdfText:      ``,
 			shouldDetect: false,
-			description:  "Empty content should use standard parser",
+			description:  "Empty content should fail with ErrNoText",
 		},
 		{
 			name: "only_whitespace",
@@ -916,7 +1064,7 @@ Date Description Amount
 
 			`,
 			shouldDetect: false,
-			description:  "Only whitespace should use standard parser",
+			description:  "Only whitespace should fail with ErrNoText",
 		},
 	}
 
@@ -936,6 +1084,11 @@ Date Description Amount
 			adapter.SetCategorizer(mockCategorizer)
 
 			transactions, err := adapter.Parse(context.Background(), file)
+			if strings.TrimSpace(tt.pdfText) == "" {
+				// Without text there is nothing to detect
+				assert.ErrorIs(t, err, ErrNoText, tt.description)
+				return
+			}
 			require.NoError(t, err)
 
 			// The detection happens internally, we verify by checking the log output
@@ -0,0 +1,107 @@
+package pdfparser
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrStatementTotalMismatch is returned in strict mode when the transactions
+// parsed from a Viseca statement do not add up to the statement's total.
+var ErrStatementTotalMismatch = errors.New("statement total does not match parsed transactions")
+
+// visecaTotalTolerance is the largest difference between the stated and the
+// computed total that is still treated as a match.
+var visecaTotalTolerance = decimal.New(5, -2)
+
+// summaryAmountPattern matches the amount at the end of a summary line,
+// including Swiss thousand separators, with an optional trailing minus sign.
+var summaryAmountPattern = regexp.MustCompile(`((?:\d{1,3}(?:'\d{3})+|\d+)[.,]\d{2})\s*(-)?$`)
+
+// visecaSummary holds the summary lines of a Viseca statement, which are not
+// transactions but let the parsed transactions be reconciled: the previous
+// total, plus payments, plus the statement's transactions gives the new total.
+type visecaSummary struct {
+	previousTotal decimal.Decimal
+	payments      decimal.Decimal
+	total         decimal.Decimal
+	hasTotal      bool
+}
+
+// isVisecaSummaryLine reports whether line is a "Montant total" or
+// "Votre paiement" summary line rather than a transaction.
+func isVisecaSummaryLine(line string) bool {
+	return strings.Contains(line, "Montant total") || strings.Contains(line, "Votre paiement")
+}
+
+// record stores the amount of a summary line. Lines without an amount are ignored.
+func (s *visecaSummary) record(line string) {
+	match := summaryAmountPattern.FindStringSubmatch(line)
+	if match == nil {
+		return
+	}
+	amount := models.ParseAmount(match[1])
+	if match[2] == "-" {
+		amount = amount.Neg()
+	}
+
+	switch {
+	case strings.Contains(line, "Votre paiement"):
+		s.payments = s.payments.Add(amount)
+	case strings.Contains(line, "dernier relevé"):
+		s.previousTotal = amount
+	default:
+		s.total = amount
+		s.hasTotal = true
+	}
+}
+
+// expectedTotal returns the total implied by the summary lines and the
+// transactions: debits raise the amount owed and credits lower it.
+func (s *visecaSummary) expectedTotal(transactions []models.Transaction) decimal.Decimal {
+	total := s.previousTotal.Add(s.payments)
+	for _, tx := range transactions {
+		if tx.IsCredit() {
+			total = total.Sub(tx.Amount.Abs())
+		} else {
+			total = total.Add(tx.Amount.Abs())
+		}
+	}
+	return total
+}
+
+// checkVisecaTotal compares the statement's total with the total computed from
+// the parsed transactions. A difference beyond visecaTotalTolerance usually
+// means PDF lines were dropped; it is logged as a warning, or returned as
+// ErrStatementTotalMismatch when strict is set. Statements without a total
+// are not checked.
+func checkVisecaTotal(summary visecaSummary, transactions []models.Transaction, strict bool, logger logging.Logger) error {
+	if !summary.hasTotal {
+		logger.Debug("Viseca statement has no total, skipping total check")
+		return nil
+	}
+
+	computed := summary.expectedTotal(transactions)
+	difference := summary.total.Sub(computed)
+	if difference.Abs().LessThanOrEqual(visecaTotalTolerance) {
+		logger.Debug("Viseca statement total matches parsed transactions",
+			logging.Field{Key: "total", Value: summary.total.String()})
+		return nil
+	}
+
+	if strict {
+		return fmt.Errorf("%w: statement total %s, computed %s", ErrStatementTotalMismatch,
+			summary.total.StringFixed(2), computed.StringFixed(2))
+	}
+	logger.Warn("Viseca statement total does not match parsed transactions, some lines may have been dropped",
+		logging.Field{Key: "statement_total", Value: summary.total.StringFixed(2)},
+		logging.Field{Key: "computed_total", Value: computed.StringFixed(2)},
+		logging.Field{Key: "difference", Value: difference.StringFixed(2)})
+	return nil
+}
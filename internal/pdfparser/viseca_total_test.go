@@ -0,0 +1,195 @@
+package pdfparser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const visecaStatementMismatchMsg = "Viseca statement total does not match parsed transactions, some lines may have been dropped"
+
+// visecaStatement builds Viseca statement text whose closing total is total.
+// Previous total 500.00, payment 500.00-, then 120.50 + 100.00 - 20.00.
+func visecaStatement(total string) string {
+	return `Visa Gold XXXX 1234
+Date de transaction Date valeur Détails Montant
+01.01.25 Montant total dernier relevé 500.00
+05.01.25 Votre paiement - Merci 500.00-
+10.01.25 11.01.25 Migros Lausanne 120.50
+12.01.25 13.01.25 Coop Pully 100.00
+15.01.25 16.01.25 Remboursement Galaxus 20.00-
+31.01.25 Montant total ` + total
+}
+
+func parseVisecaText(t *testing.T, text string, strict bool, logger logging.Logger) ([]models.Transaction, error) {
+	t.Helper()
+	adapter := NewAdapter(logger, NewMockPDFExtractor(text, nil))
+	adapter.SetStrict(strict)
+	return adapter.Parse(context.Background(), strings.NewReader("dummy content"))
+}
+
+func TestVisecaTotal_Matches(t *testing.T) {
+	logger := logging.NewMockLogger()
+
+	transactions, err := parseVisecaText(t, visecaStatement("200.50"), true, logger)
+
+	require.NoError(t, err)
+	assert.Len(t, transactions, 3)
+	assert.False(t, logger.HasEntry("WARN", visecaStatementMismatchMsg))
+}
+
+func TestVisecaTotal_MismatchWarns(t *testing.T) {
+	logger := logging.NewMockLogger()
+
+	transactions, err := parseVisecaText(t, visecaStatement("250.50"), false, logger)
+
+	require.NoError(t, err)
+	assert.Len(t, transactions, 3)
+	assert.True(t, logger.HasEntry("WARN", visecaStatementMismatchMsg))
+}
+
+func TestVisecaTotal_MismatchFailsWhenStrict(t *testing.T) {
+	_, err := parseVisecaText(t, visecaStatement("250.50"), true, logging.NewMockLogger())
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrStatementTotalMismatch)
+	assert.Contains(t, err.Error(), "statement total 250.50, computed 200.50")
+}
+
+func TestVisecaTotal_WithinTolerance(t *testing.T) {
+	logger := logging.NewMockLogger()
+
+	_, err := parseVisecaText(t, visecaStatement("200.54"), true, logger)
+
+	require.NoError(t, err)
+	assert.False(t, logger.HasEntry("WARN", visecaStatementMismatchMsg))
+}
+
+func TestVisecaTotal_NoTotalSkipsCheck(t *testing.T) {
+	text := `Visa Gold XXXX 1234
+Date de transaction Date valeur Détails Montant
+10.01.25 11.01.25 Migros Lausanne 120.50`
+
+	transactions, err := parseVisecaText(t, text, true, logging.NewMockLogger())
+
+	require.NoError(t, err)
+	assert.Len(t, transactions, 1)
+}
+
+func TestVisecaSummary_Record(t *testing.T) {
+	var summary visecaSummary
+
+	summary.record("01.01.25 Montant total dernier relevé 1'234.50")
+	summary.record("05.01.25 Votre paiement - Merci 1'234.50-")
+	summary.record("Montant total CHF 42.10")
+
+	assert.True(t, summary.previousTotal.Equal(decimal.RequireFromString("1234.50")))
+	assert.True(t, summary.payments.Equal(decimal.RequireFromString("-1234.50")))
+	assert.True(t, summary.total.Equal(decimal.RequireFromString("42.10")))
+	assert.True(t, summary.hasTotal)
+}
+
+func TestViseca_CardLast4PerSection(t *testing.T) {
+	text := `Visa Gold XXXX 1234
+Date de transaction Date valeur Détails Montant
+10.01.25 11.01.25 Migros Lausanne 120.50
+Visa Gold XXXX 5678
+12.01.25 13.01.25 Coop Pully 100.00
+15.01.25 16.01.25 Galaxus XXXX 9999 20.00`
+
+	transactions, err := parseVisecaText(t, text, false, logging.NewMockLogger())
+
+	require.NoError(t, err)
+	require.Len(t, transactions, 3)
+	assert.Equal(t, "1234", transactions[0].CardLast4)
+	assert.Equal(t, "5678", transactions[1].CardLast4)
+	assert.Equal(t, "9999", transactions[2].CardLast4, "a card on the transaction line wins")
+	assert.NotContains(t, transactions[1].Description, "XXXX", "card lines are not categories")
+}
+
+// mccMockCategorizer is a MockCategorizer that also maps merchant category codes.
+type mccMockCategorizer struct {
+	MockCategorizer
+	mcc map[string]string
+}
+
+func (m *mccMockCategorizer) CategoryForMCC(mcc string) (string, bool) {
+	category, ok := m.mcc[mcc]
+	return category, ok
+}
+
+func TestViseca_MCC(t *testing.T) {
+	text := `Visa Gold XXXX 1234
+Date de transaction Date valeur Détails Montant
+10.01.25 11.01.25 Migros Lausanne MCC 5411 120.50
+12.01.25 13.01.25 Café du Commerce Pully 45.00
+MCC 5812
+15.01.25 16.01.25 Galaxus Online 80.00`
+
+	categorizer := &mccMockCategorizer{
+		MockCategorizer: MockCategorizer{categories: map[string]string{"Galaxus": "Shopping", "Migros": "Shopping"}},
+		mcc:             map[string]string{"5411": "Alimentation", "5812": "Restaurants"},
+	}
+	adapter := NewAdapter(logging.NewMockLogger(), NewMockPDFExtractor(text, nil))
+	adapter.SetCategorizer(categorizer)
+	transactions, err := adapter.Parse(context.Background(), strings.NewReader("dummy content"))
+
+	require.NoError(t, err)
+	require.Len(t, transactions, 3)
+	assert.Equal(t, "5411", transactions[0].MCC)
+	assert.Equal(t, "Migros Lausanne", transactions[0].Description, "the code is not part of the description")
+	assert.Equal(t, "Alimentation", transactions[0].Category, "the code wins over the merchant name")
+	assert.Equal(t, models.CategorySourceMCC, transactions[0].CategorySource)
+
+	assert.Equal(t, "5812", transactions[1].MCC, "a code on its own line belongs to the transaction above")
+	assert.Equal(t, "Restaurants", transactions[1].Category)
+
+	assert.Empty(t, transactions[2].MCC)
+	assert.NotContains(t, transactions[2].Description, "MCC", "code lines are not categories")
+	assert.Equal(t, "Shopping", transactions[2].Category)
+	assert.Equal(t, 1, categorizer.callCount, "only the transaction without a code is categorized by name")
+}
+
+func parseVisecaRefundFixture(t *testing.T, refundCategory string) []models.Transaction {
+	t.Helper()
+	text, err := os.ReadFile(filepath.Join("testdata", "viseca_refund.txt"))
+	require.NoError(t, err)
+
+	adapter := NewAdapter(logging.NewMockLogger(), NewMockPDFExtractor(string(text), nil))
+	adapter.SetStrict(true)
+	adapter.SetCategorizer(&MockCategorizer{categories: map[string]string{"Galaxus": "Shopping"}})
+	adapter.SetRefundCategory(refundCategory)
+	transactions, err := adapter.Parse(context.Background(), strings.NewReader("dummy content"))
+	require.NoError(t, err)
+	require.Len(t, transactions, 3)
+	return transactions
+}
+
+func TestViseca_RefundType(t *testing.T) {
+	transactions := parseVisecaRefundFixture(t, "")
+
+	purchase, refund := transactions[0], transactions[1]
+	assert.True(t, purchase.IsDebit())
+	assert.Empty(t, purchase.Type)
+	assert.True(t, refund.IsCredit())
+	assert.Equal(t, models.TypeRefund, refund.Type)
+	assert.Equal(t, "Shopping", refund.Category, "without a refund category the merchant's is kept")
+	assert.Empty(t, transactions[2].Type)
+}
+
+func TestViseca_RefundCategory(t *testing.T) {
+	transactions := parseVisecaRefundFixture(t, "Refund")
+
+	assert.Equal(t, "Shopping", transactions[0].Category)
+	assert.Equal(t, models.TypeRefund, transactions[1].Type)
+	assert.Equal(t, "Refund", transactions[1].Category)
+}
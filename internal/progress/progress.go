@@ -0,0 +1,172 @@
+// Package progress renders a terminal progress indicator for long-running
+// conversions. Reporters are passed explicitly (batch processing) or through a
+// context (single-file parsing), and default to a no-op so that callers never
+// need to check whether progress output is enabled.
+package progress
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// barWidth is the number of cells in the rendered bar.
+const barWidth = 30
+
+// Reporter receives progress updates for a unit of work.
+type Reporter interface {
+	// Start begins tracking total items of the given unit (e.g. "files").
+	Start(total int, unit string)
+	// Increment marks one more item as done.
+	Increment()
+	// Finish ends tracking and releases the terminal line.
+	Finish()
+}
+
+// nopReporter discards all updates.
+type nopReporter struct{}
+
+func (nopReporter) Start(int, string) {}
+func (nopReporter) Increment()        {}
+func (nopReporter) Finish()           {}
+
+// Nop returns a Reporter that does nothing.
+func Nop() Reporter {
+	return nopReporter{}
+}
+
+// Bar is a Reporter that redraws a single line on w:
+//
+//	Converting [###############               ]  50% 150/300 files
+//
+// Work with a single item is not drawn, since there is nothing to follow.
+type Bar struct {
+	w     io.Writer
+	label string
+
+	mu       sync.Mutex
+	total    int
+	current  int
+	unit     string
+	rendered bool
+}
+
+// NewBar creates a Bar writing to w, prefixing each line with label.
+func NewBar(w io.Writer, label string) *Bar {
+	return &Bar{w: w, label: label}
+}
+
+// Start resets the bar for total items of unit.
+func (b *Bar) Start(total int, unit string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.total = total
+	b.current = 0
+	b.unit = unit
+	b.rendered = false
+	b.render()
+}
+
+// Increment advances the bar by one item.
+func (b *Bar) Increment() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.current < b.total {
+		b.current++
+	}
+	b.render()
+}
+
+// Finish terminates the progress line so later output starts on a fresh line.
+func (b *Bar) Finish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.rendered {
+		_, _ = fmt.Fprintln(b.w)
+	}
+	b.rendered = false
+}
+
+// Writer returns a writer to w that keeps other output, such as log lines,
+// from running into the bar: the bar line is cleared before each write and
+// redrawn below it afterwards.
+func (b *Bar) Writer(w io.Writer) io.Writer {
+	return &barWriter{bar: b, w: w}
+}
+
+type barWriter struct {
+	bar *Bar
+	w   io.Writer
+}
+
+func (bw *barWriter) Write(p []byte) (int, error) {
+	b := bw.bar
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.rendered {
+		return bw.w.Write(p)
+	}
+	_, _ = fmt.Fprint(bw.w, "\r\033[K")
+	n, err := bw.w.Write(p)
+	b.render()
+	return n, err
+}
+
+// render redraws the line; the caller holds b.mu.
+func (b *Bar) render() {
+	if b.total <= 1 {
+		return
+	}
+	filled := b.current * barWidth / b.total
+	_, _ = fmt.Fprintf(b.w, "\r%s [%s%s] %3d%% %d/%d %s",
+		b.label,
+		strings.Repeat("#", filled),
+		strings.Repeat(" ", barWidth-filled),
+		b.current*100/b.total,
+		b.current, b.total, b.unit)
+	b.rendered = true
+}
+
+// isTerminal reports whether f is an interactive terminal.
+var isTerminal = func(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Enabled reports whether progress should be drawn: stdout and stderr are
+// terminals, quiet mode is off, and logs are not structured (JSON log lines
+// would be corrupted by in-place redraws).
+func Enabled(quiet bool, logFormat string) bool {
+	if quiet || strings.EqualFold(logFormat, "json") {
+		return false
+	}
+	return isTerminal(os.Stdout) && isTerminal(os.Stderr)
+}
+
+// New returns a Bar on stderr when Enabled, and a no-op Reporter otherwise.
+func New(label string, quiet bool, logFormat string) Reporter {
+	if !Enabled(quiet, logFormat) {
+		return Nop()
+	}
+	return NewBar(os.Stderr, label)
+}
+
+type contextKey struct{}
+
+// WithReporter returns a copy of ctx carrying r.
+func WithReporter(ctx context.Context, r Reporter) context.Context {
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+// FromContext returns the Reporter carried by ctx, or a no-op Reporter.
+func FromContext(ctx context.Context) Reporter {
+	if ctx != nil {
+		if r, ok := ctx.Value(contextKey{}).(Reporter); ok && r != nil {
+			return r
+		}
+	}
+	return Nop()
+}
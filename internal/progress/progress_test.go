@@ -0,0 +1,83 @@
+package progress
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBar_Render(t *testing.T) {
+	var buf bytes.Buffer
+	bar := NewBar(&buf, "Converting")
+
+	bar.Start(4, "files")
+	bar.Increment()
+	bar.Increment()
+	assert.Contains(t, buf.String(), "\rConverting [###############               ]  50% 2/4 files")
+
+	bar.Increment()
+	bar.Increment()
+	bar.Increment() // past total is clamped
+	assert.Contains(t, buf.String(), "100% 4/4 files")
+
+	bar.Finish()
+	assert.True(t, bytes.HasSuffix(buf.Bytes(), []byte("\n")))
+}
+
+func TestBar_SingleItemNotDrawn(t *testing.T) {
+	var buf bytes.Buffer
+	bar := NewBar(&buf, "Parsing")
+
+	bar.Start(1, "statements")
+	bar.Increment()
+	bar.Finish()
+
+	assert.Empty(t, buf.String())
+}
+
+func TestBar_Writer(t *testing.T) {
+	var buf bytes.Buffer
+	bar := NewBar(&buf, "Converting")
+	logs := bar.Writer(&buf)
+
+	_, _ = logs.Write([]byte("before\n"))
+	assert.Equal(t, "before\n", buf.String(), "no bar shown yet")
+
+	buf.Reset()
+	bar.Start(2, "files")
+	bar.Increment()
+	_, _ = logs.Write([]byte("warning\n"))
+	assert.True(t, strings.HasSuffix(buf.String(), "\r\033[Kwarning\n\rConverting [###############               ]  50% 1/2 files"),
+		"the log line replaces the bar, which is redrawn below it")
+
+	bar.Finish()
+	buf.Reset()
+	_, _ = logs.Write([]byte("after\n"))
+	assert.Equal(t, "after\n", buf.String())
+}
+
+func TestEnabled(t *testing.T) {
+	orig := isTerminal
+	defer func() { isTerminal = orig }()
+
+	isTerminal = func(*os.File) bool { return true }
+	assert.True(t, Enabled(false, "text"))
+	assert.False(t, Enabled(true, "text"), "quiet mode")
+	assert.False(t, Enabled(false, "json"), "structured logging")
+
+	isTerminal = func(*os.File) bool { return false }
+	assert.False(t, Enabled(false, "text"), "not a terminal")
+	assert.IsType(t, nopReporter{}, New("x", false, "text"))
+}
+
+func TestFromContext(t *testing.T) {
+	assert.IsType(t, nopReporter{}, FromContext(context.Background()))
+
+	bar := NewBar(&bytes.Buffer{}, "x")
+	ctx := WithReporter(context.Background(), bar)
+	assert.Same(t, bar, FromContext(ctx))
+}
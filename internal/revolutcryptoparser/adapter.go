@@ -12,6 +12,7 @@ import (
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
 	"fjacquet/camt-csv/internal/parser"
+	"fjacquet/camt-csv/internal/parsererror"
 )
 
 // Adapter implements the parser.FullParser interface for Revolut Crypto CSV files.
@@ -19,6 +20,12 @@ type Adapter struct {
 	parser.BaseParser
 }
 
+func init() {
+	parser.RegisterParser("revolut-crypto", func(logger logging.Logger) parser.FullParser {
+		return NewAdapter(logger)
+	})
+}
+
 // NewAdapter creates a new Adapter for the revolutcryptoparser.
 func NewAdapter(logger logging.Logger) *Adapter {
 	return &Adapter{
@@ -28,7 +35,11 @@ func NewAdapter(logger logging.Logger) *Adapter {
 
 // Parse reads data from the provided io.Reader and returns a slice of Transaction models.
 func (a *Adapter) Parse(ctx context.Context, r io.Reader) ([]models.Transaction, error) {
-	return ParseWithCategorizer(r, a.GetLogger(), a.GetCategorizer())
+	r, err := parser.DecodeInput(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseWithCategorizer(ctx, r, a.GetLogger(), a.GetCategorizer())
 }
 
 // ConvertToCSV implements parser.FullParser.ConvertToCSV.
@@ -40,7 +51,7 @@ func (a *Adapter) ConvertToCSV(ctx context.Context, inputFile, outputFile string
 func (a *Adapter) ValidateFormat(file string) (bool, error) {
 	f, err := os.Open(file) // #nosec G304 -- CLI tool requires user-provided file paths
 	if err != nil {
-		return false, err
+		return false, fmt.Errorf("%w: %w", parsererror.ErrReadFailed, err)
 	}
 	defer func() {
 		if err := f.Close(); err != nil {
@@ -11,8 +11,10 @@ import (
 	"strings"
 	"time"
 
+	"fjacquet/camt-csv/internal/common"
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parser"
 	"fjacquet/camt-csv/internal/parsererror"
 
 	"github.com/shopspring/decimal"
@@ -106,7 +108,7 @@ func parseFrenchAmount(s string) (decimal.Decimal, string) {
 }
 
 // ParseWithCategorizer parses a Revolut Crypto CSV reader and returns transactions.
-func ParseWithCategorizer(r io.Reader, logger logging.Logger, categorizer models.TransactionCategorizer) ([]models.Transaction, error) {
+func ParseWithCategorizer(ctx context.Context, r io.Reader, logger logging.Logger, categorizer models.TransactionCategorizer) ([]models.Transaction, error) {
 	if logger == nil {
 		logger = logging.NewLogrusAdapter("info", "text")
 	}
@@ -115,7 +117,7 @@ func ParseWithCategorizer(r io.Reader, logger logging.Logger, categorizer models
 	reader := csv.NewReader(r)
 	records, err := reader.ReadAll()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV: %w", err)
+		return nil, fmt.Errorf("%w: failed to read CSV: %w", parsererror.ErrReadFailed, err)
 	}
 
 	if len(records) < 2 {
@@ -123,6 +125,7 @@ func ParseWithCategorizer(r io.Reader, logger logging.Logger, categorizer models
 			FilePath:       "(from reader)",
 			ExpectedFormat: "Revolut Crypto CSV",
 			Msg:            "CSV file is empty or contains only headers",
+			Err:            parsererror.ErrNoTransactions,
 		}
 	}
 
@@ -171,27 +174,16 @@ func ParseWithCategorizer(r io.Reader, logger logging.Logger, categorizer models
 			continue
 		}
 
-		if categorizer != nil {
-			isDebtor := tx.CreditDebit == models.TransactionTypeDebit
-			catDate := ""
-			if !tx.Date.IsZero() {
-				catDate = tx.Date.Format("02.01.2006")
-			}
-			category, catErr := categorizer.Categorize(context.Background(), tx.PartyName, isDebtor, tx.Amount.String(), catDate, "")
-			if catErr != nil {
-				logger.WithError(catErr).Warn("Failed to categorize transaction",
-					logging.Field{Key: "party", Value: tx.PartyName})
-				tx.Category = models.CategoryUncategorized
-			} else {
-				tx.Category = category.Name
-			}
-		} else {
-			tx.Category = models.CategoryUncategorized
+		if err := parser.CheckTransactionLimit(ctx, len(transactions)+1); err != nil {
+			return nil, err
 		}
 
 		transactions = append(transactions, tx)
 	}
 
+	// Categorize the transactions using the injected categorizer
+	transactions = common.ProcessTransactionsWithCategorizationStats(ctx, transactions, logger, categorizer, "Revolut Crypto")
+
 	logger.Info("Successfully parsed transactions from Revolut Crypto CSV",
 		logging.Field{Key: "count", Value: len(transactions)})
 	return transactions, nil
@@ -8,8 +8,10 @@ import (
 	"strings"
 	"time"
 
+	"fjacquet/camt-csv/internal/common"
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parser"
 	"fjacquet/camt-csv/internal/parsererror"
 
 	"github.com/shopspring/decimal"
@@ -28,7 +30,7 @@ type RevolutInvestmentCSVRow struct {
 }
 
 // ParseWithCategorizer parses a Revolut investment CSV file and categorizes transactions using the provided categorizer.
-func ParseWithCategorizer(r io.Reader, logger logging.Logger, categorizer models.TransactionCategorizer) ([]models.Transaction, error) {
+func ParseWithCategorizer(ctx context.Context, r io.Reader, logger logging.Logger, categorizer models.TransactionCategorizer) ([]models.Transaction, error) {
 	if logger == nil {
 		logger = logging.NewLogrusAdapter("info", "text")
 	}
@@ -37,7 +39,7 @@ func ParseWithCategorizer(r io.Reader, logger logging.Logger, categorizer models
 	reader := csv.NewReader(r)
 	records, err := reader.ReadAll()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV: %w", err)
+		return nil, fmt.Errorf("%w: failed to read CSV: %w", parsererror.ErrReadFailed, err)
 	}
 
 	if len(records) < 2 {
@@ -45,6 +47,7 @@ func ParseWithCategorizer(r io.Reader, logger logging.Logger, categorizer models
 			FilePath:       "(from reader)",
 			ExpectedFormat: "Revolut Investment CSV",
 			Msg:            "CSV file is empty or contains only headers",
+			Err:            parsererror.ErrNoTransactions,
 		}
 	}
 
@@ -96,35 +99,16 @@ func ParseWithCategorizer(r io.Reader, logger logging.Logger, categorizer models
 			continue
 		}
 
-		// Categorize the transaction using the injected categorizer
-		if categorizer != nil {
-			isDebtor := transaction.CreditDebit == models.TransactionTypeDebit
-			catAmount := transaction.Amount.String()
-			catDate := ""
-			if !transaction.Date.IsZero() {
-				catDate = transaction.Date.Format("02.01.2006")
-			}
-
-			category, catErr := categorizer.Categorize(context.Background(), transaction.PartyName, isDebtor, catAmount, catDate, "")
-			if catErr != nil {
-				logger.WithError(catErr).WithFields(
-					logging.Field{Key: "party", Value: transaction.PartyName},
-				).Warn("Failed to categorize transaction")
-				transaction.Category = models.CategoryUncategorized
-			} else {
-				transaction.Category = category.Name
-				logger.WithFields(
-					logging.Field{Key: "party", Value: transaction.PartyName},
-					logging.Field{Key: "category", Value: category.Name},
-				).Debug("Transaction categorized successfully")
-			}
-		} else {
-			transaction.Category = models.CategoryUncategorized
+		if err := parser.CheckTransactionLimit(ctx, len(transactions)+1); err != nil {
+			return nil, err
 		}
 
 		transactions = append(transactions, transaction)
 	}
 
+	// Categorize the transactions using the injected categorizer
+	transactions = common.ProcessTransactionsWithCategorizationStats(ctx, transactions, logger, categorizer, "Revolut Investment")
+
 	logger.Info("Successfully parsed transactions from Revolut investment CSV",
 		logging.Field{Key: "count", Value: len(transactions)})
 	return transactions, nil
@@ -2,6 +2,7 @@ package revolutparser
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -10,6 +11,7 @@ import (
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
 	"fjacquet/camt-csv/internal/parser"
+	"fjacquet/camt-csv/internal/parsererror"
 )
 
 // Adapter implements the parser.FullParser interface for Revolut CSV files.
@@ -17,6 +19,12 @@ type Adapter struct {
 	parser.BaseParser
 }
 
+func init() {
+	parser.RegisterParser("revolut", func(logger logging.Logger) parser.FullParser {
+		return NewAdapter(logger)
+	})
+}
+
 // NewAdapter creates a new adapter for the revolutparser.
 func NewAdapter(logger logging.Logger) *Adapter {
 	return &Adapter{
@@ -26,7 +34,11 @@ func NewAdapter(logger logging.Logger) *Adapter {
 
 // Parse reads data from the provided io.Reader and returns a slice of Transaction models.
 func (a *Adapter) Parse(ctx context.Context, r io.Reader) ([]models.Transaction, error) {
-	return ParseWithCategorizer(r, a.GetLogger(), a.GetCategorizer())
+	r, err := parser.DecodeInput(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseWithCategorizer(ctx, r, a.GetLogger(), a.GetCategorizer())
 }
 
 // ConvertToCSV implements parser.FullParser.ConvertToCSV
@@ -38,7 +50,7 @@ func (a *Adapter) ConvertToCSV(ctx context.Context, inputFile, outputFile string
 func (a *Adapter) ValidateFormat(file string) (bool, error) {
 	f, err := os.Open(file) // #nosec G304 -- CLI tool requires user-provided file paths
 	if err != nil {
-		return false, err
+		return false, fmt.Errorf("%w: %w", parsererror.ErrReadFailed, err)
 	}
 	defer func() {
 		if err := f.Close(); err != nil {
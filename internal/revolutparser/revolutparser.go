@@ -10,8 +10,10 @@ import (
 	"io"
 	"strings"
 
+	"fjacquet/camt-csv/internal/common"
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parser"
 	"fjacquet/camt-csv/internal/parsererror"
 
 	"github.com/gocarina/gocsv"
@@ -35,7 +37,7 @@ type RevolutCSVRow struct {
 
 // ParseWithCategorizer parses a Revolut CSV file and categorizes transactions using the provided categorizer.
 // This is the preferred entry point when categorization is needed.
-func ParseWithCategorizer(r io.Reader, logger logging.Logger, categorizer models.TransactionCategorizer) ([]models.Transaction, error) {
+func ParseWithCategorizer(ctx context.Context, r io.Reader, logger logging.Logger, categorizer models.TransactionCategorizer) ([]models.Transaction, error) {
 	if logger == nil {
 		logger = logging.NewLogrusAdapter("info", "text")
 	}
@@ -45,7 +47,7 @@ func ParseWithCategorizer(r io.Reader, logger logging.Logger, categorizer models
 	// Buffer the reader content so we can validate and parse from the same data
 	data, err := io.ReadAll(r)
 	if err != nil {
-		return nil, fmt.Errorf("error reading input: %w", err)
+		return nil, fmt.Errorf("%w: error reading input: %w", parsererror.ErrReadFailed, err)
 	}
 
 	// Normalize French-localized CSVs before validation and parsing
@@ -115,32 +117,16 @@ func ParseWithCategorizer(r io.Reader, logger logging.Logger, categorizer models
 			continue
 		}
 
-		// Categorize the transaction using the injected categorizer
-		if categorizer != nil {
-			// Determine if this is a debit (payment out) or credit (payment in)
-			isDebtor := tx.CreditDebit == models.TransactionTypeDebit
-			catAmount := tx.Amount.String()
-			catDate := ""
-			if !tx.Date.IsZero() {
-				catDate = tx.Date.Format("02.01.2006")
-			}
-
-			category, catErr := categorizer.Categorize(context.Background(), tx.Description, isDebtor, catAmount, catDate, "")
-			if catErr != nil {
-				logger.WithError(catErr).WithFields(
-					logging.Field{Key: "party", Value: tx.Description},
-				).Warn("Failed to categorize transaction")
-				tx.Category = models.CategoryUncategorized
-			} else {
-				tx.Category = category.Name
-			}
-		} else {
-			tx.Category = models.CategoryUncategorized
+		if err := parser.CheckTransactionLimit(ctx, len(transactions)+1); err != nil {
+			return nil, err
 		}
 
 		transactions = append(transactions, tx)
 	}
 
+	// Categorize the transactions using the injected categorizer
+	transactions = common.ProcessTransactionsWithCategorizationStats(ctx, transactions, logger, categorizer, "Revolut")
+
 	// Post-process transactions to apply specific description transformations
 	processedTransactions := postProcessTransactions(transactions)
 
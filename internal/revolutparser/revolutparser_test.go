@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"fjacquet/camt-csv/internal/dateutils"
 	"fjacquet/camt-csv/internal/logging"
@@ -112,7 +113,7 @@ CARD_PAYMENT,Current,2025-01-02 08:07:09,2025-01-03 15:38:51,Boreal Coffee Shop,
 	}
 
 	logger := logging.NewLogrusAdapter("info", "text")
-	transactions, err := ParseWithCategorizer(file, logger, mockCategorizer)
+	transactions, err := ParseWithCategorizer(context.Background(), file, logger, mockCategorizer)
 	assert.NoError(t, err)
 	assert.Len(t, transactions, 1)
 	assert.Equal(t, "Food & Dining", transactions[0].Category)
@@ -140,7 +141,7 @@ CARD_PAYMENT,Current,2025-01-02 08:07:09,2025-01-03 15:38:51,Boreal Coffee Shop,
 	mockCategorizer := &mockCategorizerError{}
 
 	logger := logging.NewLogrusAdapter("info", "text")
-	transactions, err := ParseWithCategorizer(file, logger, mockCategorizer)
+	transactions, err := ParseWithCategorizer(context.Background(), file, logger, mockCategorizer)
 	assert.NoError(t, err)
 	assert.Len(t, transactions, 1)
 	assert.Equal(t, models.CategoryUncategorized, transactions[0].Category)
@@ -455,7 +456,7 @@ CARD_PAYMENT,Current,2025-01-02 08:07:09,2025-01-03 15:38:51,Coffee,-10.50,0.00,
 CARD_PAYMENT,Current,2025-01-03 08:07:09,2025-01-04 15:38:51,,-20.00,0.00,CHF,COMPLETED,80.00
 CARD_PAYMENT,Current,2025-01-04 08:07:09,2025-01-05 15:38:51,Lunch,-30.00,0.00,CHF,COMPLETED,50.00`
 
-	transactions, err := ParseWithCategorizer(strings.NewReader(csvContent), logger, nil)
+	transactions, err := ParseWithCategorizer(context.Background(), strings.NewReader(csvContent), logger, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, 2, len(transactions)) // Row with empty description should be skipped
 }
@@ -467,7 +468,7 @@ func TestParseWithCategorizer_PendingTransactions(t *testing.T) {
 CARD_PAYMENT,Current,2025-01-02 08:07:09,2025-01-03 15:38:51,Coffee,-10.50,0.00,CHF,COMPLETED,100.00
 CARD_PAYMENT,Current,2025-01-03 08:07:09,,Pending Payment,-20.00,0.00,CHF,PENDING,80.00`
 
-	transactions, err := ParseWithCategorizer(strings.NewReader(csvContent), logger, nil)
+	transactions, err := ParseWithCategorizer(context.Background(), strings.NewReader(csvContent), logger, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(transactions)) // Pending transaction should be skipped
 }
@@ -758,8 +759,48 @@ func TestParseWithCategorizer_FrenchCSV(t *testing.T) {
 	require.NoError(t, err)
 	data = normalizeCSVData(data)
 
-	transactions, err := ParseWithCategorizer(strings.NewReader(string(data)), logger, nil)
+	transactions, err := ParseWithCategorizer(context.Background(), strings.NewReader(string(data)), logger, nil)
 	assert.NoError(t, err)
 	assert.Len(t, transactions, 1)
 	assert.Equal(t, "Coffee Shop", transactions[0].Description)
 }
+
+func TestParseWithCategorizer_PreservesTimestamps(t *testing.T) {
+	file, err := os.Open(filepath.Join("testdata", "revolut_timestamps.csv"))
+	require.NoError(t, err)
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			t.Logf("Failed to close file: %v", closeErr)
+		}
+	}()
+
+	logger := logging.NewLogrusAdapter("info", "text")
+	transactions, err := ParseWithCategorizer(context.Background(), file, logger, nil)
+	require.NoError(t, err)
+	require.Len(t, transactions, 4)
+
+	assert.Equal(t, time.Date(2025, 3, 14, 18, 42, 11, 0, time.UTC), transactions[0].Date)
+	assert.Equal(t, time.Date(2025, 3, 14, 7, 55, 3, 0, time.UTC), transactions[1].Date)
+	assert.Equal(t, time.Date(2025, 3, 14, 18, 42, 10, 0, time.UTC), transactions[0].ValueDate)
+
+	// Minute-resolution timestamps are accepted too
+	assert.Equal(t, time.Date(2025, 3, 15, 9, 4, 0, 0, time.UTC), transactions[3].Date)
+
+	// Same-day transactions keep their intraday order
+	assert.True(t, transactions[1].Date.Before(transactions[2].Date))
+	assert.True(t, transactions[2].Date.Before(transactions[0].Date))
+}
+
+func TestAdapter_ParseWindows1252(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "revolut_latin1.csv"))
+	require.NoError(t, err)
+
+	adapter := NewAdapter(logging.NewMockLogger())
+	transactions, err := adapter.Parse(context.Background(), strings.NewReader(string(data)))
+	require.NoError(t, err)
+	require.Len(t, transactions, 3)
+
+	assert.Equal(t, "Café de la Gare", transactions[0].Description)
+	assert.Equal(t, "Brasserie Müller", transactions[1].Description)
+	assert.Equal(t, "Crêperie Ève – €uro Shop", transactions[2].Description)
+}
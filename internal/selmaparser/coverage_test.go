@@ -101,6 +101,7 @@ func TestCategorizeTransaction_AllBranches(t *testing.T) {
 		{"Income", "Income", "income", decimal.NewFromInt(50), "Revenus Financiers"},
 		{"Dividend investment", "Dividend", "div", decimal.NewFromInt(25), "Revenus Financiers"},
 		{"Expense", "Expense", "fee", decimal.NewFromInt(10), "Frais Bancaires"},
+		{"Refund", "Refund", "selma_fee", decimal.NewFromInt(3), "Frais Bancaires"},
 		{"selma_fee desc", "", "selma_fee", decimal.NewFromInt(5), "Frais Bancaires"},
 		{"cash_transfer desc", "", "cash_transfer", decimal.NewFromInt(200), "Revenus Financiers"},
 		{"trade negative", "", "trade", decimal.NewFromInt(-100), "Investissements"},
@@ -127,6 +128,6 @@ func TestProcessTransactionsWithCategorizer_NilLogger(t *testing.T) {
 		{Description: "test", Amount: decimal.NewFromInt(100)},
 	}
 
-	result := ProcessTransactionsWithCategorizer(transactions, nil, nil)
+	result := ProcessTransactionsWithCategorizer(context.Background(), transactions, nil, nil)
 	assert.Len(t, result, 1)
 }
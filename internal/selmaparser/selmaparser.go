@@ -2,6 +2,7 @@
 package selmaparser
 
 import (
+	"context"
 	"encoding/csv"
 	"fmt"
 	"io"
@@ -11,6 +12,7 @@ import (
 	"fjacquet/camt-csv/internal/common"
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parser"
 	"fjacquet/camt-csv/internal/parsererror"
 
 	"github.com/shopspring/decimal"
@@ -18,7 +20,7 @@ import (
 
 // ParseWithCategorizer reads and parses a Selma CSV file from an io.Reader into a slice of Transaction objects
 // and applies categorization using the provided categorizer.
-func ParseWithCategorizer(r io.Reader, logger logging.Logger, categorizer models.TransactionCategorizer) ([]models.Transaction, error) {
+func ParseWithCategorizer(ctx context.Context, r io.Reader, logger logging.Logger, categorizer models.TransactionCategorizer) ([]models.Transaction, error) {
 	if logger == nil {
 		logger = logging.NewLogrusAdapter("info", "text")
 	}
@@ -27,7 +29,7 @@ func ParseWithCategorizer(r io.Reader, logger logging.Logger, categorizer models
 	// Buffer the reader content so we can validate and parse from the same data
 	data, err := io.ReadAll(r)
 	if err != nil {
-		return nil, fmt.Errorf("error reading input: %w", err)
+		return nil, fmt.Errorf("%w: error reading input: %w", parsererror.ErrReadFailed, err)
 	}
 
 	// Check if the file format is valid first
@@ -111,11 +113,15 @@ func ParseWithCategorizer(r io.Reader, logger logging.Logger, categorizer models
 				logging.Field{Key: "row", Value: row})
 			continue
 		}
+
+		if err := parser.CheckTransactionLimit(ctx, len(transactions)+1); err != nil {
+			return nil, err
+		}
 		transactions = append(transactions, tx)
 	}
 
 	// Process the transactions (categorize, associate related transactions)
-	return ProcessTransactionsWithCategorizer(transactions, logger, categorizer), nil
+	return ProcessTransactionsWithCategorizer(ctx, transactions, logger, categorizer), nil
 }
 
 // convertSelmaRowToTransaction converts a SelmaCSVRow to a Transaction
@@ -204,13 +210,14 @@ func determineCreditDebit(transactionType, amount string) string {
 // It applies categorization using the provided categorizer and associates related transactions like stamp duties.
 //
 // Parameters:
+//   - ctx: Context for cancellation and timeout control of categorization
 //   - transactions: A slice of Transaction objects to process
 //   - logger: Logger instance for logging operations
 //   - categorizer: Optional categorizer for transaction categorization
 //
 // Returns:
 //   - []models.Transaction: The processed transactions with additional metadata
-func ProcessTransactionsWithCategorizer(transactions []models.Transaction, logger logging.Logger, categorizer models.TransactionCategorizer) []models.Transaction {
+func ProcessTransactionsWithCategorizer(ctx context.Context, transactions []models.Transaction, logger logging.Logger, categorizer models.TransactionCategorizer) []models.Transaction {
 	if logger == nil {
 		logger = logging.NewLogrusAdapter("info", "text")
 	}
@@ -222,6 +229,7 @@ func ProcessTransactionsWithCategorizer(transactions []models.Transaction, logge
 
 	// Then apply categorization with statistics tracking
 	finalTransactions := common.ProcessTransactionsWithCategorizationStats(
+		ctx,
 		processedTransactions, logger, categorizer, "Selma")
 
 	logger.Info("Successfully processed Selma transactions",
@@ -244,7 +252,7 @@ func validateFormat(r io.Reader, logger logging.Logger) (bool, error) {
 	header, err := reader.Read()
 	if err != nil {
 		if err == io.EOF {
-			return false, fmt.Errorf("CSV file is empty")
+			return false, fmt.Errorf("%w: CSV file is empty", parsererror.ErrNoTransactions)
 		}
 		return false, fmt.Errorf("failed to read CSV header: %w", err)
 	}
@@ -2,6 +2,8 @@
 package selmaparser
 
 import (
+	"strings"
+
 	"fjacquet/camt-csv/internal/dateutils"
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
@@ -82,7 +84,8 @@ func processTransactionsInternalWithCategorizer(transactions []models.Transactio
 			dateKey := tx.Date.Format(dateutils.DateLayoutEuropean)
 			if dayDuties, exists := stampDuties[dateKey]; exists {
 				if dutyInfo, found := dayDuties[tx.Fund]; found {
-					// Associate stamp duty as a fee (decimal)
+					// The stamp duty row itself is dropped, so Fees is the only
+					// place it is counted; it keeps the sign Selma booked it with
 					tx.Fees = dutyInfo.Amount
 				}
 			}
@@ -117,7 +120,13 @@ func setInvestmentType(tx models.Transaction) models.Transaction {
 		}
 		tx.PartyName = tx.Fund
 	case "selma_fee":
-		tx.Investment = "Expense"
+		// The fee is already the amount of the row, so Fees stays empty
+		if tx.Amount.IsPositive() {
+			tx.Investment = "Refund"
+			tx.Type = "Fee Refund"
+		} else {
+			tx.Investment = "Expense"
+		}
 		tx.PartyName = "Selma"
 	case "dividend":
 		tx.Investment = "Dividend"
@@ -125,6 +134,14 @@ func setInvestmentType(tx models.Transaction) models.Transaction {
 	case "withholding_tax":
 		tx.Investment = "Tax"
 		tx.PartyName = tx.Fund
+	default:
+		// Cash corrections ("cash_adjustment" and the like) move money in
+		// either direction; CreditDebit already follows the amount's sign
+		if strings.Contains(tx.Description, "adjustment") {
+			tx.Investment = "Adjustment"
+			tx.Type = "Adjustment"
+			tx.PartyName = "Selma"
+		}
 	}
 
 	// Set appropriate category based on investment type
@@ -144,7 +161,7 @@ func categorizeTransaction(tx models.Transaction) models.Transaction {
 		tx.Category = "Revenus Financiers"
 	case "Dividend":
 		tx.Category = "Revenus Financiers"
-	case "Expense":
+	case "Expense", "Refund":
 		tx.Category = "Frais Bancaires"
 	case "Tax":
 		tx.Category = "Impôts"
@@ -15,6 +15,7 @@ import (
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -336,3 +337,60 @@ Value1,Value2,Value3`
 		}
 	})
 }
+
+func TestParse_FeeRefundsAndAdjustments(t *testing.T) {
+	file, err := os.Open("testdata/selma_fee_refund.csv")
+	require.NoError(t, err)
+	defer func() { _ = file.Close() }()
+
+	transactions, err := NewAdapter(logging.NewMockLogger()).Parse(context.Background(), file)
+	require.NoError(t, err)
+	require.Len(t, transactions, 6, "stamp duties are attached to their trades")
+
+	// 2024-03-04,stamp_duty,30010002,IE00BK5BQT80,-0.75,CHF, is charged on the
+	// first trade, 2024-03-11,stamp_duty,30010004,IE00BK5BQT80,0.38,CHF, refunded
+	// on the second; both keep the sign of the statement row
+	record, err := transactions[0].MarshalCSV()
+	require.NoError(t, err)
+	assert.Equal(t, "-501.20", record[8])
+	assert.Equal(t, "-0.75", record[20])
+	record, err = transactions[1].MarshalCSV()
+	require.NoError(t, err)
+	assert.Equal(t, "-250.60", record[8])
+	assert.Equal(t, "0.38", record[20])
+
+	// 2024-03-31,selma_fee,30010005,,-12.40,CHF, is counted in Amount only
+	fee := transactions[2]
+	assert.Equal(t, "Expense", fee.Investment)
+	assert.Equal(t, models.TransactionTypeDebit, fee.CreditDebit)
+	record, err = fee.MarshalCSV()
+	require.NoError(t, err)
+	assert.Equal(t, "-12.40", record[8])
+	assert.Equal(t, "0.00", record[20])
+
+	// 2024-04-02,selma_fee,30010006,,3.10,CHF,
+	refund := transactions[3]
+	assert.Equal(t, "Refund", refund.Investment)
+	assert.Equal(t, "Fee Refund", refund.Type)
+	assert.Equal(t, models.TransactionTypeCredit, refund.CreditDebit)
+	assert.Equal(t, "Frais Bancaires", refund.Category)
+	record, err = refund.MarshalCSV()
+	require.NoError(t, err)
+	assert.Equal(t, "3.10", record[8])
+	assert.Equal(t, "0.00", record[20])
+
+	for i, direction := range []string{models.TransactionTypeCredit, models.TransactionTypeDebit} {
+		adjustment := transactions[4+i]
+		assert.Equal(t, "Adjustment", adjustment.Investment)
+		assert.Equal(t, "Adjustment", adjustment.Type)
+		assert.Equal(t, "Selma", adjustment.PartyName)
+		assert.Equal(t, direction, adjustment.CreditDebit)
+	}
+
+	// Every statement row is counted exactly once across Amount and Fees
+	total := decimal.Zero
+	for _, tx := range transactions {
+		total = total.Add(tx.Amount).Add(tx.Fees)
+	}
+	assert.Equal(t, "-761.44", total.StringFixed(2), "sum of the Amount column of the statement")
+}
@@ -0,0 +1,243 @@
+// Package server exposes the statement parsers over HTTP, so conversions can
+// run as a small service instead of one CLI invocation per file.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"fjacquet/camt-csv/internal/common"
+	"fjacquet/camt-csv/internal/formatter"
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parser"
+)
+
+const (
+	// maxRequestSize limits the size of a request body or multipart upload.
+	maxRequestSize = 32 << 20
+
+	// shutdownTimeout bounds how long in-flight requests may run after a shutdown request.
+	shutdownTimeout = 10 * time.Second
+
+	// pdfFormField is the multipart field holding the uploaded PDF files.
+	pdfFormField = "file"
+)
+
+// Server converts uploaded statements to CSV using the existing parsers.
+type Server struct {
+	camt       parser.Parser
+	pdf        parser.Parser
+	formatters *formatter.FormatterRegistry
+	format     string
+	opts       formatter.Options
+	bom        bool
+	logger     logging.Logger
+
+	// Parsers and auto-learning are not designed for concurrent use,
+	// so conversions run one at a time.
+	mu sync.Mutex
+}
+
+// New creates a Server. format is the output format used when a request does
+// not set the "format" query parameter; opts apply to every response.
+func New(camt, pdf parser.Parser, formatters *formatter.FormatterRegistry, format string, opts formatter.Options, logger logging.Logger) *Server {
+	if logger == nil {
+		logger = logging.NewLogrusAdapter("info", "text")
+	}
+	if formatters == nil {
+		formatters = formatter.NewFormatterRegistry()
+	}
+	return &Server{
+		camt:       camt,
+		pdf:        pdf,
+		formatters: formatters,
+		format:     format,
+		opts:       opts,
+		logger:     logger,
+	}
+}
+
+// SetBOM makes every response start with a UTF-8 byte order mark.
+func (s *Server) SetBOM(bom bool) {
+	s.bom = bom
+}
+
+// Handler returns the HTTP routes:
+//
+//	POST /convert/camt  request body is a CAMT.053 XML document
+//	POST /convert/pdf   multipart form with one or more "file" parts
+//
+// Both respond with CSV in the selected format.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /convert/camt", s.handleCAMT)
+	mux.HandleFunc("POST /convert/pdf", s.handlePDF)
+	return mux
+}
+
+// handleCAMT parses the request body as CAMT.053 XML.
+func (s *Server) handleCAMT(w http.ResponseWriter, r *http.Request) {
+	out, ok := s.outputFormatter(w, r)
+	if !ok {
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxRequestSize)
+	transactions, err := s.parse(r.Context(), s.camt, body)
+	if err != nil {
+		s.fail(w, requestErrorStatus(err), fmt.Errorf("error parsing CAMT statement: %w", err))
+		return
+	}
+
+	s.writeCSV(w, transactions, out)
+}
+
+// handlePDF parses every uploaded PDF and returns their transactions as one CSV.
+func (s *Server) handlePDF(w http.ResponseWriter, r *http.Request) {
+	out, ok := s.outputFormatter(w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestSize)
+	if err := r.ParseMultipartForm(maxRequestSize); err != nil {
+		s.fail(w, requestErrorStatus(err), fmt.Errorf("error reading multipart upload: %w", err))
+		return
+	}
+	defer func() {
+		if err := r.MultipartForm.RemoveAll(); err != nil {
+			s.logger.WithError(err).Warn("Failed to remove multipart temporary files")
+		}
+	}()
+
+	files := r.MultipartForm.File[pdfFormField]
+	if len(files) == 0 {
+		s.fail(w, http.StatusBadRequest, fmt.Errorf("missing multipart field %q", pdfFormField))
+		return
+	}
+
+	var transactions []models.Transaction
+	for _, fh := range files {
+		file, err := fh.Open()
+		if err != nil {
+			s.fail(w, http.StatusBadRequest, fmt.Errorf("error opening %s: %w", fh.Filename, err))
+			return
+		}
+		parsed, err := s.parse(r.Context(), s.pdf, file)
+		if closeErr := file.Close(); closeErr != nil {
+			s.logger.WithError(closeErr).Warn("Failed to close uploaded file",
+				logging.Field{Key: "file", Value: fh.Filename})
+		}
+		if err != nil {
+			s.fail(w, http.StatusBadRequest, fmt.Errorf("error parsing %s: %w", fh.Filename, err))
+			return
+		}
+		transactions = append(transactions, parsed...)
+	}
+
+	s.writeCSV(w, transactions, out)
+}
+
+// parse runs p under the server lock.
+func (s *Server) parse(ctx context.Context, p parser.Parser, r io.Reader) ([]models.Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return p.Parse(ctx, r)
+}
+
+// outputFormatter resolves the formatter from the "format" query parameter or
+// the server default, writing a 400 response if it is unknown.
+func (s *Server) outputFormatter(w http.ResponseWriter, r *http.Request) (formatter.OutputFormatter, bool) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = s.format
+	}
+	out, err := s.formatters.Get(format)
+	if err != nil {
+		s.fail(w, http.StatusBadRequest, err)
+		return nil, false
+	}
+	return formatter.ApplyOptions(out, s.opts), true
+}
+
+// writeCSV streams the transactions as CSV to the response.
+func (s *Server) writeCSV(w http.ResponseWriter, transactions []models.Transaction, out formatter.OutputFormatter) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="transactions.csv"`)
+	if s.bom {
+		if _, err := io.WriteString(w, models.UTF8BOM); err != nil {
+			s.logger.WithError(err).Error("Failed to write CSV response")
+			return
+		}
+	}
+	if err := common.WriteTransactionsWithFormatter(w, transactions, out, out.Delimiter()); err != nil {
+		// Headers are already sent; all we can do is log
+		s.logger.WithError(err).Error("Failed to write CSV response")
+		return
+	}
+	s.logger.Info("Conversion served", logging.Field{Key: "count", Value: len(transactions)})
+}
+
+// fail logs err and writes it as a plain-text error response.
+func (s *Server) fail(w http.ResponseWriter, status int, err error) {
+	s.logger.WithError(err).Warn("Conversion request failed",
+		logging.Field{Key: "status", Value: status})
+	http.Error(w, err.Error(), status)
+}
+
+// requestErrorStatus maps an error reading the request to an HTTP status.
+func requestErrorStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// ListenAndServe serves handler on addr until ctx is cancelled, then shuts the
+// server down gracefully, letting in-flight requests finish.
+func ListenAndServe(ctx context.Context, addr string, handler http.Handler, logger logging.Logger) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %w", addr, err)
+	}
+	return Serve(ctx, listener, handler, logger)
+}
+
+// Serve is ListenAndServe on an existing listener.
+func Serve(ctx context.Context, listener net.Listener, handler http.Handler, logger logging.Logger) error {
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Info("HTTP server listening", logging.Field{Key: "addr", Value: listener.Addr().String()})
+		errCh <- srv.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Info("Shutting down HTTP server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("error shutting down HTTP server: %w", err)
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"fjacquet/camt-csv/internal/formatter"
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubParser returns one transaction whose description is the request body.
+type stubParser struct {
+	err error
+}
+
+func (p *stubParser) Parse(_ context.Context, r io.Reader) ([]models.Transaction, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return []models.Transaction{{
+		Date:        time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+		Description: strings.TrimSpace(string(body)),
+		Amount:      decimal.NewFromInt(42),
+		Currency:    "CHF",
+	}}, nil
+}
+
+func newTestServer(camtErr, pdfErr error) *Server {
+	return New(&stubParser{err: camtErr}, &stubParser{err: pdfErr},
+		formatter.NewFormatterRegistry(), "standard", formatter.Options{}, logging.NewMockLogger())
+}
+
+func TestHandleCAMT(t *testing.T) {
+	srv := newTestServer(nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/convert/camt", strings.NewReader("Coffee shop"))
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[1], "Coffee shop")
+}
+
+func TestHandleCAMT_FormatQuery(t *testing.T) {
+	srv := newTestServer(nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/convert/camt?format=icompta", strings.NewReader("Coffee shop"))
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), ";", "iCompta output is semicolon-delimited")
+}
+
+func TestHandleCAMT_BOM(t *testing.T) {
+	srv := New(&stubParser{}, &stubParser{}, formatter.NewFormatterRegistry(), "standard",
+		formatter.Options{}, logging.NewMockLogger())
+	srv.SetBOM(true)
+
+	req := httptest.NewRequest(http.MethodPost, "/convert/camt", strings.NewReader("Café"))
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, strings.HasPrefix(rec.Body.String(), models.UTF8BOM+"Status,"), "response must start with the byte order mark")
+}
+
+func TestHandleCAMT_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		err    error
+		status int
+	}{
+		{"wrong method", http.MethodGet, "/convert/camt", nil, http.StatusMethodNotAllowed},
+		{"unknown format", http.MethodPost, "/convert/camt?format=nope", nil, http.StatusBadRequest},
+		{"parse error", http.MethodPost, "/convert/camt", errors.New("invalid XML"), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(tt.err, nil)
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader("<xml/>"))
+			rec := httptest.NewRecorder()
+			srv.Handler().ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
+
+func newMultipartRequest(t *testing.T, field string, files map[string]string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for name, content := range files {
+		part, err := mw.CreateFormFile(field, name)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/convert/pdf", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestHandlePDF(t *testing.T) {
+	srv := newTestServer(nil, nil)
+
+	req := newMultipartRequest(t, "file", map[string]string{
+		"a.pdf": "First statement",
+		"b.pdf": "Second statement",
+	})
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, "First statement")
+	assert.Contains(t, body, "Second statement")
+}
+
+func TestHandlePDF_Errors(t *testing.T) {
+	t.Run("missing file field", func(t *testing.T) {
+		srv := newTestServer(nil, nil)
+		req := newMultipartRequest(t, "upload", map[string]string{"a.pdf": "x"})
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("not multipart", func(t *testing.T) {
+		srv := newTestServer(nil, nil)
+		req := httptest.NewRequest(http.MethodPost, "/convert/pdf", strings.NewReader("raw"))
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("parse error", func(t *testing.T) {
+		srv := newTestServer(nil, errors.New("not a PDF"))
+		req := newMultipartRequest(t, "file", map[string]string{"a.pdf": "x"})
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "a.pdf")
+	})
+}
+
+func TestServe_GracefulShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(ctx, listener, newTestServer(nil, nil).Handler(), logging.NewMockLogger())
+	}()
+
+	resp, err := http.Post("http://"+listener.Addr().String()+"/convert/camt", "application/xml", strings.NewReader("Coffee"))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
@@ -280,6 +280,30 @@ func TestLoadCategories_EmptyFile(t *testing.T) {
 	assert.Empty(t, cats)
 }
 
+func TestLoadCategories_AmountBounds(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "categories.yaml")
+
+	content := `categories:
+  - name: Groceries
+    keywords: [COOP]
+    max_amount: 80
+  - name: Shopping
+    keywords: [COOP]
+    min_amount: "80.01"
+`
+	require.NoError(t, os.WriteFile(file, []byte(content), models.PermissionConfigFile))
+
+	cats, err := NewCategoryStore(file, "", "").LoadCategories()
+	require.NoError(t, err)
+	require.Len(t, cats, 2)
+	require.NotNil(t, cats[0].MaxAmount)
+	assert.Equal(t, "80", cats[0].MaxAmount.String())
+	assert.Nil(t, cats[0].MinAmount)
+	require.NotNil(t, cats[1].MinAmount)
+	assert.Equal(t, "80.01", cats[1].MinAmount.String())
+}
+
 // Test resolveConfigFile with relative path that exists.
 
 func TestResolveConfigFile_RelativePathExists(t *testing.T) {
@@ -0,0 +1,150 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fjacquet/camt-csv/internal/models"
+)
+
+// ErrFileLocked is returned by the save methods when another process holds
+// the lock on a mapping file for longer than lockTimeout. Nothing is written;
+// the caller can keep its mappings and save them later.
+var ErrFileLocked = errors.New("file is locked by another process")
+
+// How long a save waits for another process to release a mapping file, and
+// how often it tries to take the lock meanwhile.
+var (
+	lockTimeout       = 2 * time.Second
+	lockRetryInterval = 50 * time.Millisecond
+)
+
+// lockFileSuffix names the file next to a mapping file that saves lock. The
+// mapping file itself cannot hold the lock, as it is replaced on each save.
+const lockFileSuffix = ".lock"
+
+// updateLocked rewrites the file at filePath with what update returns for its
+// current content, holding an exclusive advisory lock on filePath+".lock"
+// throughout, so that processes saving the same file at the same time take
+// turns and each sees what the previous one wrote. A missing file's current
+// content is empty. The file is backed up, then replaced through a temporary
+// file and a rename, so that a failed save never leaves it truncated.
+//
+// On platforms without advisory locks, the file is rewritten without one.
+func (s *CategoryStore) updateLocked(filePath string, update func(current []byte) ([]byte, error)) error {
+	lock, err := os.OpenFile(filePath+lockFileSuffix, os.O_RDWR|os.O_CREATE, models.PermissionNonSecretFile) // #nosec G304 -- config file path resolved internally
+	if err != nil {
+		return fmt.Errorf("error opening lock file for %s: %w", filePath, err)
+	}
+	defer func() { _ = lock.Close() }()
+
+	if err := lockWithRetry(lock); err != nil {
+		return fmt.Errorf("%w: %s", err, filePath)
+	}
+	defer func() { _ = unlockFile(lock) }()
+
+	current, err := os.ReadFile(filePath) // #nosec G304 -- config file path resolved internally
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error reading %s: %w", filePath, err)
+	}
+
+	// Create backup before modifying the file (critical - prevents data loss)
+	if len(current) > 0 {
+		if err := s.createBackup(filePath); err != nil {
+			return fmt.Errorf("failed to backup before save: %w", err)
+		}
+	}
+
+	data, err := update(current)
+	if err != nil {
+		return err
+	}
+	return replaceFile(filePath, data)
+}
+
+// replaceFile writes data to a temporary file in the directory of filePath and
+// renames it over filePath, keeping the permissions of the file it replaces.
+func replaceFile(filePath string, data []byte) error {
+	perm := os.FileMode(models.PermissionNonSecretFile)
+	if info, err := os.Stat(filePath); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating temporary file for %s: %w", filePath, err)
+	}
+	tmp := tmpFile.Name()
+
+	if _, err := tmpFile.Write(data); err != nil {
+		_ = tmpFile.Close()
+		_ = os.Remove(tmp)
+		return fmt.Errorf("error writing %s: %w", filePath, err)
+	}
+	if err := tmpFile.Chmod(perm); err != nil {
+		_ = tmpFile.Close()
+		_ = os.Remove(tmp)
+		return fmt.Errorf("error writing %s: %w", filePath, err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("error writing %s: %w", filePath, err)
+	}
+
+	if err := os.Rename(tmp, filePath); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("error replacing %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// lockWithRetry takes an exclusive lock on file, trying again every
+// lockRetryInterval until lockTimeout has passed, and returns ErrFileLocked
+// if the lock is still held by then.
+func lockWithRetry(file *os.File) error {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		locked, err := tryLockFile(file)
+		if err != nil {
+			return fmt.Errorf("error locking file: %w", err)
+		}
+		if locked {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrFileLocked
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// mergeMappings returns the mappings of current, a mapping file's content as
+// re-read under the lock, updated with mappings, the entries being saved.
+// Entries of current that mappings does not name are kept as they are, so
+// that entries saved, edited or removed by someone else since the file was
+// loaded stay that way. Keys are compared after normalize; mappings wins for
+// a key in both.
+func mergeMappings(current map[string]string, mappings map[string]string, normalize func(string) string) map[string]string {
+	merged := make(map[string]string, len(current)+len(mappings))
+	ours := make(map[string]bool, len(mappings))
+	for key, category := range mappings {
+		merged[key] = category
+		ours[normalize(key)] = true
+	}
+	for key, category := range current {
+		if !ours[normalize(key)] {
+			merged[key] = category
+		}
+	}
+	return merged
+}
+
+// lowerName is the key normalization of the party mapping files, whose
+// lookups ignore case.
+func lowerName(name string) string {
+	return strings.ToLower(name)
+}
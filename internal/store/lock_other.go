@@ -0,0 +1,16 @@
+//go:build !unix
+
+package store
+
+import "os"
+
+// tryLockFile reports the lock as taken: advisory locks are not available on
+// this platform, so concurrent saves are not serialized.
+func tryLockFile(_ *os.File) (bool, error) {
+	return true, nil
+}
+
+// unlockFile does nothing, as tryLockFile takes no lock.
+func unlockFile(_ *os.File) error {
+	return nil
+}
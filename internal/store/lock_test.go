@@ -0,0 +1,157 @@
+//go:build unix
+
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateLocked_ConcurrentUpdatesTakeTurns(t *testing.T) {
+	counterFile := filepath.Join(t.TempDir(), "counter")
+	store := NewCategoryStore("", "", "")
+	store.SetBackupConfig(false, "", "")
+
+	// Each update reads the counter, takes a while, then writes it incremented:
+	// without the lock, updates running at the same time would be lost
+	const processes = 8
+	var wg sync.WaitGroup
+	errs := make([]error, processes)
+	for i := range processes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = store.updateLocked(counterFile, func(current []byte) ([]byte, error) {
+				count, _ := strconv.Atoi(string(current))
+				time.Sleep(5 * time.Millisecond)
+				return []byte(strconv.Itoa(count + 1)), nil
+			})
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	data, err := os.ReadFile(counterFile)
+	require.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(processes), string(data))
+}
+
+func TestSaveCreditorMappings_ConcurrentSavesMerge(t *testing.T) {
+	creditorsFile := filepath.Join(t.TempDir(), "creditors.yaml")
+	writeFile(t, creditorsFile, "existing party: Shopping\n")
+
+	// Each store stands for a process that loaded the file before the others saved
+	const processes = 8
+	var wg sync.WaitGroup
+	errs := make([]error, processes)
+	for i := range processes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store := NewCategoryStore("", creditorsFile, "")
+			store.SetBackupConfig(false, "", "")
+			mappings, err := store.LoadCreditorMappings()
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			mappings[fmt.Sprintf("party %d", i)] = "Restaurants"
+			errs[i] = store.SaveCreditorMappings(mappings)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	store := NewCategoryStore("", creditorsFile, "")
+	mappings, err := store.LoadCreditorMappings()
+	require.NoError(t, err)
+	assert.Len(t, mappings, processes+1, "no process overwrote the entries of another")
+	assert.Equal(t, "Shopping", mappings["existing party"])
+	for i := range processes {
+		assert.Equal(t, "Restaurants", mappings[fmt.Sprintf("party %d", i)])
+	}
+}
+
+func TestSaveCreditorMappings_MergeKeepsOurCategory(t *testing.T) {
+	creditorsFile := filepath.Join(t.TempDir(), "creditors.yaml")
+	writeFile(t, creditorsFile, "Migros: Shopping\nCoop: Courses\n")
+
+	store := NewCategoryStore("", creditorsFile, "")
+	store.SetBackupConfig(false, "", "")
+	require.NoError(t, store.SaveCreditorMappings(map[string]string{"migros": "Alimentation"}))
+
+	mappings, err := store.LoadCreditorMappings()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"migros": "Alimentation", "Coop": "Courses"}, mappings)
+}
+
+func TestSaveCreditorMappings_LockedFile(t *testing.T) {
+	restoreTimeout, restoreInterval := lockTimeout, lockRetryInterval
+	lockTimeout, lockRetryInterval = 100*time.Millisecond, 10*time.Millisecond
+	t.Cleanup(func() { lockTimeout, lockRetryInterval = restoreTimeout, restoreInterval })
+
+	creditorsFile := filepath.Join(t.TempDir(), "creditors.yaml")
+	writeFile(t, creditorsFile, "Migros: Shopping\n")
+
+	// Another process holds the lock
+	holder, err := os.OpenFile(creditorsFile+lockFileSuffix, os.O_RDWR|os.O_CREATE, 0600)
+	require.NoError(t, err)
+	defer func() { _ = holder.Close() }()
+	locked, err := tryLockFile(holder)
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	store := NewCategoryStore("", creditorsFile, "")
+	err = store.SaveCreditorMappings(map[string]string{"coop": "Courses"})
+	assert.ErrorIs(t, err, ErrFileLocked)
+
+	data, err := os.ReadFile(creditorsFile)
+	require.NoError(t, err)
+	assert.Equal(t, "Migros: Shopping\n", string(data), "nothing is written while the file is locked")
+
+	// Once the lock is released, the save goes through
+	require.NoError(t, unlockFile(holder))
+	require.NoError(t, store.SaveCreditorMappings(map[string]string{"coop": "Courses"}))
+	mappings, err := store.LoadCreditorMappings()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Migros": "Shopping", "coop": "Courses"}, mappings)
+}
+
+func TestSaveCreditorMappings_ReplacesFile(t *testing.T) {
+	dir := t.TempDir()
+	creditorsFile := filepath.Join(dir, "creditors.yaml")
+	writeFile(t, creditorsFile, "Migros: Shopping\n")
+	require.NoError(t, os.Chmod(creditorsFile, 0640))
+	before, err := os.Stat(creditorsFile)
+	require.NoError(t, err)
+
+	store := NewCategoryStore("", creditorsFile, "")
+	store.SetBackupConfig(false, "", "")
+	require.NoError(t, store.SaveCreditorMappings(map[string]string{"coop": "Courses"}))
+
+	// The file is replaced rather than rewritten in place, keeping its
+	// permissions, and no temporary file is left behind
+	after, err := os.Stat(creditorsFile)
+	require.NoError(t, err)
+	assert.False(t, os.SameFile(before, after))
+	assert.Equal(t, os.FileMode(0640), after.Mode().Perm())
+	leftovers, err := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	require.NoError(t, err)
+	assert.Empty(t, leftovers)
+
+	mappings, err := store.LoadCreditorMappings()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Migros": "Shopping", "coop": "Courses"}, mappings)
+}
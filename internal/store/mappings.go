@@ -0,0 +1,151 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"fjacquet/camt-csv/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mappingFile describes one of the two party mapping files.
+type mappingFile struct {
+	kind        string // "creditor" or "debtor", used in error messages
+	defaultName string
+	isDebit     bool // Direction of the transactions looked up in this file
+}
+
+var (
+	creditorMappingFile = mappingFile{kind: "creditor", defaultName: "creditors.yaml", isDebit: false}
+	debtorMappingFile   = mappingFile{kind: "debtor", defaultName: "debtors.yaml", isDebit: true}
+)
+
+// readMappingFile loads a mapping file. Plain "party: category" entries are
+// returned in plain and entries with per-direction categories in directional.
+// A missing file yields empty maps.
+func (s *CategoryStore) readMappingFile(filename string, file mappingFile) (map[string]string, map[string]models.DirectionalCategory, error) {
+	if filename == "" {
+		filename = file.defaultName
+	}
+
+	filePath, err := s.resolveConfigFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, map[string]models.DirectionalCategory{}, nil
+		}
+		return nil, nil, fmt.Errorf("error resolving %s mappings file: %w", file.kind, err)
+	}
+
+	data, err := os.ReadFile(filePath) // #nosec G304 -- config file path resolved internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, map[string]models.DirectionalCategory{}, nil
+		}
+		return nil, nil, fmt.Errorf("error reading %s mappings file: %w", file.kind, err)
+	}
+
+	plain, directional, err := parseMappings(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing %s mappings: %w", file.kind, err)
+	}
+	return plain, directional, nil
+}
+
+// parseMappings decodes mapping YAML whose values are either a category name
+// or a models.DirectionalCategory.
+func parseMappings(data []byte) (map[string]string, map[string]models.DirectionalCategory, error) {
+	var nodes map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &nodes); err != nil {
+		return nil, nil, err
+	}
+
+	plain := make(map[string]string, len(nodes))
+	directional := make(map[string]models.DirectionalCategory)
+	for party, node := range nodes {
+		switch node.Kind {
+		case yaml.MappingNode:
+			var entry models.DirectionalCategory
+			if err := node.Decode(&entry); err != nil {
+				return nil, nil, fmt.Errorf("entry %q: %w", party, err)
+			}
+			directional[party] = entry
+		default:
+			var category string
+			if err := node.Decode(&category); err != nil {
+				return nil, nil, fmt.Errorf("entry %q: %w", party, err)
+			}
+			plain[party] = category
+		}
+	}
+	return plain, directional, nil
+}
+
+// marshalMappings encodes plain mappings together with the directional
+// entries already in the file, so that saving learned mappings keeps them. A
+// plain mapping for a party that has a directional entry fills in the file's
+// direction when the entry has no category for it.
+func marshalMappings(plain map[string]string, directional map[string]models.DirectionalCategory, file mappingFile) ([]byte, error) {
+	if len(directional) == 0 {
+		return yaml.Marshal(plain)
+	}
+
+	byLowerName := make(map[string]string, len(directional))
+	for party := range directional {
+		byLowerName[strings.ToLower(party)] = party
+	}
+
+	entries := make(map[string]interface{}, len(plain)+len(directional))
+	for party, entry := range directional {
+		entries[party] = entry
+	}
+	for party, category := range plain {
+		original, ok := byLowerName[strings.ToLower(party)]
+		if !ok {
+			entries[party] = category
+			continue
+		}
+		entry := directional[original]
+		if entry.For(file.isDebit) == "" {
+			if file.isDebit {
+				entry.Debit = category
+			} else {
+				entry.Credit = category
+			}
+			entries[original] = entry
+		}
+	}
+	return yaml.Marshal(entries)
+}
+
+// LoadDirectionalMappings returns the mapping entries of the creditor and
+// debtor files that give a category per direction, keyed by lowercased party
+// name. When both files have an entry for a party, their categories are
+// combined, the creditor file winning for a direction set in both.
+func (s *CategoryStore) LoadDirectionalMappings() (map[string]models.DirectionalCategory, error) {
+	_, creditorEntries, err := s.readMappingFile(s.CreditorsFile, creditorMappingFile)
+	if err != nil {
+		return nil, err
+	}
+	_, debtorEntries, err := s.readMappingFile(s.DebtorsFile, debtorMappingFile)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]models.DirectionalCategory, len(creditorEntries)+len(debtorEntries))
+	for _, entries := range []map[string]models.DirectionalCategory{creditorEntries, debtorEntries} {
+		for party, entry := range entries {
+			key := strings.ToLower(party)
+			current := merged[key]
+			if current.Debit == "" {
+				current.Debit = entry.Debit
+			}
+			if current.Credit == "" {
+				current.Credit = entry.Credit
+			}
+			merged[key] = current
+		}
+	}
+	return merged, nil
+}
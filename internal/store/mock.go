@@ -9,13 +9,27 @@ type MockCategoryStore struct {
 	Categories       []models.CategoryConfig
 	CreditorMappings map[string]string
 	DebtorMappings   map[string]string
+	TagRules         []models.TagRule
+	MCCMappings      map[string]string
+	IBANMappings     map[string]string
+	CleanupRules     []models.NameCleanupRule // nil means no cleanup file
+	InternalParties  models.InternalPartiesConfig
+
+	DirectionalMappings map[string]models.DirectionalCategory
 
 	// Error flags for testing error conditions
-	LoadCategoriesError       error
-	LoadCreditorMappingsError error
-	LoadDebtorMappingsError   error
-	SaveCreditorMappingsError error
-	SaveDebtorMappingsError   error
+	LoadCategoriesError          error
+	LoadCreditorMappingsError    error
+	LoadDebtorMappingsError      error
+	LoadTagRulesError            error
+	LoadMCCMappingsError         error
+	LoadIBANMappingsError        error
+	LoadCleanupRulesError        error
+	LoadInternalPartiesError     error
+	LoadDirectionalMappingsError error
+	SaveCreditorMappingsError    error
+	SaveDebtorMappingsError      error
+	SaveIBANMappingsError        error
 }
 
 // LoadCategories returns the mock categories.
@@ -58,6 +72,54 @@ func (m *MockCategoryStore) LoadDebtorMappings() (map[string]string, error) {
 	return result, nil
 }
 
+// LoadDirectionalMappings returns the mock directional mappings.
+func (m *MockCategoryStore) LoadDirectionalMappings() (map[string]models.DirectionalCategory, error) {
+	if m.LoadDirectionalMappingsError != nil {
+		return nil, m.LoadDirectionalMappingsError
+	}
+	return m.DirectionalMappings, nil
+}
+
+// LoadTagRules returns the mock tag rules.
+func (m *MockCategoryStore) LoadTagRules() ([]models.TagRule, error) {
+	if m.LoadTagRulesError != nil {
+		return nil, m.LoadTagRulesError
+	}
+	return m.TagRules, nil
+}
+
+// LoadMCCMappings returns the mock merchant category code mappings.
+func (m *MockCategoryStore) LoadMCCMappings() (map[string]string, error) {
+	if m.LoadMCCMappingsError != nil {
+		return nil, m.LoadMCCMappingsError
+	}
+	return m.MCCMappings, nil
+}
+
+// LoadIBANMappings returns the mock counterparty IBAN mappings.
+func (m *MockCategoryStore) LoadIBANMappings() (map[string]string, error) {
+	if m.LoadIBANMappingsError != nil {
+		return nil, m.LoadIBANMappingsError
+	}
+	return m.IBANMappings, nil
+}
+
+// LoadNameCleanupRules returns the mock cleanup rules.
+func (m *MockCategoryStore) LoadNameCleanupRules() ([]models.NameCleanupRule, error) {
+	if m.LoadCleanupRulesError != nil {
+		return nil, m.LoadCleanupRulesError
+	}
+	return m.CleanupRules, nil
+}
+
+// LoadInternalParties returns the mock internal party settings.
+func (m *MockCategoryStore) LoadInternalParties() (models.InternalPartiesConfig, error) {
+	if m.LoadInternalPartiesError != nil {
+		return models.InternalPartiesConfig{}, m.LoadInternalPartiesError
+	}
+	return m.InternalParties, nil
+}
+
 // SaveCreditorMappings updates the mock creditor mappings.
 func (m *MockCategoryStore) SaveCreditorMappings(mappings map[string]string) error {
 	if m.SaveCreditorMappingsError != nil {
@@ -88,6 +150,20 @@ func (m *MockCategoryStore) SaveDebtorMappings(mappings map[string]string) error
 	return nil
 }
 
+// SaveIBANMappings updates the mock counterparty IBAN mappings.
+func (m *MockCategoryStore) SaveIBANMappings(mappings map[string]string) error {
+	if m.SaveIBANMappingsError != nil {
+		return m.SaveIBANMappingsError
+	}
+	if m.IBANMappings == nil {
+		m.IBANMappings = make(map[string]string)
+	}
+	for k, v := range mappings {
+		m.IBANMappings[k] = v
+	}
+	return nil
+}
+
 // FindConfigFile is a mock implementation that returns a dummy path.
 func (m *MockCategoryStore) FindConfigFile(filename string) (string, error) {
 	return "/mock/path/" + filename, nil
@@ -7,9 +7,13 @@
 // configurations and saving updated mappings back to disk.
 //
 // Configuration files supported:
-//   - categories.yaml: Category definitions with keywords for pattern matching
+//   - categories.yaml: Category definitions with keywords for pattern matching, and
+//     the user's own (internal) party names
 //   - creditors.yaml: Direct mappings from creditor names to categories
 //   - debtors.yaml: Direct mappings from debtor names to categories
+//   - budgets.yaml: Monthly budget per category, for the stats command
+//   - mcc.yaml: Mappings from card merchant category codes to categories
+//   - iban_mappings.yaml: Mappings from counterparty IBANs to categories
 package store
 
 import (
@@ -17,10 +21,12 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"fjacquet/camt-csv/internal/models"
 
+	"github.com/shopspring/decimal"
 	"gopkg.in/yaml.v3"
 )
 
@@ -38,6 +44,13 @@ type CategoryStore struct {
 	CategoriesFile string // Path to the categories configuration file
 	CreditorsFile  string // Path to the creditor mappings file
 	DebtorsFile    string // Path to the debtor mappings file
+	TagsFile       string // Path to the tag rules file
+	MCCFile        string // Path to the merchant category code mappings file
+	IBANFile       string // Path to the counterparty IBAN mappings file
+	CleanupFile    string // Path to the party-name cleanup rules file
+	BudgetsFile    string // Path to the category budgets file
+	ProfilesFile   string // Path to the export profiles file
+	PresetsFile    string // Path to the bank presets file
 
 	// Backup configuration (optional, defaults provided if not set)
 	backupEnabled         bool
@@ -194,6 +207,53 @@ func (s *CategoryStore) LoadCategories() ([]models.CategoryConfig, error) {
 	return config.Categories, nil
 }
 
+// LoadInternalParties loads the internal party settings from the categories file:
+//
+//	internal_parties: [names of the user's own accounts and household members]
+//	internal_transfer_category: category assigned to transfers with them
+//	exclude_internal_transfers_from_stats: whether to leave them out of statistics
+//
+// If the file is not found, or uses the legacy list layout, returns an empty
+// configuration without error.
+//
+// Returns:
+//   - models.InternalPartiesConfig: The internal party settings
+//   - error: Any error encountered during file reading or YAML parsing
+func (s *CategoryStore) LoadInternalParties() (models.InternalPartiesConfig, error) {
+	filename := s.CategoriesFile
+	if filename == "" {
+		filename = "categories.yaml"
+	}
+
+	filePath, err := s.resolveConfigFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return models.InternalPartiesConfig{}, nil
+		}
+		return models.InternalPartiesConfig{}, fmt.Errorf("error resolving categories file: %w", err)
+	}
+
+	data, err := os.ReadFile(filePath) // #nosec G304 -- config file path resolved internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return models.InternalPartiesConfig{}, nil
+		}
+		return models.InternalPartiesConfig{}, fmt.Errorf("error reading categories file: %w", err)
+	}
+
+	var config models.InternalPartiesConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		// The legacy list layout has no room for internal parties
+		var categories []models.CategoryConfig
+		if yaml.Unmarshal(data, &categories) == nil {
+			return models.InternalPartiesConfig{}, nil
+		}
+		return models.InternalPartiesConfig{}, fmt.Errorf("error parsing categories file: %w", err)
+	}
+
+	return config, nil
+}
+
 // createBackup creates a timestamped backup of the specified file.
 // This is called before saving to provide a safety net for category mapping changes.
 // If the original file doesn't exist, no backup is created (no error).
@@ -264,15 +324,77 @@ func (s *CategoryStore) createBackup(filePath string) error {
 
 // LoadCreditorMappings loads creditor-to-category mappings from the configured YAML file.
 // These mappings provide direct associations between creditor names and their assigned categories,
-// enabling fast categorization without pattern matching or AI inference.
+// enabling fast categorization without pattern matching or AI inference. Entries
+// that give a category per direction are returned by LoadDirectionalMappings.
 //
 // Returns:
 //   - map[string]string: Map of creditor names to category names
 //   - error: Any error encountered during file reading or YAML parsing
 func (s *CategoryStore) LoadCreditorMappings() (map[string]string, error) {
-	filename := s.CreditorsFile
+	mappings, _, err := s.readMappingFile(s.CreditorsFile, creditorMappingFile)
+	return mappings, err
+}
+
+// LoadDebtorMappings loads debtor-to-category mappings from the configured YAML file.
+// These mappings provide direct associations between debtor names and their assigned categories,
+// enabling fast categorization without pattern matching or AI inference. Entries
+// that give a category per direction are returned by LoadDirectionalMappings.
+//
+// Returns:
+//   - map[string]string: Map of debtor names to category names
+//   - error: Any error encountered during file reading or YAML parsing
+func (s *CategoryStore) LoadDebtorMappings() (map[string]string, error) {
+	mappings, _, err := s.readMappingFile(s.DebtorsFile, debtorMappingFile)
+	return mappings, err
+}
+
+// LoadTagRules loads tag rules from the configured YAML file.
+// If the file is not found, returns an empty slice without error.
+//
+// Returns:
+//   - []models.TagRule: Slice of tag rules loaded from the file
+//   - error: Any error encountered during file reading or YAML parsing
+func (s *CategoryStore) LoadTagRules() ([]models.TagRule, error) {
+	filename := s.TagsFile
 	if filename == "" {
-		filename = "creditors.yaml"
+		filename = "tags.yaml"
+	}
+
+	filePath, err := s.resolveConfigFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []models.TagRule{}, nil
+		}
+		return nil, fmt.Errorf("error resolving tags file: %w", err)
+	}
+
+	data, err := os.ReadFile(filePath) // #nosec G304 -- config file path resolved internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []models.TagRule{}, nil
+		}
+		return nil, fmt.Errorf("error reading tags file: %w", err)
+	}
+
+	var config models.TagsConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing tags file: %w", err)
+	}
+
+	return config.Tags, nil
+}
+
+// LoadMCCMappings loads the merchant-category-code-to-category mappings from
+// the configured YAML file. Codes are returned without surrounding spaces.
+// If the file is not found, returns an empty map without error.
+//
+// Returns:
+//   - map[string]string: Map of merchant category codes to category names
+//   - error: Any error encountered during file reading or YAML parsing
+func (s *CategoryStore) LoadMCCMappings() (map[string]string, error) {
+	filename := s.MCCFile
+	if filename == "" {
+		filename = "mcc.yaml"
 	}
 
 	filePath, err := s.resolveConfigFile(filename)
@@ -280,33 +402,41 @@ func (s *CategoryStore) LoadCreditorMappings() (map[string]string, error) {
 		if os.IsNotExist(err) {
 			return map[string]string{}, nil
 		}
-		return nil, fmt.Errorf("error resolving creditor mappings file: %w", err)
+		return nil, fmt.Errorf("error resolving MCC file: %w", err)
 	}
 
 	data, err := os.ReadFile(filePath) // #nosec G304 -- config file path resolved internally
 	if err != nil {
-		return nil, fmt.Errorf("error reading creditor mappings file: %w", err)
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("error reading MCC file: %w", err)
 	}
 
-	var mappings map[string]string
-	if err := yaml.Unmarshal(data, &mappings); err != nil {
-		return nil, fmt.Errorf("error parsing creditor mappings: %w", err)
+	var config models.MCCConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing MCC file: %w", err)
 	}
 
+	mappings := make(map[string]string, len(config.MCC))
+	for mcc, category := range config.MCC {
+		mappings[strings.TrimSpace(mcc)] = category
+	}
 	return mappings, nil
 }
 
-// LoadDebtorMappings loads debtor-to-category mappings from the configured YAML file.
-// These mappings provide direct associations between debtor names and their assigned categories,
-// enabling fast categorization without pattern matching or AI inference.
+// LoadIBANMappings loads the counterparty-IBAN-to-category mappings from the
+// configured YAML file, a map of IBANs to category names. IBANs are returned
+// normalized with models.NormalizeIBAN, so they may be written with spaces.
+// If the file is not found, returns an empty map without error.
 //
 // Returns:
-//   - map[string]string: Map of debtor names to category names
+//   - map[string]string: Map of normalized IBANs to category names
 //   - error: Any error encountered during file reading or YAML parsing
-func (s *CategoryStore) LoadDebtorMappings() (map[string]string, error) {
-	filename := s.DebtorsFile
+func (s *CategoryStore) LoadIBANMappings() (map[string]string, error) {
+	filename := s.IBANFile
 	if filename == "" {
-		filename = "debtors.yaml"
+		filename = "iban_mappings.yaml"
 	}
 
 	filePath, err := s.resolveConfigFile(filename)
@@ -314,22 +444,183 @@ func (s *CategoryStore) LoadDebtorMappings() (map[string]string, error) {
 		if os.IsNotExist(err) {
 			return map[string]string{}, nil
 		}
-		return nil, fmt.Errorf("error resolving debtor mappings file: %w", err)
+		return nil, fmt.Errorf("error resolving IBAN mappings file: %w", err)
 	}
 
 	data, err := os.ReadFile(filePath) // #nosec G304 -- config file path resolved internally
 	if err != nil {
-		return nil, fmt.Errorf("error reading debtor mappings file: %w", err)
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("error reading IBAN mappings file: %w", err)
 	}
 
-	var mappings map[string]string
-	if err := yaml.Unmarshal(data, &mappings); err != nil {
-		return nil, fmt.Errorf("error parsing debtor mappings: %w", err)
+	var entries map[string]string
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("error parsing IBAN mappings file: %w", err)
 	}
 
+	mappings := make(map[string]string, len(entries))
+	for iban, category := range entries {
+		mappings[models.NormalizeIBAN(iban)] = category
+	}
 	return mappings, nil
 }
 
+// LoadNameCleanupRules loads the party-name cleanup rules from the configured
+// YAML file. If the file is not found, returns nil without error, so that the
+// caller can fall back to models.DefaultNameCleanupRules; a file with an empty
+// rule list disables the cleanup.
+//
+// Returns:
+//   - []models.NameCleanupRule: Slice of cleanup rules loaded from the file
+//   - error: Any error encountered during file reading or YAML parsing
+func (s *CategoryStore) LoadNameCleanupRules() ([]models.NameCleanupRule, error) {
+	filename := s.CleanupFile
+	if filename == "" {
+		filename = "cleanup.yaml"
+	}
+
+	filePath, err := s.resolveConfigFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error resolving cleanup file: %w", err)
+	}
+
+	data, err := os.ReadFile(filePath) // #nosec G304 -- config file path resolved internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading cleanup file: %w", err)
+	}
+
+	var config models.NameCleanupConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing cleanup file: %w", err)
+	}
+	if config.Rules == nil {
+		config.Rules = []models.NameCleanupRule{}
+	}
+
+	return config.Rules, nil
+}
+
+// LoadBudgets loads the monthly budget of each category from the configured
+// YAML file. If the file is not found, returns an empty map without error.
+//
+// Returns:
+//   - map[string]decimal.Decimal: Monthly budget by category name
+//   - error: Any error encountered during file reading or YAML parsing
+func (s *CategoryStore) LoadBudgets() (map[string]decimal.Decimal, error) {
+	filename := s.BudgetsFile
+	if filename == "" {
+		filename = "budgets.yaml"
+	}
+
+	filePath, err := s.resolveConfigFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]decimal.Decimal{}, nil
+		}
+		return nil, fmt.Errorf("error resolving budgets file: %w", err)
+	}
+
+	data, err := os.ReadFile(filePath) // #nosec G304 -- config file path resolved internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]decimal.Decimal{}, nil
+		}
+		return nil, fmt.Errorf("error reading budgets file: %w", err)
+	}
+
+	var config models.BudgetsConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing budgets file: %w", err)
+	}
+	if config.Budgets == nil {
+		config.Budgets = map[string]decimal.Decimal{}
+	}
+	for category, budget := range config.Budgets {
+		if budget.IsNegative() {
+			return nil, fmt.Errorf("error parsing budgets file: budget of %q is negative", category)
+		}
+	}
+
+	return config.Budgets, nil
+}
+
+// LoadExportProfiles loads the named CSV export profiles from the configured
+// YAML file. If the file is not found, returns an empty slice without error.
+//
+// Returns:
+//   - []models.ExportProfile: Slice of export profiles loaded from the file
+//   - error: Any error encountered during file reading or YAML parsing
+func (s *CategoryStore) LoadExportProfiles() ([]models.ExportProfile, error) {
+	filename := s.ProfilesFile
+	if filename == "" {
+		filename = "profiles.yaml"
+	}
+
+	filePath, err := s.resolveConfigFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []models.ExportProfile{}, nil
+		}
+		return nil, fmt.Errorf("error resolving profiles file: %w", err)
+	}
+
+	data, err := os.ReadFile(filePath) // #nosec G304 -- config file path resolved internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []models.ExportProfile{}, nil
+		}
+		return nil, fmt.Errorf("error reading profiles file: %w", err)
+	}
+
+	var config models.ExportProfilesConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing profiles file: %w", err)
+	}
+
+	return config.Profiles, nil
+}
+
+// LoadBankPresets loads the bank presets selected with --preset from the
+// configured YAML file (default: presets.yaml). A missing file yields no
+// presets.
+func (s *CategoryStore) LoadBankPresets() ([]models.BankPreset, error) {
+	filename := s.PresetsFile
+	if filename == "" {
+		filename = "presets.yaml"
+	}
+
+	filePath, err := s.resolveConfigFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []models.BankPreset{}, nil
+		}
+		return nil, fmt.Errorf("error resolving presets file: %w", err)
+	}
+
+	data, err := os.ReadFile(filePath) // #nosec G304 -- config file path resolved internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []models.BankPreset{}, nil
+		}
+		return nil, fmt.Errorf("error reading presets file: %w", err)
+	}
+
+	var config models.BankPresetsConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing presets file: %w", err)
+	}
+
+	return config.Presets, nil
+}
+
 // SaveCreditorMappings saves creditor-to-category mappings to the configured YAML file.
 // If the file doesn't exist, it creates it in the database directory. The method ensures
 // the parent directory exists before writing and uses appropriate file permissions.
@@ -338,6 +629,12 @@ func (s *CategoryStore) LoadDebtorMappings() (map[string]string, error) {
 // successfully categorizes a transaction, allowing future transactions from the same
 // creditor to be categorized instantly.
 //
+// mappings is merged onto the file as it is on disk, so entries other
+// processes saved in the meantime are kept, those of mappings winning. The
+// merge runs under a lock on a sidecar ".lock" file, and the result replaces
+// the file atomically. When another process keeps the lock, the save gives up
+// after a short wait with ErrFileLocked, writing nothing.
+//
 // Parameters:
 //   - mappings: Map of creditor names to category names to save
 //
@@ -371,22 +668,22 @@ func (s *CategoryStore) SaveCreditorMappings(mappings map[string]string) error {
 		return fmt.Errorf("error creating directory: %w", err)
 	}
 
-	// Create backup before modifying the file (critical - prevents data loss)
-	if err := s.createBackup(filePath); err != nil {
-		return fmt.Errorf("failed to backup before save: %w", err)
-	}
-
-	data, err := yaml.Marshal(mappings)
-	if err != nil {
-		return fmt.Errorf("error marshaling creditor mappings: %w", err)
-	}
-
-	// SECURITY: Creditor mappings are non-secret (just category mappings), use 0644 permissions
-	if err := os.WriteFile(filePath, data, models.PermissionNonSecretFile); err != nil {
-		return fmt.Errorf("error writing creditor mappings: %w", err)
-	}
-
-	return nil
+	// Processes saving at the same time take turns, and entries saved by
+	// another one since the file was loaded are kept (SECURITY: mappings are
+	// non-secret, the file is created with 0644 permissions)
+	return s.updateLocked(filePath, func(current []byte) ([]byte, error) {
+		// Entries with per-direction categories are not part of mappings; keep
+		// them. An unreadable file is replaced, its backup keeping it.
+		plain, directional, err := parseMappings(current)
+		if err != nil {
+			plain, directional = nil, nil
+		}
+		data, err := marshalMappings(mergeMappings(plain, mappings, lowerName), directional, creditorMappingFile)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling creditor mappings: %w", err)
+		}
+		return data, nil
+	})
 }
 
 // SaveDebtorMappings saves debtor-to-category mappings to the configured YAML file.
@@ -397,6 +694,12 @@ func (s *CategoryStore) SaveCreditorMappings(mappings map[string]string) error {
 // successfully categorizes a transaction, allowing future transactions from the same
 // debtor to be categorized instantly.
 //
+// mappings is merged onto the file as it is on disk, so entries other
+// processes saved in the meantime are kept, those of mappings winning. The
+// merge runs under a lock on a sidecar ".lock" file, and the result replaces
+// the file atomically. When another process keeps the lock, the save gives up
+// after a short wait with ErrFileLocked, writing nothing.
+//
 // Parameters:
 //   - mappings: Map of debtor names to category names to save
 //
@@ -430,20 +733,75 @@ func (s *CategoryStore) SaveDebtorMappings(mappings map[string]string) error {
 		return fmt.Errorf("error creating directory: %w", err)
 	}
 
-	// Create backup before modifying the file (critical - prevents data loss)
-	if err := s.createBackup(filePath); err != nil {
-		return fmt.Errorf("failed to backup before save: %w", err)
+	// Processes saving at the same time take turns, and entries saved by
+	// another one since the file was loaded are kept (SECURITY: mappings are
+	// non-secret, the file is created with 0644 permissions)
+	return s.updateLocked(filePath, func(current []byte) ([]byte, error) {
+		// Entries with per-direction categories are not part of mappings; keep
+		// them. An unreadable file is replaced, its backup keeping it.
+		plain, directional, err := parseMappings(current)
+		if err != nil {
+			plain, directional = nil, nil
+		}
+		data, err := marshalMappings(mergeMappings(plain, mappings, lowerName), directional, debtorMappingFile)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling debtor mappings: %w", err)
+		}
+		return data, nil
+	})
+}
+
+// SaveIBANMappings saves counterparty-IBAN-to-category mappings to the
+// configured YAML file, with IBANs normalized. If the file doesn't exist, it
+// creates it in the database directory; like SaveCreditorMappings, it locks
+// the file and keeps entries saved by other processes.
+//
+// Parameters:
+//   - mappings: Map of IBANs to category names to save
+//
+// Returns:
+//   - error: Any error encountered during file writing or directory creation
+func (s *CategoryStore) SaveIBANMappings(mappings map[string]string) error {
+	filename := s.IBANFile
+	if filename == "" {
+		filename = "iban_mappings.yaml"
+	}
+
+	// Find the existing file or use standard locations
+	filePath, err := s.FindConfigFile(filename)
+	if err != nil && err != os.ErrNotExist {
+		return fmt.Errorf("error resolving IBAN mappings file: %w", err)
 	}
 
-	data, err := yaml.Marshal(mappings)
-	if err != nil {
-		return fmt.Errorf("error marshaling debtor mappings: %w", err)
+	// If file not found, use the database directory by default
+	if err == os.ErrNotExist {
+		if !filepath.IsAbs(filename) {
+			filePath = filepath.Join("database", filename)
+		} else {
+			filePath = filename
+		}
 	}
 
-	// SECURITY: Debtor mappings are non-secret (just category mappings), use 0644 permissions
-	if err := os.WriteFile(filePath, data, models.PermissionNonSecretFile); err != nil {
-		return fmt.Errorf("error writing debtor mappings: %w", err)
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, models.PermissionDirectory); err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
 	}
 
-	return nil
+	// Processes saving at the same time take turns, and entries saved by
+	// another one since the file was loaded are kept
+	return s.updateLocked(filePath, func(current []byte) ([]byte, error) {
+		var existing map[string]string
+		if err := yaml.Unmarshal(current, &existing); err != nil {
+			existing = nil // An unreadable file is replaced, its backup keeping it
+		}
+		normalized := make(map[string]string, len(mappings))
+		for iban, category := range mergeMappings(existing, mappings, models.NormalizeIBAN) {
+			normalized[models.NormalizeIBAN(iban)] = category
+		}
+		data, err := yaml.Marshal(normalized)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling IBAN mappings: %w", err)
+		}
+		return data, nil
+	})
 }
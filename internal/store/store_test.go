@@ -5,9 +5,11 @@ import (
 	"path/filepath"
 	"testing"
 
+	"fjacquet/camt-csv/internal/formatter"
 	"fjacquet/camt-csv/internal/models"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 )
 
@@ -687,3 +689,396 @@ func TestCategoryStore_MultipleBackupsWithTimestamps(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "3", currentMappings["Version"], "Current file should have latest version")
 }
+
+func TestLoadMCCMappings(t *testing.T) {
+	tempDir := t.TempDir()
+	mccFile := filepath.Join(tempDir, "mcc.yaml")
+	writeFile(t, mccFile, `mcc:
+  "5411": Alimentation
+  5812: Restaurants
+`)
+
+	store := NewCategoryStore("", "", "")
+	store.MCCFile = mccFile
+
+	mappings, err := store.LoadMCCMappings()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"5411": "Alimentation", "5812": "Restaurants"}, mappings)
+
+	// Missing file yields no mappings
+	store.MCCFile = filepath.Join(tempDir, "missing.yaml")
+	mappings, err = store.LoadMCCMappings()
+	assert.NoError(t, err)
+	assert.Empty(t, mappings)
+
+	// Malformed file is an error
+	writeFile(t, mccFile, "mcc: [unclosed")
+	store.MCCFile = mccFile
+	_, err = store.LoadMCCMappings()
+	assert.Error(t, err)
+}
+
+func TestLoadIBANMappings(t *testing.T) {
+	tempDir := t.TempDir()
+	ibanFile := filepath.Join(tempDir, "iban_mappings.yaml")
+	writeFile(t, ibanFile, `CH93 0076 2011 6238 5295 7: Logement
+ch5604835012345678009: Épargne
+`)
+
+	store := NewCategoryStore("", "", "")
+	store.IBANFile = ibanFile
+
+	mappings, err := store.LoadIBANMappings()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"CH9300762011623852957": "Logement",
+		"CH5604835012345678009": "Épargne",
+	}, mappings)
+
+	// Missing file yields no mappings
+	store.IBANFile = filepath.Join(tempDir, "missing.yaml")
+	mappings, err = store.LoadIBANMappings()
+	assert.NoError(t, err)
+	assert.Empty(t, mappings)
+
+	// Malformed file is an error
+	writeFile(t, ibanFile, "[unclosed")
+	store.IBANFile = ibanFile
+	_, err = store.LoadIBANMappings()
+	assert.Error(t, err)
+}
+
+func TestSaveIBANMappings(t *testing.T) {
+	tempDir := t.TempDir()
+	ibanFile := filepath.Join(tempDir, "iban_mappings.yaml")
+
+	store := NewCategoryStore("", "", "")
+	store.IBANFile = ibanFile
+
+	err := store.SaveIBANMappings(map[string]string{"CH93 0076 2011 6238 5295 7": "Logement"})
+	assert.NoError(t, err)
+
+	mappings, err := store.LoadIBANMappings()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"CH9300762011623852957": "Logement"}, mappings)
+
+	data, err := os.ReadFile(ibanFile)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "CH9300762011623852957: Logement")
+}
+
+func TestLoadTagRules(t *testing.T) {
+	tempDir := t.TempDir()
+	tagsFile := filepath.Join(tempDir, "tags.yaml")
+	writeFile(t, tagsFile, `tags:
+  - name: business
+    keywords: ["AWS", "GitHub"]
+  - name: reimbursable
+    keywords: ["Hotel"]
+`)
+
+	store := NewCategoryStore("", "", "")
+	store.TagsFile = tagsFile
+
+	rules, err := store.LoadTagRules()
+	assert.NoError(t, err)
+	assert.Equal(t, []models.TagRule{
+		{Name: "business", Keywords: []string{"AWS", "GitHub"}},
+		{Name: "reimbursable", Keywords: []string{"Hotel"}},
+	}, rules)
+
+	// Missing file yields no rules
+	store.TagsFile = filepath.Join(tempDir, "missing.yaml")
+	rules, err = store.LoadTagRules()
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+
+	// Malformed file is an error
+	writeFile(t, tagsFile, "tags: [unclosed")
+	store.TagsFile = tagsFile
+	_, err = store.LoadTagRules()
+	assert.Error(t, err)
+}
+
+func TestLoadNameCleanupRules(t *testing.T) {
+	tempDir := t.TempDir()
+	cleanupFile := filepath.Join(tempDir, "cleanup.yaml")
+	writeFile(t, cleanupFile, `rules:
+  - action: strip_prefix
+    values: ["PMT CARTE", "PMT TWINT"]
+  - action: replace
+    pattern: 'X{4,}\d*'
+    replacement: ""
+  - action: strip_suffix
+    values: [" CH"]
+`)
+
+	store := NewCategoryStore("", "", "")
+	store.CleanupFile = cleanupFile
+
+	rules, err := store.LoadNameCleanupRules()
+	assert.NoError(t, err)
+	assert.Equal(t, []models.NameCleanupRule{
+		{Action: models.CleanupStripPrefix, Values: []string{"PMT CARTE", "PMT TWINT"}},
+		{Action: models.CleanupReplace, Pattern: `X{4,}\d*`},
+		{Action: models.CleanupStripSuffix, Values: []string{" CH"}},
+	}, rules)
+
+	// A file without rules disables the cleanup
+	writeFile(t, cleanupFile, "rules: []\n")
+	rules, err = store.LoadNameCleanupRules()
+	assert.NoError(t, err)
+	assert.NotNil(t, rules)
+	assert.Empty(t, rules)
+
+	// Missing file yields nil, so the defaults apply
+	store.CleanupFile = filepath.Join(tempDir, "missing.yaml")
+	rules, err = store.LoadNameCleanupRules()
+	assert.NoError(t, err)
+	assert.Nil(t, rules)
+
+	// Malformed file is an error
+	writeFile(t, cleanupFile, "rules: [unclosed")
+	store.CleanupFile = cleanupFile
+	_, err = store.LoadNameCleanupRules()
+	assert.Error(t, err)
+}
+
+func TestLoadInternalParties(t *testing.T) {
+	tempDir := t.TempDir()
+	categoriesFile := filepath.Join(tempDir, "categories.yaml")
+	writeFile(t, categoriesFile, `categories:
+  - name: Virements
+    keywords: ["virement"]
+internal_parties:
+  - Jane Doe
+  - DOE JOHN
+internal_transfer_category: Virements
+exclude_internal_transfers_from_stats: true
+`)
+
+	store := NewCategoryStore(categoriesFile, "", "")
+	config, err := store.LoadInternalParties()
+	assert.NoError(t, err)
+	assert.Equal(t, models.InternalPartiesConfig{
+		Names:            []string{"Jane Doe", "DOE JOHN"},
+		Category:         "Virements",
+		ExcludeFromStats: true,
+	}, config)
+
+	// Legacy list layout has no internal parties
+	writeFile(t, categoriesFile, `- name: Virements
+  keywords: ["virement"]
+`)
+	config, err = store.LoadInternalParties()
+	assert.NoError(t, err)
+	assert.Empty(t, config.Names)
+
+	// Missing file yields no internal parties
+	store.CategoriesFile = filepath.Join(tempDir, "missing.yaml")
+	config, err = store.LoadInternalParties()
+	assert.NoError(t, err)
+	assert.Empty(t, config.Names)
+
+	// Malformed file is an error
+	writeFile(t, categoriesFile, "internal_parties: [unclosed")
+	store.CategoriesFile = categoriesFile
+	_, err = store.LoadInternalParties()
+	assert.Error(t, err)
+}
+
+func TestLoadExportProfiles(t *testing.T) {
+	tempDir := t.TempDir()
+	profilesFile := filepath.Join(tempDir, "profiles.yaml")
+	writeFile(t, profilesFile, `profiles:
+  - name: erp
+    signed_amount: true
+    delimiter: ";"
+    columns: [Date, Amount, Currency]
+`)
+
+	store := NewCategoryStore("", "", "")
+	store.ProfilesFile = profilesFile
+
+	profiles, err := store.LoadExportProfiles()
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ExportProfile{
+		{Name: "erp", Columns: []string{"Date", "Amount", "Currency"}, SignedAmount: true, Delimiter: ";"},
+	}, profiles)
+
+	// Missing file yields no profiles
+	store.ProfilesFile = filepath.Join(tempDir, "missing.yaml")
+	profiles, err = store.LoadExportProfiles()
+	assert.NoError(t, err)
+	assert.Empty(t, profiles)
+
+	// Malformed file is an error
+	writeFile(t, profilesFile, "profiles: [unclosed")
+	store.ProfilesFile = profilesFile
+	_, err = store.LoadExportProfiles()
+	assert.Error(t, err)
+}
+
+func TestLoadExportProfiles_ShippedProfilesAreValid(t *testing.T) {
+	store := NewCategoryStore("", "", "")
+	store.ProfilesFile, _ = filepath.Abs(filepath.Join("..", "..", "database", "profiles.yaml"))
+
+	profiles, err := store.LoadExportProfiles()
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(profiles))
+	for _, p := range profiles {
+		names = append(names, p.Name)
+		assert.NoError(t, formatter.ValidateProfile(p))
+	}
+	assert.Equal(t, []string{"default", "erp"}, names)
+}
+
+func TestLoadBankPresets(t *testing.T) {
+	tempDir := t.TempDir()
+	presetsFile := filepath.Join(tempDir, "presets.yaml")
+	writeFile(t, presetsFile, `presets:
+  - name: bcv
+    parser: camt
+    number_format: comma
+    cleanup:
+      - action: strip_prefix
+        values: ["BCV-NET"]
+`)
+
+	store := NewCategoryStore("", "", "")
+	store.PresetsFile = presetsFile
+
+	presets, err := store.LoadBankPresets()
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BankPreset{{
+		Name:         "bcv",
+		Parser:       "camt",
+		NumberFormat: "comma",
+		Cleanup:      []models.NameCleanupRule{{Action: models.CleanupStripPrefix, Values: []string{"BCV-NET"}}},
+	}}, presets)
+
+	// Missing file yields no presets
+	store.PresetsFile = filepath.Join(tempDir, "missing.yaml")
+	presets, err = store.LoadBankPresets()
+	assert.NoError(t, err)
+	assert.Empty(t, presets)
+
+	// Malformed file is an error
+	writeFile(t, presetsFile, "presets: [unclosed")
+	store.PresetsFile = presetsFile
+	_, err = store.LoadBankPresets()
+	assert.Error(t, err)
+}
+
+func TestLoadBankPresets_ShippedPresetsAreValid(t *testing.T) {
+	store := NewCategoryStore("", "", "")
+	store.PresetsFile, _ = filepath.Abs(filepath.Join("..", "..", "database", "presets.yaml"))
+
+	presets, err := store.LoadBankPresets()
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(presets))
+	for _, p := range presets {
+		names = append(names, p.Name)
+		assert.NoError(t, models.ValidateBankPreset(p))
+	}
+	assert.Equal(t, []string{"bcv", "viseca", "revolut", "selma", "wise"}, names)
+}
+
+func TestLoadDirectionalMappings(t *testing.T) {
+	tempDir := t.TempDir()
+	creditorsFile := filepath.Join(tempDir, "creditors.yaml")
+	debtorsFile := filepath.Join(tempDir, "debtors.yaml")
+	writeFile(t, creditorsFile, `Employer SA: Salaire
+Twint:
+  credit: Remboursements
+`)
+	writeFile(t, debtorsFile, `Coop: Alimentation
+Galaxus:
+  debit: Shopping
+  credit: Remboursements
+twint:
+  debit: Transferts
+`)
+
+	store := NewCategoryStore(filepath.Join(tempDir, "categories.yaml"), creditorsFile, debtorsFile)
+
+	// Plain loaders skip directional entries
+	creditors, err := store.LoadCreditorMappings()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Employer SA": "Salaire"}, creditors)
+	debtors, err := store.LoadDebtorMappings()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Coop": "Alimentation"}, debtors)
+
+	directional, err := store.LoadDirectionalMappings()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]models.DirectionalCategory{
+		"galaxus": {Debit: "Shopping", Credit: "Remboursements"},
+		"twint":   {Debit: "Transferts", Credit: "Remboursements"},
+	}, directional)
+
+	// Malformed directional entry is an error
+	writeFile(t, debtorsFile, "Galaxus:\n  debit: [Shopping]\n")
+	_, err = store.LoadDirectionalMappings()
+	assert.Error(t, err)
+}
+
+func TestSaveMappingsKeepsDirectionalEntries(t *testing.T) {
+	tempDir := t.TempDir()
+	debtorsFile := filepath.Join(tempDir, "debtors.yaml")
+	writeFile(t, debtorsFile, `Coop: Alimentation
+Galaxus:
+  credit: Remboursements
+`)
+
+	store := NewCategoryStore(filepath.Join(tempDir, "categories.yaml"), filepath.Join(tempDir, "creditors.yaml"), debtorsFile)
+	store.SetBackupConfig(false, "", "20060102_150405")
+
+	// A learned mapping for a directional party fills in the missing direction
+	err := store.SaveDebtorMappings(map[string]string{"coop": "Alimentation", "migros": "Alimentation", "galaxus": "Shopping"})
+	require.NoError(t, err)
+
+	debtors, err := store.LoadDebtorMappings()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"coop": "Alimentation", "migros": "Alimentation"}, debtors)
+
+	directional, err := store.LoadDirectionalMappings()
+	require.NoError(t, err)
+	assert.Equal(t, models.DirectionalCategory{Debit: "Shopping", Credit: "Remboursements"}, directional["galaxus"])
+}
+
+func TestLoadBudgets(t *testing.T) {
+	tempDir := t.TempDir()
+	budgetsFile := filepath.Join(tempDir, "budgets.yaml")
+	writeFile(t, budgetsFile, `budgets:
+  Courses: 600
+  Restaurants: "150.50"
+`)
+
+	store := NewCategoryStore("", "", "")
+	store.BudgetsFile = budgetsFile
+
+	budgets, err := store.LoadBudgets()
+	require.NoError(t, err)
+	require.Len(t, budgets, 2)
+	assert.Equal(t, "600", budgets["Courses"].String())
+	assert.Equal(t, "150.5", budgets["Restaurants"].String())
+
+	// Missing file yields no budgets
+	store.BudgetsFile = filepath.Join(tempDir, "missing.yaml")
+	budgets, err = store.LoadBudgets()
+	require.NoError(t, err)
+	assert.Empty(t, budgets)
+
+	// Negative budgets and malformed files are errors
+	store.BudgetsFile = budgetsFile
+	writeFile(t, budgetsFile, "budgets:\n  Courses: -10\n")
+	_, err = store.LoadBudgets()
+	assert.ErrorContains(t, err, "negative")
+
+	writeFile(t, budgetsFile, "budgets:\n  Courses: lots\n")
+	_, err = store.LoadBudgets()
+	assert.Error(t, err)
+}
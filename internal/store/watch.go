@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"fjacquet/camt-csv/internal/logging"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long Watch waits after the last change before calling
+// onChange, so that an editor saving a file in several steps causes one reload.
+var watchDebounce = 250 * time.Millisecond
+
+// watchedFiles returns the paths of the files the categorizer loads its rules
+// from (categories, creditor, debtor, IBAN, MCC, tag and cleanup files) that
+// exist.
+func (s *CategoryStore) watchedFiles() []string {
+	names := []string{
+		defaultName(s.CategoriesFile, "categories.yaml"),
+		defaultName(s.CreditorsFile, creditorMappingFile.defaultName),
+		defaultName(s.DebtorsFile, debtorMappingFile.defaultName),
+		defaultName(s.IBANFile, "iban_mappings.yaml"),
+		defaultName(s.MCCFile, "mcc.yaml"),
+		defaultName(s.TagsFile, "tags.yaml"),
+		defaultName(s.CleanupFile, "cleanup.yaml"),
+	}
+
+	var paths []string
+	for _, name := range names {
+		path, err := s.FindConfigFile(name)
+		if err != nil {
+			continue
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// Watch calls onChange when one of the categorizer's rule files changes,
+// until ctx is done. Files that do not exist when Watch is called are not
+// watched. The directories holding the files are watched rather than the files
+// themselves, so that files replaced by an editor (written to a temporary file,
+// then renamed) keep being watched.
+//
+// Parameters:
+//   - ctx: Watching stops when ctx is done
+//   - onChange: Called from the watching goroutine after a change
+//   - logger: Logger for watcher errors
+//
+// Returns:
+//   - error: Any error encountered while setting up the watcher
+func (s *CategoryStore) Watch(ctx context.Context, onChange func(), logger logging.Logger) error {
+	files := s.watchedFiles()
+	if len(files) == 0 {
+		logger.Warn("No categories files found, hot reload disabled")
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("error creating file watcher: %w", err)
+	}
+
+	watched := make(map[string]bool, len(files))
+	for _, file := range files {
+		watched[file] = true
+		dir := filepath.Dir(file)
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+			return fmt.Errorf("error watching %s: %w", dir, err)
+		}
+		logger.WithField("file", file).Info("Watching categories file")
+	}
+
+	go func() {
+		defer func() { _ = watcher.Close() }()
+
+		// A nil channel blocks, so no reload is pending until a change arrives
+		var pending <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if watched[filepath.Clean(event.Name)] && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+					pending = time.After(watchDebounce)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.WithError(err).Error("Categories file watcher error")
+			case <-pending:
+				pending = nil
+				onChange()
+			}
+		}
+	}()
+	return nil
+}
+
+// defaultName returns filename, or fallback when filename is empty.
+func defaultName(filename, fallback string) string {
+	if filename == "" {
+		return fallback
+	}
+	return filename
+}
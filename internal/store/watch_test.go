@@ -0,0 +1,65 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fjacquet/camt-csv/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatch(t *testing.T) {
+	watchDebounce = 10 * time.Millisecond
+	t.Cleanup(func() { watchDebounce = 250 * time.Millisecond })
+
+	dir := t.TempDir()
+	s := NewTestCategoryStore(dir)
+	writeFile(t, s.CategoriesFile, "categories: []\n")
+	writeFile(t, s.CreditorsFile, "{}\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan struct{}, 10)
+	require.NoError(t, s.Watch(ctx, func() { changes <- struct{}{} }, logging.NewMockLogger()))
+
+	waitForChange := func() bool {
+		select {
+		case <-changes:
+			return true
+		case <-time.After(2 * time.Second):
+			return false
+		}
+	}
+
+	// Other files in the directory are ignored
+	writeFile(t, filepath.Join(dir, "notes.txt"), "hello")
+	writeFile(t, s.CreditorsFile, "ACME SA: Salary\n")
+	assert.True(t, waitForChange())
+	assert.Empty(t, changes)
+
+	// A file replaced by renaming a temporary file over it is seen too
+	tmp := filepath.Join(dir, "categories.yaml.tmp")
+	writeFile(t, tmp, "categories:\n  - name: Food\n")
+	require.NoError(t, os.Rename(tmp, s.CategoriesFile))
+	assert.True(t, waitForChange())
+
+	// Nothing is reported once the context is done
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	writeFile(t, s.CreditorsFile, "ACME SA: Bonus\n")
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, changes)
+}
+
+func TestWatch_NoFiles(t *testing.T) {
+	s := NewTestCategoryStore(t.TempDir())
+	logger := logging.NewMockLogger()
+
+	require.NoError(t, s.Watch(context.Background(), func() {}, logger))
+	assert.True(t, logger.HasEntry("WARN", "No categories files found, hot reload disabled"))
+}
@@ -0,0 +1,296 @@
+// Package wiseparser parses Wise (formerly TransferWise) statement CSV exports.
+// Wise issues one statement per currency balance; rows carry a signed amount,
+// optional fee and conversion columns, and dates in DD-MM-YYYY format.
+package wiseparser
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"fjacquet/camt-csv/internal/common"
+	"fjacquet/camt-csv/internal/dateutils"
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parser"
+	"fjacquet/camt-csv/internal/parsererror"
+
+	"github.com/shopspring/decimal"
+)
+
+// Column names used by the Wise statement export.
+const (
+	colID               = "TransferWise ID"
+	colDate             = "Date"
+	colAmount           = "Amount"
+	colCurrency         = "Currency"
+	colDescription      = "Description"
+	colPaymentReference = "Payment Reference"
+	colExchangeFrom     = "Exchange From"
+	colExchangeTo       = "Exchange To"
+	colExchangeRate     = "Exchange Rate"
+	colExchangeToAmount = "Exchange To Amount"
+	colPayerName        = "Payer Name"
+	colPayeeName        = "Payee Name"
+	colMerchant         = "Merchant"
+	colTotalFees        = "Total fees"
+)
+
+// requiredColumns must be present in the header of a Wise statement.
+var requiredColumns = []string{colID, colDate, colAmount, colCurrency}
+
+// feeIDPrefix marks rows that record a standalone Wise fee.
+const feeIDPrefix = "FEE-"
+
+// feeParty is the counterparty used for standalone fee rows.
+const feeParty = "Wise"
+
+// columnIndex maps Wise column names to their position in the header.
+type columnIndex map[string]int
+
+// newColumnIndex indexes header and checks that the required columns are present.
+func newColumnIndex(header []string) (columnIndex, error) {
+	idx := make(columnIndex, len(header))
+	for i, h := range header {
+		// Wise exports may start with a UTF-8 byte order mark
+		idx[strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))] = i
+	}
+	for _, col := range requiredColumns {
+		if _, ok := idx[col]; !ok {
+			return nil, fmt.Errorf("missing required column %q", col)
+		}
+	}
+	return idx, nil
+}
+
+// get returns the trimmed value of column col in record, or "" if absent.
+func (c columnIndex) get(record []string, col string) string {
+	i, ok := c[col]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// wiseCSVRow represents one row in a Wise statement.
+type wiseCSVRow struct {
+	ID               string
+	Date             string
+	Amount           string
+	Currency         string
+	Description      string
+	PaymentReference string
+	ExchangeFrom     string
+	ExchangeTo       string
+	ExchangeRate     string
+	ExchangeToAmount string
+	PayerName        string
+	PayeeName        string
+	Merchant         string
+	TotalFees        string
+}
+
+// parseWiseDate parses a Wise date ("15-01-2025"), falling back to the
+// common layouts for exports that include a time of day.
+func parseWiseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(dateutils.DateLayoutDashed, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(dateutils.DateLayoutDashed+" 15:04:05", s); err == nil {
+		return t, nil
+	}
+	t, err := dateutils.ParseDateString(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if t.IsZero() {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+	return t, nil
+}
+
+// parseDecimal parses an optional decimal column, treating "" as zero.
+func parseDecimal(s string) (decimal.Decimal, error) {
+	if s == "" {
+		return decimal.Zero, nil
+	}
+	return decimal.NewFromString(s)
+}
+
+// ParseWithCategorizer parses a Wise statement CSV reader and returns transactions.
+func ParseWithCategorizer(ctx context.Context, r io.Reader, logger logging.Logger, categorizer models.TransactionCategorizer) ([]models.Transaction, error) {
+	if logger == nil {
+		logger = logging.NewLogrusAdapter("info", "text")
+	}
+	logger.Info("Parsing Wise statement CSV from reader")
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read CSV: %w", parsererror.ErrReadFailed, err)
+	}
+
+	if len(records) < 2 {
+		return nil, &parsererror.InvalidFormatError{
+			FilePath:       "(from reader)",
+			ExpectedFormat: "Wise statement CSV",
+			Msg:            "CSV file is empty or contains only headers",
+			Err:            parsererror.ErrNoTransactions,
+		}
+	}
+
+	cols, err := newColumnIndex(records[0])
+	if err != nil {
+		return nil, &parsererror.InvalidFormatError{
+			FilePath:       "(from reader)",
+			ExpectedFormat: "Wise statement CSV",
+			Msg:            err.Error(),
+		}
+	}
+
+	var transactions []models.Transaction
+
+	for i, record := range records[1:] {
+		row := wiseCSVRow{
+			ID:               cols.get(record, colID),
+			Date:             cols.get(record, colDate),
+			Amount:           cols.get(record, colAmount),
+			Currency:         cols.get(record, colCurrency),
+			Description:      cols.get(record, colDescription),
+			PaymentReference: cols.get(record, colPaymentReference),
+			ExchangeFrom:     cols.get(record, colExchangeFrom),
+			ExchangeTo:       cols.get(record, colExchangeTo),
+			ExchangeRate:     cols.get(record, colExchangeRate),
+			ExchangeToAmount: cols.get(record, colExchangeToAmount),
+			PayerName:        cols.get(record, colPayerName),
+			PayeeName:        cols.get(record, colPayeeName),
+			Merchant:         cols.get(record, colMerchant),
+			TotalFees:        cols.get(record, colTotalFees),
+		}
+
+		tx, err := convertRowToTransaction(row)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to convert row to transaction",
+				logging.Field{Key: "row", Value: i + 2})
+			continue
+		}
+
+		if err := parser.CheckTransactionLimit(ctx, len(transactions)+1); err != nil {
+			return nil, err
+		}
+
+		transactions = append(transactions, tx)
+	}
+
+	// Categorize the transactions using the injected categorizer
+	transactions = common.ProcessTransactionsWithCategorizationStats(ctx, transactions, logger, categorizer, "Wise")
+
+	logger.Info("Successfully parsed transactions from Wise statement CSV",
+		logging.Field{Key: "count", Value: len(transactions)})
+	return transactions, nil
+}
+
+// convertRowToTransaction converts a wiseCSVRow to a models.Transaction.
+func convertRowToTransaction(row wiseCSVRow) (models.Transaction, error) {
+	date, err := parseWiseDate(row.Date)
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("invalid date %q: %w", row.Date, err)
+	}
+
+	amount, err := decimal.NewFromString(row.Amount)
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("invalid amount %q: %w", row.Amount, err)
+	}
+
+	fees, err := parseDecimal(row.TotalFees)
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("invalid fees %q: %w", row.TotalFees, err)
+	}
+
+	isFee := strings.HasPrefix(strings.ToUpper(row.ID), feeIDPrefix)
+	partyName := counterparty(row, amount.IsNegative(), isFee)
+
+	description := row.Description
+	if description == "" {
+		description = partyName
+	}
+
+	builder := models.NewTransactionBuilder().
+		WithDatetime(date).
+		WithValueDatetime(date).
+		WithAmount(amount.Abs(), row.Currency).
+		WithDescription(description).
+		WithPartyName(partyName).
+		WithEntryReference(row.ID).
+		WithReference(row.PaymentReference).
+		WithFees(fees.Abs())
+
+	if isFee {
+		builder = builder.WithType("Fee")
+	}
+
+	if origAmount, origCurrency, rate, ok := conversion(row, amount); ok {
+		builder = builder.
+			WithOriginalAmount(origAmount, origCurrency).
+			WithExchangeRate(rate)
+	}
+
+	if amount.IsNegative() {
+		builder = builder.WithPayee(partyName, "").AsDebit()
+	} else {
+		builder = builder.WithPayer(partyName, "").AsCredit()
+	}
+
+	tx, err := builder.Build()
+	if err != nil {
+		return models.Transaction{}, fmt.Errorf("error building transaction: %w", err)
+	}
+	return tx, nil
+}
+
+// counterparty picks the most specific party name available for a row.
+func counterparty(row wiseCSVRow, isDebit, isFee bool) string {
+	if isFee {
+		return feeParty
+	}
+	candidates := []string{row.Merchant, row.PayerName, row.PayeeName}
+	if isDebit {
+		candidates = []string{row.Merchant, row.PayeeName, row.PayerName}
+	}
+	for _, c := range candidates {
+		if c != "" {
+			return c
+		}
+	}
+	return row.Description
+}
+
+// conversion returns the other side of a currency conversion recorded on the
+// row, as seen from the row's own currency balance. Wise states the rate as
+// Exchange From → Exchange To.
+func conversion(row wiseCSVRow, amount decimal.Decimal) (decimal.Decimal, string, decimal.Decimal, bool) {
+	if row.ExchangeFrom == "" || row.ExchangeTo == "" || row.ExchangeFrom == row.ExchangeTo {
+		return decimal.Zero, "", decimal.Zero, false
+	}
+	rate, err := decimal.NewFromString(row.ExchangeRate)
+	if err != nil || rate.IsZero() {
+		return decimal.Zero, "", decimal.Zero, false
+	}
+
+	switch row.Currency {
+	case row.ExchangeFrom:
+		toAmount, err := parseDecimal(row.ExchangeToAmount)
+		if err != nil || toAmount.IsZero() {
+			toAmount = amount.Abs().Mul(rate).Round(2)
+		}
+		return toAmount.Abs(), row.ExchangeTo, rate, true
+	case row.ExchangeTo:
+		return amount.Abs().Div(rate).Round(2), row.ExchangeFrom, rate, true
+	default:
+		return decimal.Zero, "", decimal.Zero, false
+	}
+}
@@ -0,0 +1,219 @@
+package wiseparser
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parsererror"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const fixture = "testdata/wise_statement.csv"
+
+type mockCategorizer struct {
+	mock.Mock
+}
+
+func (m *mockCategorizer) Categorize(ctx context.Context, partyName string, isDebtor bool, amount, date, description string) (models.Category, error) {
+	args := m.Called(ctx, partyName, isDebtor, amount, date, description)
+	return args.Get(0).(models.Category), args.Error(1)
+}
+
+func newTestLogger() logging.Logger {
+	return logging.NewLogrusAdapter("info", "text")
+}
+
+func parseFixture(t *testing.T) []models.Transaction {
+	t.Helper()
+	f, err := os.Open(fixture)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	txs, err := NewAdapter(newTestLogger()).Parse(context.Background(), f)
+	require.NoError(t, err)
+	return txs
+}
+
+func TestParseWiseDate(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"15-01-2025", time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), false},
+		{"31-12-2024 18:30:05", time.Date(2024, 12, 31, 18, 30, 5, 0, time.UTC), false},
+		{"2025-01-15", time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), false},
+		{"", time.Time{}, true},
+		{"not a date", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseWiseDate(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "got %v", got)
+		})
+	}
+}
+
+func TestParse_Fixture(t *testing.T) {
+	txs := parseFixture(t)
+
+	// The zero-amount card verification row is skipped
+	require.Len(t, txs, 5)
+
+	salary := txs[0]
+	assert.Equal(t, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), salary.Date)
+	assert.Equal(t, "ACME SA", salary.PartyName)
+	assert.Equal(t, models.TransactionTypeCredit, salary.CreditDebit)
+	assert.True(t, decimal.NewFromInt(2500).Equal(salary.Amount))
+	assert.Equal(t, "TRANSFER-1001", salary.EntryReference)
+	assert.Equal(t, "Salary", salary.Reference)
+
+	card := txs[1]
+	assert.Equal(t, "Migros Lausanne", card.PartyName)
+	assert.Equal(t, models.TransactionTypeDebit, card.CreditDebit)
+	assert.True(t, decimal.RequireFromString("-42.80").Equal(card.Amount))
+
+	conversion := txs[2]
+	assert.Equal(t, "CHF", conversion.Currency)
+	assert.Equal(t, "EUR", conversion.OriginalCurrency)
+	assert.True(t, decimal.RequireFromString("530.50").Equal(conversion.OriginalAmount))
+	assert.True(t, decimal.RequireFromString("1.061").Equal(conversion.ExchangeRate))
+	assert.True(t, decimal.RequireFromString("1.38").Equal(conversion.Fees))
+
+	fee := txs[3]
+	assert.Equal(t, "Wise", fee.PartyName)
+	assert.Equal(t, "Fee", fee.Type)
+	assert.True(t, decimal.RequireFromString("-1.50").Equal(fee.Amount))
+
+	transfer := txs[4]
+	assert.Equal(t, "John Smith", transfer.PartyName)
+	assert.Equal(t, "Rent", transfer.Reference)
+
+	for _, tx := range txs {
+		assert.Equal(t, models.CategoryUncategorized, tx.Category)
+		assert.Equal(t, models.CategorySourceFallback, tx.CategorySource)
+	}
+}
+
+func TestParse_MultiCurrencyTargetSide(t *testing.T) {
+	input := `"TransferWise ID",Date,Amount,Currency,Description,"Exchange From","Exchange To","Exchange Rate"
+BALANCE-3001,10-01-2025,530.50,EUR,"Converted 500.00 CHF to 530.50 EUR",CHF,EUR,1.061
+`
+	txs, err := ParseWithCategorizer(context.Background(), strings.NewReader(input), newTestLogger(), nil)
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+
+	assert.Equal(t, "EUR", txs[0].Currency)
+	assert.Equal(t, models.TransactionTypeCredit, txs[0].CreditDebit)
+	assert.Equal(t, "CHF", txs[0].OriginalCurrency)
+	assert.True(t, decimal.RequireFromString("500").Equal(txs[0].OriginalAmount))
+}
+
+func TestParse_Categorizer(t *testing.T) {
+	input := `"TransferWise ID",Date,Amount,Currency,Description,Merchant
+CARD-2001,05-01-2025,-42.80,CHF,"Card transaction",Migros Lausanne
+`
+	cat := &mockCategorizer{}
+	cat.On("Categorize", mock.Anything, "Migros Lausanne", true, "-42.8", "2025-01-05", "Card transaction").
+		Return(models.Category{Name: "Groceries", Source: models.CategorySourceKeyword}, nil)
+
+	txs, err := ParseWithCategorizer(context.Background(), strings.NewReader(input), newTestLogger(), cat)
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	assert.Equal(t, "Groceries", txs[0].Category)
+	assert.Equal(t, models.CategorySourceKeyword, txs[0].CategorySource)
+	cat.AssertExpectations(t)
+}
+
+func TestParse_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"header only", "\"TransferWise ID\",Date,Amount,Currency\n"},
+		{"missing column", "ID,Date,Amount,Currency\nX,05-01-2025,1,CHF\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWithCategorizer(context.Background(), strings.NewReader(tt.input), newTestLogger(), nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	a := NewAdapter(newTestLogger())
+
+	valid, err := a.ValidateFormat(fixture)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	other := filepath.Join(t.TempDir(), "other.csv")
+	require.NoError(t, os.WriteFile(other, []byte("Symbol,Type,Date\n"), 0600))
+	valid, err = a.ValidateFormat(other)
+	require.NoError(t, err)
+	assert.False(t, valid)
+}
+
+func TestConvertToCSV_RoundTrip(t *testing.T) {
+	want := parseFixture(t)
+	output := filepath.Join(t.TempDir(), "wise.csv")
+
+	require.NoError(t, NewAdapter(newTestLogger()).ConvertToCSV(context.Background(), fixture, output))
+
+	f, err := os.Open(output)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	records, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, len(want)+1)
+
+	col := make(map[string]int, len(records[0]))
+	for i, h := range records[0] {
+		col[h] = i
+	}
+	for i, tx := range want {
+		row := records[i+1]
+		assert.Equal(t, tx.Date.Format("02.01.2006"), row[col["Date"]])
+		assert.Equal(t, tx.PartyName, row[col["PartyName"]])
+		assert.Equal(t, tx.Currency, row[col["Currency"]])
+		assert.Equal(t, tx.EntryReference, row[col["EntryReference"]])
+
+		amount, err := decimal.NewFromString(row[col["Amount"]])
+		require.NoError(t, err)
+		assert.True(t, tx.Amount.Abs().Equal(amount.Abs()), "row %d amount %s", i, amount)
+	}
+}
+
+func TestParse_HeaderOnlyIsNoTransactions(t *testing.T) {
+	header := "ID,Status,Direction,Created on,Finished on,Source fee amount,Source fee currency,Target fee amount,Target fee currency,Source name,Source amount (after fees),Source currency,Target name,Target amount (after fees),Target currency,Exchange rate,Reference,Batch\n"
+
+	_, err := NewAdapter(newTestLogger()).Parse(context.Background(), strings.NewReader(header))
+	assert.ErrorIs(t, err, parsererror.ErrNoTransactions)
+	assert.ErrorIs(t, err, parsererror.ErrInvalidFormat)
+}
+
+func TestValidateFormat_MissingFileIsReadFailure(t *testing.T) {
+	_, err := NewAdapter(newTestLogger()).ValidateFormat(filepath.Join(t.TempDir(), "missing.csv"))
+	assert.ErrorIs(t, err, parsererror.ErrReadFailed)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
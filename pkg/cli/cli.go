@@ -0,0 +1,212 @@
+// Package cli runs the camt-csv command line. The camt-csv binary is a thin
+// main around Execute; a program that adds its own parsers is another one:
+//
+//	package main
+//
+//	import (
+//		"os"
+//
+//		"fjacquet/camt-csv/pkg/cli"
+//		"fjacquet/camt-csv/pkg/parser"
+//
+//		"example.com/mybank"
+//	)
+//
+//	func init() {
+//		parser.RegisterParser("mybank", func(logger parser.Logger) parser.FullParser {
+//			return mybank.NewAdapter(logger)
+//		})
+//	}
+//
+//	func main() {
+//		if err := cli.Execute("mybank-build"); err != nil {
+//			os.Exit(1)
+//		}
+//	}
+//
+// Every registered parser, built-in or not, gets a convert command named
+// after it.
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fjacquet/camt-csv/cmd/auto"
+	"fjacquet/camt-csv/cmd/camt"
+	"fjacquet/camt-csv/cmd/categories"
+	"fjacquet/camt-csv/cmd/categorize"
+	"fjacquet/camt-csv/cmd/common"
+	"fjacquet/camt-csv/cmd/debit"
+	"fjacquet/camt-csv/cmd/diff"
+	"fjacquet/camt-csv/cmd/doctor"
+	"fjacquet/camt-csv/cmd/mt940"
+	"fjacquet/camt-csv/cmd/pdf"
+	"fjacquet/camt-csv/cmd/reprocess"
+	"fjacquet/camt-csv/cmd/revolut"
+	revolutcrypto "fjacquet/camt-csv/cmd/revolut-crypto"
+	revolutinvestment "fjacquet/camt-csv/cmd/revolut-investment"
+	"fjacquet/camt-csv/cmd/root"
+	"fjacquet/camt-csv/cmd/schema"
+	"fjacquet/camt-csv/cmd/selma"
+	"fjacquet/camt-csv/cmd/serve"
+	"fjacquet/camt-csv/cmd/stats"
+	"fjacquet/camt-csv/cmd/wise"
+	"fjacquet/camt-csv/pkg/parser"
+	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// Execute sets up the command line and runs it with the process arguments.
+// version is what --version prints. The commands are built here rather than
+// in an init function, so that the parsers registered by the caller's init
+// functions have their command too. An error has already been printed.
+//
+// Execute must be called once per process.
+func Execute(version string) error {
+	// 1. Load environment variables silently first (no logging yet)
+	loadEnvSilently(envFileFromArgs(os.Args[1:]))
+
+	// 2. Configure global log level directly - this affects ALL new loggers
+	configureLogLevelDirectly()
+
+	// 3. Logging level is now handled by the configuration system
+
+	// 4. Now that logging is properly configured, initialize root command
+	root.Init()
+
+	// 5. Set the version shown by --version
+	root.Cmd.Version = version
+
+	// 6. Add all subcommands
+	root.Cmd.AddCommand(categorize.Cmd)
+	root.Cmd.AddCommand(categories.Cmd)
+	root.Cmd.AddCommand(serve.Cmd)
+	root.Cmd.AddCommand(doctor.Cmd)
+	root.Cmd.AddCommand(auto.Cmd)
+	root.Cmd.AddCommand(stats.Cmd)
+	root.Cmd.AddCommand(diff.Cmd)
+	root.Cmd.AddCommand(schema.Cmd)
+	addParserCommands()
+
+	if err := root.Cmd.Execute(); err != nil {
+		fmt.Println(err)
+		return err
+	}
+	return nil
+}
+
+// dedicatedCommands maps registered parser names to their hand-written commands.
+// Registered parsers without an entry get a generic convert command.
+var dedicatedCommands = map[string]*cobra.Command{
+	"camt":               camt.Cmd,
+	"pdf":                pdf.Cmd,
+	"selma":              selma.Cmd,
+	"revolut":            revolut.Cmd,
+	"revolut-crypto":     revolutcrypto.Cmd,
+	"debit":              debit.Cmd,
+	"revolut-investment": revolutinvestment.Cmd,
+	"wise":               wise.Cmd,
+	"mt940":              mt940.Cmd,
+	"reprocess":          reprocess.Cmd,
+}
+
+// addParserCommands adds one subcommand per parser in the parser registry
+func addParserCommands() {
+	for _, name := range parser.RegisteredParsers() {
+		if cmd, ok := dedicatedCommands[name]; ok {
+			root.Cmd.AddCommand(cmd)
+			continue
+		}
+		root.Cmd.AddCommand(common.NewConvertCommand(name))
+	}
+}
+
+// localEnvSuffix names the override file loaded alongside an env file:
+// ".env" is overridden by ".env.local".
+const localEnvSuffix = ".local"
+
+// loadEnvSilently loads environment variables without logging anything.
+// envFile is the file given with --env-file; when empty, .env is looked up in
+// the current directory, then its parent. The file's ".local" sibling (e.g.
+// .env.local) is loaded too and wins over it. Variables already set in the
+// environment win over both.
+func loadEnvSilently(envFile string) {
+	if envFile == "" {
+		// Try to find .env file in current directory
+		envFile = ".env"
+		if !fileExists(envFile) && !fileExists(envFile+localEnvSuffix) {
+			// Try to find .env in parent directory (project root)
+			envFile = filepath.Join("..", ".env")
+		}
+	} else if !fileExists(envFile) {
+		// Logging is not configured yet; an explicit file that is missing is
+		// still worth a word on stderr
+		fmt.Fprintf(os.Stderr, "Warning: env file not found: %s\n", envFile)
+	}
+
+	// godotenv never overrides a variable that is already set, so the local
+	// file is loaded first
+	var files []string
+	for _, f := range []string{envFile + localEnvSuffix, envFile} {
+		if fileExists(f) {
+			files = append(files, f)
+		}
+	}
+	if len(files) == 0 {
+		return
+	}
+
+	// Load .env files silently without logging
+	_ = godotenv.Load(files...)
+}
+
+// envFileFromArgs returns the value of --env-file in args. It runs before
+// cobra parses the command line, because the environment must be loaded
+// before logging and configuration are set up.
+func envFileFromArgs(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, "--env-file="); ok {
+			return value
+		}
+		if arg == "--env-file" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// fileExists reports whether path exists.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// configureLogLevelDirectly sets the global log level for all logrus instances
+// and returns the configured level
+func configureLogLevelDirectly() logrus.Level {
+	// Get log level from environment variable
+	logLevelStr := os.Getenv("LOG_LEVEL")
+	if logLevelStr == "" {
+		logLevelStr = "info" // Default log level
+	}
+
+	// Parse the log level
+	logLevel, err := logrus.ParseLevel(strings.ToLower(logLevelStr))
+	if err != nil {
+		// Don't log here, just use default info level if we can't parse
+		logLevel = logrus.InfoLevel
+	}
+
+	// This is critical: set the global logrus level BEFORE any logging happens
+	// This affects ALL existing and future loggers
+	logrus.SetLevel(logLevel)
+
+	return logLevel
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvFileFromArgs(t *testing.T) {
+	assert.Equal(t, "", envFileFromArgs([]string{"camt", "-i", "in.xml"}))
+	assert.Equal(t, "prod.env", envFileFromArgs([]string{"camt", "--env-file", "prod.env", "-i", "in.xml"}))
+	assert.Equal(t, "prod.env", envFileFromArgs([]string{"--env-file=prod.env", "camt"}))
+	assert.Equal(t, "", envFileFromArgs([]string{"camt", "--env-file"}))
+	assert.Equal(t, "", envFileFromArgs([]string{"camt", "--", "--env-file", "prod.env"}))
+}
+
+// unsetEnv removes keys now and again after the test, since godotenv never
+// overrides variables that are already set.
+func unsetEnv(t *testing.T, keys ...string) {
+	t.Helper()
+	for _, key := range keys {
+		require.NoError(t, os.Unsetenv(key))
+	}
+	t.Cleanup(func() {
+		for _, key := range keys {
+			_ = os.Unsetenv(key)
+		}
+	})
+}
+
+func TestLoadEnvSilently_LocalOverridesEnvFile(t *testing.T) {
+	unsetEnv(t, "CAMT_TEST_SHARED", "CAMT_TEST_BASE_ONLY", "CAMT_TEST_PRESET")
+	t.Setenv("CAMT_TEST_PRESET", "process")
+
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, "prod.env")
+	require.NoError(t, os.WriteFile(envFile, []byte("CAMT_TEST_SHARED=base\nCAMT_TEST_BASE_ONLY=base\nCAMT_TEST_PRESET=base\n"), 0600))
+	require.NoError(t, os.WriteFile(envFile+".local", []byte("CAMT_TEST_SHARED=local\n"), 0600))
+
+	loadEnvSilently(envFile)
+
+	assert.Equal(t, "local", os.Getenv("CAMT_TEST_SHARED"))
+	assert.Equal(t, "base", os.Getenv("CAMT_TEST_BASE_ONLY"))
+	assert.Equal(t, "process", os.Getenv("CAMT_TEST_PRESET"))
+}
+
+func TestLoadEnvSilently_DefaultDotEnv(t *testing.T) {
+	unsetEnv(t, "CAMT_TEST_DOTENV", "CAMT_TEST_DOTENV_LOCAL")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("CAMT_TEST_DOTENV=base\nCAMT_TEST_DOTENV_LOCAL=base\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env.local"), []byte("CAMT_TEST_DOTENV_LOCAL=local\n"), 0600))
+	t.Chdir(dir)
+
+	loadEnvSilently("")
+
+	assert.Equal(t, "base", os.Getenv("CAMT_TEST_DOTENV"))
+	assert.Equal(t, "local", os.Getenv("CAMT_TEST_DOTENV_LOCAL"))
+}
+
+func TestLoadEnvSilently_MissingFilesAreIgnored(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	assert.NotPanics(t, func() { loadEnvSilently("") })
+}
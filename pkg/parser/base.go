@@ -1,4 +1,3 @@
-// Package parser provides the base parser functionality and common interfaces.
 package parser
 
 import (
@@ -10,6 +9,7 @@ import (
 	"fjacquet/camt-csv/internal/common"
 	"fjacquet/camt-csv/internal/logging"
 	"fjacquet/camt-csv/internal/models"
+	"fjacquet/camt-csv/internal/parsererror"
 )
 
 // BaseParser provides common functionality for all parser implementations.
@@ -113,7 +113,7 @@ func (b *BaseParser) WriteToCSV(transactions []models.Transaction, csvFile strin
 func (b *BaseParser) ConvertToCSVDefault(ctx context.Context, inputFile, outputFile string, parseFn func(ctx context.Context, r io.Reader) ([]models.Transaction, error)) error {
 	file, err := os.Open(inputFile) // #nosec G304 -- CLI tool requires user-provided file paths
 	if err != nil {
-		return fmt.Errorf("error opening input file: %w", err)
+		return fmt.Errorf("%w: error opening input file: %w", parsererror.ErrReadFailed, err)
 	}
 	defer func() {
 		if err := file.Close(); err != nil {
@@ -0,0 +1,122 @@
+// Package parser provides the interfaces parsers implement, the BaseParser
+// they embed and the registry that makes them available to the CLI.
+//
+// It is the public API for adding a bank format without forking camt-csv: a
+// parser in another module embeds BaseParser, implements FullParser and is
+// registered with RegisterParser from an init function. Running the CLI with
+// cli.Execute from that module's main then offers a convert command for it.
+package parser
+
+import (
+	"context"
+	"io"
+
+	"fjacquet/camt-csv/internal/logging"
+	"fjacquet/camt-csv/internal/models"
+)
+
+// Types of the parser interfaces, so that parsers outside this module can
+// name them.
+type (
+	// Transaction is a parsed transaction, in the layout of the standard CSV.
+	Transaction = models.Transaction
+
+	// TransactionCategorizer categorizes transactions by party name.
+	TransactionCategorizer = models.TransactionCategorizer
+
+	// StatementInfo is the statement-level metadata of StatementInfoReader.
+	StatementInfo = models.StatementInfo
+
+	// Logger is the structured logger given to parsers.
+	Logger = logging.Logger
+
+	// Field is a key-value pair attached to a log entry.
+	Field = logging.Field
+)
+
+// Parser defines the core parsing capability.
+// This interface follows the Interface Segregation Principle by containing only
+// the essential parsing method that all parsers must implement.
+type Parser interface {
+	// Parse reads data from the provided io.Reader and returns a slice of Transaction models.
+	// It is responsible for understanding the specific input format (e.g., CAMT XML, PDF, CSV)
+	// and transforming it into the standardized Transaction structure.
+	// Implementations should return custom error types (e.g., InvalidFormatError, DataExtractionError)
+	// for specific parsing failures.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation and timeout control
+	//   - r: Reader containing the data to parse
+	Parse(ctx context.Context, r io.Reader) ([]models.Transaction, error)
+}
+
+// Validator defines format validation capability.
+// Not all parsers need validation, so this is separated from the core Parser interface.
+type Validator interface {
+	// ValidateFormat checks if the given file path contains data in the expected format.
+	// Returns true if the format is valid, false otherwise, along with any error encountered.
+	ValidateFormat(filePath string) (bool, error)
+}
+
+// CSVConverter defines CSV conversion capability.
+// This interface allows parsers to provide a convenient method for converting
+// input files directly to CSV format without requiring separate Parse and Write steps.
+type CSVConverter interface {
+	// ConvertToCSV converts an input file to CSV format and writes it to the output file.
+	// This is a convenience method that typically combines Parse and WriteToCSV operations.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation and timeout control
+	//   - inputFile: Path to the input file
+	//   - outputFile: Path to the output CSV file
+	ConvertToCSV(ctx context.Context, inputFile, outputFile string) error
+}
+
+// LoggerConfigurable defines the ability to configure logging.
+// This allows parsers to accept logger instances for structured logging.
+type LoggerConfigurable interface {
+	// SetLogger configures the logger instance for the parser.
+	// Parsers should use this logger for all logging operations.
+	SetLogger(logger logging.Logger)
+}
+
+// CategorizerConfigurable defines the ability to configure categorization.
+// This allows parsers to accept categorizer instances for transaction categorization.
+type CategorizerConfigurable interface {
+	// SetCategorizer configures the categorizer instance for the parser.
+	// Parsers should use this categorizer for transaction categorization.
+	SetCategorizer(categorizer models.TransactionCategorizer)
+}
+
+// BatchConverter defines batch conversion capability.
+// This interface allows parsers to convert multiple files in a directory.
+type BatchConverter interface {
+	// BatchConvert converts all files in inputDir and writes them to outputDir.
+	// Returns the number of files converted and any error encountered.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation and timeout control
+	//   - inputDir: Directory containing input files
+	//   - outputDir: Directory for output files
+	BatchConvert(ctx context.Context, inputDir, outputDir string) (int, error)
+}
+
+// StatementInfoReader is implemented by parsers whose input carries
+// statement-level metadata such as CAMT.053 sequence numbers. It is optional;
+// batch processing uses it to detect missing statements.
+type StatementInfoReader interface {
+	// ReadStatementInfo returns the metadata of each statement in r.
+	ReadStatementInfo(r io.Reader) ([]models.StatementInfo, error)
+}
+
+// FullParser combines all parser capabilities into a single interface.
+// Use this interface when you need a parser with all available features.
+// Individual interfaces should be used when only specific capabilities are required.
+type FullParser interface {
+	Parser
+	Validator
+	CSVConverter
+	LoggerConfigurable
+	CategorizerConfigurable
+	BatchConverter
+}
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Factory creates a parser instance wired with the given logger.
+type Factory func(logger Logger) FullParser
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// RegisterParser makes a parser available under the given name.
+// It is intended to be called from an init() function, so that built-in and
+// custom parsers are both picked up by the container and the CLI, whether
+// they live in this module or in the module of a wrapper main:
+//
+//	func init() {
+//		parser.RegisterParser("mybank", func(logger parser.Logger) parser.FullParser {
+//			return mybank.NewAdapter(logger)
+//		})
+//	}
+//
+// RegisterParser panics if name is empty, factory is nil, or the name is
+// already registered, mirroring database/sql.Register.
+func RegisterParser(name string, factory Factory) {
+	if name == "" {
+		panic("parser: RegisterParser called with empty name")
+	}
+	if factory == nil {
+		panic(fmt.Sprintf("parser: RegisterParser factory for %q is nil", name))
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("parser: RegisterParser called twice for %q", name))
+	}
+	registry[name] = factory
+}
+
+// RegisteredParsers returns the names of all registered parsers in sorted order.
+func RegisteredParsers() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupParser returns the factory registered under name.
+func LookupParser(name string) (Factory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	factory, ok := registry[name]
+	return factory, ok
+}
@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"testing"
+
+	"fjacquet/camt-csv/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterParser(t *testing.T) {
+	var gotLogger logging.Logger
+	RegisterParser("registry-test", func(logger logging.Logger) FullParser {
+		gotLogger = logger
+		return nil
+	})
+
+	assert.Contains(t, RegisteredParsers(), "registry-test")
+
+	factory, ok := LookupParser("registry-test")
+	require.True(t, ok)
+	logger := &mockLogger{}
+	factory(logger)
+	assert.Same(t, logger, gotLogger)
+
+	_, ok = LookupParser("registry-test-missing")
+	assert.False(t, ok)
+}
+
+func TestRegisterParser_Panics(t *testing.T) {
+	noop := func(logging.Logger) FullParser { return nil }
+
+	assert.Panics(t, func() { RegisterParser("", noop) }, "empty name")
+	assert.Panics(t, func() { RegisterParser("registry-test-nil", nil) }, "nil factory")
+
+	RegisterParser("registry-test-dup", noop)
+	assert.Panics(t, func() { RegisterParser("registry-test-dup", noop) }, "duplicate name")
+}
+
+func TestRegisteredParsers_Sorted(t *testing.T) {
+	noop := func(logging.Logger) FullParser { return nil }
+	RegisterParser("registry-test-zz", noop)
+	RegisterParser("registry-test-aa", noop)
+
+	names := RegisteredParsers()
+	assert.IsNonDecreasing(t, names)
+}
@@ -2,6 +2,7 @@ package parsererror
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -252,3 +253,27 @@ func TestErrorTypeAssertions(t *testing.T) {
 		})
 	}
 }
+
+func TestSentinelErrors(t *testing.T) {
+	t.Run("InvalidFormatError matches ErrInvalidFormat", func(t *testing.T) {
+		err := fmt.Errorf("wrapped: %w", &InvalidFormatError{FilePath: "file.csv", ExpectedFormat: "CSV", Msg: "test"})
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+		assert.NotErrorIs(t, err, ErrReadFailed)
+	})
+
+	t.Run("ValidationError matches ErrInvalidFormat", func(t *testing.T) {
+		err := &ValidationError{FilePath: "file.csv", Reason: "missing header"}
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+	})
+
+	t.Run("InvalidFormatError wrapping ErrNoTransactions matches both", func(t *testing.T) {
+		err := &InvalidFormatError{FilePath: "file.csv", ExpectedFormat: "CSV", Msg: "empty", Err: ErrNoTransactions}
+		assert.ErrorIs(t, err, ErrInvalidFormat)
+		assert.ErrorIs(t, err, ErrNoTransactions)
+	})
+
+	t.Run("ParseError does not match ErrInvalidFormat", func(t *testing.T) {
+		err := &ParseError{Parser: "CAMT", Field: "amount", Value: "x", Err: errors.New("bad")}
+		assert.NotErrorIs(t, err, ErrInvalidFormat)
+	})
+}